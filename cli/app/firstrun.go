@@ -26,6 +26,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/kardianos/osext"
 
+	"github.com/akamai/cli/pkg/commands"
 	"github.com/akamai/cli/pkg/config"
 
 	"github.com/akamai/cli/pkg/stats"
@@ -176,7 +177,7 @@ func choosePath(ctx context.Context, writablePaths []string, selfPath string) {
 func firstRunCheckUpgrade(ctx context.Context, cfg config.Config, bannerShown bool) (bool, error) {
 	term := terminal.Get(ctx)
 	_, ok := cfg.GetValue("cli", "last-upgrade-check")
-	if ok {
+	if ok || commands.UpgradeCheckDisabled(ctx) {
 		return bannerShown, nil
 	}
 	if !bannerShown {
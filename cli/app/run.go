@@ -3,9 +3,12 @@ package app
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -30,6 +33,11 @@ func Run() int {
 	if err := cleanupUpgrade(); err != nil && errors.As(err, &pathErr) && pathErr.Err != syscall.ENOENT {
 		logger.Debugf("Unable to remove old executable: %s", err.Error())
 	}
+	if applied, err := commands.ApplyStagedUpgrade(); err != nil {
+		term.WriteErrorf("Unable to apply Akamai CLI upgrade: %s", err.Error())
+	} else if applied {
+		logger.Debug("Staged upgrade has been applied")
+	}
 
 	if err := os.Setenv("AKAMAI_CLI", "1"); err != nil {
 		term.WriteErrorf("Unable to set AKAMAI_CLI: %s", err.Error())
@@ -39,6 +47,12 @@ func Run() int {
 		term.WriteErrorf("Unable to set AKAMAI_CLI_VERSION: %s", err.Error())
 		return 1
 	}
+	migrated, err := tools.MigrateLegacyLayout()
+	if err != nil {
+		term.WriteErrorf("Unable to move Akamai CLI files from ~/.akamai-cli to XDG base directories: %s\n", err.Error())
+	} else if migrated {
+		logger.Debug("Files have been moved from ~/.akamai-cli to XDG base directories")
+	}
 	cfg, err := config.NewIni()
 	if err != nil {
 		term.WriteErrorf("Unable to open cli config: %s", err.Error())
@@ -47,11 +61,13 @@ func Run() int {
 	ctx = config.Context(ctx, cfg)
 
 	cachePath, ok := cfg.GetValue("cli", "cache-path")
+	if legacyCachePath, err := tools.LegacyCachePath(); migrated && err == nil && cachePath == legacyCachePath {
+		// the default cache directory has been moved along with other files
+		ok = false
+	}
 	if !ok {
-		cliHome, _ := tools.GetAkamaiCliPath()
-
-		cachePath = filepath.Join(cliHome, "cache")
-		if err := os.MkdirAll(cachePath, 0700); err != nil {
+		cachePath, err = tools.GetAkamaiCliCachePath()
+		if err != nil {
 			term.WriteErrorf("Unable to create cache directory: %s", err.Error())
 			return 2
 		}
@@ -63,22 +79,61 @@ func Run() int {
 	if err := cfg.Save(ctx); err != nil {
 		return 3
 	}
+	cliApp := app.CreateApp(ctx)
+	// settings of the profile named after the selected edgerc section take precedence
+	cfg.UseProfile(globalSection(cliApp.Flags, os.Args))
 	if err := cfg.ExportEnv(ctx); err != nil {
 		term.WriteErrorf("Unable to export required envs: %s", err.Error())
 	}
 
-	cliApp := app.CreateApp(ctx)
-	ctx = log.SetupContext(ctx, cliApp.Writer)
+	quiet := hasQuietFlag(cliApp.Flags, os.Args)
+	completing := isCompletionRequest(os.Args)
+	if quiet {
+		term.Quiet()
+		// let executed commands know they run in a script, and that colors should be disabled
+		for _, env := range []string{"AKAMAI_CLI_QUIET", "NO_COLOR"} {
+			if err := os.Setenv(env, "true"); err != nil {
+				term.WriteErrorf("Unable to set %s: %s", env, err.Error())
+			}
+		}
+	} else if hasNoColorFlag(cliApp.Flags, os.Args) {
+		term.DisableColors()
+		// let executed commands know colors should be disabled
+		if err := os.Setenv("NO_COLOR", "true"); err != nil {
+			term.WriteErrorf("Unable to set NO_COLOR: %s", err.Error())
+		}
+	}
+	ctx = log.SetupContext(ctx, cliApp.Writer, logOptions(cliApp.Flags, os.Args))
+	// the proxy is needed before the app runs, to check for upgrades
+	if err := app.SetProxy(globalProxy(cliApp.Flags, os.Args)); err != nil {
+		term.WriteErrorf("Unable to set proxy: %s\n", err.Error())
+	}
 
 	cmds := commands.CommandLocator(ctx)
 	cliApp.Commands = cmds
+	// aliases are expanded in os.Args, since installed commands are executed with the arguments following the command name
+	os.Args = commands.SetupAliases(ctx, cliApp, os.Args, commandPosition(cliApp.Flags, os.Args))
 
-	if err := firstRun(ctx); err != nil {
-		return 5
+	if quiet {
+		ctx = commands.WithQuietOutput(ctx)
+	}
+	if quiet || completing || hasNoUpdateCheckFlag(cliApp.Flags, os.Args) {
+		ctx = commands.WithoutUpgradeCheck(ctx)
+	}
+	// first run prompts would block scripts, and anything printed while completing would be taken as completions
+	if !quiet && !completing {
+		if err := firstRun(ctx); err != nil {
+			return 5
+		}
 	}
 	checkUpgrade(ctx)
-	if err := stats.CheckPing(ctx); err != nil {
-		term.WriteError(err.Error())
+	if !completing {
+		if err := stats.CheckPing(ctx); err != nil {
+			term.WriteError(err.Error())
+		}
+		if err := stats.SendTelemetry(ctx); err != nil {
+			log.FromContext(ctx).Debugf("Unable to send telemetry: %s", err)
+		}
 	}
 
 	// check command collision
@@ -87,19 +142,151 @@ func Run() int {
 		return 7
 	}
 
+	if completing {
+		os.Args = completionArgs(os.Args)
+	}
 	if err := cliApp.RunContext(ctx, os.Args); err != nil {
+		var exitErr cli.ExitCoder
+		if errors.As(err, &exitErr) && exitErr.ExitCode() != 0 {
+			return exitErr.ExitCode()
+		}
 		return 6
 	}
 
 	return 0
 }
 
+// hasNoColorFlag checks if global --no-color flag was provided
+// global flags have to be known before the app runs, since output is produced before any command is executed
+func hasNoColorFlag(flags []cli.Flag, args []string) bool {
+	return hasGlobalBoolFlag(flags, args, "no-color")
+}
+
+// hasNoUpdateCheckFlag checks if global --no-update-check flag was provided
+// the upgrade check runs before the app, so the flag has to be known beforehand
+func hasNoUpdateCheckFlag(flags []cli.Flag, args []string) bool {
+	return hasGlobalBoolFlag(flags, args, "no-update-check")
+}
+
+// hasQuietFlag checks if global --quiet flag, or its -q alias, was provided, or if AKAMAI_CLI_QUIET is set to true,
+// as it is by CI bootstrap scripts and for commands executed by Akamai CLI in quiet mode
+func hasQuietFlag(flags []cli.Flag, args []string) bool {
+	if quiet, _ := strconv.ParseBool(os.Getenv("AKAMAI_CLI_QUIET")); quiet {
+		return true
+	}
+	return hasGlobalBoolFlag(flags, args, "quiet") || hasGlobalBoolFlag(flags, args, "q")
+}
+
+// isCompletionRequest checks if the CLI is run by a completion script, to list completions of the last argument
+func isCompletionRequest(args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+	last := args[len(args)-1]
+	return last == "--"+cli.BashCompletionFlag.Names()[0] || last == urfaveCompletionFlag
+}
+
+// urfaveCompletionFlag is the only completion flag recognized by urfave/cli, whatever the name of cli.BashCompletionFlag
+const urfaveCompletionFlag = "--generate-bash-completion"
+
+// completionArgs replaces the completion flag passed by completion scripts with the one recognized by urfave/cli
+func completionArgs(args []string) []string {
+	result := append([]string{}, args...)
+	result[len(result)-1] = urfaveCompletionFlag
+	return result
+}
+
+func hasGlobalBoolFlag(flags []cli.Flag, args []string, name string) bool {
+	set := parseGlobalFlags(flags, args)
+	if set == nil {
+		return false
+	}
+	f := set.Lookup(name)
+	return f != nil && f.Value.String() == "true"
+}
+
+// globalSection returns the edgerc section selected with global --section flag or AKAMAI_SECTION environment variable
+// it is known before the app runs, since it selects the config profile
+func globalSection(flags []cli.Flag, args []string) string {
+	set := parseGlobalFlags(flags, args)
+	if set == nil {
+		return os.Getenv("AKAMAI_SECTION")
+	}
+	var section string
+	if f := set.Lookup("section"); f != nil {
+		section = f.Value.String()
+	}
+	// aliases are separate flags in the flag set
+	set.Visit(func(f *flag.Flag) {
+		if f.Name == "section" || f.Name == "s" {
+			section = f.Value.String()
+		}
+	})
+	return section
+}
+
+// globalProxy returns the proxy set with global --proxy flag or, if the flag is not set, with cli.proxy config value
+func globalProxy(flags []cli.Flag, args []string) string {
+	if set := parseGlobalFlags(flags, args); set != nil {
+		if f := set.Lookup("proxy"); f != nil && f.Value.String() != "" {
+			return f.Value.String()
+		}
+	}
+	return os.Getenv("AKAMAI_CLI_PROXY")
+}
+
+// logOptions returns logging settings passed with global --log-level, --log-file and --log-format flags
+// logging is set up before the app runs, so that config and package loading can be logged as well
+func logOptions(flags []cli.Flag, args []string) log.Options {
+	set := parseGlobalFlags(flags, args)
+	if set == nil {
+		return log.Options{}
+	}
+	var opts log.Options
+	set.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "log-level":
+			opts.Level = f.Value.String()
+		case "log-file":
+			opts.Path = f.Value.String()
+		case "log-format":
+			opts.Format = f.Value.String()
+		}
+	})
+	return opts
+}
+
+// commandPosition returns the position of the command name in args, following global flags
+func commandPosition(flags []cli.Flag, args []string) int {
+	set := parseGlobalFlags(flags, args)
+	if set == nil {
+		return len(args)
+	}
+	return len(args) - set.NArg()
+}
+
+func parseGlobalFlags(flags []cli.Flag, args []string) *flag.FlagSet {
+	if len(args) < 2 {
+		return nil
+	}
+	set := flag.NewFlagSet("global", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+	for _, f := range append(flags, cli.HelpFlag, cli.VersionFlag, cli.BashCompletionFlag) {
+		if err := f.Apply(set); err != nil {
+			return nil
+		}
+	}
+	// parsing stops at the first non-flag argument, so command flags are not taken into account
+	_ = set.Parse(args[1:])
+	return set
+}
+
 func findCollisions(availableCmds []*cli.Command, args []string) error {
 	if len(args) > 1 {
 		// check names and aliases
 
 		// for some built in commands, we need to check their first parameter (args[2])
-		metaCmds := []string{"help", "uninstall", "update"}
+		metaCmds := []string{"help", "rollback", "uninstall", "update"}
 		for _, c := range metaCmds {
 			if c == args[1] && len(args) > 2 {
 				if err := findDuplicate(availableCmds, args[2]); err != nil {
@@ -1,8 +1,10 @@
 package app
 
 import (
+	"os"
 	"testing"
 
+	"github.com/akamai/cli/pkg/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
@@ -455,3 +457,221 @@ func TestFindDuplicate(t *testing.T) {
 		})
 	}
 }
+
+func TestHasNoColorFlag(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.BoolFlag{Name: "no-color"},
+		&cli.StringFlag{Name: "proxy"},
+	}
+	tests := map[string]struct {
+		args     []string
+		expected bool
+	}{
+		"no args":                  {args: []string{"akamai"}},
+		"no flag":                  {args: []string{"akamai", "list"}},
+		"global flag":              {args: []string{"akamai", "--no-color", "list"}, expected: true},
+		"global flag after string": {args: []string{"akamai", "--proxy", "localhost", "--no-color", "list"}, expected: true},
+		"command flag":             {args: []string{"akamai", "echo", "--no-color"}},
+		"unknown flag":             {args: []string{"akamai", "--abc", "--no-color"}},
+		"flag set to false":        {args: []string{"akamai", "--no-color=false", "list"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, hasNoColorFlag(flags, test.args))
+		})
+	}
+}
+
+func TestHasNoUpdateCheckFlag(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.BoolFlag{Name: "no-update-check"},
+		&cli.BoolFlag{Name: "no-color"},
+	}
+	tests := map[string]struct {
+		args     []string
+		expected bool
+	}{
+		"no flag":           {args: []string{"akamai", "list"}},
+		"global flag":       {args: []string{"akamai", "--no-color", "--no-update-check", "list"}, expected: true},
+		"command flag":      {args: []string{"akamai", "echo", "--no-update-check"}},
+		"flag set to false": {args: []string{"akamai", "--no-update-check=false", "list"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, hasNoUpdateCheckFlag(flags, test.args))
+		})
+	}
+}
+
+func TestHasQuietFlag(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.BoolFlag{Name: "quiet", Aliases: []string{"q"}},
+		&cli.BoolFlag{Name: "no-color"},
+	}
+	tests := map[string]struct {
+		args     []string
+		env      string
+		expected bool
+	}{
+		"no flag":           {args: []string{"akamai", "list"}},
+		"global flag":       {args: []string{"akamai", "--no-color", "--quiet", "list"}, expected: true},
+		"global flag alias": {args: []string{"akamai", "-q", "list"}, expected: true},
+		"command flag":      {args: []string{"akamai", "echo", "-q"}},
+		"flag set to false": {args: []string{"akamai", "--quiet=false", "list"}},
+		"environment":       {args: []string{"akamai", "list"}, env: "true", expected: true},
+		"environment false": {args: []string{"akamai", "list"}, env: "false"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_QUIET", test.env))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_QUIET"))
+			}()
+			assert.Equal(t, test.expected, hasQuietFlag(flags, test.args))
+		})
+	}
+}
+
+func TestIsCompletionRequest(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		expected bool
+	}{
+		"no args":            {args: []string{"akamai"}},
+		"command":            {args: []string{"akamai", "list"}},
+		"completion script":  {args: []string{"akamai", "echo", "--" + cli.BashCompletionFlag.Names()[0]}, expected: true},
+		"urfave/cli flag":    {args: []string{"akamai", "--generate-bash-completion"}, expected: true},
+		"flag not last":      {args: []string{"akamai", "--generate-bash-completion", "list"}},
+		"completion command": {args: []string{"akamai", "completion", "bash"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, isCompletionRequest(test.args))
+		})
+	}
+}
+
+func TestCompletionArgs(t *testing.T) {
+	args := []string{"akamai", "echo", "--generate-auto-complete"}
+	assert.Equal(t, []string{"akamai", "echo", "--generate-bash-completion"}, completionArgs(args))
+	assert.Equal(t, "--generate-auto-complete", args[2])
+}
+
+func TestGlobalSection(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		env      string
+		expected string
+	}{
+		"no args":             {args: []string{"akamai"}},
+		"no args, env":        {args: []string{"akamai"}, env: "staging", expected: "staging"},
+		"no flag":             {args: []string{"akamai", "list"}},
+		"global flag":         {args: []string{"akamai", "--section", "staging", "list"}, expected: "staging"},
+		"global flag alias":   {args: []string{"akamai", "-s", "staging", "list"}, expected: "staging"},
+		"env":                 {args: []string{"akamai", "list"}, env: "staging", expected: "staging"},
+		"flag overrides env":  {args: []string{"akamai", "--section=production", "list"}, env: "staging", expected: "production"},
+		"command flag":        {args: []string{"akamai", "config", "--section", "staging"}},
+		"other global flag":   {args: []string{"akamai", "--no-color", "-s", "staging"}, expected: "staging"},
+		"empty flag with env": {args: []string{"akamai", "--section", "", "list"}, env: "staging"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := []cli.Flag{
+				&cli.BoolFlag{Name: "no-color"},
+				&cli.StringFlag{Name: "section", Aliases: []string{"s"}, EnvVars: []string{"AKAMAI_SECTION"}},
+			}
+			if test.env != "" {
+				require.NoError(t, os.Setenv("AKAMAI_SECTION", test.env))
+				defer func() {
+					require.NoError(t, os.Unsetenv("AKAMAI_SECTION"))
+				}()
+			}
+			assert.Equal(t, test.expected, globalSection(flags, test.args))
+		})
+	}
+}
+
+func TestLogOptions(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.BoolFlag{Name: "no-color"},
+		&cli.StringFlag{Name: "log-level"},
+		&cli.StringFlag{Name: "log-file"},
+		&cli.StringFlag{Name: "log-format"},
+	}
+	tests := map[string]struct {
+		args     []string
+		expected log.Options
+	}{
+		"no args": {args: []string{"akamai"}},
+		"no flag": {args: []string{"akamai", "list"}},
+		"all flags": {
+			args:     []string{"akamai", "--log-level", "debug", "--log-file=cli.log", "--no-color", "--log-format", "json", "install"},
+			expected: log.Options{Level: "debug", Path: "cli.log", Format: "json"},
+		},
+		"command flag": {args: []string{"akamai", "install", "--log-level", "debug"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, logOptions(flags, test.args))
+		})
+	}
+}
+
+func TestGlobalProxy(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.BoolFlag{Name: "no-color"},
+		&cli.StringFlag{Name: "proxy"},
+	}
+	tests := map[string]struct {
+		args     []string
+		config   string
+		expected string
+	}{
+		"no args":                {args: []string{"akamai"}},
+		"no args, config":        {args: []string{"akamai"}, config: "proxy.example.com", expected: "proxy.example.com"},
+		"global flag":            {args: []string{"akamai", "--proxy", "proxy.example.com", "list"}, expected: "proxy.example.com"},
+		"flag overrides config":  {args: []string{"akamai", "--proxy=other.example.com", "list"}, config: "proxy.example.com", expected: "other.example.com"},
+		"command flag, config":   {args: []string{"akamai", "echo", "--proxy", "other.example.com"}, config: "proxy.example.com", expected: "proxy.example.com"},
+		"empty flag with config": {args: []string{"akamai", "--proxy", "", "list"}, config: "proxy.example.com", expected: "proxy.example.com"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.config != "" {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_PROXY", test.config))
+				defer func() {
+					require.NoError(t, os.Unsetenv("AKAMAI_CLI_PROXY"))
+				}()
+			}
+			assert.Equal(t, test.expected, globalProxy(flags, test.args))
+		})
+	}
+}
+
+func TestCommandPosition(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.BoolFlag{Name: "no-color"},
+		&cli.StringFlag{Name: "section", Aliases: []string{"s"}},
+	}
+	tests := map[string]struct {
+		args     []string
+		expected int
+	}{
+		"no args":      {args: []string{"akamai"}, expected: 1},
+		"command":      {args: []string{"akamai", "pls", "--json"}, expected: 1},
+		"global flags": {args: []string{"akamai", "--no-color", "-s", "staging", "pls"}, expected: 4},
+		"only flags":   {args: []string{"akamai", "--no-color"}, expected: 2},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, commandPosition(flags, test.args))
+		})
+	}
+}
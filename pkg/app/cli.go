@@ -3,16 +3,15 @@ package app
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/akamai/cli/pkg/terminal"
-	"github.com/akamai/cli/pkg/tools"
 	"github.com/akamai/cli/pkg/version"
 
 	"github.com/fatih/color"
-	"github.com/kardianos/osext"
 	"github.com/urfave/cli/v2"
 )
 
@@ -61,6 +60,35 @@ func CreateApp(ctx context.Context) *cli.App {
 			Name:  "proxy",
 			Usage: "Set a proxy to use",
 		},
+		&cli.BoolFlag{
+			Name:  "no-color",
+			Usage: "Disable colored output",
+		},
+		&cli.BoolFlag{
+			Name:    "quiet",
+			Aliases: []string{"q"},
+			Usage:   "Print only command results and errors, without spinners, status messages, colors and the upgrade check, also turned on with AKAMAI_CLI_QUIET",
+		},
+		&cli.BoolFlag{
+			Name:  "no-update-check",
+			Usage: "Do not check for a new version of Akamai CLI, also turned off with AKAMAI_CLI_NO_UPDATE_CHECK",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print what install, update and uninstall commands would do without making any changes",
+		},
+		&cli.StringFlag{
+			Name:  "log-level",
+			Usage: "Log level: fatal, error, warn, info or debug, defaults to AKAMAI_LOG or 'error'",
+		},
+		&cli.StringFlag{
+			Name:  "log-file",
+			Usage: "Write logs to a file instead of the terminal, defaults to AKAMAI_CLI_LOG_PATH",
+		},
+		&cli.StringFlag{
+			Name:  "log-format",
+			Usage: "Log format: text or json, defaults to 'text'",
+		},
 		&cli.BoolFlag{
 			Name:    "daemon",
 			Usage:   "Keep Akamai CLI running in the background, particularly useful for Docker containers",
@@ -74,8 +102,9 @@ func CreateApp(ctx context.Context) *cli.App {
 		},
 		&cli.StringFlag{
 			Name:    "section",
-			Usage:   "edgerc section name passed to executed commands, defaults to 'default'",
+			Usage:   "edgerc section name passed to executed commands, defaults to 'default'. Also selects the config profile of the same name",
 			Aliases: []string{"s"},
+			EnvVars: []string{"AKAMAI_SECTION"},
 		},
 	}
 
@@ -85,14 +114,14 @@ func CreateApp(ctx context.Context) *cli.App {
 
 	app.Before = func(c *cli.Context) error {
 		if c.IsSet("proxy") {
-			proxy := c.String("proxy")
-			if !strings.HasPrefix(proxy, "http://") && !strings.HasPrefix(proxy, "https://") {
-				proxy = fmt.Sprintf("http://%s", proxy)
-			}
-			if err := os.Setenv("HTTP_PROXY", proxy); err != nil {
+			if err := SetProxy(c.String("proxy")); err != nil {
 				return err
 			}
-			if err := os.Setenv("HTTPS_PROXY", proxy); err != nil {
+		}
+
+		if section := c.String("section"); section != "" {
+			// let executed commands know which section, and so which account, is in use
+			if err := os.Setenv("AKAMAI_SECTION", section); err != nil {
 				return err
 			}
 		}
@@ -145,7 +174,7 @@ func DefaultAutoComplete(ctx *cli.Context) {
 		}
 
 		for _, name := range command.Names() {
-			term.Writeln(ctx.App.Writer, name)
+			term.Writeln(name)
 		}
 	}
 
@@ -167,9 +196,9 @@ func DefaultAutoComplete(ctx *cli.Context) {
 			switch len(name) {
 			case 0:
 			case 1:
-				term.Writeln(ctx.App.Writer, "-"+name)
+				term.Writeln("-" + name)
 			default:
-				term.Writeln(ctx.App.Writer, "--"+name)
+				term.Writeln("--" + name)
 			}
 		}
 	}
@@ -208,6 +237,12 @@ func SetHelpTemplates() {
 		"{{end}}" +
 		"{{end}}" +
 		"{{end}}\n" +
+		"{{with index .Metadata \"aliases\"}}" +
+		color.YellowString("Aliases:\n") +
+		"{{range .}}" +
+		color.GreenString("  {{.Name}}") + " = {{.Command}}\n" +
+		"{{end}}\n" +
+		"{{end}}" +
 		"{{if .VisibleFlags}}" +
 		color.YellowString("Global Flags:\n") +
 		"{{range $index, $option := .VisibleFlags}}" +
@@ -255,45 +290,55 @@ func SetHelpTemplates() {
 }
 
 func defaultAction(c *cli.Context) error {
-	cmd, err := osext.Executable()
-	if err != nil {
-		cmd = tools.Self()
+	term := terminal.Get(c.Context)
+
+	for _, shell := range []string{"bash", "zsh"} {
+		if c.Bool(shell) {
+			script, err := CompletionScript(shell)
+			if err != nil {
+				return err
+			}
+			term.Writeln(script)
+			return nil
+		}
 	}
 
-	zshScript := `set -k
-# To enable zsh auto-completion, run: eval "$(` + cmd + ` --zsh)"
-# We recommend adding this to your .zshrc file
-autoload -U compinit && compinit
-autoload -U bashcompinit && bashcompinit`
-
-	bashComments := `# To enable bash auto-completion, run: eval "$(` + cmd + ` --bash)"
-# We recommend adding this to your .bashrc or .bash_profile file`
-
-	bashScript := `_akamai_cli_bash_autocomplete() {
-    local cur opts base
-    COMPREPLY=()
-    cur="${COMP_WORDS[COMP_CWORD]}"
-    opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-auto-complete )
-    COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
-    return 0
+	cli.ShowAppHelpAndExit(c, 0)
+	return nil
 }
 
-complete -F _akamai_cli_bash_autocomplete ` + tools.Self()
-
-	term := terminal.Get(c.Context)
-
-	if c.Bool("bash") {
-		term.Writeln(bashComments)
-		term.Writeln(bashScript)
-		return nil
+// ParseProxy validates given proxy URL. The http:// scheme is added if the proxy has none.
+func ParseProxy(proxy string) (string, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = fmt.Sprintf("http://%s", proxy)
 	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return "", fmt.Errorf("invalid proxy: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return "", fmt.Errorf("invalid proxy: unsupported scheme %q", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return "", fmt.Errorf("invalid proxy: missing host")
+	}
+	return proxy, nil
+}
 
-	if c.Bool("zsh") {
-		term.Writeln(zshScript)
-		term.Writeln(bashScript)
+// SetProxy sets HTTP_PROXY and HTTPS_PROXY, so that given proxy is used by Akamai CLI and by commands it executes.
+// Nothing is changed if proxy is empty.
+func SetProxy(proxy string) error {
+	if proxy == "" {
 		return nil
 	}
-
-	cli.ShowAppHelpAndExit(c, 0)
-	return nil
+	proxy, err := ParseProxy(proxy)
+	if err != nil {
+		return err
+	}
+	if err := os.Setenv("HTTP_PROXY", proxy); err != nil {
+		return err
+	}
+	return os.Setenv("HTTPS_PROXY", proxy)
 }
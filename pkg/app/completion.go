@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kardianos/osext"
+
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// CompletionShells lists shells for which a completion script can be generated
+var CompletionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// CompletionScript returns a script enabling completion of Akamai CLI commands in given shell.
+// Completions are not embedded in the script: it calls Akamai CLI with the --generate-auto-complete flag,
+// so that built-in commands, installed package commands, their aliases and flags are always up to date.
+func CompletionScript(shell string) (string, error) {
+	cmd, err := osext.Executable()
+	if err != nil {
+		cmd = tools.Self()
+	}
+	self := tools.Self()
+
+	bashScript := `_akamai_cli_bash_autocomplete() {
+    local cur opts base
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-auto-complete )
+    COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
+    return 0
+}
+
+complete -F _akamai_cli_bash_autocomplete ` + self
+
+	switch shell {
+	case "bash":
+		return `# To enable bash auto-completion, run: eval "$(` + cmd + ` completion bash)"
+# We recommend adding this to your .bashrc or .bash_profile file
+` + bashScript, nil
+	case "zsh":
+		return `set -k
+# To enable zsh auto-completion, run: eval "$(` + cmd + ` completion zsh)"
+# We recommend adding this to your .zshrc file
+autoload -U compinit && compinit
+autoload -U bashcompinit && bashcompinit
+` + bashScript, nil
+	case "fish":
+		return `# To enable fish auto-completion, run: ` + cmd + ` completion fish | source
+# We recommend saving the output to ~/.config/fish/completions/` + self + `.fish
+function __akamai_cli_fish_autocomplete
+    set -l args (commandline -opc)
+    $args[1] $args[2..-1] --generate-auto-complete 2>/dev/null
+end
+
+complete -c ` + self + ` -f -a '(__akamai_cli_fish_autocomplete)'`, nil
+	case "powershell":
+		return `# To enable PowerShell auto-completion, run: ` + cmd + ` completion powershell | Out-String | Invoke-Expression
+# We recommend adding this to your PowerShell profile
+Register-ArgumentCompleter -Native -CommandName '` + self + `' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $arguments = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '' -and $arguments.Count -gt 0) {
+        $arguments = @($arguments | Select-Object -First ($arguments.Count - 1))
+    }
+    & '` + cmd + `' @arguments --generate-auto-complete 2>$null |
+        Where-Object { $_ -like "$wordToComplete*" } |
+        ForEach-Object { [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_) }
+}`, nil
+	}
+
+	return "", fmt.Errorf("unsupported shell %q, expected one of: %s", shell, strings.Join(CompletionShells, ", "))
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func TestCompletionScript(t *testing.T) {
+	tests := map[string]struct {
+		shell     string
+		expected  []string
+		withError string
+	}{
+		"bash": {
+			shell:    "bash",
+			expected: []string{"--generate-auto-complete", "complete -F _akamai_cli_bash_autocomplete " + tools.Self(), "completion bash"},
+		},
+		"zsh": {
+			shell:    "zsh",
+			expected: []string{"bashcompinit", "complete -F _akamai_cli_bash_autocomplete " + tools.Self(), "completion zsh"},
+		},
+		"fish": {
+			shell:    "fish",
+			expected: []string{"commandline -opc", "--generate-auto-complete", "complete -c " + tools.Self() + " -f -a '(__akamai_cli_fish_autocomplete)'"},
+		},
+		"powershell": {
+			shell:    "powershell",
+			expected: []string{"Register-ArgumentCompleter -Native -CommandName '" + tools.Self() + "'", "--generate-auto-complete"},
+		},
+		"unsupported shell": {
+			shell:     "tcsh",
+			withError: `unsupported shell "tcsh", expected one of: bash, zsh, fish, powershell`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			script, err := CompletionScript(test.shell)
+			if test.withError != "" {
+				assert.EqualError(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+			for _, expected := range test.expected {
+				assert.Contains(t, script, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// operations recorded in the audit log
+const (
+	auditInstall   = "install"
+	auditUpdate    = "update"
+	auditUninstall = "uninstall"
+	auditRollback  = "rollback"
+	auditUpgrade   = "upgrade"
+)
+
+// audit log formats set with cli.audit-log-format
+const (
+	auditFormatText = "text"
+	auditFormatJSON = "json"
+)
+
+const (
+	auditLogName   = "audit.log"
+	auditSucceeded = "success"
+	auditFailed    = "failed"
+	// auditCliPackage is the package name recorded for upgrades of Akamai CLI itself
+	auditCliPackage = "akamai"
+)
+
+// auditEntry records an operation changing installed packages or Akamai CLI itself: who did it and when, the version installed and where it came from
+type auditEntry struct {
+	Time            time.Time `json:"time"`
+	User            string    `json:"user"`
+	Host            string    `json:"host,omitempty"`
+	Action          string    `json:"action"`
+	Package         string    `json:"package"`
+	Status          string    `json:"status"`
+	Version         string    `json:"version,omitempty"`
+	Commit          string    `json:"commit,omitempty"`
+	PreviousVersion string    `json:"previous-version,omitempty"`
+	PreviousCommit  string    `json:"previous-commit,omitempty"`
+	Source          string    `json:"source,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// auditFields lists keys of auditEntry in the order they are written in the text format
+var auditFields = []string{"time", "user", "host", "action", "package", "status", "version", "commit", "previous-version", "previous-commit", "source", "error"}
+
+// auditMutex serializes writes of packages installed or updated concurrently
+var auditMutex sync.Mutex
+
+// parseAuditLogFormat parses the cli.audit-log-format setting. The text format is used for an empty value.
+func parseAuditLogFormat(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", auditFormatText:
+		return auditFormatText, nil
+	case auditFormatJSON:
+		return auditFormatJSON, nil
+	}
+	return auditFormatText, fmt.Errorf("invalid audit log format %q, expected text or json", value)
+}
+
+// auditLogPath returns location of the audit log, set with cli.audit-log, in the Akamai CLI directory by default
+func auditLogPath() (string, error) {
+	if path := os.Getenv("AKAMAI_CLI_AUDIT_LOG"); path != "" {
+		return path, nil
+	}
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, auditLogName), nil
+}
+
+// auditPackage returns an audit entry describing the package in given directory: its name, version, commit and where it was installed from
+func auditPackage(action, dir string) auditEntry {
+	entry := auditEntry{Action: action, Package: filepath.Base(dir)}
+	if pkg, err := readPackage(dir); err == nil && len(pkg.Commands) > 0 {
+		entry.Version = pkg.Commands[0].Version
+	}
+	if meta, err := readInstallMetadata(dir); err == nil && meta != nil {
+		entry.Commit = meta.Commit
+		entry.Source = meta.Repo
+		if meta.Source != "" {
+			entry.Source = meta.Source
+		}
+	}
+	return entry
+}
+
+// recordAudit appends the entry to the audit log, as failed if err is not nil.
+// Operations which were not done without failing, such as installing a package which is already installed, are not recorded.
+// The audit log is append-only: errors writing it are logged, but do not fail the operation.
+func recordAudit(ctx context.Context, entry auditEntry, err error) {
+	if err != nil && isWarning(err) {
+		return
+	}
+	recordPackageTelemetry(ctx, entry, err)
+	logger := log.FromContext(ctx)
+	entry.Time = time.Now().Truncate(time.Second)
+	entry.User, entry.Host = auditUser()
+	entry.Status = auditSucceeded
+	if err != nil {
+		entry.Status = auditFailed
+		entry.Error = strings.TrimSpace(terminal.StripColors(err.Error()))
+	}
+
+	format, formatErr := parseAuditLogFormat(os.Getenv("AKAMAI_CLI_AUDIT_LOG_FORMAT"))
+	if formatErr != nil {
+		logger.Warn(formatErr.Error())
+	}
+	line, err := formatAuditEntry(entry, format)
+	if err != nil {
+		logger.Errorf("Unable to format audit entry: %s", err.Error())
+		return
+	}
+	path, err := auditLogPath()
+	if err != nil {
+		logger.Errorf("Unable to determine audit log location: %s", err.Error())
+		return
+	}
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logger.Errorf("Unable to save audit log: %s", err.Error())
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.Errorf("Unable to save audit log: %s", err.Error())
+		return
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			logger.Errorf("Unable to save audit log: %s", err.Error())
+		}
+	}()
+	// the line is written at once, so that entries of concurrent processes are not interleaved
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		logger.Errorf("Unable to save audit log: %s", err.Error())
+	}
+}
+
+// auditUser returns the name of the user running Akamai CLI, along with the host name
+func auditUser() (string, string) {
+	name := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	// on shared hosts, the user who ran sudo is the one accountable for the operation
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && sudoUser != name {
+		name = fmt.Sprintf("%s (sudo: %s)", name, sudoUser)
+	}
+	host, _ := os.Hostname()
+	return name, host
+}
+
+// formatAuditEntry returns a line of the audit log, either as JSON, or in the text format with key=value pairs.
+// Values containing spaces or quotes are quoted in the text format, and empty values are omitted.
+func formatAuditEntry(entry auditEntry, format string) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil || format == auditFormatJSON {
+		return data, err
+	}
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	pairs := make([]string, 0, len(auditFields))
+	for _, key := range auditFields {
+		value := values[key]
+		if value == "" {
+			continue
+		}
+		if strings.ContainsAny(value, " \t\"=\\") || !strconv.CanBackquote(value) {
+			value = strconv.Quote(value)
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+	return []byte(strings.Join(pairs, " ")), nil
+}
+
+// parseAuditEntry parses a line of the audit log written in either format, so that the format may be changed at any time
+func parseAuditEntry(line string) (auditEntry, error) {
+	var entry auditEntry
+	if strings.HasPrefix(line, "{") {
+		err := json.Unmarshal([]byte(line), &entry)
+		return entry, err
+	}
+
+	values := make(map[string]string)
+	for rest := strings.TrimSpace(line); rest != ""; rest = strings.TrimLeft(rest, " ") {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return entry, fmt.Errorf("malformed entry: %s", line)
+		}
+		key := rest[:eq]
+		rest = rest[eq+1:]
+		end := strings.IndexByte(rest, ' ')
+		if strings.HasPrefix(rest, `"`) {
+			end = quotedValueEnd(rest)
+			if end < 0 {
+				return entry, fmt.Errorf("malformed entry: %s", line)
+			}
+		}
+		if end < 0 {
+			end = len(rest)
+		}
+		value := rest[:end]
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return entry, fmt.Errorf("malformed entry: %s", line)
+			}
+			value = unquoted
+		}
+		values[key] = value
+		rest = rest[end:]
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return entry, err
+	}
+	err = json.Unmarshal(data, &entry)
+	return entry, err
+}
+
+// quotedValueEnd returns the index following the closing quote of the quoted value v starts with, or -1 if it is not closed
+func quotedValueEnd(v string) int {
+	for i := 1; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// readAuditLog returns entries of the audit log in given file, oldest first. A missing audit log has no entries.
+// Malformed lines, e.g. of an entry being written, are skipped.
+func readAuditLog(ctx context.Context, path string) ([]auditEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.FromContext(ctx).Error(err.Error())
+		}
+	}()
+
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entry, err := parseAuditEntry(line)
+		if err != nil {
+			log.FromContext(ctx).Warnf("Skipping audit log entry: %s", err.Error())
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
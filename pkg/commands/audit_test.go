@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAuditEntry(t *testing.T) {
+	entry := auditEntry{
+		Time:    time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC),
+		User:    "alice",
+		Host:    "jump",
+		Action:  auditInstall,
+		Package: "cli-echo",
+		Status:  auditFailed,
+		Version: "1.0.0",
+		Source:  "https://github.com/akamai/cli-echo",
+		Error:   "Unable to check out \"v2\": no branch, tag or commit found",
+	}
+	tests := map[string]struct {
+		format   string
+		expected string
+	}{
+		"text": {
+			format: auditFormatText,
+			expected: `time=2026-10-14T09:30:00Z user=alice host=jump action=install package=cli-echo status=failed version=1.0.0 ` +
+				`source=https://github.com/akamai/cli-echo error="Unable to check out \"v2\": no branch, tag or commit found"`,
+		},
+		"json": {
+			format: auditFormatJSON,
+			expected: `{"time":"2026-10-14T09:30:00Z","user":"alice","host":"jump","action":"install","package":"cli-echo","status":"failed",` +
+				`"version":"1.0.0","source":"https://github.com/akamai/cli-echo","error":"Unable to check out \"v2\": no branch, tag or commit found"}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			line, err := formatAuditEntry(entry, test.format)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(line))
+
+			parsed, err := parseAuditEntry(string(line))
+			require.NoError(t, err)
+			assert.True(t, entry.Time.Equal(parsed.Time))
+			parsed.Time = entry.Time
+			assert.Equal(t, entry, parsed)
+		})
+	}
+}
+
+func TestParseAuditEntry(t *testing.T) {
+	tests := map[string]struct {
+		line      string
+		expected  auditEntry
+		withError bool
+	}{
+		"unknown keys are ignored": {
+			line:     `action=update package=cli-echo status=success extra=value`,
+			expected: auditEntry{Action: auditUpdate, Package: "cli-echo", Status: auditSucceeded},
+		},
+		"escaped quote in value": {
+			line:     `package=cli-echo error="hook \"pre-update\" failed" status=failed`,
+			expected: auditEntry{Package: "cli-echo", Status: auditFailed, Error: `hook "pre-update" failed`},
+		},
+		"unterminated quote": {
+			line:      `package=cli-echo error="hook failed`,
+			withError: true,
+		},
+		"missing key": {
+			line:      `=cli-echo`,
+			withError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			entry, err := parseAuditEntry(test.line)
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, entry)
+		})
+	}
+}
+
+func TestRecordAudit(t *testing.T) {
+	tests := map[string]struct {
+		format   string
+		err      error
+		expected []string
+	}{
+		"success": {
+			expected: []string{"action=install package=cli-echo status=success version=1.0.0"},
+		},
+		"failure": {
+			err:      errors.New("\x1b[31mUnable to install selected package\x1b[0m\n"),
+			expected: []string{"status=failed", `error="Unable to install selected package"`},
+		},
+		"json format": {
+			format:   "JSON",
+			expected: []string{`{"time":`, `"action":"install","package":"cli-echo","status":"success","version":"1.0.0"}`},
+		},
+		"warning is not recorded": {
+			err: commandWarning(errAlreadyInstalled, "Package directory already exists"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer useTempAuditLog(t)()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_AUDIT_LOG_FORMAT", test.format))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_AUDIT_LOG_FORMAT"))
+			}()
+
+			recordAudit(context.Background(), auditEntry{Action: auditInstall, Package: "cli-echo", Version: "1.0.0"}, test.err)
+
+			data, err := ioutil.ReadFile(os.Getenv("AKAMAI_CLI_AUDIT_LOG"))
+			if test.expected == nil {
+				assert.True(t, errors.Is(err, os.ErrNotExist))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, strings.Count(string(data), "\n"))
+			assert.NotContains(t, string(data), "\x1b[")
+			for _, expected := range test.expected {
+				assert.Contains(t, string(data), expected)
+			}
+
+			entries, err := readAuditLog(context.Background(), os.Getenv("AKAMAI_CLI_AUDIT_LOG"))
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.NotEmpty(t, entries[0].User)
+		})
+	}
+}
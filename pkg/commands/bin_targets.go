@@ -0,0 +1,141 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"text/template"
+
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	libcGNU  = "gnu"
+	libcMusl = "musl"
+)
+
+// rosettaRuntime is installed along with Rosetta 2, which runs amd64 binaries on Apple Silicon
+const rosettaRuntime = "/Library/Apple/usr/libexec/oah/libRosettaRuntime"
+
+var (
+	hostOS   = runtime.GOOS
+	hostArch = runtime.GOARCH
+
+	// hasRosetta and isMusl detect host capabilities, they are replaced in tests
+	hasRosetta = func() bool {
+		_, err := os.Stat(rosettaRuntime)
+		return err == nil
+	}
+	isMusl = func() bool {
+		loaders, _ := filepath.Glob("/lib/ld-musl-*")
+		return len(loaders) > 0
+	}
+
+	// archAliases are other names binaries for an architecture are commonly published with
+	archAliases = map[string][]string{
+		"arm64": {"aarch64"},
+	}
+)
+
+type (
+	// binTarget is a platform a command binary may be published for, as exposed to bin URL templates
+	binTarget struct {
+		os   string
+		arch string
+		libc string
+	}
+
+	// binCandidate is a binary URL to try, along with the command with platform fields set for checksum lookup
+	binCandidate struct {
+		cmd command
+		url string
+	}
+)
+
+// binTargets returns platforms whose binaries can run on the host, most suitable first.
+// Architecture aliases follow the native name, and on Apple Silicon amd64 binaries are tried last if Rosetta 2 is installed.
+// On musl based Linux distributions, binaries built for musl are preferred over glibc ones, which run only if statically linked.
+func binTargets() []binTarget {
+	osName := hostOS
+	if osName == "darwin" {
+		osName = "mac"
+	}
+
+	archs := append([]string{hostArch}, archAliases[hostArch]...)
+	if hostOS == "darwin" && hostArch == "arm64" && hasRosetta() {
+		archs = append(archs, "amd64")
+	}
+
+	libcs := []string{""}
+	if hostOS == "linux" {
+		libcs = []string{libcGNU}
+		if isMusl() {
+			libcs = []string{libcMusl, libcGNU}
+		}
+	}
+
+	targets := make([]binTarget, 0, len(archs)*len(libcs))
+	for _, libc := range libcs {
+		for _, arch := range archs {
+			targets = append(targets, binTarget{os: osName, arch: arch, libc: libc})
+		}
+	}
+	return targets
+}
+
+// binPlatform returns the OS and architecture names of the host as used in binary URL templates and checksums declared in cli.json
+func binPlatform() (string, string) {
+	target := binTargets()[0]
+	return target.os, target.arch
+}
+
+// binCandidates returns binary URLs of given command to try in order, one for each platform able to run it.
+// Templates not using every platform field result in the same URL for several platforms, which is only tried once.
+func binCandidates(cmd command) ([]binCandidate, error) {
+	t, err := template.New("url").Parse(cmd.Bin)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	candidates := make([]binCandidate, 0)
+	for _, target := range binTargets() {
+		cmd.OS, cmd.Arch, cmd.Libc = target.os, target.arch, target.libc
+		cmd.BinSuffix = tools.BinSuffix()
+		buf := &bytes.Buffer{}
+		if err := t.Execute(buf, cmd); err != nil {
+			return nil, err
+		}
+		url := buf.String()
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+		candidates = append(candidates, binCandidate{cmd: cmd, url: url})
+	}
+	return candidates, nil
+}
+
+// checksumKeys returns keys of the checksums declared in cli.json matching the platform the binary was fetched for
+func checksumKeys(cmd command) []string {
+	key := cmd.OS + "/" + cmd.Arch
+	if cmd.Libc == libcMusl {
+		return []string{key + "/" + libcMusl, key}
+	}
+	return []string{key}
+}
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockHost(t *testing.T, osName, arch string, rosetta, musl bool) {
+	prevOS, prevArch, prevRosetta, prevMusl := hostOS, hostArch, hasRosetta, isMusl
+	hostOS, hostArch = osName, arch
+	hasRosetta = func() bool { return rosetta }
+	isMusl = func() bool { return musl }
+	t.Cleanup(func() {
+		hostOS, hostArch, hasRosetta, isMusl = prevOS, prevArch, prevRosetta, prevMusl
+	})
+}
+
+func TestBinCandidates(t *testing.T) {
+	tests := map[string]struct {
+		os, arch      string
+		rosetta, musl bool
+		bin           string
+		expected      []string
+	}{
+		"linux amd64": {
+			os: "linux", arch: "amd64",
+			bin:      "/akamai-{{.Name}}-{{.OS}}{{.Arch}}",
+			expected: []string{"/akamai-test-linuxamd64"},
+		},
+		"linux arm64 tries aarch64": {
+			os: "linux", arch: "arm64",
+			bin:      "/akamai-{{.Name}}-{{.OS}}{{.Arch}}",
+			expected: []string{"/akamai-test-linuxarm64", "/akamai-test-linuxaarch64"},
+		},
+		"linux musl prefers musl binaries": {
+			os: "linux", arch: "amd64", musl: true,
+			bin:      `/akamai-{{.Name}}-{{.OS}}-{{.Arch}}{{if eq .Libc "musl"}}-musl{{end}}`,
+			expected: []string{"/akamai-test-linux-amd64-musl", "/akamai-test-linux-amd64"},
+		},
+		"linux musl, template without libc": {
+			os: "linux", arch: "amd64", musl: true,
+			bin:      "/akamai-{{.Name}}-{{.OS}}-{{.Arch}}",
+			expected: []string{"/akamai-test-linux-amd64"},
+		},
+		"apple silicon with rosetta": {
+			os: "darwin", arch: "arm64", rosetta: true,
+			bin:      "/akamai-{{.Name}}-{{.OS}}{{.Arch}}",
+			expected: []string{"/akamai-test-macarm64", "/akamai-test-macaarch64", "/akamai-test-macamd64"},
+		},
+		"apple silicon without rosetta": {
+			os: "darwin", arch: "arm64",
+			bin:      "/akamai-{{.Name}}-{{.OS}}{{.Arch}}",
+			expected: []string{"/akamai-test-macarm64", "/akamai-test-macaarch64"},
+		},
+		"template without platform": {
+			os: "darwin", arch: "arm64", rosetta: true,
+			bin:      "/akamai-{{.Name}}",
+			expected: []string{"/akamai-test"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockHost(t, test.os, test.arch, test.rosetta, test.musl)
+			candidates, err := binCandidates(command{Name: "test", Bin: test.bin})
+			require.NoError(t, err)
+			urls := make([]string, 0, len(candidates))
+			for _, candidate := range candidates {
+				urls = append(urls, candidate.url)
+			}
+			assert.Equal(t, test.expected, urls)
+		})
+	}
+}
+
+func TestChecksumKeys(t *testing.T) {
+	assert.Equal(t, []string{"linux/arm64"}, checksumKeys(command{OS: "linux", Arch: "arm64", Libc: libcGNU}))
+	assert.Equal(t, []string{"linux/arm64/musl", "linux/arm64"}, checksumKeys(command{OS: "linux", Arch: "arm64", Libc: libcMusl}))
+	assert.Equal(t, []string{"mac/amd64"}, checksumKeys(command{OS: "mac", Arch: "amd64"}))
+}
+
+func TestDownloadBinFallback(t *testing.T) {
+	content := []byte("binary content")
+	checksum := "93a0b24644f2e0fd11d6b422c90275c482b0cc20be4a4e3f62148ed2932b4792"
+
+	tests := map[string]struct {
+		published map[string]int
+		withError []string
+	}{
+		"native binary": {
+			published: map[string]int{"/akamai-test-macarm64": http.StatusOK},
+		},
+		"alias of the architecture": {
+			published: map[string]int{"/akamai-test-macaarch64": http.StatusOK},
+		},
+		"amd64 binary run by rosetta": {
+			published: map[string]int{"/akamai-test-macamd64": http.StatusOK},
+		},
+		"no binary found": {
+			withError: []string{"no binary published for mac/arm64", "/akamai-test-macarm64", "/akamai-test-macaarch64", "/akamai-test-macamd64"},
+		},
+		"fetch error is not a missing binary": {
+			published: map[string]int{"/akamai-test-macarm64": http.StatusForbidden, "/akamai-test-macamd64": http.StatusOK},
+			withError: []string{"invalid response status while fetching command binary: 403"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockHost(t, "darwin", "arm64", true, false)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status, ok := test.published[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(status)
+				_, err := w.Write(content)
+				assert.NoError(t, err)
+			}))
+			defer srv.Close()
+
+			dir, err := ioutil.TempDir("", "download-bin")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+
+			cmd := command{
+				Name:      "test",
+				Bin:       srv.URL + "/akamai-{{.Name}}-{{.OS}}{{.Arch}}",
+				Checksums: map[string]string{"mac/arm64": checksum, "mac/aarch64": checksum, "mac/amd64": checksum},
+			}
+			err = downloadBin(context.Background(), dir, cmd, ioutil.Discard)
+			if len(test.withError) > 0 {
+				require.Error(t, err)
+				for _, msg := range test.withError {
+					assert.Contains(t, err.Error(), msg)
+				}
+				return
+			}
+			require.NoError(t, err)
+			downloaded, err := ioutil.ReadFile(filepath.Join(dir, "akamai-test"))
+			require.NoError(t, err)
+			assert.Equal(t, content, downloaded)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// exitCanceled is the exit code of a command interrupted by SIGINT or SIGTERM, the same as of a process killed by SIGINT
+const exitCanceled = 130
+
+// cancelOnSignal returns a copy of ctx which is canceled on SIGINT or SIGTERM, so that in-flight work of a command is aborted
+// and its partial changes are cleaned up before it exits. A second signal exits immediately.
+// The returned function restores the default handling of signals and must be called once the command is done.
+func cancelOnSignal(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	term := terminal.Get(ctx)
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		term.WriteErrorf("\nCanceling, press Ctrl-C again to exit immediately\n")
+		cancel()
+		select {
+		case <-signals:
+			os.Exit(exitCanceled)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+}
+
+// canceledError replaces the error of a command interrupted by a signal, as the command fails with an error of the aborted operation
+func canceledError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	return cli.Exit(color.RedString("Canceled, partial changes have been cleaned up"), exitCanceled)
+}
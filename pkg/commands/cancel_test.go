@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestCancelOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending interrupt is not supported on windows")
+	}
+	term := &terminal.Mock{}
+	term.On("WriteErrorf", "\nCanceling, press Ctrl-C again to exit immediately\n", []interface{}(nil)).Return().Once()
+	ctx, stop := cancelOnSignal(terminal.Context(context.Background(), term))
+	defer stop()
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(os.Interrupt))
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context should be canceled")
+	}
+	term.AssertExpectations(t)
+}
+
+func TestCanceledError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := map[string]struct {
+		ctx      context.Context
+		err      error
+		expected error
+	}{
+		"no error": {
+			ctx: canceled,
+		},
+		"error, not canceled": {
+			ctx:      context.Background(),
+			err:      fmt.Errorf("oops"),
+			expected: fmt.Errorf("oops"),
+		},
+		"error, canceled": {
+			ctx:      canceled,
+			err:      fmt.Errorf("oops"),
+			expected: cli.Exit(color.RedString("Canceled, partial changes have been cleaned up"), exitCanceled),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, canceledError(test.ctx, test.err))
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+)
+
+// defaultCloneDepth is the number of commits fetched when cloning a package, unless install.clone-depth is set.
+// Packages cloned with a limited history are updated by cloning them again, see updateShallowPackage.
+const defaultCloneDepth = 1
+
+// commitPattern matches refs which look like commit hashes, which can't be checked out from a shallow clone
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// parseCloneDepth parses the install.clone-depth setting, an empty value standing for defaultCloneDepth and 0 for the full history
+func parseCloneDepth(value string) (int, error) {
+	if value == "" {
+		return defaultCloneDepth, nil
+	}
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 0 {
+		return 0, fmt.Errorf("invalid clone depth %q, expected a number of commits, or 0 for the full history", value)
+	}
+	return depth, nil
+}
+
+// cloneDepth returns the number of commits to fetch when cloning a package to check out given ref.
+// The full history is fetched to check out a commit, as it may not be the tip of a branch or a tag.
+func cloneDepth(ctx context.Context, ref string) int {
+	logger := log.FromContext(ctx)
+	if commitPattern.MatchString(ref) {
+		logger.Debugf("Cloning the full history to check out commit %s", ref)
+		return 0
+	}
+	value, _ := config.Get(ctx).GetValue("install", "clone-depth")
+	depth, err := parseCloneDepth(value)
+	if err != nil {
+		logger.Warnf("%s, using %d", err, defaultCloneDepth)
+		return defaultCloneDepth
+	}
+	return depth
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestCloneDepth(t *testing.T) {
+	tests := map[string]struct {
+		ref      string
+		value    string
+		expected int
+	}{
+		"default depth":               {expected: defaultCloneDepth},
+		"configured depth":            {value: "10", expected: 10},
+		"full history":                {value: "0", expected: 0},
+		"invalid depth":               {value: "-2", expected: defaultCloneDepth},
+		"tag is cloned shallow":       {ref: "v1.0.0", expected: defaultCloneDepth},
+		"branch is cloned shallow":    {ref: "feature", value: "5", expected: 5},
+		"short commit is cloned full": {ref: "0a1b2c3", expected: 0},
+		"commit is cloned full":       {ref: "0100000000000000000000000000000000000000", value: "5", expected: 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "install", "clone-depth").Return(test.value, test.value != "").Maybe()
+			ctx := config.Context(context.Background(), cfg)
+
+			assert.Equal(t, test.expected, cloneDepth(ctx, test.ref))
+			cfg.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInstallAndUpdateAtDepth(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("package executable is a shell script")
+	}
+	defer useTempAuditLog(t)()
+	tests := map[string]struct {
+		depth string
+	}{
+		"default depth": {},
+		"full history":  {depth: "0"},
+		"more history":  {depth: "2"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "akamai-depth")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(home))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			defer func() {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+
+			upstream := filepath.Join(home, "cli-depth")
+			repo, err := gogit.PlainInit(upstream, false)
+			require.NoError(t, err)
+			worktree, err := repo.Worktree()
+			require.NoError(t, err)
+			commit := func(version string) plumbing.Hash {
+				manifest := `{"requirements": {"go": "1.14.0"}, "commands": [{"name": "depth", "version": "` + version + `"}]}`
+				require.NoError(t, ioutil.WriteFile(filepath.Join(upstream, "cli.json"), []byte(manifest), 0644))
+				_, err := worktree.Add("cli.json")
+				require.NoError(t, err)
+				hash, err := worktree.Commit("Release "+version, &gogit.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com"}})
+				require.NoError(t, err)
+				return hash
+			}
+			commit("1.0.0")
+			commit("1.1.0")
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, &packages.Mock{}}
+			m.cfg.On("GetValue", "install", "clone-depth").Return(test.depth, test.depth != "")
+			m.cfg.On("GetValue", mock.Anything, mock.Anything).Return("", false)
+			m.cfg.On("Values").Return(map[string]map[string]string{})
+			m.term.On("Spinner").Return(m.term)
+			for _, method := range []string{"Start", "Printf"} {
+				m.term.On(method, mock.Anything, mock.Anything).Return()
+			}
+			for _, method := range []string{"OK", "WarnOK", "Warn", "Fail"} {
+				m.term.On(method).Return()
+			}
+			m.term.On("Write", mock.Anything).Return(0, nil)
+			m.term.On("Writeln", mock.Anything).Return(0, nil)
+			m.term.On("IsTTY").Return(false)
+			m.langManager.On("Install", mock.Anything, packages.LanguageRequirements{Go: "1.14.0"}, []string{"depth"}).Return(nil).
+				Run(func(args mock.Arguments) {
+					// the executable the update looks for, as a go build would write it
+					bin := filepath.Join(args.String(0), "akamai-depth")
+					require.NoError(t, ioutil.WriteFile(bin, []byte("#!/bin/sh\n"), 0755))
+				})
+
+			run := func(command *cli.Command, args ...string) error {
+				app, ctx := setupTestApp(command, m)
+				app.Commands = append(app.Commands, &cli.Command{Name: "depth", Category: "Installed"})
+				return app.RunContext(ctx, append([]string{os.Args[0], command.Name}, args...))
+			}
+			require.NoError(t, run(&cli.Command{Name: "install", Action: cmdInstall(git.NewRepository(), m.langManager)}, "file://"+upstream))
+
+			pkgDir := filepath.Join(home, ".akamai-cli", "src", "cli-depth")
+			assert.Equal(t, test.depth != "0", isShallowClone(pkgDir))
+
+			latest := commit("1.2.0")
+			update := &cli.Command{
+				Name:   "update",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "latest"}, &cli.BoolFlag{Name: "no-changelog"}, &cli.BoolFlag{Name: "check"}, &cli.BoolFlag{Name: "json"}},
+				Action: cmdUpdate(git.NewRepository(), m.langManager),
+			}
+			err = run(update, "--check", "depth")
+			var exitErr cli.ExitCoder
+			require.True(t, errors.As(err, &exitErr), "package is reported as outdated")
+			assert.Equal(t, exitFailure, exitErr.ExitCode())
+
+			require.NoError(t, run(update, "depth"))
+			meta, err := readInstallMetadata(pkgDir)
+			require.NoError(t, err)
+			assert.Equal(t, latest.String(), meta.Commit)
+			pkg, err := readPackage(pkgDir)
+			require.NoError(t, err)
+			assert.Equal(t, "1.2.0", pkg.Commands[0].Version)
+			assert.Equal(t, test.depth != "0", isShallowClone(pkgDir))
+
+			require.NoError(t, run(update, "--check", "depth"), "package is reported as current")
+			require.NoError(t, run(update, "depth"))
+			meta, err = readInstallMetadata(pkgDir)
+			require.NoError(t, err)
+			assert.Equal(t, latest.String(), meta.Commit)
+		})
+	}
+}
@@ -34,23 +34,8 @@ import (
 	"github.com/akamai/cli/pkg/tools"
 )
 
-type command struct {
-	Name         string   `json:"name"`
-	Aliases      []string `json:"aliases"`
-	Version      string   `json:"version"`
-	Description  string   `json:"description"`
-	Usage        string   `json:"usage"`
-	Arguments    string   `json:"arguments"`
-	Bin          string   `json:"bin"`
-	AutoComplete bool     `json:"auto-complete"`
-
-	Flags       []cli.Flag     `json:"-"`
-	Docs        string         `json:"-"`
-	BinSuffix   string         `json:"-"`
-	OS          string         `json:"-"`
-	Arch        string         `json:"-"`
-	Subcommands []*cli.Command `json:"-"`
-}
+// command is a command of an installed package, or a built-in command
+type command = packages.Command
 
 func getBuiltinCommands(c *cli.Context) []subcommands {
 	commands := make([]subcommands, 0)
@@ -97,27 +82,23 @@ func subcommandToCliCommands(from subcommands, gitRepo git.Repository, langManag
 		commandPkg.Commands = commandPkg.Commands[key : key+1]
 		aliases := append(command.Aliases, fmt.Sprintf("%s/%s", from.Pkg, command.Name))
 
+		action := cmdSubcommand(gitRepo, langManager)
+		if command.RenamedTo != "" {
+			action = cmdRenamedSubcommand(gitRepo, langManager, command.RenamedTo)
+		}
+		if command.Deprecated != "" || command.RenamedTo != "" {
+			action = withDeprecationWarning(command, action)
+		}
+
 		commands = append(commands, &cli.Command{
 			Name:        strings.ToLower(command.Name),
 			Aliases:     aliases,
 			Description: command.Description,
 
-			Action:          cmdSubcommand(gitRepo, langManager),
+			Action:          action,
 			Category:        color.YellowString("Installed Commands:"),
 			SkipFlagParsing: true,
-			BashComplete: func(c *cli.Context) {
-				if command.AutoComplete {
-					executable, err := findExec(c.Context, langManager, c.Command.Name)
-					if err != nil {
-						return
-					}
-
-					executable = append(executable, os.Args[2:]...)
-					if err = passthruCommand(executable); err != nil {
-						return
-					}
-				}
-			},
+			BashComplete:    completeInstalledCommand(langManager, command),
 		})
 	}
 	return commands
@@ -127,7 +108,7 @@ func subcommandToCliCommands(from subcommands, gitRepo git.Repository, langManag
 func CommandLocator(ctx context.Context) []*cli.Command {
 	gitRepo := git.NewRepository()
 	langManager := packages.NewLangManager()
-	commands := createBuiltinCommands()
+	commands := withTelemetry(createBuiltinCommands(), "")
 	commands = append(commands, createInstalledCommands(ctx, gitRepo, langManager)...)
 
 	sortCommands(commands)
@@ -144,7 +125,118 @@ func sortCommands(commands []*cli.Command) {
 func createBuiltinCommands() []*cli.Command {
 	gitRepo := git.NewRepository()
 	langManager := packages.NewLangManager()
+	profileFlag := &cli.StringFlag{
+		Name:    "section",
+		Aliases: []string{"s"},
+		Usage:   "Use settings of the `PROFILE` profile, which default to settings outside of any profile",
+	}
 	return []*cli.Command{
+		{
+			Name:         "completion",
+			ArgsUsage:    "bash|zsh|fish|powershell",
+			Description:  "Output a script enabling completion of built-in and installed commands, their aliases and flags in given shell",
+			Action:       cmdCompletion,
+			UsageText:    fmt.Sprintf("Examples:\n\n   %v\n   %v", `eval "$(akamai completion bash)"`, "akamai completion fish | source"),
+			HideHelp:     true,
+			BashComplete: completeShells,
+		},
+		{
+			Name:        "alias",
+			ArgsUsage:   "<action> [alias] [command]",
+			Description: "Manage shortcuts to commands, for example: akamai alias set pls \"property list --json\"",
+			Subcommands: []*cli.Command{
+				{
+					Name:      "set",
+					ArgsUsage: "<alias> <command>",
+					Action:    cmdAliasSet,
+				},
+				{
+					Name:   "list",
+					Action: cmdAliasList,
+				},
+				{
+					Name:      "rm",
+					Aliases:   []string{"remove"},
+					ArgsUsage: "<alias>...",
+					Action:    cmdAliasRemove,
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "browse",
+			Description: "Browse packages of configured registries in an interactive terminal, to view their details and README, and install, update or uninstall them",
+			Action:      cmdBrowse(gitRepo, langManager),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "refresh",
+					Usage: "Fetch the package list from registries, instead of using the cached one",
+				},
+			},
+		},
+		{
+			Name:        "bundle",
+			ArgsUsage:   "<action>",
+			Description: "Share a set of installed packages with your team",
+			Subcommands: []*cli.Command{
+				{
+					Name:        "export",
+					ArgsUsage:   "[<bundle file>]",
+					Description: "Export installed packages, pinned to their installed commits, to a bundle file (JSON if the file has the .json extension, YAML otherwise), or to standard output",
+					Action:      cmdBundleExport,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "format",
+							Usage: "Write the bundle to standard output in `FORMAT`: yaml or json",
+							Value: formatYAML,
+						},
+					},
+				},
+				{
+					Name:        "install",
+					ArgsUsage:   "<bundle file>",
+					Description: "Install packages listed in a bundle file at their bundled versions",
+					Action:      withPackagesLock(cmdBundleInstall(gitRepo, langManager)),
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "prune",
+							Usage: "Uninstall packages which are not listed in the bundle",
+						},
+						&cli.BoolFlag{
+							Name:  "force",
+							Usage: "Force binary installation if available when source installation fails",
+						},
+						&cli.DurationFlag{
+							Name:  "timeout",
+							Usage: "Abort git operations, builds and registry requests taking longer than `DURATION`, such as 30s or 10m, instead of after the configured timeouts",
+						},
+					},
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "cache",
+			ArgsUsage:   "<action>",
+			Description: "Manage the build cache shared by package installations and updates",
+			Subcommands: []*cli.Command{
+				{
+					Name:        "info",
+					Description: "Display the location and size of the build cache",
+					Action:      cmdCacheInfo,
+				},
+				{
+					Name:        "clean",
+					ArgsUsage:   "[<cache>...]",
+					Description: "Remove all caches, or only the given ones: " + buildCacheNames(),
+					Action:      cmdCacheClean,
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
 		{
 			Name:        "config",
 			ArgsUsage:   "<action> <setting> [value]",
@@ -153,96 +245,446 @@ func createBuiltinCommands() []*cli.Command {
 				{
 					Name:      "get",
 					ArgsUsage: "<setting>",
+					Flags:     []cli.Flag{profileFlag},
 					Action:    cmdConfigGet,
 				},
 				{
 					Name:      "set",
 					ArgsUsage: "<setting> <value>",
+					Flags:     []cli.Flag{profileFlag},
 					Action:    cmdConfigSet,
 				},
 				{
 					Name:      "list",
 					ArgsUsage: "[section]",
+					Flags:     []cli.Flag{profileFlag},
 					Action:    cmdConfigList,
 				},
 				{
 					Name:      "unset",
 					Aliases:   []string{"rm"},
 					ArgsUsage: "<setting>",
+					Flags:     []cli.Flag{profileFlag},
 					Action:    cmdConfigUnset,
 				},
+				{
+					Name:        "edit",
+					Description: "Open the config file in $VISUAL or $EDITOR, and save it once it is valid",
+					Action:      cmdConfigEdit,
+				},
+				{
+					Name:        "validate",
+					ArgsUsage:   "[file]",
+					Description: "Check the config file, or the given one, for syntax errors, unknown settings and invalid values",
+					Action:      cmdConfigValidate,
+				},
 			},
 			HideHelp:     true,
 			BashComplete: app.DefaultAutoComplete,
 		},
 		{
-			Name:         "help",
-			ArgsUsage:    "[command] [sub-command]",
-			Description:  "Displays help information",
-			Action:       cmdHelp,
+			Name:         "doctor",
+			Description:  "Diagnose problems with the Akamai CLI installation and installed packages, and suggest how to fix them",
+			Action:       cmdDoctor(langManager),
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "export-env",
+			ArgsUsage:   "<package>... | --lock <lock file>",
+			Description: "Output a script for CI which installs packages without prompts, spinners, colors or upgrade checks, and keeps them turned off for the following commands",
+			Action:      cmdExportEnv,
+			UsageText: fmt.Sprintf("Examples:\n\n   %v\n   %v\n   %v",
+				"akamai export-env property purge > akamai-bootstrap.sh",
+				"akamai export-env --format github --lock akamai-packages.lock",
+				"akamai export-env --format powershell --env AKAMAI_CLI_INSTALL_STRATEGY=binary property"),
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: fmt.Sprintf("Output the script in `FORMAT`: %s", strings.Join(exportFormats, ", ")),
+					Value: exportFormats[0],
+				},
+				&cli.StringFlag{
+					Name:  "lock",
+					Usage: "Install packages at the commits recorded in the lock `FILE`, instead of the given packages",
+				},
+				&cli.StringSliceFlag{
+					Name:  "env",
+					Usage: "Also set `KEY=VALUE` in the script, can be repeated",
+				},
+				&cli.BoolFlag{
+					Name:  "binary-only",
+					Usage: "Download published binaries without building packages from source",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "help",
+			ArgsUsage:   "[command] [sub-command] | --all",
+			Description: "Displays help information, along with the documentation of installed packages",
+			Action:      cmdHelp,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "all",
+					Usage: "Display the help of every built-in and installed command",
+				},
+				&cli.BoolFlag{
+					Name:  "no-pager",
+					Usage: "Write help directly instead of paging it with $PAGER",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "history",
+			ArgsUsage:   "[<package>...]",
+			Description: "Display the audit log of package installs, updates and uninstalls, and of Akamai CLI upgrades",
+			Action:      cmdHistory,
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "limit",
+					Usage: "Display only the last `N` operations",
+				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Display operations in JSON format",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "info",
+			ArgsUsage:   "<command>",
+			Description: "Display the source, installed version, location, language and commands of the package providing <command>",
+			Action:      cmdInfo(gitRepo),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Display package information in JSON format",
+				},
+			},
 			HideHelp:     true,
 			BashComplete: app.DefaultAutoComplete,
 		},
 		{
 			Name:        "install",
 			Aliases:     []string{"get"},
-			ArgsUsage:   "<package name or repository URL>...",
-			Description: "Fetch and install packages from a Git repository",
-			Action:      cmdInstall(gitRepo, langManager),
-			UsageText: fmt.Sprintf("Examples:\n\n   %v\n,  %v\n   %v\n   %v",
+			ArgsUsage:   "<package name, repository URL, directory or archive>[@<branch, tag or commit>]... | --from-lock [<lock file>]",
+			Description: "Fetch and install packages from a Git or Mercurial repository, a local directory or a package archive",
+			Action:      withPackagesLock(cmdInstall(gitRepo, langManager)),
+			UsageText: fmt.Sprintf("Examples:\n\n   %v\n,  %v\n   %v\n   %v\n   %v\n   %v\n   %v",
 				"akamai install property purge",
 				"akamai install akamai/cli-property",
+				"akamai install akamai/cli-property@v1.3.0",
 				"akamai install git@github.com:akamai/cli-property.git",
-				"akamai install https://github.com/akamai/cli-property.git"),
+				"akamai install https://github.com/akamai/cli-property.git",
+				"akamai install --from-lock ./akamai-packages.lock",
+				"akamai install ./cli-property.tar.gz"),
 			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Print what would be installed without making any changes",
+				},
+				&cli.BoolFlag{
+					Name:  "no-hooks",
+					Usage: "Do not run lifecycle hooks declared by the package",
+				},
+				&cli.BoolFlag{
+					Name:  "container",
+					Usage: "Run the package from the container image declared in cli.json using docker or podman, instead of building it",
+				},
 				&cli.BoolFlag{
 					Name:  "force",
 					Usage: "Force binary installation if available when source installation fails",
 				},
+				&cli.BoolFlag{
+					Name:  "binary-only",
+					Usage: "Download published binaries without building packages from source",
+				},
+				&cli.BoolFlag{
+					Name:  "source-only",
+					Usage: "Build packages from source and never fall back to binaries",
+				},
+				&cli.BoolFlag{
+					Name:  "skip-verify",
+					Usage: "Install binaries without verifying their checksum and signature",
+				},
+				&cli.BoolFlag{
+					Name:  "from-lock",
+					Usage: "Install packages at the commits recorded in a lock file (defaults to the lock file in the CLI home directory)",
+				},
+				&cli.IntFlag{
+					Name:  "concurrency",
+					Usage: "Number of packages to install concurrently",
+					Value: 1,
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "Abort git operations, builds and registry requests taking longer than `DURATION`, such as 30s or 10m, instead of after the configured timeouts",
+				},
 			},
 			HideHelp:     true,
 			BashComplete: app.DefaultAutoComplete,
 		},
 		{
 			Name:        "list",
-			Description: "By default, displays installed commands. Optionally, can display package commands from Git repositories",
-			Action:      cmdList,
+			ArgsUsage:   "[<name>...]",
+			Description: "By default, displays installed commands. Optionally, can display package commands from Git repositories. If names are given, only packages and commands containing one of them are displayed",
+			Action:      cmdList(gitRepo),
 			Flags: []cli.Flag{
 				&cli.BoolFlag{
 					Name:  "remote",
 					Usage: "Display all available packages",
 				},
+				&cli.BoolFlag{
+					Name:  "installed",
+					Usage: "Display only commands of installed packages, without built-in commands",
+				},
+				&cli.BoolFlag{
+					Name:    "outdated",
+					Aliases: []string{"updates-available"},
+					Usage:   "Display only installed packages with updates available upstream",
+				},
+				&cli.BoolFlag{
+					Name:  "current",
+					Usage: "Display only installed packages which are up-to-date with upstream",
+				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Display packages in JSON format",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Display packages in `FORMAT`: table, json, yaml, or a Go template such as '{{.Name}} {{.Version}}'",
+				},
+				&cli.BoolFlag{
+					Name:  "refresh",
+					Usage: "Fetch the package list from registries with --remote, instead of using the cached one",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "outdated",
+			ArgsUsage:   "[<command>...]",
+			Description: "List installed packages with their installed and available versions, without updating them. Exits with code 1 if any package is outdated",
+			Action:      cmdOutdated(gitRepo),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Display results in JSON format",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "package",
+			ArgsUsage:   "<action>",
+			Description: "Manage package archives for offline installation",
+			Subcommands: []*cli.Command{
+				{
+					Name:        "pack",
+					ArgsUsage:   "[<package directory>]",
+					Description: "Create a package archive, which can be installed with \"akamai install <archive>\"",
+					Action:      cmdPackagePack,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "output",
+							Aliases: []string{"o"},
+							Usage:   "Write the archive to `FILE` instead of <package directory name>.tar.gz",
+						},
+					},
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:         "pin",
+			ArgsUsage:    "<command>...",
+			Description:  "Pin the package containing <command>, so that it is held back when all commands are updated",
+			Action:       withPackagesLock(cmdPin),
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:         "rollback",
+			ArgsUsage:    "<command>...",
+			Description:  "Restore the version of the package containing <command> which was installed before the last update",
+			Action:       withPackagesLock(cmdRollback(langManager)),
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "sbom",
+			Description: "Generate a software bill of materials covering Akamai CLI and the installed packages",
+			Action:      cmdSBOM(gitRepo),
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Generate the document in `FORMAT`: cyclonedx or spdx",
+					Value: sbomFormatCycloneDX,
+				},
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Save the document to `FILE` instead of displaying it",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "search",
+			ArgsUsage:   "[<keyword>...]",
+			Description: "Search for packages in configured package registries, tolerating typos in keywords",
+			Action:      cmdSearch,
+			UsageText:   "Examples:\n\n   akamai search property\n   akamai search --tag security --author akamai",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Display matching packages in JSON format",
+				},
+				&cli.StringSliceFlag{
+					Name:  "tag",
+					Usage: "Display only packages with given tag, can be repeated to require several tags",
+				},
+				&cli.StringFlag{
+					Name:  "author",
+					Usage: "Display only packages published by given author",
+				},
+				&cli.BoolFlag{
+					Name:  "refresh",
+					Usage: "Fetch the package list from registries, instead of using the cached one",
+				},
 			},
 			HideHelp:     true,
 			BashComplete: app.DefaultAutoComplete,
 		},
 		{
-			Name:         "search",
-			ArgsUsage:    "<keyword>...",
-			Description:  "Search for packages in the official Akamai CLI package repository",
-			Action:       cmdSearch,
-			UsageText:    "Examples:\n\n   akamai search property",
+			Name:        "setup-path",
+			Description: "Write shims of installed commands to a single bin directory and add it to the user PATH",
+			Action:      cmdSetupPath,
+		},
+		{
+			Name:        "telemetry",
+			ArgsUsage:   "<action>",
+			Description: "Inspect the anonymous usage data collected when cli.telemetry is on",
+			Subcommands: []*cli.Command{
+				{
+					Name:        "show",
+					Description: "Display the events queued until they are sent",
+					Action:      cmdTelemetryShow,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "json",
+							Usage: "Display events in JSON format",
+						},
+						&cli.BoolFlag{
+							Name:  "payload",
+							Usage: "Display the bodies of the requests sending events, exactly as they are sent",
+						},
+					},
+				},
+			},
 			HideHelp:     true,
 			BashComplete: app.DefaultAutoComplete,
 		},
 		{
-			Name:         "uninstall",
+			Name:        "uninstall",
+			ArgsUsage:   "<command>...",
+			Description: "Uninstall package containing <command>",
+			Action:      withPackagesLock(cmdUninstall(langManager)),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "all",
+					Usage: "Uninstall all installed packages",
+				},
+				&cli.BoolFlag{
+					Name:  "purge",
+					Usage: "Also remove config entries and container images of the packages, and the build cache with --all",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Print what would be removed without making any changes",
+				},
+				&cli.BoolFlag{
+					Name:  "no-hooks",
+					Usage: "Do not run lifecycle hooks declared by the package",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:         "unpin",
 			ArgsUsage:    "<command>...",
-			Description:  "Uninstall package containing <command>",
-			Action:       cmdUninstall(langManager),
+			Description:  "Unpin the package containing <command>, so that it is updated again with all commands",
+			Action:       withPackagesLock(cmdUnpin),
 			HideHelp:     true,
 			BashComplete: app.DefaultAutoComplete,
 		},
 		{
 			Name:        "update",
 			ArgsUsage:   "[<command>...]",
-			Description: "Update one or more commands. If no command is specified, all commands are updated",
-			Action:      cmdUpdate(gitRepo, langManager),
+			Description: "Update one or more commands. If no command is specified, all commands except pinned ones are updated",
+			Action:      withPackagesLock(cmdUpdate(gitRepo, langManager)),
 			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Print what would be updated without making any changes",
+				},
+				&cli.BoolFlag{
+					Name:  "no-hooks",
+					Usage: "Do not run lifecycle hooks declared by the package",
+				},
 				&cli.BoolFlag{
 					Name:  "force",
 					Usage: "Force binary installation if available when source installation fails",
 				},
+				&cli.BoolFlag{
+					Name:  "binary-only",
+					Usage: "Download published binaries without building packages from source",
+				},
+				&cli.BoolFlag{
+					Name:  "source-only",
+					Usage: "Build packages from source and never fall back to binaries",
+				},
+				&cli.BoolFlag{
+					Name:  "skip-verify",
+					Usage: "Install binaries without verifying their checksum and signature",
+				},
+				&cli.IntFlag{
+					Name:  "concurrency",
+					Usage: "Number of packages to update concurrently",
+					Value: 1,
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "Abort git operations, builds and registry requests taking longer than `DURATION`, such as 30s or 10m, instead of after the configured timeouts",
+				},
+				&cli.BoolFlag{
+					Name:  "latest",
+					Usage: "Update packages installed from a specific tag or commit to the latest version of their default branch",
+				},
+				&cli.BoolFlag{
+					Name:  "check",
+					Usage: "Check for available updates without updating",
+				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Display update check results in JSON format, requires --check",
+				},
+				&cli.BoolFlag{
+					Name:  "no-changelog",
+					Usage: "Do not display commits included in the update",
+				},
 			},
 			HideHelp:     true,
 			BashComplete: app.DefaultAutoComplete,
@@ -251,18 +693,40 @@ func createBuiltinCommands() []*cli.Command {
 			Name:        "upgrade",
 			Description: "Upgrade Akamai CLI to the latest version",
 			Action:      cmdUpgrade,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "channel",
+					Usage: fmt.Sprintf("Release channel to upgrade from: %s. Defaults to cli.channel config value or 'stable'", strings.Join(releaseChannels, ", ")),
+				},
+				&cli.BoolFlag{
+					Name:  "check",
+					Usage: fmt.Sprintf("Only check if a newer version is available, exit with status %d if there is one", exitUpgradeAvailable),
+				},
+				&cli.StringFlag{
+					Name:  "version",
+					Usage: "Upgrade or downgrade to the specified version, e.g. 1.3.0",
+				},
+			},
+		},
+		{
+			Name:        "verify",
+			Description: "Verify that installed packages are complete and unmodified",
+			Action:      withPackagesLock(cmdVerify(gitRepo, langManager)),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "repair",
+					Usage: "Reinstall broken packages from the repository they were installed from",
+				},
+			},
 		},
 	}
 }
 
-func createInstalledCommands(_ context.Context, gitRepo git.Repository, langManager packages.LangManager) []*cli.Command {
+// createInstalledCommands returns commands of installed packages, using the package index to avoid reading every cli.json on startup
+func createInstalledCommands(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager) []*cli.Command {
 	commands := make([]*cli.Command, 0)
-	packagePaths := getPackagePaths()
-	for _, dir := range packagePaths {
-		pkg, err := readPackage(dir)
-		if err == nil {
-			commands = append(commands, subcommandToCliCommands(pkg, gitRepo, langManager)...)
-		}
+	for _, pkg := range installedPackages(ctx) {
+		commands = append(commands, subcommandToCliCommands(pkg, gitRepo, langManager)...)
 	}
 	return commands
 }
@@ -364,8 +828,9 @@ func getPackageBinPaths() string {
 	return path
 }
 
-func passthruCommand(executable []string) error {
+func passthruCommand(executable []string, dir string) error {
 	subCmd := exec.Command(executable[0], executable[1:]...)
+	subCmd.Dir = dir
 	subCmd.Stdin = os.Stdin
 	subCmd.Stderr = os.Stderr
 	subCmd.Stdout = os.Stdout
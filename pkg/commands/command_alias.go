@@ -0,0 +1,239 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// aliasesMetadataKey is the key of user aliases in app metadata, used to display them in help output
+const aliasesMetadataKey = "aliases"
+
+var aliasNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// userAlias is a shortcut defined with "akamai alias set", expanded to a command line before the command is executed
+type userAlias struct {
+	Name    string
+	Command string
+}
+
+func cmdAliasSet(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("ALIAS SET START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("ALIAS SET FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("ALIAS SET ERROR: %v", e.Error())
+		}
+	}()
+	if c.NArg() < 2 {
+		return commandFailure(errUsage, "You must specify the alias and the command it stands for")
+	}
+	name := c.Args().First()
+	if !aliasNamePattern.MatchString(name) {
+		return commandFailure(errUsage, "Invalid alias \"%s\": only letters, digits, \"-\" and \"_\" are allowed", name)
+	}
+	if findCommand(rootApp(c).Commands, name) != nil {
+		return commandFailure(errUsage, "Unable to set alias \"%s\": a command with the same name exists", name)
+	}
+	command := strings.Join(c.Args().Tail(), " ")
+	if _, err := splitAliasCommand(command); err != nil {
+		return commandFailure(errConfig, "Unable to set alias \"%s\": %s", name, err)
+	}
+
+	cfg := config.Get(c.Context)
+	cfg.SetValue(config.AliasesSection, name, command)
+	if err := cfg.Save(c.Context); err != nil {
+		return commandFailure(errConfig, "Unable to set alias: %s", err)
+	}
+	return nil
+}
+
+func cmdAliasRemove(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("ALIAS RM START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("ALIAS RM FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("ALIAS RM ERROR: %v", e.Error())
+		}
+	}()
+	if !c.Args().Present() {
+		return commandFailure(errUsage, "You must specify at least one alias")
+	}
+
+	cfg := config.Get(c.Context)
+	for _, name := range c.Args().Slice() {
+		if _, ok := cfg.GetValue(config.AliasesSection, name); !ok {
+			return commandFailure(errNotFound, "Alias \"%s\" not found. Try \"%s alias list\".", name, tools.Self())
+		}
+		cfg.UnsetValue(config.AliasesSection, name)
+	}
+	if err := cfg.Save(c.Context); err != nil {
+		return commandFailure(errConfig, "Unable to remove alias: %s", err)
+	}
+	return nil
+}
+
+func cmdAliasList(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("ALIAS LIST START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("ALIAS LIST FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("ALIAS LIST ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+	for _, alias := range userAliases(config.Get(c.Context)) {
+		term.Printf("%s = %s\n", alias.Name, alias.Command)
+	}
+	return nil
+}
+
+// SetupAliases makes user aliases available in help output of the app and expands the alias used in args, if any.
+// pos is the position of the command name in args, after global flags.
+func SetupAliases(ctx context.Context, app *cli.App, args []string, pos int) []string {
+	aliases := userAliases(config.Get(ctx))
+	if app.Metadata == nil {
+		app.Metadata = make(map[string]interface{})
+	}
+	app.Metadata[aliasesMetadataKey] = aliases
+	return expandAlias(ctx, app.Commands, aliases, args, pos)
+}
+
+// userAliases returns aliases stored in the config, ordered by name
+func userAliases(cfg config.Config) []userAlias {
+	aliases := make([]userAlias, 0)
+	for name, command := range cfg.Values()[config.AliasesSection] {
+		aliases = append(aliases, userAlias{Name: name, Command: command})
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].Name < aliases[j].Name
+	})
+	return aliases
+}
+
+// expandAlias replaces the alias at given position in args with the command line it stands for.
+// Aliases may refer to other aliases, each of them being expanded at most once. Commands take precedence over aliases.
+func expandAlias(ctx context.Context, cmds []*cli.Command, aliases []userAlias, args []string, pos int) []string {
+	logger := log.FromContext(ctx)
+	expanded := make(map[string]bool)
+	for pos < len(args) && findCommand(cmds, args[pos]) == nil {
+		name := args[pos]
+		var alias *userAlias
+		for i := range aliases {
+			if aliases[i].Name == name {
+				alias = &aliases[i]
+			}
+		}
+		if alias == nil || expanded[name] {
+			break
+		}
+		expanded[name] = true
+
+		words, err := splitAliasCommand(alias.Command)
+		if err != nil {
+			logger.Warnf("Ignoring alias %s: %s", name, err)
+			break
+		}
+		logger.Debugf("Expanding alias %s to: %s", name, alias.Command)
+		expandedArgs := make([]string, 0, len(args)+len(words)-1)
+		expandedArgs = append(expandedArgs, args[:pos]...)
+		expandedArgs = append(expandedArgs, words...)
+		args = append(expandedArgs, args[pos+1:]...)
+	}
+	return args
+}
+
+// rootApp returns the top-level app; the app of a subcommand context only contains its sibling subcommands
+func rootApp(c *cli.Context) *cli.App {
+	app := c.App
+	for _, ctx := range c.Lineage() {
+		if ctx.App != nil {
+			app = ctx.App
+		}
+	}
+	return app
+}
+
+// findCommand returns the command with given name or alias
+func findCommand(cmds []*cli.Command, name string) *cli.Command {
+	for _, cmd := range cmds {
+		if cmd.HasName(name) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// splitAliasCommand splits the command line of an alias into arguments.
+// Arguments containing spaces can be enclosed in single or double quotes.
+func splitAliasCommand(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	inWord := false
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %s", command)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("the command is empty")
+	}
+	return words, nil
+}
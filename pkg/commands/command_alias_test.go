@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func TestCmdAlias(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		withError string
+	}{
+		"set alias": {
+			args: []string{"set", "pls", "property list --json"},
+			init: func(m *mocked) {
+				m.cfg.On("SetValue", config.AliasesSection, "pls", "property list --json").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"set alias to unquoted command": {
+			args: []string{"set", "pls", "property", "list", "--json"},
+			init: func(m *mocked) {
+				m.cfg.On("SetValue", config.AliasesSection, "pls", "property list --json").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"set alias shadowing a command": {
+			args:      []string{"set", "alias", "list"},
+			init:      func(m *mocked) {},
+			withError: `Unable to set alias "alias": a command with the same name exists`,
+		},
+		"set alias named like a subcommand": {
+			args: []string{"set", "list", "property list"},
+			init: func(m *mocked) {
+				m.cfg.On("SetValue", config.AliasesSection, "list", "property list").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"set alias with invalid name": {
+			args:      []string{"set", "p.ls", "property list"},
+			init:      func(m *mocked) {},
+			withError: `Invalid alias "p.ls": only letters, digits, "-" and "_" are allowed`,
+		},
+		"set alias with unterminated quote": {
+			args:      []string{"set", "pls", `property "list`},
+			init:      func(m *mocked) {},
+			withError: `Unable to set alias "pls": unterminated quote in property "list`,
+		},
+		"set alias without command": {
+			args:      []string{"set", "pls"},
+			init:      func(m *mocked) {},
+			withError: "You must specify the alias and the command it stands for",
+		},
+		"set alias, error on save": {
+			args: []string{"set", "pls", "property list"},
+			init: func(m *mocked) {
+				m.cfg.On("SetValue", config.AliasesSection, "pls", "property list").Return().Once()
+				m.cfg.On("Save").Return(fmt.Errorf("save error")).Once()
+			},
+			withError: "Unable to set alias: save error",
+		},
+		"remove aliases": {
+			args: []string{"rm", "pls", "pu"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", config.AliasesSection, "pls").Return("property list", true).Once()
+				m.cfg.On("UnsetValue", config.AliasesSection, "pls").Return().Once()
+				m.cfg.On("GetValue", config.AliasesSection, "pu").Return("purge invalidate", true).Once()
+				m.cfg.On("UnsetValue", config.AliasesSection, "pu").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"remove unknown alias": {
+			args: []string{"rm", "abc"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", config.AliasesSection, "abc").Return("", false).Once()
+			},
+			withError: fmt.Sprintf(`Alias "abc" not found. Try "%s alias list".`, tools.Self()),
+		},
+		"list aliases": {
+			args: []string{"list"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"cli":                 {"cache-path": "/tmp"},
+					config.AliasesSection: {"pu": "purge invalidate", "pls": "property list --json"},
+				}).Once()
+				m.term.On("Printf", "%s = %s\n", []interface{}{"pls", "property list --json"}).Return().Once()
+				m.term.On("Printf", "%s = %s\n", []interface{}{"pu", "purge invalidate"}).Return().Once()
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := &cli.Command{
+				Name: "alias",
+				Subcommands: []*cli.Command{
+					{Name: "set", Action: cmdAliasSet},
+					{Name: "list", Action: cmdAliasList},
+					{Name: "rm", Action: cmdAliasRemove},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "alias")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), color.RedString(test.withError))
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSetupAliases(t *testing.T) {
+	cmds := []*cli.Command{
+		{Name: "list", Aliases: []string{"ls"}},
+		{Name: "property", Category: "Installed"},
+	}
+	aliases := map[string]string{
+		"pls":    "property list --json",
+		"pj":     "pls --section 'my section'",
+		"ls":     "property list",
+		"loop":   "loop-b",
+		"loop-b": "loop",
+		"broken": `property "list`,
+	}
+
+	tests := map[string]struct {
+		args     []string
+		pos      int
+		expected []string
+	}{
+		"alias expanded": {
+			args:     []string{"akamai", "pls", "--contract", "ctr_1"},
+			pos:      1,
+			expected: []string{"akamai", "property", "list", "--json", "--contract", "ctr_1"},
+		},
+		"alias after global flags": {
+			args:     []string{"akamai", "--no-color", "pls"},
+			pos:      2,
+			expected: []string{"akamai", "--no-color", "property", "list", "--json"},
+		},
+		"alias of alias": {
+			args:     []string{"akamai", "pj"},
+			pos:      1,
+			expected: []string{"akamai", "property", "list", "--json", "--section", "my section"},
+		},
+		"command takes precedence": {
+			args:     []string{"akamai", "ls"},
+			pos:      1,
+			expected: []string{"akamai", "ls"},
+		},
+		"recursive aliases": {
+			args:     []string{"akamai", "loop"},
+			pos:      1,
+			expected: []string{"akamai", "loop"},
+		},
+		"invalid alias": {
+			args:     []string{"akamai", "broken"},
+			pos:      1,
+			expected: []string{"akamai", "broken"},
+		},
+		"no command": {
+			args:     []string{"akamai"},
+			pos:      1,
+			expected: []string{"akamai"},
+		},
+		"alias used as an argument": {
+			args:     []string{"akamai", "property", "pls"},
+			pos:      1,
+			expected: []string{"akamai", "property", "pls"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &config.Mock{}
+			cfg.On("Values").Return(map[string]map[string]string{config.AliasesSection: aliases}).Once()
+			ctx := config.Context(context.Background(), cfg)
+			app := &cli.App{Commands: cmds}
+
+			args := SetupAliases(ctx, app, test.args, test.pos)
+			cfg.AssertExpectations(t)
+			assert.Equal(t, test.expected, args)
+			assert.Len(t, app.Metadata[aliasesMetadataKey], len(aliases))
+		})
+	}
+}
+
+func TestSplitAliasCommand(t *testing.T) {
+	tests := map[string]struct {
+		command   string
+		expected  []string
+		withError string
+	}{
+		"words":          {command: "property list  --json", expected: []string{"property", "list", "--json"}},
+		"quoted words":   {command: `purge --tag "a b" 'c d'`, expected: []string{"purge", "--tag", "a b", "c d"}},
+		"empty quotes":   {command: `purge ""`, expected: []string{"purge", ""}},
+		"nested quotes":  {command: `echo "it's"`, expected: []string{"echo", "it's"}},
+		"empty":          {command: "  ", withError: "the command is empty"},
+		"unterminated":   {command: `echo 'a`, withError: `unterminated quote in echo 'a`},
+		"quote in words": {command: `a"b c"d`, expected: []string{"ab cd"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			words, err := splitAliasCommand(test.command)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, words)
+		})
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	surveyterm "github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// options of the browse menus, next to packages
+const (
+	browseSearch    = "Search by keywords..."
+	browseAll       = "Show all packages"
+	browseQuit      = "Quit"
+	browseInstall   = "Install"
+	browseUpdate    = "Update"
+	browseUninstall = "Uninstall"
+	browseReadme    = "Show README"
+	browseBack      = "Back to packages"
+)
+
+// readmeNames are file names looked up, in order, for the README of an installed package
+var readmeNames = []string{"README.md", "readme.md", "README", "README.txt"}
+
+// githubRawURL serves files of GitHub repositories, it is replaced in tests
+var githubRawURL = "https://raw.githubusercontent.com"
+
+// packageBrowser lets the user search packages of configured registries, view them and install or remove them, using menus
+type packageBrowser struct {
+	term    terminal.Terminal
+	manager *PackageManager
+	list    *packageList
+}
+
+func cmdBrowse(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("BROWSE START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("BROWSE FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("BROWSE ERROR: %v", e.Error())
+			}
+		}()
+		if c.Bool("refresh") {
+			c.Context = withPackageListRefresh(c.Context)
+		}
+
+		packageList, err := fetchPackageList(c.Context)
+		if err != nil {
+			return commandFailure(errNetwork, err.Error())
+		}
+		browser := &packageBrowser{
+			term:    terminal.Get(c.Context),
+			manager: NewPackageManager(gitRepo, langManager),
+			list:    packageList,
+		}
+		if !browser.term.IsTTY() {
+			// menus can't be used, so all packages are listed as by search
+			browser.term.WriteErrorf("%s\n", color.YellowString("Browsing packages requires an interactive terminal, listing all packages instead"))
+			printPackages(browser.term, findPackages(nil, packageFilter{}, packageList), browser.installed(), quietOutput(c.Context))
+			return nil
+		}
+
+		if err := browser.run(c.Context); err != nil && !errors.Is(err, surveyterm.InterruptErr) {
+			return cli.Exit(color.RedString("Unable to browse packages: %s", err), 1)
+		}
+		return nil
+	}
+}
+
+// run shows packages until the user quits, all packages at first and the ones matching keywords after a search
+func (b *packageBrowser) run(ctx context.Context) error {
+	found := findPackages(nil, packageFilter{}, b.list)
+	searched := false
+	for {
+		installed := b.installed()
+		options := []string{browseSearch}
+		if searched {
+			options = append(options, browseAll)
+		}
+		byOption := make(map[string]string, len(found))
+		for _, pkg := range found {
+			option := packageOption(pkg, installed[pkg.Name])
+			byOption[option] = pkg.Name
+			options = append(options, option)
+		}
+		options = append(options, browseQuit)
+
+		answer, err := b.term.Prompt(fmt.Sprintf("Select a package (%d found, type to filter)", len(found)), options...)
+		if err != nil {
+			return err
+		}
+		switch answer {
+		case browseQuit:
+			return nil
+		case browseAll:
+			found, searched = findPackages(nil, packageFilter{}, b.list), false
+		case browseSearch:
+			keywords, err := b.term.Prompt("Keywords")
+			if err != nil {
+				return err
+			}
+			results := findPackages(strings.Fields(keywords), packageFilter{}, b.list)
+			if len(results) == 0 {
+				b.term.Printf("%s\n", color.YellowString("No packages found for \"%s\"", keywords))
+				continue
+			}
+			found, searched = results, true
+		default:
+			quit, err := b.showPackage(ctx, b.find(byOption[answer]))
+			if err != nil || quit {
+				return err
+			}
+		}
+	}
+}
+
+// showPackage displays details of the package along with the actions which can be taken on it, until the user goes back or quits
+func (b *packageBrowser) showPackage(ctx context.Context, pkg packageListPackage) (bool, error) {
+	for {
+		installed := b.installed()[pkg.Name]
+		printPackageDetails(b.term, pkg, installed)
+
+		actions := []string{browseInstall}
+		if installed {
+			actions = []string{browseUpdate, browseUninstall}
+		}
+		actions = append(actions, browseReadme, browseBack, browseQuit)
+		answer, err := b.term.Prompt(fmt.Sprintf("What do you want to do with %s?", pkg.Name), actions...)
+		if err != nil {
+			return false, err
+		}
+
+		switch answer {
+		case browseInstall:
+			if _, err := b.manager.Install(ctx, pkg.Name); err != nil {
+				b.term.WriteErrorf("%s\n", err.Error())
+			}
+		case browseUpdate:
+			if err := b.manager.Update(ctx, pkg.Commands[0].Name); err != nil {
+				b.term.WriteErrorf("%s\n", err.Error())
+			}
+		case browseUninstall:
+			answer, err := b.term.Confirm(fmt.Sprintf("Uninstall %s", pkg.Name), false)
+			if err != nil {
+				return false, err
+			}
+			if !answer {
+				continue
+			}
+			if err := b.manager.Uninstall(ctx, pkg.Commands[0].Name); err != nil {
+				b.term.WriteErrorf("%s\n", err.Error())
+			}
+		case browseReadme:
+			readme, err := packageReadme(ctx, pkg, installed)
+			if err != nil {
+				b.term.WriteErrorf("%s\n", color.YellowString("README of %s is not available: %s", pkg.Name, err))
+				continue
+			}
+			b.term.Printf("\n%s\n", readme)
+		case browseBack:
+			return false, nil
+		case browseQuit:
+			return true, nil
+		}
+	}
+}
+
+// installed returns names of the packages from the list which are installed
+func (b *packageBrowser) installed() map[string]bool {
+	commands := make(map[string]bool)
+	for _, pkg := range b.manager.Installed() {
+		for _, cmd := range pkg.Commands {
+			commands[cmd.Name] = true
+		}
+	}
+	installed := make(map[string]bool)
+	for _, pkg := range b.list.Packages {
+		installed[pkg.Name] = isPackageInstalled(pkg, commands)
+	}
+	return installed
+}
+
+// find returns a package from the list, with all of its commands, unlike packages returned by a search
+func (b *packageBrowser) find(name string) packageListPackage {
+	for _, pkg := range b.list.Packages {
+		if pkg.Name == name {
+			return pkg
+		}
+	}
+	return packageListPackage{Name: name}
+}
+
+func packageOption(pkg packageListPackage, installed bool) string {
+	option := pkg.Name
+	if pkg.Title != "" {
+		option += " - " + pkg.Title
+	}
+	if installed {
+		option += " (installed)"
+	}
+	return option
+}
+
+func printPackageDetails(term terminal.Terminal, pkg packageListPackage, installed bool) {
+	bold := color.New(color.FgWhite, color.Bold)
+	field := func(label, value string) {
+		if value != "" {
+			term.Printf(bold.Sprintf("  %s:", label)+" %s\n", value)
+		}
+	}
+
+	var status string
+	if installed {
+		status = " " + color.CyanString("(installed)")
+	}
+	term.Printf("\n"+color.GreenString("Package: ")+"%s [%s]%s\n", pkg.Title, color.BlueString(pkg.Name), status)
+	field("Version", pkg.Version)
+	field("Author", pkg.Author)
+	field("Repository", pkg.URL)
+	field("Issues", pkg.Issues)
+	field("Registry", pkg.Registry)
+	field("Tags", strings.Join(pkg.Tags, ", "))
+	requirements := make([]string, 0)
+	for _, r := range []struct{ name, version string }{
+		{"go", pkg.Requirements.Go},
+		{"python", pkg.Requirements.Python},
+		{"node", pkg.Requirements.Node},
+		{"ruby", pkg.Requirements.Ruby},
+		{"php", pkg.Requirements.Php},
+	} {
+		if r.version != "" {
+			requirements = append(requirements, fmt.Sprintf("%s %s", r.name, r.version))
+		}
+	}
+	sort.Strings(requirements)
+	field("Requires", strings.Join(requirements, ", "))
+
+	if len(pkg.Commands) == 0 {
+		return
+	}
+	term.Printf("\n" + color.YellowString("Commands:") + "\n")
+	for _, cmd := range pkg.Commands {
+		term.Printf(bold.Sprintf("  %s", cmd.Name))
+		printCommandAliases(term, cmd.Aliases)
+		term.Writeln()
+		if cmd.Description != "" {
+			term.Printf("    %s\n", cmd.Description)
+		}
+	}
+	term.Writeln()
+}
+
+// packageReadme returns the README of a package, read from its directory if it is installed, or fetched from its repository if it is hosted on GitHub
+func packageReadme(ctx context.Context, pkg packageListPackage, installed bool) (string, error) {
+	if installed && len(pkg.Commands) > 0 {
+		if dir, ok := findCommandPackageDir(pkg.Commands[0].Name); ok {
+			for _, name := range readmeNames {
+				if data, err := ioutil.ReadFile(filepath.Join(dir, name)); err == nil {
+					return string(data), nil
+				}
+			}
+		}
+	}
+
+	readmeURL, ok := githubReadmeURL(pkg.URL)
+	if !ok {
+		if pkg.URL == "" {
+			return "", fmt.Errorf("the package has no repository")
+		}
+		return "", fmt.Errorf("see %s", pkg.URL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, readmeURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := tools.HTTPClient(0).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.FromContext(ctx).Error(err.Error())
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to fetch %s (%s)", readmeURL, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// githubReadmeURL returns the location of README.md on the default branch of a GitHub repository
+func githubReadmeURL(repo string) (string, bool) {
+	u, err := url.Parse(repo)
+	if err != nil || !strings.EqualFold(u.Host, "github.com") {
+		return "", false
+	}
+	path := strings.Split(strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/"), "/")
+	if len(path) < 2 || path[0] == "" || path[1] == "" {
+		return "", false
+	}
+	return fmt.Sprintf("%s/%s/%s/HEAD/README.md", githubRawURL, path[0], path[1]), true
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	surveyterm "github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestCmdBrowse(t *testing.T) {
+	allPackages := []string{browseSearch, "cli-echo - Echo (installed)", "cli-property-manager - Property Manager", "cli-purge - Purge", browseQuit}
+	selectPackage := "Select a package (3 found, type to filter)"
+
+	tests := map[string]struct {
+		init      func(*terminal.Mock)
+		withError string
+	}{
+		"no terminal, all packages are listed": {
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(false)
+				m.On("WriteErrorf", "%s\n", []interface{}{color.YellowString("Browsing packages requires an interactive terminal, listing all packages instead")}).Return().Once()
+				m.On("Printf", color.YellowString("Results Found:")+" %d\n\n", []interface{}{3}).Return().Once()
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Echo", color.BlueString("cli-echo"), " " + color.CyanString("(installed)")}).Return().Once()
+				m.On("Printf", mock.Anything, mock.Anything).Return()
+			},
+		},
+		"view a package and its missing README": {
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(true)
+				m.On("Prompt", selectPackage, allPackages).Return("cli-purge - Purge", nil).Once()
+				m.On("Printf", "\n"+color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Purge", color.BlueString("cli-purge"), ""}).Return()
+				m.On("Printf", mock.Anything, mock.Anything).Return()
+				m.On("Writeln", mock.Anything).Return(0, nil)
+				actions := []string{browseInstall, browseReadme, browseBack, browseQuit}
+				m.On("Prompt", "What do you want to do with cli-purge?", actions).Return(browseReadme, nil).Once()
+				m.On("WriteErrorf", "%s\n", []interface{}{color.YellowString("README of cli-purge is not available: the package has no repository")}).Return().Once()
+				m.On("Prompt", "What do you want to do with cli-purge?", actions).Return(browseBack, nil).Once()
+				m.On("Prompt", selectPackage, allPackages).Return(browseQuit, nil).Once()
+			},
+		},
+		"search without results": {
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(true)
+				m.On("Prompt", selectPackage, allPackages).Return(browseSearch, nil).Once()
+				m.On("Prompt", "Keywords", []string(nil)).Return("abc123", nil).Once()
+				m.On("Printf", "%s\n", []interface{}{color.YellowString("No packages found for \"abc123\"")}).Return().Once()
+				m.On("Prompt", selectPackage, allPackages).Return(browseQuit, nil).Once()
+			},
+		},
+		"search and quit from a package": {
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(true)
+				m.On("Prompt", selectPackage, allPackages).Return(browseSearch, nil).Once()
+				m.On("Prompt", "Keywords", []string(nil)).Return("purge", nil).Once()
+				m.On("Prompt", "Select a package (1 found, type to filter)", []string{browseSearch, browseAll, "cli-purge - Purge", browseQuit}).Return("cli-purge - Purge", nil).Once()
+				m.On("Printf", mock.Anything, mock.Anything).Return()
+				m.On("Writeln", mock.Anything).Return(0, nil)
+				m.On("Prompt", "What do you want to do with cli-purge?", []string{browseInstall, browseReadme, browseBack, browseQuit}).Return(browseQuit, nil).Once()
+			},
+		},
+		"uninstall declined": {
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(true)
+				m.On("Prompt", selectPackage, allPackages).Return("cli-echo - Echo (installed)", nil).Once()
+				m.On("Printf", "\n"+color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Echo", color.BlueString("cli-echo"), " " + color.CyanString("(installed)")}).Return()
+				m.On("Printf", mock.Anything, mock.Anything).Return()
+				m.On("Writeln", mock.Anything).Return(0, nil)
+				actions := []string{browseUpdate, browseUninstall, browseReadme, browseBack, browseQuit}
+				m.On("Prompt", "What do you want to do with cli-echo?", actions).Return(browseUninstall, nil).Once()
+				m.On("Confirm", "Uninstall cli-echo", false).Return(false, nil).Once()
+				m.On("Prompt", "What do you want to do with cli-echo?", actions).Return(browseQuit, nil).Once()
+			},
+		},
+		"interrupted": {
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(true)
+				m.On("Prompt", selectPackage, allPackages).Return("", surveyterm.InterruptErr).Once()
+			},
+		},
+		"prompt error": {
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(true)
+				m.On("Prompt", selectPackage, allPackages).Return("", fmt.Errorf("oops")).Once()
+			},
+			withError: "Unable to browse packages: oops",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/cli/package-list.json", r.URL.String())
+				pkgResponse, err := ioutil.ReadFile("./testdata/cli-search/packages-tags-response.json")
+				require.NoError(t, err)
+				_, err = w.Write(pkgResponse)
+				assert.NoError(t, err)
+			}))
+			defer srv.Close()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_PACKAGE_REPO"))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			defer useTempCache(t)()
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
+			command := &cli.Command{
+				Name:   "browse",
+				Action: cmdBrowse(m.gitRepo, m.langManager),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "browse")
+
+			test.init(m.term)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPackageReadme(t *testing.T) {
+	tests := map[string]struct {
+		pkg       packageListPackage
+		installed bool
+		expected  string
+		withError string
+	}{
+		"fetched from github": {
+			pkg:      packageListPackage{Name: "cli-purge", URL: "https://github.com/akamai/cli-purge.git"},
+			expected: "# Purge\n",
+		},
+		"not found on github": {
+			pkg:       packageListPackage{Name: "cli-missing", URL: "https://github.com/akamai/cli-missing"},
+			withError: "unable to fetch ${SERVER}/akamai/cli-missing/HEAD/README.md (404 Not Found)",
+		},
+		"not hosted on github": {
+			pkg:       packageListPackage{Name: "cli-other", URL: "https://git.example.com/cli-other"},
+			withError: "see https://git.example.com/cli-other",
+		},
+		"installed without README": {
+			pkg:       packageListPackage{Name: "cli-echo", Commands: []command{{Name: "echo"}}},
+			installed: true,
+			withError: "the package has no repository",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/akamai/cli-purge/HEAD/README.md" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, err := w.Write([]byte("# Purge\n"))
+				assert.NoError(t, err)
+			}))
+			defer srv.Close()
+			githubRawURL = srv.URL
+			defer func() {
+				githubRawURL = "https://raw.githubusercontent.com"
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			_, ctx := setupTestApp(&cli.Command{Name: "browse"}, m)
+
+			readme, err := packageReadme(ctx, test.pkg, test.installed)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, strings.ReplaceAll(test.withError, "${SERVER}", srv.URL), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, readme)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const bundleVersion = 1
+
+type (
+	// bundle is a shareable set of packages, exported with "akamai bundle export" and installed with "akamai bundle install"
+	bundle struct {
+		Version  int             `json:"version" yaml:"version"`
+		Packages []bundlePackage `json:"packages" yaml:"packages"`
+	}
+
+	// bundlePackage is a package repository, pinned to a commit
+	bundlePackage struct {
+		Name      string `json:"name" yaml:"name"`
+		Repo      string `json:"repo" yaml:"repo"`
+		Ref       string `json:"ref,omitempty" yaml:"ref,omitempty"`
+		Commit    string `json:"commit,omitempty" yaml:"commit,omitempty"`
+		Container bool   `json:"container,omitempty" yaml:"container,omitempty"`
+	}
+)
+
+// version returns the git reference the package should be installed from, the commit taking precedence over the ref
+func (p bundlePackage) version() string {
+	if p.Commit != "" {
+		return p.Commit
+	}
+	return p.Ref
+}
+
+func cmdBundleExport(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("BUNDLE EXPORT START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("BUNDLE EXPORT FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("BUNDLE EXPORT ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+	if c.Args().Len() > 1 {
+		return commandFailure(errUsage, "Only one bundle file can be specified")
+	}
+	path, format := c.Args().First(), c.String("format")
+	if format != "" && format != formatYAML && format != formatJSON {
+		return commandFailure(errUsage, "Invalid format \"%s\", expected %s or %s", format, formatYAML, formatJSON)
+	}
+
+	b, skipped, err := buildBundle()
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to export packages: %s", err), 1)
+	}
+	for _, name := range skipped {
+		// warnings go to the error stream, so that the bundle written to standard output remains valid
+		term.WriteErrorf("%s\n", color.YellowString("Package \"%s\" was not installed from a repository and is not exported", name))
+	}
+
+	if path == "" {
+		if format == formatJSON {
+			return writeJSON(term, b)
+		}
+		return writeYAML(term, b)
+	}
+	if err := writeBundle(path, b); err != nil {
+		return cli.Exit(color.RedString("Unable to save bundle: %s", err), 1)
+	}
+	term.Printf("Bundle of %d package(s) saved to %s\n", len(b.Packages), path)
+	return nil
+}
+
+func cmdBundleInstall(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		start := time.Now()
+		logger.Debug("BUNDLE INSTALL START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("BUNDLE INSTALL FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("BUNDLE INSTALL ERROR: %v", e.Error())
+			}
+		}()
+		var stop func()
+		c.Context, stop = cancelOnSignal(c.Context)
+		defer stop()
+		defer func() {
+			e = canceledError(c.Context, e)
+		}()
+		term := terminal.Get(c.Context)
+		if c.Args().Len() != 1 {
+			return commandFailure(errUsage, "You must specify exactly one bundle file")
+		}
+		strategy, err := installStrategyFromFlags(c)
+		if err != nil {
+			return commandFailure(errUsage, err.Error())
+		}
+		c.Context = withInstallStrategy(c.Context, strategy)
+		timeout, err := timeoutFromFlags(c)
+		if err != nil {
+			return commandFailure(errUsage, err.Error())
+		}
+		c.Context = withTimeout(c.Context, timeout)
+
+		b, err := readBundle(c.Args().First())
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read bundle: %s", err), 1)
+		}
+		srcPath, err := tools.GetAkamaiCliSrcPath()
+		if err != nil {
+			return err
+		}
+
+		oldCmds := getCommands(c)
+		defer updateLockFile(c.Context)
+		defer updateCommandShims(c.Context)
+
+		bundled := make(map[string]bool, len(b.Packages))
+		for _, pkg := range b.Packages {
+			if err := c.Context.Err(); err != nil {
+				return err
+			}
+			if pkg.Repo == "" {
+				return commandFailure(errUsage, "Invalid bundle: package \"%s\" does not specify repository", pkg.Name)
+			}
+			dirName := packageDirName(pkg.Repo)
+			bundled[dirName] = true
+
+			packageDir := filepath.Join(srcPath, dirName)
+			if _, err := os.Stat(packageDir); err == nil {
+				meta, err := readInstallMetadata(packageDir)
+				if pkg.Commit != "" && (err != nil || meta == nil || meta.Commit != pkg.Commit) {
+					term.Writeln(color.YellowString("Package \"%s\" is installed in a different version than the bundled one. To install the bundled version, first run 'akamai uninstall' command.", dirName))
+					continue
+				}
+				term.Printf("Package %s already installed\n", color.CyanString(dirName))
+				continue
+			}
+
+			ctx := c.Context
+			if pkg.Container {
+				ctx = withContainerMode(ctx)
+			}
+			subCmd, err := installPackage(ctx, gitRepo, langManager, pkg.Repo, pkg.version(), c.Bool("force"), nil)
+			if err != nil {
+				if isPublicRepo(pkg.Repo) {
+					stats.TrackEvent(c.Context, "package.install", "failed", pkg.Repo)
+				}
+				return err
+			}
+			c.App.Commands = append(c.App.Commands, subcommandToCliCommands(*subCmd, gitRepo, langManager)...)
+			sortCommands(c.App.Commands)
+
+			if isPublicRepo(pkg.Repo) {
+				stats.TrackEvent(c.Context, "package.install", "success", pkg.Repo)
+			}
+		}
+
+		if c.Bool("prune") {
+			if err := pruneBundlePackages(c.Context, bundled, logger); err != nil {
+				return commandFailure(kindOf(err), err.Error())
+			}
+		}
+
+		packageListDiff(c, oldCmds)
+
+		return nil
+	}
+}
+
+// buildBundle collects installed packages with their repository and commit.
+// Names of packages installed from a local directory or archive, or before install metadata was introduced, are returned as skipped.
+func buildBundle() (*bundle, []string, error) {
+	b := &bundle{Version: bundleVersion, Packages: make([]bundlePackage, 0)}
+	var skipped []string
+	for _, dir := range getPackagePaths() {
+		meta, err := readInstallMetadata(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		if meta == nil || meta.Repo == "" {
+			skipped = append(skipped, filepath.Base(dir))
+			continue
+		}
+		pkg := bundlePackage{
+			Name:      filepath.Base(dir),
+			Repo:      meta.Repo,
+			Commit:    meta.Commit,
+			Container: meta.Image != "",
+		}
+		if meta.isPinned() {
+			pkg.Ref = meta.Ref
+		}
+		b.Packages = append(b.Packages, pkg)
+	}
+	return b, skipped, nil
+}
+
+// pruneBundlePackages uninstalls packages which are not part of the bundle
+func pruneBundlePackages(ctx context.Context, bundled map[string]bool, logger log.Logger) error {
+	for _, dir := range getPackagePaths() {
+		if bundled[filepath.Base(dir)] {
+			continue
+		}
+		name := packageCommandName(dir)
+		if err := removePackage(ctx, name, dir, logger); err != nil {
+			stats.TrackEvent(ctx, "package.uninstall", "failed", name)
+			return err
+		}
+		stats.TrackEvent(ctx, "package.uninstall", "success", name)
+	}
+	return nil
+}
+
+// readBundle reads a bundle file, either in YAML or JSON format
+func readBundle(path string) (*bundle, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, so both formats are decoded the same way
+	var b bundle
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	if b.Version > bundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d, upgrade Akamai CLI to install it", b.Version)
+	}
+	return &b, nil
+}
+
+// writeBundle writes a bundle file in JSON format if its name has the .json extension, in YAML format otherwise
+func writeBundle(path string, b *bundle) error {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := json.MarshalIndent(b, "", "  ")
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, data, 0644)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(b); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
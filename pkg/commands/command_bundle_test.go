@@ -0,0 +1,277 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// setupBundleHome creates a CLI home directory with cli-echo installed from a repository and cli-installed
+// installed from a local directory, and returns the path to its src directory
+func setupBundleHome(t *testing.T, home string) string {
+	srcPath := filepath.Join(home, ".akamai-cli", "src")
+	copyFile(t, "./testdata/.akamai-cli/src/cli-echo/cli.json", filepath.Join(srcPath, "cli-echo"))
+	copyFile(t, "./testdata/.akamai-cli/src/cli-installed/cli.json", filepath.Join(srcPath, "cli-installed"))
+	require.NoError(t, writeInstallMetadata(filepath.Join(srcPath, "cli-echo"), &installMetadata{
+		Repo:    "https://github.com/akamai/cli-echo.git",
+		Ref:     "v1.0.0",
+		RefType: refTypeTag,
+		Commit:  plumbing.Hash{1}.String(),
+	}))
+	require.NoError(t, writeInstallMetadata(filepath.Join(srcPath, "cli-installed"), &installMetadata{Source: "/tmp/cli-installed"}))
+	return srcPath
+}
+
+func TestCmdBundleExport(t *testing.T) {
+	exported := &bundle{Version: bundleVersion, Packages: []bundlePackage{
+		{Name: "cli-echo", Repo: "https://github.com/akamai/cli-echo.git", Ref: "v1.0.0", Commit: plumbing.Hash{1}.String()},
+	}}
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked, string)
+		expected  *bundle
+		withError string
+	}{
+		"export to standard output": {
+			init: func(m *mocked, dir string) {
+				m.term.On("Writeln", []interface{}{"version: 1\npackages:\n  - name: cli-echo\n    repo: https://github.com/akamai/cli-echo.git\n    ref: v1.0.0\n    commit: \"" + plumbing.Hash{1}.String() + "\""}).Return(0, nil).Once()
+			},
+		},
+		"export to standard output in json": {
+			args: []string{"--format", "json"},
+			init: func(m *mocked, dir string) {
+				m.term.On("Writeln", jsonOutput(`{"version": 1, "packages": [
+					{"name": "cli-echo", "repo": "https://github.com/akamai/cli-echo.git", "ref": "v1.0.0", "commit": "`+plumbing.Hash{1}.String()+`"}
+				]}`)).Return(0, nil).Once()
+			},
+		},
+		"export to yaml file": {
+			args: []string{"bundle.yaml"},
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", "Bundle of %d package(s) saved to %s\n", []interface{}{1, filepath.Join(dir, "bundle.yaml")}).Return().Once()
+			},
+			expected: exported,
+		},
+		"export to json file": {
+			args: []string{"bundle.json"},
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", "Bundle of %d package(s) saved to %s\n", []interface{}{1, filepath.Join(dir, "bundle.json")}).Return().Once()
+			},
+			expected: exported,
+		},
+		"invalid format": {
+			args:      []string{"--format", "xml"},
+			init:      func(m *mocked, dir string) {},
+			withError: `Invalid format "xml", expected yaml or json`,
+		},
+		"too many files": {
+			args:      []string{"bundle.yaml", "other.yaml"},
+			init:      func(m *mocked, dir string) {},
+			withError: "Only one bundle file can be specified",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "cli-bundle")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(home))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			setupBundleHome(t, home)
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name:        "bundle",
+				Subcommands: []*cli.Command{{Name: "export", Action: cmdBundleExport, Flags: []cli.Flag{&cli.StringFlag{Name: "format"}}}},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "bundle", "export")
+			for _, arg := range test.args {
+				if filepath.Ext(arg) != "" {
+					arg = filepath.Join(home, arg)
+				}
+				args = append(args, arg)
+			}
+
+			if test.withError == "" {
+				m.term.On("WriteErrorf", "%s\n", []interface{}{color.YellowString(`Package "cli-installed" was not installed from a repository and is not exported`)}).Return().Once()
+			}
+			test.init(m, home)
+			err = app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			if test.expected != nil {
+				b, err := readBundle(filepath.Join(home, test.args[0]))
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, b)
+			}
+		})
+	}
+}
+
+func TestCmdBundleInstall(t *testing.T) {
+	defer useTempAuditLog(t)()
+	bundledHash := plumbing.Hash{3}
+	tests := map[string]struct {
+		bundle    string
+		args      []string
+		init      func(*testing.T, *mocked, string)
+		installed []string
+		withError string
+	}{
+		"install bundled packages": {
+			bundle: `
+version: 1
+packages:
+  - name: cli-echo
+    repo: https://github.com/akamai/cli-echo.git
+    commit: 0100000000000000000000000000000000000000
+  - name: cli-test-cmd
+    repo: https://github.com/akamai/cli-test-cmd.git
+    commit: 0300000000000000000000000000000000000000
+`,
+			init: func(t *testing.T, m *mocked, srcPath string) {
+				packageDir := filepath.Join(srcPath, "cli-test-cmd")
+				worktree := &gogit.Worktree{}
+				m.term.On("Printf", "Package %s already installed\n", []interface{}{color.CyanString("cli-echo")}).Return().Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				// the full history is cloned to check out the bundled commit
+				m.gitRepo.On("Clone", packageDir, "https://github.com/akamai/cli-test-cmd.git", false, 0, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", packageDir)
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Reference", plumbing.NewRemoteReferenceName(git.DefaultRemoteName, bundledHash.String())).Return(nil, plumbing.ErrReferenceNotFound).Once()
+				m.gitRepo.On("Reference", plumbing.NewTagReferenceName(bundledHash.String())).Return(nil, plumbing.ErrReferenceNotFound).Once()
+				m.gitRepo.On("ResolveRevision", plumbing.Revision(bundledHash.String())).Return(&bundledHash, nil).Once()
+				m.gitRepo.On("Checkout", worktree, &gogit.CheckoutOptions{Hash: bundledHash}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, bundledHash), nil).Once()
+				m.term.On("OK").Return()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", packageDir, packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+			},
+			installed: []string{"cli-echo", "cli-installed", "cli-test-cmd"},
+		},
+		"package installed in a different version": {
+			bundle: `{"version": 1, "packages": [{"name": "cli-echo", "repo": "https://github.com/akamai/cli-echo.git", "commit": "0300000000000000000000000000000000000000"}]}`,
+			init: func(t *testing.T, m *mocked, srcPath string) {
+				m.term.On("Writeln", []interface{}{color.YellowString(`Package "cli-echo" is installed in a different version than the bundled one. To install the bundled version, first run 'akamai uninstall' command.`)}).Return(0, nil).Once()
+			},
+			installed: []string{"cli-echo", "cli-installed"},
+		},
+		"prune packages not in the bundle": {
+			bundle: `
+packages:
+  - name: cli-echo
+    repo: https://github.com/akamai/cli-echo.git
+`,
+			args: []string{"--prune"},
+			init: func(t *testing.T, m *mocked, srcPath string) {
+				m.term.On("Printf", "Package %s already installed\n", []interface{}{color.CyanString("cli-echo")}).Return().Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", `Attempting to uninstall "installed" command...`, []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+			},
+			installed: []string{"cli-echo"},
+		},
+		"invalid package entry": {
+			bundle:    `{"packages": [{"name": "cli-test-cmd"}]}`,
+			init:      func(t *testing.T, m *mocked, srcPath string) {},
+			installed: []string{"cli-echo", "cli-installed"},
+			withError: `Invalid bundle: package "cli-test-cmd" does not specify repository`,
+		},
+		"unsupported bundle version": {
+			bundle:    `{"version": 2, "packages": []}`,
+			init:      func(t *testing.T, m *mocked, srcPath string) {},
+			installed: []string{"cli-echo", "cli-installed"},
+			withError: "unsupported bundle version 2",
+		},
+		"bundle file not found": {
+			init:      func(t *testing.T, m *mocked, srcPath string) {},
+			installed: []string{"cli-echo", "cli-installed"},
+			withError: "Unable to read bundle",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "cli-bundle")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(home))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			srcPath := setupBundleHome(t, home)
+			bundlePath := filepath.Join(home, "bundle.yaml")
+			if test.bundle != "" {
+				require.NoError(t, ioutil.WriteFile(bundlePath, []byte(test.bundle), 0644))
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
+			m.cfg.On("GetValue", "install", "clone-depth").Return("", false).Maybe()
+			m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Maybe()
+			command := &cli.Command{
+				Name: "bundle",
+				Subcommands: []*cli.Command{{
+					Name:   "install",
+					Action: cmdBundleInstall(m.gitRepo, m.langManager),
+					Flags:  []cli.Flag{&cli.BoolFlag{Name: "prune"}},
+				}},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "bundle", "install")
+			args = append(args, test.args...)
+			args = append(args, bundlePath)
+
+			test.init(t, m, srcPath)
+			// list all packages
+			m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return().Maybe()
+			m.term.On("Writeln", mock.Anything).Return(0, nil).Maybe()
+			err = app.RunContext(ctx, args)
+
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			var installed []string
+			for _, dir := range getPackagePaths() {
+				installed = append(installed, filepath.Base(dir))
+			}
+			assert.Equal(t, test.installed, installed)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
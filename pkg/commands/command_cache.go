@@ -0,0 +1,213 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// buildCacheDirName is the directory inside the cache directory holding package manager caches
+const buildCacheDirName = "build"
+
+func cmdCacheInfo(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("CACHE INFO START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("CACHE INFO FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("CACHE INFO ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+	dir, err := buildCacheDir()
+	if err != nil {
+		return commandFailure(errConfig, "Unable to locate the build cache: %s", err)
+	}
+
+	term.Printf(color.GreenString("Build cache: ")+"%s\n", dir)
+	if buildCacheDisabled() {
+		term.Printf("%s\n", color.YellowString("The build cache is disabled with cli.build-cache"))
+	}
+	var total int64
+	for _, cache := range packages.BuildCaches {
+		size, err := dirSize(filepath.Join(dir, cache.Name))
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read the build cache: %s", err), 1)
+		}
+		total += size
+		term.Printf("  %-10s %10s\n", cache.Name, formatSize(size))
+	}
+	term.Printf("  %-10s %10s\n", "total", formatSize(total))
+	return nil
+}
+
+func cmdCacheClean(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("CACHE CLEAN START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("CACHE CLEAN FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("CACHE CLEAN ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+	dir, err := buildCacheDir()
+	if err != nil {
+		return commandFailure(errConfig, "Unable to locate the build cache: %s", err)
+	}
+
+	caches := packages.BuildCaches
+	if c.Args().Present() {
+		caches = nil
+		for _, name := range c.Args().Slice() {
+			cache, ok := findBuildCache(name)
+			if !ok {
+				return commandFailure(errUsage, "Unknown cache \"%s\", expected one of: %s", name, buildCacheNames())
+			}
+			caches = append(caches, cache)
+		}
+	}
+
+	var freed int64
+	for _, cache := range caches {
+		path := filepath.Join(dir, cache.Name)
+		size, err := dirSize(path)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read the build cache: %s", err), 1)
+		}
+		if err := removeCacheDir(path); err != nil {
+			return cli.Exit(color.RedString("Unable to clean %s cache: %s", cache.Name, err), 1)
+		}
+		logger.Debugf("Removed %s (%d bytes)", path, size)
+		freed += size
+	}
+	term.Printf("Build cache cleaned, %s freed\n", formatSize(freed))
+	return nil
+}
+
+// buildCacheDir returns the directory package managers cache modules, packages and build artifacts in
+func buildCacheDir() (string, error) {
+	cachePath, err := cliCachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cachePath, buildCacheDirName), nil
+}
+
+// buildCacheDisabled returns true if the build cache is turned off with cli.build-cache, in which case
+// package managers use their own caches
+func buildCacheDisabled() bool {
+	return strings.EqualFold(os.Getenv("AKAMAI_CLI_BUILD_CACHE"), "false")
+}
+
+// withBuildCache returns a context in which packages are built using the build cache, unless it is disabled
+func withBuildCache(ctx context.Context) context.Context {
+	if buildCacheDisabled() {
+		return ctx
+	}
+	dir, err := buildCacheDir()
+	if err != nil {
+		log.FromContext(ctx).Warnf("Unable to locate the build cache: %s", err)
+		return ctx
+	}
+	return packages.WithBuildCache(ctx, dir)
+}
+
+func findBuildCache(name string) (packages.BuildCache, bool) {
+	for _, cache := range packages.BuildCaches {
+		if cache.Name == name {
+			return cache, true
+		}
+	}
+	return packages.BuildCache{}, false
+}
+
+func buildCacheNames() string {
+	names := make([]string, 0, len(packages.BuildCaches))
+	for _, cache := range packages.BuildCaches {
+		names = append(names, cache.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// dirSize returns the total size of files in given directory, 0 if it does not exist
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// removeCacheDir removes a cache directory, including the read-only directories the Go module cache is made of
+func removeCacheDir(dir string) error {
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() && info.Mode().Perm()&0200 == 0 {
+			return os.Chmod(path, info.Mode().Perm()|0700)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// formatSize returns a size in bytes in human readable form, e.g. 1.5 MB
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
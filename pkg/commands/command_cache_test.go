@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestCmdCache(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked, string)
+		remaining []string
+		withError string
+	}{
+		"info": {
+			args: []string{"info"},
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", color.GreenString("Build cache: ")+"%s\n", []interface{}{dir}).Return().Once()
+				for _, cache := range packages.BuildCaches {
+					size := "0 B"
+					switch cache.Name {
+					case "go-mod":
+						size = "2.0 KB"
+					case "pip":
+						size = "10 B"
+					}
+					m.term.On("Printf", "  %-10s %10s\n", []interface{}{cache.Name, size}).Return().Once()
+				}
+				m.term.On("Printf", "  %-10s %10s\n", []interface{}{"total", "2.0 KB"}).Return().Once()
+			},
+			remaining: []string{"go-mod", "pip"},
+		},
+		"clean all caches": {
+			args: []string{"clean"},
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", "Build cache cleaned, %s freed\n", []interface{}{"2.0 KB"}).Return().Once()
+			},
+		},
+		"clean given cache": {
+			args: []string{"clean", "go-mod"},
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", "Build cache cleaned, %s freed\n", []interface{}{"2.0 KB"}).Return().Once()
+			},
+			remaining: []string{"pip"},
+		},
+		"unknown cache": {
+			args:      []string{"clean", "maven"},
+			init:      func(m *mocked, dir string) {},
+			remaining: []string{"go-mod", "pip"},
+			withError: `Unknown cache "maven", expected one of: go-build, go-mod, pip, npm, yarn, composer, bundler`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cachePath, err := ioutil.TempDir("", "cli-cache")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cachePath))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_CACHE_PATH", cachePath))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_CACHE_PATH"))
+			}()
+			dir := filepath.Join(cachePath, buildCacheDirName)
+			// modules in the Go module cache are read-only
+			modDir := filepath.Join(dir, "go-mod", "github.com", "akamai", "cli-test@v1.0.0")
+			require.NoError(t, os.MkdirAll(modDir, 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(modDir, "go.mod"), make([]byte, 2038), 0444))
+			require.NoError(t, os.Chmod(modDir, 0555))
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "pip"), 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "pip", "wheel"), make([]byte, 10), 0644))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name: "cache",
+				Subcommands: []*cli.Command{
+					{Name: "info", Action: cmdCacheInfo},
+					{Name: "clean", Action: cmdCacheClean},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "cache")
+			args = append(args, test.args...)
+
+			test.init(m, dir)
+			err = app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			for _, cache := range packages.BuildCaches {
+				_, statErr := os.Stat(filepath.Join(dir, cache.Name))
+				assert.Equal(t, containsString(test.remaining, cache.Name), statErr == nil, cache.Name)
+			}
+			// let the temporary directory be removed
+			_ = os.Chmod(modDir, 0755)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[string]struct {
+		size     int64
+		expected string
+	}{
+		"bytes":     {size: 512, expected: "512 B"},
+		"kilobytes": {size: 1536, expected: "1.5 KB"},
+		"megabytes": {size: 5 * 1024 * 1024, expected: "5.0 MB"},
+		"gigabytes": {size: 3 * 1024 * 1024 * 1024, expected: "3.0 GB"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, formatSize(test.size))
+		})
+	}
+}
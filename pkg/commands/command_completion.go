@@ -0,0 +1,134 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/app"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+const (
+	// completionProtocolCommand is the protocol of commands listing completions when run with __complete,
+	// followed by arguments typed so far and the one being completed, as cobra based commands do
+	completionProtocolCommand = "command"
+	completeArg               = "__complete"
+	// completionFlag is the flag with which urfave/cli based commands list completions, it is passed by default
+	completionFlag = "--generate-bash-completion"
+	// completionTimeout bounds the time a package may take to list completions, so that the shell is not blocked
+	completionTimeout = 5 * time.Second
+)
+
+func cmdCompletion(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("COMPLETION START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("COMPLETION FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("COMPLETION ERROR: %v", e.Error())
+		}
+	}()
+	if c.Args().Len() != 1 {
+		return commandFailure(errUsage, "You must specify one shell: %s", strings.Join(app.CompletionShells, ", "))
+	}
+
+	script, err := app.CompletionScript(c.Args().First())
+	if err != nil {
+		return commandFailure(errUsage, err.Error())
+	}
+	terminal.Get(c.Context).Writeln(script)
+
+	return nil
+}
+
+// completeShells lists shells supported by the completion command
+func completeShells(c *cli.Context) {
+	if c.Args().Present() {
+		return
+	}
+	term := terminal.Get(c.Context)
+	for _, shell := range app.CompletionShells {
+		term.Writeln(shell)
+	}
+}
+
+// completeInstalledCommand delegates completion of arguments of an installed command to its package,
+// if the command declares auto-complete in its cli.json.
+// Failures are only logged, since the shell would display any error as a completion.
+func completeInstalledCommand(langManager packages.LangManager, cmd command) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		if !cmd.AutoComplete {
+			return
+		}
+		logger := log.FromContext(c.Context)
+		executable, err := findExec(c.Context, langManager, c.Command.Name)
+		if err != nil {
+			logger.Debugf("Unable to complete %s: %s", c.Command.Name, err)
+			return
+		}
+
+		args := c.Args().Slice()
+		if cmd.CompletionProtocol == completionProtocolCommand {
+			// the word being completed is not passed by completion scripts, it is filtered by the shell
+			args = append(append([]string{completeArg}, args...), "")
+		} else {
+			args = append(args, completionFlag)
+		}
+		candidates, err := requestCompletions(c.Context, append(executable, args...))
+		if err != nil {
+			logger.Debugf("Unable to complete %s: %s", c.Command.Name, err)
+			return
+		}
+		term := terminal.Get(c.Context)
+		for _, candidate := range candidates {
+			term.Writeln(candidate)
+		}
+	}
+}
+
+// requestCompletions runs the command and returns completions it prints, one per line.
+// Descriptions following a tab and directives on a line starting with a colon, printed by cobra based commands, are left out.
+func requestCompletions(ctx context.Context, executable []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, executable[0], executable[1:]...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if i := strings.Index(line, "\t"); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		candidates = append(candidates, line)
+	}
+	return candidates, nil
+}
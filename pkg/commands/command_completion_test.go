@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestCmdCompletion(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		withError string
+	}{
+		"bash": {
+			args: []string{"bash"},
+			init: func(m *mocked) {
+				m.term.On("Writeln", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && strings.Contains(args[0].(string), "complete -F _akamai_cli_bash_autocomplete")
+				})).Return(0, nil).Once()
+			},
+		},
+		"fish": {
+			args: []string{"fish"},
+			init: func(m *mocked) {
+				m.term.On("Writeln", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && strings.Contains(args[0].(string), "__akamai_cli_fish_autocomplete")
+				})).Return(0, nil).Once()
+			},
+		},
+		"unsupported shell": {
+			args:      []string{"tcsh"},
+			init:      func(m *mocked) {},
+			withError: color.RedString(`unsupported shell "tcsh", expected one of: bash, zsh, fish, powershell`),
+		},
+		"no args passed": {
+			args:      []string{},
+			init:      func(m *mocked) {},
+			withError: color.RedString("You must specify one shell: bash, zsh, fish, powershell"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name:   "completion",
+				Action: cmdCompletion,
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "completion")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.EqualError(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCompleteInstalledCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands in tests are shell scripts")
+	}
+	tests := map[string]struct {
+		autoComplete bool
+		protocol     string
+		output       string
+		expected     []string
+	}{
+		"auto-complete not declared": {
+			output: `echo "$*"`,
+		},
+		"completion flag passed by default": {
+			autoComplete: true,
+			output:       `echo "$*"; echo --verbose`,
+			expected:     []string{"get --generate-bash-completion", "--verbose"},
+		},
+		"completion command": {
+			autoComplete: true,
+			protocol:     completionProtocolCommand,
+			output:       `echo "[$*]"; printf 'list\tList items\nshow\n:4\n'; echo "Completion ended with directive" >&2`,
+			expected:     []string{"[__complete get ]", "list", "show"},
+		},
+		"command failure": {
+			autoComplete: true,
+			output:       `echo --verbose; exit 1`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "akamai-complete")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(home))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			binPath := filepath.Join(home, ".akamai-cli", "src", "cli-complete", "bin")
+			require.NoError(t, os.MkdirAll(binPath, 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(binPath, "akamai-complete"), []byte("#!/bin/sh\n"+test.output+"\n"), 0755))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			pkg := subcommands{
+				Pkg:      "complete",
+				Commands: []command{{Name: "complete", AutoComplete: test.autoComplete, CompletionProtocol: test.protocol}},
+			}
+			app, ctx := setupTestApp(subcommandToCliCommands(pkg, m.gitRepo, m.langManager)[0], m)
+			app.EnableBashCompletion = true
+			for _, candidate := range test.expected {
+				m.term.On("Writeln", []interface{}{candidate}).Return(0, nil).Once()
+			}
+
+			err = app.RunContext(ctx, []string{"akamai", "complete", "get", "--generate-bash-completion"})
+			require.NoError(t, err)
+			m.term.AssertExpectations(t)
+			m.term.AssertNumberOfCalls(t, "Writeln", len(test.expected))
+		})
+	}
+}
@@ -15,18 +15,31 @@
 package commands
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/akamai/cli/pkg/log"
-	"github.com/fatih/color"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
 	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/keyring"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
 
+	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
 
+// maskedSecret replaces values of secrets listed with config list
+const maskedSecret = "********"
+
 func cmdConfigSet(c *cli.Context) (e error) {
 	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
 	logger := log.WithCommand(c.Context, c.Command.Name)
@@ -42,12 +55,24 @@ func cmdConfigSet(c *cli.Context) (e error) {
 	cfg := config.Get(c.Context)
 	section, key, err := parseConfigPath(c)
 	if err != nil {
-		return cli.Exit(color.RedString(fmt.Sprintf("Unable to set config value: %s", err)), 1)
+		return commandFailure(errConfig, "Unable to set config value: %s", err)
 	}
 	value := strings.Join(c.Args().Tail(), " ")
-	cfg.SetValue(section, key, value)
+	if profile := configProfile(c); profile != "" {
+		section, key = config.ProfileSection(profile), section+"."+key
+	}
+	if config.IsSecretKey(section, key) {
+		if err := cfg.SetSecret(section, key, value); err != nil {
+			if errors.Is(err, keyring.ErrUnavailable) {
+				return commandFailure(errConfig, "Unable to store the secret in the keyring: %s. To store secrets in the config file instead, run \"%s config set cli.secret-storage file\"", err, tools.Self())
+			}
+			return commandFailure(errConfig, "Unable to set config value: %s", err)
+		}
+	} else {
+		cfg.SetValue(section, key, value)
+	}
 	if err := cfg.Save(c.Context); err != nil {
-		return cli.Exit(color.RedString(fmt.Sprintf("Unable to set config value: %s", err)), 1)
+		return commandFailure(errConfig, "Unable to set config value: %s", err)
 	}
 	return nil
 }
@@ -67,9 +92,16 @@ func cmdConfigGet(c *cli.Context) (e error) {
 	cfg := config.Get(c.Context)
 	section, key, err := parseConfigPath(c)
 	if err != nil {
-		return cli.Exit(color.RedString(fmt.Sprintf("Unable to get config value: %s", err)), 1)
+		return commandFailure(errConfig, "Unable to get config value: %s", err)
+	}
+	var val string
+	var found bool
+	if profile := configProfile(c); profile != "" {
+		val, found = cfg.GetValue(config.ProfileSection(profile), section+"."+key)
+	}
+	if !found {
+		val, _ = cfg.GetValue(section, key)
 	}
-	val, _ := cfg.GetValue(section, key)
 	terminal.Get(c.Context).Writeln(val)
 	logger.Debug(val)
 	return nil
@@ -90,12 +122,21 @@ func cmdConfigUnset(c *cli.Context) (e error) {
 	cfg := config.Get(c.Context)
 	section, key, err := parseConfigPath(c)
 	if err != nil {
-		return cli.Exit(color.RedString(fmt.Sprintf("Unable to unset config value: %s", err)), 1)
+		return commandFailure(errConfig, "Unable to unset config value: %s", err)
 	}
 
-	cfg.UnsetValue(section, key)
+	if profile := configProfile(c); profile != "" {
+		section, key = config.ProfileSection(profile), section+"."+key
+	}
+	if config.IsSecretKey(section, key) {
+		if err := cfg.UnsetSecret(section, key); err != nil {
+			return commandFailure(errConfig, "Unable to unset config value: %s", err)
+		}
+	} else {
+		cfg.UnsetValue(section, key)
+	}
 	if err := cfg.Save(c.Context); err != nil {
-		return cli.Exit(color.RedString(fmt.Sprintf("Unable to set config value: %s", err)), 1)
+		return commandFailure(errConfig, "Unable to set config value: %s", err)
 	}
 	return nil
 }
@@ -116,6 +157,17 @@ func cmdConfigList(c *cli.Context) (e error) {
 	term := terminal.Get(c.Context)
 
 	allValues := cfg.Values()
+	if profile := configProfile(c); profile != "" {
+		for path, value := range allValues[config.ProfileSection(profile)] {
+			sectionName := strings.SplitN(path, ".", 2)[0]
+			if c.NArg() > 0 && sectionName != c.Args().First() {
+				continue
+			}
+			term.Printf("%s = %s\n", path, listedValue(config.ProfileSection(profile), path, value))
+		}
+		return nil
+	}
+
 	if c.NArg() > 0 {
 		sectionName := c.Args().First()
 		section, ok := allValues[sectionName]
@@ -123,20 +175,42 @@ func cmdConfigList(c *cli.Context) (e error) {
 			return nil
 		}
 		for key, value := range section {
-			term.Printf("%s.%s = %s\n", sectionName, key, value)
+			term.Printf("%s.%s = %s\n", sectionName, key, listedValue(sectionName, key, value))
 		}
 
 		return nil
 	}
 
 	for sectionName, section := range allValues {
+		// profile settings are listed only when the profile is selected
+		if _, ok := config.ProfileName(sectionName); ok {
+			continue
+		}
 		for key, value := range section {
-			term.Printf("%s.%s = %s\n", sectionName, key, value)
+			term.Printf("%s.%s = %s\n", sectionName, key, listedValue(sectionName, key, value))
 		}
 	}
 	return nil
 }
 
+// listedValue masks secrets in config list, they are printed only by config get
+func listedValue(section, key, value string) string {
+	if config.IsSecretKey(section, key) && value != "" {
+		return maskedSecret
+	}
+	return value
+}
+
+// configProfile returns the profile selected with --section, either on the config command or as a global flag
+func configProfile(c *cli.Context) string {
+	for _, ctx := range c.Lineage() {
+		if profile := ctx.String("section"); profile != "" {
+			return profile
+		}
+	}
+	return ""
+}
+
 func parseConfigPath(c *cli.Context) (string, string, error) {
 	path := strings.Split(c.Args().First(), ".")
 	if len(path) < 2 {
@@ -144,5 +218,149 @@ func parseConfigPath(c *cli.Context) (string, string, error) {
 	}
 	section := path[0]
 	key := strings.Join(path[1:], "-")
+	if section == config.RegistriesSection {
+		// registry settings are stored as <registry>.<setting>
+		key = strings.Join(path[1:], ".")
+	}
 	return section, key, nil
 }
+
+// editFile opens the file in an editor and waits for it to exit, it is replaced in tests
+var editFile = runEditor
+
+func cmdConfigEdit(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("CONFIG EDIT START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("CONFIG EDIT FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("CONFIG EDIT ERROR: %v", e.Error())
+		}
+	}()
+	cfg := config.Get(c.Context)
+	term := terminal.Get(c.Context)
+	if !term.IsTTY() {
+		return commandFailure(errUsage, "Editing the config file requires an interactive terminal, use \"%s config set\" instead", tools.Self())
+	}
+
+	path := cfg.Path()
+	original, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return commandFailure(errConfig, "Unable to read config file: %s", err)
+	}
+	// the file is edited as a copy, so that it is replaced only once valid
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return commandFailure(errConfig, "Unable to edit config file: %s", err)
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "config-edit-*.ini")
+	if err != nil {
+		return commandFailure(errConfig, "Unable to edit config file: %s", err)
+	}
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return commandFailure(errConfig, "Unable to edit config file: %s", err)
+	}
+
+	var edited []byte
+	for {
+		if err := editFile(c.Context, tmp.Name()); err != nil {
+			return commandFailure(errConfig, "Unable to edit config file: %s. Your changes are kept in %s", err, tmp.Name())
+		}
+		if edited, err = ioutil.ReadFile(tmp.Name()); err != nil {
+			return commandFailure(errConfig, "Unable to read edited config file: %s", err)
+		}
+		problems := validateConfig(edited, packageCommandNames())
+		if len(problems) == 0 {
+			break
+		}
+		term.Writeln(color.RedString("The config file is invalid:"))
+		for _, problem := range problems {
+			term.Printf("  %s\n", problem)
+		}
+		answer, err := term.Confirm("Edit the config file again", true)
+		if err != nil || !answer {
+			return commandFailure(errConfig, "Config file not saved, your changes are kept in %s", tmp.Name())
+		}
+	}
+
+	if err := os.Remove(tmp.Name()); err != nil {
+		logger.Warnf("Unable to remove %s: %s", tmp.Name(), err)
+	}
+	if bytes.Equal(edited, original) {
+		term.Writeln("No changes made to the config file")
+		return nil
+	}
+	if err := cfg.Replace(c.Context, edited); err != nil {
+		return commandFailure(errConfig, "Unable to save config file: %s", err)
+	}
+	term.Printf("Saved %s\n", path)
+	return nil
+}
+
+func cmdConfigValidate(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("CONFIG VALIDATE START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("CONFIG VALIDATE FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("CONFIG VALIDATE ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+	path := config.Get(c.Context).Path()
+	if c.NArg() > 0 {
+		path = c.Args().First()
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && c.NArg() == 0 {
+		term.Printf("No config file, default settings are used\n")
+		return nil
+	}
+	if err != nil {
+		return commandFailure(errConfig, "Unable to read config file: %s", err)
+	}
+	problems := validateConfig(data, packageCommandNames())
+	if len(problems) == 0 {
+		term.Printf("%s is valid\n", path)
+		return nil
+	}
+	for _, problem := range problems {
+		term.Printf("%s: %s\n", path, problem)
+	}
+	return commandFailure(errConfig, "Found %d problem(s) in %s", len(problems), path)
+}
+
+// runEditor opens the file in the editor set with the VISUAL or EDITOR environment variable, vi or Notepad by default
+func runEditor(ctx context.Context, path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// the editor may be given with arguments, such as "code --wait"
+	args, err := splitAliasCommand(editor)
+	if err != nil || len(args) == 0 {
+		return fmt.Errorf("invalid editor %q", editor)
+	}
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	log.FromContext(ctx).Debugf("Running editor: %s", cmd.String())
+	return cmd.Run()
+}
@@ -1,13 +1,19 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/keyring"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -24,6 +30,41 @@ func TestCmdConfigSet(t *testing.T) {
 				m.On("Save").Return(nil).Once()
 			},
 		},
+		"set profile value": {
+			args: []string{"--section", "staging", "cli.testKey", "testValue"},
+			init: func(m *config.Mock) {
+				m.On("SetValue", "profile staging", "cli.testKey", "testValue").Return().Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"set registry value": {
+			args: []string{"registries.internal.url", "https://example.com"},
+			init: func(m *config.Mock) {
+				m.On("SetValue", "registries", "internal.url", "https://example.com").Return().Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"set secret": {
+			args: []string{"install.github-token", "ghp_abc"},
+			init: func(m *config.Mock) {
+				m.On("SetSecret", "install", "github-token", "ghp_abc").Return(nil).Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"set profile registry header": {
+			args: []string{"--section", "staging", "registries.internal.header", "Authorization: Bearer abc"},
+			init: func(m *config.Mock) {
+				m.On("SetSecret", "profile staging", "registries.internal.header", "Authorization: Bearer abc").Return(nil).Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"keyring unavailable": {
+			args: []string{"install.github-token", "ghp_abc"},
+			init: func(m *config.Mock) {
+				m.On("SetSecret", "install", "github-token", "ghp_abc").Return(fmt.Errorf("%w: secret-tool: not found", keyring.ErrUnavailable)).Once()
+			},
+			withError: "config set cli.secret-storage file",
+		},
 		"key format error": {
 			args:      []string{"cli", "testKey", "testValue"},
 			init:      func(m *config.Mock) {},
@@ -47,6 +88,7 @@ func TestCmdConfigSet(t *testing.T) {
 				Subcommands: []*cli.Command{
 					{
 						Name:   "set",
+						Flags:  []cli.Flag{&cli.StringFlag{Name: "section", Aliases: []string{"s"}}},
 						Action: cmdConfigSet,
 					},
 				},
@@ -84,6 +126,23 @@ func TestCmdConfigGet(t *testing.T) {
 				m.term.On("Writeln", []interface{}{"test val"}).Return(0, nil).Once()
 			},
 		},
+		"get profile value": {
+			args: []string{"--section", "staging", "cli.testKey"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "profile staging", "cli.testKey").Return("staging val", true).Once()
+
+				m.term.On("Writeln", []interface{}{"staging val"}).Return(0, nil).Once()
+			},
+		},
+		"profile value not set, get default value": {
+			args: []string{"--section", "staging", "cli.testKey"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "profile staging", "cli.testKey").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "testKey").Return("test val", true).Once()
+
+				m.term.On("Writeln", []interface{}{"test val"}).Return(0, nil).Once()
+			},
+		},
 		"key format error": {
 			args:      []string{"cli"},
 			init:      func(m *mocked) {},
@@ -99,6 +158,7 @@ func TestCmdConfigGet(t *testing.T) {
 				Subcommands: []*cli.Command{
 					{
 						Name:   "get",
+						Flags:  []cli.Flag{&cli.StringFlag{Name: "section", Aliases: []string{"s"}}},
 						Action: cmdConfigGet,
 					},
 				},
@@ -136,6 +196,27 @@ func TestCmdConfigUnset(t *testing.T) {
 				m.On("Save").Return(nil).Once()
 			},
 		},
+		"unset profile value": {
+			args: []string{"-s", "staging", "cli.testKey"},
+			init: func(m *config.Mock) {
+				m.On("UnsetValue", "profile staging", "cli.testKey").Return().Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"unset secret": {
+			args: []string{"install.github-token"},
+			init: func(m *config.Mock) {
+				m.On("UnsetSecret", "install", "github-token").Return(nil).Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"error removing secret": {
+			args: []string{"install.github-token"},
+			init: func(m *config.Mock) {
+				m.On("UnsetSecret", "install", "github-token").Return(fmt.Errorf("keyring error")).Once()
+			},
+			withError: "keyring error",
+		},
 		"key format error": {
 			args:      []string{"cli", "testKey"},
 			init:      func(m *config.Mock) {},
@@ -159,6 +240,7 @@ func TestCmdConfigUnset(t *testing.T) {
 				Subcommands: []*cli.Command{
 					{
 						Name:   "unset",
+						Flags:  []cli.Flag{&cli.StringFlag{Name: "section", Aliases: []string{"s"}}},
 						Action: cmdConfigUnset,
 					},
 				},
@@ -192,8 +274,9 @@ func TestCmdConfigList(t *testing.T) {
 			args: []string{},
 			init: func(m *mocked) {
 				m.cfg.On("Values").Return(map[string]map[string]string{
-					"cli":  {"key1": "val1", "key2": "val2"},
-					"test": {"key3": "val3"},
+					"cli":             {"key1": "val1", "key2": "val2"},
+					"test":            {"key3": "val3"},
+					"profile staging": {"cli.key1": "staging1"},
 				}).Once()
 				m.term.On("Printf", "%s.%s = %s\n", []interface{}{"cli", "key1", "val1"}).Return().Once()
 				m.term.On("Printf", "%s.%s = %s\n", []interface{}{"cli", "key2", "val2"}).Return().Once()
@@ -210,6 +293,37 @@ func TestCmdConfigList(t *testing.T) {
 				m.term.On("Printf", "%s.%s = %s\n", []interface{}{"test", "key3", "val3"}).Return().Once()
 			},
 		},
+		"list profile": {
+			args: []string{"--section", "staging"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"cli":             {"key1": "val1", "key2": "val2"},
+					"profile staging": {"cli.key1": "staging1", "test.key3": "staging3"},
+				}).Once()
+				m.term.On("Printf", "%s = %s\n", []interface{}{"cli.key1", "staging1"}).Return().Once()
+				m.term.On("Printf", "%s = %s\n", []interface{}{"test.key3", "staging3"}).Return().Once()
+			},
+		},
+		"list specific section of profile": {
+			args: []string{"--section", "staging", "test"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"profile staging": {"cli.key1": "staging1", "test.key3": "staging3"},
+				}).Once()
+				m.term.On("Printf", "%s = %s\n", []interface{}{"test.key3", "staging3"}).Return().Once()
+			},
+		},
+		"secrets are masked": {
+			args: []string{},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"install":    {"github-token": "ghp_abc"},
+					"registries": {"internal.header": "Authorization: Bearer abc"},
+				}).Once()
+				m.term.On("Printf", "%s.%s = %s\n", []interface{}{"install", "github-token", "********"}).Return().Once()
+				m.term.On("Printf", "%s.%s = %s\n", []interface{}{"registries", "internal.header", "********"}).Return().Once()
+			},
+		},
 		"section does not exist": {
 			args: []string{"empty"},
 			init: func(m *mocked) {
@@ -229,6 +343,7 @@ func TestCmdConfigList(t *testing.T) {
 				Subcommands: []*cli.Command{
 					{
 						Name:   "list",
+						Flags:  []cli.Flag{&cli.StringFlag{Name: "section", Aliases: []string{"s"}}},
 						Action: cmdConfigList,
 					},
 				},
@@ -251,3 +366,196 @@ func TestCmdConfigList(t *testing.T) {
 		})
 	}
 }
+
+func TestCmdConfigEdit(t *testing.T) {
+	const (
+		original = "[cli]\nchannel = beta\n"
+		edited   = "[cli]\nchannel = stable\n"
+		invalid  = "[cli]\nchanel = stable\n"
+	)
+	tests := map[string]struct {
+		edits     []string
+		init      func(*mocked, string)
+		withError string
+		kept      bool
+	}{
+		"save edited config": {
+			edits: []string{edited},
+			init: func(m *mocked, path string) {
+				m.cfg.On("Replace", []byte(edited)).Return(nil).Once()
+				m.term.On("Printf", "Saved %s\n", []interface{}{path}).Return().Once()
+			},
+		},
+		"edit again until valid": {
+			edits: []string{invalid, edited},
+			init: func(m *mocked, path string) {
+				m.term.On("Writeln", mock.Anything).Return(0, nil).Once()
+				m.term.On("Printf", "  %s\n", []interface{}{configProblem{2, "unknown setting cli.chanel, did you mean cli.channel?"}}).Return().Once()
+				m.term.On("Confirm", "Edit the config file again", true).Return(true, nil).Once()
+				m.cfg.On("Replace", []byte(edited)).Return(nil).Once()
+				m.term.On("Printf", "Saved %s\n", []interface{}{path}).Return().Once()
+			},
+		},
+		"invalid config not saved": {
+			edits: []string{invalid},
+			init: func(m *mocked, path string) {
+				m.term.On("Writeln", mock.Anything).Return(0, nil).Once()
+				m.term.On("Printf", "  %s\n", []interface{}{configProblem{2, "unknown setting cli.chanel, did you mean cli.channel?"}}).Return().Once()
+				m.term.On("Confirm", "Edit the config file again", true).Return(false, nil).Once()
+			},
+			withError: "Config file not saved, your changes are kept in",
+			kept:      true,
+		},
+		"no changes": {
+			edits: []string{original},
+			init: func(m *mocked, path string) {
+				m.term.On("Writeln", []interface{}{"No changes made to the config file"}).Return(0, nil).Once()
+			},
+		},
+		"editor fails": {
+			edits:     []string{},
+			init:      func(m *mocked, path string) {},
+			withError: "Unable to edit config file: editor failed",
+			kept:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-config-edit")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			path := filepath.Join(dir, "config")
+			require.NoError(t, ioutil.WriteFile(path, []byte(original), 0644))
+			edits := test.edits
+			editFile = func(_ context.Context, file string) error {
+				if len(edits) == 0 {
+					return fmt.Errorf("editor failed")
+				}
+				if len(edits) == len(test.edits) {
+					// the copy being edited starts with the current config
+					data, err := ioutil.ReadFile(file)
+					require.NoError(t, err)
+					assert.Equal(t, original, string(data))
+				}
+				require.NoError(t, ioutil.WriteFile(file, []byte(edits[0]), 0644))
+				edits = edits[1:]
+				return nil
+			}
+			defer func() {
+				editFile = runEditor
+			}()
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := &cli.Command{
+				Name: "config",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "edit",
+						Action: cmdConfigEdit,
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			m.term.On("IsTTY").Return(true).Once()
+			m.cfg.On("Path").Return(path).Once()
+			test.init(m, path)
+			err = app.RunContext(ctx, []string{os.Args[0], "config", "edit"})
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			copies, globErr := filepath.Glob(filepath.Join(dir, "config-edit-*"))
+			require.NoError(t, globErr)
+			assert.Equal(t, test.kept, len(copies) == 1)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdConfigValidate(t *testing.T) {
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	dir, err := ioutil.TempDir("", "cli-config-validate")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	valid := filepath.Join(dir, "valid")
+	require.NoError(t, ioutil.WriteFile(valid, []byte("[cli]\nchannel = beta\n[echo]\ngreeting = hi\n"), 0644))
+	invalid := filepath.Join(dir, "invalid")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("[cli]\nchannel = alpha\n[ehco]\ngreeting = hi\n"), 0644))
+
+	tests := map[string]struct {
+		args         []string
+		init         func(*mocked)
+		withError    string
+		withExitCode int
+	}{
+		"valid config": {
+			init: func(m *mocked) {
+				m.cfg.On("Path").Return(valid).Once()
+				m.term.On("Printf", "%s is valid\n", []interface{}{valid}).Return().Once()
+			},
+		},
+		"invalid file given": {
+			args: []string{invalid},
+			init: func(m *mocked) {
+				m.cfg.On("Path").Return(valid).Once()
+				m.term.On("Printf", "%s: %s\n", []interface{}{invalid, configProblem{2, `invalid value of cli.channel: unknown release channel "alpha", expected one of: stable, beta, nightly`}}).Return().Once()
+				m.term.On("Printf", "%s: %s\n", []interface{}{invalid, configProblem{3, "unknown section [ehco], did you mean [echo]?"}}).Return().Once()
+			},
+			withError:    "Found 2 problem(s) in " + invalid,
+			withExitCode: exitConfig,
+		},
+		"no config file": {
+			init: func(m *mocked) {
+				m.cfg.On("Path").Return(filepath.Join(dir, "missing")).Once()
+				m.term.On("Printf", "No config file, default settings are used\n", []interface{}(nil)).Return().Once()
+			},
+		},
+		"given file does not exist": {
+			args: []string{filepath.Join(dir, "missing")},
+			init: func(m *mocked) {
+				m.cfg.On("Path").Return(valid).Once()
+			},
+			withError:    "Unable to read config file",
+			withExitCode: exitConfig,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := &cli.Command{
+				Name: "config",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "validate",
+						Action: cmdConfigValidate,
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			test.init(m)
+			err := app.RunContext(ctx, append([]string{os.Args[0], "config", "validate"}, test.args...))
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				var exitErr cli.ExitCoder
+				require.True(t, errors.As(err, &exitErr))
+				assert.Equal(t, test.withExitCode, exitErr.ExitCode())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,468 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kardianos/osext"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/app"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/registry"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+type doctorStatus string
+
+const (
+	doctorOK   doctorStatus = "OK"
+	doctorWarn doctorStatus = "WARN"
+	doctorFail doctorStatus = "FAIL"
+
+	registryTimeout = 10 * time.Second
+)
+
+// doctorResult is an outcome of a single diagnostic, along with a suggested fix if there is a problem
+type doctorResult struct {
+	Status  doctorStatus
+	Message string
+	Fix     string
+}
+
+// doctorRuntime is a program used to build or run packages written in a given language
+type doctorRuntime struct {
+	name        string
+	executables []string
+	required    func(packages.LanguageRequirements) bool
+	purpose     string
+}
+
+var doctorRuntimes = []doctorRuntime{
+	{"git", []string{"git"}, func(packages.LanguageRequirements) bool { return false }, "reading credentials of private repositories from git credential helpers"},
+	{"go", []string{"go"}, func(r packages.LanguageRequirements) bool { return r.Go != "" }, "building packages written in Go"},
+	{"python", []string{"python3", "python"}, func(r packages.LanguageRequirements) bool { return r.Python != "" }, "running packages written in Python"},
+	{"node", []string{"node"}, func(r packages.LanguageRequirements) bool { return r.Node != "" }, "running packages written in JavaScript"},
+	{"ruby", []string{"ruby"}, func(r packages.LanguageRequirements) bool { return r.Ruby != "" }, "running packages written in Ruby"},
+	{"php", []string{"php"}, func(r packages.LanguageRequirements) bool { return r.Php != "" }, "running packages written in PHP"},
+}
+
+// lookPath finds executables in PATH, it can be replaced in tests
+var lookPath = exec.LookPath
+
+func cmdDoctor(langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		start := time.Now()
+		logger.Debug("DOCTOR START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("DOCTOR FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("DOCTOR ERROR: %v", e.Error())
+			}
+		}()
+		term := terminal.Get(c.Context)
+
+		sections := []struct {
+			title string
+			check func() []doctorResult
+		}{
+			{"Package directory", checkCliDirectory},
+			{"PATH", checkPathSetup},
+			{"Runtimes", checkRuntimes},
+			{"Configuration", checkConfigFile},
+			{"Package repository", func() []doctorResult { return checkPackageRepository(c.Context) }},
+			{"Installed packages", func() []doctorResult { return checkPackages(c.Context, langManager) }},
+		}
+
+		var failures, warnings int
+		for _, section := range sections {
+			results := section.check()
+			printDoctorResults(term, section.title, results)
+			for _, result := range results {
+				switch result.Status {
+				case doctorFail:
+					failures++
+				case doctorWarn:
+					warnings++
+				}
+			}
+		}
+
+		term.Writeln()
+		if failures > 0 {
+			return cli.Exit(color.RedString("Found %d problem(s) and %d warning(s), apply the suggested fixes and run \"%s doctor\" again", failures, warnings, tools.Self()), 1)
+		}
+		if warnings > 0 {
+			term.Writeln(color.YellowString("No problems found, %d warning(s)", warnings))
+			return nil
+		}
+		term.Writeln(color.GreenString("No problems found"))
+		return nil
+	}
+}
+
+func printDoctorResults(term terminal.Terminal, title string, results []doctorResult) {
+	term.Writeln(color.YellowString("\n%s:", title))
+	for _, result := range results {
+		var status string
+		switch result.Status {
+		case doctorOK:
+			status = color.GreenString("[%s]", result.Status)
+		case doctorWarn:
+			status = color.YellowString("[%s]", result.Status)
+		default:
+			status = color.RedString("[%s]", result.Status)
+		}
+		term.Printf("  %s %s\n", status, result.Message)
+		if result.Fix != "" {
+			term.Printf("    %s %s\n", color.CyanString("Fix:"), result.Fix)
+		}
+	}
+}
+
+// checkCliDirectory verifies that package directory is writable, and that it does not contain leftovers of interrupted operations
+func checkCliDirectory() []doctorResult {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return []doctorResult{{doctorFail, err.Error(), "Set AKAMAI_CLI_HOME to a writable directory"}}
+	}
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return []doctorResult{{doctorFail, err.Error(), "Set AKAMAI_CLI_HOME to a writable directory"}}
+	}
+
+	var results []doctorResult
+	info, err := os.Stat(srcPath)
+	switch {
+	case os.IsNotExist(err):
+		results = append(results, doctorResult{doctorOK, fmt.Sprintf("No packages installed yet in %s", srcPath), ""})
+	case err != nil:
+		results = append(results, doctorResult{doctorFail, fmt.Sprintf("Unable to read %s: %s", srcPath, err), fmt.Sprintf("Check permissions of %s", cliPath)})
+	case !info.IsDir():
+		results = append(results, doctorResult{doctorFail, fmt.Sprintf("%s is not a directory", srcPath), fmt.Sprintf("Remove %s, it is recreated on the next install", srcPath)})
+	default:
+		tmpFile, err := ioutil.TempFile(srcPath, ".doctor-")
+		if err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("%s is not writable: %s", srcPath, err), fmt.Sprintf("Check permissions of %s", srcPath)})
+			break
+		}
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		results = append(results, doctorResult{doctorOK, fmt.Sprintf("Packages are installed in %s", srcPath), ""})
+	}
+
+	stagingPath := filepath.Join(cliPath, stagingDirName)
+	if leftovers, _ := filepath.Glob(filepath.Join(stagingPath, "*")); len(leftovers) > 0 {
+		results = append(results, doctorResult{doctorWarn, fmt.Sprintf("%s contains files of an interrupted install or update", stagingPath),
+			fmt.Sprintf("Remove %s", stagingPath)})
+	}
+
+	for _, path := range getPackagePaths() {
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			results = append(results, doctorResult{doctorWarn, fmt.Sprintf("%s is not a package directory", path), fmt.Sprintf("Remove %s", path)})
+		}
+	}
+	return results
+}
+
+// checkPathSetup verifies that Akamai CLI can be executed from any directory
+func checkPathSetup() []doctorResult {
+	self, err := osext.Executable()
+	if err != nil {
+		return []doctorResult{{doctorWarn, fmt.Sprintf("Unable to locate %s executable: %s", tools.Self(), err), ""}}
+	}
+	found, err := lookPath(tools.Self())
+	if err != nil {
+		return []doctorResult{{doctorWarn, fmt.Sprintf("%s is not in PATH", tools.Self()),
+			fmt.Sprintf("Add %s to PATH, so that %s can be run from any directory", filepath.Dir(self), tools.Self())}}
+	}
+	if resolved, err := filepath.EvalSymlinks(found); err == nil {
+		found = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(self); err == nil {
+		self = resolved
+	}
+	if found != self {
+		return []doctorResult{{doctorWarn, fmt.Sprintf("%s in PATH resolves to %s instead of %s", tools.Self(), found, self),
+			fmt.Sprintf("Remove the other installation or move %s before %s in PATH", filepath.Dir(self), filepath.Dir(found))}}
+	}
+	return []doctorResult{{doctorOK, fmt.Sprintf("%s in PATH resolves to %s", tools.Self(), self), ""}}
+}
+
+// checkRuntimes verifies presence of programs used to build and run packages. Missing programs are a problem only if an installed package requires them.
+func checkRuntimes() []doctorResult {
+	requirements := make(map[string]packages.LanguageRequirements)
+	for _, dir := range getPackagePaths() {
+		if pkg, err := readPackage(dir); err == nil {
+			requirements[filepath.Base(dir)] = pkg.Requirements
+		}
+	}
+
+	var results []doctorResult
+	for _, runtime := range doctorRuntimes {
+		var path string
+		for _, executable := range runtime.executables {
+			if found, err := lookPath(executable); err == nil {
+				path = found
+				break
+			}
+		}
+		if path != "" {
+			results = append(results, doctorResult{doctorOK, fmt.Sprintf("%s found at %s", runtime.name, path), ""})
+			continue
+		}
+
+		var requiredBy []string
+		for name, reqs := range requirements {
+			if runtime.required(reqs) {
+				requiredBy = append(requiredBy, name)
+			}
+		}
+		if len(requiredBy) > 0 {
+			sort.Strings(requiredBy)
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("%s not found in PATH, it is required by: %s", runtime.name, strings.Join(requiredBy, ", ")),
+				fmt.Sprintf("Install %s and make sure it is in PATH", runtime.name)})
+			continue
+		}
+		results = append(results, doctorResult{doctorWarn, fmt.Sprintf("%s not found in PATH, it is needed only for %s", runtime.name, runtime.purpose), ""})
+	}
+	return results
+}
+
+// checkConfigFile verifies that the config file can be parsed and that values used by Akamai CLI itself are valid
+func checkConfigFile() []doctorResult {
+	configPath, err := tools.GetAkamaiCliConfigPath()
+	if err != nil {
+		return []doctorResult{{doctorFail, err.Error(), "Set AKAMAI_CLI_HOME to a writable directory"}}
+	}
+	path := filepath.Join(configPath, "config")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []doctorResult{{doctorOK, "No config file, default settings are used", ""}}
+	}
+
+	cfg, err := config.NewIni()
+	if err != nil {
+		return []doctorResult{{doctorFail, fmt.Sprintf("Unable to parse %s: %s", path, strings.TrimSpace(err.Error())),
+			fmt.Sprintf("Fix the syntax error or remove %s, it is recreated with default settings", path)}}
+	}
+
+	var results []doctorResult
+	if value, _ := cfg.GetValue("cli", "enable-cli-statistics"); value != "" && value != "true" && value != "false" {
+		results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.enable-cli-statistics: %q", value),
+			fmt.Sprintf("Run \"%s config set cli.enable-cli-statistics false\"", tools.Self())})
+	}
+	if value, _ := cfg.GetValue("cli", "last-upgrade-check"); value != "" && value != "never" && value != "ignore" {
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.last-upgrade-check: %q", value),
+				fmt.Sprintf("Run \"%s config unset cli.last-upgrade-check\"", tools.Self())})
+		}
+	}
+	if value, _ := cfg.GetValue("cli", "upgrade-check-interval"); value != "" {
+		if _, err := parseUpgradeCheckInterval(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.upgrade-check-interval: %s", err),
+				fmt.Sprintf("Run \"%s config set cli.upgrade-check-interval %s\"", tools.Self(), upgradeCheckDaily)})
+		}
+	}
+	if value, _ := cfg.GetValue("cli", "channel"); value != "" {
+		if _, err := upgradeChannel(config.Context(context.Background(), cfg)); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.channel: %q", value),
+				fmt.Sprintf("Run \"%s config set cli.channel %s\"", tools.Self(), channelStable)})
+		}
+	}
+	retries, _ := cfg.GetValue("cli", "retries")
+	backoff, _ := cfg.GetValue("cli", "retry-backoff")
+	timeout, _ := cfg.GetValue("cli", "request-timeout")
+	if _, err := tools.ParseRetryOptions(retries, backoff, timeout); err != nil {
+		results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid retry settings: %s", err),
+			"Set cli.retries to a number, and cli.retry-backoff and cli.request-timeout to durations such as 2s or 1m"})
+	}
+	if value, _ := cfg.GetValue("cli", "package-cache-ttl"); value != "" {
+		if _, err := parsePackageListTTL(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.package-cache-ttl: %s", err),
+				fmt.Sprintf("Run \"%s config set cli.package-cache-ttl 1h\"", tools.Self())})
+		}
+	}
+	if value, _ := cfg.GetValue("cli", "lock-timeout"); value != "" {
+		if _, err := tools.ParseLockTimeout(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.lock-timeout: %s", err),
+				fmt.Sprintf("Run \"%s config set cli.lock-timeout 1m\"", tools.Self())})
+		}
+	}
+	if value, _ := cfg.GetValue("cli", "secret-storage"); value != "" {
+		if _, err := config.ParseSecretStorage(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.secret-storage: %s", err),
+				fmt.Sprintf("Run \"%s config set cli.secret-storage keyring\"", tools.Self())})
+		}
+	}
+	if value, _ := cfg.GetValue("cli", "hook-timeout"); value != "" {
+		if _, err := parseHookTimeout(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.hook-timeout: %s", err),
+				fmt.Sprintf("Run \"%s config set cli.hook-timeout 5m\"", tools.Self())})
+		}
+	}
+	if value, _ := cfg.GetValue("cli", "install-strategy"); value != "" {
+		if _, err := parseInstallStrategy(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.install-strategy: %s", err),
+				fmt.Sprintf("Run \"%s config set cli.install-strategy auto\"", tools.Self())})
+		}
+	}
+	if value, _ := cfg.GetValue("cli", "audit-log-format"); value != "" {
+		if _, err := parseAuditLogFormat(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.audit-log-format: %s", err),
+				fmt.Sprintf("Run \"%s config set cli.audit-log-format %s\"", tools.Self(), auditFormatText)})
+		}
+	}
+	if value, _ := cfg.GetValue("cli", "telemetry"); value != "" {
+		if _, err := stats.ParseTelemetry(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.telemetry: %s", err),
+				fmt.Sprintf("Run \"%s config set cli.telemetry off\"", tools.Self())})
+		}
+	}
+	if value, _ := cfg.GetValue("install", "clone-depth"); value != "" {
+		if _, err := parseCloneDepth(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of install.clone-depth: %s", err),
+				fmt.Sprintf("Run \"%s config set install.clone-depth %d\"", tools.Self(), defaultCloneDepth)})
+		}
+	}
+	if value, _ := cfg.GetValue("install", "git-timeout"); value != "" {
+		if _, err := parseGitTimeout(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of install.git-timeout: %s", err),
+				fmt.Sprintf("Run \"%s config set install.git-timeout 10m\"", tools.Self())})
+		}
+	}
+	if value, _ := cfg.GetValue("install", "build-timeout"); value != "" {
+		if _, err := parseBuildTimeout(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of install.build-timeout: %s", err),
+				fmt.Sprintf("Run \"%s config set install.build-timeout 30m\"", tools.Self())})
+		}
+	}
+	if value, _ := cfg.GetValue("cli", "proxy"); value != "" {
+		// the value is not displayed, since it may contain proxy credentials
+		if _, err := app.ParseProxy(value); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("Invalid value of cli.proxy: %s", err),
+				fmt.Sprintf("Run \"%s config set cli.proxy http://proxy.example.com:3128\"", tools.Self())})
+		}
+	}
+	if len(results) == 0 {
+		results = append(results, doctorResult{doctorOK, fmt.Sprintf("%s is valid", path), ""})
+	}
+	return results
+}
+
+// checkPackageRepository verifies that the package list used by install and search commands can be fetched
+func checkPackageRepository(ctx context.Context) []doctorResult {
+	registries, err := registry.FromConfig(config.Get(ctx))
+	if err != nil {
+		return []doctorResult{{doctorFail, fmt.Sprintf("Invalid registries: %s", err),
+			"Fix the registries section of the config file, see README for the expected format"}}
+	}
+
+	var results []doctorResult
+	for _, reg := range registries {
+		results = append(results, checkRegistry(ctx, reg))
+	}
+	return results
+}
+
+func checkRegistry(ctx context.Context, reg packageRegistry) doctorResult {
+	logger := log.FromContext(ctx)
+	url := reg.ListURL()
+	fix := "Check your network connection. If you are behind a proxy, set it using the --proxy flag, the cli.proxy config value or the HTTPS_PROXY environment variable"
+
+	resp, err := reg.Get(ctx, registryTimeout)
+	if err != nil {
+		return doctorResult{doctorFail, fmt.Sprintf("Unable to reach %s: %s", url, err), fix}
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Error(err.Error())
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		fix := fmt.Sprintf("Make sure registries.%s.url points to a valid package repository, and registries.%s.header is set if it requires authentication", reg.Name, reg.Name)
+		if reg.Name == registry.OfficialName {
+			fix = "If AKAMAI_CLI_PACKAGE_REPO is set, make sure it points to a valid package repository"
+		}
+		return doctorResult{doctorFail, fmt.Sprintf("Unable to fetch %s: %s", url, resp.Status), fix}
+	}
+	return doctorResult{doctorOK, fmt.Sprintf("%s is reachable", url), ""}
+}
+
+// checkPackages verifies that each installed package has a valid cli.json and that executables of its commands can be found.
+// For packages run in containers, a container engine must be available instead.
+func checkPackages(ctx context.Context, langManager packages.LangManager) []doctorResult {
+	var results []doctorResult
+	for _, dir := range getPackagePaths() {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		name := filepath.Base(dir)
+		reinstall := fmt.Sprintf("Run \"%s uninstall\" and install the package again, or remove %s", tools.Self(), dir)
+		if err := validatePackage(dir); err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("%s: %s", name, err), reinstall})
+			continue
+		}
+		pkg, err := readPackage(dir)
+		if err != nil {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("%s: %s", name, err), reinstall})
+			continue
+		}
+
+		if meta, err := readInstallMetadata(dir); err == nil && meta != nil && meta.Image != "" {
+			if _, err := containerEngine(); err != nil {
+				results = append(results, doctorResult{doctorFail, fmt.Sprintf("%s: %s", name, err),
+					fmt.Sprintf("Install docker or podman, or set its location with \"%s config set cli.container-engine\"", tools.Self())})
+				continue
+			}
+			results = append(results, doctorResult{doctorOK, fmt.Sprintf("%s is healthy (runs in %s)", name, meta.Image), ""})
+			continue
+		}
+
+		var broken []string
+		for _, cmd := range executableCommands(pkg) {
+			if _, err := findExec(ctx, langManager, cmd.Name); err != nil {
+				broken = append(broken, cmd.Name)
+			}
+		}
+		if len(broken) > 0 {
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("%s: no executable found for: %s", name, strings.Join(broken, ", ")),
+				fmt.Sprintf("Run \"%s update %s\" to rebuild the package, or reinstall it", tools.Self(), broken[0])})
+			continue
+		}
+		results = append(results, doctorResult{doctorOK, fmt.Sprintf("%s is healthy", name), ""})
+	}
+	if len(results) == 0 {
+		results = append(results, doctorResult{doctorOK, "No packages installed", ""})
+	}
+	return results
+}
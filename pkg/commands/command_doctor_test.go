@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func mockLookPath(found map[string]string) func() {
+	lookPath = func(file string) (string, error) {
+		if path, ok := found[file]; ok {
+			return path, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	return func() {
+		lookPath = exec.LookPath
+	}
+}
+
+func TestCmdDoctor(t *testing.T) {
+	tests := map[string]struct {
+		registryStatus int
+		expected       [][]interface{}
+		withError      string
+	}{
+		"problems found": {
+			registryStatus: http.StatusOK,
+			expected: [][]interface{}{
+				{color.GreenString("[OK]"), "Packages are installed in testdata/.akamai-cli/src"},
+				{color.GreenString("[OK]"), "go found at /usr/bin/go"},
+				{color.RedString("[FAIL]"), "python not found in PATH, it is required by: cli-echo-python"},
+				{color.YellowString("[WARN]"), "node not found in PATH, it is needed only for running packages written in JavaScript"},
+				{color.GreenString("[OK]"), "No config file, default settings are used"},
+				{color.GreenString("[OK]"), "${REPOSITORY}/cli/package-list.json is reachable"},
+				{color.GreenString("[OK]"), "cli-echo is healthy"},
+				{color.GreenString("[OK]"), "cli-echo-python is healthy"},
+				{color.RedString("[FAIL]"), "cli-echo-invalid-json: unable to parse cli.json: invalid character 'i' looking for beginning of value"},
+				{color.RedString("[FAIL]"), "cli-installed: no executable found for: installed"},
+				{color.CyanString("Fix:"), fmt.Sprintf(`Run "%s update installed" to rebuild the package, or reinstall it`, tools.Self())},
+			},
+			withError: color.RedString("Found 3 problem(s)"),
+		},
+		"package repository not reachable": {
+			registryStatus: http.StatusNotFound,
+			expected: [][]interface{}{
+				{color.RedString("[FAIL]"), "Unable to fetch ${REPOSITORY}/cli/package-list.json: 404 Not Found"},
+			},
+			withError: color.RedString("Found 4 problem(s)"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/cli/package-list.json", r.URL.String())
+				w.WriteHeader(test.registryStatus)
+			}))
+			defer srv.Close()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_PACKAGE_REPO"))
+			}()
+			defer mockLookPath(map[string]string{"go": "/usr/bin/go"})()
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
+			command := &cli.Command{
+				Name:   "doctor",
+				Action: cmdDoctor(m.langManager),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "doctor")
+
+			m.term.On("Writeln", mock.Anything).Return(0, nil)
+			m.term.On("Printf", mock.Anything, mock.Anything).Return()
+			err := app.RunContext(ctx, args)
+
+			m.langManager.AssertExpectations(t)
+			for _, expected := range test.expected {
+				format := "  %s %s\n"
+				if expected[0] == color.CyanString("Fix:") {
+					format = "    %s %s\n"
+				}
+				m.term.AssertCalled(t, "Printf", format, []interface{}{expected[0], strings.ReplaceAll(expected[1].(string), "${REPOSITORY}", srv.URL)})
+			}
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	tests := map[string]struct {
+		config   string
+		expected []doctorResult
+	}{
+		"valid config": {
+			config:   "[cli]\nconfig-version = 1.1\nenable-cli-statistics = false\nlast-upgrade-check = 2021-09-10T10:00:00+02:00\nchannel = beta\nproxy = user:secret@proxy.example.com:3128\nretries = 5\nretry-backoff = 500ms\nrequest-timeout = 2m\npackage-cache-ttl = 24h\nhook-timeout = 10m\nlock-timeout = 30s\nsecret-storage = file\ninstall-strategy = source\nupgrade-check-interval = weekly\naudit-log-format = json\ntelemetry = on\n[install]\nclone-depth = 0\ngit-timeout = 5m\nbuild-timeout = 1h\n",
+			expected: []doctorResult{{doctorOK, "${CONFIG} is valid", ""}},
+		},
+		"invalid values": {
+			config: "[cli]\nenable-cli-statistics = maybe\nlast-upgrade-check = yesterday\nchannel = alpha\nproxy = ftp://proxy.example.com\nretries = many\npackage-cache-ttl = daily\nhook-timeout = never\nlock-timeout = forever\nsecret-storage = vault\ninstall-strategy = fastest\nupgrade-check-interval = monthly\naudit-log-format = xml\ntelemetry = maybe\n[install]\nclone-depth = -1\ngit-timeout = never\nbuild-timeout = 0s\n",
+			expected: []doctorResult{
+				{doctorFail, `Invalid value of cli.enable-cli-statistics: "maybe"`, fmt.Sprintf(`Run "%s config set cli.enable-cli-statistics false"`, tools.Self())},
+				{doctorFail, `Invalid value of cli.last-upgrade-check: "yesterday"`, fmt.Sprintf(`Run "%s config unset cli.last-upgrade-check"`, tools.Self())},
+				{doctorFail, `Invalid value of cli.upgrade-check-interval: invalid upgrade check interval "monthly", expected daily, weekly, never or a duration such as 12h`,
+					fmt.Sprintf(`Run "%s config set cli.upgrade-check-interval daily"`, tools.Self())},
+				{doctorFail, `Invalid value of cli.channel: "alpha"`, fmt.Sprintf(`Run "%s config set cli.channel stable"`, tools.Self())},
+				{doctorFail, `Invalid retry settings: invalid number of retries "many", expected a non-negative integer`,
+					"Set cli.retries to a number, and cli.retry-backoff and cli.request-timeout to durations such as 2s or 1m"},
+				{doctorFail, `Invalid value of cli.package-cache-ttl: invalid package cache TTL "daily", expected a duration such as 30m or 24h`,
+					fmt.Sprintf(`Run "%s config set cli.package-cache-ttl 1h"`, tools.Self())},
+				{doctorFail, `Invalid value of cli.lock-timeout: invalid lock timeout "forever", expected a duration such as 500ms, 2s or 1m`,
+					fmt.Sprintf(`Run "%s config set cli.lock-timeout 1m"`, tools.Self())},
+				{doctorFail, `Invalid value of cli.secret-storage: invalid secret storage "vault", expected keyring or file`,
+					fmt.Sprintf(`Run "%s config set cli.secret-storage keyring"`, tools.Self())},
+				{doctorFail, `Invalid value of cli.hook-timeout: invalid hook timeout "never", expected a duration such as 30s or 10m`,
+					fmt.Sprintf(`Run "%s config set cli.hook-timeout 5m"`, tools.Self())},
+				{doctorFail, `Invalid value of cli.install-strategy: invalid install strategy "fastest", expected auto, binary or source`,
+					fmt.Sprintf(`Run "%s config set cli.install-strategy auto"`, tools.Self())},
+				{doctorFail, `Invalid value of cli.audit-log-format: invalid audit log format "xml", expected text or json`,
+					fmt.Sprintf(`Run "%s config set cli.audit-log-format text"`, tools.Self())},
+				{doctorFail, `Invalid value of cli.telemetry: invalid telemetry setting "maybe", expected on or off`,
+					fmt.Sprintf(`Run "%s config set cli.telemetry off"`, tools.Self())},
+				{doctorFail, `Invalid value of install.clone-depth: invalid clone depth "-1", expected a number of commits, or 0 for the full history`,
+					fmt.Sprintf(`Run "%s config set install.clone-depth 1"`, tools.Self())},
+				{doctorFail, `Invalid value of install.git-timeout: invalid timeout "never", expected a duration such as 30s or 10m`,
+					fmt.Sprintf(`Run "%s config set install.git-timeout 10m"`, tools.Self())},
+				{doctorFail, `Invalid value of install.build-timeout: invalid timeout "0s", expected a duration such as 30s or 10m`,
+					fmt.Sprintf(`Run "%s config set install.build-timeout 30m"`, tools.Self())},
+				{doctorFail, `Invalid value of cli.proxy: invalid proxy: unsupported scheme "ftp"`, fmt.Sprintf(`Run "%s config set cli.proxy http://proxy.example.com:3128"`, tools.Self())},
+			},
+		},
+		"syntax error": {
+			config: "[cli\n",
+			expected: []doctorResult{
+				{doctorFail, "Unable to parse ${CONFIG}: unclosed section: [cli", "Fix the syntax error or remove ${CONFIG}, it is recreated with default settings"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "akamai-doctor")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(home))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			defer func() {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			path := filepath.Join(home, ".akamai-cli", "config")
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+			require.NoError(t, ioutil.WriteFile(path, []byte(test.config), 0600))
+
+			for i, result := range test.expected {
+				test.expected[i].Message = strings.ReplaceAll(result.Message, "${CONFIG}", path)
+				test.expected[i].Fix = strings.ReplaceAll(result.Fix, "${CONFIG}", path)
+			}
+			assert.Equal(t, test.expected, checkConfigFile())
+		})
+	}
+}
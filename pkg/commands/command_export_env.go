@@ -0,0 +1,216 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// formats of CI bootstrap scripts printed by export-env
+const (
+	exportFormatShell      = "sh"
+	exportFormatPowerShell = "powershell"
+	exportFormatGitHub     = "github"
+)
+
+// exportFormats lists the formats of export-env, the first one being the default
+var exportFormats = []string{exportFormatShell, exportFormatPowerShell, exportFormatGitHub}
+
+// exportEnvName matches names of environment variables which can be set in bootstrap scripts
+var exportEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// exportEnvDelimiter ends the JSON list of installed packages written to the step outputs of GitHub Actions
+const exportEnvDelimiter = "AKAMAI_CLI_EOF"
+
+// envVar is an environment variable set by a bootstrap script
+type envVar struct {
+	Name  string
+	Value string
+}
+
+// exportScript describes a CI bootstrap script: the environment it sets and the arguments of the install command it runs
+type exportScript struct {
+	Env     []envVar
+	Install []string
+}
+
+// cmdExportEnv prints a script bootstrapping Akamai CLI in CI: it sets the environment turning off prompts, spinners, colors
+// and upgrade checks, and installs the given packages, or the ones of a lock file
+func cmdExportEnv(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("EXPORT ENV START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("EXPORT ENV FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("EXPORT ENV ERROR: %v", e.Error())
+		}
+	}()
+
+	format := strings.ToLower(c.String("format"))
+	if format == "" {
+		format = exportFormats[0]
+	}
+	if !containsString(exportFormats, format) {
+		return commandFailure(errUsage, "Invalid format %q, expected one of: %s", c.String("format"), strings.Join(exportFormats, ", "))
+	}
+	script, err := newExportScript(c)
+	if err != nil {
+		return err
+	}
+
+	var out string
+	switch format {
+	case exportFormatPowerShell:
+		out = script.powerShell()
+	case exportFormatGitHub:
+		out = script.gitHub()
+	default:
+		out = script.shell()
+	}
+	terminal.Get(c.Context).Writeln(strings.TrimSuffix(out, "\n"))
+	return nil
+}
+
+// newExportScript returns the script described by export-env arguments and flags
+func newExportScript(c *cli.Context) (*exportScript, error) {
+	lock := c.String("lock")
+	if lock != "" && c.Args().Present() {
+		return nil, commandFailure(errUsage, "Packages cannot be given along with --lock")
+	}
+	if lock == "" && !c.Args().Present() {
+		return nil, commandFailure(errUsage, "You must specify one or more packages to install, or --lock")
+	}
+
+	script := &exportScript{
+		Env: []envVar{
+			{"AKAMAI_CLI_QUIET", "true"},
+			{"AKAMAI_CLI_NO_UPDATE_CHECK", "true"},
+			{"NO_COLOR", "true"},
+		},
+		Install: []string{"install"},
+	}
+	if edgerc := c.String("edgerc"); edgerc != "" {
+		script.Env = append(script.Env, envVar{"AKAMAI_EDGERC", edgerc})
+	}
+	if section := c.String("section"); section != "" {
+		script.Env = append(script.Env, envVar{"AKAMAI_SECTION", section})
+	}
+	for _, value := range c.StringSlice("env") {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || !exportEnvName.MatchString(parts[0]) {
+			return nil, commandFailure(errUsage, "Invalid environment variable %q, expected KEY=VALUE", value)
+		}
+		if strings.ContainsAny(parts[1], "\r\n") {
+			return nil, commandFailure(errUsage, "Value of environment variable %s cannot span several lines", parts[0])
+		}
+		script.Env = append(script.Env, envVar{parts[0], parts[1]})
+	}
+
+	if lock != "" {
+		script.Install = append(script.Install, "--from-lock", lock)
+	}
+	if c.Bool("binary-only") {
+		script.Install = append(script.Install, "--binary-only")
+	}
+	script.Install = append(script.Install, c.Args().Slice()...)
+	return script, nil
+}
+
+// header describes the script, along with how to use it in CI
+func (s *exportScript) header(usage string) string {
+	return fmt.Sprintf("# CI bootstrap generated by \"%s export-env\": installs Akamai CLI packages without prompts\n# %s\n", tools.Self(), usage)
+}
+
+// shell returns the script for POSIX shells
+func (s *exportScript) shell() string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString(s.header("Source it to keep the environment in the current shell: . ./akamai-bootstrap.sh"))
+	b.WriteString("set -e\n")
+	for _, env := range s.Env {
+		fmt.Fprintf(&b, "export %s=%s\n", env.Name, shellQuote(env.Value))
+	}
+	fmt.Fprintf(&b, "%s %s\n", tools.Self(), shellQuoteAll(s.Install))
+	return b.String()
+}
+
+// powerShell returns the script for PowerShell
+func (s *exportScript) powerShell() string {
+	var b strings.Builder
+	b.WriteString(s.header("Dot-source it to keep the environment in the current session: . .\\akamai-bootstrap.ps1"))
+	b.WriteString("$ErrorActionPreference = 'Stop'\n")
+	for _, env := range s.Env {
+		fmt.Fprintf(&b, "$env:%s = %s\n", env.Name, powerShellQuote(env.Value))
+	}
+	args := make([]string, 0, len(s.Install))
+	for _, arg := range s.Install {
+		args = append(args, powerShellQuote(arg))
+	}
+	fmt.Fprintf(&b, "& %s %s\n", tools.Self(), strings.Join(args, " "))
+	b.WriteString("if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }\n")
+	return b.String()
+}
+
+// gitHub returns the script for a run step of a GitHub Actions workflow. The environment is also written to $GITHUB_ENV for the
+// following steps, and installed packages to the packages output of the step, in the JSON format of "akamai list --json".
+func (s *exportScript) gitHub() string {
+	var b strings.Builder
+	b.WriteString(s.header("Run it in a step of a GitHub Actions workflow, the environment is kept for the following steps"))
+	b.WriteString("set -e\n")
+	for _, env := range s.Env {
+		fmt.Fprintf(&b, "export %s=%s\n", env.Name, shellQuote(env.Value))
+		fmt.Fprintf(&b, "echo %s >> \"$GITHUB_ENV\"\n", shellQuote(env.Name+"="+env.Value))
+	}
+	fmt.Fprintf(&b, "%s %s\n", tools.Self(), shellQuoteAll(s.Install))
+	b.WriteString("{\n")
+	fmt.Fprintf(&b, "  echo 'packages<<%s'\n", exportEnvDelimiter)
+	fmt.Fprintf(&b, "  %s list --installed --json\n", tools.Self())
+	fmt.Fprintf(&b, "  echo '%s'\n", exportEnvDelimiter)
+	b.WriteString("} >> \"$GITHUB_OUTPUT\"\n")
+	return b.String()
+}
+
+// shellQuote quotes s for POSIX shells, unless it only contains characters which need no quoting
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@=+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func shellQuoteAll(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// powerShellQuote quotes s as a verbatim PowerShell string
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func TestCmdExportEnv(t *testing.T) {
+	header := func(usage string) string {
+		return fmt.Sprintf("# CI bootstrap generated by \"%s export-env\": installs Akamai CLI packages without prompts\n# %s", tools.Self(), usage)
+	}
+	tests := map[string]struct {
+		args      []string
+		expected  []string
+		withError string
+	}{
+		"shell script": {
+			args: []string{"--section", "ci", "export-env", "property", "purge@v1.2.0"},
+			expected: []string{
+				"#!/bin/sh",
+				header("Source it to keep the environment in the current shell: . ./akamai-bootstrap.sh"),
+				"set -e",
+				"export AKAMAI_CLI_QUIET=true",
+				"export AKAMAI_CLI_NO_UPDATE_CHECK=true",
+				"export NO_COLOR=true",
+				"export AKAMAI_SECTION=ci",
+				tools.Self() + " install property purge@v1.2.0",
+			},
+		},
+		"PowerShell script": {
+			args: []string{"export-env", "--format", "powershell", "--binary-only", "--env", "GREETING=it's me", "property"},
+			expected: []string{
+				header("Dot-source it to keep the environment in the current session: . .\\akamai-bootstrap.ps1"),
+				"$ErrorActionPreference = 'Stop'",
+				"$env:AKAMAI_CLI_QUIET = 'true'",
+				"$env:AKAMAI_CLI_NO_UPDATE_CHECK = 'true'",
+				"$env:NO_COLOR = 'true'",
+				"$env:GREETING = 'it''s me'",
+				fmt.Sprintf("& %s 'install' '--binary-only' 'property'", tools.Self()),
+				"if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }",
+			},
+		},
+		"GitHub Actions step": {
+			args: []string{"--edgerc", "/run/secrets/edgerc", "export-env", "--format", "GitHub", "--lock", "ci/akamai packages.lock"},
+			expected: []string{
+				header("Run it in a step of a GitHub Actions workflow, the environment is kept for the following steps"),
+				"set -e",
+				"export AKAMAI_CLI_QUIET=true",
+				`echo AKAMAI_CLI_QUIET=true >> "$GITHUB_ENV"`,
+				"export AKAMAI_CLI_NO_UPDATE_CHECK=true",
+				`echo AKAMAI_CLI_NO_UPDATE_CHECK=true >> "$GITHUB_ENV"`,
+				"export NO_COLOR=true",
+				`echo NO_COLOR=true >> "$GITHUB_ENV"`,
+				"export AKAMAI_EDGERC=/run/secrets/edgerc",
+				`echo AKAMAI_EDGERC=/run/secrets/edgerc >> "$GITHUB_ENV"`,
+				tools.Self() + " install --from-lock 'ci/akamai packages.lock'",
+				"{",
+				"  echo 'packages<<AKAMAI_CLI_EOF'",
+				"  " + tools.Self() + " list --installed --json",
+				"  echo 'AKAMAI_CLI_EOF'",
+				`} >> "$GITHUB_OUTPUT"`,
+			},
+		},
+		"no packages": {
+			args:      []string{"export-env"},
+			withError: "You must specify one or more packages to install, or --lock",
+		},
+		"packages and lock file": {
+			args:      []string{"export-env", "--lock", "akamai-packages.lock", "property"},
+			withError: "Packages cannot be given along with --lock",
+		},
+		"invalid format": {
+			args:      []string{"export-env", "--format", "bat", "property"},
+			withError: `Invalid format "bat", expected one of: sh, powershell, github`,
+		},
+		"invalid environment variable": {
+			args:      []string{"export-env", "--env", "NO-VALUE", "property"},
+			withError: `Invalid environment variable "NO-VALUE", expected KEY=VALUE`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := &cli.Command{
+				Name: "export-env",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "format"},
+					&cli.StringFlag{Name: "lock"},
+					&cli.StringSliceFlag{Name: "env"},
+					&cli.BoolFlag{Name: "binary-only"},
+				},
+				Action: cmdExportEnv,
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, test.args...)
+
+			if test.expected != nil {
+				m.term.On("Writeln", []interface{}{strings.Join(test.expected, "\n")}).Return(0, nil).Once()
+			}
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]struct {
+		given    string
+		expected string
+	}{
+		"plain":        {given: "akamai-packages.lock", expected: "akamai-packages.lock"},
+		"empty":        {given: "", expected: "''"},
+		"spaces":       {given: "two words", expected: "'two words'"},
+		"single quote": {given: "it's", expected: `'it'\''s'`},
+		"variable":     {given: "$HOME", expected: "'$HOME'"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, shellQuote(test.given))
+		})
+	}
+}
@@ -15,14 +15,23 @@
 package commands
 
 import (
-	"github.com/akamai/cli/pkg/log"
+	"bytes"
+	"fmt"
 	"os"
+	"strings"
 
+	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
 )
 
 func cmdHelp(c *cli.Context) error {
 	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	if c.Bool("all") {
+		return showAllHelp(c)
+	}
 	if c.Args().Present() {
 		cmd := c.Args().First()
 
@@ -33,11 +42,129 @@ func cmdHelp(c *cli.Context) error {
 			}
 		}
 
-		// The arg mangling ensures that aliases are handled
-		os.Args = append([]string{os.Args[0], cmd, "help"}, c.Args().Tail()...)
+		if c.Args().Len() == 1 {
+			if help, ok := installedCommandDocs(c, cmd); ok {
+				return writeHelp(c, help)
+			}
+		}
+
+		// The arg mangling ensures that aliases are handled, and the package gets the same --help flag as when
+		// running "akamai <command> --help"
+		os.Args = append(append([]string{os.Args[0], cmd}, c.Args().Tail()...), "--help")
 		err := c.App.RunContext(c.Context, os.Args)
 		return err
 	}
 
 	return cli.ShowAppHelp(c)
 }
+
+// showAllHelp writes the help of the app followed by the help of every built-in and installed command.
+// Help of installed commands is made of what their cli.json and docs declare, packages are not run.
+func showAllHelp(c *cli.Context) error {
+	help, err := allHelp(c)
+	if err != nil {
+		return err
+	}
+	return writeHelp(c, help)
+}
+
+func allHelp(c *cli.Context) (string, error) {
+	var out bytes.Buffer
+	writer := c.App.Writer
+	c.App.Writer = &out
+	defer func() {
+		c.App.Writer = writer
+	}()
+
+	if err := cli.ShowAppHelp(c); err != nil {
+		return "", err
+	}
+	for _, cmd := range c.App.VisibleCommands() {
+		// builtin commands do not have Category set
+		if cmd.Category != "" {
+			continue
+		}
+		out.WriteString(helpSeparator(cmd.Name))
+		if err := cli.ShowCommandHelp(c, cmd.Name); err != nil {
+			return "", err
+		}
+	}
+	for _, dir := range getPackagePaths() {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			log.FromContext(c.Context).Debugf("Skipping help of package %s: %s", dir, err.Error())
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			docs, err := readPackageDocs(dir, cmd)
+			if err != nil {
+				log.FromContext(c.Context).Debugf("Unable to read docs of package %s: %s", dir, err.Error())
+			}
+			out.WriteString(helpSeparator(cmd.Name))
+			out.WriteString(installedCommandHelp(cmd, docs))
+		}
+	}
+	return out.String(), nil
+}
+
+// installedCommandDocs returns the help of an installed command if its package is documented, either in cli.json
+// or in its docs directory. Other commands display the help of the package itself.
+func installedCommandDocs(c *cli.Context, name string) (string, bool) {
+	appCmd := c.App.Command(name)
+	if appCmd == nil || appCmd.Category == "" {
+		return "", false
+	}
+	dir, ok := findCommandPackageDir(appCmd.Name)
+	if !ok {
+		return "", false
+	}
+	pkg, err := readPackage(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, cmd := range pkg.Commands {
+		if cmd.Name != appCmd.Name {
+			continue
+		}
+		docs, err := readPackageDocs(dir, cmd)
+		if err != nil || docs == "" {
+			return "", false
+		}
+		help := installedCommandHelp(cmd, docs)
+		help += fmt.Sprintf("Run \"%s %s --help\" for its flags and sub-commands.\n", tools.Self(), cmd.Name)
+		return help, true
+	}
+	return "", false
+}
+
+// installedCommandHelp formats what cli.json declares about an installed command like the help of built-in commands,
+// followed by its rendered documentation
+func installedCommandHelp(cmd command, docs string) string {
+	var b strings.Builder
+	b.WriteString(color.YellowString("Name: \n"))
+	fmt.Fprintf(&b, "   %s %s\n\n", tools.Self(), cmd.Name)
+	if len(cmd.Aliases) > 0 {
+		b.WriteString(color.YellowString("Aliases: \n"))
+		fmt.Fprintf(&b, "   %s\n\n", strings.Join(cmd.Aliases, ", "))
+	}
+	usage := cmd.Usage
+	if usage == "" {
+		usage = strings.TrimSpace(fmt.Sprintf("%s %s %s", tools.Self(), cmd.Name, cmd.Arguments))
+	}
+	b.WriteString(color.YellowString("Usage: \n"))
+	b.WriteString(color.BlueString("   %s\n\n", usage))
+	if cmd.Description != "" {
+		b.WriteString(color.YellowString("Description: \n"))
+		fmt.Fprintf(&b, "   %s\n\n", cmd.Description)
+	}
+	if docs != "" {
+		b.WriteString(color.YellowString("Documentation: \n"))
+		b.WriteString(renderMarkdown(docs))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func helpSeparator(name string) string {
+	return "\n" + color.HiBlackString("%s %s\n", strings.Repeat("=", 3), name) + "\n"
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 )
@@ -45,10 +47,46 @@ func TestCmdHelp(t *testing.T) {
 				Description: "test command",
 				Category:    "Installed command",
 			},
-			expectedOutput: regexp.MustCompile(`.*Name: \n.*help\n\n.*Usage: \n.*help \[command] \[sub-command]\n\n.*Description: \n.*Displays help information\n\n`),
+			expectedOutput: regexp.MustCompile(`.*Name: \n.*test\n\n.*Usage: \n.*test \[command options] \[arguments...]\n\n.*Type: \n.*Installed command\n\n.*Description: \n.*test command\n\n`),
+		},
+		"help for documented installed command": {
+			args: []string{"documented"},
+			cmd: &cli.Command{
+				Name:        "documented",
+				Description: "Documented command",
+				Category:    "Installed command",
+			},
+			expectedOutput: regexp.MustCompile(`(?s)Name: \n   \S+ documented\n\n.*Usage: \n   \S+ documented <property>\n\n.*` +
+				`Documentation: \n   Pass the name of a property\.\n\n   Examples\n\n       akamai documented www\.example\.com\n\n` +
+				`Run "\S+ documented --help" for its flags and sub-commands\.\n$`),
+		},
+		"help for all commands": {
+			args: []string{"--all"},
+			cmd: &cli.Command{
+				Name:        "test",
+				Description: "test command",
+			},
+			expectedOutput: regexp.MustCompile(`(?s)Built-In Commands:.*=== test\n\n.*Name: \n.*test\n\n.*Description: \n.*test command\n\n.*` +
+				`=== documented\n\n.*Description: \n   Documented command\n\n.*Documentation: \n   Pass the name of a property\.`),
 		},
 	}
 
+	home, err := ioutil.TempDir("", "akamai-help")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(home))
+	}()
+	pkgDir := filepath.Join(home, ".akamai-cli", "src", "cli-documented")
+	require.NoError(t, os.MkdirAll(filepath.Join(pkgDir, packageDocsDir), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, "cli.json"), []byte(`{"commands": [{
+		"name": "documented", "description": "Documented command", "arguments": "<property>", "docs": "Pass the name of a property."
+	}]}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, packageDocsDir, "examples.md"), []byte("# Examples\n\n```\nakamai documented www.example.com\n```\n"), 0644))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
@@ -59,6 +97,7 @@ func TestCmdHelp(t *testing.T) {
 				ArgsUsage:    "[command] [sub-command]",
 				Description:  "Displays help information",
 				Action:       cmdHelp,
+				Flags:        []cli.Flag{&cli.BoolFlag{Name: "all"}, &cli.BoolFlag{Name: "no-pager"}},
 				HideHelp:     true,
 				BashComplete: app.DefaultAutoComplete,
 			})
@@ -0,0 +1,126 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// cmdHistory displays the audit log of package installs, updates, uninstalls and upgrades of Akamai CLI
+func cmdHistory(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("HISTORY START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("HISTORY FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("HISTORY ERROR: %v", e.Error())
+		}
+	}()
+	if c.Int("limit") < 0 {
+		return commandFailure(errUsage, "Invalid limit: %d", c.Int("limit"))
+	}
+
+	path, err := auditLogPath()
+	if err != nil {
+		return commandFailure(errConfig, "Unable to determine audit log location: %s", err.Error())
+	}
+	entries, err := readAuditLog(c.Context, path)
+	if err != nil {
+		return commandFailure(errConfig, "Unable to read audit log: %s", err.Error())
+	}
+	entries = filterAuditEntries(entries, c.Args().Slice())
+	if limit := c.Int("limit"); limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	term := terminal.Get(c.Context)
+	if c.Bool("json") {
+		if entries == nil {
+			entries = make([]auditEntry, 0)
+		}
+		return writeJSON(term, entries)
+	}
+	if len(entries) == 0 {
+		term.Writeln(color.YellowString("No package operations recorded in %s", path))
+		return nil
+	}
+	printAuditEntries(term, entries)
+	return nil
+}
+
+// filterAuditEntries returns entries of given packages, which may be named with or without the cli- prefix, or all entries if none are given
+func filterAuditEntries(entries []auditEntry, names []string) []auditEntry {
+	if len(names) == 0 {
+		return entries
+	}
+	filtered := make([]auditEntry, 0, len(entries))
+	for _, entry := range entries {
+		for _, name := range names {
+			if strings.EqualFold(entry.Package, name) || strings.EqualFold(entry.Package, "cli-"+name) {
+				filtered = append(filtered, entry)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// printAuditEntries displays audit log entries in a table, oldest first
+func printAuditEntries(term terminal.Terminal, entries []auditEntry) {
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		status := color.GreenString(entry.Status)
+		if entry.Status == auditFailed {
+			status = color.RedString(entry.Status)
+		}
+		rows = append(rows, []string{entry.Time.Local().Format("2006-01-02 15:04:05"), entry.User, entry.Action, entry.Package, auditVersion(entry), status})
+	}
+	printTable(term, []string{"TIME", "USER", "ACTION", "PACKAGE", "VERSION", "STATUS"}, rows)
+}
+
+// auditVersion describes the version installed by an operation, along with the one it replaced if it changed.
+// The short commit hash is used for packages which do not declare a version, or whose version did not change.
+func auditVersion(entry auditEntry) string {
+	current := describeAuditVersion(entry.Version, entry.Commit)
+	previous := describeAuditVersion(entry.PreviousVersion, entry.PreviousCommit)
+	if entry.Version != "" && entry.Version == entry.PreviousVersion && entry.Commit != entry.PreviousCommit {
+		current = describeAuditVersion("", entry.Commit)
+		previous = describeAuditVersion("", entry.PreviousCommit)
+	}
+	if previous == "" || previous == current {
+		return current
+	}
+	return previous + " -> " + current
+}
+
+func describeAuditVersion(version, commit string) string {
+	if version != "" {
+		return version
+	}
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestCmdHistory(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2026, 10, d, hour, 0, 0, 0, time.Local)
+	}
+	entries := []struct {
+		entry  auditEntry
+		format string
+	}{
+		{auditEntry{Time: day(1, 9), User: "alice", Host: "jump", Action: auditInstall, Package: "cli-echo", Status: auditSucceeded,
+			Version: "1.0.0", Commit: "0100000000000000000000000000000000000000", Source: "https://github.com/akamai/cli-echo"}, auditFormatText},
+		{auditEntry{Time: day(2, 10), User: "bob", Host: "jump", Action: auditUpdate, Package: "cli-echo", Status: auditSucceeded,
+			Version: "1.1.0", PreviousVersion: "1.0.0", Source: "https://github.com/akamai/cli-echo"}, auditFormatText},
+		{auditEntry{Time: day(3, 11), User: "alice", Host: "jump", Action: auditUninstall, Package: "cli-purge", Status: auditFailed,
+			Version: "2.0.0", Error: "pre-uninstall hook failed: exit status 1"}, auditFormatJSON},
+		{auditEntry{Time: day(4, 12), User: "alice", Host: "jump", Action: auditUpgrade, Package: auditCliPackage, Status: auditSucceeded,
+			Version: "1.6.0", PreviousVersion: "1.5.0"}, auditFormatJSON},
+	}
+
+	tests := map[string]struct {
+		args      []string
+		noLog     bool
+		init      func(*terminal.Mock, string)
+		withError string
+	}{
+		"all operations": {
+			init: func(m *terminal.Mock, _ string) {
+				row := func(cells ...string) []interface{} {
+					return []interface{}{fmt.Sprintf("  %-21s%-7s%-11s%-11s%-16s%s", cells[0], cells[1], cells[2], cells[3], cells[4], cells[5])}
+				}
+				m.On("Writeln", []interface{}{color.New(color.FgWhite, color.Bold).Sprint(row("TIME", "USER", "ACTION", "PACKAGE", "VERSION", "STATUS")[0])}).Return(0, nil).Once()
+				m.On("Writeln", row("2026-10-01 09:00:00", "alice", "install", "cli-echo", "1.0.0", color.GreenString("success"))).Return(0, nil).Once()
+				m.On("Writeln", row("2026-10-02 10:00:00", "bob", "update", "cli-echo", "1.0.0 -> 1.1.0", color.GreenString("success"))).Return(0, nil).Once()
+				m.On("Writeln", row("2026-10-03 11:00:00", "alice", "uninstall", "cli-purge", "2.0.0", color.RedString("failed"))).Return(0, nil).Once()
+				m.On("Writeln", row("2026-10-04 12:00:00", "alice", "upgrade", "akamai", "1.5.0 -> 1.6.0", color.GreenString("success"))).Return(0, nil).Once()
+			},
+		},
+		"last operation of a package": {
+			args: []string{"--limit", "1", "echo"},
+			init: func(m *terminal.Mock, _ string) {
+				row := func(cells ...string) []interface{} {
+					return []interface{}{fmt.Sprintf("  %-21s%-6s%-8s%-10s%-16s%s", cells[0], cells[1], cells[2], cells[3], cells[4], cells[5])}
+				}
+				m.On("Writeln", []interface{}{color.New(color.FgWhite, color.Bold).Sprint(row("TIME", "USER", "ACTION", "PACKAGE", "VERSION", "STATUS")[0])}).Return(0, nil).Once()
+				m.On("Writeln", row("2026-10-02 10:00:00", "bob", "update", "cli-echo", "1.0.0 -> 1.1.0", color.GreenString("success"))).Return(0, nil).Once()
+			},
+		},
+		"operations in JSON format": {
+			args: []string{"--json", "cli-purge"},
+			init: func(m *terminal.Mock, _ string) {
+				m.On("Writeln", jsonOutput(fmt.Sprintf(`[{"time": %q, "user": "alice", "host": "jump", "action": "uninstall", "package": "cli-purge",
+					"status": "failed", "version": "2.0.0", "error": "pre-uninstall hook failed: exit status 1"}]`, day(3, 11).Format(time.RFC3339)))).Return(0, nil).Once()
+			},
+		},
+		"no operations recorded": {
+			noLog: true,
+			init: func(m *terminal.Mock, path string) {
+				m.On("Writeln", []interface{}{color.YellowString("No package operations recorded in %s", path)}).Return(0, nil).Once()
+			},
+		},
+		"invalid limit": {
+			args:      []string{"--limit", "-1"},
+			init:      func(m *terminal.Mock, _ string) {},
+			withError: "Invalid limit: -1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer useTempAuditLog(t)()
+			path := os.Getenv("AKAMAI_CLI_AUDIT_LOG")
+			if !test.noLog {
+				lines := make([]string, 0, len(entries)+1)
+				for i, e := range entries {
+					line, err := formatAuditEntry(e.entry, e.format)
+					require.NoError(t, err)
+					lines = append(lines, string(line))
+					if i == 1 {
+						// malformed lines are skipped
+						lines = append(lines, `time=2026-10-02T11:00:00Z user="unterminated`)
+					}
+				}
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(t, ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := &cli.Command{
+				Name:   "history",
+				Flags:  []cli.Flag{&cli.IntFlag{Name: "limit"}, &cli.BoolFlag{Name: "json"}},
+				Action: cmdHistory,
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "history")
+			args = append(args, test.args...)
+
+			test.init(m.term, path)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
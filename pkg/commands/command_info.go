@@ -0,0 +1,233 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// ways a package can be installed
+const (
+	installTypeSource    = "source"
+	installTypeBinary    = "binary"
+	installTypeContainer = "container"
+)
+
+// packageInfo describes an installed package in info command output
+type packageInfo struct {
+	Name            string        `json:"name"`
+	Path            string        `json:"path"`
+	Repo            string        `json:"repo,omitempty"`
+	Source          string        `json:"source,omitempty"`
+	Ref             string        `json:"ref,omitempty"`
+	RefType         string        `json:"ref-type,omitempty"`
+	Commit          string        `json:"commit,omitempty"`
+	InstallType     string        `json:"install-type"`
+	Image           string        `json:"image,omitempty"`
+	Language        string        `json:"language,omitempty"`
+	LanguageVersion string        `json:"language-version,omitempty"`
+	Updated         *time.Time    `json:"updated,omitempty"`
+	Commands        []infoCommand `json:"commands"`
+}
+
+// infoCommand describes a package command along with its usage, as declared in cli.json
+type infoCommand struct {
+	jsonCommand
+	Usage     string `json:"usage,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+func cmdInfo(gitRepo git.Repository) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("INFO START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("INFO FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("INFO ERROR: %v", e.Error())
+			}
+		}()
+		if c.Args().Len() != 1 {
+			return commandFailure(errUsage, "You must specify exactly one command")
+		}
+
+		cmd := c.Args().First()
+		dir, ok := findCommandPackageDir(cmd)
+		if !ok {
+			return commandFailure(errNotFound, "Command \"%s\" not found. Try \"%s list --installed\".", cmd, tools.Self())
+		}
+		info, err := readPackageInfo(gitRepo, dir)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read package of command \"%s\": %s", cmd, err.Error()), 1)
+		}
+
+		term := terminal.Get(c.Context)
+		if c.Bool("json") {
+			return writeJSON(term, info)
+		}
+		printPackageInfo(term, info)
+		return nil
+	}
+}
+
+// findCommandPackageDir returns the directory of the installed package providing given command or alias
+func findCommandPackageDir(cmd string) (string, bool) {
+	for _, dir := range getPackagePaths() {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		for _, command := range pkg.Commands {
+			if strings.EqualFold(command.Name, cmd) {
+				return dir, true
+			}
+			for _, alias := range command.Aliases {
+				if strings.EqualFold(alias, cmd) {
+					return dir, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// readPackageInfo collects information about the package installed in given directory from its cli.json,
+// its install metadata and, for packages installed before metadata was introduced, its git repository
+func readPackageInfo(gitRepo git.Repository, dir string) (*packageInfo, error) {
+	pkg, err := readPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := readInstallMetadata(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &packageInfo{Name: filepath.Base(dir), Path: dir, InstallType: installTypeSource, Commands: make([]infoCommand, 0)}
+	info.Language, info.LanguageVersion = pkg.Requirements.Language()
+	if meta != nil {
+		info.Repo, info.Source, info.Commit = meta.Repo, meta.Source, meta.Commit
+		info.Ref, info.RefType = meta.Ref, meta.RefType
+		if info.Ref == "" && meta.DefaultBranch != "" {
+			info.Ref, info.RefType = meta.DefaultBranch, refTypeBranch
+		}
+	}
+	if info.Commit == "" && info.Source == "" {
+		if err := gitRepo.Open(dir); err == nil {
+			if head, err := gitRepo.Head(); err == nil {
+				info.Commit = head.Hash().String()
+				if info.Ref == "" && head.Name().IsBranch() {
+					info.Ref, info.RefType = head.Name().Short(), refTypeBranch
+				}
+			}
+		}
+	}
+	if updated, ok := packageUpdateTime(dir); ok {
+		info.Updated = &updated
+	}
+	if meta != nil && meta.Image != "" {
+		info.InstallType, info.Image = installTypeContainer, meta.Image
+	}
+
+	for _, cmd := range pkg.Commands {
+		if info.Image == "" && binaryInstalled(dir, cmd) {
+			info.InstallType = installTypeBinary
+		}
+		info.Commands = append(info.Commands, infoCommand{
+			jsonCommand: commandsToJSON([]command{cmd})[0],
+			Usage:       cmd.Usage,
+			Arguments:   cmd.Arguments,
+		})
+	}
+	return info, nil
+}
+
+// binaryInstalled returns true if the binary of the command has been downloaded, instead of being built from source
+func binaryInstalled(dir string, cmd command) bool {
+	_, err := os.Stat(filepath.Join(dir, "bin", "akamai-"+strings.ToLower(cmd.Name)+tools.BinSuffix()))
+	return err == nil
+}
+
+// packageUpdateTime returns when the package was last installed or updated, which is when its install metadata was written.
+// The modification time of the package directory is used for packages without install metadata.
+func packageUpdateTime(dir string) (time.Time, bool) {
+	for _, path := range []string{filepath.Join(dir, installMetadataFile), dir} {
+		if stat, err := os.Stat(path); err == nil {
+			return stat.ModTime(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func printPackageInfo(term terminal.Terminal, info *packageInfo) {
+	bold := color.New(color.FgWhite, color.Bold)
+	field := func(label, value string) {
+		if value != "" {
+			term.Printf(bold.Sprintf("  %s:", label)+" %s\n", value)
+		}
+	}
+
+	term.Printf(color.GreenString("Package: ")+"%s\n", info.Name)
+	field("Path", info.Path)
+	if info.Source != "" {
+		field("Source", info.Source)
+	} else {
+		field("Repository", info.Repo)
+	}
+	if info.Ref != "" {
+		field("Ref", fmt.Sprintf("%s (%s)", info.Ref, info.RefType))
+	}
+	field("Commit", info.Commit)
+	field("Install type", info.InstallType)
+	field("Image", info.Image)
+	if info.Language != "" {
+		field("Language", strings.TrimSpace(info.Language+" "+info.LanguageVersion))
+	}
+	if info.Updated != nil {
+		field("Last update", info.Updated.Format(time.RFC1123))
+	}
+
+	term.Printf("\n" + color.YellowString("Commands:") + "\n")
+	for _, cmd := range info.Commands {
+		term.Printf(bold.Sprintf("  %s", cmd.Name))
+		printCommandAliases(term, cmd.Aliases)
+		term.Writeln()
+		for _, f := range []struct{ label, value string }{
+			{"Version", cmd.Version},
+			{"Description", cmd.Description},
+			{"Usage", cmd.Usage},
+			{"Arguments", cmd.Arguments},
+		} {
+			if f.value != "" {
+				term.Printf("    %s %s\n", bold.Sprintf("%s:", f.label), f.value)
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func TestCmdInfo(t *testing.T) {
+	master := plumbing.NewBranchReferenceName("master")
+	hash := plumbing.NewHash("0100000000000000000000000000000000000000")
+	bold := color.New(color.FgWhite, color.Bold)
+
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		withError string
+	}{
+		"package without install metadata": {
+			args: []string{"ac2"},
+			init: func(m *mocked) {
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-installed").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(master, hash), nil).Once()
+				m.term.On("Printf", color.GreenString("Package: ")+"%s\n", []interface{}{"cli-installed"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("  %s:", "Path")+" %s\n", []interface{}{"testdata/.akamai-cli/src/cli-installed"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("  %s:", "Ref")+" %s\n", []interface{}{"master (branch)"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("  %s:", "Commit")+" %s\n", []interface{}{hash.String()}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("  %s:", "Install type")+" %s\n", []interface{}{"source"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("  %s:", "Language")+" %s\n", []interface{}{"go 1.14.0"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("  %s:", "Last update")+" %s\n", mock.Anything).Return().Once()
+				m.term.On("Printf", "\n"+color.YellowString("Commands:")+"\n", []interface{}(nil)).Return().Once()
+				m.term.On("Printf", bold.Sprintf("  installed"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", " (%s: ", []interface{}{"alias"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("ac2"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", ")", []interface{}(nil)).Return().Once()
+				m.term.On("Writeln", []interface{}(nil)).Return(0, nil).Once()
+				m.term.On("Printf", "    %s %s\n", []interface{}{bold.Sprintf("Description:"), "Test command"}).Return().Once()
+			},
+		},
+		"binary package in JSON format": {
+			args: []string{"--json", "echo-python"},
+			init: func(m *mocked) {
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-python").Return(errors.New("not a git repository")).Once()
+				m.term.On("Writeln", mock.MatchedBy(func(args []interface{}) bool {
+					return assert.Contains(t, args[0], `"install-type": "binary"`) &&
+						assert.Contains(t, args[0], `"language": "python"`) &&
+						assert.Contains(t, args[0], `"language-version": "3.0.0"`) &&
+						assert.NotContains(t, args[0], `"commit"`)
+				})).Return(0, nil).Once()
+			},
+		},
+		"command not found": {
+			args:      []string{"abc"},
+			init:      func(m *mocked) {},
+			withError: `Command "abc" not found. Try "` + tools.Self() + ` list --installed".`,
+		},
+		"no command": {
+			args:      []string{},
+			init:      func(m *mocked) {},
+			withError: "You must specify exactly one command",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, nil}
+			command := &cli.Command{
+				Name:   "info",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "json"}},
+				Action: cmdInfo(m.gitRepo),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "info")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReadPackageInfoWithMetadata(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-info")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(`{
+		"requirements": {"node": "16.0.0"},
+		"commands": [{"name": "Test", "version": "1.2.0", "usage": "test [flags]", "arguments": "<name>"}]
+	}`), 0644))
+	require.NoError(t, writeInstallMetadata(dir, &installMetadata{
+		Repo:    "https://github.com/akamai/cli-test.git",
+		Ref:     "v1.2.0",
+		RefType: refTypeTag,
+		Commit:  "0200000000000000000000000000000000000000",
+	}))
+
+	info, err := readPackageInfo(&git.Mock{}, dir)
+	require.NoError(t, err)
+	require.NotNil(t, info.Updated)
+	assert.WithinDuration(t, time.Now(), *info.Updated, time.Minute)
+	info.Updated = nil
+	assert.Equal(t, &packageInfo{
+		Name:            filepath.Base(dir),
+		Path:            dir,
+		Repo:            "https://github.com/akamai/cli-test.git",
+		Ref:             "v1.2.0",
+		RefType:         refTypeTag,
+		Commit:          "0200000000000000000000000000000000000000",
+		InstallType:     installTypeSource,
+		Language:        "javascript",
+		LanguageVersion: "16.0.0",
+		Commands: []infoCommand{{
+			jsonCommand: jsonCommand{Name: "test", Version: "1.2.0"},
+			Usage:       "test [flags]",
+			Arguments:   "<name>",
+		}},
+	}, info)
+}
@@ -26,6 +26,9 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 
 	"github.com/akamai/cli/pkg/git"
 	"github.com/akamai/cli/pkg/packages"
@@ -48,26 +51,71 @@ func cmdInstall(git git.Repository, langManager packages.LangManager) cli.Action
 			if e == nil {
 				logger.Debugf("INSTALL FINISH: %v", time.Now().Sub(start))
 			} else {
-				var exitErr cli.ExitCoder
-				if errors.As(e, &exitErr) && exitErr.ExitCode() == 0 {
+				if isWarning(e) {
 					logger.Warnf("INSTALL WARN: %v", e.Error())
 				} else {
 					logger.Errorf("INSTALL ERROR: %v", e.Error())
 				}
 			}
 		}()
+		var stop func()
+		c.Context, stop = cancelOnSignal(c.Context)
+		defer stop()
+		defer func() {
+			e = canceledError(c.Context, e)
+		}()
+		if c.Bool("skip-verify") {
+			c.Context = withSkipVerify(c.Context)
+		}
+		if c.Bool("no-hooks") {
+			c.Context = withoutHooks(c.Context)
+		}
+		if c.Bool("container") {
+			c.Context = withContainerMode(c.Context)
+		}
+		strategy, err := installStrategyFromFlags(c)
+		if err != nil {
+			return commandFailure(errUsage, err.Error())
+		}
+		c.Context = withInstallStrategy(c.Context, strategy)
+		timeout, err := timeoutFromFlags(c)
+		if err != nil {
+			return commandFailure(errUsage, err.Error())
+		}
+		c.Context = withTimeout(c.Context, timeout)
+		if c.Bool("from-lock") {
+			if isDryRun(c) {
+				return planInstallFromLock(c)
+			}
+			return installFromLock(c, git, langManager)
+		}
 		if !c.Args().Present() {
-			return cli.Exit(color.RedString("You must specify a repository URL"), 1)
+			return commandFailure(errUsage, "You must specify a repository URL")
+		}
+		if isDryRun(c) {
+			return planInstall(c)
 		}
 
 		oldCmds := getCommands(c)
+		defer updateLockFile(c.Context)
+		defer updateCommandShims(c.Context)
 
-		for _, repo := range c.Args().Slice() {
-			repo = tools.Githubize(repo)
-			subCmd, err := installPackage(c.Context, git, langManager, repo, c.Bool("force"))
+		if c.Int("concurrency") > 1 {
+			return installPackagesConcurrently(c, langManager, oldCmds)
+		}
+
+		resolver := newDependencyResolver(git, langManager, c.Bool("force"))
+		for _, arg := range c.Args().Slice() {
+			if err := c.Context.Err(); err != nil {
+				return err
+			}
+			repo, subCmd, err := installPackageArg(c.Context, git, langManager, arg, c.Bool("force"), resolver)
+			for _, dep := range resolver.takeInstalled() {
+				c.App.Commands = append(c.App.Commands, subcommandToCliCommands(dep, git, langManager)...)
+			}
 			if err != nil {
 				// Only track public github repos
-				if isPublicRepo(repo) {
+				if repo != "" && isPublicRepo(repo) {
 					stats.TrackEvent(c.Context, "package.install", "failed", repo)
 				}
 				return err
@@ -75,7 +123,7 @@ func cmdInstall(git git.Repository, langManager packages.LangManager) cli.Action
 			c.App.Commands = append(c.App.Commands, subcommandToCliCommands(*subCmd, git, langManager)...)
 			sortCommands(c.App.Commands)
 
-			if isPublicRepo(repo) {
+			if repo != "" && isPublicRepo(repo) {
 				stats.TrackEvent(c.Context, "package.install", "success", repo)
 			}
 		}
@@ -86,7 +134,239 @@ func cmdInstall(git git.Repository, langManager packages.LangManager) cli.Action
 	}
 }
 
+// installFromLock installs packages recorded in the lock file at their locked commits.
+// Packages already installed at the locked commit are left untouched.
+func installFromLock(c *cli.Context, gitRepo git.Repository, langManager packages.LangManager) error {
+	logger := log.FromContext(c.Context)
+	term := terminal.Get(c.Context)
+
+	if c.Args().Len() > 1 {
+		return commandFailure(errUsage, "Only one lock file can be specified")
+	}
+	path := c.Args().First()
+	if path == "" {
+		var err error
+		if path, err = defaultLockFilePath(); err != nil {
+			return commandFailure(errConfig, "Unable to determine CLI home directory")
+		}
+	}
+	lock, err := readLockFile(path)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to read lock file: %s", err.Error()), 1)
+	}
+
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return err
+	}
+
+	oldCmds := getCommands(c)
+	defer updateLockFile(c.Context)
+	defer updateCommandShims(c.Context)
+
+	for _, pkg := range lock.Packages {
+		if err := c.Context.Err(); err != nil {
+			return err
+		}
+		if err := pkg.validate(); err != nil {
+			return commandFailure(errUsage, "Invalid lock file: %s", err.Error())
+		}
+		source, fromSource := pkg.source()
+
+		packageDir := filepath.Join(srcPath, pkg.Name)
+		if !fromSource {
+			packageDir = filepath.Join(srcPath, packageDirName(pkg.Repo))
+		}
+		if _, err := os.Stat(packageDir); err == nil {
+			meta, err := readInstallMetadata(packageDir)
+			if err != nil || !pkg.isInstalled(meta) {
+				return commandFailure(errAlreadyInstalled, "Package \"%s\" is already installed in a different version. To install the locked version, first run 'akamai uninstall' command.", pkg.Name)
+			}
+			term.Printf("Package %s already installed at the locked commit\n", color.CyanString(pkg.Name))
+			continue
+		}
+
+		ctx := c.Context
+		if pkg.Image != "" {
+			ctx = withContainerMode(ctx)
+		}
+		if fromSource {
+			subCmd, err := installLockedSourcePackage(ctx, langManager, pkg, source, packageDir, c.Bool("force"))
+			if err != nil {
+				return err
+			}
+			c.App.Commands = append(c.App.Commands, subcommandToCliCommands(*subCmd, gitRepo, langManager)...)
+			sortCommands(c.App.Commands)
+			continue
+		}
+		subCmd, err := installPackage(ctx, gitRepo, langManager, pkg.Repo, pkg.Commit, c.Bool("force"), nil)
+		if err != nil {
+			if isPublicRepo(pkg.Repo) {
+				stats.TrackEvent(c.Context, "package.install", "failed", pkg.Repo)
+			}
+			return err
+		}
+
+		// the installed package is removed if its binaries are not the locked ones, unless verification is skipped,
+		// for example when binaries built from source depend on the local toolchain
+		mismatched, err := mismatchedBinaries(packageDir, pkg.Binaries)
+		if err != nil {
+			logger.Errorf("Unable to verify package binaries: %s", err.Error())
+		} else if len(mismatched) > 0 {
+			msg := fmt.Sprintf("Checksums of \"%s\" package binaries do not match the lock file: %s", pkg.Name, strings.Join(mismatched, ", "))
+			if !skipVerify(c.Context) {
+				if err := os.RemoveAll(packageDir); err != nil {
+					logger.Errorf("Unable to remove package directory: %s", err.Error())
+				}
+				if isPublicRepo(pkg.Repo) {
+					stats.TrackEvent(c.Context, "package.install", "failed", pkg.Repo)
+				}
+				return commandFailure(errBuild, "%s. The package has been removed, use --skip-verify to install it anyway", msg)
+			}
+			logger.Warn(msg)
+			term.Writeln(color.YellowString(msg))
+		}
+
+		c.App.Commands = append(c.App.Commands, subcommandToCliCommands(*subCmd, gitRepo, langManager)...)
+		sortCommands(c.App.Commands)
+
+		if isPublicRepo(pkg.Repo) {
+			stats.TrackEvent(c.Context, "package.install", "success", pkg.Repo)
+		}
+	}
+
+	packageListDiff(c, oldCmds)
+
+	return nil
+}
+
+// installLockedSourcePackage installs a package recorded in the lock file with its source, such as an archive or a Mercurial
+// repository. Mercurial repositories are checked out at the locked changeset, while the installed package is removed
+// if its archive or binaries do not match the lock file, unless verification is skipped.
+func installLockedSourcePackage(ctx context.Context, langManager packages.LangManager, pkg lockedPackage, source packageSource, packageDir string, forceBinary bool) (*subcommands, error) {
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+	subCmd, err := installSourcePackage(ctx, langManager, source, forceBinary, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatched []string
+	if meta, err := readInstallMetadata(packageDir); err != nil || !pkg.isInstalled(meta) {
+		mismatched = append(mismatched, source.String())
+	}
+	binaries, err := mismatchedBinaries(packageDir, pkg.Binaries)
+	if err != nil {
+		logger.Errorf("Unable to verify package binaries: %s", err.Error())
+	}
+	mismatched = append(mismatched, binaries...)
+	if len(mismatched) == 0 {
+		return subCmd, nil
+	}
+
+	msg := fmt.Sprintf("Checksums of \"%s\" package do not match the lock file: %s", pkg.Name, strings.Join(mismatched, ", "))
+	if !skipVerify(ctx) {
+		if err := os.RemoveAll(packageDir); err != nil {
+			logger.Errorf("Unable to remove package directory: %s", err.Error())
+		}
+		return nil, commandFailure(errBuild, "%s. The package has been removed, use --skip-verify to install it anyway", msg)
+	}
+	logger.Warn(msg)
+	term.Writeln(color.YellowString(msg))
+	return subCmd, nil
+}
+
+// installPackagesConcurrently installs packages given as arguments using a pool of workers.
+// Output of all installations is displayed when they are done, in the order of arguments.
+func installPackagesConcurrently(c *cli.Context, langManager packages.LangManager, oldCmds []subcommands) error {
+	term := terminal.Get(c.Context)
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return err
+	}
+
+	type installTask struct {
+		*packageTask
+		repo    string
+		subCmds []subcommands
+	}
+	var tasks []*installTask
+	claims := newPackageClaims()
+	for _, arg := range c.Args().Slice() {
+		// the same package given twice would be installed into the same directory by two workers
+		dir := arg
+		if source, ok := localPackageSource(arg); ok {
+			if dir, err = filepath.Abs(source); err != nil {
+				return err
+			}
+		} else {
+			repo, _ := splitPackageRef(arg)
+			dir = filepath.Join(srcPath, packageDirName(resolvePackageRepo(c.Context, repo)))
+		}
+		if !claims.claim(dir) {
+			continue
+		}
+		tasks = append(tasks, &installTask{packageTask: newPackageTask(arg)})
+	}
+
+	term.Spinner().Start("Installing %d package(s)...", len(tasks))
+	runConcurrently(len(tasks), c.Int("concurrency"), func(i int) {
+		task := tasks[i]
+		ctx := terminal.Context(c.Context, task.out)
+		gitRepo := newGitRepository()
+		resolver := newDependencyResolver(gitRepo, langManager, c.Bool("force"))
+		resolver.claims = claims
+		repo, subCmd, err := installPackageArg(ctx, gitRepo, langManager, task.name, c.Bool("force"), resolver)
+		task.repo, task.subCmds, task.err = repo, resolver.takeInstalled(), err
+		if subCmd != nil {
+			task.subCmds = append(task.subCmds, *subCmd)
+		}
+	})
+
+	results := make([]*packageTask, 0, len(tasks))
+	failed, skipped := 0, 0
+	for _, task := range tasks {
+		results = append(results, task.packageTask)
+		for _, subCmd := range task.subCmds {
+			c.App.Commands = append(c.App.Commands, subcommandToCliCommands(subCmd, newGitRepository(), langManager)...)
+		}
+		if task.failed() {
+			failed++
+		} else if isWarning(task.err) {
+			skipped++
+		}
+		// Only track public github repos
+		if task.repo != "" && isPublicRepo(task.repo) {
+			status := "success"
+			if task.failed() {
+				status = "failed"
+			}
+			stats.TrackEvent(c.Context, "package.install", status, task.repo)
+		}
+	}
+	sortCommands(c.App.Commands)
+
+	if failed > 0 {
+		term.Spinner().Fail()
+	} else {
+		term.Spinner().OK()
+	}
+	printTaskResults(term, results)
+	packageListDiff(c, oldCmds)
+
+	if failed > 0 {
+		return commandFailure(failureKind(results), "Unable to install %d of %d package(s)", failed, len(tasks))
+	}
+	if skipped > 0 {
+		return commandWarning(errAlreadyInstalled, "%d of %d package(s) already installed", skipped, len(tasks))
+	}
+	return nil
+}
+
 func packageListDiff(c *cli.Context, oldcmds []subcommands) {
+	if quietOutput(c.Context) {
+		return
+	}
 	cmds := getCommands(c)
 
 	var old []command
@@ -134,14 +414,59 @@ func packageListDiff(c *cli.Context, oldcmds []subcommands) {
 		}
 	}
 
-	listInstalledCommands(c, added, removed)
+	listInstalledCommands(c, listFilter{}, added, removed)
 }
 
 func isPublicRepo(repo string) bool {
 	return !strings.Contains(repo, ":") || strings.HasPrefix(repo, "https://github.com/")
 }
 
-func installPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, repo string, forceBinary bool) (*subcommands, error) {
+// isAkamaiPackage returns true if the package is installed from a repository of Akamai, for which no third-party disclaimer is displayed
+func isAkamaiPackage(repo string) bool {
+	return strings.HasPrefix(repo, "https://github.com/akamai/cli-") || strings.HasPrefix(repo, "git@github.com:akamai/cli-")
+}
+
+// installPackageArg installs a package given as install argument, either from a git repository, or from another source such as
+// a local directory, an archive or a Mercurial repository. The returned repository is empty for packages not installed from git.
+func installPackageArg(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, arg string, forceBinary bool, resolver *dependencyResolver) (string, *subcommands, error) {
+	if source, ok := parsePackageSource(arg); ok {
+		subCmd, err := installSourcePackage(ctx, langManager, source, forceBinary, resolver)
+		return "", subCmd, err
+	}
+
+	repo, ref := splitPackageRef(arg)
+	repo = resolvePackageRepo(ctx, repo)
+	subCmd, err := installPackage(ctx, gitRepo, langManager, repo, ref, forceBinary, resolver)
+	return repo, subCmd, err
+}
+
+// packageDirName returns name of the directory the package from given repository is installed in
+func packageDirName(repo string) string {
+	return strings.TrimSuffix(filepath.Base(repo), ".git")
+}
+
+// splitPackageRef splits package argument in form of <repository>@<ref> into repository and git reference
+func splitPackageRef(pkg string) (string, string) {
+	i := strings.LastIndex(pkg, "@")
+	if i <= 0 || i == len(pkg)-1 {
+		return pkg, ""
+	}
+	repo, ref := pkg[:i], pkg[i+1:]
+
+	// "@" separates user from host in SSH and HTTP URLs
+	if strings.Contains(ref, ":") {
+		return pkg, ""
+	}
+	if scheme := strings.Index(repo, "://"); scheme != -1 && !strings.Contains(repo[scheme+3:], "/") {
+		return pkg, ""
+	}
+
+	return repo, ref
+}
+
+// installPackage clones the package repository and builds the package.
+// If resolver is provided, dependencies declared in cli.json are installed before building the package.
+func installPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, repo, ref string, forceBinary bool, resolver *dependencyResolver) (_ *subcommands, e error) {
 	logger := log.FromContext(ctx)
 	srcPath, err := tools.GetAkamaiCliSrcPath()
 	if err != nil {
@@ -154,15 +479,22 @@ func installPackage(ctx context.Context, gitRepo git.Repository, langManager pac
 
 	spin.Start("Attempting to fetch command from %s...", repo)
 
-	dirName := strings.TrimSuffix(filepath.Base(repo), ".git")
-	packageDir := filepath.Join(srcPath, dirName)
+	packageDir := filepath.Join(srcPath, packageDirName(repo))
+	defer func() {
+		// the package directory is removed if the installation fails
+		entry := auditPackage(auditInstall, packageDir)
+		entry.Source = repo
+		recordAudit(ctx, entry, e)
+	}()
 	if _, err = os.Stat(packageDir); err == nil {
 		spin.Stop(terminal.SpinnerStatusWarn)
 		warningMsg := fmt.Sprintf("Package directory already exists (%s). To reinstall this package, first run 'akamai uninstall' command.", packageDir)
-		return nil, cli.Exit(color.YellowString(warningMsg), 0)
+		return nil, commandWarning(errAlreadyInstalled, warningMsg)
 	}
 
-	err = gitRepo.Clone(ctx, packageDir, repo, false, spin)
+	err = runWithTimeout(ctx, "git clone", gitTimeout(ctx), func(ctx context.Context) error {
+		return gitRepo.Clone(ctx, packageDir, repo, false, cloneDepth(ctx, ref), spin)
+	})
 	if err != nil {
 		if err := os.RemoveAll(packageDir); err != nil {
 			return nil, err
@@ -170,23 +502,152 @@ func installPackage(ctx context.Context, gitRepo git.Repository, langManager pac
 		spin.Stop(terminal.SpinnerStatusFail)
 
 		errorMsg := "Unable to clone repository: " + err.Error()
+		var timeoutErr *timeoutError
+		if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) {
+			errorMsg += ". To install from a private repository, set an access token with 'akamai config set install.github-token' or configure a git credential helper"
+		} else if errors.As(err, &timeoutErr) {
+			errorMsg += ", set install.git-timeout or --timeout to allow more time"
+		}
+		logger.Error(errorMsg)
+		return nil, commandFailure(errNetwork, errorMsg)
+	}
+
+	meta, err := newInstallMetadata(gitRepo, repo, ref)
+	if err != nil {
+		if err := os.RemoveAll(packageDir); err != nil {
+			return nil, err
+		}
+		spin.Stop(terminal.SpinnerStatusFail)
+
+		errorMsg := "Unable to read repository: " + err.Error()
+		if ref != "" {
+			errorMsg = fmt.Sprintf("Unable to check out \"%s\": %s", ref, err.Error())
+		}
 		logger.Error(errorMsg)
 		return nil, cli.Exit(color.RedString(errorMsg), 1)
 	}
 	spin.OK()
 
-	if !strings.HasPrefix(repo, "https://github.com/akamai/cli-") && !strings.HasPrefix(repo, "git@github.com:akamai/cli-") {
+	if !quietOutput(ctx) && !isAkamaiPackage(repo) {
 		term.Printf(color.CyanString(thirdPartyDisclaimer))
 	}
 
-	ok, subCmd := installPackageDependencies(ctx, langManager, packageDir, forceBinary, logger)
+	if resolver != nil {
+		if err := resolver.resolve(ctx, packageDir); err != nil {
+			if err := os.RemoveAll(packageDir); err != nil {
+				return nil, err
+			}
+			return nil, err
+		}
+	}
+
+	subCmd, err := buildInstalledPackage(ctx, langManager, packageDir, forceBinary, map[string]string{"AKAMAI_CLI_COMMIT": meta.Commit})
+	if err != nil {
+		return nil, err
+	}
+
+	if containerMode(ctx) {
+		meta.Image = subCmd.Image
+	}
+	if err := writeInstallMetadata(packageDir, meta); err != nil {
+		logger.Errorf("Unable to save install metadata: %s", err.Error())
+	}
+
+	return subCmd, nil
+}
+
+// newInstallMetadata checks out requested ref, if any, and collects install metadata of a freshly cloned package
+func newInstallMetadata(gitRepo git.Repository, repo, ref string) (*installMetadata, error) {
+	meta := &installMetadata{Repo: repo}
+	head, err := gitRepo.Head()
+	if err != nil {
+		return nil, err
+	}
+	if head.Name().IsBranch() {
+		meta.DefaultBranch = head.Name().Short()
+	}
+
+	if ref != "" {
+		refType, err := checkoutPackageRef(gitRepo, head, ref)
+		if err != nil {
+			return nil, err
+		}
+		meta.Ref, meta.RefType = ref, refType
+
+		if head, err = gitRepo.Head(); err != nil {
+			return nil, err
+		}
+	}
+	meta.Commit = head.Hash().String()
+
+	return meta, nil
+}
+
+// checkoutPackageRef checks out given branch, tag or commit and returns the type of the reference
+func checkoutPackageRef(gitRepo git.Repository, head *plumbing.Reference, ref string) (string, error) {
+	w, err := gitRepo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	if remoteRef, err := gitRepo.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, ref)); err == nil {
+		branch := plumbing.NewBranchReferenceName(ref)
+		if head.Name() == branch {
+			return refTypeBranch, nil
+		}
+		return refTypeBranch, gitRepo.Checkout(w, &gogit.CheckoutOptions{Branch: branch, Hash: remoteRef.Hash(), Create: true})
+	}
+
+	refType := refTypeCommit
+	if _, err := gitRepo.Reference(plumbing.NewTagReferenceName(ref)); err == nil {
+		refType = refTypeTag
+	}
+	hash, err := gitRepo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return "", fmt.Errorf("no branch, tag or commit found")
+	}
+
+	return refType, gitRepo.Checkout(w, &gogit.CheckoutOptions{Hash: *hash})
+}
+
+// buildInstalledPackage runs the pre-install hook, builds the package copied to its final location and runs the post-install hook.
+// In container mode, the image of the package is pulled instead.
+// The package directory is removed if any of the steps fails.
+func buildInstalledPackage(ctx context.Context, langManager packages.LangManager, dir string, forceBinary bool, env map[string]string) (*subcommands, error) {
+	logger := log.FromContext(ctx)
+	hookFailed := func(err error) (*subcommands, error) {
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, err
+		}
+		return nil, commandFailure(errBuild, "Unable to install selected package, %s. Use --no-hooks to install it without running hooks", err.Error())
+	}
+	if err := runPackageHook(ctx, dir, hookPreInstall, env); err != nil {
+		return hookFailed(err)
+	}
+
+	if containerMode(ctx) {
+		subCmd, err := pullPackageImage(ctx, dir)
+		if err != nil {
+			logger.Error(err.Error())
+			if err := os.RemoveAll(dir); err != nil {
+				return nil, err
+			}
+			return nil, commandFailure(errBuild, "Unable to install selected package, %s", err.Error())
+		}
+		return subCmd, nil
+	}
+
+	ok, subCmd := installPackageDependencies(ctx, langManager, dir, forceBinary, logger)
 	if !ok {
-		if err := os.RemoveAll(packageDir); err != nil {
+		if err := os.RemoveAll(dir); err != nil {
 			return nil, err
 		}
-		return nil, cli.Exit("Unable to install selected package", 1)
+		return nil, commandFailure(errBuild, "Unable to install selected package")
 	}
 
+	if err := runPackageHook(ctx, dir, hookPostInstall, env); err != nil {
+		return hookFailed(err)
+	}
 	return subCmd, nil
 }
 
@@ -195,7 +656,8 @@ func installPackageDependencies(ctx context.Context, langManager packages.LangMa
 
 	term := terminal.Get(ctx)
 
-	term.Spinner().Start("Installing...")
+	spin := term.Spinner()
+	spin.Start("Installing...")
 	if err != nil {
 		term.Spinner().Stop(terminal.SpinnerStatusFail)
 		term.Writeln(err.Error())
@@ -203,12 +665,31 @@ func installPackageDependencies(ctx context.Context, langManager packages.LangMa
 		return false, nil
 	}
 
+	if installStrategy(ctx) == installStrategyBinary {
+		return installPackageBinaries(ctx, dir, cmdPackage, logger)
+	}
+
 	var commands []string
-	for _, cmd := range cmdPackage.Commands {
+	for _, cmd := range executableCommands(cmdPackage) {
 		commands = append(commands, cmd.Name)
 	}
 
-	err = langManager.Install(ctx, dir, cmdPackage.Requirements, commands)
+	err = runWithTimeout(ctx, "build", buildTimeout(ctx), func(ctx context.Context) error {
+		return langManager.Install(packages.WithProgress(withBuildCache(ctx), spin), dir, cmdPackage.Requirements, commands)
+	})
+	if ctx.Err() != nil {
+		// do not fall back to binaries once the installation is canceled
+		term.Spinner().Stop(terminal.SpinnerStatusFail)
+		logger.Warn("Installation canceled")
+		return false, nil
+	}
+	var timeoutErr *timeoutError
+	if errors.As(err, &timeoutErr) {
+		// binaries are not offered instead, so that the stalled build is reported
+		term.Spinner().Stop(terminal.SpinnerStatusFail)
+		term.Writeln(color.RedString("Package %s, set install.build-timeout or --timeout to allow more time", err.Error()))
+		return false, nil
+	}
 	if errors.Is(err, packages.ErrUnknownLang) {
 		term.Spinner().WarnOK()
 		warnMsg := "Package installed successfully, however package type is unknown, and may or may not function correctly."
@@ -222,8 +703,15 @@ func installPackageDependencies(ctx context.Context, langManager packages.LangMa
 		return true, &cmdPackage
 	}
 
+	if installStrategy(ctx) == installStrategySource {
+		term.Spinner().Stop(terminal.SpinnerStatusFail)
+		term.Writeln(color.RedString(err.Error()))
+		logger.Errorf("%s, not falling back to binaries with source-only install strategy", err.Error())
+		return false, nil
+	}
+
 	first := true
-	for _, cmd := range cmdPackage.Commands {
+	for _, cmd := range executableCommands(cmdPackage) {
 		if cmd.Bin != "" {
 			if first {
 				first = false
@@ -254,7 +742,7 @@ func installPackageDependencies(ctx context.Context, langManager packages.LangMa
 				term.Spinner().Start("Downloading binary...")
 			}
 
-			if err = downloadBin(ctx, filepath.Join(dir, "bin"), cmd); err != nil {
+			if err = downloadBin(ctx, filepath.Join(dir, "bin"), cmd, spin); err != nil {
 				term.Spinner().Stop(terminal.SpinnerStatusFail)
 				errorMsg := "Unable to download binary: " + err.Error()
 				term.Writeln(color.RedString(errorMsg))
@@ -275,3 +763,41 @@ func installPackageDependencies(ctx context.Context, langManager packages.LangMa
 
 	return true, &cmdPackage
 }
+
+// installPackageBinaries downloads binaries of all package commands instead of building the package from source.
+// The installation fails if any of the commands does not publish a binary.
+func installPackageBinaries(ctx context.Context, dir string, cmdPackage subcommands, logger log.Logger) (bool, *subcommands) {
+	term := terminal.Get(ctx)
+
+	var missing []string
+	for _, cmd := range executableCommands(cmdPackage) {
+		if cmd.Bin == "" {
+			missing = append(missing, cmd.Name)
+		}
+	}
+	if len(missing) > 0 {
+		term.Spinner().Stop(terminal.SpinnerStatusFail)
+		errorMsg := fmt.Sprintf("Unable to install binaries, no binary published for: %s", strings.Join(missing, ", "))
+		term.Writeln(color.RedString(errorMsg))
+		logger.Error(errorMsg)
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "bin"), 0700); err != nil {
+		term.Spinner().Stop(terminal.SpinnerStatusFail)
+		logger.Error(err.Error())
+		return false, nil
+	}
+	for _, cmd := range executableCommands(cmdPackage) {
+		if err := downloadBin(ctx, filepath.Join(dir, "bin"), cmd, term.Spinner()); err != nil {
+			term.Spinner().Stop(terminal.SpinnerStatusFail)
+			errorMsg := "Unable to download binary: " + err.Error()
+			term.Writeln(color.RedString(errorMsg))
+			logger.Error(errorMsg)
+			return false, nil
+		}
+	}
+
+	term.Spinner().Stop(terminal.SpinnerStatusOK)
+	return true, &cmdPackage
+}
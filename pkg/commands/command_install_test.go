@@ -1,11 +1,14 @@
 package commands
 
 import (
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -13,20 +16,27 @@ import (
 	"github.com/akamai/cli/pkg/git"
 	"github.com/akamai/cli/pkg/packages"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 )
 
 func TestCmdInstall(t *testing.T) {
+	defer useTempAuditLog(t)()
 	tests := map[string]struct {
 		args                 []string
 		init                 func(*testing.T, *mocked)
 		teardown             func(*testing.T)
 		binaryResponseStatus int
+		binaryChecksum       string
 		withError            string
+		withExitCode         int
 	}{
 		"install from official akamai repository, build from source": {
 			args: []string{"test-cmd"},
@@ -35,10 +45,11 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
-					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
 						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
 					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
 				m.term.On("OK").Return().Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
@@ -68,7 +79,7 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
-					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
 						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
 						input, err := ioutil.ReadFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json")
@@ -77,6 +88,7 @@ func TestCmdInstall(t *testing.T) {
 						err = ioutil.WriteFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json", []byte(output), 0755)
 						require.NoError(t, err)
 					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -92,6 +104,105 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Confirm", "Binary command(s) found, would you like to download and install it?", true).Return(true, nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Downloading binary...", []interface{}(nil)).Return().Once()
+				m.term.On("Write", mock.AnythingOfType("[]uint8")).Return(0, nil)
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				// list all packages
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+			},
+			binaryResponseStatus: http.StatusOK,
+			binaryChecksum:       "93a0b24644f2e0fd11d6b422c90275c482b0cc20be4a4e3f62148ed2932b4792",
+			teardown: func(t *testing.T) {
+				info, err := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd/bin/akamai-app-1-cmd-1")
+				require.NoError(t, err)
+				assert.NotZero(t, info.Mode()&0100)
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+			},
+		},
+		"install from official akamai repository, download binary without checksum": {
+			args: []string{"test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+						input, err := ioutil.ReadFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json")
+						require.NoError(t, err)
+						output := strings.ReplaceAll(string(input), "${REPOSITORY_URL}", os.Getenv("REPOSITORY_URL"))
+						err = ioutil.WriteFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json", []byte(output), 0755)
+						require.NoError(t, err)
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(fmt.Errorf("oops")).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.term.On("Stop", terminal.SpinnerStatusWarn).Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString("oops")}).Return(0, nil).Once()
+				m.term.On("IsTTY").Return(true).Once()
+				m.term.On("Confirm", "Binary command(s) found, would you like to download and install it?", true).Return(true, nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Downloading binary...", []interface{}(nil)).Return().Once()
+				m.term.On("Write", mock.AnythingOfType("[]uint8")).Return(0, nil)
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				// list all packages
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+			},
+			binaryResponseStatus: http.StatusOK,
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+			},
+			withError:    "Unable to install selected package",
+			withExitCode: exitBuild,
+		},
+		"install from official akamai repository, download binary without checksum, skip verification": {
+			args: []string{"--skip-verify", "test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+						input, err := ioutil.ReadFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json")
+						require.NoError(t, err)
+						output := strings.ReplaceAll(string(input), "${REPOSITORY_URL}", os.Getenv("REPOSITORY_URL"))
+						err = ioutil.WriteFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json", []byte(output), 0755)
+						require.NoError(t, err)
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(fmt.Errorf("oops")).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.term.On("Stop", terminal.SpinnerStatusWarn).Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString("oops")}).Return(0, nil).Once()
+				m.term.On("IsTTY").Return(true).Once()
+				m.term.On("Confirm", "Binary command(s) found, would you like to download and install it?", true).Return(true, nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Downloading binary...", []interface{}(nil)).Return().Once()
+				m.term.On("Write", mock.AnythingOfType("[]uint8")).Return(0, nil)
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
@@ -114,12 +225,14 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Stop", terminal.SpinnerStatusWarn).Return().Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
 			},
-			withError: color.RedString("Package directory already exists ("),
+			withError:    color.RedString("Package directory already exists ("),
+			withExitCode: exitAlreadyInstalled,
 		},
 		"no args passed": {
-			args:      []string{},
-			init:      func(t *testing.T, m *mocked) {},
-			withError: "You must specify a repository URL",
+			args:         []string{},
+			init:         func(t *testing.T, m *mocked) {},
+			withError:    "You must specify a repository URL",
+			withExitCode: exitUsage,
 		},
 		"git clone error": {
 			args: []string{"test-cmd"},
@@ -128,14 +241,175 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
-					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(fmt.Errorf("oops")).Once().
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(fmt.Errorf("oops")).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			withError:    "Unable to clone repository: oops",
+			withExitCode: exitNetwork,
+		},
+		"git clone error, authentication required": {
+			args: []string{"test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(transport.ErrAuthenticationRequired).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			withError: "Unable to clone repository: authentication required. To install from a private repository, set an access token",
+		},
+		"install a specific tag": {
+			args: []string{"test-cmd@v1.0.0"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				tagHash := plumbing.Hash{2}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Reference", plumbing.NewRemoteReferenceName(git.DefaultRemoteName, "v1.0.0")).Return(nil, plumbing.ErrReferenceNotFound).Once()
+				m.gitRepo.On("Reference", plumbing.NewTagReferenceName("v1.0.0")).Return(plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.0.0"), tagHash), nil).Once()
+				m.gitRepo.On("ResolveRevision", plumbing.Revision("v1.0.0")).Return(&tagHash, nil).Once()
+				m.gitRepo.On("Checkout", worktree, &gogit.CheckoutOptions{Hash: tagHash}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, tagHash), nil).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				// list all packages
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+			},
+			teardown: func(t *testing.T) {
+				meta, err := readInstallMetadata("./testdata/.akamai-cli/src/cli-test-cmd")
+				require.NoError(t, err)
+				assert.Equal(t, &installMetadata{
+					Repo:          "https://github.com/akamai/cli-test-cmd.git",
+					DefaultBranch: "master",
+					Ref:           "v1.0.0",
+					RefType:       refTypeTag,
+					Commit:        plumbing.Hash{2}.String(),
+				}, meta)
+				lock, err := readLockFile("./testdata/.akamai-cli/" + lockFileName)
+				require.NoError(t, err)
+				assert.Contains(t, lock.Packages, lockedPackage{
+					Name:   "cli-test-cmd",
+					Repo:   "https://github.com/akamai/cli-test-cmd.git",
+					Commit: plumbing.Hash{2}.String(),
+				})
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+			},
+		},
+		"unknown ref": {
+			args: []string{"test-cmd@abc"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
 						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
 					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Reference", plumbing.NewRemoteReferenceName(git.DefaultRemoteName, "abc")).Return(nil, plumbing.ErrReferenceNotFound).Once()
+				m.gitRepo.On("Reference", plumbing.NewTagReferenceName("abc")).Return(nil, plumbing.ErrReferenceNotFound).Once()
+				m.gitRepo.On("ResolveRevision", plumbing.Revision("abc")).Return(nil, plumbing.ErrReferenceNotFound).Once()
 				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
 			},
-			withError: "Unable to clone repository: oops",
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd")
+				assert.True(t, os.IsNotExist(err))
+			},
+			withError: `Unable to check out "abc": no branch, tag or commit found`,
+		},
+		"install package with dependencies": {
+			args: []string{"test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo_dependencies/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Twice()
+				m.term.On("OK").Return()
+				m.term.On("Writeln", []interface{}{color.YellowString("Package cli-test-cmd requires echo >=2.0.0, but installed version is unknown")}).Return(0, nil).Once()
+				m.term.On("Printf", "Installing %s, required by %s\n", []interface{}{color.CyanString("cli-dep"), color.CyanString("cli-test-cmd")}).Return().Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-dep.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-dep",
+					"https://github.com/akamai/cli-dep.git", false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-dep")
+					})
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Twice()
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-dep",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				// list all packages
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+			},
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-dep/cli.json")
+				assert.NoError(t, err)
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-dep"))
+			},
+		},
+		"dependency cycle": {
+			args: []string{"test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo_dependencies/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Twice()
+				m.term.On("OK").Return()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-dep.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-dep",
+					"https://github.com/akamai/cli-dep.git", false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo_dependency_cycle/cli.json", "./testdata/.akamai-cli/src/cli-dep")
+					})
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+			},
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd")
+				assert.True(t, os.IsNotExist(err))
+				_, err = os.Stat("./testdata/.akamai-cli/src/cli-dep")
+				assert.True(t, os.IsNotExist(err))
+			},
+			withError: "Dependency cycle detected: cli-test-cmd -> cli-dep -> cli-test-cmd",
 		},
 		"error reading downloaded package, invalid cli.json": {
 			args: []string{"test-invalid-json"},
@@ -143,10 +417,11 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-invalid-json.git"}).Return().Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-invalid-json",
-					"https://github.com/akamai/cli-test-invalid-json.git", false, m.term).Return(nil).Once().
+					"https://github.com/akamai/cli-test-invalid-json.git", false, defaultCloneDepth, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
 						copyFile(t, "./testdata/repo_invalid_json/cli.json", "./testdata/.akamai-cli/src/cli-test-invalid-json")
 					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -169,10 +444,11 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
-					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
 						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
 					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -199,7 +475,7 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
-					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
 						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
 						input, err := ioutil.ReadFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json")
@@ -208,6 +484,7 @@ func TestCmdInstall(t *testing.T) {
 						err = ioutil.WriteFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json", []byte(output), 0755)
 						require.NoError(t, err)
 					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -238,10 +515,11 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
-					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
 						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
 					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -276,7 +554,7 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
-					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
 						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
 						input, err := ioutil.ReadFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json")
@@ -285,6 +563,7 @@ func TestCmdInstall(t *testing.T) {
 						err = ioutil.WriteFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json", []byte(output), 0755)
 						require.NoError(t, err)
 					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -313,13 +592,121 @@ func TestCmdInstall(t *testing.T) {
 				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
 			},
 		},
+		"binary only, download binary without building": {
+			args: []string{"--binary-only", "test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+						input, err := ioutil.ReadFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json")
+						require.NoError(t, err)
+						output := strings.ReplaceAll(string(input), "${REPOSITORY_URL}", os.Getenv("REPOSITORY_URL"))
+						err = ioutil.WriteFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json", []byte(output), 0755)
+						require.NoError(t, err)
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Write", mock.AnythingOfType("[]uint8")).Return(0, nil)
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				// list all packages
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+			},
+			binaryResponseStatus: http.StatusOK,
+			binaryChecksum:       "93a0b24644f2e0fd11d6b422c90275c482b0cc20be4a4e3f62148ed2932b4792",
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd/bin/akamai-app-1-cmd-1")
+				require.NoError(t, err)
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+			},
+		},
+		"binary only, no binary published": {
+			args: []string{"--binary-only", "test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo_no_binary/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.term.On("Writeln", []interface{}{color.RedString("Unable to install binaries, no binary published for: app-1-cmd-1")}).Return(0, nil).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd")
+				assert.True(t, os.IsNotExist(err))
+			},
+			withError: "Unable to install selected package",
+		},
+		"source only, no fallback to binary": {
+			args: []string{"--source-only", "test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(fmt.Errorf("oops")).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.term.On("Writeln", []interface{}{color.RedString("oops")}).Return(0, nil).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd")
+				assert.True(t, os.IsNotExist(err))
+			},
+			withError: "Unable to install selected package",
+		},
+		"binary only and source only": {
+			args:      []string{"--binary-only", "--source-only", "test-cmd"},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: "flags --binary-only and --source-only cannot be used together",
+		},
+		"invalid install strategy in config": {
+			args: []string{"test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_INSTALL_STRATEGY", "fastest"))
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_INSTALL_STRATEGY"))
+			},
+			withError: `invalid install strategy "fastest", expected auto, binary or source`,
+		},
 		"error on install from source, binary does not exist": {
 			args: []string{"test-cmd"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
-					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					"https://github.com/akamai/cli-test-cmd.git", false, defaultCloneDepth, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
 						copyFile(t, "./testdata/repo_no_binary/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
 						input, err := ioutil.ReadFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json")
@@ -328,6 +715,7 @@ func TestCmdInstall(t *testing.T) {
 						err = ioutil.WriteFile("./testdata/.akamai-cli/src/cli-test-cmd/cli.json", []byte(output), 0755)
 						require.NoError(t, err)
 					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -356,18 +744,32 @@ func TestCmdInstall(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				assert.Equal(t, "/akamai/cli-test-command/releases/download/1.0.0/akamai-app-1-cmd-1", r.URL.String())
 				assert.Equal(t, http.MethodGet, r.Method)
-				w.WriteHeader(test.binaryResponseStatus)
-				_, err := w.Write([]byte(`binary content`))
-				assert.NoError(t, err)
+				switch r.URL.String() {
+				case "/akamai/cli-test-command/releases/download/1.0.0/akamai-app-1-cmd-1":
+					w.WriteHeader(test.binaryResponseStatus)
+					_, err := w.Write([]byte(`binary content`))
+					assert.NoError(t, err)
+				case "/akamai/cli-test-command/releases/download/1.0.0/akamai-app-1-cmd-1.sha256":
+					if test.binaryChecksum == "" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_, err := w.Write([]byte(test.binaryChecksum + "  akamai-app-1-cmd-1"))
+					assert.NoError(t, err)
+				default:
+					t.Errorf("unexpected request: %s", r.URL)
+				}
 			}))
 			defer srv.Close()
 			require.NoError(t, os.Setenv("REPOSITORY_URL", srv.URL))
 			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
 			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
+			m.cfg.On("GetValue", "install", "clone-depth").Return("", false).Maybe()
 			command := &cli.Command{
 				Name:   "install",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "skip-verify"}, &cli.BoolFlag{Name: "binary-only"}, &cli.BoolFlag{Name: "source-only"}},
 				Action: cmdInstall(m.gitRepo, m.langManager),
 			}
 			app, ctx := setupTestApp(command, m)
@@ -380,8 +782,449 @@ func TestCmdInstall(t *testing.T) {
 			if test.teardown != nil {
 				test.teardown(t)
 			}
+			require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/"+lockFileName))
 
 			m.cfg.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				if test.withExitCode != 0 {
+					var exitErr cli.ExitCoder
+					require.True(t, errors.As(err, &exitErr))
+					assert.Equal(t, test.withExitCode, exitErr.ExitCode())
+				}
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSplitPackageRef(t *testing.T) {
+	tests := map[string]struct {
+		given        string
+		expectedRepo string
+		expectedRef  string
+	}{
+		"package name":            {given: "property", expectedRepo: "property"},
+		"package name with tag":   {given: "property@v1.3.0", expectedRepo: "property", expectedRef: "v1.3.0"},
+		"github repo with branch": {given: "akamai/cli-property@feature/abc", expectedRepo: "akamai/cli-property", expectedRef: "feature/abc"},
+		"ssh url":                 {given: "git@github.com:akamai/cli-property.git", expectedRepo: "git@github.com:akamai/cli-property.git"},
+		"ssh url with ref":        {given: "git@github.com:akamai/cli-property.git@v1", expectedRepo: "git@github.com:akamai/cli-property.git", expectedRef: "v1"},
+		"https url with user":     {given: "https://user@github.com/akamai/cli-property.git", expectedRepo: "https://user@github.com/akamai/cli-property.git"},
+		"https url with ref":      {given: "https://github.com/akamai/cli-property.git@master", expectedRepo: "https://github.com/akamai/cli-property.git", expectedRef: "master"},
+		"empty ref":               {given: "property@", expectedRepo: "property@"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo, ref := splitPackageRef(test.given)
+			assert.Equal(t, test.expectedRepo, repo)
+			assert.Equal(t, test.expectedRef, ref)
+		})
+	}
+}
+
+func TestCmdInstallFromLock(t *testing.T) {
+	defer useTempAuditLog(t)()
+	lockedHash := plumbing.Hash{3}
+	binary := []byte("binary content")
+	binaryChecksum := fmt.Sprintf("%x", sha256.Sum256(binary))
+	sourceDir, err := filepath.Abs("./testdata/repo")
+	require.NoError(t, err)
+	archive := filepath.Join(t.TempDir(), "cli-archived.tar.gz")
+	require.NoError(t, tools.CreateTarGz(sourceDir, archive, "cli-archived", nil))
+	archiveChecksum, err := fileChecksum(archive)
+	require.NoError(t, err)
+	expectLockedArchiveInstall := func(t *testing.T, m *mocked) {
+		m.term.On("Spinner").Return(m.term)
+		m.term.On("Start", "Attempting to install package from %s...", []interface{}{archive}).Return().Once()
+		m.term.On("OK").Return()
+		m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+		m.langManager.On("Install", "testdata/.akamai-cli/src/cli-archived",
+			packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+
+		// list all packages
+		m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+		m.term.On("Writeln", mock.Anything).Return(0, nil)
+	}
+	expectLockedInstall := func(t *testing.T, m *mocked) {
+		worktree := &gogit.Worktree{}
+		m.term.On("Spinner").Return(m.term)
+		m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+		m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+			"https://github.com/akamai/cli-test-cmd.git", false, 0, m.term).Return(nil).Once().
+			Run(func(args mock.Arguments) {
+				copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+			})
+		m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Once()
+		m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+		m.gitRepo.On("Reference", plumbing.NewRemoteReferenceName(git.DefaultRemoteName, lockedHash.String())).Return(nil, plumbing.ErrReferenceNotFound).Once()
+		m.gitRepo.On("Reference", plumbing.NewTagReferenceName(lockedHash.String())).Return(nil, plumbing.ErrReferenceNotFound).Once()
+		m.gitRepo.On("ResolveRevision", plumbing.Revision(lockedHash.String())).Return(&lockedHash, nil).Once()
+		m.gitRepo.On("Checkout", worktree, &gogit.CheckoutOptions{Hash: lockedHash}).Return(nil).Once()
+		m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, lockedHash), nil).Once()
+		m.term.On("OK").Return()
+		m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+		m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+			packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once().
+			Run(func(args mock.Arguments) {
+				bin := filepath.Join(args.String(0), "bin", "akamai-app-1-cmd-1")
+				require.NoError(t, os.MkdirAll(filepath.Dir(bin), 0755))
+				require.NoError(t, ioutil.WriteFile(bin, binary, 0755))
+			})
+		m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+		// list all packages
+		m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+		m.term.On("Writeln", mock.Anything).Return(0, nil)
+	}
+	tests := map[string]struct {
+		args      []string
+		lock      *lockFile
+		init      func(*testing.T, *mocked)
+		teardown  func(*testing.T)
+		withError string
+	}{
+		"install package at locked commit": {
+			lock: &lockFile{Version: lockFileVersion, Packages: []lockedPackage{
+				{Name: "cli-test-cmd", Repo: "https://github.com/akamai/cli-test-cmd.git", Commit: lockedHash.String(),
+					Binaries: map[string]string{"bin/akamai-app-1-cmd-1": binaryChecksum}},
+			}},
+			init: func(t *testing.T, m *mocked) {
+				expectLockedInstall(t, m)
+			},
+			teardown: func(t *testing.T) {
+				meta, err := readInstallMetadata("./testdata/.akamai-cli/src/cli-test-cmd")
+				require.NoError(t, err)
+				assert.Equal(t, lockedHash.String(), meta.Commit)
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+			},
+		},
+		"binaries do not match the lock file": {
+			lock: &lockFile{Version: lockFileVersion, Packages: []lockedPackage{
+				{Name: "cli-test-cmd", Repo: "https://github.com/akamai/cli-test-cmd.git", Commit: lockedHash.String(),
+					Binaries: map[string]string{"bin/akamai-app-1-cmd-1": "abc"}},
+			}},
+			init: func(t *testing.T, m *mocked) {
+				expectLockedInstall(t, m)
+			},
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd")
+				assert.True(t, os.IsNotExist(err), "package should be removed")
+			},
+			withError: `Checksums of "cli-test-cmd" package binaries do not match the lock file: bin/akamai-app-1-cmd-1. The package has been removed`,
+		},
+		"binaries do not match the lock file with skip verify": {
+			args: []string{"--skip-verify"},
+			lock: &lockFile{Version: lockFileVersion, Packages: []lockedPackage{
+				{Name: "cli-test-cmd", Repo: "https://github.com/akamai/cli-test-cmd.git", Commit: lockedHash.String(),
+					Binaries: map[string]string{"bin/akamai-app-1-cmd-1": "abc"}},
+			}},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Writeln", []interface{}{color.YellowString(`Checksums of "cli-test-cmd" package binaries do not match the lock file: bin/akamai-app-1-cmd-1`)}).Return(0, nil).Once()
+				expectLockedInstall(t, m)
+			},
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd/cli.json")
+				assert.NoError(t, err)
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+			},
+		},
+		"package installed in a different version": {
+			lock: &lockFile{Version: lockFileVersion, Packages: []lockedPackage{
+				{Name: "cli-echo", Repo: "https://github.com/akamai/cli-echo.git", Commit: lockedHash.String()},
+			}},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: `Package "cli-echo" is already installed in a different version`,
+		},
+		"invalid package entry": {
+			lock: &lockFile{Version: lockFileVersion, Packages: []lockedPackage{
+				{Name: "cli-test-cmd", Repo: "https://github.com/akamai/cli-test-cmd.git"},
+			}},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: `Invalid lock file: package "cli-test-cmd" does not specify repository and commit`,
+		},
+		"install package from locked archive": {
+			lock: &lockFile{Version: lockFileVersion, Packages: []lockedPackage{
+				{Name: "cli-archived", Source: archive, SourceType: sourceArchive, Checksum: archiveChecksum},
+			}},
+			init: func(t *testing.T, m *mocked) {
+				expectLockedArchiveInstall(t, m)
+			},
+			teardown: func(t *testing.T) {
+				meta, err := readInstallMetadata("./testdata/.akamai-cli/src/cli-archived")
+				require.NoError(t, err)
+				assert.Equal(t, &installMetadata{Source: archive, SourceType: sourceArchive, Checksum: archiveChecksum}, meta)
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-archived"))
+			},
+		},
+		"archive does not match the lock file": {
+			lock: &lockFile{Version: lockFileVersion, Packages: []lockedPackage{
+				{Name: "cli-archived", Source: archive, SourceType: sourceArchive, Checksum: "abc"},
+			}},
+			init: func(t *testing.T, m *mocked) {
+				expectLockedArchiveInstall(t, m)
+			},
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-archived")
+				assert.True(t, os.IsNotExist(err), "package should be removed")
+			},
+			withError: fmt.Sprintf(`Checksums of "cli-archived" package do not match the lock file: %s. The package has been removed`, archive),
+		},
+		"source package without revision": {
+			lock: &lockFile{Version: lockFileVersion, Packages: []lockedPackage{
+				{Name: "cli-archived", Source: archive, SourceType: sourceArchive},
+			}},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: `Invalid lock file: package "cli-archived" does not specify the revision of its source`,
+		},
+		"invalid package name": {
+			lock: &lockFile{Version: lockFileVersion, Packages: []lockedPackage{
+				{Name: "../cli-archived", Source: archive, SourceType: sourceArchive, Checksum: archiveChecksum},
+			}},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: `Invalid lock file: invalid package name "../cli-archived"`,
+		},
+		"lock file not found": {
+			init:      func(t *testing.T, m *mocked) {},
+			withError: "Unable to read lock file",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			lockPath := "./testdata/.akamai-cli/" + lockFileName
+			if test.lock != nil {
+				require.NoError(t, writeLockFile(lockPath, test.lock))
+			}
+			defer func() {
+				require.NoError(t, os.RemoveAll(lockPath))
+			}()
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
+			m.cfg.On("GetValue", "install", "clone-depth").Return("", false).Maybe()
+			command := &cli.Command{
+				Name:   "install",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "from-lock"}, &cli.BoolFlag{Name: "skip-verify"}},
+				Action: cmdInstall(m.gitRepo, m.langManager),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "install", "--from-lock")
+			args = append(args, test.args...)
+
+			test.init(t, m)
+			err := app.RunContext(ctx, args)
+			if test.teardown != nil {
+				test.teardown(t)
+			}
+
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdInstallConcurrently(t *testing.T) {
+	defer useTempAuditLog(t)()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+	m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
+	m.cfg.On("GetValue", "install", "clone-depth").Return("", false).Maybe()
+	newGitRepository = func() git.Repository { return m.gitRepo }
+	defer func() {
+		newGitRepository = git.NewRepository
+		require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+		require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd-2"))
+		require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/"+lockFileName))
+	}()
+	command := &cli.Command{
+		Name:   "install",
+		Flags:  []cli.Flag{&cli.IntFlag{Name: "concurrency"}},
+		Action: cmdInstall(m.gitRepo, m.langManager),
+	}
+	app, ctx := setupTestApp(command, m)
+
+	for _, name := range []string{"cli-test-cmd", "cli-test-cmd-2"} {
+		repo := "https://github.com/akamai/" + name + ".git"
+		dir := "testdata/.akamai-cli/src/" + name
+		m.gitRepo.On("Clone", dir, repo, false, defaultCloneDepth, mock.Anything).Return(nil).Once().
+			Run(func(args mock.Arguments) {
+				copyFile(t, "./testdata/repo/cli.json", dir)
+			})
+		m.langManager.On("Install", dir, packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+		m.term.On("Printf", "%s", []interface{}{
+			"Attempting to fetch command from " + repo + "... " + string(terminal.SpinnerStatusOK) +
+				"Installing... " + string(terminal.SpinnerStatusOK),
+		}).Return().Once()
+	}
+	m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{1}), nil).Times(2)
+	m.term.On("Spinner").Return(m.term)
+	m.term.On("Start", "Installing %d package(s)...", []interface{}{2}).Return().Once()
+	m.term.On("OK").Return().Once()
+	m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+	// list all packages
+	m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+	m.term.On("Writeln", mock.Anything).Return(0, nil)
+
+	err := app.RunContext(ctx, []string{os.Args[0], "install", "--concurrency", "2", "test-cmd", "test-cmd-2", "test-cmd"})
+	require.NoError(t, err)
+
+	m.gitRepo.AssertExpectations(t)
+	m.langManager.AssertExpectations(t)
+	for _, name := range []string{"cli-test-cmd", "cli-test-cmd-2"} {
+		meta, err := readInstallMetadata("./testdata/.akamai-cli/src/" + name)
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/akamai/"+name+".git", meta.Repo)
+	}
+}
+
+func TestCmdInstallLocal(t *testing.T) {
+	defer useTempAuditLog(t)()
+	sourceDir, err := filepath.Abs("./testdata/repo")
+	require.NoError(t, err)
+	tmpDir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+	archive := filepath.Join(tmpDir, "cli-archived.tar.gz")
+	require.NoError(t, tools.CreateTarGz(sourceDir, archive, "cli-archived", nil))
+	checksum, err := fileChecksum(archive)
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/releases/cli-archived.tar.gz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeFile(w, r, archive)
+	}))
+	defer srv.Close()
+
+	expectInstall := func(m *mocked, source, dir string) {
+		m.term.On("Spinner").Return(m.term)
+		m.term.On("Start", "Attempting to install package from %s...", []interface{}{source}).Return().Once()
+		m.term.On("OK").Return()
+		m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+		m.langManager.On("Install", dir, packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+
+		// list all packages
+		m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+		m.term.On("Writeln", mock.Anything).Return(0, nil)
+	}
+
+	tests := map[string]struct {
+		args      []string
+		init      func(*testing.T, *mocked)
+		teardown  func(*testing.T)
+		withError string
+	}{
+		"install from directory": {
+			args: []string{"./testdata/repo"},
+			init: func(t *testing.T, m *mocked) {
+				expectInstall(m, sourceDir, "testdata/.akamai-cli/src/repo")
+			},
+			teardown: func(t *testing.T) {
+				meta, err := readInstallMetadata("./testdata/.akamai-cli/src/repo")
+				require.NoError(t, err)
+				assert.Equal(t, &installMetadata{Source: sourceDir, SourceType: sourceDirectory}, meta)
+				_, err = os.Stat(filepath.Join(sourceDir, installMetadataFile))
+				assert.True(t, os.IsNotExist(err), "source directory should not be modified")
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/repo"))
+			},
+		},
+		"install from archive": {
+			args: []string{"file://" + archive},
+			init: func(t *testing.T, m *mocked) {
+				expectInstall(m, archive, "testdata/.akamai-cli/src/cli-archived")
+			},
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-archived/cli.json")
+				assert.NoError(t, err)
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-archived"))
+			},
+		},
+		"install from archive URL": {
+			args: []string{srv.URL + "/releases/cli-archived.tar.gz"},
+			init: func(t *testing.T, m *mocked) {
+				expectInstall(m, srv.URL+"/releases/cli-archived.tar.gz", "testdata/.akamai-cli/src/cli-archived")
+			},
+			teardown: func(t *testing.T) {
+				meta, err := readInstallMetadata("./testdata/.akamai-cli/src/cli-archived")
+				require.NoError(t, err)
+				assert.Equal(t, &installMetadata{Source: srv.URL + "/releases/cli-archived.tar.gz", SourceType: sourceArchive, Checksum: checksum}, meta)
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-archived"))
+			},
+		},
+		"archive URL not found": {
+			args: []string{srv.URL + "/releases/missing.tar.gz"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to install package from %s...", []interface{}{srv.URL + "/releases/missing.tar.gz"}).Return().Once()
+				m.term.On("Fail").Return().Once()
+			},
+			withError: "404 Not Found",
+		},
+		"directory without cli.json": {
+			args: []string{tmpDir},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to install package from %s...", []interface{}{tmpDir}).Return().Once()
+				m.term.On("Fail").Return().Once()
+			},
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/" + filepath.Base(tmpDir))
+				assert.True(t, os.IsNotExist(err))
+			},
+			withError: "Invalid package: package does not contain a cli.json file",
+		},
+		"source does not exist": {
+			args: []string{"./testdata/missing"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to install package from %s...", []interface{}{filepath.Join(filepath.Dir(sourceDir), "missing")}).Return().Once()
+				m.term.On("Fail").Return().Once()
+			},
+			withError: "Unable to read package",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
+			m.cfg.On("GetValue", "install", "clone-depth").Return("", false).Maybe()
+			command := &cli.Command{
+				Name:   "install",
+				Action: cmdInstall(m.gitRepo, m.langManager),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "install")
+			args = append(args, test.args...)
+
+			test.init(t, m)
+			err := app.RunContext(ctx, args)
+			if test.teardown != nil {
+				test.teardown(t)
+			}
+			require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/"+lockFileName))
+
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			_, statErr := os.Stat("./testdata/.akamai-cli/" + stagingDirName)
+			assert.True(t, os.IsNotExist(statErr), "staging directory should be removed")
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
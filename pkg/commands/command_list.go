@@ -17,8 +17,10 @@ package commands
 import (
 	"fmt"
 	"github.com/akamai/cli/pkg/log"
+	"strings"
 	"time"
 
+	"github.com/akamai/cli/pkg/git"
 	"github.com/akamai/cli/pkg/terminal"
 
 	"github.com/fatih/color"
@@ -27,80 +29,249 @@ import (
 	"github.com/akamai/cli/pkg/tools"
 )
 
-func cmdList(c *cli.Context) (e error) {
-	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
-	start := time.Now()
-	logger := log.WithCommand(c.Context, c.Command.Name)
-	logger.Debug("LIST START")
-	defer func() {
-		if e == nil {
-			logger.Debugf("LIST FINISH: %v", time.Now().Sub(start))
-		} else {
-			logger.Errorf("LIST ERROR: %v", e.Error())
-		}
-	}()
+func cmdList(gitRepo git.Repository) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("LIST START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("LIST FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("LIST ERROR: %v", e.Error())
+			}
+		}()
+
+		if c.Bool("outdated") && c.Bool("current") {
+			return commandFailure(errUsage, "Flags --outdated and --current are mutually exclusive")
+		}
+		if c.Bool("installed") && c.Bool("remote") {
+			return commandFailure(errUsage, "Flags --installed and --remote are mutually exclusive")
+		}
+		format, err := listFormat(c)
+		if err != nil {
+			return err
+		}
+		if c.Bool("refresh") {
+			c.Context = withPackageListRefresh(c.Context)
+		}
+		filter := listFilter{names: c.Args().Slice(), installedOnly: c.Bool("installed")}
+
+		if format != formatTable {
+			return listPackagesFormatted(c, gitRepo, filter, format)
+		}
+
+		var commands map[string]bool
+		switch {
+		case c.Bool("outdated"):
+			commands = listPackagesByStatus(c, gitRepo, filter, packageStatusOutdated)
+		case c.Bool("current"):
+			commands = listPackagesByStatus(c, gitRepo, filter, packageStatusCurrent)
+		default:
+			commands = listInstalledCommands(c, filter, nil, nil)
+		}
+
+		if c.IsSet("remote") {
+			return listRemoteCommands(c, filter, commands)
+		}
+
+		return nil
+	}
+}
+
+func listRemoteCommands(c *cli.Context, filter listFilter, commands map[string]bool) error {
+	logger := log.FromContext(c.Context)
 	term := terminal.Get(c.Context)
 	bold := color.New(color.FgWhite, color.Bold)
 
-	commands := listInstalledCommands(c, nil, nil)
+	packageList, err := fetchPackageList(c.Context)
+	if err != nil {
+		return commandFailure(errNetwork, "Unable to fetch remote package list")
+	}
 
-	if c.IsSet("remote") {
-		packageList, err := fetchPackageList(c.Context)
-		if err != nil {
-			return cli.Exit("Unable to fetch remote package list", 1)
+	foundCommands := true
+	for _, cmd := range packageList.Packages {
+		for _, command := range cmd.Commands {
+			if _, ok := commands[command.Name]; !ok {
+				foundCommands = false
+				continue
+			}
 		}
+	}
 
-		foundCommands := true
-		for _, cmd := range packageList.Packages {
-			for _, command := range cmd.Commands {
-				if _, ok := commands[command.Name]; !ok {
-					foundCommands = false
-					continue
-				}
+	if foundCommands {
+		return nil
+	}
+	headerMsg := "\nAvailable Commands:\n\n"
+	term.Writeln(color.YellowString(headerMsg))
+	logger.Debug(headerMsg)
+
+	for _, remotePackage := range packageList.Packages {
+		if !filter.matchesPackage(remotePackage.Name, remotePackage.Commands) {
+			continue
+		}
+		for _, command := range remotePackage.Commands {
+			if _, ok := commands[command.Name]; ok {
+				continue
 			}
+			commandName := bold.Sprintf("  %s", command.Name)
+			term.Printf(commandName)
+			packageName := fmt.Sprintf(" [package: %s]", color.BlueString(remotePackage.Name))
+			term.Writeln(packageName)
+			commandDescription := fmt.Sprintf("    %s\n", command.Description)
+			term.Printf(commandDescription)
+			logger.Debug(commandName)
+			logger.Debug(packageName)
+			logger.Debug(commandDescription)
 		}
+	}
+
+	if !quietOutput(c.Context) {
+		term.Printf("\nInstall using \"%s\".\n", color.BlueString("%s install [package]", tools.Self()))
+	}
+	return nil
+}
+
+// listPackagesFormatted writes installed packages in JSON, YAML or Go template format, applying the same filters as text output.
+// Remote packages which are not installed are included if --remote flag is set.
+func listPackagesFormatted(c *cli.Context, gitRepo git.Repository, filter listFilter, format string) error {
+	term := terminal.Get(c.Context)
+
+	status := ""
+	if c.Bool("outdated") {
+		status = packageStatusOutdated
+	} else if c.Bool("current") {
+		status = packageStatusCurrent
+	}
 
-		if foundCommands {
-			return nil
+	result := make([]jsonPackage, 0)
+	if status != "" {
+		for _, check := range checkInstalledPackages(c.Context, gitRepo) {
+			if check.Status != status && check.Status != packageStatusUnknown {
+				continue
+			}
+			if !filter.matchesPackage(check.Name, check.Package.Commands) {
+				continue
+			}
+			result = append(result, updateCheckToJSON(check))
 		}
-		headerMsg := "\nAvailable Commands:\n\n"
-		term.Writeln(color.YellowString(headerMsg))
-		logger.Debug(headerMsg)
+	} else {
+		for _, dir := range getPackagePaths() {
+			pkg, err := readPackage(dir)
+			if err != nil {
+				continue
+			}
+			if !filter.matchesPackage(pkg.Pkg, pkg.Commands) {
+				continue
+			}
+			result = append(result, installedPackageToJSON(pkg))
+		}
+	}
 
+	if c.IsSet("remote") {
+		packageList, err := fetchPackageList(c.Context)
+		if err != nil {
+			return commandFailure(errNetwork, "Unable to fetch remote package list")
+		}
+		commands := installedCommandNames(c)
 		for _, remotePackage := range packageList.Packages {
-			for _, command := range remotePackage.Commands {
-				if _, ok := commands[command.Name]; ok {
-					continue
-				}
-				commandName := bold.Sprintf("  %s", command.Name)
-				term.Printf(commandName)
-				packageName := fmt.Sprintf(" [package: %s]", color.BlueString(remotePackage.Name))
-				term.Writeln(packageName)
-				commandDescription := fmt.Sprintf("    %s\n", command.Description)
-				term.Printf(commandDescription)
-				logger.Debug(commandName)
-				logger.Debug(packageName)
-				logger.Debug(commandDescription)
+			if !isPackageInstalled(remotePackage, commands) && filter.matchesPackage(remotePackage.Name, remotePackage.Commands) {
+				result = append(result, remotePackageToJSON(remotePackage, false))
 			}
 		}
+	}
 
-		term.Printf("\nInstall using \"%s\".\n", color.BlueString("%s install [package]", tools.Self()))
+	switch format {
+	case formatJSON:
+		return writeJSON(term, result)
+	case formatYAML:
+		return writeYAML(term, result)
 	}
+	return writeTemplate(term, format, result)
+}
 
-	return nil
+// listPackagesByStatus lists commands of installed packages matching given update status.
+// Packages which could not be compared with upstream are always listed and marked as unknown.
+func listPackagesByStatus(c *cli.Context, gitRepo git.Repository, filter listFilter, status string) map[string]bool {
+	term := terminal.Get(c.Context)
+
+	commands := installedCommandNames(c)
+
+	term.Spinner().Start("Checking for package updates...")
+	checks := checkInstalledPackages(c.Context, gitRepo)
+	term.Spinner().OK()
+
+	header := "\nUp-to-date Commands:\n"
+	if status == packageStatusOutdated {
+		header = "\nOutdated Commands:\n"
+	}
+	term.Writeln(color.YellowString(header))
+
+	for _, check := range checks {
+		if check.Status != status && check.Status != packageStatusUnknown {
+			continue
+		}
+		if !filter.matchesPackage(check.Name, check.Package.Commands) {
+			continue
+		}
+		printPackageStatus(term, check)
+	}
+
+	if status == packageStatusOutdated && !quietOutput(c.Context) {
+		term.Printf("\nUpdate using \"%s\".\n", color.BlueString("%s update [command]", tools.Self()))
+	}
+	return commands
+}
+
+// printPackageStatus lists commands of a package along with its update status
+func printPackageStatus(term terminal.Terminal, check packageUpdateCheck) {
+	bold := color.New(color.FgWhite, color.Bold)
+
+	var statusMsg string
+	switch check.Status {
+	case packageStatusOutdated:
+		statusMsg = fmt.Sprintf(" [%s: %s -> %s]", check.Status, check.Installed, check.Available)
+	case packageStatusCurrent:
+		statusMsg = fmt.Sprintf(" [%s: %s]", check.Status, check.Installed)
+	default:
+		statusMsg = color.CyanString(" [%s: %s]", check.Status, check.Reason)
+	}
+
+	if len(check.Package.Commands) == 0 {
+		term.Printf(bold.Sprintf("  %s", check.Name))
+		term.Writeln(statusMsg)
+		return
+	}
+
+	for _, command := range check.Package.Commands {
+		term.Printf(bold.Sprintf("  %s", command.Name))
+		printCommandAliases(term, command.Aliases)
+		printCommandDeprecation(term, command)
+		term.Writeln(statusMsg)
+		if len(command.Description) > 0 {
+			term.Printf("    %s\n", command.Description)
+		}
+	}
 }
-func listInstalledCommands(c *cli.Context, added map[string]bool, removed map[string]bool) map[string]bool {
+
+func listInstalledCommands(c *cli.Context, filter listFilter, added map[string]bool, removed map[string]bool) map[string]bool {
 	bold := color.New(color.FgWhite, color.Bold)
 
 	term := terminal.Get(c.Context)
 
-	commands := make(map[string]bool)
 	installedCmds := color.YellowString("\nInstalled Commands:\n")
 	term.Writeln(installedCmds)
-	cmds := getCommands(c)
-	for _, cmd := range cmds {
-		for _, command := range cmd.Commands {
-			commands[command.Name] = true
+	deprecated := deprecatedCommands()
+	for _, cmd := range c.App.Commands {
+		// builtin commands do not have Category set
+		if filter.installedOnly && cmd.Category == "" {
+			continue
+		}
+		for _, command := range cliCommandToSubcommand(cmd).Commands {
+			if !filter.matchesCommand(command) {
+				continue
+			}
 			if _, ok := added[command.Name]; ok {
 				term.Printf(color.GreenString("  %s", command.Name))
 			} else if _, ok := removed[command.Name]; ok {
@@ -109,23 +280,9 @@ func listInstalledCommands(c *cli.Context, added map[string]bool, removed map[st
 				term.Printf(bold.Sprintf("  %s", command.Name))
 			}
 
-			if len(command.Aliases) > 0 {
-				var aliases string
-
-				if len(command.Aliases) == 1 {
-					aliases = "alias"
-				} else {
-					aliases = "aliases"
-				}
-
-				term.Printf(" (%s: ", aliases)
-				for i, alias := range command.Aliases {
-					term.Printf(bold.Sprintf(alias))
-					if i < len(command.Aliases)-1 {
-						term.Printf(", ")
-					}
-				}
-				term.Printf(")")
+			printCommandAliases(term, command.Aliases)
+			if cmd.Category != "" {
+				printCommandDeprecation(term, deprecated[command.Name])
 			}
 
 			term.Writeln()
@@ -136,5 +293,136 @@ func listInstalledCommands(c *cli.Context, added map[string]bool, removed map[st
 		}
 	}
 	term.Printf("\nSee \"%s\" for details.\n", color.BlueString("%s help [command]", tools.Self()))
+	return installedCommandNames(c)
+}
+
+func printCommandAliases(term terminal.Terminal, aliases []string) {
+	if len(aliases) == 0 {
+		return
+	}
+	bold := color.New(color.FgWhite, color.Bold)
+
+	label := "aliases"
+	if len(aliases) == 1 {
+		label = "alias"
+	}
+
+	term.Printf(" (%s: ", label)
+	for i, alias := range aliases {
+		term.Printf(bold.Sprintf(alias))
+		if i < len(aliases)-1 {
+			term.Printf(", ")
+		}
+	}
+	term.Printf(")")
+}
+
+// printCommandDeprecation marks a command deprecated or renamed by its package
+func printCommandDeprecation(term terminal.Terminal, cmd command) {
+	switch {
+	case cmd.RenamedTo != "":
+		term.Printf(color.YellowString(" [renamed to %s]", cmd.RenamedTo))
+	case cmd.Deprecated != "":
+		term.Printf(color.YellowString(" [deprecated]"))
+	}
+}
+
+// deprecatedCommands returns installed commands deprecated or renamed by their packages, by name.
+// Commands of the app do not carry package metadata, so it is read again from package directories.
+func deprecatedCommands() map[string]command {
+	deprecated := make(map[string]command)
+	for _, dir := range getPackagePaths() {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			if cmd.Deprecated != "" || cmd.RenamedTo != "" {
+				deprecated[cmd.Name] = cmd
+			}
+		}
+	}
+	return deprecated
+}
+
+func installedCommandNames(c *cli.Context) map[string]bool {
+	commands := make(map[string]bool)
+	for _, cmd := range getCommands(c) {
+		for _, command := range cmd.Commands {
+			commands[command.Name] = true
+		}
+	}
 	return commands
 }
+
+// isPackageInstalled checks if all commands of a remote package are available
+func isPackageInstalled(pkg packageListPackage, commands map[string]bool) bool {
+	if len(pkg.Commands) == 0 {
+		return false
+	}
+	for _, command := range pkg.Commands {
+		if _, ok := commands[command.Name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// listFilter selects packages and commands displayed by the list command
+type listFilter struct {
+	// names are matched against package names, command names and aliases; everything matches if empty
+	names []string
+	// installedOnly excludes built-in commands
+	installedOnly bool
+}
+
+// matchesCommand returns true if the command name or one of its aliases contains one of the filter names
+func (f listFilter) matchesCommand(cmd command) bool {
+	if len(f.names) == 0 {
+		return true
+	}
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		if f.contains(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPackage returns true if the package name or one of its commands matches the filter
+func (f listFilter) matchesPackage(name string, commands []command) bool {
+	if len(f.names) == 0 || f.contains(name) {
+		return true
+	}
+	for _, cmd := range commands {
+		if f.matchesCommand(cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f listFilter) contains(value string) bool {
+	value = strings.ToLower(value)
+	for _, name := range f.names {
+		if strings.Contains(value, strings.ToLower(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// listFormat returns the output format set with --format or --json, table by default
+func listFormat(c *cli.Context) (string, error) {
+	format := c.String("format")
+	if c.Bool("json") {
+		if format != "" && format != formatJSON {
+			return "", commandFailure(errUsage, "Flags --json and --format are mutually exclusive")
+		}
+		format = formatJSON
+	}
+	if format == "" {
+		format = formatTable
+	}
+	return format, nil
+}
@@ -3,12 +3,15 @@ package commands
 import (
 	"fmt"
 	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/akamai/cli/pkg/tools"
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -65,7 +68,9 @@ func TestCmdListWithRemote(t *testing.T) {
 		require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
 		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
 		t.Run(name, func(t *testing.T) {
-			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			defer useTempCache(t)()
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, nil}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
 			command := &cli.Command{
 				Name: "list",
 				Flags: []cli.Flag{
@@ -75,7 +80,7 @@ func TestCmdListWithRemote(t *testing.T) {
 				},
 				Description: "Displays available commands",
 				Aliases:     []string{"ls", "show"},
-				Action:      cmdList,
+				Action:      cmdList(m.gitRepo),
 			}
 			app, ctx := setupTestApp(command, m)
 			args := os.Args[0:1]
@@ -95,3 +100,215 @@ func TestCmdListWithRemote(t *testing.T) {
 		})
 	}
 }
+
+func TestCmdListWithStatusFilter(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		withError string
+	}{
+		"list outdated packages": {
+			args: []string{"--outdated"},
+			init: func(m *mocked) {
+				bold := color.New(color.FgWhite, color.Bold)
+				mockPackageChecks(m)
+				m.term.On("Spinner").Return(m.term).Twice()
+				m.term.On("Start", "Checking for package updates...", []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.YellowString("\nOutdated Commands:\n")}).Return(0, nil).Once()
+
+				m.term.On("Printf", bold.Sprintf("  echo"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", " (%s: ", []interface{}{"alias"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("e"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", ")", []interface{}(nil)).Return().Once()
+				m.term.On("Writeln", []interface{}{" [outdated: 0100000 -> 0200000]"}).Return(0, nil).Once()
+				m.term.On("Printf", "    %s\n", []interface{}{"echo command"}).Return().Once()
+
+				m.term.On("Printf", bold.Sprintf("  echo-invalid-json"), []interface{}(nil)).Return().Once()
+				m.term.On("Writeln", mock.Anything).Return(0, nil).Once()
+
+				m.term.On("Printf", bold.Sprintf("  echo-python"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", " (%s: ", []interface{}{"alias"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("e"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", ")", []interface{}(nil)).Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString(" [unknown: unable to open package repository: oops]")}).Return(0, nil).Once()
+				m.term.On("Printf", "    %s\n", []interface{}{"echo command"}).Return().Once()
+
+				m.term.On("Printf", "\nUpdate using \"%s\".\n", []interface{}{color.BlueString("%s update [command]", tools.Self())}).Return().Once()
+			},
+		},
+		"list up-to-date packages": {
+			args: []string{"--current"},
+			init: func(m *mocked) {
+				bold := color.New(color.FgWhite, color.Bold)
+				mockPackageChecks(m)
+				m.term.On("Spinner").Return(m.term).Twice()
+				m.term.On("Start", "Checking for package updates...", []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.YellowString("\nUp-to-date Commands:\n")}).Return(0, nil).Once()
+
+				m.term.On("Printf", bold.Sprintf("  echo-invalid-json"), []interface{}(nil)).Return().Once()
+				m.term.On("Writeln", mock.Anything).Return(0, nil).Once()
+
+				m.term.On("Printf", bold.Sprintf("  echo-python"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", " (%s: ", []interface{}{"alias"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("e"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", ")", []interface{}(nil)).Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString(" [unknown: unable to open package repository: oops]")}).Return(0, nil).Once()
+				m.term.On("Printf", "    %s\n", []interface{}{"echo command"}).Return().Once()
+
+				m.term.On("Printf", bold.Sprintf("  installed"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", " (%s: ", []interface{}{"alias"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("ac2"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", ")", []interface{}(nil)).Return().Once()
+				m.term.On("Writeln", []interface{}{" [current: 0100000]"}).Return(0, nil).Once()
+				m.term.On("Printf", "    %s\n", []interface{}{"Test command"}).Return().Once()
+			},
+		},
+		"list installed packages in JSON format": {
+			args: []string{"--json"},
+			init: func(m *mocked) {
+				m.term.On("Writeln", jsonOutput(`[
+					{"name": "echo", "installed": true, "commands": [{"name": "echo", "aliases": ["e"], "description": "echo command"}]},
+					{"name": "echo-python", "installed": true, "commands": [{"name": "echo-python", "aliases": ["e"], "description": "echo command"}]},
+					{"name": "installed", "installed": true, "commands": [{"name": "installed", "aliases": ["ac2"], "description": "Test command"}]}
+				]`)).Return(0, nil).Once()
+			},
+		},
+		"list outdated packages in JSON format": {
+			args: []string{"--outdated", "--json"},
+			init: func(m *mocked) {
+				mockPackageChecks(m)
+				m.term.On("Writeln", jsonOutput(`[
+					{"name": "echo", "installed": true, "status": "outdated", "installed-commit": "0100000", "available-commit": "0200000",
+						"commands": [{"name": "echo", "aliases": ["e"], "description": "echo command"}]},
+					{"name": "echo-invalid-json", "installed": true, "status": "unknown", "reason": "unable to read package: invalid character 'i' looking for beginning of value",
+						"commands": []},
+					{"name": "echo-python", "installed": true, "status": "unknown", "reason": "unable to open package repository: oops",
+						"commands": [{"name": "echo-python", "aliases": ["e"], "description": "echo command"}]}
+				]`)).Return(0, nil).Once()
+			},
+		},
+		"outdated and current flags are mutually exclusive": {
+			args:      []string{"--outdated", "--current"},
+			init:      func(m *mocked) {},
+			withError: "Flags --outdated and --current are mutually exclusive",
+		},
+		"list installed commands matching name": {
+			args: []string{"--installed", "EC"},
+			init: func(m *mocked) {
+				bold := color.New(color.FgWhite, color.Bold)
+				m.term.On("Writeln", []interface{}{color.YellowString("\nInstalled Commands:\n")}).Return(0, nil).Once()
+				m.term.On("Printf", bold.Sprintf("  echo"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", " (%s: ", []interface{}{"alias"}).Return().Once()
+				m.term.On("Printf", bold.Sprintf("e"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", ")", []interface{}(nil)).Return().Once()
+				m.term.On("Writeln", []interface{}(nil)).Return(0, nil).Once()
+				m.term.On("Printf", "    echo command\n", []interface{}(nil)).Return().Once()
+				m.term.On("Printf", "\nSee \"%s\" for details.\n", []interface{}{color.BlueString("%s help [command]", tools.Self())}).Return().Once()
+			},
+		},
+		"list packages matching name in YAML format": {
+			args: []string{"--format", "yaml", "python"},
+			init: func(m *mocked) {
+				m.term.On("Writeln", []interface{}{`- name: echo-python
+  installed: true
+  commands:
+    - name: echo-python
+      aliases:
+        - e
+      description: echo command`}).Return(0, nil).Once()
+			},
+		},
+		"list packages with custom template": {
+			args: []string{"--format", "{{.Name}}: {{len .Commands}}"},
+			init: func(m *mocked) {
+				m.term.On("Writeln", []interface{}{"echo: 1"}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{"echo-python: 1"}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{"installed: 1"}).Return(0, nil).Once()
+			},
+		},
+		"list packages with updates available matching name": {
+			args: []string{"--updates-available", "--format", "{{.Name}} {{.Status}}", "echo"},
+			init: func(m *mocked) {
+				mockPackageChecks(m)
+				m.term.On("Writeln", []interface{}{"echo outdated"}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{"echo-invalid-json unknown"}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{"echo-python unknown"}).Return(0, nil).Once()
+			},
+		},
+		"invalid template": {
+			args:      []string{"--format", "{{.Name"},
+			init:      func(m *mocked) {},
+			withError: "Invalid format: template: format:1: unclosed action",
+		},
+		"json and format flags are mutually exclusive": {
+			args:      []string{"--json", "--format", "yaml"},
+			init:      func(m *mocked) {},
+			withError: "Flags --json and --format are mutually exclusive",
+		},
+		"installed and remote flags are mutually exclusive": {
+			args:      []string{"--installed", "--remote"},
+			init:      func(m *mocked) {},
+			withError: "Flags --installed and --remote are mutually exclusive",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			defer useTempCache(t)()
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, nil}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
+			command := &cli.Command{
+				Name: "list",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "remote"},
+					&cli.BoolFlag{Name: "installed"},
+					&cli.BoolFlag{Name: "outdated", Aliases: []string{"updates-available"}},
+					&cli.BoolFlag{Name: "current"},
+					&cli.BoolFlag{Name: "json"},
+					&cli.StringFlag{Name: "format"},
+				},
+				Action: cmdList(m.gitRepo),
+			}
+			app, ctx := setupTestApp(command, m)
+			app.Commands = append(app.Commands, &cli.Command{Name: "echo", Aliases: []string{"e"}, Description: "echo command", Category: "Installed"})
+			args := os.Args[0:1]
+			args = append(args, "list")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// mockPackageChecks sets up git repository mocks for packages in test CLI home:
+// echo is outdated, echo-python cannot be opened and installed is up-to-date
+func mockPackageChecks(m *mocked) {
+	master := plumbing.NewBranchReferenceName("master")
+	remoteMaster := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, "master")
+
+	m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+	m.gitRepo.On("Head").Return(plumbing.NewHashReference(master, plumbing.Hash{1}), nil).Once()
+	m.gitRepo.On("Fetch").Return(nil).Once()
+	m.gitRepo.On("Reference", remoteMaster).Return(plumbing.NewHashReference(remoteMaster, plumbing.Hash{2}), nil).Once()
+	m.gitRepo.On("IsAncestor", plumbing.Hash{2}, plumbing.Hash{1}).Return(false, nil).Once()
+
+	m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-python").Return(fmt.Errorf("oops")).Once()
+
+	m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-installed").Return(nil).Once()
+	m.gitRepo.On("Head").Return(plumbing.NewHashReference(master, plumbing.Hash{1}), nil).Once()
+	m.gitRepo.On("Fetch").Return(fmt.Errorf(alreadyUptoDate)).Once()
+	m.gitRepo.On("Reference", remoteMaster).Return(plumbing.NewHashReference(remoteMaster, plumbing.Hash{1}), nil).Once()
+}
@@ -0,0 +1,42 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+)
+
+// cmdOutdated lists available updates of installed packages, like "update --check", and fails if any package is outdated
+func cmdOutdated(gitRepo git.Repository) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		start := time.Now()
+		logger.Debug("OUTDATED START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("OUTDATED FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("OUTDATED ERROR: %v", e.Error())
+			}
+		}()
+		return checkUpdates(c, gitRepo)
+	}
+}
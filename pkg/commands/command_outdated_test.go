@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestCmdOutdated(t *testing.T) {
+	tests := map[string]struct {
+		args         []string
+		expected     string
+		withExitCode int
+	}{
+		"up-to-date package": {
+			args: []string{"--json", "installed"},
+			expected: `[{"name": "installed", "installed": true, "status": "current", "installed-commit": "0100000", "available-commit": "0100000",
+				"commands": [{"name": "installed", "aliases": ["ac2"], "description": "Test command"}]}]`,
+		},
+		"outdated package": {
+			args: []string{"--json", "echo"},
+			expected: `[{"name": "echo", "installed": true, "status": "outdated", "installed-commit": "0100000", "available-commit": "0200000",
+				"commands": [{"name": "echo", "aliases": ["e"], "description": "echo command"}]}]`,
+			withExitCode: exitFailure,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name:   "outdated",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "json"}},
+				Action: cmdOutdated(m.gitRepo),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "outdated")
+			args = append(args, test.args...)
+
+			mockPackageChecks(m)
+			m.term.On("Writeln", jsonOutput(test.expected)).Return(0, nil).Once()
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			if test.withExitCode != 0 {
+				var exitErr cli.ExitCoder
+				require.True(t, errors.As(err, &exitErr))
+				assert.Equal(t, test.withExitCode, exitErr.ExitCode())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func cmdPackagePack(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("PACKAGE PACK START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("PACKAGE PACK FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("PACKAGE PACK ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	dir := "."
+	if c.Args().Present() {
+		dir = c.Args().First()
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to pack package: %s", err.Error()), 1)
+	}
+	if err := validatePackage(dir); err != nil {
+		return cli.Exit(color.RedString("Invalid package: %s", err.Error()), 1)
+	}
+
+	name := filepath.Base(dir)
+	output := c.String("output")
+	if output == "" {
+		output = name + ".tar.gz"
+	}
+	if output, err = filepath.Abs(output); err != nil {
+		return cli.Exit(color.RedString("Unable to pack package: %s", err.Error()), 1)
+	}
+
+	// git history and install metadata are not part of the package
+	skip := func(rel string, info os.FileInfo) bool {
+		return rel == ".git" || rel == installMetadataFile || filepath.Join(dir, rel) == output
+	}
+	if err := tools.CreateTarGz(dir, output, name, skip); err != nil {
+		if rmErr := os.Remove(output); rmErr != nil && !os.IsNotExist(rmErr) {
+			logger.Errorf("Unable to remove incomplete archive: %s", rmErr.Error())
+		}
+		return cli.Exit(color.RedString("Unable to pack package: %s", err.Error()), 1)
+	}
+
+	term.Printf("Package %s written to %s\n", color.CyanString(name), output)
+	term.Printf("Install it using \"%s\".\n", color.BlueString("%s install %s", tools.Self(), output))
+	return nil
+}
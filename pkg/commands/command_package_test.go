@@ -0,0 +1,94 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdPackagePack(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+	output := filepath.Join(tmpDir, "repo.tar.gz")
+
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		withError string
+	}{
+		"pack package directory": {
+			args: []string{"--output", output, "./testdata/repo"},
+			init: func(m *mocked) {
+				m.term.On("Printf", "Package %s written to %s\n", mock.Anything).Return().Once()
+				m.term.On("Printf", "Install it using \"%s\".\n", mock.Anything).Return().Once()
+			},
+		},
+		"directory without cli.json": {
+			args:      []string{"--output", output, tmpDir},
+			init:      func(m *mocked) {},
+			withError: "Invalid package: package does not contain a cli.json file",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name:   "pack",
+				Flags:  []cli.Flag{&cli.StringFlag{Name: "output"}},
+				Action: cmdPackagePack,
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "pack")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+
+			extracted := filepath.Join(tmpDir, "extracted")
+			require.NoError(t, tools.ExtractTarGz(output, extracted))
+			expected, err := ioutil.ReadFile("./testdata/repo/cli.json")
+			require.NoError(t, err)
+			actual, err := ioutil.ReadFile(filepath.Join(extracted, "repo", "cli.json"))
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func cmdPin(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("PIN START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("PIN FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("PIN ERROR: %v", e.Error())
+		}
+	}()
+	if !c.Args().Present() {
+		return commandFailure(errUsage, "You must specify a command to pin")
+	}
+	return setPackagesHeld(c, true)
+}
+
+func cmdUnpin(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("UNPIN START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("UNPIN FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("UNPIN ERROR: %v", e.Error())
+		}
+	}()
+	if !c.Args().Present() {
+		return commandFailure(errUsage, "You must specify a command to unpin")
+	}
+	return setPackagesHeld(c, false)
+}
+
+// setPackagesHeld marks packages containing given commands as held back, or not, when all packages are updated
+func setPackagesHeld(c *cli.Context, held bool) error {
+	term := terminal.Get(c.Context)
+	for _, cmd := range c.Args().Slice() {
+		dir, ok := findCommandPackageDir(cmd)
+		if !ok {
+			return commandFailure(errNotFound, "Command \"%s\" not found. Try \"%s help\".\n", cmd, tools.Self())
+		}
+		meta, err := readInstallMetadata(dir)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read install metadata of command \"%s\": %s", cmd, err.Error()), 1)
+		}
+		name := filepath.Base(dir)
+		switch {
+		case held && meta != nil && meta.Held:
+			term.Printf("Package %s is already pinned\n", color.CyanString(name))
+			continue
+		case !held && (meta == nil || !meta.Held):
+			term.Printf("Package %s is not pinned\n", color.CyanString(name))
+			continue
+		case meta == nil:
+			// packages installed before install metadata was introduced get metadata holding only the pin
+			meta = &installMetadata{}
+		}
+
+		meta.Held = held
+		if err := writeInstallMetadata(dir, meta); err != nil {
+			return cli.Exit(color.RedString("Unable to save install metadata of command \"%s\": %s", cmd, err.Error()), 1)
+		}
+		if held {
+			term.Printf("Package %s pinned, \"%s\" will hold it back\n", color.CyanString(name), color.BlueString("%s update", tools.Self()))
+		} else {
+			term.Printf("Package %s unpinned\n", color.CyanString(name))
+		}
+	}
+	return nil
+}
+
+// heldPackages returns names of packages pinned with the pin command, by commands they contain
+func heldPackages() map[string]string {
+	held := make(map[string]string)
+	for _, dir := range getPackagePaths() {
+		if meta, err := readInstallMetadata(dir); err != nil || meta == nil || !meta.Held {
+			continue
+		}
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			held[cmd.Name] = filepath.Base(dir)
+		}
+	}
+	return held
+}
+
+// heldBackMessage is displayed for each pinned package skipped when all packages are updated
+func heldBackMessage(pkg, cmd string) string {
+	return fmt.Sprintf("Package %s is pinned and was held back, run \"%s unpin %s\" to update it with other packages, or \"%s update %s\" to update it once", pkg, tools.Self(), cmd, tools.Self(), cmd)
+}
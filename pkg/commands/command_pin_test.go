@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func TestCmdPin(t *testing.T) {
+	metadataPath := "./testdata/.akamai-cli/src/cli-echo/" + installMetadataFile
+	tests := map[string]struct {
+		command   string
+		args      []string
+		metadata  *installMetadata
+		init      func(*terminal.Mock)
+		expected  *installMetadata
+		withError string
+	}{
+		"pin package without install metadata": {
+			command: "pin",
+			args:    []string{"echo"},
+			init: func(m *terminal.Mock) {
+				m.On("Printf", "Package %s pinned, \"%s\" will hold it back\n", []interface{}{color.CyanString("cli-echo"), color.BlueString("%s update", tools.Self())}).Return().Once()
+			},
+			expected: &installMetadata{Held: true},
+		},
+		"pin package by alias": {
+			command:  "pin",
+			args:     []string{"e"},
+			metadata: &installMetadata{Repo: "https://github.com/akamai/cli-echo", Commit: plumbing.Hash{1}.String()},
+			init: func(m *terminal.Mock) {
+				m.On("Printf", "Package %s pinned, \"%s\" will hold it back\n", []interface{}{color.CyanString("cli-echo"), color.BlueString("%s update", tools.Self())}).Return().Once()
+			},
+			expected: &installMetadata{Repo: "https://github.com/akamai/cli-echo", Commit: plumbing.Hash{1}.String(), Held: true},
+		},
+		"package already pinned": {
+			command:  "pin",
+			args:     []string{"echo"},
+			metadata: &installMetadata{Held: true},
+			init: func(m *terminal.Mock) {
+				m.On("Printf", "Package %s is already pinned\n", []interface{}{color.CyanString("cli-echo")}).Return().Once()
+			},
+			expected: &installMetadata{Held: true},
+		},
+		"unpin package": {
+			command:  "unpin",
+			args:     []string{"echo"},
+			metadata: &installMetadata{Commit: plumbing.Hash{1}.String(), Held: true},
+			init: func(m *terminal.Mock) {
+				m.On("Printf", "Package %s unpinned\n", []interface{}{color.CyanString("cli-echo")}).Return().Once()
+			},
+			expected: &installMetadata{Commit: plumbing.Hash{1}.String()},
+		},
+		"package not pinned": {
+			command: "unpin",
+			args:    []string{"echo"},
+			init: func(m *terminal.Mock) {
+				m.On("Printf", "Package %s is not pinned\n", []interface{}{color.CyanString("cli-echo")}).Return().Once()
+			},
+		},
+		"command not found": {
+			command:   "pin",
+			args:      []string{"not-found"},
+			init:      func(m *terminal.Mock) {},
+			withError: fmt.Sprintf("Command \"not-found\" not found. Try \"%s help\".\n", tools.Self()),
+		},
+		"no args passed": {
+			command:   "unpin",
+			init:      func(m *terminal.Mock) {},
+			withError: "You must specify a command to unpin",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			if test.metadata != nil {
+				require.NoError(t, writeInstallMetadata("./testdata/.akamai-cli/src/cli-echo", test.metadata))
+			}
+			defer func() {
+				require.NoError(t, os.RemoveAll(metadataPath))
+			}()
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := &cli.Command{Name: "pin", Action: cmdPin}
+			if test.command == "unpin" {
+				command = &cli.Command{Name: "unpin", Action: cmdUnpin}
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, test.command)
+			args = append(args, test.args...)
+
+			test.init(m.term)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			meta, err := readInstallMetadata("./testdata/.akamai-cli/src/cli-echo")
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, meta)
+		})
+	}
+}
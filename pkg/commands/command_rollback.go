@@ -0,0 +1,114 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func cmdRollback(langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		start := time.Now()
+		logger.Debug("ROLLBACK START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("ROLLBACK FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("ROLLBACK ERROR: %v", e.Error())
+			}
+		}()
+		if !c.Args().Present() {
+			return commandFailure(errUsage, "You must specify a command to roll back")
+		}
+
+		defer updateLockFile(c.Context)
+		defer updateCommandShims(c.Context)
+		for _, cmd := range c.Args().Slice() {
+			if err := rollbackPackage(c.Context, langManager, cmd, logger); err != nil {
+				stats.TrackEvent(c.Context, "package.rollback", "failed", cmd)
+				return err
+			}
+			stats.TrackEvent(c.Context, "package.rollback", "success", cmd)
+		}
+
+		return nil
+	}
+}
+
+// rollbackPackage restores the version of the package containing given command which was installed before the last update
+func rollbackPackage(ctx context.Context, langManager packages.LangManager, cmd string, logger log.Logger) (e error) {
+	term := terminal.Get(ctx)
+	exec, err := findExec(ctx, langManager, cmd)
+	if err != nil {
+		return commandFailure(errNotFound, "Command \"%s\" not found. Try \"%s help\".\n", cmd, tools.Self())
+	}
+
+	term.Spinner().Start("Attempting to roll back \"%s\" command...", cmd)
+
+	repoDir := findExecPackageDir(exec)
+	if repoDir == "" {
+		term.Spinner().Fail()
+		return commandFailure(errNotFound, "unable to roll back, was it installed using "+color.CyanString("\"akamai install\"")+"?")
+	}
+	logger.Debugf("Repo found: %s", repoDir)
+	previous := auditPackage(auditRollback, repoDir)
+	defer func() {
+		entry := auditPackage(auditRollback, repoDir)
+		entry.PreviousVersion, entry.PreviousCommit = previous.Version, previous.Commit
+		recordAudit(ctx, entry, e)
+	}()
+
+	previousDir, err := previousPackageDir(repoDir)
+	if err != nil {
+		term.Spinner().Fail()
+		return cli.Exit(color.RedString("Unable to roll back command \"%s\": %s", cmd, err.Error()), 1)
+	}
+	if _, err := os.Stat(previousDir); err != nil {
+		term.Spinner().Fail()
+		return commandFailure(errNotFound, "No previous version of command \"%s\" is available. A version is kept only when the package is updated using \"akamai update\".", cmd)
+	}
+
+	if err := restorePreviousPackage(repoDir); err != nil {
+		term.Spinner().Fail()
+		logger.Errorf("Unable to restore previous version: %s", err.Error())
+		return cli.Exit(color.RedString("Unable to roll back command \"%s\": %s", cmd, err.Error()), 1)
+	}
+	term.Spinner().OK()
+
+	meta, err := readInstallMetadata(repoDir)
+	if err != nil {
+		logger.Warnf("Unable to read install metadata: %s", err.Error())
+	}
+	if meta != nil && meta.Commit != "" {
+		term.Printf("Command \"%s\" restored to commit %s\n", cmd, shortHash(plumbing.NewHash(meta.Commit)))
+	}
+	term.Printf("Run \"%s\" again to restore the updated version.\n", color.BlueString("%s rollback %s", tools.Self(), cmd))
+
+	return nil
+}
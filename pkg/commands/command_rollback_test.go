@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func TestCmdRollback(t *testing.T) {
+	defer useTempAuditLog(t)()
+	setupPackage := func(t *testing.T, dir, version string) {
+		copyFile(t, "./testdata/.akamai-cli/src/cli-echo/cli.json", dir)
+		copyFile(t, "./testdata/.akamai-cli/src/cli-echo/bin/akamai-echo", dir+"/bin")
+		require.NoError(t, os.Rename(dir+"/bin/akamai-echo", dir+"/bin/akamai-echo-rollback"))
+		require.NoError(t, os.Chmod(dir+"/bin/akamai-echo-rollback", 0755))
+		require.NoError(t, ioutil.WriteFile(dir+"/VERSION", []byte(version), 0644))
+	}
+	assertVersion := func(t *testing.T, dir, version string) {
+		content, err := ioutil.ReadFile(dir + "/VERSION")
+		require.NoError(t, err)
+		assert.Equal(t, version, string(content))
+	}
+
+	tests := map[string]struct {
+		args      []string
+		init      func(*testing.T, *mocked)
+		teardown  func(*testing.T)
+		withError string
+	}{
+		"roll back command": {
+			args: []string{"echo-rollback"},
+			init: func(t *testing.T, m *mocked) {
+				setupPackage(t, "./testdata/.akamai-cli/src/cli-echo-rollback", "updated")
+				setupPackage(t, "./testdata/.akamai-cli/.rollback/cli-echo-rollback", "previous")
+				require.NoError(t, writeInstallMetadata("./testdata/.akamai-cli/.rollback/cli-echo-rollback", &installMetadata{Commit: plumbing.Hash{1}.String()}))
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to roll back "%s" command...`, []interface{}{"echo-rollback"}).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "Command \"%s\" restored to commit %s\n", []interface{}{"echo-rollback", "0100000"}).Return().Once()
+				m.term.On("Printf", "Run \"%s\" again to restore the updated version.\n", mock.Anything).Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+			},
+			teardown: func(t *testing.T) {
+				assertVersion(t, "./testdata/.akamai-cli/src/cli-echo-rollback", "previous")
+				assertVersion(t, "./testdata/.akamai-cli/.rollback/cli-echo-rollback", "updated")
+			},
+		},
+		"no previous version": {
+			args: []string{"echo-rollback"},
+			init: func(t *testing.T, m *mocked) {
+				setupPackage(t, "./testdata/.akamai-cli/src/cli-echo-rollback", "updated")
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to roll back "%s" command...`, []interface{}{"echo-rollback"}).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+			},
+			teardown: func(t *testing.T) {
+				assertVersion(t, "./testdata/.akamai-cli/src/cli-echo-rollback", "updated")
+			},
+			withError: color.RedString(`No previous version of command "echo-rollback" is available.`),
+		},
+		"no args passed": {
+			args:      []string{},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: "You must specify a command to roll back",
+		},
+		"executable not found": {
+			args: []string{"invalid"},
+			init: func(t *testing.T, m *mocked) {
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			withError: fmt.Sprintf(`Command "invalid" not found. Try "%s help".`, tools.Self()),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name:   "rollback",
+				Action: cmdRollback(m.langManager),
+			}
+			app, ctx := setupTestApp(command, m)
+			defer func() {
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-echo-rollback"))
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/"+rollbackDirName))
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/"+lockFileName))
+			}()
+			args := os.Args[0:1]
+			args = append(args, "rollback")
+			args = append(args, test.args...)
+
+			test.init(t, m)
+			err := app.RunContext(ctx, args)
+			if test.teardown != nil {
+				test.teardown(t)
+			}
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/version"
+)
+
+// SBOM formats supported by the sbom command
+const (
+	sbomFormatCycloneDX = "cyclonedx"
+	sbomFormatSPDX      = "spdx"
+)
+
+const (
+	sbomToolName   = "akamai-cli"
+	sbomToolVendor = "Akamai Technologies"
+	noAssertion    = "NOASSERTION"
+)
+
+var (
+	// sbomTime and sbomID return the creation time and unique identifier of a document, replaced in tests
+	sbomTime = time.Now
+	sbomID   = uuid.NewRandom
+
+	spdxInvalidIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+)
+
+type (
+	// cycloneDXDocument is a CycloneDX 1.4 bill of materials, see https://cyclonedx.org/docs/1.4/json/
+	cycloneDXDocument struct {
+		BOMFormat    string                `json:"bomFormat"`
+		SpecVersion  string                `json:"specVersion"`
+		SerialNumber string                `json:"serialNumber"`
+		Version      int                   `json:"version"`
+		Metadata     cycloneDXMetadata     `json:"metadata"`
+		Components   []cycloneDXComponent  `json:"components"`
+		Dependencies []cycloneDXDependency `json:"dependencies"`
+	}
+
+	cycloneDXMetadata struct {
+		Timestamp string             `json:"timestamp"`
+		Tools     []cycloneDXTool    `json:"tools"`
+		Component cycloneDXComponent `json:"component"`
+	}
+
+	cycloneDXTool struct {
+		Vendor  string `json:"vendor"`
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	cycloneDXComponent struct {
+		Type               string               `json:"type"`
+		BOMRef             string               `json:"bom-ref"`
+		Name               string               `json:"name"`
+		Version            string               `json:"version,omitempty"`
+		Purl               string               `json:"purl,omitempty"`
+		Hashes             []cycloneDXHash      `json:"hashes,omitempty"`
+		ExternalReferences []cycloneDXReference `json:"externalReferences,omitempty"`
+		Properties         []cycloneDXProperty  `json:"properties,omitempty"`
+		Components         []cycloneDXComponent `json:"components,omitempty"`
+	}
+
+	cycloneDXHash struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	}
+
+	cycloneDXReference struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	}
+
+	cycloneDXProperty struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	cycloneDXDependency struct {
+		Ref       string   `json:"ref"`
+		DependsOn []string `json:"dependsOn"`
+	}
+
+	// spdxDocument is an SPDX 2.3 document, see https://spdx.github.io/spdx-spec/v2.3/
+	spdxDocument struct {
+		SPDXVersion       string             `json:"spdxVersion"`
+		DataLicense       string             `json:"dataLicense"`
+		SPDXID            string             `json:"SPDXID"`
+		Name              string             `json:"name"`
+		DocumentNamespace string             `json:"documentNamespace"`
+		CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+		Packages          []spdxPackage      `json:"packages"`
+		Files             []spdxFile         `json:"files,omitempty"`
+		Relationships     []spdxRelationship `json:"relationships"`
+	}
+
+	spdxCreationInfo struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	}
+
+	spdxPackage struct {
+		Name             string            `json:"name"`
+		SPDXID           string            `json:"SPDXID"`
+		VersionInfo      string            `json:"versionInfo,omitempty"`
+		DownloadLocation string            `json:"downloadLocation"`
+		FilesAnalyzed    bool              `json:"filesAnalyzed"`
+		LicenseConcluded string            `json:"licenseConcluded"`
+		LicenseDeclared  string            `json:"licenseDeclared"`
+		CopyrightText    string            `json:"copyrightText"`
+		Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+		ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+	}
+
+	spdxFile struct {
+		FileName         string         `json:"fileName"`
+		SPDXID           string         `json:"SPDXID"`
+		Checksums        []spdxChecksum `json:"checksums"`
+		LicenseConcluded string         `json:"licenseConcluded"`
+		CopyrightText    string         `json:"copyrightText"`
+	}
+
+	spdxChecksum struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	}
+
+	spdxExternalRef struct {
+		ReferenceCategory string `json:"referenceCategory"`
+		ReferenceType     string `json:"referenceType"`
+		ReferenceLocator  string `json:"referenceLocator"`
+	}
+
+	spdxRelationship struct {
+		SPDXElementID      string `json:"spdxElementId"`
+		RelationshipType   string `json:"relationshipType"`
+		RelatedSPDXElement string `json:"relatedSpdxElement"`
+	}
+)
+
+func cmdSBOM(gitRepo git.Repository) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("SBOM START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("SBOM FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("SBOM ERROR: %v", e.Error())
+			}
+		}()
+		format := strings.ToLower(c.String("format"))
+		if format != sbomFormatCycloneDX && format != sbomFormatSPDX {
+			return commandFailure(errUsage, "Invalid format \"%s\", expected %s or %s", c.String("format"), sbomFormatCycloneDX, sbomFormatSPDX)
+		}
+
+		pkgs, err := collectSBOMPackages(gitRepo)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read installed packages: %s", err.Error()), 1)
+		}
+		id, err := sbomID()
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to generate document identifier: %s", err.Error()), 1)
+		}
+		cliHash := cliExecutableChecksum(logger)
+
+		var doc interface{}
+		if format == sbomFormatSPDX {
+			doc = newSPDXDocument(pkgs, cliHash, id.String(), sbomTime())
+		} else {
+			doc = newCycloneDXDocument(pkgs, cliHash, id.String(), sbomTime())
+		}
+
+		term := terminal.Get(c.Context)
+		output := c.String("output")
+		if output == "" {
+			return writeJSON(term, doc)
+		}
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to encode output: %s", err.Error()), 1)
+		}
+		if err := ioutil.WriteFile(output, data, 0644); err != nil {
+			return cli.Exit(color.RedString("Unable to save SBOM: %s", err.Error()), 1)
+		}
+		term.Printf("SBOM of %d package(s) saved to %s\n", len(pkgs), output)
+		return nil
+	}
+}
+
+// cliExecutableChecksum returns the SHA-256 checksum of the running executable, or an empty string if it cannot be read
+func cliExecutableChecksum(logger log.Logger) string {
+	exe, err := os.Executable()
+	if err != nil {
+		logger.Warnf("Unable to locate the CLI executable: %s", err.Error())
+		return ""
+	}
+	sum, err := fileChecksum(exe)
+	if err != nil {
+		logger.Warnf("Unable to calculate checksum of the CLI executable: %s", err.Error())
+		return ""
+	}
+	return sum
+}
+
+// sortedBinaries returns paths of package binaries in a stable order
+func sortedBinaries(binaries map[string]string) []string {
+	paths := make([]string, 0, len(binaries))
+	for path := range binaries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func newCycloneDXDocument(pkgs []sbomPackage, cliHash, id string, created time.Time) *cycloneDXDocument {
+	cliComponent := cycloneDXComponent{
+		Type:    "application",
+		BOMRef:  cliPurl(),
+		Name:    sbomToolName,
+		Version: version.Version,
+		Purl:    cliPurl(),
+	}
+	if cliHash != "" {
+		cliComponent.Hashes = []cycloneDXHash{{Alg: "SHA-256", Content: cliHash}}
+	}
+	doc := &cycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: "urn:uuid:" + id,
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: created.UTC().Format(time.RFC3339),
+			Tools:     []cycloneDXTool{{Vendor: sbomToolVendor, Name: sbomToolName, Version: version.Version}},
+			Component: cliComponent,
+		},
+		Components:   make([]cycloneDXComponent, 0),
+		Dependencies: make([]cycloneDXDependency, 0),
+	}
+
+	cliDependency := cycloneDXDependency{Ref: cliComponent.BOMRef, DependsOn: make([]string, 0)}
+	libraries := make(map[string]bool)
+	var libraryComponents []cycloneDXComponent
+	for _, pkg := range pkgs {
+		component := cycloneDXComponent{
+			Type:    "application",
+			BOMRef:  "package:" + pkg.Name,
+			Name:    pkg.Name,
+			Version: pkg.Commit,
+			Purl:    pkg.purl(),
+		}
+		if pkg.Repo != "" {
+			component.ExternalReferences = []cycloneDXReference{{Type: "vcs", URL: pkg.Repo}}
+		}
+		if pkg.Source != "" {
+			component.Properties = append(component.Properties, cycloneDXProperty{Name: "akamai-cli:source", Value: pkg.Source})
+		}
+		if pkg.Language != "" {
+			component.Properties = append(component.Properties, cycloneDXProperty{Name: "akamai-cli:language", Value: pkg.Language})
+		}
+		for _, path := range sortedBinaries(pkg.Binaries) {
+			component.Components = append(component.Components, cycloneDXComponent{
+				Type:   "file",
+				BOMRef: component.BOMRef + ":" + path,
+				Name:   path,
+				Hashes: []cycloneDXHash{{Alg: "SHA-256", Content: pkg.Binaries[path]}},
+			})
+		}
+		doc.Components = append(doc.Components, component)
+		cliDependency.DependsOn = append(cliDependency.DependsOn, component.BOMRef)
+
+		dependency := cycloneDXDependency{Ref: component.BOMRef, DependsOn: make([]string, 0)}
+		for _, dep := range pkg.Dependencies {
+			purl := dep.purl()
+			dependency.DependsOn = append(dependency.DependsOn, purl)
+			if libraries[purl] {
+				continue
+			}
+			libraries[purl] = true
+			libraryComponents = append(libraryComponents, cycloneDXComponent{
+				Type:    "library",
+				BOMRef:  purl,
+				Name:    dep.Name,
+				Version: dep.Version,
+				Purl:    purl,
+			})
+		}
+		doc.Dependencies = append(doc.Dependencies, dependency)
+	}
+	doc.Components = append(doc.Components, libraryComponents...)
+	doc.Dependencies = append([]cycloneDXDependency{cliDependency}, doc.Dependencies...)
+	return doc
+}
+
+func newSPDXDocument(pkgs []sbomPackage, cliHash, id string, created time.Time) *spdxDocument {
+	cliPackage := spdxPackage{
+		Name:             sbomToolName,
+		SPDXID:           "SPDXRef-Package-" + sbomToolName,
+		VersionInfo:      version.Version,
+		DownloadLocation: "git+https://github.com/akamai/cli.git@v" + version.Version,
+		LicenseConcluded: noAssertion,
+		LicenseDeclared:  "Apache-2.0",
+		CopyrightText:    noAssertion,
+		ExternalRefs:     []spdxExternalRef{purlRef(cliPurl())},
+	}
+	if cliHash != "" {
+		cliPackage.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: cliHash}}
+	}
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              sbomToolName,
+		DocumentNamespace: "https://spdx.org/spdxdocs/" + sbomToolName + "-" + id,
+		CreationInfo: spdxCreationInfo{
+			Created:  created.UTC().Format(time.RFC3339),
+			Creators: []string{"Organization: " + sbomToolVendor, "Tool: " + sbomToolName + "-" + version.Version},
+		},
+		Packages: []spdxPackage{cliPackage},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: cliPackage.SPDXID},
+		},
+	}
+
+	libraries := make(map[string]bool)
+	var libraryPackages []spdxPackage
+	for _, pkg := range pkgs {
+		spdxPkg := spdxPackage{
+			Name:             pkg.Name,
+			SPDXID:           spdxID("Package", pkg.Name),
+			VersionInfo:      pkg.Commit,
+			DownloadLocation: noAssertion,
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  noAssertion,
+			CopyrightText:    noAssertion,
+		}
+		if pkg.Repo != "" {
+			spdxPkg.DownloadLocation = "git+" + pkg.Repo
+			if pkg.Commit != "" {
+				spdxPkg.DownloadLocation += "@" + pkg.Commit
+			}
+		}
+		if purl := pkg.purl(); purl != "" {
+			spdxPkg.ExternalRefs = []spdxExternalRef{purlRef(purl)}
+		}
+		doc.Packages = append(doc.Packages, spdxPkg)
+		doc.Relationships = append(doc.Relationships,
+			spdxRelationship{SPDXElementID: cliPackage.SPDXID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: spdxPkg.SPDXID})
+
+		for _, path := range sortedBinaries(pkg.Binaries) {
+			file := spdxFile{
+				FileName:         "./" + path,
+				SPDXID:           spdxID("File", pkg.Name+"-"+path),
+				Checksums:        []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: pkg.Binaries[path]}},
+				LicenseConcluded: noAssertion,
+				CopyrightText:    noAssertion,
+			}
+			doc.Files = append(doc.Files, file)
+			doc.Relationships = append(doc.Relationships,
+				spdxRelationship{SPDXElementID: spdxPkg.SPDXID, RelationshipType: "CONTAINS", RelatedSPDXElement: file.SPDXID})
+		}
+
+		for _, dep := range pkg.Dependencies {
+			purl := dep.purl()
+			libraryID := spdxID("Library", strings.TrimPrefix(purl, "pkg:"))
+			doc.Relationships = append(doc.Relationships,
+				spdxRelationship{SPDXElementID: spdxPkg.SPDXID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: libraryID})
+			if libraries[purl] {
+				continue
+			}
+			libraries[purl] = true
+			libraryPackages = append(libraryPackages, spdxPackage{
+				Name:             dep.Name,
+				SPDXID:           libraryID,
+				VersionInfo:      dep.Version,
+				DownloadLocation: noAssertion,
+				LicenseConcluded: noAssertion,
+				LicenseDeclared:  noAssertion,
+				CopyrightText:    noAssertion,
+				ExternalRefs:     []spdxExternalRef{purlRef(purl)},
+			})
+		}
+	}
+	doc.Packages = append(doc.Packages, libraryPackages...)
+	return doc
+}
+
+func purlRef(purl string) spdxExternalRef {
+	return spdxExternalRef{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}
+}
+
+// spdxID returns an SPDX element identifier, which may only contain letters, numbers, "." and "-"
+func spdxID(kind, name string) string {
+	return "SPDXRef-" + kind + "-" + strings.Trim(spdxInvalidIDChars.ReplaceAllString(name, "-"), "-")
+}
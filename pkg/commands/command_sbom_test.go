@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/version"
+)
+
+func TestCmdSBOM(t *testing.T) {
+	commit := plumbing.Hash{1}.String()
+	binarySum := "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	cliSum, err := fileChecksum(exe)
+	require.NoError(t, err)
+
+	decode := func(t *testing.T, data string, v interface{}) {
+		require.NoError(t, json.Unmarshal([]byte(data), v))
+	}
+	expectedCycloneDX := func(t *testing.T, data string) {
+		var doc cycloneDXDocument
+		decode(t, data, &doc)
+		assert.Equal(t, "CycloneDX", doc.BOMFormat)
+		assert.Equal(t, "urn:uuid:00000000-0000-0000-0000-000000000001", doc.SerialNumber)
+		assert.Equal(t, "2021-03-01T10:00:00Z", doc.Metadata.Timestamp)
+		assert.Equal(t, cycloneDXComponent{
+			Type: "application", BOMRef: cliPurl(), Name: "akamai-cli", Version: version.Version, Purl: cliPurl(),
+			Hashes: []cycloneDXHash{{Alg: "SHA-256", Content: cliSum}},
+		}, doc.Metadata.Component)
+		assert.Equal(t, []cycloneDXComponent{
+			{
+				Type: "application", BOMRef: "package:cli-echo", Name: "cli-echo", Version: commit,
+				Purl:               "pkg:github/akamai/cli-echo@" + commit,
+				ExternalReferences: []cycloneDXReference{{Type: "vcs", URL: "https://github.com/akamai/cli-echo.git"}},
+				Properties:         []cycloneDXProperty{{Name: "akamai-cli:language", Value: packages.Go}},
+				Components: []cycloneDXComponent{{
+					Type: "file", BOMRef: "package:cli-echo:bin/akamai-echo", Name: "bin/akamai-echo",
+					Hashes: []cycloneDXHash{{Alg: "SHA-256", Content: binarySum}},
+				}},
+			},
+			{
+				Type: "application", BOMRef: "package:cli-installed", Name: "cli-installed",
+				Properties: []cycloneDXProperty{{Name: "akamai-cli:source", Value: "/tmp/cli-installed"}, {Name: "akamai-cli:language", Value: packages.Go}},
+			},
+			{Type: "library", BOMRef: "pkg:golang/github.com/apex/log@v1.9.0", Name: "github.com/apex/log", Version: "v1.9.0", Purl: "pkg:golang/github.com/apex/log@v1.9.0"},
+		}, doc.Components)
+		assert.Equal(t, []cycloneDXDependency{
+			{Ref: cliPurl(), DependsOn: []string{"package:cli-echo", "package:cli-installed"}},
+			{Ref: "package:cli-echo", DependsOn: []string{"pkg:golang/github.com/apex/log@v1.9.0"}},
+			{Ref: "package:cli-installed", DependsOn: []string{"pkg:golang/github.com/apex/log@v1.9.0"}},
+		}, doc.Dependencies)
+	}
+	expectedSPDX := func(t *testing.T, data string) {
+		var doc spdxDocument
+		decode(t, data, &doc)
+		assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+		assert.Equal(t, "https://spdx.org/spdxdocs/akamai-cli-00000000-0000-0000-0000-000000000001", doc.DocumentNamespace)
+		assert.Equal(t, "2021-03-01T10:00:00Z", doc.CreationInfo.Created)
+		var names []string
+		for _, pkg := range doc.Packages {
+			names = append(names, pkg.SPDXID)
+		}
+		assert.Equal(t, []string{"SPDXRef-Package-akamai-cli", "SPDXRef-Package-cli-echo", "SPDXRef-Package-cli-installed",
+			"SPDXRef-Library-golang-github.com-apex-log-v1.9.0"}, names)
+		assert.Equal(t, []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: cliSum}}, doc.Packages[0].Checksums)
+		assert.Equal(t, "git+https://github.com/akamai/cli-echo.git@"+commit, doc.Packages[1].DownloadLocation)
+		assert.Equal(t, noAssertion, doc.Packages[2].DownloadLocation)
+		assert.Equal(t, []spdxFile{{
+			FileName: "./bin/akamai-echo", SPDXID: "SPDXRef-File-cli-echo-bin-akamai-echo",
+			Checksums:        []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: binarySum}},
+			LicenseConcluded: noAssertion, CopyrightText: noAssertion,
+		}}, doc.Files)
+		assert.Equal(t, []spdxRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Package-akamai-cli"},
+			{SPDXElementID: "SPDXRef-Package-akamai-cli", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-cli-echo"},
+			{SPDXElementID: "SPDXRef-Package-cli-echo", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-File-cli-echo-bin-akamai-echo"},
+			{SPDXElementID: "SPDXRef-Package-cli-echo", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Library-golang-github.com-apex-log-v1.9.0"},
+			{SPDXElementID: "SPDXRef-Package-akamai-cli", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-cli-installed"},
+			{SPDXElementID: "SPDXRef-Package-cli-installed", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Library-golang-github.com-apex-log-v1.9.0"},
+		}, doc.Relationships)
+	}
+
+	tests := map[string]struct {
+		args      []string
+		init      func(*testing.T, *mocked, string)
+		withError string
+	}{
+		"cyclonedx by default": {
+			init: func(t *testing.T, m *mocked, home string) {
+				m.term.On("Writeln", mock.Anything).Return(0, nil).Once().Run(func(args mock.Arguments) {
+					expectedCycloneDX(t, args.Get(0).([]interface{})[0].(string))
+				})
+			},
+		},
+		"spdx": {
+			args: []string{"--format", "SPDX"},
+			init: func(t *testing.T, m *mocked, home string) {
+				m.term.On("Writeln", mock.Anything).Return(0, nil).Once().Run(func(args mock.Arguments) {
+					expectedSPDX(t, args.Get(0).([]interface{})[0].(string))
+				})
+			},
+		},
+		"save to file": {
+			args: []string{"--output", "sbom.json"},
+			init: func(t *testing.T, m *mocked, home string) {
+				m.term.On("Printf", "SBOM of %d package(s) saved to %s\n", []interface{}{2, filepath.Join(home, "sbom.json")}).Return().Once().
+					Run(func(args mock.Arguments) {
+						data, err := ioutil.ReadFile(filepath.Join(home, "sbom.json"))
+						require.NoError(t, err)
+						expectedCycloneDX(t, string(data))
+					})
+			},
+		},
+		"invalid format": {
+			args:      []string{"--format", "swid"},
+			init:      func(t *testing.T, m *mocked, home string) {},
+			withError: `Invalid format "swid", expected cyclonedx or spdx`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "cli-sbom")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(home))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			srcPath := setupBundleHome(t, home)
+			require.NoError(t, os.MkdirAll(filepath.Join(srcPath, "cli-echo", "bin"), 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(srcPath, "cli-echo", "bin", "akamai-echo"), []byte("foo"), 0755))
+			for _, pkg := range []string{"cli-echo", "cli-installed"} {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(srcPath, pkg, "go.mod"), []byte("module test\n\nrequire github.com/apex/log v1.9.0\n"), 0644))
+			}
+			require.NoError(t, writeInstallMetadata(filepath.Join(srcPath, "cli-echo"), &installMetadata{
+				Repo: "https://github.com/akamai/cli-echo.git", Commit: commit,
+			}))
+
+			sbomTime = func() time.Time { return time.Date(2021, 3, 1, 11, 0, 0, 0, time.FixedZone("CET", 3600)) }
+			sbomID = func() (uuid.UUID, error) { return uuid.UUID{15: 1}, nil }
+			defer func() {
+				sbomTime, sbomID = time.Now, uuid.NewRandom
+			}()
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name:   "sbom",
+				Action: cmdSBOM(m.gitRepo),
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "format", Value: sbomFormatCycloneDX},
+					&cli.StringFlag{Name: "output"},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "sbom")
+			for _, arg := range test.args {
+				if arg == "sbom.json" {
+					arg = filepath.Join(home, arg)
+				}
+				args = append(args, arg)
+			}
+
+			test.init(t, m, home)
+			err = app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
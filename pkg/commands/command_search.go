@@ -16,16 +16,15 @@ package commands
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/akamai/cli/pkg/log"
-	"io/ioutil"
-	"net/http"
-	"os"
 	"sort"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/registry"
 	"github.com/akamai/cli/pkg/terminal"
 
 	"github.com/fatih/color"
@@ -34,27 +33,6 @@ import (
 	"github.com/akamai/cli/pkg/tools"
 )
 
-type packageList struct {
-	Version  float64              `json:"version"`
-	Packages []packageListPackage `json:"packages"`
-}
-
-type packageListPackage struct {
-	Title        string    `json:"title"`
-	Name         string    `json:"name"`
-	Version      string    `json:"version"`
-	URL          string    `json:"url"`
-	Issues       string    `json:"issues"`
-	Commands     []command `json:"commands"`
-	Requirements struct {
-		Go     string `json:"go"`
-		Php    string `json:"php"`
-		Node   string `json:"node"`
-		Ruby   string `json:"ruby"`
-		Python string `json:"python"`
-	} `json:"requirements"`
-}
-
 func cmdSearch(c *cli.Context) (e error) {
 	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
 	start := time.Now()
@@ -67,152 +45,267 @@ func cmdSearch(c *cli.Context) (e error) {
 			logger.Errorf("SEARCH ERROR: %v", e.Error())
 		}
 	}()
-	if !c.Args().Present() {
-		return cli.Exit(color.RedString("You must specify one or more keywords"), 1)
+	filter := packageFilter{Tags: c.StringSlice("tag"), Author: c.String("author")}
+	if !c.Args().Present() && len(filter.Tags) == 0 && filter.Author == "" {
+		return commandFailure(errUsage, "You must specify one or more keywords, --tag or --author")
+	}
+	if c.Bool("refresh") {
+		c.Context = withPackageListRefresh(c.Context)
 	}
 
 	packageList, err := fetchPackageList(c.Context)
 	if err != nil {
-		return cli.Exit(color.RedString(err.Error()), 1)
+		return commandFailure(errNetwork, err.Error())
 	}
 
-	err = searchPackages(c.Context, c.Args().Slice(), packageList)
-	if err != nil {
-		return cli.Exit(color.RedString(err.Error()), 1)
+	// installed packages are determined before commands not matching keywords are removed from results
+	commands := installedCommandNames(c)
+	installed := make(map[string]bool)
+	for _, pkg := range packageList.Packages {
+		installed[pkg.Name] = isPackageInstalled(pkg, commands)
+	}
+	found := findPackages(c.Args().Slice(), filter, packageList)
+
+	if c.Bool("json") {
+		result := make([]jsonPackage, 0)
+		for _, pkg := range found {
+			result = append(result, remotePackageToJSON(pkg, installed[pkg.Name]))
+		}
+		return writeJSON(terminal.Get(c.Context), result)
 	}
 
+	printPackages(terminal.Get(c.Context), found, installed, quietOutput(c.Context))
 	return nil
 }
 
+// fetchPackageList returns packages of all configured registries. If a package is published in several registries,
+// the one from the registry with the highest priority is returned. Registries which cannot be reached are skipped,
+// unless none of them can be.
 func fetchPackageList(ctx context.Context) (*packageList, error) {
 	logger := log.FromContext(ctx)
-	var repo string
-	repo = "https://developer.akamai.com"
-	if customRepo := os.Getenv("AKAMAI_CLI_PACKAGE_REPO"); customRepo != "" {
-		repo = customRepo
-	}
-	repo = fmt.Sprintf("%s/cli/package-list.json", repo)
-	resp, err := http.Get(repo)
+	registries, err := registry.FromConfig(config.Get(ctx))
 	if err != nil {
-		return nil, fmt.Errorf("unable to fetch remote Package List (%s)", err.Error())
+		return nil, fmt.Errorf("invalid registries configuration (%s)", err.Error())
 	}
 
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			logger.Error(err.Error())
-		}
-	}()
-
 	result := &packageList{}
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("unable to fetch remote Package List (%s)", err.Error())
+	seen := make(map[string]bool)
+	var firstErr error
+	for _, reg := range registries {
+		list, err := fetchRegistryPackageList(ctx, reg)
+		if err != nil {
+			logger.Warnf("Unable to fetch package list of reg %s: %s", reg.Name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if result.Version == 0 {
+			result.Version = list.Version
+		}
+		for _, pkg := range list.Packages {
+			if seen[pkg.Name] {
+				continue
+			}
+			seen[pkg.Name] = true
+			pkg.Registry = reg.Name
+			result.Packages = append(result.Packages, pkg)
+		}
 	}
-
-	err = json.Unmarshal(body, result)
-	if err != nil {
-		return nil, fmt.Errorf("unable to fetch remote Package List (%s)", err.Error())
+	if firstErr != nil && len(result.Packages) == 0 {
+		return nil, firstErr
+	}
+	if firstErr != nil {
+		terminal.Get(ctx).WriteErrorf("%s\n", color.YellowString("Some package registries could not be reached, results may be incomplete"))
 	}
-
 	return result, nil
 }
 
-func searchPackages(ctx context.Context, keywords []string, packageList *packageList) error {
-	results := make(map[int]map[string]packageListPackage)
+// relevance of a keyword found in each package field, a match in the package name being the most relevant
+const (
+	nameWeight        = 100
+	titleWeight       = 50
+	tagWeight         = 40
+	commandWeight     = 30
+	aliasWeight       = 20
+	descriptionWeight = 1
+)
 
-	term := terminal.Get(ctx)
+// packageFilter restricts search results to packages having all Tags and, if set, published by Author
+type packageFilter struct {
+	Tags   []string
+	Author string
+}
 
-	var hits int
-	for key, pkg := range packageList.Packages {
-		hits = 0
-		validCmds := make([]command, 0)
-		for _, keyword := range keywords {
-			keyword = strings.ToLower(keyword)
-			if strings.Contains(strings.ToLower(pkg.Name), keyword) {
-				hits += 100
+func (f packageFilter) matches(pkg packageListPackage) bool {
+	if f.Author != "" && !strings.Contains(strings.ToLower(pkg.Author), strings.ToLower(f.Author)) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		found := false
+		for _, pkgTag := range pkg.Tags {
+			if strings.EqualFold(tag, pkgTag) {
+				found = true
+				break
 			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// findPackages returns packages matching given keywords and filter, ordered by relevance and name.
+// Only matching commands are kept for each package. If no keywords are given, all packages matching the filter are returned.
+func findPackages(keywords []string, filter packageFilter, packageList *packageList) []packageListPackage {
+	type result struct {
+		pkg  packageListPackage
+		hits int
+	}
+	results := make([]result, 0)
 
-			if strings.Contains(strings.ToLower(pkg.Title), keyword) {
-				hits += 50
+	for _, pkg := range packageList.Packages {
+		if !filter.matches(pkg) {
+			continue
+		}
+		var hits int
+		for _, keyword := range keywords {
+			keyword = strings.ToLower(keyword)
+			hits += matchScore(keyword, pkg.Name, nameWeight)
+			hits += matchScore(keyword, pkg.Title, titleWeight)
+			for _, tag := range pkg.Tags {
+				hits += matchScore(keyword, tag, tagWeight)
 			}
 		}
 
+		validCmds := make([]command, 0)
 		for _, cmd := range pkg.Commands {
-			cmdMatches := false
+			var cmdHits int
 			for _, keyword := range keywords {
 				keyword = strings.ToLower(keyword)
-
-				if strings.Contains(strings.ToLower(cmd.Name), keyword) {
-					hits += 30
-					cmdMatches = true
-				}
-
+				cmdHits += matchScore(keyword, cmd.Name, commandWeight)
 				for _, alias := range cmd.Aliases {
-					if strings.Contains(strings.ToLower(alias), keyword) {
-						hits += 20
-						cmdMatches = true
-					}
-				}
-
-				if strings.Contains(strings.ToLower(cmd.Description), keyword) {
-					hits++
-					cmdMatches = true
+					cmdHits += matchScore(keyword, alias, aliasWeight)
 				}
+				cmdHits += matchScore(keyword, cmd.Description, descriptionWeight)
 			}
-
-			if cmdMatches {
+			if cmdHits > 0 || len(keywords) == 0 {
 				validCmds = append(validCmds, cmd)
 			}
+			hits += cmdHits
+		}
+		pkg.Commands = validCmds
+
+		if hits > 0 || len(keywords) == 0 {
+			results = append(results, result{pkg, hits})
 		}
-		packageList.Packages[key].Commands = validCmds
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].hits != results[j].hits {
+			return results[i].hits > results[j].hits
+		}
+		return results[i].pkg.Name < results[j].pkg.Name
+	})
+
+	found := make([]packageListPackage, 0, len(results))
+	for _, r := range results {
+		found = append(found, r.pkg)
+	}
+	return found
+}
 
-		if hits > 0 {
-			if _, ok := results[hits]; !ok {
-				results[hits] = make(map[string]packageListPackage)
+// matchScore returns weight if the lowercase keyword is found in text, half of it if a word of text differs
+// from the keyword by a typo, and 0 otherwise. Typos are not tolerated in keywords shorter than 4 characters.
+func matchScore(keyword, text string, weight int) int {
+	text = strings.ToLower(text)
+	if strings.Contains(text, keyword) {
+		return weight
+	}
+	maxDistance := 1
+	switch {
+	case len(keyword) < 4:
+		return 0
+	case len(keyword) >= 8:
+		maxDistance = 2
+	}
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if editDistance(keyword, word) <= maxDistance {
+			if weight > 1 {
+				return weight / 2
 			}
-			results[hits][pkg.Name] = packageList.Packages[key]
+			return weight
 		}
 	}
+	return 0
+}
 
-	resultHits := make([]int, 0)
-	resultPkgs := make([]string, 0)
-	for hits := range results {
-		resultHits = append(resultHits, hits)
-		for _, pkg := range results[hits] {
-			resultPkgs = append(resultPkgs, pkg.Name)
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
 		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
 	}
+	return a
+}
 
-	sort.Sort(sort.Reverse(sort.IntSlice(resultHits)))
-	sort.Strings(resultPkgs)
+// printPackages displays search results, marking packages which are already installed
+func printPackages(term terminal.Terminal, found []packageListPackage, installed map[string]bool, quiet bool) {
 	bold := color.New(color.FgWhite, color.Bold)
 
-	term.Printf(color.YellowString("Results Found:")+" %d\n\n", len(resultPkgs))
-
-	for _, hits := range resultHits {
-		for _, pkgName := range resultPkgs {
-			if _, ok := results[hits][pkgName]; ok {
-				pkg := results[hits][pkgName]
-				term.Printf(color.GreenString("Package: ")+"%s [%s]\n", pkg.Title, color.BlueString(pkg.Name))
-				for _, cmd := range results[hits][pkgName].Commands {
-					var aliases string
-					if len(cmd.Aliases) == 1 {
-						aliases = fmt.Sprintf("(alias: %s)", cmd.Aliases[0])
-					} else if len(cmd.Aliases) > 1 {
-						aliases = fmt.Sprintf("(aliases: %s)", strings.Join(cmd.Aliases, ", "))
-					}
-
-					term.Printf(bold.Sprintf("  Command:")+" %s %s\n", cmd.Name, aliases)
-					term.Printf(bold.Sprintf("  Version:")+" %s\n", cmd.Version)
-					term.Printf(bold.Sprintf("  Description:")+" %s\n\n", cmd.Description)
-				}
+	term.Printf(color.YellowString("Results Found:")+" %d\n\n", len(found))
+
+	for _, pkg := range found {
+		var status string
+		if installed[pkg.Name] {
+			status = " " + color.CyanString("(installed)")
+		}
+		term.Printf(color.GreenString("Package: ")+"%s [%s]%s\n", pkg.Title, color.BlueString(pkg.Name), status)
+		if len(pkg.Tags) > 0 {
+			term.Printf(bold.Sprintf("  Tags:")+" %s\n", strings.Join(pkg.Tags, ", "))
+		}
+		for _, cmd := range pkg.Commands {
+			var aliases string
+			if len(cmd.Aliases) == 1 {
+				aliases = fmt.Sprintf("(alias: %s)", cmd.Aliases[0])
+			} else if len(cmd.Aliases) > 1 {
+				aliases = fmt.Sprintf("(aliases: %s)", strings.Join(cmd.Aliases, ", "))
 			}
+
+			term.Printf(bold.Sprintf("  Command:")+" %s %s\n", cmd.Name, aliases)
+			term.Printf(bold.Sprintf("  Version:")+" %s\n", cmd.Version)
+			term.Printf(bold.Sprintf("  Description:")+" %s\n\n", cmd.Description)
 		}
 	}
 
-	if len(resultHits) > 0 {
+	if len(found) > 0 && !quiet {
 		term.Printf("\nInstall using \"%s\".\n", color.BlueString("%s install [package]", tools.Self()))
 	}
-
-	return nil
 }
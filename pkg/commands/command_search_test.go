@@ -21,6 +21,7 @@ func TestCmdSearch(t *testing.T) {
 		args         []string
 		responseFile string
 		init         func(*terminal.Mock)
+		quiet        bool
 		withError    string
 	}{
 		"search and find packages based on criteria": {
@@ -30,7 +31,7 @@ func TestCmdSearch(t *testing.T) {
 				bold := color.New(color.FgWhite, color.Bold)
 				m.On("Printf", color.YellowString("Results Found:")+" %d\n\n", []interface{}{5})
 
-				m.On("Printf", color.GreenString("Package: ")+"%s [%s]\n", []interface{}{"Test CLI", color.BlueString("test-cli")}).
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Test CLI", color.BlueString("test-cli"), ""}).
 					Return().Once()
 				m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"test-cmd", "(aliases: test, abc)"}).
 					Return().Once()
@@ -39,10 +40,10 @@ func TestCmdSearch(t *testing.T) {
 				m.On("Printf", bold.Sprintf("  Description:")+" %s\n\n", []interface{}{"test for highest score"}).
 					Return().Once()
 
-				m.On("Printf", color.GreenString("Package: ")+"%s [%s]\n", []interface{}{"Test no cmd match", color.BlueString("test-no-cmd-match")}).
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Test no cmd match", color.BlueString("test-no-cmd-match"), ""}).
 					Return().Once()
 
-				m.On("Printf", color.GreenString("Package: ")+"%s [%s]\n", []interface{}{"Test CLI", color.BlueString("cli-1")}).
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Test CLI", color.BlueString("cli-1"), ""}).
 					Return().Once()
 				m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"title-cmd", ""}).
 					Return().Once()
@@ -51,7 +52,7 @@ func TestCmdSearch(t *testing.T) {
 				m.On("Printf", bold.Sprintf("  Description:")+" %s\n\n", []interface{}{"test for match on title"}).
 					Return().Once()
 
-				m.On("Printf", color.GreenString("Package: ")+"%s [%s]\n", []interface{}{"Some CLI", color.BlueString("cli-4")}).
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Some CLI", color.BlueString("cli-4"), ""}).
 					Return().Once()
 				m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"test", ""}).
 					Return().Once()
@@ -60,7 +61,7 @@ func TestCmdSearch(t *testing.T) {
 				m.On("Printf", bold.Sprintf("  Description:")+" %s\n\n", []interface{}{"test for match on command name"}).
 					Return().Once()
 
-				m.On("Printf", color.GreenString("Package: ")+"%s [%s]\n", []interface{}{"Some CLI", color.BlueString("cli-2")}).
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Some CLI", color.BlueString("cli-2"), ""}).
 					Return().Once()
 				m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"desc-cmd", ""}).
 					Return().Once()
@@ -80,6 +81,91 @@ func TestCmdSearch(t *testing.T) {
 				m.On("Printf", color.YellowString("Results Found:")+" %d\n\n", []interface{}{0}).Return().Once()
 			},
 		},
+		"search packages in JSON format": {
+			args:         []string{"--json", "desc"},
+			responseFile: "packages-response.json",
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", jsonOutput(`[
+					{"name": "cli-2", "title": "Some CLI", "installed": false,
+						"commands": [{"name": "desc-cmd", "version": "1.0.0", "description": "test - match on description"}]}
+				]`)).Return(0, nil).Once()
+			},
+		},
+		"typo in keyword": {
+			args:         []string{"proprety"},
+			responseFile: "packages-tags-response.json",
+			init: func(m *terminal.Mock) {
+				bold := color.New(color.FgWhite, color.Bold)
+				m.On("Printf", color.YellowString("Results Found:")+" %d\n\n", []interface{}{1}).Return().Once()
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Property Manager", color.BlueString("cli-property-manager"), ""}).
+					Return().Once()
+				m.On("Printf", bold.Sprintf("  Tags:")+" %s\n", []interface{}{"property, delivery"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"property-manager", "(alias: pm)"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Version:")+" %s\n", []interface{}{"1.0.0"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Description:")+" %s\n\n", []interface{}{"Manage property configurations"}).Return().Once()
+				m.On("Printf", "\nInstall using \"%s\".\n", []interface{}{color.BlueString("%s install [package]", tools.Self())}).Return().Once()
+			},
+		},
+		"installed package": {
+			args:         []string{"echo"},
+			responseFile: "packages-tags-response.json",
+			init: func(m *terminal.Mock) {
+				bold := color.New(color.FgWhite, color.Bold)
+				m.On("Printf", color.YellowString("Results Found:")+" %d\n\n", []interface{}{1}).Return().Once()
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Echo", color.BlueString("cli-echo"), " " + color.CyanString("(installed)")}).
+					Return().Once()
+				m.On("Printf", bold.Sprintf("  Tags:")+" %s\n", []interface{}{"testing"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"echo", ""}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Version:")+" %s\n", []interface{}{"1.0.0"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Description:")+" %s\n\n", []interface{}{"Print given arguments"}).Return().Once()
+				m.On("Printf", "\nInstall using \"%s\".\n", []interface{}{color.BlueString("%s install [package]", tools.Self())}).Return().Once()
+			},
+		},
+		"quiet output without install hint": {
+			args:         []string{"echo"},
+			responseFile: "packages-tags-response.json",
+			quiet:        true,
+			init: func(m *terminal.Mock) {
+				bold := color.New(color.FgWhite, color.Bold)
+				m.On("Printf", color.YellowString("Results Found:")+" %d\n\n", []interface{}{1}).Return().Once()
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Echo", color.BlueString("cli-echo"), " " + color.CyanString("(installed)")}).
+					Return().Once()
+				m.On("Printf", bold.Sprintf("  Tags:")+" %s\n", []interface{}{"testing"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"echo", ""}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Version:")+" %s\n", []interface{}{"1.0.0"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Description:")+" %s\n\n", []interface{}{"Print given arguments"}).Return().Once()
+			},
+		},
+		"filter by tag and author without keywords": {
+			args:         []string{"--tag", "delivery", "--author", "akamai"},
+			responseFile: "packages-tags-response.json",
+			init: func(m *terminal.Mock) {
+				bold := color.New(color.FgWhite, color.Bold)
+				m.On("Printf", color.YellowString("Results Found:")+" %d\n\n", []interface{}{2}).Return().Once()
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Property Manager", color.BlueString("cli-property-manager"), ""}).
+					Return().Once()
+				m.On("Printf", bold.Sprintf("  Tags:")+" %s\n", []interface{}{"property, delivery"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"property-manager", "(alias: pm)"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Description:")+" %s\n\n", []interface{}{"Manage property configurations"}).Return().Once()
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s]%s\n", []interface{}{"Purge", color.BlueString("cli-purge"), ""}).
+					Return().Once()
+				m.On("Printf", bold.Sprintf("  Tags:")+" %s\n", []interface{}{"delivery, cache"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"purge", ""}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Description:")+" %s\n\n", []interface{}{"Purge content from the edge"}).Return().Once()
+				m.On("Printf", bold.Sprintf("  Version:")+" %s\n", []interface{}{"1.0.0"}).Return().Twice()
+				m.On("Printf", "\nInstall using \"%s\".\n", []interface{}{color.BlueString("%s install [package]", tools.Self())}).Return().Once()
+			},
+		},
+		"filter by tag in JSON format": {
+			args:         []string{"--json", "--tag", "CACHE"},
+			responseFile: "packages-tags-response.json",
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", jsonOutput(`[
+					{"name": "cli-purge", "title": "Purge", "author": "Akamai Technologies", "tags": ["delivery", "cache"], "installed": false,
+						"commands": [{"name": "purge", "version": "1.0.0", "description": "Purge content from the edge"}]}
+				]`)).Return(0, nil).Once()
+			},
+		},
 		"invalid response json": {
 			args:         []string{"abc123"},
 			responseFile: "invalid-response.json",
@@ -89,7 +175,7 @@ func TestCmdSearch(t *testing.T) {
 		"no args passed": {
 			args:      []string{},
 			init:      func(m *terminal.Mock) {},
-			withError: "You must specify one or more keywords",
+			withError: "You must specify one or more keywords, --tag or --author",
 		},
 	}
 
@@ -106,12 +192,20 @@ func TestCmdSearch(t *testing.T) {
 		require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
 		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
 		t.Run(name, func(t *testing.T) {
+			defer useTempCache(t)()
 			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
 			command := &cli.Command{
 				Name:   "search",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "json"}, &cli.StringSliceFlag{Name: "tag"}, &cli.StringFlag{Name: "author"}},
 				Action: cmdSearch,
 			}
 			app, ctx := setupTestApp(command, m)
+			// echo command of cli-echo package is installed
+			app.Commands = append(app.Commands, &cli.Command{Name: "echo"})
+			if test.quiet {
+				ctx = WithQuietOutput(ctx)
+			}
 			args := os.Args[0:1]
 			args = append(args, "search")
 			args = append(args, test.args...)
@@ -130,3 +224,26 @@ func TestCmdSearch(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchScore(t *testing.T) {
+	tests := map[string]struct {
+		keyword  string
+		text     string
+		weight   int
+		expected int
+	}{
+		"substring":                    {keyword: "prop", text: "Property Manager", weight: 10, expected: 10},
+		"one typo":                     {keyword: "purgr", text: "cli-purge", weight: 10, expected: 5},
+		"two typos in a long keyword":  {keyword: "proprety", text: "property-manager", weight: 10, expected: 5},
+		"two typos in a short keyword": {keyword: "pugre", text: "cli-purge", weight: 10, expected: 0},
+		"typo in a too short keyword":  {keyword: "ech", text: "ecko", weight: 10, expected: 0},
+		"typo in description":          {keyword: "contenr", text: "Purge content", weight: descriptionWeight, expected: 1},
+		"no match":                     {keyword: "abc123", text: "abc", weight: 10, expected: 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, matchScore(test.keyword, test.text, test.weight))
+		})
+	}
+}
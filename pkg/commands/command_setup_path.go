@@ -0,0 +1,62 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func cmdSetupPath(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("SETUP PATH START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("SETUP PATH FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("SETUP PATH ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	binDir, err := tools.GetAkamaiCliBinPath()
+	if err != nil {
+		return commandFailure(errConfig, "Unable to determine bin directory location: %s", err)
+	}
+	if err := writeCommandShims(binDir); err != nil {
+		return cli.Exit(color.RedString("Unable to write command shims: %s", err), 1)
+	}
+	logger.Debugf("Command shims written to %s", binDir)
+
+	location, added, err := addToUserPath(binDir)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to add %s to PATH: %s", binDir, err), 1)
+	}
+	if !added {
+		term.Printf("%s is already in your PATH, set in %s\n", binDir, location)
+		return nil
+	}
+	term.Printf("Added %s to your PATH in %s\n", binDir, location)
+	term.Printf("Open a new terminal, then run installed commands as akamai-<command>\n")
+	return nil
+}
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestCommandShims(t *testing.T) {
+	tests := map[string]struct {
+		goos     string
+		self     string
+		expected map[string]string
+	}{
+		"windows": {
+			goos: "windows",
+			self: `C:\akamai\akamai.exe`,
+			expected: map[string]string{
+				"akamai-echo.cmd": "@echo off\r\nrem " + shimHeader + "\r\n\"C:\\akamai\\akamai.exe\" echo %*\r\n",
+				"akamai-echo.ps1": "# " + shimHeader + "\r\n& 'C:\\akamai\\akamai.exe' echo @args\r\nexit $LASTEXITCODE\r\n",
+			},
+		},
+		"windows path with special characters": {
+			goos: "windows",
+			self: `C:\100% $env:x\it's\akamai.exe`,
+			expected: map[string]string{
+				"akamai-echo.cmd": "@echo off\r\nrem " + shimHeader + "\r\n\"C:\\100%% $env:x\\it's\\akamai.exe\" echo %*\r\n",
+				"akamai-echo.ps1": "# " + shimHeader + "\r\n& 'C:\\100% $env:x\\it''s\\akamai.exe' echo @args\r\nexit $LASTEXITCODE\r\n",
+			},
+		},
+		"linux": {
+			goos: "linux",
+			self: "/usr/local/bin/akamai",
+			expected: map[string]string{
+				"akamai-echo": "#!/bin/sh\n# " + shimHeader + "\nexec '/usr/local/bin/akamai' echo \"$@\"\n",
+			},
+		},
+		"linux path with special characters": {
+			goos: "linux",
+			self: "/home/it's $(id)/`id`/akamai",
+			expected: map[string]string{
+				"akamai-echo": "#!/bin/sh\n# " + shimHeader + "\nexec '/home/it'\\''s $(id)/`id`/akamai' echo \"$@\"\n",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, commandShims(test.goos, test.self, "echo"))
+		})
+	}
+}
+
+func TestWriteCommandShims(t *testing.T) {
+	home, err := ioutil.TempDir("", "cli-shims")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(home))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+	setupBundleHome(t, home)
+	binDir := filepath.Join(home, "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "akamai-removed"), []byte("# "+shimHeader+"\n"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "custom"), []byte("#!/bin/sh\n"), 0755))
+
+	require.NoError(t, writeCommandShims(binDir))
+
+	files, err := ioutil.ReadDir(binDir)
+	require.NoError(t, err)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	expected := []string{"akamai-echo", "akamai-installed", "custom"}
+	if runtime.GOOS == "windows" {
+		expected = []string{"akamai-echo.cmd", "akamai-echo.ps1", "akamai-installed.cmd", "akamai-installed.ps1", "custom"}
+	}
+	assert.Equal(t, expected, names)
+}
+
+func TestCmdSetupPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the user PATH is stored in the registry on Windows")
+	}
+	tests := map[string]struct {
+		shell           string
+		profile         string
+		existing        string
+		expectedLine    string
+		expectedMessage string
+	}{
+		"add to zsh startup file": {
+			shell:           "/bin/zsh",
+			profile:         ".zshrc",
+			existing:        "alias ll='ls -l'\n",
+			expectedLine:    `export PATH="${BIN}:$PATH"`,
+			expectedMessage: "Added %s to your PATH in %s\n",
+		},
+		"add to fish config": {
+			shell:           "/usr/bin/fish",
+			profile:         filepath.Join(".config", "fish", "config.fish"),
+			expectedLine:    `set -gx PATH "${BIN}" $PATH`,
+			expectedMessage: "Added %s to your PATH in %s\n",
+		},
+		"unknown shell": {
+			shell:           "/bin/ksh",
+			profile:         ".profile",
+			expectedLine:    `export PATH="${BIN}:$PATH"`,
+			expectedMessage: "Added %s to your PATH in %s\n",
+		},
+		"already in path": {
+			shell:           "/bin/zsh",
+			profile:         ".zshrc",
+			existing:        "export PATH=\"${BIN}:$PATH\"\n",
+			expectedLine:    `export PATH="${BIN}:$PATH"`,
+			expectedMessage: "%s is already in your PATH, set in %s\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "cli-setup-path")
+			require.NoError(t, err)
+			shell, userHome := os.Getenv("SHELL"), os.Getenv("HOME")
+			homedir.DisableCache = true
+			defer func() {
+				homedir.DisableCache = false
+				require.NoError(t, os.RemoveAll(home))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+				require.NoError(t, os.Setenv("HOME", userHome))
+				require.NoError(t, os.Setenv("SHELL", shell))
+			}()
+			require.NoError(t, os.Setenv("HOME", home))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			require.NoError(t, os.Setenv("SHELL", test.shell))
+			setupBundleHome(t, home)
+			binDir := filepath.Join(home, ".akamai-cli", "bin")
+			profile := filepath.Join(home, test.profile)
+			if test.existing != "" {
+				require.NoError(t, ioutil.WriteFile(profile, []byte(strings.ReplaceAll(test.existing, "${BIN}", binDir)), 0644))
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.term.On("Printf", test.expectedMessage, []interface{}{binDir, profile}).Return().Once()
+			if test.existing == "" || !strings.Contains(test.existing, "${BIN}") {
+				m.term.On("Printf", "Open a new terminal, then run installed commands as akamai-<command>\n", []interface{}(nil)).Return().Once()
+			}
+			app, ctx := setupTestApp(&cli.Command{Name: "setup-path", Action: cmdSetupPath}, m)
+
+			err = app.RunContext(ctx, []string{os.Args[0], "setup-path"})
+			require.NoError(t, err)
+			m.term.AssertExpectations(t)
+
+			data, err := ioutil.ReadFile(profile)
+			require.NoError(t, err)
+			expectedLine := strings.ReplaceAll(test.expectedLine, "${BIN}", binDir)
+			assert.Equal(t, 1, strings.Count(string(data), expectedLine))
+			if test.existing != "" {
+				assert.True(t, strings.HasPrefix(string(data), strings.ReplaceAll(test.existing, "${BIN}", binDir)))
+			}
+			_, err = os.Stat(filepath.Join(binDir, "akamai-echo"))
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -15,17 +15,20 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 
+	"github.com/akamai/cli/pkg/config"
 	"github.com/akamai/cli/pkg/log"
 	"github.com/akamai/cli/pkg/packages"
 
 	"github.com/akamai/cli/pkg/stats"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
 
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
@@ -35,87 +38,216 @@ import (
 
 func cmdSubcommand(git git.Repository, langManager packages.LangManager) cli.ActionFunc {
 	return func(c *cli.Context) error {
-		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
-		logger := log.WithCommand(c.Context, c.Command.Name)
-		term := terminal.Get(c.Context)
+		return runSubcommand(c, git, langManager, strings.ToLower(c.Command.Name))
+	}
+}
 
-		commandName := strings.ToLower(c.Command.Name)
+// cmdRenamedSubcommand forwards invocations of a command renamed by its package to the command it was renamed to
+func cmdRenamedSubcommand(git git.Repository, langManager packages.LangManager, renamedTo string) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		return runSubcommand(c, git, langManager, renamedTo)
+	}
+}
 
-		executable, err := findExec(c.Context, langManager, commandName)
-		if err != nil {
-			errMsg := color.RedString("Executable \"%s\" not found.", commandName)
-			logger.Error(errMsg)
-			return cli.Exit(errMsg, 1)
-		}
+// withDeprecationWarning wraps the action of a command deprecated or renamed by its package, to warn about it before the command runs
+func withDeprecationWarning(cmd command, action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		terminal.Get(c.Context).WriteErrorf("%s\n", color.YellowString(deprecationMessage(cmd)))
+		return action(c)
+	}
+}
 
-		var packageDir string
-		if len(executable) == 1 {
-			packageDir = findPackageDir(executable[0])
-		} else if len(executable) > 1 {
-			packageDir = findPackageDir(executable[1])
-		}
+func deprecationMessage(cmd command) string {
+	msg := fmt.Sprintf("Command \"%s\" is deprecated", cmd.Name)
+	if cmd.RenamedTo != "" {
+		msg = fmt.Sprintf("Command \"%s\" was renamed to \"%s\", run \"%s %s\" instead", cmd.Name, cmd.RenamedTo, tools.Self(), cmd.RenamedTo)
+	}
+	if cmd.Deprecated != "" {
+		msg += ": " + cmd.Deprecated
+	}
+	return msg
+}
 
-		cmdPackage, _ := readPackage(packageDir)
+// runSubcommand executes the installed command with given name, passing it arguments of the invoked command
+func runSubcommand(c *cli.Context, git git.Repository, langManager packages.LangManager, commandName string) error {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	term := terminal.Get(c.Context)
 
-		if cmdPackage.Requirements.Python != "" {
-			var err error
-			if runtime.GOOS == "linux" {
-				_, err = os.Stat(filepath.Join(packageDir, ".local"))
-			} else if runtime.GOOS == "darwin" {
-				_, err = os.Stat(filepath.Join(packageDir, "Library"))
-			} else if runtime.GOOS == "windows" {
-				_, err = os.Stat(filepath.Join(packageDir, "Lib"))
-			}
+	if dir, image, ok := containerPackage(commandName); ok {
+		return runContainerCommand(c, dir, image, commandName)
+	}
 
-			if err == nil {
-				answer, err := term.Confirm("Would you like to reinstall it", true)
-				logger.Debugf("Would you like to reinstall it? %v", answer)
-				if err != nil {
-					return err
-				}
-				if !answer {
-					logger.Error(packages.ErrPackageNeedsReinstall.Error())
-					return cli.Exit(color.RedString(packages.ErrPackageNeedsReinstall.Error()), -1)
-				}
-
-				if err = uninstallPackage(c.Context, langManager, commandName, logger); err != nil {
-					return err
-				}
-
-				if _, err = installPackage(c.Context, git, langManager, commandName, false); err != nil {
-					return err
-				}
+	executable, err := findExec(c.Context, langManager, commandName)
+	if err != nil {
+		errMsg := color.RedString("Executable \"%s\" not found.", commandName)
+		logger.Error(errMsg)
+		return &commandError{kind: errNotFound, message: errMsg}
+	}
+
+	var packageDir string
+	if len(executable) == 1 {
+		packageDir = findPackageDir(executable[0])
+	} else if len(executable) > 1 {
+		packageDir = findPackageDir(executable[1])
+	}
+
+	cmdPackage, _ := readPackage(packageDir)
+
+	if cmdPackage.Requirements.Python != "" {
+		if hasLegacyPythonInstall(packageDir) {
+			answer, err := term.Confirm("Would you like to reinstall it", true)
+			logger.Debugf("Would you like to reinstall it? %v", answer)
+			if err != nil {
+				return err
+			}
+			if !answer {
+				logger.Error(packages.ErrPackageNeedsReinstall.Error())
+				return cli.Exit(color.RedString(packages.ErrPackageNeedsReinstall.Error()), -1)
 			}
-			if err := os.Setenv("PYTHONUSERBASE", packageDir); err != nil {
+
+			if err := reinstallPythonPackage(c.Context, git, langManager, commandName, packageDir, logger); err != nil {
 				return err
 			}
 		}
+		if err := activatePythonEnv(packageDir); err != nil {
+			return err
+		}
+	}
+
+	var currentCmd command
+	for _, cmd := range cmdPackage.Commands {
+		if strings.EqualFold(cmd.Name, commandName) {
+			currentCmd = cmd
+			break
+		}
 
-		var currentCmd command
-		for _, cmd := range cmdPackage.Commands {
-			if strings.EqualFold(cmd.Name, commandName) {
+		for _, alias := range cmd.Aliases {
+			if strings.EqualFold(alias, commandName) {
 				currentCmd = cmd
-				break
 			}
+		}
+	}
 
-			for _, alias := range cmd.Aliases {
-				if strings.EqualFold(alias, commandName) {
-					currentCmd = cmd
-				}
-			}
+	executable = append(executable, c.Args().Slice()...)
+	if err := os.Setenv("AKAMAI_CLI_COMMAND", commandName); err != nil {
+		return err
+	}
+	if err := os.Setenv("AKAMAI_CLI_COMMAND_VERSION", currentCmd.Version); err != nil {
+		return err
+	}
+	if err := exportPackageConfig(c.Context, cmdPackage); err != nil {
+		return err
+	}
+	if err := setPackageEnv(cmdPackage.Env); err != nil {
+		return err
+	}
+	stats.TrackEvent(c.Context, "exec", commandName, currentCmd.Version)
+	executable = findAndAppendFlags(c, executable, "edgerc", "section")
+	return passthruCommand(executable, packageWorkDir(packageDir, cmdPackage.Cwd))
+}
+
+// hasLegacyPythonInstall returns true if the Python package has dependencies installed in the package directory,
+// as done before virtual environments were introduced
+func hasLegacyPythonInstall(packageDir string) bool {
+	var err error
+	if runtime.GOOS == "linux" {
+		_, err = os.Stat(filepath.Join(packageDir, ".local"))
+	} else if runtime.GOOS == "darwin" {
+		_, err = os.Stat(filepath.Join(packageDir, "Library"))
+	} else if runtime.GOOS == "windows" {
+		_, err = os.Stat(filepath.Join(packageDir, "Lib"))
+	}
+	return err == nil
+}
+
+// reinstallPythonPackage replaces a legacy installation of the Python package with a fresh one, holding the packages lock
+// like commands modifying installed packages do. The package is left as is if another instance reinstalled it meanwhile.
+func reinstallPythonPackage(ctx context.Context, git git.Repository, langManager packages.LangManager, commandName, packageDir string, logger log.Logger) error {
+	lock, err := lockPackages(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		invalidatePackageIndex(ctx)
+		if err := lock.Unlock(); err != nil {
+			logger.Errorf("Unable to release packages lock: %s", err)
 		}
+	}()
+
+	if !hasLegacyPythonInstall(packageDir) {
+		logger.Debugf("Package already reinstalled: %s", packageDir)
+		return nil
+	}
+
+	// reinstall the same ref which was installed before
+	var ref string
+	if meta, err := readInstallMetadata(packageDir); err == nil && meta != nil {
+		ref = meta.Ref
+	}
+
+	if err := uninstallPackage(ctx, langManager, commandName, logger); err != nil {
+		return err
+	}
 
-		executable = append(executable, c.Args().Slice()...)
-		if err := os.Setenv("AKAMAI_CLI_COMMAND", commandName); err != nil {
+	if _, err := installPackage(ctx, git, langManager, commandName, ref, false, nil); err != nil {
+		return err
+	}
+	updateLockFile(ctx)
+	updateCommandShims(ctx)
+	return nil
+}
+
+// exportPackageConfig exports settings of config sections named after commands of the package, secrets included,
+// so that "akamai config set property.default-group 123" is available to the package as AKAMAI_PROPERTY_DEFAULT_GROUP.
+// Settings are exported before variables declared in package metadata, so that they take precedence.
+func exportPackageConfig(ctx context.Context, pkg subcommands) error {
+	cfg := config.Get(ctx)
+	for _, cmd := range pkg.Commands {
+		if err := cfg.ExportSectionEnv(strings.ToLower(cmd.Name)); err != nil {
 			return err
 		}
-		if err := os.Setenv("AKAMAI_CLI_COMMAND_VERSION", currentCmd.Version); err != nil {
+	}
+	return nil
+}
+
+// setPackageEnv exports environment variables declared in package metadata
+// Variables already present in the environment are left untouched, so that user settings take precedence
+func setPackageEnv(env map[string]string) error {
+	for key, value := range env {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
 			return err
 		}
-		stats.TrackEvent(c.Context, "exec", commandName, currentCmd.Version)
-		executable = findAndAppendFlags(c, executable, "edgerc", "section")
-		return passthruCommand(executable)
 	}
+	return nil
+}
+
+// activatePythonEnv activates the virtual environment of a Python package, so that the command
+// and any subprocess it spawns run with the interpreter and dependencies installed for it.
+// Packages installed before virtual environments were introduced keep using PYTHONUSERBASE until reinstalled.
+func activatePythonEnv(packageDir string) error {
+	if _, ok := packages.VenvPython(packageDir); !ok {
+		return os.Setenv("PYTHONUSERBASE", packageDir)
+	}
+	if err := os.Setenv("VIRTUAL_ENV", filepath.Join(packageDir, packages.VenvDirName)); err != nil {
+		return err
+	}
+	if err := os.Unsetenv("PYTHONHOME"); err != nil {
+		return err
+	}
+	return os.Setenv("PATH", packages.VenvBinDir(packageDir)+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// packageWorkDir returns the directory in which package command should be executed
+// Relative paths are resolved against package directory, empty string means current working directory
+func packageWorkDir(packageDir, cwd string) string {
+	if cwd == "" || filepath.IsAbs(cwd) {
+		return cwd
+	}
+	return filepath.Join(packageDir, cwd)
 }
 
 func findAndAppendFlags(c *cli.Context, target []string, flags ...string) []string {
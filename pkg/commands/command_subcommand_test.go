@@ -2,13 +2,18 @@ package commands
 
 import (
 	"flag"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/akamai/cli/pkg/config"
 	"github.com/akamai/cli/pkg/git"
 	"github.com/akamai/cli/pkg/packages"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
@@ -28,6 +33,7 @@ func TestCmdSubcommand(t *testing.T) {
 			args:    []string{"abc"},
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("ExportSectionEnv", "echo").Return(nil).Once()
 			},
 		},
 		"run installed akamai echo command as binary with edgerc location": {
@@ -35,6 +41,7 @@ func TestCmdSubcommand(t *testing.T) {
 			args:    []string{"abc"},
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("ExportSectionEnv", "echo").Return(nil).Once()
 			},
 		},
 		"run installed akamai echo command as binary with alias": {
@@ -44,6 +51,7 @@ func TestCmdSubcommand(t *testing.T) {
 			section:        "some_section",
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("ExportSectionEnv", "echo").Return(nil).Once()
 			},
 		},
 		"run installed akamai echo command with python required": {
@@ -51,6 +59,7 @@ func TestCmdSubcommand(t *testing.T) {
 			args:    []string{"abc"},
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("ExportSectionEnv", "echo-python").Return(nil).Once()
 			},
 		},
 		"run installed akamai echo command as .cmd file": {
@@ -58,6 +67,7 @@ func TestCmdSubcommand(t *testing.T) {
 			args:    []string{"abc"},
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("ExportSectionEnv", "echo").Return(nil).Once()
 				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, "testdata/.akamai-cli/src/cli-echo/bin/akamai-echo-cmd.cmd").
 					Return([]string{"testdata/.akamai-cli/src/cli-echo/bin/akamai-echo-cmd.cmd"}, nil)
 			},
@@ -67,6 +77,7 @@ func TestCmdSubcommand(t *testing.T) {
 			args:    []string{"abc"},
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("ExportSectionEnv", "echo").Return(nil).Once()
 				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, "testdata/.akamai-cli/src/cli-echo/bin/akamai-echo-cmd.cmd").
 					Return([]string{"testdata/.akamai-cli/src/cli-echo/bin/akamai-echo-cmd.cmd"}, nil)
 			},
@@ -162,3 +173,170 @@ func TestFindAndAppendFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestSetPackageEnv(t *testing.T) {
+	tests := map[string]struct {
+		givenEnv map[string]string
+		userEnv  map[string]string
+		expected map[string]string
+	}{
+		"package env is exported": {
+			givenEnv: map[string]string{"AKAMAI_TEST_PKG_ENV": "package"},
+			expected: map[string]string{"AKAMAI_TEST_PKG_ENV": "package"},
+		},
+		"user env takes precedence": {
+			givenEnv: map[string]string{"AKAMAI_TEST_PKG_ENV": "package", "AKAMAI_TEST_PKG_OTHER": "other"},
+			userEnv:  map[string]string{"AKAMAI_TEST_PKG_ENV": "user"},
+			expected: map[string]string{"AKAMAI_TEST_PKG_ENV": "user", "AKAMAI_TEST_PKG_OTHER": "other"},
+		},
+		"no env declared": {
+			givenEnv: nil,
+			expected: map[string]string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for key, value := range test.userEnv {
+				require.NoError(t, os.Setenv(key, value))
+			}
+			require.NoError(t, setPackageEnv(test.givenEnv))
+			for key, value := range test.expected {
+				assert.Equal(t, value, os.Getenv(key))
+			}
+			require.NoError(t, os.Unsetenv("AKAMAI_TEST_PKG_ENV"))
+			require.NoError(t, os.Unsetenv("AKAMAI_TEST_PKG_OTHER"))
+		})
+	}
+}
+
+func TestActivatePythonEnv(t *testing.T) {
+	path, home, userBase := os.Getenv("PATH"), os.Getenv("PYTHONHOME"), os.Getenv("PYTHONUSERBASE")
+	defer func() {
+		require.NoError(t, os.Setenv("PATH", path))
+		require.NoError(t, os.Setenv("PYTHONHOME", home))
+		require.NoError(t, os.Setenv("PYTHONUSERBASE", userBase))
+		require.NoError(t, os.Unsetenv("VIRTUAL_ENV"))
+	}()
+
+	t.Run("virtual environment is activated", func(t *testing.T) {
+		packageDir, err := ioutil.TempDir("", "cli-python")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(packageDir))
+		}()
+		binDir := packages.VenvBinDir(packageDir)
+		require.NoError(t, os.MkdirAll(binDir, 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "python"), nil, 0755))
+		require.NoError(t, os.Setenv("PYTHONHOME", "/usr/lib/python3"))
+		require.NoError(t, os.Unsetenv("PYTHONUSERBASE"))
+
+		require.NoError(t, activatePythonEnv(packageDir))
+		assert.Equal(t, filepath.Join(packageDir, ".venv"), os.Getenv("VIRTUAL_ENV"))
+		assert.Equal(t, binDir+string(os.PathListSeparator)+path, os.Getenv("PATH"))
+		_, ok := os.LookupEnv("PYTHONHOME")
+		assert.False(t, ok)
+		assert.Empty(t, os.Getenv("PYTHONUSERBASE"))
+	})
+
+	t.Run("package installed without virtual environment", func(t *testing.T) {
+		require.NoError(t, os.Setenv("PATH", path))
+		require.NoError(t, os.Unsetenv("VIRTUAL_ENV"))
+
+		require.NoError(t, activatePythonEnv("testdata/python-package"))
+		assert.Equal(t, "testdata/python-package", os.Getenv("PYTHONUSERBASE"))
+		assert.Empty(t, os.Getenv("VIRTUAL_ENV"))
+		assert.Equal(t, path, os.Getenv("PATH"))
+	})
+}
+
+func TestPackageWorkDir(t *testing.T) {
+	tests := map[string]struct {
+		packageDir string
+		cwd        string
+		expected   string
+	}{
+		"cwd not set": {
+			packageDir: "/pkg",
+			expected:   "",
+		},
+		"relative cwd": {
+			packageDir: "/pkg",
+			cwd:        "work/dir",
+			expected:   filepath.Join("/pkg", "work", "dir"),
+		},
+		"absolute cwd": {
+			packageDir: "/pkg",
+			cwd:        filepath.Join(os.TempDir(), "work"),
+			expected:   filepath.Join(os.TempDir(), "work"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, packageWorkDir(test.packageDir, test.cwd))
+		})
+	}
+}
+
+func TestDeprecatedSubcommand(t *testing.T) {
+	manifest := `{"requirements": {"go": "1.14.0"}, "commands": [
+		{"name": "new"},
+		{"name": "old", "renamed-to": "New"},
+		{"name": "legacy", "deprecated": "use \"new\" instead"}
+	]}`
+	tests := map[string]struct {
+		command  string
+		expected string
+		warning  string
+	}{
+		"renamed command is forwarded": {
+			command:  "old",
+			expected: "new",
+			warning:  fmt.Sprintf(`Command "old" was renamed to "new", run "%s new" instead`, tools.Self()),
+		},
+		"deprecated command runs with a warning": {
+			command:  "legacy",
+			expected: "legacy",
+			warning:  `Command "legacy" is deprecated: use "new" instead`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "akamai-deprecated")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(home))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_COMMAND"))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			dir := filepath.Join(home, ".akamai-cli", "src", "cli-test")
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(manifest), 0644))
+			for _, bin := range []string{"akamai-new", "akamai-legacy"} {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", bin), []byte("#!/bin/sh\n"), 0755))
+			}
+			pkg, err := readPackage(dir)
+			require.NoError(t, err)
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.term.On("WriteErrorf", "%s\n", []interface{}{color.YellowString(test.warning)}).Return().Once()
+			m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			for _, section := range []string{"new", "old", "legacy"} {
+				m.cfg.On("ExportSectionEnv", section).Return(nil).Once()
+			}
+			app, ctx := setupTestApp(&cli.Command{Name: "list"}, m)
+			app.Commands = subcommandToCliCommands(pkg, m.gitRepo, m.langManager)
+			args := os.Args[0:1]
+			args = append(args, test.command, "abc")
+
+			err = app.RunContext(ctx, args)
+			require.NoError(t, err)
+			m.term.AssertExpectations(t)
+			m.cfg.AssertExpectations(t)
+			assert.Equal(t, test.expected, os.Getenv("AKAMAI_CLI_COMMAND"))
+		})
+	}
+}
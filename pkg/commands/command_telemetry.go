@@ -0,0 +1,133 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	telemetryCommand    = "command"
+	telemetrySuccess    = "success"
+	telemetryFailure    = "failure"
+	telemetryThirdParty = "third-party"
+)
+
+// cmdTelemetryShow displays the telemetry events queued, exactly as they will be sent if --payload is given
+func cmdTelemetryShow(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("TELEMETRY SHOW START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("TELEMETRY SHOW FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("TELEMETRY SHOW ERROR: %v", e.Error())
+		}
+	}()
+
+	events, err := stats.ReadTelemetryQueue()
+	if err != nil {
+		return commandFailure(errConfig, "Unable to read telemetry queue: %s", err.Error())
+	}
+	term := terminal.Get(c.Context)
+	if c.Bool("json") {
+		if events == nil {
+			events = make([]stats.TelemetryEvent, 0)
+		}
+		return writeJSON(term, events)
+	}
+	if c.Bool("payload") {
+		for _, payload := range stats.TelemetryPayloads(events) {
+			term.Writeln(payload + "\n")
+		}
+		return nil
+	}
+
+	if !stats.TelemetryEnabled() {
+		term.Writeln(color.YellowString("Telemetry is off, turn it on with \"%s config set cli.telemetry on\"", tools.Self()))
+		return nil
+	}
+	if len(events) == 0 {
+		term.Writeln(color.YellowString("Telemetry is on, no events are queued"))
+		return nil
+	}
+	term.Printf("%d event(s) queued, sent to %s once %d are queued or the oldest is a day old:\n\n", len(events), stats.TelemetryURL(), stats.TelemetryBatchSize)
+	printTelemetryEvents(term, events)
+	return nil
+}
+
+// printTelemetryEvents displays queued telemetry events in a table, oldest first
+func printTelemetryEvents(term terminal.Terminal, events []stats.TelemetryEvent) {
+	rows := make([][]string, 0, len(events))
+	for _, event := range events {
+		outcome := color.GreenString(event.Outcome)
+		if event.Outcome != telemetrySuccess {
+			outcome = color.RedString(event.Outcome)
+		}
+		rows = append(rows, []string{event.Time.Local().Format("2006-01-02 15:04:05"), event.Category, event.Action, outcome, event.Version, event.Platform})
+	}
+	printTable(term, []string{"TIME", "CATEGORY", "ACTION", "OUTCOME", "VERSION", "PLATFORM"}, rows)
+}
+
+// withTelemetry records usage of given built-in commands and their subcommands, named after their full name such as "config set"
+func withTelemetry(commands []*cli.Command, parent string) []*cli.Command {
+	for _, cmd := range commands {
+		name := cmd.Name
+		if parent != "" {
+			name = parent + " " + cmd.Name
+		}
+		if action := cmd.Action; action != nil {
+			cmd.Action = func(c *cli.Context) error {
+				err := action(c)
+				stats.RecordTelemetry(c.Context, telemetryCommand, name, telemetryOutcome(err))
+				return err
+			}
+		}
+		withTelemetry(cmd.Subcommands, name)
+	}
+	return commands
+}
+
+// recordPackageTelemetry records the outcome of a package operation logged in the audit log.
+// Only packages published by Akamai are named, others being recorded as third-party packages.
+func recordPackageTelemetry(ctx context.Context, entry auditEntry, err error) {
+	pkg := entry.Package
+	if entry.Action != auditUpgrade && !isAkamaiPackage(entry.Source) {
+		pkg = telemetryThirdParty
+	}
+	stats.RecordTelemetry(ctx, entry.Action, pkg, telemetryOutcome(err))
+}
+
+// telemetryOutcome describes the outcome of a command by the kind of error it returned, without its message
+func telemetryOutcome(err error) string {
+	if err == nil {
+		return telemetrySuccess
+	}
+	if kind := kindOf(err); kind != nil {
+		return kind.Error()
+	}
+	return telemetryFailure
+}
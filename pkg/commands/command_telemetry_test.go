@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// useTempTelemetry turns telemetry on or off, with events queued in a new temporary Akamai CLI directory rather than in testdata.
+// The returned function removes the directory.
+func useTempTelemetry(t *testing.T, enabled string) func() {
+	home, err := ioutil.TempDir("", "akamai-telemetry")
+	require.NoError(t, err)
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_TELEMETRY", enabled))
+	return func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_TELEMETRY"))
+		require.NoError(t, os.RemoveAll(home))
+	}
+}
+
+func TestCmdTelemetryShow(t *testing.T) {
+	queued := time.Date(2026, 10, 14, 9, 30, 0, 0, time.Local)
+	events := []stats.TelemetryEvent{
+		{Time: queued, Category: "command", Action: "search", Outcome: "success", Version: "1.3.0", Platform: "linux/amd64"},
+		{Time: queued, Category: "install", Action: "third-party", Outcome: "build failure", Version: "1.3.0", Platform: "linux/amd64"},
+	}
+
+	tests := map[string]struct {
+		args    []string
+		enabled string
+		queued  []stats.TelemetryEvent
+		init    func(*terminal.Mock)
+	}{
+		"queued events": {
+			enabled: "on",
+			queued:  events,
+			init: func(m *terminal.Mock) {
+				row := func(cells ...string) []interface{} {
+					return []interface{}{fmt.Sprintf("  %-21s%-10s%-13s%-15s%-9s%s", cells[0], cells[1], cells[2], cells[3], cells[4], cells[5])}
+				}
+				m.On("Printf", "%d event(s) queued, sent to %s once %d are queued or the oldest is a day old:\n\n",
+					[]interface{}{2, stats.TelemetryURL(), stats.TelemetryBatchSize}).Return().Once()
+				m.On("Writeln", []interface{}{color.New(color.FgWhite, color.Bold).Sprint(row("TIME", "CATEGORY", "ACTION", "OUTCOME", "VERSION", "PLATFORM")[0])}).Return(0, nil).Once()
+				m.On("Writeln", row("2026-10-14 09:30:00", "command", "search", color.GreenString("success"), "1.3.0", "linux/amd64")).Return(0, nil).Once()
+				m.On("Writeln", row("2026-10-14 09:30:00", "install", "third-party", color.RedString("build failure"), "1.3.0", "linux/amd64")).Return(0, nil).Once()
+			},
+		},
+		"request bodies": {
+			args:    []string{"--payload"},
+			enabled: "on",
+			queued:  events,
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", []interface{}{stats.TelemetryPayloads(events)[0] + "\n"}).Return(0, nil).Once()
+			},
+		},
+		"events in JSON format": {
+			args:    []string{"--json"},
+			enabled: "on",
+			queued:  events[:1],
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", jsonOutput(fmt.Sprintf(`[{"time": %q, "category": "command", "action": "search", "outcome": "success",
+					"version": "1.3.0", "platform": "linux/amd64"}]`, queued.Format(time.RFC3339)))).Return(0, nil).Once()
+			},
+		},
+		"no events queued": {
+			enabled: "on",
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", []interface{}{color.YellowString("Telemetry is on, no events are queued")}).Return(0, nil).Once()
+			},
+		},
+		"telemetry off": {
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", []interface{}{color.YellowString("Telemetry is off, turn it on with \"%s config set cli.telemetry on\"", tools.Self())}).Return(0, nil).Once()
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer useTempTelemetry(t, test.enabled)()
+			path, err := stats.TelemetryQueuePath()
+			require.NoError(t, err)
+			var data []byte
+			for _, event := range test.queued {
+				line, err := json.Marshal(event)
+				require.NoError(t, err)
+				data = append(append(data, line...), '\n')
+			}
+			require.NoError(t, ioutil.WriteFile(path, data, 0600))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := &cli.Command{
+				Name:   "show",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "json"}, &cli.BoolFlag{Name: "payload"}},
+				Action: cmdTelemetryShow,
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "show")
+			args = append(args, test.args...)
+
+			test.init(m.term)
+			require.NoError(t, app.RunContext(ctx, args))
+			m.term.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWithTelemetry(t *testing.T) {
+	defer useTempTelemetry(t, "on")()
+	failing := func(*cli.Context) error { return commandFailure(errNetwork, "unreachable") }
+	commands := withTelemetry([]*cli.Command{
+		{
+			Name:   "search",
+			Action: func(*cli.Context) error { return nil },
+		},
+		{
+			Name: "config",
+			Subcommands: []*cli.Command{
+				{Name: "set", Action: failing},
+			},
+		},
+		{
+			Name:   "upgrade",
+			Action: func(*cli.Context) error { return errors.New("unexpected") },
+		},
+	}, "")
+	app := cli.NewApp()
+	app.Commands = commands
+
+	for _, args := range [][]string{{"search"}, {"config", "set"}, {"upgrade"}} {
+		_ = app.RunContext(context.Background(), append(os.Args[0:1], args...))
+	}
+	recordPackageTelemetry(context.Background(), auditEntry{Action: auditInstall, Package: "cli-echo", Source: "https://github.com/akamai/cli-echo"}, nil)
+	recordPackageTelemetry(context.Background(), auditEntry{Action: auditUpdate, Package: "cli-private", Source: "https://git.example.com/cli-private"},
+		commandFailure(errBuild, "build failed"))
+
+	events, err := stats.ReadTelemetryQueue()
+	require.NoError(t, err)
+	var recorded [][]string
+	for _, event := range events {
+		recorded = append(recorded, []string{event.Category, event.Action, event.Outcome})
+	}
+	assert.Equal(t, [][]string{
+		{"command", "search", "success"},
+		{"command", "config set", "network failure"},
+		{"command", "upgrade", "failure"},
+		{"install", "cli-echo", "success"},
+		{"update", "third-party", "build failure"},
+	}, recorded)
+}
@@ -15,6 +15,7 @@ import (
 
 func TestCommandsLocator(t *testing.T) {
 	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	defer useTempCache(t)()
 	res := CommandLocator(context.Background())
 	for i := 0; i < len(res)-1; i++ {
 		assert.True(t, strings.Compare(res[i].Name, res[i+1].Name) == -1)
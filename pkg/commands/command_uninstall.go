@@ -44,11 +44,37 @@ func cmdUninstall(langManager packages.LangManager) cli.ActionFunc {
 				logger.Errorf("UNINSTALL ERROR: %v", e.Error())
 			}
 		}()
+		var stop func()
+		c.Context, stop = cancelOnSignal(c.Context)
+		defer stop()
+		defer func() {
+			e = canceledError(c.Context, e)
+		}()
+		if c.Bool("no-hooks") {
+			c.Context = withoutHooks(c.Context)
+		}
+		if c.Bool("purge") {
+			c.Context = withPurge(c.Context)
+		}
+		if c.Bool("all") && c.Args().Present() {
+			return commandFailure(errUsage, "Commands cannot be specified along with --all")
+		}
+		if isDryRun(c) {
+			return planUninstall(c, langManager)
+		}
+		defer updateLockFile(c.Context)
+		defer updateCommandShims(c.Context)
+		if c.Bool("all") {
+			return uninstallAllPackages(c.Context, logger)
+		}
 		for _, cmd := range c.Args().Slice() {
+			if err := c.Context.Err(); err != nil {
+				return err
+			}
 			if err := uninstallPackage(c.Context, langManager, cmd, logger); err != nil {
 				stats.TrackEvent(c.Context, "package.uninstall", "failed", cmd)
 				logger.Error(err.Error())
-				return cli.Exit(color.RedString(err.Error()), 1)
+				return commandFailure(kindOf(err), err.Error())
 			}
 			stats.TrackEvent(c.Context, "package.uninstall", "success", cmd)
 		}
@@ -57,28 +83,86 @@ func cmdUninstall(langManager packages.LangManager) cli.ActionFunc {
 	}
 }
 
+// uninstallAllPackages removes every installed package and, with --purge, the build cache they share
+func uninstallAllPackages(ctx context.Context, logger log.Logger) error {
+	for _, dir := range getPackagePaths() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := packageCommandName(dir)
+		if err := removePackage(ctx, name, dir, logger); err != nil {
+			stats.TrackEvent(ctx, "package.uninstall", "failed", name)
+			logger.Error(err.Error())
+			return commandFailure(kindOf(err), err.Error())
+		}
+		stats.TrackEvent(ctx, "package.uninstall", "success", name)
+	}
+	if purgeEnabled(ctx) {
+		if err := purgeBuildCache(ctx); err != nil {
+			return cli.Exit(color.RedString("Unable to clean the build cache: %s", err), 1)
+		}
+	}
+	return nil
+}
+
+// packageCommandName returns the name of the first command of the package in given directory, or the directory name
+func packageCommandName(dir string) string {
+	if pkg, err := readPackage(dir); err == nil && len(pkg.Commands) > 0 {
+		return pkg.Commands[0].Name
+	}
+	return filepath.Base(dir)
+}
+
 func uninstallPackage(ctx context.Context, langManager packages.LangManager, cmd string, logger log.Logger) error {
+	var repoDir string
+	if dir, _, ok := containerPackage(cmd); ok {
+		repoDir = dir
+	} else {
+		exec, err := findExec(ctx, langManager, cmd)
+		if err != nil {
+			return &commandError{kind: errNotFound, message: fmt.Sprintf("command \"%s\" not found. Try \"%s help\"", cmd, tools.Self())}
+		}
+		if len(exec) == 1 {
+			repoDir = findPackageDir(filepath.Dir(exec[0]))
+		} else if len(exec) > 1 {
+			repoDir = findPackageDir(filepath.Dir(exec[len(exec)-1]))
+		}
+	}
+
+	return removePackage(ctx, cmd, repoDir, logger)
+}
+
+// removePackage runs the pre-uninstall hook of the package providing given command and removes the package directory.
+// If the context is marked with withPurge, config entries and the container image of the package are removed as well.
+func removePackage(ctx context.Context, cmd, repoDir string, logger log.Logger) (e error) {
 	term := terminal.Get(ctx)
 
-	exec, err := findExec(ctx, langManager, cmd)
-	if err != nil {
-		return fmt.Errorf("command \"%s\" not found. Try \"%s help\"", cmd, tools.Self())
+	if repoDir != "" {
+		entry := auditPackage(auditUninstall, repoDir)
+		defer func() {
+			recordAudit(ctx, entry, e)
+		}()
 	}
 
-	term.Spinner().Start(fmt.Sprintf("Attempting to uninstall \"%s\" command...", cmd))
-	logger.Debugf("Attempting to uninstall \"%s\" command...", cmd)
+	if repoDir != "" {
+		if err := runPackageHook(ctx, repoDir, hookPreUninstall, nil); err != nil {
+			logger.Error(err.Error())
+			return &commandError{kind: errBuild, message: fmt.Sprintf("unable to uninstall \"%s\", %s. Use --no-hooks to uninstall it without running hooks", cmd, err.Error())}
+		}
+	}
 
-	var repoDir string
-	if len(exec) == 1 {
-		repoDir = findPackageDir(filepath.Dir(exec[0]))
-	} else if len(exec) > 1 {
-		repoDir = findPackageDir(filepath.Dir(exec[len(exec)-1]))
+	var leftovers packageLeftovers
+	if purgeEnabled(ctx) && repoDir != "" {
+		leftovers = findPackageLeftovers(repoDir)
 	}
 
+	term.Spinner().Start(fmt.Sprintf("Attempting to uninstall \"%s\" command...", cmd))
+	logger.Debugf("Attempting to uninstall \"%s\" command...", cmd)
+
 	if repoDir == "" {
 		term.Spinner().Fail()
 		logger.Error("unable to uninstall, was it installed using \"akamai install\"?")
-		return fmt.Errorf("unable to uninstall, was it installed using " + color.CyanString("\"akamai install\"") + "?")
+		return &commandError{kind: errNotFound, message: "unable to uninstall, was it installed using " + color.CyanString("\"akamai install\"") + "?"}
 	}
 
 	if err := os.RemoveAll(repoDir); err != nil {
@@ -86,6 +170,16 @@ func uninstallPackage(ctx context.Context, langManager packages.LangManager, cmd
 		logger.Errorf("unable to remove directory: %s", repoDir)
 		return fmt.Errorf("unable to remove directory: %s", repoDir)
 	}
+	if err := removePreviousPackage(repoDir); err != nil {
+		logger.Errorf("Unable to remove previous version of the package: %s", err.Error())
+	}
+	if purgeEnabled(ctx) {
+		if err := purgePackage(ctx, leftovers); err != nil {
+			term.Spinner().Fail()
+			logger.Errorf("Unable to purge package: %s", err.Error())
+			return &commandError{kind: errConfig, message: fmt.Sprintf("unable to purge \"%s\": %s", cmd, err.Error())}
+		}
+	}
 
 	term.Spinner().OK()
 
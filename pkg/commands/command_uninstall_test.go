@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"github.com/akamai/cli/pkg/config"
 	"github.com/akamai/cli/pkg/git"
@@ -11,11 +12,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestCmdUninstall(t *testing.T) {
+	defer useTempAuditLog(t)()
 	tests := map[string]struct {
 		args      []string
 		init      func(*testing.T, *mocked)
@@ -38,6 +42,30 @@ func TestCmdUninstall(t *testing.T) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
 			},
 		},
+		"pre-uninstall hook fails": {
+			args: []string{"echo-uninstall"},
+			init: func(t *testing.T, m *mocked) {
+				copyHookedPackage(t, `{"pre-uninstall": "echo state could not be saved >&2; exit 1"}`)
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Running %s hook...", []interface{}{"pre-uninstall"}).Return().Once()
+				m.term.On("Fail").Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+			},
+			withError: `unable to uninstall "echo-uninstall", pre-uninstall hook failed: exit status 1: state could not be saved. Use --no-hooks to uninstall it without running hooks`,
+		},
+		"pre-uninstall hook skipped with --no-hooks": {
+			args: []string{"--no-hooks", "echo-uninstall"},
+			init: func(t *testing.T, m *mocked) {
+				copyHookedPackage(t, `{"pre-uninstall": "exit 1"}`)
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to uninstall "echo-uninstall" command...`, []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+			},
+		},
 		"package does not contain cli.json": {
 			args: []string{"echo-uninstall"},
 			init: func(t *testing.T, m *mocked) {
@@ -70,11 +98,13 @@ func TestCmdUninstall(t *testing.T) {
 			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
 			command := &cli.Command{
 				Name:   "uninstall",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "no-hooks"}},
 				Action: cmdUninstall(m.langManager),
 			}
 			app, ctx := setupTestApp(command, m)
 			defer func() {
 				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-echo-uninstall"))
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/"+lockFileName))
 			}()
 			args := os.Args[0:1]
 			args = append(args, "uninstall")
@@ -84,12 +114,123 @@ func TestCmdUninstall(t *testing.T) {
 			err := app.RunContext(ctx, args)
 
 			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdUninstallAll(t *testing.T) {
+	defer useTempAuditLog(t)()
+	configValues := map[string]map[string]string{
+		"cli":             {"config-version": "1.1"},
+		"echo":            {"greeting": "hello", "api-token": "abc"},
+		"aliases":         {"hi": "echo hi", "ls": "list"},
+		"profile staging": {"echo.greeting": "bonjour", "cli.cache-path": "/tmp"},
+	}
+	tests := map[string]struct {
+		args         []string
+		init         func(*testing.T, *mocked)
+		purged       bool
+		withError    string
+		withExitCode int
+	}{
+		"uninstall all packages": {
+			args: []string{"--all"},
+			init: func(t *testing.T, m *mocked) {
+				for _, cmd := range []string{"echo", "installed"} {
+					m.term.On("Spinner").Return(m.term).Twice()
+					m.term.On("Start", fmt.Sprintf(`Attempting to uninstall "%s" command...`, cmd), []interface{}(nil)).Return().Once()
+					m.term.On("OK").Return().Once()
+				}
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+		},
+		"uninstall all packages with purge": {
+			args: []string{"--all", "--purge"},
+			init: func(t *testing.T, m *mocked) {
+				for _, cmd := range []string{"echo", "installed"} {
+					m.term.On("Spinner").Return(m.term).Twice()
+					m.term.On("Start", fmt.Sprintf(`Attempting to uninstall "%s" command...`, cmd), []interface{}(nil)).Return().Once()
+					m.term.On("OK").Return().Once()
+				}
+				m.cfg.On("Values").Return(configValues)
+				m.cfg.On("RemoveSection", "echo").Return().Once()
+				m.cfg.On("UnsetSecret", "echo", "api-token").Return(nil).Once()
+				m.cfg.On("UnsetValue", "aliases", "hi").Return().Once()
+				m.cfg.On("UnsetValue", "profile staging", "echo.greeting").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			purged: true,
+		},
+		"commands along with --all": {
+			args:         []string{"--all", "echo"},
+			init:         func(t *testing.T, m *mocked) {},
+			withError:    "Commands cannot be specified along with --all",
+			withExitCode: exitUsage,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "cli-uninstall")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(home))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_CACHE_PATH"))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_CACHE_PATH", filepath.Join(home, "cache")))
+			srcPath := setupBundleHome(t, home)
+			goCache := filepath.Join(home, "cache", buildCacheDirName, "go-build")
+			require.NoError(t, os.MkdirAll(goCache, 0755))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name:   "uninstall",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "all"}, &cli.BoolFlag{Name: "purge"}},
+				Action: cmdUninstall(m.langManager),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "uninstall")
+			args = append(args, test.args...)
+
+			test.init(t, m)
+			err = app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
+				var exitErr cli.ExitCoder
+				require.True(t, errors.As(err, &exitErr))
+				assert.Equal(t, test.withExitCode, exitErr.ExitCode())
 				return
 			}
 			require.NoError(t, err)
+			assert.Empty(t, getPackagePaths())
+			_, err = os.Stat(srcPath)
+			assert.NoError(t, err)
+			_, err = os.Stat(goCache)
+			assert.Equal(t, test.purged, os.IsNotExist(err))
 		})
 	}
 }
+
+// copyHookedPackage installs the echo-uninstall test package declaring given hooks in its cli.json
+func copyHookedPackage(t *testing.T, hooks string) {
+	copyFile(t, "./testdata/.akamai-cli/src/cli-echo/bin/akamai-echo", "./testdata/.akamai-cli/src/cli-echo-uninstall/bin")
+	require.NoError(t, os.Rename("./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo", "./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo-uninstall"))
+	require.NoError(t, os.Chmod("./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo-uninstall", 0755))
+	cliJSON := `{"commands": [{"name": "echo-uninstall"}], "hooks": ` + hooks + `}`
+	require.NoError(t, ioutil.WriteFile("./testdata/.akamai-cli/src/cli-echo-uninstall/cli.json", []byte(cliJSON), 0644))
+}
@@ -16,14 +16,19 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/akamai/cli/pkg/packages"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
 
 	"github.com/akamai/cli/pkg/git"
 	"github.com/akamai/cli/pkg/log"
@@ -44,27 +49,97 @@ func cmdUpdate(gitRepo git.Repository, langManager packages.LangManager) cli.Act
 				logger.Errorf("UPDATE ERROR: %v", e.Error())
 			}
 		}()
+		var stop func()
+		c.Context, stop = cancelOnSignal(c.Context)
+		defer stop()
+		defer func() {
+			e = canceledError(c.Context, e)
+		}()
+		if c.Bool("json") && !c.Bool("check") {
+			return commandFailure(errUsage, "Flag --json requires --check")
+		}
+		if c.Bool("skip-verify") {
+			c.Context = withSkipVerify(c.Context)
+		}
+		if c.Bool("no-changelog") {
+			c.Context = withoutChangelog(c.Context)
+		}
+		if c.Bool("no-hooks") {
+			c.Context = withoutHooks(c.Context)
+		}
+		strategy, err := installStrategyFromFlags(c)
+		if err != nil {
+			return commandFailure(errUsage, err.Error())
+		}
+		c.Context = withInstallStrategy(c.Context, strategy)
+		timeout, err := timeoutFromFlags(c)
+		if err != nil {
+			return commandFailure(errUsage, err.Error())
+		}
+		c.Context = withTimeout(c.Context, timeout)
+
+		if c.Bool("check") {
+			return checkUpdates(c, gitRepo)
+		}
+		if isDryRun(c) {
+			return planUpdate(c, gitRepo)
+		}
+
 		if !c.Args().Present() {
 			var builtinCmds = make(map[string]bool)
 			for _, cmd := range getBuiltinCommands(c) {
 				builtinCmds[strings.ToLower(cmd.Commands[0].Name)] = true
 			}
 
+			// pinned packages are held back, and only updated when given explicitly
+			held := heldPackages()
+			heldBack := make(map[string]bool)
+			var cmds []string
 			for _, cmd := range getCommands(c) {
 				for _, command := range cmd.Commands {
-					if _, ok := builtinCmds[command.Name]; !ok {
-						if err := updatePackage(c.Context, gitRepo, langManager, logger, command.Name, c.Bool("force")); err != nil {
-							return err
+					if _, ok := builtinCmds[command.Name]; ok {
+						continue
+					}
+					if pkg, ok := held[command.Name]; ok {
+						if !heldBack[pkg] {
+							logger.Debugf("Package %s is pinned, skipping", pkg)
+							terminal.Get(c.Context).Writeln(color.CyanString(heldBackMessage(pkg, command.Name)))
+							heldBack[pkg] = true
 						}
+						continue
 					}
+					cmds = append(cmds, command.Name)
+				}
+			}
+
+			// keep the lock file in sync with packages updated before a failure
+			defer updateLockFile(c.Context)
+			defer updateCommandShims(c.Context)
+			if c.Int("concurrency") > 1 {
+				return updatePackagesConcurrently(c, langManager, logger, cmds)
+			}
+			for _, cmd := range cmds {
+				if err := c.Context.Err(); err != nil {
+					return err
+				}
+				if err := updatePackage(c.Context, gitRepo, langManager, logger, cmd, c.Bool("force"), c.Bool("latest")); err != nil {
+					return err
 				}
 			}
 
 			return nil
 		}
 
+		defer updateLockFile(c.Context)
+		defer updateCommandShims(c.Context)
+		if c.Int("concurrency") > 1 {
+			return updatePackagesConcurrently(c, langManager, logger, c.Args().Slice())
+		}
 		for _, cmd := range c.Args().Slice() {
-			if err := updatePackage(c.Context, gitRepo, langManager, logger, cmd, c.Bool("force")); err != nil {
+			if err := c.Context.Err(); err != nil {
+				return err
+			}
+			if err := updatePackage(c.Context, gitRepo, langManager, logger, cmd, c.Bool("force"), c.Bool("latest")); err != nil {
 				return err
 			}
 		}
@@ -73,44 +148,288 @@ func cmdUpdate(gitRepo git.Repository, langManager packages.LangManager) cli.Act
 	}
 }
 
-func updatePackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger log.Logger, cmd string, forceBinary bool) error {
+// checkUpdates reports available updates of all installed packages, or only of packages containing given commands.
+// Packages are not modified. It exits with exitFailure status code if a package is outdated, so that it can be used in scripts.
+func checkUpdates(c *cli.Context, gitRepo git.Repository) error {
+	term := terminal.Get(c.Context)
+	jsonOutput := c.Bool("json")
+
+	if !jsonOutput {
+		term.Spinner().Start("Checking for package updates...")
+	}
+	checks := checkInstalledPackages(c.Context, gitRepo)
+
+	if c.Args().Present() {
+		var missing string
+		if checks, missing = selectPackageChecks(checks, c.Args().Slice()); missing != "" {
+			if !jsonOutput {
+				term.Spinner().Fail()
+			}
+			return commandFailure(errNotFound, "Command \"%s\" not found. Try \"%s help\".\n", missing, tools.Self())
+		}
+	}
+
+	// packages pinned with the pin command are held back on purpose, so they don't fail the check
+	held := make(map[string]bool)
+	for _, pkg := range heldPackages() {
+		held[pkg] = true
+	}
+	outdated := 0
+	for _, check := range checks {
+		if check.Status == packageStatusOutdated && !held[filepath.Base(check.Dir)] {
+			outdated++
+		}
+	}
+
+	if jsonOutput {
+		result := make([]jsonPackage, 0, len(checks))
+		for _, check := range checks {
+			result = append(result, updateCheckToJSON(check))
+		}
+		if err := writeJSON(term, result); err != nil {
+			return err
+		}
+	} else {
+		term.Spinner().OK()
+		term.Writeln(color.YellowString("\nPackage Updates:\n"))
+		printUpdateChecks(term, checks, held)
+		if outdated > 0 && !quietOutput(c.Context) {
+			term.Printf("\nUpdate using \"%s\".\n", color.BlueString("%s update [command]", tools.Self()))
+		}
+	}
+
+	if outdated > 0 {
+		return cli.Exit("", exitFailure)
+	}
+	return nil
+}
+
+// printUpdateChecks displays a table of installed and available versions of packages
+func printUpdateChecks(term terminal.Terminal, checks []packageUpdateCheck, held map[string]bool) {
+	rows := make([][]string, 0, len(checks))
+	for _, check := range checks {
+		installed, available := check.Installed, check.Available
+		if installed == "" {
+			installed = "-"
+		}
+		if available == "" {
+			available = "-"
+		}
+		var status string
+		switch check.Status {
+		case packageStatusOutdated:
+			status = color.YellowString(check.Status)
+			if held[filepath.Base(check.Dir)] {
+				status += " (pinned)"
+			}
+		case packageStatusCurrent:
+			status = color.GreenString(check.Status)
+		default:
+			status = color.CyanString("%s: %s", check.Status, check.Reason)
+		}
+		rows = append(rows, []string{check.Name, installed, available, status})
+	}
+	printTable(term, []string{"PACKAGE", "INSTALLED", "AVAILABLE", "STATUS"}, rows)
+}
+
+// selectPackageChecks returns checks of packages containing given commands.
+// If a command is not found in any package, its name is returned.
+func selectPackageChecks(checks []packageUpdateCheck, cmds []string) ([]packageUpdateCheck, string) {
+	selected := make(map[string]bool)
+	for _, cmd := range cmds {
+		found := false
+		for _, check := range checks {
+			if packageHasCommand(check.Package, cmd) {
+				selected[check.Dir] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, cmd
+		}
+	}
+
+	filtered := make([]packageUpdateCheck, 0, len(selected))
+	for _, check := range checks {
+		if selected[check.Dir] {
+			filtered = append(filtered, check)
+		}
+	}
+	return filtered, ""
+}
+
+func packageHasCommand(pkg subcommands, name string) bool {
+	name = strings.ToLower(name)
+	for _, command := range pkg.Commands {
+		if command.Name == name {
+			return true
+		}
+		for _, alias := range command.Aliases {
+			if strings.ToLower(alias) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// updatePackagesConcurrently updates packages containing given commands using a pool of workers.
+// Each package is updated once, even if several of its commands are given.
+// Output of all updates is displayed when they are done, in the order of commands.
+func updatePackagesConcurrently(c *cli.Context, langManager packages.LangManager, logger log.Logger, cmds []string) error {
+	term := terminal.Get(c.Context)
+
+	var tasks []*packageTask
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, cmd := range cmds {
+		repoDir, _, ok := containerPackage(cmd)
+		if !ok {
+			exec, err := findExec(c.Context, langManager, cmd)
+			if err != nil {
+				return commandFailure(errNotFound, "Command \"%s\" not found. Try \"%s help\".\n", cmd, tools.Self())
+			}
+			repoDir = findExecPackageDir(exec)
+		}
+		if repoDir == "" {
+			return commandFailure(errNotFound, "unable to update, was it installed using "+color.CyanString("\"akamai install\"")+"?")
+		}
+		if seen[repoDir] {
+			continue
+		}
+		seen[repoDir] = true
+		tasks = append(tasks, newPackageTask(cmd))
+		dirs = append(dirs, repoDir)
+	}
+
+	term.Spinner().Start("Updating %d package(s)...", len(tasks))
+	runConcurrently(len(tasks), c.Int("concurrency"), func(i int) {
+		task := tasks[i]
+		ctx := terminal.Context(c.Context, task.out)
+		task.out.Spinner().Start("Attempting to update \"%s\" command...", task.name)
+		task.err = updatePackageDir(ctx, newGitRepository(), langManager, logger, task.name, dirs[i], c.Bool("force"), c.Bool("latest"))
+	})
+
+	failed := 0
+	for _, task := range tasks {
+		if task.failed() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		term.Spinner().Fail()
+	} else {
+		term.Spinner().OK()
+	}
+	printTaskResults(term, tasks)
+
+	if failed > 0 {
+		return commandFailure(failureKind(tasks), "Unable to update %d of %d package(s)", failed, len(tasks))
+	}
+	return nil
+}
+
+func updatePackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger log.Logger, cmd string, forceBinary, latest bool) error {
 	term := terminal.Get(ctx)
+	if repoDir, _, ok := containerPackage(cmd); ok {
+		term.Spinner().Start("Attempting to update \"%s\" command...", cmd)
+		return updatePackageDir(ctx, gitRepo, langManager, logger, cmd, repoDir, forceBinary, latest)
+	}
 	exec, err := findExec(ctx, langManager, cmd)
 	if err != nil {
-		return cli.Exit(color.RedString("Command \"%s\" not found. Try \"%s help\".\n", cmd, tools.Self()), 1)
+		return commandFailure(errNotFound, "Command \"%s\" not found. Try \"%s help\".\n", cmd, tools.Self())
 	}
 
 	logger.Debugf("Command found: %s", filepath.Join(exec...))
 
 	term.Spinner().Start("Attempting to update \"%s\" command...", cmd)
 
-	var repoDir string
 	logger.Debug("Searching for package repo")
-	if len(exec) == 1 {
-		repoDir = findPackageDir(filepath.Dir(exec[0]))
-	} else if len(exec) > 1 {
-		repoDir = findPackageDir(filepath.Dir(exec[len(exec)-1]))
-	}
-
+	repoDir := findExecPackageDir(exec)
 	if repoDir == "" {
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("unable to update, was it installed using "+color.CyanString("\"akamai install\"")+"?"), 1)
+		return commandFailure(errNotFound, "unable to update, was it installed using "+color.CyanString("\"akamai install\"")+"?")
+	}
+
+	return updatePackageDir(ctx, gitRepo, langManager, logger, cmd, repoDir, forceBinary, latest)
+}
+
+// findExecPackageDir returns the directory of the package containing given command executable
+func findExecPackageDir(exec []string) string {
+	if len(exec) == 0 {
+		return ""
 	}
+	return findPackageDir(filepath.Dir(exec[len(exec)-1]))
+}
 
+// updatePackageDir updates the package in given directory. The update spinner is expected to be started by the caller.
+func updatePackageDir(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger log.Logger, cmd, repoDir string, forceBinary, latest bool) (e error) {
+	term := terminal.Get(ctx)
 	logger.Debugf("Repo found: %s", repoDir)
 
-	err = gitRepo.Open(repoDir)
+	previous := auditPackage(auditUpdate, repoDir)
+	var updated bool
+	var updatedCommit string
+	defer func() {
+		// packages which are already up-to-date or pinned are left as they are
+		if e == nil && !updated {
+			return
+		}
+		entry := auditPackage(auditUpdate, repoDir)
+		if updatedCommit != "" {
+			entry.Commit = updatedCommit
+		}
+		entry.PreviousVersion, entry.PreviousCommit = previous.Version, previous.Commit
+		recordAudit(ctx, entry, e)
+	}()
+
+	meta, err := readInstallMetadata(repoDir)
+	if err != nil {
+		logger.Warnf("Unable to read install metadata: %s", err.Error())
+	}
+	if source, ok := installedSource(meta); ok {
+		updated, err = updateSourcePackage(ctx, langManager, logger, cmd, repoDir, meta, source, forceBinary, latest)
+		return err
+	}
+	if meta != nil && meta.isPinned() && !latest {
+		term.Spinner().WarnOK()
+		warnMsg := fmt.Sprintf("command \"%s\" is pinned to %s %s, use --latest to update", cmd, meta.RefType, meta.Ref)
+		logger.Warn(warnMsg)
+		term.Writeln(color.CyanString(warnMsg))
+		return nil
+	}
+
+	if meta != nil && meta.Repo != "" && isShallowClone(repoDir) {
+		updatedCommit, err = updateShallowPackage(ctx, gitRepo, langManager, logger, cmd, repoDir, meta, forceBinary, latest)
+		updated = updatedCommit != ""
+		return err
+	}
+
+	stagedDir, err := stagePackage(repoDir)
+	if err != nil {
+		logger.Debugf("Unable to stage package: %s", err.Error())
+		term.Spinner().Fail()
+		return commandFailure(errBuild, "unable to update, could not prepare a copy of the package: %s", err.Error())
+	}
+	defer func() {
+		if err := discardStagedPackage(stagedDir); err != nil {
+			logger.Errorf("Unable to remove staging directory: %s", err.Error())
+		}
+	}()
+	logger.Debugf("Package staged in: %s", stagedDir)
+
+	err = gitRepo.Open(stagedDir)
 	if err != nil {
 		logger.Debug("Unable to open repo")
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("unable to update, there an issue with the package repo: %s", err.Error()), 1)
+		return commandFailure(errBuild, "unable to update, there an issue with the package repo: %s", err.Error())
 	}
 
 	w, err := gitRepo.Worktree()
 	if err != nil {
 		logger.Debug("Unable to open repo")
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("unable to update, there an issue with the package repo: %s", err.Error()), 1)
+		return commandFailure(errBuild, "unable to update, there an issue with the package repo: %s", err.Error())
 	}
 	refName := "refs/remotes/" + git.DefaultRemoteName + "/master"
 
@@ -121,23 +440,42 @@ func updatePackage(ctx context.Context, gitRepo git.Repository, langManager pack
 	if errBeforePull != nil {
 		logger.Debugf("Fetch error: %s", errBeforePull.Error())
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("Unable to fetch updates (%s)", errBeforePull.Error()), 1)
+		return commandFailure(errNetwork, "Unable to fetch updates (%s)", errBeforePull.Error())
+	}
+
+	// with --latest, packages installed from a specific ref are moved back to the default branch
+	switchBranch := latest && meta != nil && meta.Ref != "" && meta.DefaultBranch != ""
+	if switchBranch {
+		logger.Debugf("Switching to default branch: %s", meta.DefaultBranch)
+		err = gitRepo.Checkout(w, &gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(meta.DefaultBranch)})
+		if err != nil {
+			term.Spinner().Fail()
+			return cli.Exit(color.RedString("Unable to switch to default branch (%s)", err.Error()), 1)
+		}
 	}
 
-	err = gitRepo.Pull(ctx, w)
+	err = runWithTimeout(ctx, "git pull", gitTimeout(ctx), func(ctx context.Context) error {
+		return gitRepo.Pull(ctx, w)
+	})
+	var timeoutErr *timeoutError
+	if errors.As(err, &timeoutErr) {
+		term.Spinner().Fail()
+		return commandFailure(errNetwork, "Unable to fetch updates (%s), set install.git-timeout or --timeout to allow more time", err.Error())
+	}
 	if err != nil && err.Error() != alreadyUptoDate {
 		logger.Debugf("Fetch error: %s", err.Error())
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("Unable to fetch updates (%s)", err.Error()), 1)
+		return commandFailure(errNetwork, "Unable to fetch updates (%s)", err.Error())
 	}
 
 	ref, err := gitRepo.Head()
 	if err != nil && err.Error() != alreadyUptoDate {
 		logger.Debugf("Fetch error: %s", err.Error())
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("Unable to fetch updates (%s)", err.Error()), 1)
+		return commandFailure(errNetwork, "Unable to fetch updates (%s)", err.Error())
 	}
 
+	var changelog []*object.Commit
 	if refBeforePull.Hash() != ref.Hash() {
 		commit, err := gitRepo.CommitObject(ref.Hash())
 		logger.Debugf("HEAD differs: %s (old) vs %s (new)", refBeforePull.Hash().String(), ref.Hash().String())
@@ -146,9 +484,17 @@ func updatePackage(ctx context.Context, gitRepo git.Repository, langManager pack
 		if err != nil && err.Error() != alreadyUptoDate {
 			logger.Debugf("Fetch error: %s", err.Error())
 			term.Spinner().Fail()
-			return cli.Exit(color.RedString("Unable to fetch updates (%s)", err.Error()), 1)
+			return commandFailure(errNetwork, "Unable to fetch updates (%s)", err.Error())
 		}
-	} else {
+
+		if showChangelog(ctx) {
+			// one more commit than displayed is requested to know if the changelog is truncated
+			changelog, err = gitRepo.Log(ref.Hash(), refBeforePull.Hash(), changelogLimit+1)
+			if err != nil {
+				logger.Warnf("Unable to read changelog: %s", err.Error())
+			}
+		}
+	} else if !switchBranch {
 		logger.Debugf("HEAD is the same as the remote: %s (old) vs %s (new)", refBeforePull.Hash().String(), ref.Hash().String())
 		term.Spinner().WarnOK()
 		debugMessage := fmt.Sprintf("command \"%s\" already up-to-date", cmd)
@@ -160,10 +506,365 @@ func updatePackage(ctx context.Context, gitRepo git.Repository, langManager pack
 	logger.Debug("Repo updated successfully")
 	term.Spinner().OK()
 
-	if ok, _ := installPackageDependencies(ctx, langManager, repoDir, forceBinary, logger); !ok {
-		logger.Trace("Error updating dependencies")
-		return cli.Exit("Unable to update command", 1)
+	if meta != nil {
+		meta.Commit = ref.Hash().String()
+		if switchBranch {
+			meta.Ref, meta.RefType = "", ""
+		}
+	}
+	hookEnv := map[string]string{"AKAMAI_CLI_PREVIOUS_COMMIT": refBeforePull.Hash().String(), "AKAMAI_CLI_COMMIT": ref.Hash().String()}
+	if err := replaceUpdatedPackage(ctx, langManager, logger, cmd, repoDir, stagedDir, meta, forceBinary, hookEnv); err != nil {
+		return err
 	}
+	printChangelog(term, cmd, changelog)
+	updated, updatedCommit = true, ref.Hash().String()
 
 	return nil
 }
+
+// updateSourcePackage updates a package installed without git by staging it again from its source, and returns true if it was updated.
+// Packages whose archive checksum or Mercurial changeset did not change are left as they are, while packages installed
+// from a directory are always refreshed. With latest, Mercurial repositories are updated to the tip of their default branch.
+func updateSourcePackage(ctx context.Context, langManager packages.LangManager, logger log.Logger, cmd, repoDir string, meta *installMetadata, source packageSource, forceBinary, latest bool) (bool, error) {
+	term := terminal.Get(ctx)
+	if latest {
+		source.Ref = ""
+	}
+	if source.isLocal() {
+		if _, err := os.Stat(source.Location); err != nil {
+			term.Spinner().Fail()
+			return false, commandFailure(errNotFound, "Unable to update command \"%s\", its source cannot be read (%s). Reinstall the package from a newer source instead", cmd, err.Error())
+		}
+	}
+
+	logger.Debugf("Staging package from %s", source)
+	stagedDir, revision, err := source.stage(ctx)
+	if err != nil {
+		logger.Debugf("Fetch error: %s", err.Error())
+		term.Spinner().Fail()
+		var timeoutErr *timeoutError
+		if errors.As(err, &timeoutErr) {
+			return false, commandFailure(errNetwork, "Unable to fetch updates (%s), set install.git-timeout or --timeout to allow more time", err.Error())
+		}
+		return false, commandFailure(errNetwork, "Unable to fetch updates (%s)", err.Error())
+	}
+	defer func() {
+		if err := discardStagedPackage(stagedDir); err != nil {
+			logger.Errorf("Unable to remove staging directory: %s", err.Error())
+		}
+	}()
+
+	previousRevision := source.revision(meta)
+	if revision != "" && revision == previousRevision && source.Ref == meta.Ref {
+		term.Spinner().WarnOK()
+		debugMessage := fmt.Sprintf("command \"%s\" already up-to-date", cmd)
+		logger.Warn(debugMessage)
+		term.Writeln(color.CyanString(debugMessage))
+		return false, nil
+	}
+	if err := validatePackage(stagedDir); err != nil {
+		term.Spinner().Fail()
+		return false, commandFailure(errUsage, "Unable to update command \"%s\", invalid package: %s", cmd, err.Error())
+	}
+	logger.Debug("Package staged successfully")
+	term.Spinner().OK()
+
+	meta.Ref = source.Ref
+	source.setRevision(meta, revision)
+	var hookEnv map[string]string
+	if source.Type == sourceMercurial {
+		hookEnv = map[string]string{"AKAMAI_CLI_PREVIOUS_COMMIT": previousRevision, "AKAMAI_CLI_COMMIT": revision}
+	}
+	if err := replaceUpdatedPackage(ctx, langManager, logger, cmd, repoDir, stagedDir, meta, forceBinary, hookEnv); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isShallowClone returns true if the package repository in given directory was cloned with a limited history
+func isShallowClone(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git", "shallow"))
+	return err == nil
+}
+
+// updateShallowPackage updates a package cloned with a limited history, which can't be pulled, by cloning its branch
+// again at the configured depth. It returns the updated commit, empty if the package is already up-to-date.
+func updateShallowPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger log.Logger, cmd, repoDir string, meta *installMetadata, forceBinary, latest bool) (string, error) {
+	term := terminal.Get(ctx)
+	if err := gitRepo.Open(repoDir); err != nil {
+		term.Spinner().Fail()
+		return "", commandFailure(errBuild, "unable to update, there an issue with the package repo: %s", err.Error())
+	}
+	refBefore, err := gitRepo.Head()
+	if err != nil {
+		term.Spinner().Fail()
+		return "", commandFailure(errNetwork, "Unable to fetch updates (%s)", err.Error())
+	}
+
+	// with --latest, packages installed from a specific ref are moved back to the default branch
+	switchBranch := latest && meta.Ref != "" && meta.DefaultBranch != ""
+	var branch string
+	if switchBranch {
+		branch = meta.DefaultBranch
+	} else if refBefore.Name().IsBranch() {
+		branch = refBefore.Name().Short()
+	}
+	logger.Debugf("Cloning %s again to update the package cloned with a limited history", meta.Repo)
+	stagedDir, err := stageClone(ctx, gitRepo, repoDir, meta.Repo, branch)
+	if err != nil {
+		logger.Debugf("Fetch error: %s", err.Error())
+		term.Spinner().Fail()
+		var timeoutErr *timeoutError
+		if errors.As(err, &timeoutErr) {
+			return "", commandFailure(errNetwork, "Unable to fetch updates (%s), set install.git-timeout or --timeout to allow more time", err.Error())
+		}
+		return "", commandFailure(errNetwork, "Unable to fetch updates (%s)", err.Error())
+	}
+	defer func() {
+		if err := discardStagedPackage(stagedDir); err != nil {
+			logger.Errorf("Unable to remove staging directory: %s", err.Error())
+		}
+	}()
+	logger.Debugf("Package staged in: %s", stagedDir)
+
+	ref, err := gitRepo.Head()
+	if err != nil {
+		term.Spinner().Fail()
+		return "", commandFailure(errNetwork, "Unable to fetch updates (%s)", err.Error())
+	}
+	if ref.Hash() == refBefore.Hash() && !switchBranch {
+		term.Spinner().WarnOK()
+		debugMessage := fmt.Sprintf("command \"%s\" already up-to-date", cmd)
+		logger.Warn(debugMessage)
+		term.Writeln(color.CyanString(debugMessage))
+		return "", nil
+	}
+	logger.Debug("Repo updated successfully")
+	term.Spinner().OK()
+
+	meta.Commit = ref.Hash().String()
+	if switchBranch {
+		meta.Ref, meta.RefType = "", ""
+	}
+	hookEnv := map[string]string{"AKAMAI_CLI_PREVIOUS_COMMIT": refBefore.Hash().String(), "AKAMAI_CLI_COMMIT": ref.Hash().String()}
+	if err := replaceUpdatedPackage(ctx, langManager, logger, cmd, repoDir, stagedDir, meta, forceBinary, hookEnv); err != nil {
+		return "", err
+	}
+	// the commits between both versions aren't known with a limited history, so no changelog is displayed
+	return ref.Hash().String(), nil
+}
+
+// replaceUpdatedPackage builds the staged copy of an updated package and swaps it with the installed version, which is kept
+// for rollback. The pre-update hook is the one of the installed version, the post-update hook the one of the new version.
+func replaceUpdatedPackage(ctx context.Context, langManager packages.LangManager, logger log.Logger, cmd, repoDir, stagedDir string, meta *installMetadata, forceBinary bool, hookEnv map[string]string) error {
+	if err := runPackageHook(ctx, repoDir, hookPreUpdate, hookEnv); err != nil {
+		return commandFailure(errBuild, "Unable to update command \"%s\", %s. Use --no-hooks to update it without running hooks", cmd, err.Error())
+	}
+
+	// virtual environments refer to their own location, so Python packages are built once in place
+	buildInPlace := meta == nil || meta.Image == ""
+	if buildInPlace {
+		cmdPackage, err := readPackage(stagedDir)
+		buildInPlace = err == nil && cmdPackage.Requirements.Python != ""
+	}
+
+	if meta != nil && meta.Image != "" {
+		pkg, err := pullPackageImage(ctx, stagedDir)
+		if err != nil {
+			logger.Debugf("Error pulling image, keeping previous version: %s", err.Error())
+			return commandFailure(errBuild, "Unable to update command \"%s\", %s, previous version has been kept", cmd, err.Error())
+		}
+		meta.Image = pkg.Image
+	} else if !buildInPlace {
+		if err := buildUpdatedPackage(ctx, langManager, logger, cmd, stagedDir, forceBinary, hookEnv); err != nil {
+			return err
+		}
+	}
+
+	if meta != nil {
+		if err := writeInstallMetadata(stagedDir, meta); err != nil {
+			logger.Errorf("Unable to save install metadata: %s", err.Error())
+		}
+	}
+
+	if err := commitStagedPackage(stagedDir, repoDir); err != nil {
+		logger.Errorf("Unable to replace package directory: %s", err.Error())
+		return cli.Exit(color.RedString("Unable to update command \"%s\": %s", cmd, err.Error()), 1)
+	}
+	logger.Debugf("Package directory replaced: %s", repoDir)
+	if buildInPlace {
+		if err := buildUpdatedPackage(ctx, langManager, logger, cmd, repoDir, forceBinary, hookEnv); err != nil {
+			if rbErr := revertStagedPackage(stagedDir, repoDir); rbErr != nil {
+				logger.Errorf("Unable to restore previous version of the package: %s", rbErr.Error())
+				return cli.Exit(color.RedString("Unable to update command \"%s\", unable to restore previous version: %s", cmd, rbErr.Error()), 1)
+			}
+			return err
+		}
+	}
+	if err := keepPreviousPackage(stagedDir, repoDir); err != nil {
+		logger.Errorf("Unable to keep previous version of the package: %s", err.Error())
+	}
+	return nil
+}
+
+// buildUpdatedPackage installs dependencies of the updated package in given directory and runs its post-update hook
+func buildUpdatedPackage(ctx context.Context, langManager packages.LangManager, logger log.Logger, cmd, dir string, forceBinary bool, hookEnv map[string]string) error {
+	if ok, _ := installPackageDependencies(ctx, langManager, dir, forceBinary, logger); !ok {
+		logger.Debug("Error updating dependencies, keeping previous version")
+		return commandFailure(errBuild, "Unable to update command \"%s\", previous version has been kept", cmd)
+	}
+
+	if err := runPackageHook(ctx, dir, hookPostUpdate, hookEnv); err != nil {
+		return commandFailure(errBuild, "Unable to update command \"%s\", %s, previous version has been kept. Use --no-hooks to update it without running hooks", cmd, err.Error())
+	}
+	return nil
+}
+
+// changelogLimit is the maximum number of commits displayed after a package update
+const changelogLimit = 20
+
+type noChangelogKey struct{}
+
+// withoutChangelog marks the context so that commits included in package updates are not displayed
+func withoutChangelog(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noChangelogKey{}, true)
+}
+
+func showChangelog(ctx context.Context) bool {
+	skip, _ := ctx.Value(noChangelogKey{}).(bool)
+	return !skip
+}
+
+// printChangelog displays the short hash and subject of each commit, newest first.
+// If there are more commits than changelogLimit, only the newest are displayed.
+func printChangelog(term terminal.Terminal, cmd string, commits []*object.Commit) {
+	if len(commits) == 0 {
+		return
+	}
+	term.Writeln(color.YellowString("Changes in \"%s\":", cmd))
+	for i, commit := range commits {
+		if i == changelogLimit {
+			term.Printf("  ...and older commits\n")
+			break
+		}
+		subject := strings.TrimSpace(strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0])
+		term.Printf("  %s %s\n", color.CyanString(shortHash(commit.Hash)), subject)
+	}
+}
+
+// statuses of installed package compared to its upstream repository
+const (
+	packageStatusOutdated = "outdated"
+	packageStatusCurrent  = "current"
+	packageStatusUnknown  = "unknown"
+)
+
+// packageUpdateCheck is a result of comparing an installed package with its upstream repository
+type packageUpdateCheck struct {
+	Name      string
+	Dir       string
+	Package   subcommands
+	Status    string
+	Installed string
+	Available string
+	Reason    string
+}
+
+// checkInstalledPackages compares all installed packages with their upstream repositories
+func checkInstalledPackages(ctx context.Context, gitRepo git.Repository) []packageUpdateCheck {
+	checks := make([]packageUpdateCheck, 0)
+	for _, dir := range getPackagePaths() {
+		checks = append(checks, checkPackageUpdate(ctx, gitRepo, dir))
+	}
+	return checks
+}
+
+// checkPackageUpdate fetches the package remote and compares HEAD with the tracked remote branch, without modifying the package.
+// If comparison cannot be performed, the package status is set to unknown and the reason is provided.
+func checkPackageUpdate(ctx context.Context, gitRepo git.Repository, dir string) packageUpdateCheck {
+	logger := log.FromContext(ctx)
+	check := packageUpdateCheck{
+		Name:   strings.TrimPrefix(filepath.Base(dir), "cli-"),
+		Dir:    dir,
+		Status: packageStatusUnknown,
+	}
+
+	pkg, err := readPackage(dir)
+	if err != nil {
+		check.Reason = fmt.Sprintf("unable to read package: %s", err.Error())
+		return check
+	}
+	check.Package = pkg
+
+	if meta, err := readInstallMetadata(dir); err == nil && meta != nil && meta.Source != "" {
+		check.Reason = fmt.Sprintf("installed from %s", meta.Source)
+		return check
+	}
+	if err := gitRepo.Open(dir); err != nil {
+		check.Reason = fmt.Sprintf("unable to open package repository: %s", err.Error())
+		return check
+	}
+
+	head, err := gitRepo.Head()
+	if err != nil {
+		check.Reason = fmt.Sprintf("unable to read package repository HEAD: %s", err.Error())
+		return check
+	}
+	check.Installed = shortHash(head.Hash())
+	if meta, err := readInstallMetadata(dir); err == nil && meta != nil && meta.isPinned() {
+		check.Reason = fmt.Sprintf("pinned to %s %s", meta.RefType, meta.Ref)
+		return check
+	}
+	if !head.Name().IsBranch() {
+		check.Reason = "package repository is not on a branch"
+		return check
+	}
+
+	var remoteRef *plumbing.Reference
+	if isShallowClone(dir) {
+		// commits can't be fetched into a limited history, the upstream branch is listed instead
+		err = runWithTimeout(ctx, "git ls-remote", gitTimeout(ctx), func(ctx context.Context) error {
+			remoteRef, err = gitRepo.RemoteReference(ctx, head.Name())
+			return err
+		})
+		if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+			logger.Debugf("Fetch error: %s", err.Error())
+			check.Reason = fmt.Sprintf("unable to fetch updates: %s", err.Error())
+			return check
+		}
+	} else {
+		if err := runWithTimeout(ctx, "git fetch", gitTimeout(ctx), gitRepo.Fetch); err != nil && err.Error() != alreadyUptoDate {
+			logger.Debugf("Fetch error: %s", err.Error())
+			check.Reason = fmt.Sprintf("unable to fetch updates: %s", err.Error())
+			return check
+		}
+		remoteRef, err = gitRepo.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, head.Name().Short()))
+	}
+	if err != nil {
+		check.Reason = fmt.Sprintf("unable to find upstream branch: %s", err.Error())
+		return check
+	}
+	check.Available = shortHash(remoteRef.Hash())
+	logger.Debugf("Package %s: %s (installed) vs %s (available)", check.Name, head.Hash().String(), remoteRef.Hash().String())
+
+	// HEAD is current when the upstream commit is reachable from it, for instance when it has local commits on top
+	upToDate := head.Hash() == remoteRef.Hash()
+	if !upToDate {
+		upToDate, err = gitRepo.IsAncestor(remoteRef.Hash(), head.Hash())
+		if err != nil {
+			check.Reason = fmt.Sprintf("unable to compare with upstream branch: %s", err.Error())
+			return check
+		}
+	}
+	if upToDate {
+		check.Status = packageStatusCurrent
+	} else {
+		check.Status = packageStatusOutdated
+	}
+
+	return check
+}
+
+func shortHash(h plumbing.Hash) string {
+	return h.String()[:7]
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"github.com/akamai/cli/pkg/config"
 	"github.com/akamai/cli/pkg/git"
@@ -15,13 +16,36 @@ import (
 	gogit "gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestCmdUpdate(t *testing.T) {
+	defer useTempAuditLog(t)()
+	tmpDir, err := ioutil.TempDir("", "akamai-update")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+	archive := filepath.Join(tmpDir, "cli-echo.tar.gz")
+	require.NoError(t, tools.CreateTarGz("./testdata/.akamai-cli/src/cli-echo", archive, "cli-echo", nil))
+	checksum, err := fileChecksum(archive)
+	require.NoError(t, err)
+	pythonArchive := filepath.Join(tmpDir, "cli-echo-python.tar.gz")
+	require.NoError(t, tools.CreateTarGz("./testdata/.akamai-cli/src/cli-echo-python", pythonArchive, "cli-echo-python", nil))
+	pythonChecksum, err := fileChecksum(pythonArchive)
+	require.NoError(t, err)
+	pythonPackageDir := filepath.Join("testdata", ".akamai-cli", "src", "cli-echo-python")
+	// matches the package directory once the updated version is moved in place
+	updatedPythonPackage := mock.MatchedBy(func(path string) bool {
+		meta, err := readInstallMetadata(path)
+		return path == pythonPackageDir && err == nil && meta.Checksum == pythonChecksum
+	})
+
 	tests := map[string]struct {
 		args      []string
 		init      func(*testing.T, *mocked)
@@ -35,32 +59,40 @@ func TestCmdUpdate(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
 
-				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo")).Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
 				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Log", plumbing.Hash{1}, plumbing.Hash{0}, changelogLimit+1).Return([]*object.Commit{
+					{Hash: plumbing.Hash{1}, Message: "Fix echo output\n\nLonger description"},
+					{Hash: plumbing.Hash{2}, Message: "Add echo flag"},
+				}, nil).Once()
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
-				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-echo",
+				m.langManager.On("Install", stagedPackage("cli-echo"),
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}).Return(nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
+
+				m.term.On("Writeln", []interface{}{color.YellowString(`Changes in "echo":`)}).Return(0, nil).Once()
+				m.term.On("Printf", "  %s %s\n", []interface{}{color.CyanString(shortHash(plumbing.Hash{1})), "Fix echo output"}).Return().Once()
+				m.term.On("Printf", "  %s %s\n", []interface{}{color.CyanString(shortHash(plumbing.Hash{2})), "Add echo flag"}).Return().Once()
 			},
 		},
 		"update all packages": {
-			args: []string{},
+			args: []string{"--no-changelog"},
 			init: func(t *testing.T, m *mocked) {
 				worktree := &gogit.Worktree{}
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
 
-				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo")).Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
@@ -72,7 +104,7 @@ func TestCmdUpdate(t *testing.T) {
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
-				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-echo",
+				m.langManager.On("Install", stagedPackage("cli-echo"),
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}).Return(nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
@@ -85,7 +117,7 @@ func TestCmdUpdate(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
 
-				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo")).Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
@@ -102,12 +134,13 @@ func TestCmdUpdate(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo-invalid-json"}).Return().Once()
 
-				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(nil).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo-invalid-json")).Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
 				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Log", plumbing.Hash{1}, plumbing.Hash{0}, changelogLimit+1).Return(nil, fmt.Errorf("oops")).Once()
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
@@ -127,7 +160,7 @@ func TestCmdUpdate(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo-invalid-json"}).Return().Once()
 
-				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(nil).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo-invalid-json")).Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
@@ -146,7 +179,7 @@ func TestCmdUpdate(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo-invalid-json"}).Return().Once()
 
-				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(nil).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo-invalid-json")).Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
@@ -164,7 +197,7 @@ func TestCmdUpdate(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo-invalid-json"}).Return().Once()
 
-				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(nil).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo-invalid-json")).Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
 				m.gitRepo.On("Pull", worktree).Return(fmt.Errorf("oops"))
@@ -181,7 +214,7 @@ func TestCmdUpdate(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo-invalid-json"}).Return().Once()
 
-				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(nil).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo-invalid-json")).Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(nil, fmt.Errorf("oops")).Once()
 
@@ -196,7 +229,7 @@ func TestCmdUpdate(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo-invalid-json"}).Return().Once()
 
-				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(nil).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo-invalid-json")).Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(nil, fmt.Errorf("oops")).Once()
 
 				m.term.On("Spinner").Return(m.term).Once()
@@ -210,13 +243,193 @@ func TestCmdUpdate(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo-invalid-json"}).Return().Once()
 
-				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(fmt.Errorf("oops")).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo-invalid-json")).Return(fmt.Errorf("oops")).Once()
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Fail").Return().Once()
 			},
 			withError: "unable to update, there an issue with the package repo: oops",
 		},
+		"skip package installed from a tag": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, writeInstallMetadata("./testdata/.akamai-cli/src/cli-echo", &installMetadata{
+					DefaultBranch: "master", Ref: "v1.0.0", RefType: refTypeTag, Commit: plumbing.Hash{1}.String(),
+				}))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("WarnOK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString(`command "echo" is pinned to tag v1.0.0, use --latest to update`)}).Return(0, nil).Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.Remove("./testdata/.akamai-cli/src/cli-echo/"+installMetadataFile))
+			},
+		},
+		"hold back pinned package when updating all packages": {
+			args: []string{},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, writeInstallMetadata("./testdata/.akamai-cli/src/cli-echo", &installMetadata{Held: true}))
+				m.term.On("Writeln", []interface{}{color.CyanString(heldBackMessage("cli-echo", "echo"))}).Return(0, nil).Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.Remove("./testdata/.akamai-cli/src/cli-echo/"+installMetadataFile))
+			},
+		},
+		"update package installed from a tag to latest": {
+			args: []string{"--latest", "echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, writeInstallMetadata("./testdata/.akamai-cli/src/cli-echo", &installMetadata{
+					DefaultBranch: "master", Ref: "v1.0.0", RefType: refTypeTag, Commit: plumbing.Hash{1}.String(),
+				}))
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", stagedPackage("cli-echo")).Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("Checkout", worktree, &gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}).Return(nil).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil)
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{2}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{2}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Log", plumbing.Hash{2}, plumbing.Hash{1}, changelogLimit+1).Return(nil, nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", stagedPackage("cli-echo"),
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}).Return(nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+			},
+			teardown: func(t *testing.T) {
+				meta, err := readInstallMetadata("./testdata/.akamai-cli/src/cli-echo")
+				require.NoError(t, err)
+				assert.Equal(t, &installMetadata{DefaultBranch: "master", Commit: plumbing.Hash{2}.String()}, meta)
+				require.NoError(t, os.Remove("./testdata/.akamai-cli/src/cli-echo/"+installMetadataFile))
+				// the version pinned to the tag is kept for rollback
+				previous, err := readInstallMetadata("./testdata/.akamai-cli/" + rollbackDirName + "/cli-echo")
+				require.NoError(t, err)
+				assert.Equal(t, "v1.0.0", previous.Ref)
+			},
+		},
+		"update package installed from an archive": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, writeInstallMetadata("./testdata/.akamai-cli/src/cli-echo", &installMetadata{
+					Source: archive, SourceType: sourceArchive, Checksum: "outdated",
+				}))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", stagedPackage("cli-echo"),
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}).Return(nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+			},
+			teardown: func(t *testing.T) {
+				meta, err := readInstallMetadata("./testdata/.akamai-cli/src/cli-echo")
+				require.NoError(t, err)
+				assert.Equal(t, &installMetadata{Source: archive, SourceType: sourceArchive, Checksum: checksum}, meta)
+				require.NoError(t, os.Remove("./testdata/.akamai-cli/src/cli-echo/"+installMetadataFile))
+			},
+		},
+		"python package is built at its final location": {
+			args: []string{"echo-python"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, writeInstallMetadata(pythonPackageDir, &installMetadata{
+					Source: pythonArchive, SourceType: sourceArchive, Checksum: "outdated",
+				}))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo-python"}).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", updatedPythonPackage,
+					packages.LanguageRequirements{Python: "3.0.0"}, []string{"echo-python"}).Return(nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+			},
+			teardown: func(t *testing.T) {
+				meta, err := readInstallMetadata(pythonPackageDir)
+				require.NoError(t, err)
+				assert.Equal(t, pythonChecksum, meta.Checksum)
+				require.NoError(t, os.Remove(filepath.Join(pythonPackageDir, installMetadataFile)))
+				previous, err := readInstallMetadata("./testdata/.akamai-cli/" + rollbackDirName + "/cli-echo-python")
+				require.NoError(t, err)
+				assert.Equal(t, "outdated", previous.Checksum)
+			},
+		},
+		"error building python package restores previous version": {
+			args: []string{"echo-python"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, writeInstallMetadata(pythonPackageDir, &installMetadata{
+					Source: pythonArchive, SourceType: sourceArchive, Checksum: "outdated",
+				}))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo-python"}).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", updatedPythonPackage,
+					packages.LanguageRequirements{Python: "3.0.0"}, []string{"echo-python"}).Return(fmt.Errorf("oops")).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.term.On("Writeln", []interface{}{color.RedString("oops")}).Return(0, nil).Once()
+			},
+			teardown: func(t *testing.T) {
+				meta, err := readInstallMetadata(pythonPackageDir)
+				require.NoError(t, err)
+				assert.Equal(t, "outdated", meta.Checksum)
+				require.NoError(t, os.Remove(filepath.Join(pythonPackageDir, installMetadataFile)))
+				_, err = os.Stat("./testdata/.akamai-cli/" + rollbackDirName + "/cli-echo-python")
+				assert.True(t, os.IsNotExist(err), "failed update should not be kept for rollback")
+			},
+			withError: `Unable to update command "echo-python", previous version has been kept`,
+		},
+		"package archive is unchanged": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, writeInstallMetadata("./testdata/.akamai-cli/src/cli-echo", &installMetadata{
+					Source: archive, SourceType: sourceArchive, Checksum: checksum,
+				}))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("WarnOK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString("command \"echo\" already up-to-date")}).Return(0, nil).Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.Remove("./testdata/.akamai-cli/src/cli-echo/"+installMetadataFile))
+			},
+		},
+		"source of package cannot be read": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, writeInstallMetadata("./testdata/.akamai-cli/src/cli-echo", &installMetadata{
+					Source: filepath.Join(tmpDir, "missing"), SourceType: sourceDirectory,
+				}))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.Remove("./testdata/.akamai-cli/src/cli-echo/"+installMetadataFile))
+			},
+			withError: `Unable to update command "echo", its source cannot be read`,
+		},
 		"error finding executable": {
 			args:      []string{"not-found"},
 			init:      func(t *testing.T, m *mocked) {},
@@ -237,6 +450,7 @@ func TestCmdUpdate(t *testing.T) {
 			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
 			command := &cli.Command{
 				Name:   "update",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "latest"}, &cli.BoolFlag{Name: "no-changelog"}},
 				Action: cmdUpdate(m.gitRepo, m.langManager),
 			}
 			app, ctx := setupTestApp(command, m)
@@ -253,14 +467,287 @@ func TestCmdUpdate(t *testing.T) {
 			if test.teardown != nil {
 				test.teardown(t)
 			}
+			require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/"+lockFileName))
+			require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/"+rollbackDirName))
 
 			m.cfg.AssertExpectations(t)
+			_, statErr := os.Stat("./testdata/.akamai-cli/" + stagingDirName)
+			assert.True(t, os.IsNotExist(statErr), "staging directory should be removed")
+			for _, pkg := range []string{"cli-echo", "cli-echo-invalid-json", "cli-echo-python"} {
+				_, statErr = os.Stat("./testdata/.akamai-cli/src/" + pkg + "/cli.json")
+				assert.NoError(t, statErr, "package directory should be kept")
+			}
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdUpdateConcurrently(t *testing.T) {
+	defer useTempAuditLog(t)()
+	tests := map[string]struct {
+		args           []string
+		init           func(*mocked)
+		expectedOutput []string
+		withError      string
+	}{
+		"update package once for each of its commands": {
+			args: []string{"echo", "echo"},
+			init: func(m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.gitRepo.On("Open", stagedPackage("cli-echo")).Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil)
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Updating %d package(s)...", []interface{}{1}).Return().Once()
+				m.term.On("OK").Return().Once()
+			},
+			expectedOutput: []string{
+				`Attempting to update "echo" command... ` + string(terminal.SpinnerStatusWarnOK) +
+					color.CyanString(`command "echo" already up-to-date`) + "\n",
+			},
+		},
+		"failed update is reported after all updates are done": {
+			args: []string{"echo-invalid-json", "echo"},
+			init: func(m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.gitRepo.On("Open", stagedPackage("cli-echo-invalid-json")).Return(fmt.Errorf("oops")).Once()
+				m.gitRepo.On("Open", stagedPackage("cli-echo")).Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Twice()
+				m.gitRepo.On("Pull", worktree).Return(nil)
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Updating %d package(s)...", []interface{}{2}).Return().Once()
+				m.term.On("Fail").Return().Once()
+				m.term.On("Writeln", []interface{}{color.RedString("unable to update, there an issue with the package repo: oops")}).Return(0, nil).Once()
+			},
+			expectedOutput: []string{
+				`Attempting to update "echo-invalid-json" command... ` + string(terminal.SpinnerStatusFail),
+				`Attempting to update "echo" command... ` + string(terminal.SpinnerStatusWarnOK) +
+					color.CyanString(`command "echo" already up-to-date`) + "\n",
+			},
+			withError: "Unable to update 1 of 2 package(s)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			newGitRepository = func() git.Repository { return m.gitRepo }
+			defer func() {
+				newGitRepository = git.NewRepository
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/"+lockFileName))
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/"+rollbackDirName))
+			}()
+			command := &cli.Command{
+				Name: "update",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "concurrency"},
+					&cli.BoolFlag{Name: "latest"},
+				},
+				Action: cmdUpdate(m.gitRepo, m.langManager),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "update", "--concurrency", "2")
+			args = append(args, test.args...)
+
+			test.init(m)
+			for _, output := range test.expectedOutput {
+				m.term.On("Printf", "%s", []interface{}{output}).Return().Once()
+			}
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			_, statErr := os.Stat("./testdata/.akamai-cli/" + stagingDirName)
+			assert.True(t, os.IsNotExist(statErr), "staging directory should be removed")
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdUpdateCheck(t *testing.T) {
+	tests := map[string]struct {
+		args         []string
+		init         func(*mocked)
+		withError    string
+		withExitCode int
+	}{
+		"check all packages": {
+			args: []string{"--check"},
+			init: func(m *mocked) {
+				row := func(name, installed, available, status string) []interface{} {
+					return []interface{}{fmt.Sprintf("  %-19s%-11s%-11s%s", name, installed, available, status)}
+				}
+				mockPackageChecks(m)
+				m.term.On("Spinner").Return(m.term).Twice()
+				m.term.On("Start", "Checking for package updates...", []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.YellowString("\nPackage Updates:\n")}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{color.New(color.FgWhite, color.Bold).Sprint(row("PACKAGE", "INSTALLED", "AVAILABLE", "STATUS")[0])}).Return(0, nil).Once()
+				m.term.On("Writeln", row("echo", "0100000", "0200000", color.YellowString("outdated"))).Return(0, nil).Once()
+				m.term.On("Writeln", row("echo-invalid-json", "-", "-", color.CyanString("unknown: unable to read package: invalid character 'i' looking for beginning of value"))).Return(0, nil).Once()
+				m.term.On("Writeln", row("echo-python", "-", "-", color.CyanString("unknown: unable to open package repository: oops"))).Return(0, nil).Once()
+				m.term.On("Writeln", row("installed", "0100000", "0100000", color.GreenString("current"))).Return(0, nil).Once()
+
+				m.term.On("Printf", "\nUpdate using \"%s\".\n", []interface{}{color.BlueString("%s update [command]", tools.Self())}).Return().Once()
+			},
+			withExitCode: exitFailure,
+		},
+		"check pinned package": {
+			args: []string{"--check", "echo"},
+			init: func(m *mocked) {
+				require.NoError(t, writeInstallMetadata("./testdata/.akamai-cli/src/cli-echo", &installMetadata{Held: true}))
+				row := func(name, installed, available, status string) []interface{} {
+					return []interface{}{fmt.Sprintf("  %-9s%-11s%-11s%s", name, installed, available, status)}
+				}
+				mockPackageChecks(m)
+				m.term.On("Spinner").Return(m.term).Twice()
+				m.term.On("Start", "Checking for package updates...", []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.YellowString("\nPackage Updates:\n")}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{color.New(color.FgWhite, color.Bold).Sprint(row("PACKAGE", "INSTALLED", "AVAILABLE", "STATUS")[0])}).Return(0, nil).Once()
+				m.term.On("Writeln", row("echo", "0100000", "0200000", color.YellowString("outdated")+" (pinned)")).Return(0, nil).Once()
+			},
+		},
+		"check specific packages in JSON format": {
+			args: []string{"--check", "--json", "installed", "e"},
+			init: func(m *mocked) {
+				mockPackageChecks(m)
+				m.term.On("Writeln", jsonOutput(`[
+					{"name": "echo", "installed": true, "status": "outdated", "installed-commit": "0100000", "available-commit": "0200000",
+						"commands": [{"name": "echo", "aliases": ["e"], "description": "echo command"}]},
+					{"name": "echo-python", "installed": true, "status": "unknown", "reason": "unable to open package repository: oops",
+						"commands": [{"name": "echo-python", "aliases": ["e"], "description": "echo command"}]},
+					{"name": "installed", "installed": true, "status": "current", "installed-commit": "0100000", "available-commit": "0100000",
+						"commands": [{"name": "installed", "aliases": ["ac2"], "description": "Test command"}]}
+				]`)).Return(0, nil).Once()
+			},
+			withExitCode: exitFailure,
+		},
+		"check packages ahead of or diverged from upstream": {
+			args: []string{"--check", "--json", "echo", "installed"},
+			init: func(m *mocked) {
+				master := plumbing.NewBranchReferenceName("master")
+				remoteMaster := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, "master")
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(master, plumbing.Hash{3}), nil).Once()
+				m.gitRepo.On("Fetch").Return(nil).Once()
+				m.gitRepo.On("Reference", remoteMaster).Return(plumbing.NewHashReference(remoteMaster, plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("IsAncestor", plumbing.Hash{1}, plumbing.Hash{3}).Return(true, nil).Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-python").Return(fmt.Errorf("oops")).Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-installed").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(master, plumbing.Hash{3}), nil).Once()
+				m.gitRepo.On("Fetch").Return(nil).Once()
+				m.gitRepo.On("Reference", remoteMaster).Return(plumbing.NewHashReference(remoteMaster, plumbing.Hash{2}), nil).Once()
+				m.gitRepo.On("IsAncestor", plumbing.Hash{2}, plumbing.Hash{3}).Return(false, nil).Once()
+
+				m.term.On("Writeln", jsonOutput(`[
+					{"name": "echo", "installed": true, "status": "current", "installed-commit": "0300000", "available-commit": "0100000",
+						"commands": [{"name": "echo", "aliases": ["e"], "description": "echo command"}]},
+					{"name": "installed", "installed": true, "status": "outdated", "installed-commit": "0300000", "available-commit": "0200000",
+						"commands": [{"name": "installed", "aliases": ["ac2"], "description": "Test command"}]}
+				]`)).Return(0, nil).Once()
+			},
+			withExitCode: exitFailure,
+		},
+		"check unknown command": {
+			args: []string{"--check", "--json", "abc"},
+			init: func(m *mocked) {
+				mockPackageChecks(m)
+			},
+			withError: `Command "abc" not found`,
+		},
+		"json output without check": {
+			args:      []string{"--json"},
+			init:      func(m *mocked) {},
+			withError: "Flag --json requires --check",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name: "update",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "check"},
+					&cli.BoolFlag{Name: "json"},
+				},
+				Action: cmdUpdate(m.gitRepo, m.langManager),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "update")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+			require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-echo/"+installMetadataFile))
+
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
 				return
 			}
+			if test.withExitCode != 0 {
+				var exitErr cli.ExitCoder
+				require.True(t, errors.As(err, &exitErr))
+				assert.Equal(t, test.withExitCode, exitErr.ExitCode())
+				return
+			}
 			require.NoError(t, err)
 		})
 	}
 }
+
+func TestPrintChangelog(t *testing.T) {
+	tests := map[string]struct {
+		commits int
+		init    func(*terminal.Mock)
+	}{
+		"no commits": {
+			init: func(m *terminal.Mock) {},
+		},
+		"changelog is truncated": {
+			commits: changelogLimit + 1,
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", []interface{}{color.YellowString(`Changes in "echo":`)}).Return(0, nil).Once()
+				m.On("Printf", "  %s %s\n", mock.Anything).Return().Times(changelogLimit)
+				m.On("Printf", "  ...and older commits\n", []interface{}(nil)).Return().Once()
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &terminal.Mock{}
+			var commits []*object.Commit
+			for i := 0; i < test.commits; i++ {
+				commits = append(commits, &object.Commit{Hash: plumbing.Hash{byte(i)}, Message: fmt.Sprintf("Commit %d", i)})
+			}
+			test.init(m)
+			printChangelog(m, "echo", commits)
+			m.AssertExpectations(t)
+		})
+	}
+}
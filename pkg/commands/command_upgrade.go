@@ -15,18 +15,118 @@
 package commands
 
 import (
-	"github.com/akamai/cli/pkg/log"
+	"context"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Masterminds/semver"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
 	"github.com/akamai/cli/pkg/stats"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
 	"github.com/akamai/cli/pkg/version"
 
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
 
+// release channels Akamai CLI can be upgraded from
+const (
+	channelStable  = "stable"
+	channelBeta    = "beta"
+	channelNightly = "nightly"
+)
+
+var releaseChannels = []string{channelStable, channelBeta, channelNightly}
+
+// exitUpgradeAvailable is the status code of "akamai upgrade --check" when a newer release is available
+const exitUpgradeAvailable = 2
+
+// intervals of automatic upgrade checks set with cli.upgrade-check-interval, in addition to durations such as 12h
+const (
+	upgradeCheckDaily  = "daily"
+	upgradeCheckWeekly = "weekly"
+	upgradeCheckNever  = "never"
+)
+
+type (
+	upgradeChannelKey struct{}
+	noUpgradeCheckKey struct{}
+)
+
+// WithoutUpgradeCheck marks the context so that Akamai CLI does not check for a new version on start, as with --no-update-check
+func WithoutUpgradeCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noUpgradeCheckKey{}, true)
+}
+
+// UpgradeCheckDisabled returns true if automatic checks for a new version are turned off, either with --no-update-check,
+// with the AKAMAI_CLI_NO_UPDATE_CHECK environment variable, e.g. in CI, or by setting cli.upgrade-check-interval to never
+func UpgradeCheckDisabled(ctx context.Context) bool {
+	if disabled, _ := ctx.Value(noUpgradeCheckKey{}).(bool); disabled {
+		return true
+	}
+	if value := os.Getenv("AKAMAI_CLI_NO_UPDATE_CHECK"); value != "" {
+		// any value other than false turns off the check, e.g. AKAMAI_CLI_NO_UPDATE_CHECK=yes
+		if enabled, err := strconv.ParseBool(value); err != nil || enabled {
+			return true
+		}
+	}
+	interval, err := parseUpgradeCheckInterval(upgradeCheckIntervalValue(ctx))
+	return err == nil && interval == 0
+}
+
+func upgradeCheckIntervalValue(ctx context.Context) string {
+	value, _ := config.Get(ctx).GetValue("cli", "upgrade-check-interval")
+	return value
+}
+
+// parseUpgradeCheckInterval parses the cli.upgrade-check-interval setting: daily, weekly, never or a duration such as 12h.
+// 0 means automatic checks are turned off. The daily interval is used for an empty value, and for an invalid value along with the error.
+func parseUpgradeCheckInterval(value string) (time.Duration, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", upgradeCheckDaily:
+		return 24 * time.Hour, nil
+	case upgradeCheckWeekly:
+		return 7 * 24 * time.Hour, nil
+	case upgradeCheckNever:
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return 24 * time.Hour, fmt.Errorf("invalid upgrade check interval %q, expected %s, %s, %s or a duration such as 12h",
+			value, upgradeCheckDaily, upgradeCheckWeekly, upgradeCheckNever)
+	}
+	return interval, nil
+}
+
+// withUpgradeChannel sets the release channel used for upgrade, overriding cli.channel config value
+func withUpgradeChannel(ctx context.Context, channel string) context.Context {
+	return context.WithValue(ctx, upgradeChannelKey{}, channel)
+}
+
+// upgradeChannel returns the release channel Akamai CLI is upgraded from: the one set with --channel flag,
+// or configured with "akamai config set cli.channel <channel>". Stable channel is used by default.
+func upgradeChannel(ctx context.Context) (string, error) {
+	channel, ok := ctx.Value(upgradeChannelKey{}).(string)
+	if !ok {
+		channel, _ = config.Get(ctx).GetValue("cli", "channel")
+	}
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if channel == "" {
+		return channelStable, nil
+	}
+	for _, c := range releaseChannels {
+		if c == channel {
+			return channel, nil
+		}
+	}
+	return "", fmt.Errorf("unknown release channel %q, expected one of: %s", channel, strings.Join(releaseChannels, ", "))
+}
+
 func cmdUpgrade(c *cli.Context) error {
 	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
 	logger := log.WithCommand(c.Context, c.Command.Name)
@@ -37,6 +137,25 @@ func cmdUpgrade(c *cli.Context) error {
 	}()
 	term := terminal.Get(c.Context)
 
+	if c.String("version") != "" {
+		if c.Bool("check") {
+			return commandFailure(errUsage, "Flags --check and --version cannot be used together")
+		}
+		return upgradeToVersion(c, c.String("version"))
+	}
+
+	if c.IsSet("channel") {
+		c.Context = withUpgradeChannel(c.Context, c.String("channel"))
+	}
+	channel, err := upgradeChannel(c.Context)
+	if err != nil {
+		return commandFailure(errConfig, err.Error())
+	}
+	logger.Debugf("Checking for upgrades in %s channel", channel)
+	if c.Bool("check") {
+		return checkCliUpgrade(c)
+	}
+
 	term.Spinner().Start("Checking for upgrades...")
 
 	latestVersion := CheckUpgradeVersion(c.Context, true)
@@ -58,3 +177,46 @@ func cmdUpgrade(c *cli.Context) error {
 	term.Printf("Akamai CLI version: %s", color.CyanString("v"+version.Version))
 	return nil
 }
+
+// checkCliUpgrade reports whether a newer release is available in the selected channel, without upgrading.
+// It exits with exitUpgradeAvailable status code if there is one, so that it can be used in scripts.
+func checkCliUpgrade(c *cli.Context) error {
+	term := terminal.Get(c.Context)
+
+	term.Spinner().Start("Checking for upgrades...")
+	latestVersion := getLatestReleaseVersion(c.Context)
+	if latestVersion == "0" {
+		term.Spinner().Fail()
+		return commandFailure(errNetwork, "Unable to find the latest version of Akamai CLI")
+	}
+	term.Spinner().OK()
+
+	if version.Compare(version.Version, latestVersion) != 1 {
+		term.Printf("Akamai CLI (%s) is up-to-date\n", color.CyanString("v"+version.Version))
+		return nil
+	}
+	term.Printf("New version available: %s (you are running: %s)\n", color.BlueString(latestVersion), color.BlueString(version.Version))
+	term.Printf("Upgrade using \"%s\".\n", color.BlueString("%s upgrade", tools.Self()))
+	return cli.Exit("", exitUpgradeAvailable)
+}
+
+// upgradeToVersion replaces Akamai CLI with given release, which may be older than the running version
+func upgradeToVersion(c *cli.Context, targetVersion string) error {
+	term := terminal.Get(c.Context)
+	targetVersion = strings.TrimPrefix(targetVersion, "v")
+	if _, err := semver.NewVersion(targetVersion); err != nil {
+		return commandFailure(errUsage, "Invalid version: %s", targetVersion)
+	}
+	if version.Compare(version.Version, targetVersion) == 0 {
+		term.Printf("Akamai CLI (%s) is already installed\n", color.CyanString("v"+version.Version))
+		return nil
+	}
+
+	os.Args = []string{os.Args[0], "--version"}
+	if !UpgradeCli(c.Context, targetVersion) {
+		stats.TrackEvent(c.Context, "upgrade.user", "failed", "to: "+targetVersion+" from:"+version.Version)
+		return cli.Exit("", 1)
+	}
+	stats.TrackEvent(c.Context, "upgrade.user", "success", "to: "+targetVersion+" from:"+version.Version)
+	return nil
+}
@@ -1,16 +1,24 @@
 package commands
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/akamai/cli/pkg/config"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
 	"github.com/akamai/cli/pkg/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -19,10 +27,21 @@ import (
 )
 
 func TestCmdUpgrade(t *testing.T) {
-	binURLRegexp := regexp.MustCompile(`/releases/download/[0-9]+\.[0-9]+\.[0-9]+/akamai-[0-9]+\.[0-9]+\.[0-9]+-[A-Za-z0-9]+$`)
+	defer useTempAuditLog(t)()
+	binURLRegexp := regexp.MustCompile(`/releases/download/[0-9]+\.[0-9]+\.[0-9]+[A-Za-z0-9.-]*/akamai-[0-9]+\.[0-9]+\.[0-9]+[A-Za-z0-9.-]*-[A-Za-z0-9]+$`)
+	releases := `[
+		{"tag_name": "12.0.0", "draft": true},
+		{"tag_name": "11.0.0-nightly.20211001", "prerelease": true},
+		{"tag_name": "10.1.0-beta.1", "prerelease": true},
+		{"tag_name": "10.0.0"}
+	]`
 	tests := map[string]struct {
 		args              []string
 		respLatestVersion string
+		respReleases      string
+		respBinary        string
+		respSig           string
+		expectUpgraded    bool
 		init              func(*mocked)
 		expectedExitCode  int
 		withError         string
@@ -32,6 +51,7 @@ func TestCmdUpgrade(t *testing.T) {
 			respLatestVersion: "10.0.0",
 			init: func(m *mocked) {
 
+				m.cfg.On("GetValue", "cli", "channel").Return("", false)
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
 
@@ -51,16 +71,19 @@ func TestCmdUpgrade(t *testing.T) {
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "Akamai CLI upgraded from %s to %s\n", []interface{}{"v" + version.Version, "v10.0.0"}).Return().Once()
 
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
 			},
-			expectedExitCode: 1,
+			expectedExitCode: 0,
+			expectUpgraded:   true,
 		},
 		"last upgrade check is set to ignore": {
 			args:              []string{"cli.testKey", "testValue"},
 			respLatestVersion: "10.0.0",
 			init: func(m *mocked) {
 
+				m.cfg.On("GetValue", "cli", "channel").Return("", false)
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
 
@@ -80,16 +103,19 @@ func TestCmdUpgrade(t *testing.T) {
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "Akamai CLI upgraded from %s to %s\n", []interface{}{"v" + version.Version, "v10.0.0"}).Return().Once()
 
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
 			},
-			expectedExitCode: 1,
+			expectedExitCode: 0,
+			expectUpgraded:   true,
 		},
 		"24 hours passed, upgrade": {
 			args:              []string{"cli.testKey", "testValue"},
 			respLatestVersion: "10.0.0",
 			init: func(m *mocked) {
 
+				m.cfg.On("GetValue", "cli", "channel").Return("", false)
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
 
@@ -109,35 +135,291 @@ func TestCmdUpgrade(t *testing.T) {
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "Akamai CLI upgraded from %s to %s\n", []interface{}{"v" + version.Version, "v10.0.0"}).Return().Once()
 
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
 			},
-			expectedExitCode: 1,
+			expectedExitCode: 0,
+			expectUpgraded:   true,
+		},
+		"new version is corrupted, keep previous version": {
+			args:              []string{"cli.testKey", "testValue"},
+			respLatestVersion: "10.0.0",
+			respBinary:        "binary file",
+			init: func(m *mocked) {
+
+				m.cfg.On("GetValue", "cli", "channel").Return("", false)
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
+
+				// Checking if cli should be upgraded
+				m.term.On("IsTTY").Return(true).Once()
+				m.cfg.On("GetValue", "cli", "last-upgrade-check").Return("never", true).Once()
+				m.cfg.On("SetValue", "cli", "last-upgrade-check", mock.AnythingOfType("string")).Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
+				m.term.On("Confirm", fmt.Sprintf("New upgrade found: 10.0.0 (you are running: %s). Upgrade now? [Y/n]: ", version.Version), true).Return(true, nil).Once()
+
+				// start upgrade
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Upgrading Akamai CLI", []interface{}(nil)).Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+				m.term.On("Writeln", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && strings.HasPrefix(args[0].(string), "Unable to verify new version: unable to run new version")
+				})).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{"Previous version has been kept, please try again."}).Return(0, nil).Once()
+
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+		},
+		"new version reports different version, keep previous version": {
+			args:              []string{"cli.testKey", "testValue"},
+			respLatestVersion: "10.0.0",
+			respBinary:        "#!/bin/sh\necho \"akamai version 9.0.0\"\n",
+			init: func(m *mocked) {
+
+				m.cfg.On("GetValue", "cli", "channel").Return("", false)
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
+
+				// Checking if cli should be upgraded
+				m.term.On("IsTTY").Return(true).Once()
+				m.cfg.On("GetValue", "cli", "last-upgrade-check").Return("never", true).Once()
+				m.cfg.On("SetValue", "cli", "last-upgrade-check", mock.AnythingOfType("string")).Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
+				m.term.On("Confirm", fmt.Sprintf("New upgrade found: 10.0.0 (you are running: %s). Upgrade now? [Y/n]: ", version.Version), true).Return(true, nil).Once()
+
+				// start upgrade
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Upgrading Akamai CLI", []interface{}(nil)).Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+				m.term.On("Writeln", []interface{}{"Unable to verify new version: new version reported unexpected version: akamai version 9.0.0"}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{"Previous version has been kept, please try again."}).Return(0, nil).Once()
+
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+		},
+		"checksum does not match, keep previous version": {
+			args:              []string{"cli.testKey", "testValue"},
+			respLatestVersion: "10.0.0",
+			respSig:           "9a3924b98ad3ce5e51d2c84a7129054c2523f39643a6ea27f8118511ecd4cdba",
+			init: func(m *mocked) {
+
+				m.cfg.On("GetValue", "cli", "channel").Return("", false)
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
+
+				// Checking if cli should be upgraded
+				m.term.On("IsTTY").Return(true).Once()
+				m.cfg.On("GetValue", "cli", "last-upgrade-check").Return("never", true).Once()
+				m.cfg.On("SetValue", "cli", "last-upgrade-check", mock.AnythingOfType("string")).Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
+				m.term.On("Confirm", fmt.Sprintf("New upgrade found: 10.0.0 (you are running: %s). Upgrade now? [Y/n]: ", version.Version), true).Return(true, nil).Once()
+
+				// start upgrade
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Upgrading Akamai CLI", []interface{}(nil)).Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+				m.term.On("Writeln", []interface{}{"checksums do not match, please try again"}).Return(0, nil).Once()
+
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+		},
+		"beta channel, upgrade to pre-release": {
+			respLatestVersion: "10.1.0-beta.1",
+			respReleases:      releases,
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "channel").Return("beta", true)
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
+
+				m.term.On("IsTTY").Return(true).Once()
+				m.cfg.On("GetValue", "cli", "last-upgrade-check").Return("never", true).Once()
+				m.cfg.On("SetValue", "cli", "last-upgrade-check", mock.AnythingOfType("string")).Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
+				m.term.On("Confirm", fmt.Sprintf("New upgrade found: 10.1.0-beta.1 (you are running: %s). Upgrade now? [Y/n]: ", version.Version), true).Return(true, nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Upgrading Akamai CLI", []interface{}(nil)).Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "Akamai CLI upgraded from %s to %s\n", []interface{}{"v" + version.Version, "v10.1.0-beta.1"}).Return().Once()
+
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			expectedExitCode: 0,
+			expectUpgraded:   true,
+		},
+		"channel flag overrides config, upgrade to nightly build": {
+			args:              []string{"--channel", "nightly"},
+			respLatestVersion: "11.0.0-nightly.20211001",
+			respReleases:      releases,
+			init: func(m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
+
+				m.term.On("IsTTY").Return(true).Once()
+				m.cfg.On("GetValue", "cli", "last-upgrade-check").Return("never", true).Once()
+				m.cfg.On("SetValue", "cli", "last-upgrade-check", mock.AnythingOfType("string")).Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
+				m.term.On("Confirm", fmt.Sprintf("New upgrade found: 11.0.0-nightly.20211001 (you are running: %s). Upgrade now? [Y/n]: ", version.Version), true).Return(true, nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Upgrading Akamai CLI", []interface{}(nil)).Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "Akamai CLI upgraded from %s to %s\n", []interface{}{"v" + version.Version, "v11.0.0-nightly.20211001"}).Return().Once()
+
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			expectedExitCode: 0,
+			expectUpgraded:   true,
+		},
+		"check only, newer version available": {
+			args:              []string{"--check"},
+			respLatestVersion: "10.0.0",
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "channel").Return("", false)
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "New version available: %s (you are running: %s)\n", []interface{}{"10.0.0", version.Version}).Return().Once()
+				m.term.On("Printf", "Upgrade using \"%s\".\n", []interface{}{tools.Self() + " upgrade"}).Return().Once()
+			},
+			expectedExitCode: exitUpgradeAvailable,
+		},
+		"check only, up-to-date": {
+			args:              []string{"--check"},
+			respLatestVersion: version.Version,
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "channel").Return("", false)
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "Akamai CLI (%s) is up-to-date\n", []interface{}{"v" + version.Version}).Return().Once()
+			},
+		},
+		"check only, latest version not found": {
+			args:              []string{"--check", "--channel", "beta"},
+			respLatestVersion: "10.0.0",
+			respReleases:      "[]",
+			init: func(m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+			},
+			expectedExitCode: exitNetwork,
+			withError:        "Unable to find the latest version of Akamai CLI",
+		},
+		"downgrade to specific version": {
+			args:              []string{"--version", "v1.2.0"},
+			respLatestVersion: "1.2.0",
+			init: func(m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Upgrading Akamai CLI", []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "Akamai CLI downgraded from %s to %s\n", []interface{}{"v" + version.Version, "v1.2.0"}).Return().Once()
+
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			expectUpgraded: true,
+		},
+		"specific version already installed": {
+			args: []string{"--version", version.Version},
+			init: func(m *mocked) {
+				m.term.On("Printf", "Akamai CLI (%s) is already installed\n", []interface{}{"v" + version.Version}).Return().Once()
+			},
+		},
+		"invalid version": {
+			args:             []string{"--version", "latest"},
+			init:             func(m *mocked) {},
+			expectedExitCode: exitUsage,
+			withError:        "Invalid version: latest",
+		},
+		"check and version flags": {
+			args:             []string{"--check", "--version", "1.2.0"},
+			init:             func(m *mocked) {},
+			expectedExitCode: exitUsage,
+			withError:        "Flags --check and --version cannot be used together",
+		},
+		"unknown channel": {
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "channel").Return("alpha", true)
+			},
+			expectedExitCode: exitConfig,
+			withError:        `unknown release channel "alpha", expected one of: stable, beta, nightly`,
 		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
+			binary := test.respBinary
+			if binary == "" {
+				binary = fmt.Sprintf("#!/bin/sh\necho \"akamai version %s\"\n", test.respLatestVersion)
+			}
+			sig := test.respSig
+			if sig == "" {
+				sum := sha256.Sum256([]byte(binary))
+				sig = hex.EncodeToString(sum[:])
+			}
 			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				url := r.URL.String()
 				if url == "/releases/latest" {
 					w.Header().Set("Location", test.respLatestVersion)
 					w.WriteHeader(http.StatusFound)
+				} else if url == "/releases?per_page=100" {
+					_, err := w.Write([]byte(test.respReleases))
+					require.NoError(t, err)
 				} else if binURLRegexp.MatchString(url) {
-					_, err := w.Write([]byte("binary file"))
+					_, err := w.Write([]byte(binary))
 					require.NoError(t, err)
 				} else if strings.HasSuffix(url, ".sig") {
-					// a valid SHA256 checksum for "binary file" string
-					_, err := w.Write([]byte("9a3924b98ad3ce5e51d2c84a7129054c2523f39643a6ea27f8118511ecd4cdba"))
+					_, err := w.Write([]byte(sig))
 					require.NoError(t, err)
 				} else {
 					t.Fatalf("unknown URL: %s", url)
 				}
 			}))
 			require.NoError(t, os.Setenv("CLI_REPOSITORY", srv.URL))
+
+			// replace a copy of the executable instead of the test binary
+			selfPath := filepath.Join(t.TempDir(), "akamai")
+			require.NoError(t, ioutil.WriteFile(selfPath, []byte("previous version"), 0755))
+			defer mockExecutable(selfPath)()
 			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
 			command := &cli.Command{
-				Name:   "upgrade",
+				Name: "upgrade",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "channel"},
+					&cli.BoolFlag{Name: "check"},
+					&cli.StringFlag{Name: "version"},
+				},
 				Action: cmdUpgrade,
 			}
 			app, ctx := setupTestApp(command, m)
@@ -153,15 +435,192 @@ func TestCmdUpgrade(t *testing.T) {
 			args = append(args, test.args...)
 
 			test.init(m)
-			err := app.RunContext(ctx, args)
+			runErr := app.RunContext(ctx, args)
 
 			m.cfg.AssertExpectations(t)
-			if test.withError != "" {
-				assert.Error(t, err)
-				assert.Contains(t, err.Error(), test.withError)
+			m.term.AssertExpectations(t)
+			data, err := ioutil.ReadFile(selfPath)
+			require.NoError(t, err)
+			if test.expectUpgraded {
+				assert.Equal(t, binary, string(data))
+			} else {
+				assert.Equal(t, "previous version", string(data))
+			}
+			files, err := ioutil.ReadDir(filepath.Dir(selfPath))
+			require.NoError(t, err)
+			assert.Len(t, files, 1)
+			if test.withError != "" || test.expectedExitCode != 0 {
+				assert.Error(t, runErr)
+				assert.Contains(t, runErr.Error(), test.withError)
 				return
 			}
+			require.NoError(t, runErr)
+		})
+	}
+}
+
+// mockExecutable makes the executable at given path the running executable, returning a function restoring it
+func mockExecutable(path string) func() {
+	executable = func() (string, error) {
+		return path, nil
+	}
+	return func() {
+		executable = os.Executable
+	}
+}
+
+func TestApplyStagedUpgrade(t *testing.T) {
+	tests := map[string]struct {
+		staged          bool
+		arg             string
+		symlink         bool
+		expectedApplied bool
+		expectedData    string
+	}{
+		"staged upgrade is applied": {
+			staged:          true,
+			expectedApplied: true,
+			expectedData:    "new version",
+		},
+		"started from PATH": {
+			staged:          true,
+			arg:             "akamai",
+			expectedApplied: true,
+			expectedData:    "new version",
+		},
+		"started with a relative path to a symlink": {
+			staged:          true,
+			arg:             "./bin/akamai",
+			symlink:         true,
+			expectedApplied: true,
+			expectedData:    "new version",
+		},
+		"no staged upgrade": {
+			expectedData: "previous version",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			selfPath := filepath.Join(dir, "akamai")
+			require.NoError(t, ioutil.WriteFile(selfPath, []byte("previous version"), 0755))
+			if test.staged {
+				require.NoError(t, ioutil.WriteFile(stagedUpgradePath(selfPath), []byte("new version"), 0755))
+			}
+			exePath := selfPath
+			if test.symlink {
+				if runtime.GOOS == "windows" {
+					t.Skip("creating symlinks requires privileges on Windows")
+				}
+				exePath = filepath.Join(dir, "bin", "akamai")
+				require.NoError(t, os.Mkdir(filepath.Dir(exePath), 0755))
+				require.NoError(t, os.Symlink(selfPath, exePath))
+			}
+			defer mockExecutable(exePath)()
+			defer func(arg string) {
+				os.Args[0] = arg
+			}(os.Args[0])
+			os.Args[0] = selfPath
+			if test.arg != "" {
+				os.Args[0] = test.arg
+			}
+
+			applied, err := ApplyStagedUpgrade()
 			require.NoError(t, err)
+			assert.Equal(t, test.expectedApplied, applied)
+
+			data, err := ioutil.ReadFile(selfPath)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedData, string(data))
+			_, err = os.Stat(stagedUpgradePath(selfPath))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestLatestChannelRelease(t *testing.T) {
+	releases := []githubRelease{
+		{TagName: "1.5.0", Draft: true},
+		{TagName: "1.4.0-nightly.20211001", Prerelease: true},
+		{TagName: "1.4.0-beta.2", Prerelease: true},
+		{TagName: "1.4.0-beta.1", Prerelease: true},
+		{TagName: "1.3.0"},
+		{TagName: "invalid"},
+	}
+	tests := map[string]struct {
+		releases []githubRelease
+		channel  string
+		expected string
+	}{
+		"stable":                {releases: releases, channel: channelStable, expected: "1.3.0"},
+		"beta":                  {releases: releases, channel: channelBeta, expected: "1.4.0-beta.2"},
+		"nightly":               {releases: releases, channel: channelNightly, expected: "1.4.0-nightly.20211001"},
+		"beta, stable is newer": {releases: append(releases, githubRelease{TagName: "1.4.0"}), channel: channelBeta, expected: "1.4.0"},
+		"no releases":           {channel: channelBeta},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, latestChannelRelease(test.releases, test.channel))
+		})
+	}
+}
+
+func TestParseUpgradeCheckInterval(t *testing.T) {
+	tests := map[string]struct {
+		value     string
+		expected  time.Duration
+		withError bool
+	}{
+		"default":  {value: "", expected: 24 * time.Hour},
+		"daily":    {value: "daily", expected: 24 * time.Hour},
+		"weekly":   {value: "Weekly", expected: 7 * 24 * time.Hour},
+		"never":    {value: "never", expected: 0},
+		"duration": {value: "12h", expected: 12 * time.Hour},
+		"invalid":  {value: "monthly", expected: 24 * time.Hour, withError: true},
+		"negative": {value: "-1h", expected: 24 * time.Hour, withError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			interval, err := parseUpgradeCheckInterval(test.value)
+			assert.Equal(t, test.expected, interval)
+			assert.Equal(t, test.withError, err != nil)
+		})
+	}
+}
+
+func TestUpgradeCheckDisabled(t *testing.T) {
+	tests := map[string]struct {
+		flag     bool
+		env      string
+		interval string
+		expected bool
+	}{
+		"enabled by default":            {},
+		"no-update-check flag":          {flag: true, expected: true},
+		"environment variable":          {env: "1", expected: true},
+		"environment variable, false":   {env: "false"},
+		"environment variable, any":     {env: "yes", expected: true},
+		"interval set to never":         {interval: "never", expected: true},
+		"interval set to weekly":        {interval: "weekly"},
+		"invalid interval, use default": {interval: "monthly"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_NO_UPDATE_CHECK", test.env))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_NO_UPDATE_CHECK"))
+			}()
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", "upgrade-check-interval").Return(test.interval, test.interval != "").Maybe()
+			ctx := config.Context(context.Background(), cfg)
+			if test.flag {
+				ctx = WithoutUpgradeCheck(ctx)
+			}
+			assert.Equal(t, test.expected, UpgradeCheckDisabled(ctx))
 		})
 	}
 }
@@ -0,0 +1,323 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// brokenPackage is an installed package which failed verification
+type brokenPackage struct {
+	dir      string
+	meta     *installMetadata
+	problems []string
+}
+
+func cmdVerify(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		start := time.Now()
+		logger.Debug("VERIFY START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("VERIFY FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("VERIFY ERROR: %v", e.Error())
+			}
+		}()
+		term := terminal.Get(c.Context)
+
+		locked := make(map[string]lockedPackage)
+		if path, err := defaultLockFilePath(); err == nil {
+			if lock, err := readLockFile(path); err == nil {
+				for _, pkg := range lock.Packages {
+					locked[pkg.Name] = pkg
+				}
+			} else if !os.IsNotExist(err) {
+				logger.Warnf("Unable to read lock file, checksums are not verified: %s", err.Error())
+			}
+		}
+
+		var results []doctorResult
+		var broken []brokenPackage
+		for _, dir := range getPackagePaths() {
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				continue
+			}
+			name := filepath.Base(dir)
+			meta, problems := verifyPackage(c.Context, gitRepo, langManager, dir, locked[name].Binaries)
+			if len(problems) == 0 {
+				results = append(results, doctorResult{doctorOK, fmt.Sprintf("%s is intact", name), ""})
+				continue
+			}
+			fix := fmt.Sprintf("Run \"%s verify --repair\" to reinstall it", tools.Self())
+			if !isRepairable(meta) {
+				fix = fmt.Sprintf("Reinstall the package, or remove %s", dir)
+			}
+			results = append(results, doctorResult{doctorFail, fmt.Sprintf("%s: %s", name, strings.Join(problems, "; ")), fix})
+			broken = append(broken, brokenPackage{dir, meta, problems})
+		}
+		if len(results) == 0 {
+			results = append(results, doctorResult{doctorOK, "No packages installed", ""})
+		}
+		printDoctorResults(term, "Installed packages", results)
+		term.Writeln()
+
+		if len(broken) > 0 && c.Bool("repair") {
+			remaining, err := repairPackages(c.Context, gitRepo, langManager, broken)
+			if err != nil {
+				return err
+			}
+			broken = remaining
+		}
+
+		if len(broken) > 0 {
+			if c.Bool("repair") {
+				return cli.Exit(color.RedString("Unable to repair %d package(s), reinstall them manually", len(broken)), 1)
+			}
+			return cli.Exit(color.RedString("Found %d broken package(s), run \"%s verify --repair\" to reinstall them", len(broken), tools.Self()), 1)
+		}
+		term.Writeln(color.GreenString("All packages are intact"))
+		return nil
+	}
+}
+
+// verifyPackage checks that the package in given directory is installed completely and was not modified since.
+// Install metadata of the package, if any, is returned along with the list of problems found.
+func verifyPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, dir string, lockedBinaries map[string]string) (*installMetadata, []string) {
+	if err := validatePackage(dir); err != nil {
+		return nil, []string{err.Error()}
+	}
+	pkg, err := readPackage(dir)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+	meta, err := readInstallMetadata(dir)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("unable to read install metadata: %s", err)}
+	}
+
+	var problems []string
+	// packages running in a container have no executables nor dependencies installed locally
+	if meta == nil || meta.Image == "" {
+		problems = append(problems, verifyExecutables(ctx, langManager, pkg)...)
+		problems = append(problems, verifyDependencies(dir, pkg)...)
+	}
+
+	// packages installed from a local directory or an archive are not git repositories
+	if meta != nil && meta.Commit != "" && meta.Source == "" {
+		if problem := verifyWorktree(gitRepo, dir, meta.Commit); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	if len(lockedBinaries) > 0 {
+		mismatched, err := mismatchedBinaries(dir, lockedBinaries)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("unable to verify checksums: %s", err))
+		} else if len(mismatched) > 0 {
+			problems = append(problems, fmt.Sprintf("checksums do not match the lock file: %s", strings.Join(mismatched, ", ")))
+		}
+	}
+	return meta, problems
+}
+
+// verifyExecutables checks that every command of the package has an executable
+func verifyExecutables(ctx context.Context, langManager packages.LangManager, pkg subcommands) []string {
+	var missing, notExecutable []string
+	for _, cmd := range executableCommands(pkg) {
+		executable, err := findExec(ctx, langManager, cmd.Name)
+		if err != nil {
+			missing = append(missing, cmd.Name)
+			continue
+		}
+		// scripts are run by an interpreter, only binaries need the executable bit
+		if len(executable) != 1 || runtime.GOOS == "windows" {
+			continue
+		}
+		if info, err := os.Stat(executable[0]); err != nil || info.Mode()&0111 == 0 {
+			notExecutable = append(notExecutable, filepath.Base(executable[0]))
+		}
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("no executable found for: %s", strings.Join(missing, ", ")))
+	}
+	if len(notExecutable) > 0 {
+		problems = append(problems, fmt.Sprintf("not executable: %s", strings.Join(notExecutable, ", ")))
+	}
+	return problems
+}
+
+// verifyDependencies checks that dependencies declared by the package were installed in the package directory
+func verifyDependencies(dir string, pkg subcommands) []string {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	var problems []string
+	if pkg.Requirements.Python != "" && exists("requirements.txt") {
+		if _, ok := packages.VenvPython(dir); !ok {
+			problems = append(problems, fmt.Sprintf("Python virtual environment (%s) is missing", packages.VenvDirName))
+		}
+	}
+	if exists("package.json") && !exists("node_modules") {
+		problems = append(problems, "node_modules is missing")
+	}
+	return problems
+}
+
+// verifyWorktree checks that the package repository is still checked out at the commit it was installed from,
+// and that none of the files tracked in the repository were changed or removed
+func verifyWorktree(gitRepo git.Repository, dir, commit string) string {
+	if err := gitRepo.Open(dir); err != nil {
+		return fmt.Sprintf("unable to open git repository: %s", err)
+	}
+	head, err := gitRepo.Head()
+	if err != nil {
+		return fmt.Sprintf("unable to read git repository: %s", err)
+	}
+	if head.Hash().String() != commit {
+		return fmt.Sprintf("checked out commit %s differs from the installed commit %s", shortHash(head.Hash()), shortHash(plumbing.NewHash(commit)))
+	}
+
+	w, err := gitRepo.Worktree()
+	if err != nil {
+		return fmt.Sprintf("unable to read working tree: %s", err)
+	}
+	status, err := w.Status()
+	if err != nil {
+		return fmt.Sprintf("unable to read working tree: %s", err)
+	}
+	// build artifacts and installed dependencies are untracked, they are verified separately
+	var changed []string
+	for path, s := range status {
+		if !isUnchanged(s.Worktree) || !isUnchanged(s.Staging) {
+			changed = append(changed, path)
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return fmt.Sprintf("files changed since installation: %s", strings.Join(changed, ", "))
+	}
+	return ""
+}
+
+func isUnchanged(code gogit.StatusCode) bool {
+	return code == gogit.Unmodified || code == gogit.Untracked
+}
+
+func isRepairable(meta *installMetadata) bool {
+	return meta != nil && meta.Repo != "" && meta.Source == ""
+}
+
+// repairPackages reinstalls broken packages from the repository and ref they were installed from.
+// A package which cannot be reinstalled is restored as it was. Packages which are still broken are returned.
+func repairPackages(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, broken []brokenPackage) ([]brokenPackage, error) {
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+
+	var remaining []brokenPackage
+	var repaired int
+	for _, pkg := range broken {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		name := filepath.Base(pkg.dir)
+		if !isRepairable(pkg.meta) {
+			term.Writeln(color.YellowString("Package %s was not installed from a git repository and cannot be repaired", name))
+			remaining = append(remaining, pkg)
+			continue
+		}
+		if err := reinstallPackage(ctx, gitRepo, langManager, pkg); err != nil {
+			logger.Errorf("Unable to repair package %s: %s", name, err.Error())
+			term.Writeln(color.RedString("Unable to repair package %s: %s", name, err.Error()))
+			remaining = append(remaining, pkg)
+			continue
+		}
+		term.Writeln(color.GreenString("Package %s repaired", name))
+		repaired++
+	}
+
+	if repaired > 0 {
+		updateLockFile(ctx)
+		updateCommandShims(ctx)
+	}
+	return remaining, nil
+}
+
+// reinstallPackage replaces the package with a fresh installation. The broken installation is moved to the staging area
+// until the package is installed, so that it can be restored on failure.
+func reinstallPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, pkg brokenPackage) error {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return err
+	}
+	stagingRoot := filepath.Join(cliPath, stagingDirName)
+	if err := os.MkdirAll(stagingRoot, 0700); err != nil {
+		return err
+	}
+	// remove the staging root when no other staged package is present
+	defer os.Remove(stagingRoot)
+
+	backupDir := filepath.Join(stagingRoot, filepath.Base(pkg.dir)+".broken")
+	if err := os.RemoveAll(backupDir); err != nil {
+		return err
+	}
+	if err := os.Rename(pkg.dir, backupDir); err != nil {
+		return err
+	}
+
+	if _, err := installPackage(ctx, gitRepo, langManager, pkg.meta.Repo, pkg.meta.Ref, false, nil); err != nil {
+		if rmErr := os.RemoveAll(pkg.dir); rmErr != nil {
+			return fmt.Errorf("%s; unable to restore the package: %s", err, rmErr)
+		}
+		if rbErr := os.Rename(backupDir, pkg.dir); rbErr != nil {
+			return fmt.Errorf("%s; unable to restore the package: %s", err, rbErr)
+		}
+		return err
+	}
+
+	if pkg.meta.Held {
+		if meta, err := readInstallMetadata(pkg.dir); err == nil && meta != nil {
+			meta.Held = true
+			if err := writeInstallMetadata(pkg.dir, meta); err != nil {
+				log.FromContext(ctx).Errorf("Unable to save install metadata: %s", err.Error())
+			}
+		}
+	}
+	return os.RemoveAll(backupDir)
+}
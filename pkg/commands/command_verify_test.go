@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// writeVerifiedPackage creates package cli-test in given CLI home, with an executable of its "test" command
+func writeVerifiedPackage(t *testing.T, home, manifest string, mode os.FileMode) string {
+	dir := filepath.Join(home, ".akamai-cli", "src", "cli-test")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(manifest), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", "akamai-test"), []byte("#!/bin/sh\n"), mode))
+	return dir
+}
+
+func TestCmdVerify(t *testing.T) {
+	goManifest := `{"requirements": {"go": "1.14.0"}, "commands": [{"name": "test"}]}`
+	repo := "https://github.com/akamai/cli-test"
+	repair := fmt.Sprintf(`Run "%s verify --repair" to reinstall it`, tools.Self())
+	tests := map[string]struct {
+		args      []string
+		init      func(*testing.T, *mocked, string)
+		expected  [][]interface{}
+		check     func(*testing.T, string)
+		withError string
+	}{
+		"package intact": {
+			init: func(t *testing.T, m *mocked, home string) {
+				writeVerifiedPackage(t, home, goManifest, 0755)
+				m.term.On("Writeln", []interface{}{color.GreenString("All packages are intact")}).Return(0, nil).Once()
+			},
+			expected: [][]interface{}{{color.GreenString("[OK]"), "cli-test is intact"}},
+		},
+		"missing executable and dependencies": {
+			init: func(t *testing.T, m *mocked, home string) {
+				dir := writeVerifiedPackage(t, home, `{"requirements": {"python": "3.0.0"}, "commands": [{"name": "test"}]}`, 0755)
+				require.NoError(t, os.Remove(filepath.Join(dir, "bin", "akamai-test")))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "requirements.txt"), nil, 0644))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0644))
+			},
+			expected: [][]interface{}{
+				{color.RedString("[FAIL]"), "cli-test: no executable found for: test; Python virtual environment (.venv) is missing; node_modules is missing"},
+				{color.CyanString("Fix:"), "Reinstall the package, or remove ${HOME}/.akamai-cli/src/cli-test"},
+			},
+			withError: color.RedString("Found 1 broken package(s)"),
+		},
+		"binary not executable": {
+			init: func(t *testing.T, m *mocked, home string) {
+				dir := writeVerifiedPackage(t, home, goManifest, 0644)
+				bin := filepath.Join(dir, "bin", "akamai-test")
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, bin).Return([]string{bin}, nil).Once()
+			},
+			expected: [][]interface{}{
+				{color.RedString("[FAIL]"), "cli-test: not executable: akamai-test"},
+			},
+			withError: color.RedString("Found 1 broken package(s)"),
+		},
+		"different commit checked out": {
+			init: func(t *testing.T, m *mocked, home string) {
+				dir := writeVerifiedPackage(t, home, goManifest, 0755)
+				require.NoError(t, writeInstallMetadata(dir, &installMetadata{Repo: repo, Commit: plumbing.Hash{1}.String()}))
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{2}), nil).Once()
+			},
+			expected: [][]interface{}{
+				{color.RedString("[FAIL]"), "cli-test: checked out commit 0200000 differs from the installed commit 0100000"},
+				{color.CyanString("Fix:"), repair},
+			},
+			withError: color.RedString("Found 1 broken package(s)"),
+		},
+		"tracked files changed": {
+			init: func(t *testing.T, m *mocked, home string) {
+				dir := writeVerifiedPackage(t, home, goManifest, 0755)
+				r, err := gogit.PlainInit(dir, false)
+				require.NoError(t, err)
+				w, err := r.Worktree()
+				require.NoError(t, err)
+				_, err = w.Add("cli.json")
+				require.NoError(t, err)
+				hash, err := w.Commit("init", &gogit.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com"}})
+				require.NoError(t, err)
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(goManifest+"\n"), 0644))
+				require.NoError(t, writeInstallMetadata(dir, &installMetadata{Repo: repo, Commit: hash.String()}))
+
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), hash), nil).Once()
+				m.gitRepo.On("Worktree").Return(w, nil).Once()
+			},
+			expected: [][]interface{}{
+				{color.RedString("[FAIL]"), "cli-test: files changed since installation: cli.json"},
+			},
+			withError: color.RedString("Found 1 broken package(s)"),
+		},
+		"checksum mismatch": {
+			init: func(t *testing.T, m *mocked, home string) {
+				writeVerifiedPackage(t, home, goManifest, 0755)
+				lock := &lockFile{Version: lockFileVersion, Packages: []lockedPackage{
+					{Name: "cli-test", Repo: repo, Commit: plumbing.Hash{1}.String(), Binaries: map[string]string{"bin/akamai-test": "0123"}},
+				}}
+				require.NoError(t, writeLockFile(filepath.Join(home, ".akamai-cli", lockFileName), lock))
+			},
+			expected: [][]interface{}{
+				{color.RedString("[FAIL]"), "cli-test: checksums do not match the lock file: bin/akamai-test"},
+			},
+			withError: color.RedString("Found 1 broken package(s)"),
+		},
+		"repair package": {
+			args: []string{"--repair"},
+			init: func(t *testing.T, m *mocked, home string) {
+				dir := writeVerifiedPackage(t, home, goManifest, 0755)
+				require.NoError(t, writeInstallMetadata(dir, &installMetadata{Repo: repo, Commit: plumbing.Hash{1}.String(), Held: true}))
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{2}), nil).Once()
+
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{repo}).Return().Once()
+				m.gitRepo.On("Clone", dir, repo, false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						writeVerifiedPackage(t, home, goManifest, 0755)
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{2}), nil).Once()
+				m.term.On("OK").Return()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", dir, packages.LanguageRequirements{Go: "1.14.0"}, []string{"test"}).Return(nil).Once()
+				m.term.On("Writeln", []interface{}{color.GreenString("Package %s repaired", "cli-test")}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{color.GreenString("All packages are intact")}).Return(0, nil).Once()
+			},
+			expected: [][]interface{}{
+				{color.RedString("[FAIL]"), "cli-test: checked out commit 0200000 differs from the installed commit 0100000"},
+			},
+			check: func(t *testing.T, dir string) {
+				meta, err := readInstallMetadata(dir)
+				require.NoError(t, err)
+				assert.Equal(t, &installMetadata{Repo: repo, DefaultBranch: "master", Commit: plumbing.Hash{2}.String(), Held: true}, meta)
+				_, err = os.Stat(filepath.Join(filepath.Dir(filepath.Dir(dir)), stagingDirName))
+				assert.True(t, os.IsNotExist(err))
+			},
+		},
+		"restore package which cannot be repaired": {
+			args: []string{"--repair"},
+			init: func(t *testing.T, m *mocked, home string) {
+				dir := writeVerifiedPackage(t, home, goManifest, 0755)
+				require.NoError(t, writeInstallMetadata(dir, &installMetadata{Repo: repo, Ref: "v1.0.0", RefType: refTypeTag, Commit: plumbing.Hash{1}.String(), Held: true}))
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{2}), nil).Once()
+
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{repo}).Return().Once()
+				m.gitRepo.On("Clone", dir, repo, false, defaultCloneDepth, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						writeVerifiedPackage(t, home, goManifest, 0755)
+					})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{2}), nil).Once()
+				m.gitRepo.On("Worktree").Return(nil, fmt.Errorf("oops")).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.term.On("Writeln", []interface{}{color.RedString("Unable to repair package %s: %s", "cli-test", color.RedString(`Unable to check out "v1.0.0": oops`))}).Return(0, nil).Once()
+			},
+			expected: [][]interface{}{
+				{color.RedString("[FAIL]"), "cli-test: checked out commit 0200000 differs from the installed commit 0100000"},
+			},
+			check: func(t *testing.T, dir string) {
+				meta, err := readInstallMetadata(dir)
+				require.NoError(t, err)
+				assert.Equal(t, plumbing.Hash{1}.String(), meta.Commit)
+			},
+			withError: color.RedString("Unable to repair 1 package(s)"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "akamai-verify")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(home))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("GetValue", mock.Anything, mock.Anything).Return("", false).Maybe()
+			test.init(t, m, home)
+			m.term.On("Writeln", mock.Anything).Return(0, nil)
+			m.term.On("Printf", mock.Anything, mock.Anything).Return()
+
+			command := &cli.Command{
+				Name:   "verify",
+				Action: cmdVerify(m.gitRepo, m.langManager),
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "repair"}},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "verify")
+			args = append(args, test.args...)
+			err = app.RunContext(ctx, args)
+
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			for _, expected := range test.expected {
+				format := "  %s %s\n"
+				if expected[0] == color.CyanString("Fix:") {
+					format = "    %s %s\n"
+				}
+				m.term.AssertCalled(t, "Printf", format, []interface{}{expected[0], strings.ReplaceAll(expected[1].(string), "${HOME}", home)})
+			}
+			m.term.AssertExpectations(t)
+			if test.check != nil {
+				test.check(t, filepath.Join(home, ".akamai-cli", "src", "cli-test"))
+			}
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
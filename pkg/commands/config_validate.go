@@ -0,0 +1,372 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ini/ini"
+
+	"github.com/akamai/cli/pkg/app"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/registry"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+type (
+	// configProblem is an error found in the config file, on the given line if it is known
+	configProblem struct {
+		line    int
+		message string
+	}
+
+	// valueValidator returns an error if the value of a setting is malformed, nil validators accept any value
+	valueValidator func(value string) error
+
+	// configLines maps sections of the config file, and settings in <section>\x00<key> format, to the line they are on
+	configLines map[string]int
+)
+
+var (
+	// cliSettings lists the settings of the cli section
+	cliSettings = map[string]valueValidator{
+		"audit-log":              nil,
+		"audit-log-format":       func(value string) error { _, err := parseAuditLogFormat(value); return err },
+		"build-cache":            validateBool,
+		"cache-path":             nil,
+		"channel":                validateChannel,
+		"client-id":              nil,
+		"config-version":         nil,
+		"container-engine":       nil,
+		"enable-cli-statistics":  validateStatistics,
+		"hook-timeout":           durationValidator(parseHookTimeout),
+		"install-in-path":        nil,
+		"install-strategy":       func(value string) error { _, err := parseInstallStrategy(value); return err },
+		"last-ping":              validateCheckTime,
+		"last-upgrade-check":     validateCheckTime,
+		"lock-timeout":           durationValidator(tools.ParseLockTimeout),
+		"log-format":             validateLogFormat,
+		"log-level":              validateLogLevel,
+		"log-path":               nil,
+		"package-cache-ttl":      durationValidator(parsePackageListTTL),
+		"proxy":                  func(value string) error { _, err := app.ParseProxy(value); return err },
+		"request-timeout":        func(value string) error { _, err := tools.ParseRetryOptions("", "", value); return err },
+		"retries":                func(value string) error { _, err := tools.ParseRetryOptions(value, "", ""); return err },
+		"retry-backoff":          func(value string) error { _, err := tools.ParseRetryOptions("", value, ""); return err },
+		"secret-storage":         func(value string) error { _, err := config.ParseSecretStorage(value); return err },
+		"stats-version":          nil,
+		"telemetry":              func(value string) error { _, err := stats.ParseTelemetry(value); return err },
+		"upgrade-check-interval": durationValidator(parseUpgradeCheckInterval),
+	}
+
+	// installSettings lists the settings of the install section
+	installSettings = map[string]valueValidator{
+		"build-timeout": durationValidator(parseBuildTimeout),
+		"clone-depth":   func(value string) error { _, err := parseCloneDepth(value); return err },
+		"git-timeout":   durationValidator(parseGitTimeout),
+		"github-token":  nil,
+		"gitlab-token":  nil,
+	}
+
+	// registrySettings lists the settings of each registry, stored as <registry>.<setting> in the registries section
+	registrySettings = map[string]valueValidator{
+		"url":      nil,
+		"priority": validateInt,
+		"header":   validateHeader,
+		"disabled": validateBool,
+		"timeout":  durationValidator(registry.ParseTimeout),
+	}
+
+	logLevels = []string{"fatal", "error", "warn", "warning", "info", "debug"}
+)
+
+func (p configProblem) String() string {
+	if p.line == 0 {
+		return p.message
+	}
+	return fmt.Sprintf("line %d: %s", p.line, p.message)
+}
+
+// validateConfig checks the contents of a config file: its syntax, that sections and settings are known, and that values are well-formed.
+// Sections named after installed commands, given in commands, may hold any setting, as they are exported to the commands.
+// Problems are returned in the order of the lines they are found on.
+func validateConfig(data []byte, commands []string) []configProblem {
+	lines, problems := scanConfig(data)
+	if len(problems) > 0 {
+		return problems
+	}
+	file, err := ini.Load(data)
+	if err != nil {
+		return []configProblem{{message: strings.TrimSpace(err.Error())}}
+	}
+
+	isCommand := make(map[string]bool, len(commands))
+	for _, cmd := range commands {
+		isCommand[cmd] = true
+	}
+	for _, section := range file.Sections() {
+		name := section.Name()
+		profile, isProfile := config.ProfileName(name)
+		switch {
+		case name == ini.DefaultSection:
+			for _, key := range section.KeyStrings() {
+				problems = append(problems, configProblem{lines.key(name, key), fmt.Sprintf("setting %q is outside of any section", key)})
+			}
+		case isProfile:
+			for _, key := range section.Keys() {
+				path := strings.SplitN(key.Name(), ".", 2)
+				line := lines.key(name, key.Name())
+				if len(path) != 2 {
+					problems = append(problems, configProblem{line, fmt.Sprintf("invalid setting %q of profile %q, expected <section>.<key>", key.Name(), profile)})
+					continue
+				}
+				if path[0] == config.RegistriesSection || path[0] == config.AliasesSection {
+					problems = append(problems, configProblem{line, fmt.Sprintf("%s can't be set in profile %q, it is not read from profiles", key.Name(), profile)})
+					continue
+				}
+				problems = append(problems, checkSetting(isCommand, path[0], path[1], key.String(), line)...)
+			}
+		default:
+			if !isKnownSection(isCommand, name) {
+				problems = append(problems, configProblem{lines[name], unknownSectionMessage(commands, name)})
+				continue
+			}
+			for _, key := range section.Keys() {
+				problems = append(problems, checkSetting(isCommand, name, key.Name(), key.String(), lines.key(name, key.Name()))...)
+			}
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].line < problems[j].line
+	})
+	return problems
+}
+
+// scanConfig finds lines of sections and settings in the config file, and reports syntax errors and settings set more than once
+func scanConfig(data []byte) (configLines, []configProblem) {
+	lines := configLines{ini.DefaultSection: 0}
+	var problems []configProblem
+	section := ini.DefaultSection
+	for i, text := range strings.Split(string(data), "\n") {
+		number := i + 1
+		text = strings.TrimSpace(text)
+		switch {
+		case text == "" || text[0] == ';' || text[0] == '#':
+		case text[0] == '[':
+			end := strings.LastIndex(text, "]")
+			if end == -1 {
+				problems = append(problems, configProblem{number, fmt.Sprintf("unclosed section: %s", text)})
+				continue
+			}
+			section = strings.TrimSpace(text[1:end])
+			if _, ok := lines[section]; !ok {
+				lines[section] = number
+			}
+		default:
+			end := strings.IndexAny(text, "=:")
+			if end <= 0 {
+				// the line is not displayed, since it may be a secret
+				problems = append(problems, configProblem{number, "expected a setting in <key> = <value> format"})
+				continue
+			}
+			key := strings.TrimSpace(text[:end])
+			if previous, ok := lines[section+"\x00"+key]; ok {
+				problems = append(problems, configProblem{number, fmt.Sprintf("%s is already set on line %d", settingName(section, key), previous)})
+				continue
+			}
+			lines[section+"\x00"+key] = number
+		}
+	}
+	return lines, problems
+}
+
+func (l configLines) key(section, key string) int {
+	return l[section+"\x00"+key]
+}
+
+func isKnownSection(isCommand map[string]bool, section string) bool {
+	switch section {
+	case "cli", "install", config.RegistriesSection, config.AliasesSection:
+		return true
+	}
+	return isCommand[strings.ToLower(section)]
+}
+
+// checkSetting validates a setting of a section other than a profile
+func checkSetting(isCommand map[string]bool, section, key, value string, line int) []configProblem {
+	var settings map[string]valueValidator
+	name := settingName(section, key)
+	switch section {
+	case "cli":
+		settings = cliSettings
+	case "install":
+		settings = installSettings
+	case config.RegistriesSection:
+		path := strings.SplitN(key, ".", 2)
+		if len(path) != 2 {
+			return []configProblem{{line, fmt.Sprintf("invalid registry setting %q, expected registries.<name>.<setting>", key)}}
+		}
+		section, key, settings = config.RegistriesSection+"."+path[0], path[1], registrySettings
+	case config.AliasesSection:
+		if words, err := splitAliasCommand(value); err != nil || len(words) == 0 {
+			return []configProblem{{line, fmt.Sprintf("invalid command of alias %q", key)}}
+		}
+		return nil
+	default:
+		if !isKnownSection(isCommand, section) {
+			return []configProblem{{line, fmt.Sprintf("unknown setting %s, no installed command is named %q", name, section)}}
+		}
+		// settings of commands are only known to the commands
+		return nil
+	}
+
+	validate, ok := settings[key]
+	if !ok {
+		message := fmt.Sprintf("unknown setting %s", name)
+		if suggestion := closestName(key, settingNames(settings)); suggestion != "" {
+			message += fmt.Sprintf(", did you mean %s?", settingName(section, suggestion))
+		}
+		return []configProblem{{line, message}}
+	}
+	if validate == nil || value == config.KeyringValue {
+		return nil
+	}
+	if err := validate(value); err != nil {
+		return []configProblem{{line, fmt.Sprintf("invalid value of %s: %s", name, err)}}
+	}
+	return nil
+}
+
+func unknownSectionMessage(commands []string, section string) string {
+	message := fmt.Sprintf("unknown section [%s]", section)
+	known := append([]string{"cli", "install", config.RegistriesSection, config.AliasesSection}, commands...)
+	if suggestion := closestName(section, known); suggestion != "" {
+		return message + fmt.Sprintf(", did you mean [%s]?", suggestion)
+	}
+	return message + ", sections other than cli, install, registries, aliases and profiles must be named after an installed command"
+}
+
+// closestName returns the name differing from the given one by at most 2 characters, if there is one
+func closestName(name string, names []string) string {
+	closest, distance := "", 3
+	for _, candidate := range names {
+		if d := editDistance(strings.ToLower(name), candidate); d < distance {
+			closest, distance = candidate, d
+		}
+	}
+	return closest
+}
+
+func settingNames(settings map[string]valueValidator) []string {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func settingName(section, key string) string {
+	if section == ini.DefaultSection {
+		return key
+	}
+	return section + "." + key
+}
+
+func durationValidator(parse func(string) (time.Duration, error)) valueValidator {
+	return func(value string) error {
+		_, err := parse(value)
+		return err
+	}
+}
+
+func validateBool(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return fmt.Errorf("%q, expected true or false", value)
+	}
+	return nil
+}
+
+func validateInt(value string) error {
+	if _, err := strconv.Atoi(value); err != nil {
+		return fmt.Errorf("%q, expected an integer", value)
+	}
+	return nil
+}
+
+// validateHeader checks that a registry header is in <name>: <value> format, the value is not displayed as it is usually a secret
+func validateHeader(value string) error {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return fmt.Errorf("expected a header in <name>: <value> format")
+	}
+	return nil
+}
+
+func validateChannel(value string) error {
+	channel := strings.ToLower(strings.TrimSpace(value))
+	for _, c := range releaseChannels {
+		if c == channel {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown release channel %q, expected one of: %s", value, strings.Join(releaseChannels, ", "))
+}
+
+// validateStatistics accepts the version of statistics the user agreed to send, as set on first run, or a boolean
+func validateStatistics(value string) error {
+	if _, err := strconv.ParseBool(value); err == nil {
+		return nil
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return nil
+	}
+	return fmt.Errorf("%q, expected true or false", value)
+}
+
+// validateCheckTime accepts times of the last check in RFC 3339 format, along with never and ignore
+func validateCheckTime(value string) error {
+	if value == "never" || value == "ignore" {
+		return nil
+	}
+	if _, err := time.Parse(time.RFC3339, value); err != nil {
+		return fmt.Errorf("%q, expected never, ignore or a time such as 2021-09-10T10:00:00+02:00", value)
+	}
+	return nil
+}
+
+func validateLogFormat(value string) error {
+	switch strings.ToLower(value) {
+	case log.FormatText, log.FormatJSON:
+		return nil
+	}
+	return fmt.Errorf("%q, expected text or json", value)
+}
+
+func validateLogLevel(value string) error {
+	for _, level := range logLevels {
+		if strings.EqualFold(level, value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q, expected one of: %s", value, strings.Join(logLevels, ", "))
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := map[string]struct {
+		config   string
+		expected []string
+	}{
+		"valid config": {
+			config: "# settings\n[cli]\nconfig-version = 1.1\nenable-cli-statistics = 1.1\nlast-upgrade-check = 2021-09-10T10:00:00+02:00\nchannel = beta\n" +
+				"lock-timeout = 30s\nlog-level = debug\nretries = 5\ntelemetry = on\n\n[install]\ngithub-token = <keyring>\nclone-depth = 0\ngit-timeout = 5m\n\n" +
+				"[registries]\ninternal.url = https://packages.example.com\ninternal.priority = 10\ninternal.header = Authorization: Bearer abc\ninternal.timeout = 30s\n\n" +
+				"[aliases]\nhi = echo hello\n\n[echo]\nanything = goes\n\n[profile staging]\ncli.channel = stable\necho.greeting = bonjour\n",
+		},
+		"empty config": {},
+		"syntax errors": {
+			config:   "[cli\nchannel = beta\n[echo]\nsecret\n",
+			expected: []string{"line 1: unclosed section: [cli", "line 4: expected a setting in <key> = <value> format"},
+		},
+		"setting set twice": {
+			config:   "[cli]\nchannel = beta\nretries = 1\nchannel = stable\n",
+			expected: []string{"line 4: cli.channel is already set on line 2"},
+		},
+		"unknown sections and settings": {
+			config: "retries = 1\n[cli]\nlock-timout = 1m\nfoo = bar\n[ehco]\ngreeting = hi\n[unknown]\nkey = value\n" +
+				"[registries]\ninternal.uri = https://example.com\ninternal = x\n",
+			expected: []string{
+				`line 1: setting "retries" is outside of any section`,
+				"line 3: unknown setting cli.lock-timout, did you mean cli.lock-timeout?",
+				"line 4: unknown setting cli.foo",
+				"line 5: unknown section [ehco], did you mean [echo]?",
+				"line 7: unknown section [unknown], sections other than cli, install, registries, aliases and profiles must be named after an installed command",
+				"line 10: unknown setting registries.internal.uri, did you mean registries.internal.url?",
+				`line 11: invalid registry setting "internal", expected registries.<name>.<setting>`,
+			},
+		},
+		"invalid values": {
+			config: "[cli]\nchannel = alpha\nlock-timeout = forever\nbuild-cache = maybe\nlast-upgrade-check = yesterday\nsecret-storage = vault\n" +
+				"[registries]\ninternal.priority = high\ninternal.header = secret\n[aliases]\nbroken = \"echo\n[install]\nclone-depth = all\nbuild-timeout = forever\n",
+			expected: []string{
+				`line 2: invalid value of cli.channel: unknown release channel "alpha", expected one of: stable, beta, nightly`,
+				`line 3: invalid value of cli.lock-timeout: invalid lock timeout "forever", expected a duration such as 500ms, 2s or 1m`,
+				`line 4: invalid value of cli.build-cache: "maybe", expected true or false`,
+				`line 5: invalid value of cli.last-upgrade-check: "yesterday", expected never, ignore or a time such as 2021-09-10T10:00:00+02:00`,
+				`line 6: invalid value of cli.secret-storage: invalid secret storage "vault", expected keyring or file`,
+				`line 8: invalid value of registries.internal.priority: "high", expected an integer`,
+				"line 9: invalid value of registries.internal.header: expected a header in <name>: <value> format",
+				`line 11: invalid command of alias "broken"`,
+				`line 13: invalid value of install.clone-depth: invalid clone depth "all", expected a number of commits, or 0 for the full history`,
+				`line 14: invalid value of install.build-timeout: invalid timeout "forever", expected a duration such as 30s or 10m`,
+			},
+		},
+		"invalid profile settings": {
+			config: "[profile staging]\ncli.chanel = beta\nregistries.internal.url = https://example.com\nnosection = 1\nother.key = 1\n",
+			expected: []string{
+				"line 2: unknown setting cli.chanel, did you mean cli.channel?",
+				`line 3: registries.internal.url can't be set in profile "staging", it is not read from profiles`,
+				`line 4: invalid setting "nosection" of profile "staging", expected <section>.<key>`,
+				`line 5: unknown setting other.key, no installed command is named "other"`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			problems := make([]string, 0)
+			for _, problem := range validateConfig([]byte(test.config), []string{"echo"}) {
+				problems = append(problems, problem.String())
+			}
+			if test.expected == nil {
+				test.expected = []string{}
+			}
+			assert.Equal(t, test.expected, problems)
+		})
+	}
+}
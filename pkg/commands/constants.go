@@ -1,11 +1,6 @@
 package commands
 
-import (
-	"time"
-)
-
 const (
-	alreadyUptoDate  = "already up-to-date"
-	objectNotFound   = "object not found"
-	sleepTime24Hours = time.Hour * 24
+	alreadyUptoDate = "already up-to-date"
+	objectNotFound  = "object not found"
 )
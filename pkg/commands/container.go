@@ -0,0 +1,270 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// containerEngines are the programs packages can be run in containers with, in order of preference
+var containerEngines = []string{"docker", "podman"}
+
+// locations of the edgerc file and of the current directory inside containers
+const (
+	containerEdgerc  = "/root/.edgerc"
+	containerWorkDir = "/workdir"
+)
+
+// containerEnvironment lists variables passed to containers in addition to AKAMAI_* variables
+var containerEnvironment = []string{"NO_COLOR", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"}
+
+type containerModeKey struct{}
+
+// withContainerMode marks the context so that installed packages are run from their container image instead of being built
+func withContainerMode(ctx context.Context) context.Context {
+	return context.WithValue(ctx, containerModeKey{}, true)
+}
+
+func containerMode(ctx context.Context) bool {
+	enabled, _ := ctx.Value(containerModeKey{}).(bool)
+	return enabled
+}
+
+// containerEngine returns the path of the engine set with cli.container-engine or, if none is set, of the first engine found in PATH
+func containerEngine() (string, error) {
+	if engine := os.Getenv("AKAMAI_CLI_CONTAINER_ENGINE"); engine != "" {
+		path, err := lookPath(engine)
+		if err != nil {
+			return "", fmt.Errorf("container engine %s not found", engine)
+		}
+		return path, nil
+	}
+	for _, engine := range containerEngines {
+		if path, err := lookPath(engine); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no container engine found, install %s", strings.Join(containerEngines, " or "))
+}
+
+// containerPackage returns the directory of the package providing given command and the image it runs in,
+// if the package was installed in container mode
+func containerPackage(cmd string) (string, string, bool) {
+	dir, ok := findCommandPackageDir(cmd)
+	if !ok {
+		return "", "", false
+	}
+	meta, err := readInstallMetadata(dir)
+	if err != nil || meta == nil || meta.Image == "" {
+		return "", "", false
+	}
+	return dir, meta.Image, true
+}
+
+// pullPackageImage pulls the container image declared in cli.json of the package in given directory
+func pullPackageImage(ctx context.Context, dir string) (*subcommands, error) {
+	logger := log.FromContext(ctx)
+	pkg, err := readPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+	if pkg.Image == "" {
+		return nil, fmt.Errorf("the package does not declare a container image in cli.json")
+	}
+	engine, err := containerEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	spin := terminal.Get(ctx).Spinner()
+	spin.Start("Pulling image %s...", pkg.Image)
+	cmd := exec.CommandContext(ctx, engine, "pull", pkg.Image)
+	logger.Debugf("Executing command: %s", strings.Join(cmd.Args, " "))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		spin.Fail()
+		logger.WithField("output", strings.TrimSpace(string(output))).Warnf("Unable to pull image: %s", err)
+		if len(output) > 0 {
+			return nil, fmt.Errorf("unable to pull image %s: %s", pkg.Image, lastLine(output))
+		}
+		return nil, fmt.Errorf("unable to pull image %s: %s", pkg.Image, err)
+	}
+	spin.OK()
+	return &pkg, nil
+}
+
+// containerRun describes the execution of a package command in a container
+type containerRun struct {
+	engine  string
+	image   string
+	command string
+	args    []string
+	edgerc  string
+	workDir string
+	env     []string
+	tty     bool
+}
+
+// commandLine returns the command running the package command in a container, which is removed once the command exits.
+// The edgerc file is mounted read-only, and the current directory is mounted as the working directory,
+// so that files passed as arguments can be used.
+func (r containerRun) commandLine() []string {
+	cmd := []string{r.engine, "run", "--rm", "-i"}
+	if r.tty {
+		cmd = append(cmd, "-t")
+	}
+	if r.edgerc != "" {
+		cmd = append(cmd, "-v", r.edgerc+":"+containerEdgerc+":ro", "-e", "AKAMAI_EDGERC="+containerEdgerc)
+	}
+	if r.workDir != "" {
+		cmd = append(cmd, "-v", r.workDir+":"+containerWorkDir, "-w", containerWorkDir)
+	}
+	for _, name := range r.env {
+		// values are taken from the environment of the engine, so that they do not show up in the process list
+		cmd = append(cmd, "-e", name)
+	}
+	cmd = append(cmd, r.image, "akamai-"+r.command)
+	return append(cmd, r.args...)
+}
+
+// runContainerCommand executes the package command in the container image the package was installed with.
+// Arguments and the exit code of the command are passed as is.
+func runContainerCommand(c *cli.Context, dir, image, commandName string) error {
+	logger := log.FromContext(c.Context)
+	engine, err := containerEngine()
+	if err != nil {
+		logger.Error(err.Error())
+		return cli.Exit(color.RedString("Unable to run \"%s\": %s", commandName, err), 1)
+	}
+	cmdPackage, err := readPackage(dir)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to run \"%s\": %s", commandName, err), 1)
+	}
+	currentCmd := command{Name: commandName}
+	for _, cmd := range cmdPackage.Commands {
+		if strings.EqualFold(cmd.Name, commandName) || containsFold(cmd.Aliases, commandName) {
+			currentCmd = cmd
+		}
+	}
+
+	if err := os.Setenv("AKAMAI_CLI_COMMAND", commandName); err != nil {
+		return err
+	}
+	if err := os.Setenv("AKAMAI_CLI_COMMAND_VERSION", currentCmd.Version); err != nil {
+		return err
+	}
+	if err := exportPackageConfig(c.Context, cmdPackage); err != nil {
+		return err
+	}
+	if err := setPackageEnv(cmdPackage.Env); err != nil {
+		return err
+	}
+
+	run := containerRun{
+		engine:  engine,
+		image:   image,
+		command: strings.ToLower(currentCmd.Name),
+		args:    findAndAppendFlags(c, c.Args().Slice(), "section"),
+		env:     containerEnv(cmdPackage.Env),
+		tty:     terminal.Get(c.Context).IsTTY(),
+	}
+	if edgerc := edgercPath(c); edgerc != "" {
+		run.edgerc = edgerc
+		if c.String("edgerc") != "" && !containsString(run.args, "--edgerc") {
+			run.args = append(run.args, "--edgerc", containerEdgerc)
+		}
+	}
+	if wd, err := os.Getwd(); err == nil {
+		run.workDir = wd
+	}
+
+	stats.TrackEvent(c.Context, "exec", commandName, currentCmd.Version)
+	cmdLine := run.commandLine()
+	logger.Debugf("Running in container: %s", strings.Join(cmdLine, " "))
+	return passthruCommand(cmdLine, "")
+}
+
+func containsFold(s []string, item string) bool {
+	for _, v := range s {
+		if strings.EqualFold(v, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// edgercPath returns the location of the edgerc file set with --edgerc or AKAMAI_EDGERC, defaulting to ~/.edgerc.
+// An empty string is returned if the file does not exist.
+func edgercPath(c *cli.Context) string {
+	path := c.String("edgerc")
+	if path == "" {
+		path = os.Getenv("AKAMAI_EDGERC")
+	}
+	if path == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return ""
+		}
+		path = filepath.Join(home, ".edgerc")
+	}
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// containerEnv returns names of environment variables passed to the container: AKAMAI_* variables,
+// which include config settings, variables declared by the package and proxy settings
+func containerEnv(packageEnv map[string]string) []string {
+	var names []string
+	for _, variable := range os.Environ() {
+		name := strings.SplitN(variable, "=", 2)[0]
+		if strings.HasPrefix(name, "AKAMAI_") && name != "AKAMAI_EDGERC" {
+			names = append(names, name)
+		}
+	}
+	for name := range packageEnv {
+		if !strings.HasPrefix(name, "AKAMAI_") {
+			names = append(names, name)
+		}
+	}
+	for _, name := range containerEnvironment {
+		if _, ok := os.LookupEnv(name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestContainerRunCommandLine(t *testing.T) {
+	tests := map[string]struct {
+		run      containerRun
+		expected []string
+	}{
+		"minimal": {
+			run:      containerRun{engine: "docker", image: "akamai/purge:1.0", command: "purge"},
+			expected: []string{"docker", "run", "--rm", "-i", "akamai/purge:1.0", "akamai-purge"},
+		},
+		"edgerc, working directory and environment": {
+			run: containerRun{
+				engine:  "podman",
+				image:   "akamai/purge:1.0",
+				command: "purge",
+				args:    []string{"invalidate", "--section", "ccu", "https://example.com"},
+				edgerc:  "/home/user/.edgerc",
+				workDir: "/home/user/project",
+				env:     []string{"AKAMAI_CLI_COMMAND", "NO_COLOR"},
+				tty:     true,
+			},
+			expected: []string{"podman", "run", "--rm", "-i", "-t",
+				"-v", "/home/user/.edgerc:/root/.edgerc:ro", "-e", "AKAMAI_EDGERC=/root/.edgerc",
+				"-v", "/home/user/project:/workdir", "-w", "/workdir",
+				"-e", "AKAMAI_CLI_COMMAND", "-e", "NO_COLOR",
+				"akamai/purge:1.0", "akamai-purge", "invalidate", "--section", "ccu", "https://example.com"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.run.commandLine())
+		})
+	}
+}
+
+func TestContainerEngine(t *testing.T) {
+	tests := map[string]struct {
+		engine    string
+		found     map[string]string
+		expected  string
+		withError string
+	}{
+		"docker preferred": {
+			found:    map[string]string{"docker": "/usr/bin/docker", "podman": "/usr/bin/podman"},
+			expected: "/usr/bin/docker",
+		},
+		"podman": {
+			found:    map[string]string{"podman": "/usr/bin/podman"},
+			expected: "/usr/bin/podman",
+		},
+		"engine set in config": {
+			engine:   "podman",
+			found:    map[string]string{"docker": "/usr/bin/docker", "podman": "/usr/bin/podman"},
+			expected: "/usr/bin/podman",
+		},
+		"engine set in config not found": {
+			engine:    "nerdctl",
+			found:     map[string]string{"docker": "/usr/bin/docker"},
+			withError: "container engine nerdctl not found",
+		},
+		"no engine": {
+			found:     map[string]string{},
+			withError: "no container engine found, install docker or podman",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer mockLookPath(test.found)()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_CONTAINER_ENGINE", test.engine))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_CONTAINER_ENGINE"))
+			}()
+
+			engine, err := containerEngine()
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, engine)
+		})
+	}
+}
+
+func TestPullPackageImage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the container engine in tests is a shell script")
+	}
+	tests := map[string]struct {
+		cliJSON   string
+		engine    string
+		init      func(*terminal.Mock)
+		expected  string
+		withError string
+	}{
+		"image pulled": {
+			cliJSON: `{"commands": [{"name": "purge"}], "image": "akamai/purge:1.0"}`,
+			engine:  "#!/bin/sh\necho \"$@\" > \"$(dirname \"$0\")/engine.out\"\n",
+			init: func(m *terminal.Mock) {
+				m.On("Spinner").Return(m).Once()
+				m.On("Start", "Pulling image %s...", []interface{}{"akamai/purge:1.0"}).Return().Once()
+				m.On("OK").Return().Once()
+			},
+			expected: "pull akamai/purge:1.0\n",
+		},
+		"pull fails": {
+			cliJSON: `{"commands": [{"name": "purge"}], "image": "akamai/purge:1.0"}`,
+			engine:  "#!/bin/sh\necho Trying to pull; echo manifest unknown >&2; exit 1\n",
+			init: func(m *terminal.Mock) {
+				m.On("Spinner").Return(m).Once()
+				m.On("Start", "Pulling image %s...", []interface{}{"akamai/purge:1.0"}).Return().Once()
+				m.On("Fail").Return().Once()
+			},
+			withError: "unable to pull image akamai/purge:1.0: manifest unknown",
+		},
+		"no image declared": {
+			cliJSON:   `{"commands": [{"name": "purge"}]}`,
+			init:      func(m *terminal.Mock) {},
+			withError: "the package does not declare a container image in cli.json",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-container")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(test.cliJSON), 0644))
+			engine := filepath.Join(dir, "engine")
+			require.NoError(t, ioutil.WriteFile(engine, []byte(test.engine), 0755))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_CONTAINER_ENGINE", engine))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_CONTAINER_ENGINE"))
+			}()
+			m := &terminal.Mock{}
+			test.init(m)
+
+			pkg, err := pullPackageImage(terminal.Context(context.Background(), m), dir)
+			m.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "akamai/purge:1.0", pkg.Image)
+			output, err := ioutil.ReadFile(filepath.Join(dir, "engine.out"))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(output))
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver"
+	"github.com/fatih/color"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// packageDependency is another CLI package required by a package, as declared in cli.json
+type packageDependency = packages.Dependency
+
+// dependencyResolver installs packages required by the package being installed, before the package itself is built.
+// Packages currently being installed are tracked to detect dependency cycles.
+type dependencyResolver struct {
+	gitRepo     git.Repository
+	langManager packages.LangManager
+	forceBinary bool
+	claims      *packageClaims
+	path        []string
+	installed   []subcommands
+}
+
+// packageClaims tracks package directories claimed by concurrent workers, so that each package is installed only once
+type packageClaims struct {
+	mu   sync.Mutex
+	dirs map[string]bool
+}
+
+func newPackageClaims() *packageClaims {
+	return &packageClaims{dirs: make(map[string]bool)}
+}
+
+// claim returns true if the directory was not claimed before
+func (p *packageClaims) claim(dir string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.dirs[dir] {
+		return false
+	}
+	p.dirs[dir] = true
+	return true
+}
+
+func newDependencyResolver(gitRepo git.Repository, langManager packages.LangManager, forceBinary bool) *dependencyResolver {
+	return &dependencyResolver{
+		gitRepo:     gitRepo,
+		langManager: langManager,
+		forceBinary: forceBinary,
+	}
+}
+
+// resolve installs missing dependencies of the package in given directory, recursively.
+// Dependencies which are already installed are only checked against the required version; a mismatch results in a warning.
+func (r *dependencyResolver) resolve(ctx context.Context, dir string) error {
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+
+	pkg, err := readPackage(dir)
+	if err != nil {
+		// reported when installing the package itself
+		return nil
+	}
+	if len(pkg.Dependencies) == 0 {
+		return nil
+	}
+
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(dir)
+	r.path = append(r.path, name)
+	defer func() {
+		r.path = r.path[:len(r.path)-1]
+	}()
+
+	for _, dep := range pkg.Dependencies {
+		repo := tools.Githubize(dep.Name)
+		depName := packageDirName(repo)
+		for i, p := range r.path {
+			if p == depName {
+				cycle := strings.Join(append(r.path[i:], depName), " -> ")
+				logger.Errorf("Dependency cycle detected: %s", cycle)
+				return commandFailure(errBuild, "Dependency cycle detected: %s", cycle)
+			}
+		}
+
+		depDir := filepath.Join(srcPath, depName)
+		if r.claims != nil && !r.claims.claim(depDir) {
+			logger.Debugf("Dependency %s of %s is installed by another worker", depName, name)
+			continue
+		}
+		if _, err := os.Stat(depDir); err != nil {
+			logger.Debugf("Installing dependency %s of %s", depName, name)
+			term.Printf("Installing %s, required by %s\n", color.CyanString(depName), color.CyanString(name))
+			subCmd, err := installPackage(ctx, r.gitRepo, r.langManager, repo, "", r.forceBinary, r)
+			if err != nil {
+				return err
+			}
+			r.installed = append(r.installed, *subCmd)
+		}
+
+		if warnMsg := checkDependencyVersion(name, dep, depDir); warnMsg != "" {
+			logger.Warn(warnMsg)
+			term.Writeln(color.YellowString(warnMsg))
+		}
+	}
+
+	return nil
+}
+
+// takeInstalled returns packages installed as dependencies since the last call
+func (r *dependencyResolver) takeInstalled() []subcommands {
+	installed := r.installed
+	r.installed = nil
+	return installed
+}
+
+// checkDependencyVersion verifies that the installed dependency satisfies the version constraint.
+// A dependency is satisfied if any of its commands matches the constraint. A warning message is returned otherwise.
+func checkDependencyVersion(name string, dep packageDependency, depDir string) string {
+	if dep.Version == "" {
+		return ""
+	}
+	constraint, err := semver.NewConstraint(dep.Version)
+	if err != nil {
+		return fmt.Sprintf("Package %s declares invalid version constraint for %s: %s", name, dep.Name, dep.Version)
+	}
+
+	depPkg, err := readPackage(depDir)
+	if err != nil {
+		return fmt.Sprintf("Unable to verify version of %s required by %s: %s", dep.Name, name, err.Error())
+	}
+
+	var versions []string
+	for _, cmd := range depPkg.Commands {
+		if cmd.Version == "" {
+			continue
+		}
+		versions = append(versions, cmd.Version)
+		if v, err := semver.NewVersion(cmd.Version); err == nil && constraint.Check(v) {
+			return ""
+		}
+	}
+
+	installed := "unknown"
+	if len(versions) > 0 {
+		installed = strings.Join(versions, ", ")
+	}
+	return fmt.Sprintf("Package %s requires %s %s, but installed version is %s", name, dep.Name, dep.Version, installed)
+}
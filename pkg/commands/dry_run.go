@@ -0,0 +1,327 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// isDryRun returns true if --dry-run is set, either as a global flag or as a flag of the command
+func isDryRun(c *cli.Context) bool {
+	for _, ctx := range c.Lineage() {
+		if ctx.Bool("dry-run") {
+			return true
+		}
+	}
+	return false
+}
+
+// planInstall prints what installing packages given as arguments would do, without making any changes.
+// Remote packages are not cloned, so their build steps are known only from a local package cli.json.
+func planInstall(c *cli.Context) error {
+	term := terminal.Get(c.Context)
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return err
+	}
+
+	term.Writeln(color.YellowString("Dry run, no changes will be made:"))
+	for _, arg := range c.Args().Slice() {
+		if source, ok := parsePackageSource(arg); ok {
+			if !source.isLocal() {
+				planSourceInstall(term, srcPath, source)
+				continue
+			}
+			if err := planLocalInstall(term, srcPath, source.Location, installStrategy(c.Context)); err != nil {
+				return err
+			}
+			continue
+		}
+		repo, ref := splitPackageRef(arg)
+		planRemoteInstall(term, srcPath, resolvePackageRepo(c.Context, repo), ref)
+	}
+	planLockFileUpdate(term)
+	return nil
+}
+
+// planInstallFromLock prints which packages recorded in the lock file would be installed
+func planInstallFromLock(c *cli.Context) error {
+	term := terminal.Get(c.Context)
+	if c.Args().Len() > 1 {
+		return commandFailure(errUsage, "Only one lock file can be specified")
+	}
+	path := c.Args().First()
+	if path == "" {
+		var err error
+		if path, err = defaultLockFilePath(); err != nil {
+			return commandFailure(errConfig, "Unable to determine CLI home directory")
+		}
+	}
+	lock, err := readLockFile(path)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to read lock file: %s", err.Error()), 1)
+	}
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return err
+	}
+
+	term.Writeln(color.YellowString("Dry run, no changes will be made:"))
+	for _, pkg := range lock.Packages {
+		if err := pkg.validate(); err != nil {
+			return commandFailure(errUsage, "Invalid lock file: %s", err.Error())
+		}
+		source, fromSource := pkg.source()
+		packageDir := filepath.Join(srcPath, pkg.Name)
+		if !fromSource {
+			packageDir = filepath.Join(srcPath, packageDirName(pkg.Repo))
+		}
+		if _, err := os.Stat(packageDir); err == nil {
+			meta, err := readInstallMetadata(packageDir)
+			if err != nil || !pkg.isInstalled(meta) {
+				term.Printf("Would fail on %s: already installed in a different version\n", pkg.Name)
+			} else {
+				term.Printf("Would skip %s: already installed at the locked commit\n", pkg.Name)
+			}
+			continue
+		}
+		switch {
+		case fromSource && source.isLocal():
+			if err := planLocalInstall(term, srcPath, source.Location, installStrategy(c.Context)); err != nil {
+				return err
+			}
+		case fromSource:
+			planSourceInstall(term, srcPath, source)
+		default:
+			planRemoteInstall(term, srcPath, pkg.Repo, pkg.Commit)
+		}
+	}
+	planLockFileUpdate(term)
+	return nil
+}
+
+func planRemoteInstall(term terminal.Terminal, srcPath, repo, ref string) {
+	packageDir := filepath.Join(srcPath, packageDirName(repo))
+	if _, err := os.Stat(packageDir); err == nil {
+		term.Printf("Would skip %s: package directory %s already exists\n", repo, packageDir)
+		return
+	}
+	if ref != "" {
+		term.Printf("Would clone %s at %s into %s\n", repo, ref, packageDir)
+	} else {
+		term.Printf("Would clone %s into %s\n", repo, packageDir)
+	}
+	term.Printf("Would install dependencies and build the package as declared in its cli.json\n")
+}
+
+// planSourceInstall prints what installing a package from a remote archive or a Mercurial repository would do
+func planSourceInstall(term terminal.Terminal, srcPath string, source packageSource) {
+	if source.Type == sourceMercurial {
+		planRemoteInstall(term, srcPath, mercurialPrefix+source.Location, source.Ref)
+		return
+	}
+	term.Printf("Would download package archive %s and extract it into %s\n", source, srcPath)
+	term.Printf("Would install dependencies and build the package as declared in its cli.json\n")
+}
+
+func planLocalInstall(term terminal.Terminal, srcPath, source, strategy string) error {
+	source, err := filepath.Abs(source)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return commandFailure(errNotFound, "Unable to read package: %s", err.Error())
+	}
+	if !info.IsDir() {
+		term.Printf("Would extract package archive %s into %s\n", source, srcPath)
+		term.Printf("Would install dependencies and build the package as declared in its cli.json\n")
+		return nil
+	}
+
+	if err := validatePackage(source); err != nil {
+		return commandFailure(errUsage, "Invalid package: %s", err.Error())
+	}
+	packageDir := filepath.Join(srcPath, filepath.Base(source))
+	if _, err := os.Stat(packageDir); err == nil {
+		term.Printf("Would skip %s: package directory %s already exists\n", source, packageDir)
+		return nil
+	}
+	pkg, err := readPackage(source)
+	if err != nil {
+		return commandFailure(errUsage, "Invalid package: %s", err.Error())
+	}
+	term.Printf("Would copy %s into %s\n", source, packageDir)
+	planPackageBuild(term, pkg, strategy)
+	return nil
+}
+
+// planUpdate prints which packages would be updated, based on a fetch of their remotes which does not modify installed files
+func planUpdate(c *cli.Context, gitRepo git.Repository) error {
+	term := terminal.Get(c.Context)
+	checks := checkInstalledPackages(c.Context, gitRepo)
+	if c.Args().Present() {
+		var missing string
+		if checks, missing = selectPackageChecks(checks, c.Args().Slice()); missing != "" {
+			return commandFailure(errNotFound, "Command \"%s\" not found. Try \"%s help\".\n", missing, tools.Self())
+		}
+	}
+
+	term.Writeln(color.YellowString("Dry run, no changes will be made:"))
+	updated := false
+	for _, check := range checks {
+		meta, _ := readInstallMetadata(check.Dir)
+		source, fromSource := installedSource(meta)
+		switch {
+		case meta != nil && meta.Held && !c.Args().Present():
+			term.Printf("Would skip %s: pinned\n", check.Name)
+			continue
+		case fromSource:
+			term.Printf("Would fetch %s again from %s and update it in %s if it changed\n", check.Name, source, check.Dir)
+		case meta != nil && meta.Source == "" && meta.isPinned() && c.Bool("latest"):
+			term.Printf("Would update %s from %s %s to the latest commit of its default branch in %s\n", check.Name, meta.RefType, meta.Ref, check.Dir)
+		case check.Status == packageStatusOutdated:
+			term.Printf("Would update %s from %s to %s in %s\n", check.Name, check.Installed, check.Available, check.Dir)
+		case check.Status == packageStatusCurrent:
+			term.Printf("Would skip %s: already up-to-date\n", check.Name)
+			continue
+		default:
+			term.Printf("Would skip %s: %s\n", check.Name, check.Reason)
+			continue
+		}
+
+		updated = true
+		planPackageBuild(term, check.Package, installStrategy(c.Context))
+		if previousDir, err := previousPackageDir(check.Dir); err == nil {
+			term.Printf("Would keep the current version in %s\n", previousDir)
+		}
+	}
+	if updated {
+		planLockFileUpdate(term)
+	}
+	return nil
+}
+
+// planUninstall prints which directories would be removed when uninstalling packages containing given commands
+func planUninstall(c *cli.Context, langManager packages.LangManager) error {
+	term := terminal.Get(c.Context)
+	term.Writeln(color.YellowString("Dry run, no changes will be made:"))
+	var repoDirs []string
+	if c.Bool("all") {
+		repoDirs = getPackagePaths()
+	}
+	for _, cmd := range c.Args().Slice() {
+		exec, err := findExec(c.Context, langManager, cmd)
+		if err != nil {
+			return commandFailure(errNotFound, "command \"%s\" not found. Try \"%s help\"", cmd, tools.Self())
+		}
+		repoDir := findExecPackageDir(exec)
+		if repoDir == "" {
+			return commandFailure(errNotFound, "unable to uninstall, was it installed using "+color.CyanString("\"akamai install\"")+"?")
+		}
+		repoDirs = append(repoDirs, repoDir)
+	}
+	for _, repoDir := range repoDirs {
+		term.Printf("Would remove %s\n", repoDir)
+		if previousDir, err := previousPackageDir(repoDir); err == nil {
+			if _, err := os.Stat(previousDir); err == nil {
+				term.Printf("Would remove %s\n", previousDir)
+			}
+		}
+		if purgeEnabled(c.Context) {
+			planPurge(c.Context, term, findPackageLeftovers(repoDir))
+		}
+	}
+	if c.Bool("all") && purgeEnabled(c.Context) {
+		if dir, err := buildCacheDir(); err == nil {
+			term.Printf("Would clean the build cache in %s\n", dir)
+		}
+	}
+	planLockFileUpdate(term)
+	return nil
+}
+
+// planPurge prints config entries and the container image which would be removed along with a package
+func planPurge(ctx context.Context, term terminal.Terminal, leftovers packageLeftovers) {
+	sections, keys := packageConfigEntries(config.Get(ctx), leftovers.commands)
+	for _, section := range sections {
+		term.Printf("Would remove [%s] section of the config file\n", section)
+	}
+	for _, key := range keys {
+		term.Printf("Would remove %s from [%s] section of the config file\n", key.key, key.section)
+	}
+	if leftovers.image != "" {
+		term.Printf("Would remove image %s\n", leftovers.image)
+	}
+}
+
+// planPackageBuild prints build requirements, dependencies and binaries of the package, according to its cli.json and the install strategy
+func planPackageBuild(term terminal.Terminal, pkg subcommands, strategy string) {
+	requirements := []struct{ lang, version string }{
+		{"go", pkg.Requirements.Go},
+		{"php", pkg.Requirements.Php},
+		{"node", pkg.Requirements.Node},
+		{"ruby", pkg.Requirements.Ruby},
+		{"python", pkg.Requirements.Python},
+	}
+	for _, req := range requirements {
+		if req.version != "" && strategy != installStrategyBinary {
+			term.Printf("Would build the package using %s %s\n", req.lang, req.version)
+		}
+	}
+	for _, dep := range pkg.Dependencies {
+		term.Printf("Would install dependency %s %s, if not installed\n", dep.Name, dep.Version)
+	}
+	if strategy == installStrategySource {
+		return
+	}
+	for _, cmd := range executableCommands(pkg) {
+		if cmd.Bin == "" {
+			if strategy == installStrategyBinary {
+				term.Printf("Would fail: no binary published for %s\n", cmd.Name)
+			}
+			continue
+		}
+		candidates, err := binCandidates(cmd)
+		if err != nil || len(candidates) == 0 {
+			continue
+		}
+		if strategy == installStrategyBinary {
+			term.Printf("Would download %s %s from %s\n", cmd.Name, cmd.Version, candidates[0].url)
+		} else {
+			term.Printf("Would download %s %s from %s, if the package cannot be built\n", cmd.Name, cmd.Version, candidates[0].url)
+		}
+		for _, candidate := range candidates[1:] {
+			term.Printf("  or from %s, if not found\n", candidate.url)
+		}
+	}
+}
+
+func planLockFileUpdate(term terminal.Terminal) {
+	if path, err := defaultLockFilePath(); err == nil {
+		term.Printf("Would update %s\n", path)
+	}
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func TestDryRun(t *testing.T) {
+	localPackage, err := filepath.Abs("./testdata/repo")
+	require.NoError(t, err)
+	dryRunHeader := []interface{}{color.YellowString("Dry run, no changes will be made:")}
+	lockFile := []interface{}{"testdata/.akamai-cli/" + lockFileName}
+
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		withError string
+	}{
+		"install remote packages": {
+			args: []string{"--dry-run", "install", "test-cmd@v1.0.0", "installed"},
+			init: func(m *mocked) {
+				m.term.On("Writeln", dryRunHeader).Return(0, nil).Once()
+				m.term.On("Printf", "Would clone %s at %s into %s\n",
+					[]interface{}{"https://github.com/akamai/cli-test-cmd.git", "v1.0.0", "testdata/.akamai-cli/src/cli-test-cmd"}).Return().Once()
+				m.term.On("Printf", "Would install dependencies and build the package as declared in its cli.json\n", []interface{}(nil)).Return().Once()
+				m.term.On("Printf", "Would skip %s: package directory %s already exists\n",
+					[]interface{}{"https://github.com/akamai/cli-installed.git", "testdata/.akamai-cli/src/cli-installed"}).Return().Once()
+				m.term.On("Printf", "Would update %s\n", lockFile).Return().Once()
+			},
+		},
+		"install local package": {
+			args: []string{"install", "--dry-run", localPackage},
+			init: func(m *mocked) {
+				m.term.On("Writeln", dryRunHeader).Return(0, nil).Once()
+				m.term.On("Printf", "Would copy %s into %s\n", []interface{}{localPackage, "testdata/.akamai-cli/src/repo"}).Return().Once()
+				m.term.On("Printf", "Would build the package using %s %s\n", []interface{}{"go", "1.14.0"}).Return().Once()
+				m.term.On("Printf", "Would download %s %s from %s, if the package cannot be built\n",
+					[]interface{}{"app-1-cmd-1", "1.0.0", "${REPOSITORY_URL}/akamai/cli-test-command/releases/download/1.0.0/akamai-app-1-cmd-1"}).Return().Once()
+				m.term.On("Printf", "Would update %s\n", lockFile).Return().Once()
+			},
+		},
+		"update packages": {
+			args: []string{"--dry-run", "update", "echo", "installed"},
+			init: func(m *mocked) {
+				mockPackageChecks(m)
+				m.term.On("Writeln", dryRunHeader).Return(0, nil).Once()
+				m.term.On("Printf", "Would update %s from %s to %s in %s\n",
+					[]interface{}{"echo", "0100000", "0200000", "testdata/.akamai-cli/src/cli-echo"}).Return().Once()
+				m.term.On("Printf", "Would build the package using %s %s\n", []interface{}{"go", "1.14.0"}).Return().Once()
+				m.term.On("Printf", "Would keep the current version in %s\n", []interface{}{"testdata/.akamai-cli/" + rollbackDirName + "/cli-echo"}).Return().Once()
+				m.term.On("Printf", "Would skip %s: already up-to-date\n", []interface{}{"installed"}).Return().Once()
+				m.term.On("Printf", "Would update %s\n", lockFile).Return().Once()
+			},
+		},
+		"update unknown command": {
+			args: []string{"update", "--dry-run", "abc"},
+			init: func(m *mocked) {
+				mockPackageChecks(m)
+			},
+			withError: `Command "abc" not found`,
+		},
+		"uninstall package": {
+			args: []string{"uninstall", "--dry-run", "echo"},
+			init: func(m *mocked) {
+				m.term.On("Writeln", dryRunHeader).Return(0, nil).Once()
+				m.term.On("Printf", "Would remove %s\n", []interface{}{"testdata/.akamai-cli/src/cli-echo"}).Return().Once()
+				m.term.On("Printf", "Would update %s\n", lockFile).Return().Once()
+			},
+		},
+		"uninstall unknown command": {
+			args: []string{"--dry-run", "uninstall", "abc"},
+			init: func(m *mocked) {
+				m.term.On("Writeln", dryRunHeader).Return(0, nil).Once()
+			},
+			withError: fmt.Sprintf(`command "abc" not found. Try "%s help"`, tools.Self()),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
+			dryRunFlag := &cli.BoolFlag{Name: "dry-run"}
+			app, ctx := setupTestApp(&cli.Command{
+				Name:   "install",
+				Flags:  []cli.Flag{dryRunFlag},
+				Action: cmdInstall(m.gitRepo, m.langManager),
+			}, m)
+			app.Commands = append(app.Commands, &cli.Command{
+				Name:   "update",
+				Flags:  []cli.Flag{dryRunFlag},
+				Action: cmdUpdate(m.gitRepo, m.langManager),
+			}, &cli.Command{
+				Name:   "uninstall",
+				Flags:  []cli.Flag{dryRunFlag},
+				Action: cmdUninstall(m.langManager),
+			})
+			app.Flags = append(app.Flags, dryRunFlag)
+			args := append(os.Args[0:1], test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			_, statErr := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd")
+			assert.True(t, os.IsNotExist(statErr), "package should not be installed")
+			_, statErr = os.Stat("./testdata/.akamai-cli/" + lockFileName)
+			assert.True(t, os.IsNotExist(statErr), "lock file should not be written")
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
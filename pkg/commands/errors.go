@@ -0,0 +1,116 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"errors"
+
+	"github.com/fatih/color"
+)
+
+// exit codes of failed commands, documented in README.md.
+// Codes 2 to 7 are used by Akamai CLI before a command is executed, and 130 by canceled commands.
+const (
+	exitFailure          = 1
+	exitUsage            = 10
+	exitNotFound         = 11
+	exitAlreadyInstalled = 12
+	exitNetwork          = 13
+	exitBuild            = 14
+	exitConfig           = 15
+	exitLocked           = 16
+)
+
+// kinds of command errors, each of which results in its own exit code
+var (
+	// errUsage means invalid arguments or flags were given to the command
+	errUsage = errors.New("invalid usage")
+	// errNotFound means the command, package or alias to act on does not exist
+	errNotFound = errors.New("not found")
+	// errAlreadyInstalled means the package to install is already installed
+	errAlreadyInstalled = errors.New("already installed")
+	// errNetwork means a repository, registry or download location could not be reached
+	errNetwork = errors.New("network failure")
+	// errBuild means the package could not be built, or one of its hooks failed
+	errBuild = errors.New("build failure")
+	// errConfig means the configuration could not be read or saved, or is invalid
+	errConfig = errors.New("configuration error")
+	// errLocked means another instance of Akamai CLI is modifying installed packages
+	errLocked = errors.New("locked")
+)
+
+var exitCodes = map[error]int{
+	errUsage:            exitUsage,
+	errNotFound:         exitNotFound,
+	errAlreadyInstalled: exitAlreadyInstalled,
+	errNetwork:          exitNetwork,
+	errBuild:            exitBuild,
+	errConfig:           exitConfig,
+	errLocked:           exitLocked,
+}
+
+// commandError is an error returned by a command action, which makes Akamai CLI exit with the exit code of its kind
+type commandError struct {
+	kind    error
+	message string
+}
+
+func (e *commandError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the kind of the error, so that it can be checked with errors.Is
+func (e *commandError) Unwrap() error {
+	return e.kind
+}
+
+// ExitCode implements cli.ExitCoder
+func (e *commandError) ExitCode() int {
+	if code, ok := exitCodes[e.kind]; ok {
+		return code
+	}
+	return exitFailure
+}
+
+// commandFailure returns an error of given kind with a message displayed in red
+func commandFailure(kind error, format string, args ...interface{}) error {
+	if len(args) == 0 {
+		return &commandError{kind: kind, message: color.RedString("%s", format)}
+	}
+	return &commandError{kind: kind, message: color.RedString(format, args...)}
+}
+
+// commandWarning returns an error of given kind with a message displayed in yellow, for operations which did not fail
+// but could not be done, such as installing a package which is already installed
+func commandWarning(kind error, format string, args ...interface{}) error {
+	if len(args) == 0 {
+		return &commandError{kind: kind, message: color.YellowString("%s", format)}
+	}
+	return &commandError{kind: kind, message: color.YellowString(format, args...)}
+}
+
+// kindOf returns the kind of a command error, nil for other errors
+func kindOf(err error) error {
+	var cmdErr *commandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.kind
+	}
+	return nil
+}
+
+// isWarning returns true if err only reports that a command could not be done, without it failing
+func isWarning(err error) bool {
+	return errors.Is(err, errAlreadyInstalled)
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandErrorExitCode(t *testing.T) {
+	tests := map[string]struct {
+		err          error
+		expectedCode int
+		isWarning    bool
+	}{
+		"usage":             {err: commandFailure(errUsage, "invalid"), expectedCode: exitUsage},
+		"not found":         {err: commandFailure(errNotFound, "command %q not found", "echo"), expectedCode: exitNotFound},
+		"already installed": {err: commandWarning(errAlreadyInstalled, "already installed"), expectedCode: exitAlreadyInstalled, isWarning: true},
+		"network":           {err: commandFailure(errNetwork, "unreachable"), expectedCode: exitNetwork},
+		"build":             {err: commandFailure(errBuild, "build failed"), expectedCode: exitBuild},
+		"config":            {err: commandFailure(errConfig, "invalid config"), expectedCode: exitConfig},
+		"unknown kind":      {err: commandFailure(nil, "failed"), expectedCode: exitFailure},
+		"wrapped warning":   {err: fmt.Errorf("install: %w", commandWarning(errAlreadyInstalled, "already installed")), expectedCode: exitAlreadyInstalled, isWarning: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cmdErr *commandError
+			require.True(t, errors.As(test.err, &cmdErr))
+			assert.Equal(t, test.expectedCode, cmdErr.ExitCode())
+			assert.Equal(t, test.isWarning, isWarning(test.err))
+		})
+	}
+}
+
+func TestFailureKind(t *testing.T) {
+	tests := map[string]struct {
+		errs     []error
+		expected error
+	}{
+		"same kind": {
+			errs:     []error{commandFailure(errNetwork, "a"), nil, commandFailure(errNetwork, "b")},
+			expected: errNetwork,
+		},
+		"different kinds": {
+			errs: []error{commandFailure(errNetwork, "a"), commandFailure(errBuild, "b")},
+		},
+		"warnings are ignored": {
+			errs:     []error{commandWarning(errAlreadyInstalled, "a"), commandFailure(errBuild, "b")},
+			expected: errBuild,
+		},
+		"untyped error": {
+			errs: []error{commandFailure(errBuild, "a"), fmt.Errorf("b")},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var tasks []*packageTask
+			for _, err := range test.errs {
+				task := newPackageTask("test")
+				task.err = err
+				tasks = append(tasks, task)
+			}
+			assert.Equal(t, test.expected, failureKind(tasks))
+		})
+	}
+}
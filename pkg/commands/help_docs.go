@@ -0,0 +1,153 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// packageDocsDir is the directory of a package holding its documentation, as Markdown files displayed in name order
+const packageDocsDir = "docs"
+
+var (
+	markdownCode = regexp.MustCompile("`([^`]+)`")
+	markdownBold = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownLink = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	markdownList = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+)
+
+// readPackageDocs returns the documentation of an installed command: the docs field of the command in cli.json,
+// followed by the Markdown files of the package docs directory
+func readPackageDocs(dir string, cmd command) (string, error) {
+	docs := make([]string, 0)
+	if strings.TrimSpace(cmd.Docs) != "" {
+		docs = append(docs, strings.TrimSpace(cmd.Docs))
+	}
+	files, err := filepath.Glob(filepath.Join(dir, packageDocsDir, "*.md"))
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		if doc := strings.TrimSpace(string(content)); doc != "" {
+			docs = append(docs, doc)
+		}
+	}
+	return strings.Join(docs, "\n\n"), nil
+}
+
+// renderMarkdown formats Markdown for the terminal, indented like the sections of command help.
+// Headings and bold text are emphasized, code is colored and links are followed by their URL.
+func renderMarkdown(doc string) string {
+	var b strings.Builder
+	inCode, blank := false, true
+	for _, line := range strings.Split(strings.ReplaceAll(doc, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			b.WriteString("       " + color.CyanString(strings.TrimRight(line, " \t")) + "\n")
+			blank = false
+			continue
+		}
+		if trimmed == "" {
+			// consecutive blank lines are collapsed
+			if !blank {
+				b.WriteString("\n")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			b.WriteString("   " + color.New(color.Bold).Sprint(renderInlineMarkdown(heading)) + "\n")
+		case markdownList.MatchString(line):
+			item := markdownList.FindStringSubmatch(line)
+			b.WriteString("   " + item[1] + "- " + renderInlineMarkdown(item[2]) + "\n")
+		default:
+			b.WriteString("   " + renderInlineMarkdown(trimmed) + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func renderInlineMarkdown(text string) string {
+	text = markdownLink.ReplaceAllString(text, "$1 ($2)")
+	text = markdownCode.ReplaceAllStringFunc(text, func(code string) string {
+		return color.CyanString(strings.Trim(code, "`"))
+	})
+	return markdownBold.ReplaceAllStringFunc(text, func(bold string) string {
+		return color.New(color.Bold).Sprint(bold[2 : len(bold)-2])
+	})
+}
+
+// writeHelp writes help to the app output, through the pager when the output is a terminal and --no-pager is not set
+func writeHelp(c *cli.Context, help string) error {
+	if c.Bool("no-pager") || c.App.Writer != os.Stdout || !terminal.Get(c.Context).IsTTY() {
+		_, err := io.WriteString(c.App.Writer, help)
+		return err
+	}
+	logger := log.FromContext(c.Context)
+	pager := helpPager()
+	path, err := lookPath(pager[0])
+	if err != nil {
+		logger.Debugf("Pager %s not found, writing help directly: %s", pager[0], err.Error())
+		_, err := io.WriteString(c.App.Writer, help)
+		return err
+	}
+	cmd := exec.Command(path, pager[1:]...)
+	cmd.Stdin = strings.NewReader(help)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		logger.Debugf("Unable to start pager %s, writing help directly: %s", pager[0], err.Error())
+		_, err := io.WriteString(c.App.Writer, help)
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		logger.Debugf("Pager %s exited: %s", pager[0], err.Error())
+	}
+	return nil
+}
+
+// helpPager returns the command paging help, set with PAGER. By default, less exits at once if help fits on one screen.
+func helpPager() []string {
+	if pager := strings.Fields(os.Getenv("PAGER")); len(pager) > 0 {
+		return pager
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"more"}
+	}
+	return []string{"less", "-FRX"}
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	bold := color.New(color.Bold).Sprint
+	tests := map[string]struct {
+		doc      string
+		expected string
+	}{
+		"paragraphs": {
+			doc:      "First paragraph.\n\n\n\nSecond paragraph.\n",
+			expected: "   First paragraph.\n\n   Second paragraph.\n",
+		},
+		"headings": {
+			doc:      "# Usage\n## Flags and `options`\nText",
+			expected: "   " + bold("Usage") + "\n   " + bold("Flags and "+color.CyanString("options")) + "\n   Text\n",
+		},
+		"lists": {
+			doc:      "- first\n* second\n  + nested",
+			expected: "   - first\n   - second\n     - nested\n",
+		},
+		"code blocks": {
+			doc:      "Run:\r\n```sh\r\nakamai echo  \r\n\r\n  --flag\r\n```\r\n",
+			expected: "   Run:\n       " + color.CyanString("akamai echo") + "\n       " + color.CyanString("") + "\n       " + color.CyanString("  --flag") + "\n",
+		},
+		"inline formatting": {
+			doc:      "Use **bold**, __strong__, `code` and [links](https://example.com).",
+			expected: "   Use " + bold("bold") + ", " + bold("strong") + ", " + color.CyanString("code") + " and links (https://example.com).\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, renderMarkdown(test.doc))
+		})
+	}
+}
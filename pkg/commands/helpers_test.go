@@ -2,15 +2,20 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/akamai/cli/pkg/config"
 	"github.com/akamai/cli/pkg/git"
 	"github.com/akamai/cli/pkg/packages"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -42,6 +47,30 @@ func setupTestApp(command *cli.Command, m *mocked) (*cli.App, context.Context) {
 	return app, ctx
 }
 
+// useTempCache sets AKAMAI_CLI_CACHE_PATH to a new temporary directory, so that package lists cached by one test
+// are not used by another. The returned function removes the directory.
+func useTempCache(t *testing.T) func() {
+	cachePath, err := ioutil.TempDir("", "akamai-cache")
+	require.NoError(t, err)
+	require.NoError(t, os.Setenv("AKAMAI_CLI_CACHE_PATH", cachePath))
+	return func() {
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_CACHE_PATH"))
+		require.NoError(t, os.RemoveAll(cachePath))
+	}
+}
+
+// useTempAuditLog sets AKAMAI_CLI_AUDIT_LOG to a file in a new temporary directory, so that operations done by tests
+// are not recorded in testdata. The returned function removes the directory.
+func useTempAuditLog(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "akamai-audit")
+	require.NoError(t, err)
+	require.NoError(t, os.Setenv("AKAMAI_CLI_AUDIT_LOG", filepath.Join(dir, auditLogName)))
+	return func() {
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_AUDIT_LOG"))
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
 func copyFile(t *testing.T, src, dst string) {
 	err := os.MkdirAll(dst, 0755)
 	require.NoError(t, err)
@@ -60,3 +89,31 @@ func copyFile(t *testing.T, src, dst string) {
 	err = destFile.Sync()
 	require.NoError(t, err)
 }
+
+// stagedPackage matches a path of package copy created in the staging area of test CLI home
+func stagedPackage(name string) interface{} {
+	return mock.MatchedBy(func(path string) bool {
+		return strings.HasPrefix(path, filepath.Join("testdata", ".akamai-cli", stagingDirName)) && filepath.Base(path) == name
+	})
+}
+
+// jsonOutput matches terminal output containing a single JSON document equivalent to the expected one
+func jsonOutput(expected string) interface{} {
+	return mock.MatchedBy(func(args []interface{}) bool {
+		if len(args) != 1 {
+			return false
+		}
+		out, ok := args[0].(string)
+		if !ok {
+			return false
+		}
+		var actualData, expectedData interface{}
+		if err := json.Unmarshal([]byte(out), &actualData); err != nil {
+			return false
+		}
+		if err := json.Unmarshal([]byte(expected), &expectedData); err != nil {
+			return false
+		}
+		return reflect.DeepEqual(expectedData, actualData)
+	})
+}
@@ -0,0 +1,175 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/akamai/cli/pkg/version"
+)
+
+// lifecycle hooks which can be declared in the hooks section of cli.json
+const (
+	hookPreInstall   = "pre-install"
+	hookPostInstall  = "post-install"
+	hookPreUpdate    = "pre-update"
+	hookPostUpdate   = "post-update"
+	hookPreUninstall = "pre-uninstall"
+)
+
+// defaultHookTimeout is how long a hook can run before it is killed
+const defaultHookTimeout = 5 * time.Minute
+
+// hookEnvironment lists variables passed from the environment of Akamai CLI to hooks; any other variable is left out
+var hookEnvironment = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LC_ALL", "TERM", "TMPDIR", "TEMP", "TMP",
+	"USERPROFILE", "APPDATA", "LOCALAPPDATA", "SYSTEMROOT", "COMSPEC", "PATHEXT",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"GOPATH", "GOROOT", "GOCACHE",
+}
+
+type noHooksKey struct{}
+
+// withoutHooks marks the context so that lifecycle hooks declared by packages are not executed
+func withoutHooks(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noHooksKey{}, true)
+}
+
+func hooksDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noHooksKey{}).(bool)
+	return disabled
+}
+
+// parseHookTimeout parses the cli.hook-timeout setting, e.g. "30s" or "10m".
+// The default timeout is used for an empty value, and for an invalid value along with the error.
+func parseHookTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultHookTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return defaultHookTimeout, fmt.Errorf("invalid hook timeout %q, expected a duration such as 30s or 10m", value)
+	}
+	return timeout, nil
+}
+
+// runPackageHook executes the hook of the package in given directory, if the package declares it and hooks are not disabled.
+// Hooks are not executed for packages run in containers.
+// The hook is run by the system shell in the package directory, with a restricted environment and a timeout.
+// env holds additional variables describing the operation, e.g. the previous commit on update.
+func runPackageHook(ctx context.Context, dir, hook string, env map[string]string) error {
+	if hooksDisabled(ctx) || containerMode(ctx) {
+		return nil
+	}
+	if meta, err := readInstallMetadata(dir); err == nil && meta != nil && meta.Image != "" {
+		// packages run in containers are not built locally
+		return nil
+	}
+	pkg, err := readPackage(dir)
+	if err != nil {
+		return nil
+	}
+	script := strings.TrimSpace(pkg.Hooks[hook])
+	if script == "" {
+		return nil
+	}
+
+	logger := log.FromContext(ctx).WithFields(log.Fields{"hook": hook, "dir": dir})
+	timeout, err := parseHookTimeout(os.Getenv("AKAMAI_CLI_HOOK_TIMEOUT"))
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+
+	spin := terminal.Get(ctx).Spinner()
+	spin.Start("Running %s hook...", hook)
+	output, err := execHook(ctx, dir, script, hookEnv(pkg, dir, hook, env), timeout)
+	logger = logger.WithField("output", strings.TrimSpace(string(output)))
+	if err != nil {
+		spin.Fail()
+		logger.WithError(err).Warn("Hook failed")
+		return fmt.Errorf("%s hook failed: %s", hook, err)
+	}
+	spin.OK()
+	logger.Debug("Hook finished")
+	return nil
+}
+
+// execHook runs the hook script and returns its combined output.
+// Once the timeout expires or the context is canceled, the hook is killed along with processes it spawned.
+func execHook(ctx context.Context, dir, script string, env []string, timeout time.Duration) ([]byte, error) {
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", script)
+	} else {
+		cmd = exec.Command("sh", "-c", script)
+	}
+	cmd.Dir = dir
+	cmd.Env = env
+	output := &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = output, output
+
+	err := tools.RunProcessGroup(hookCtx, cmd)
+	if hookCtx.Err() != nil {
+		if errors.Is(hookCtx.Err(), context.DeadlineExceeded) {
+			return output.Bytes(), fmt.Errorf("timed out after %s", timeout)
+		}
+		return output.Bytes(), hookCtx.Err()
+	}
+	if err != nil && output.Len() > 0 {
+		return output.Bytes(), fmt.Errorf("%s: %s", err, lastLine(output.Bytes()))
+	}
+	return output.Bytes(), err
+}
+
+// hookEnv returns the environment hooks are executed with
+func hookEnv(pkg subcommands, dir, hook string, env map[string]string) []string {
+	vars := make([]string, 0, len(hookEnvironment)+len(env)+5)
+	for _, name := range hookEnvironment {
+		if value, ok := os.LookupEnv(name); ok {
+			vars = append(vars, name+"="+value)
+		}
+	}
+	vars = append(vars,
+		"AKAMAI_CLI=1",
+		"AKAMAI_CLI_VERSION="+version.Version,
+		"AKAMAI_CLI_HOOK="+hook,
+		"AKAMAI_CLI_PACKAGE="+pkg.Pkg,
+		"AKAMAI_CLI_PACKAGE_DIR="+dir,
+	)
+	for name, value := range env {
+		vars = append(vars, name+"="+value)
+	}
+	return vars
+}
+
+// lastLine returns the last non-empty line of the output, which usually describes the error
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/version"
+)
+
+func TestRunPackageHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in tests are shell scripts")
+	}
+	tests := map[string]struct {
+		hooks     string
+		timeout   string
+		disabled  bool
+		init      func(*terminal.Mock)
+		expected  string
+		withError string
+	}{
+		"hook not declared": {
+			hooks: `{"post-install": "echo ok > hook.out"}`,
+			init:  func(m *terminal.Mock) {},
+		},
+		"hook run with package environment": {
+			hooks: `{"pre-uninstall": "echo \"$AKAMAI_CLI_HOOK $AKAMAI_CLI_PACKAGE $AKAMAI_CLI_VERSION $AKAMAI_CLI_PREVIOUS_COMMIT $SECRET_TOKEN\" > hook.out"}`,
+			init: func(m *terminal.Mock) {
+				m.On("Spinner").Return(m).Once()
+				m.On("Start", "Running %s hook...", []interface{}{"pre-uninstall"}).Return().Once()
+				m.On("OK").Return().Once()
+			},
+			expected: "pre-uninstall {package} " + version.Version + " abc \n",
+		},
+		"hook fails": {
+			hooks: `{"pre-uninstall": "echo first line; echo virtualenv not found >&2; exit 3"}`,
+			init: func(m *terminal.Mock) {
+				m.On("Spinner").Return(m).Once()
+				m.On("Start", "Running %s hook...", []interface{}{"pre-uninstall"}).Return().Once()
+				m.On("Fail").Return().Once()
+			},
+			withError: "pre-uninstall hook failed: exit status 3: virtualenv not found",
+		},
+		"hook times out": {
+			hooks:   `{"pre-uninstall": "sleep 5"}`,
+			timeout: "100ms",
+			init: func(m *terminal.Mock) {
+				m.On("Spinner").Return(m).Once()
+				m.On("Start", "Running %s hook...", []interface{}{"pre-uninstall"}).Return().Once()
+				m.On("Fail").Return().Once()
+			},
+			withError: "pre-uninstall hook failed: timed out after 100ms",
+		},
+		"hooks disabled": {
+			hooks:    `{"pre-uninstall": "echo ok > hook.out"}`,
+			disabled: true,
+			init:     func(m *terminal.Mock) {},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-hooked")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			cliJSON := `{"commands": [{"name": "hooked"}], "hooks": ` + test.hooks + `}`
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(cliJSON), 0644))
+			require.NoError(t, os.Setenv("SECRET_TOKEN", "secret"))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOOK_TIMEOUT", test.timeout))
+			defer func() {
+				require.NoError(t, os.Unsetenv("SECRET_TOKEN"))
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_HOOK_TIMEOUT"))
+			}()
+			m := &terminal.Mock{}
+			test.init(m)
+			ctx := terminal.Context(context.Background(), m)
+			if test.disabled {
+				ctx = withoutHooks(ctx)
+			}
+
+			start := time.Now()
+			err = runPackageHook(ctx, dir, hookPreUninstall, map[string]string{"AKAMAI_CLI_PREVIOUS_COMMIT": "abc"})
+			m.AssertExpectations(t)
+			assert.True(t, time.Since(start) < 5*time.Second)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			output, err := ioutil.ReadFile(filepath.Join(dir, "hook.out"))
+			if test.expected == "" {
+				assert.True(t, os.IsNotExist(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, strings.Replace(string(output), strings.TrimPrefix(filepath.Base(dir), "cli-"), "{package}", 1))
+		})
+	}
+}
+
+func TestParseHookTimeout(t *testing.T) {
+	tests := map[string]struct {
+		value     string
+		expected  time.Duration
+		withError string
+	}{
+		"default":  {value: "", expected: defaultHookTimeout},
+		"duration": {value: "30s", expected: 30 * time.Second},
+		"invalid": {value: "soon", expected: defaultHookTimeout,
+			withError: `invalid hook timeout "soon", expected a duration such as 30s or 10m`},
+		"zero": {value: "0", expected: defaultHookTimeout,
+			withError: `invalid hook timeout "0", expected a duration such as 30s or 10m`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			timeout, err := parseHookTimeout(test.value)
+			assert.Equal(t, test.expected, timeout)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
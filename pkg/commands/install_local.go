@@ -0,0 +1,177 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// localPackageSource returns the path of a package directory or archive given as install argument, which is installed without git.
+// Local packages are given as a filesystem path or a file:// URL. File URLs of git repositories are still cloned.
+func localPackageSource(arg string) (string, bool) {
+	if strings.HasPrefix(arg, "file://") {
+		path := strings.TrimPrefix(arg, "file://")
+		if !isArchive(path) {
+			if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+				return "", false
+			}
+		}
+		return path, true
+	}
+
+	if arg == "." || arg == ".." || filepath.IsAbs(arg) || isArchive(arg) {
+		return arg, true
+	}
+	for _, prefix := range []string{"./", "../", "." + string(os.PathSeparator), ".." + string(os.PathSeparator)} {
+		if strings.HasPrefix(arg, prefix) {
+			return arg, true
+		}
+	}
+	return "", false
+}
+
+// installSourcePackage installs a package without git: from a local directory, a package archive such as the ones created
+// with "akamai package pack", local or downloaded over HTTP(S), or a Mercurial repository.
+// The package is prepared in the staging area and validated before it is moved to the packages directory and built.
+func installSourcePackage(ctx context.Context, langManager packages.LangManager, source packageSource, forceBinary bool, resolver *dependencyResolver) (_ *subcommands, e error) {
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if source.isLocal() {
+		if source.Location, err = filepath.Abs(source.Location); err != nil {
+			return nil, err
+		}
+	}
+
+	spin := term.Spinner()
+	spin.Start("Attempting to install package from %s...", source.String())
+
+	if source.isLocal() {
+		if _, err := os.Stat(source.Location); err != nil {
+			spin.Fail()
+			errorMsg := "Unable to read package: " + err.Error()
+			logger.Error(errorMsg)
+			return nil, commandFailure(errNotFound, errorMsg)
+		}
+	}
+
+	stagedDir, revision, err := source.stage(ctx)
+	if err != nil {
+		spin.Fail()
+		errorMsg := "Unable to read package: " + err.Error()
+		logger.Error(errorMsg)
+		if source.isLocal() {
+			return nil, cli.Exit(color.RedString(errorMsg), 1)
+		}
+		return nil, commandFailure(errNetwork, errorMsg)
+	}
+	defer func() {
+		if err := discardStagedPackage(stagedDir); err != nil {
+			logger.Errorf("Unable to remove staging directory: %s", err.Error())
+		}
+	}()
+
+	if err := validatePackage(stagedDir); err != nil {
+		spin.Fail()
+		errorMsg := "Invalid package: " + err.Error()
+		logger.Error(errorMsg)
+		return nil, commandFailure(errUsage, errorMsg)
+	}
+
+	packageDir := filepath.Join(srcPath, filepath.Base(stagedDir))
+	defer func() {
+		entry := auditPackage(auditInstall, packageDir)
+		entry.Source = source.String()
+		recordAudit(ctx, entry, e)
+	}()
+	if _, err = os.Stat(packageDir); err == nil {
+		spin.Stop(terminal.SpinnerStatusWarn)
+		warningMsg := fmt.Sprintf("Package directory already exists (%s). To reinstall this package, first run 'akamai uninstall' command.", packageDir)
+		return nil, commandWarning(errAlreadyInstalled, warningMsg)
+	}
+
+	// the package is built in its final location, as some package managers store absolute paths
+	if err := os.Rename(stagedDir, packageDir); err != nil {
+		spin.Fail()
+		errorMsg := "Unable to install package: " + err.Error()
+		logger.Error(errorMsg)
+		return nil, cli.Exit(color.RedString(errorMsg), 1)
+	}
+	spin.OK()
+
+	if resolver != nil {
+		if err := resolver.resolve(ctx, packageDir); err != nil {
+			if err := os.RemoveAll(packageDir); err != nil {
+				return nil, err
+			}
+			return nil, err
+		}
+	}
+
+	subCmd, err := buildInstalledPackage(ctx, langManager, packageDir, forceBinary, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &installMetadata{Source: source.Location, SourceType: source.Type, Ref: source.Ref}
+	source.setRevision(meta, revision)
+	if containerMode(ctx) {
+		meta.Image = subCmd.Image
+	}
+	if err := writeInstallMetadata(packageDir, meta); err != nil {
+		logger.Errorf("Unable to save install metadata: %s", err.Error())
+	}
+
+	return subCmd, nil
+}
+
+// validatePackage verifies that the directory contains a valid cli.json file declaring at least one command
+func validatePackage(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, "cli.json")); err != nil {
+		return fmt.Errorf("package does not contain a cli.json file")
+	}
+	pkg, err := readPackage(dir)
+	if err != nil {
+		return fmt.Errorf("unable to parse cli.json: %s", err.Error())
+	}
+	if len(pkg.Commands) == 0 {
+		return fmt.Errorf("cli.json does not declare any commands")
+	}
+	for _, cmd := range pkg.Commands {
+		if cmd.Name == "" {
+			return fmt.Errorf("cli.json declares a command without a name")
+		}
+		if cmd.RenamedTo == cmd.Name {
+			return fmt.Errorf("cli.json declares command %s renamed to itself", cmd.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const installMetadataFile = ".akamai-install.json"
+
+// types of git references a package can be installed from
+const (
+	refTypeBranch = "branch"
+	refTypeTag    = "tag"
+	refTypeCommit = "commit"
+)
+
+// installMetadata describes how a package was installed.
+// It is stored alongside cli.json in the package directory, as cli.json is owned by the package author.
+type installMetadata struct {
+	Repo          string `json:"repo,omitempty"`
+	DefaultBranch string `json:"default-branch,omitempty"`
+	Ref           string `json:"ref,omitempty"`
+	RefType       string `json:"ref-type,omitempty"`
+	Commit        string `json:"commit,omitempty"`
+	Source        string `json:"source,omitempty"`
+	// SourceType is the type of Source, one of directory, archive or hg, see packageSource
+	SourceType string `json:"source-type,omitempty"`
+	// Checksum is the SHA-256 checksum of the archive a package was installed from
+	Checksum string `json:"checksum,omitempty"`
+	Image    string `json:"image,omitempty"`
+	// Held is set by the pin command, to hold the package back when all packages are updated
+	Held bool `json:"held,omitempty"`
+}
+
+// isPinned returns true if package was installed from a tag or a specific commit, which should not be updated implicitly
+func (m *installMetadata) isPinned() bool {
+	return m.RefType == refTypeTag || m.RefType == refTypeCommit
+}
+
+// readInstallMetadata reads install metadata of the package in given directory.
+// Packages installed before metadata was introduced do not have it, in which case nil is returned.
+func readInstallMetadata(dir string) (*installMetadata, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, installMetadataFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var meta installMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+func writeInstallMetadata(dir string, meta *installMetadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return tools.WriteFileAtomic(filepath.Join(dir, installMetadataFile), data, 0644)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+// strategies of installing package commands, set with cli.install-strategy or the --binary-only and --source-only flags
+const (
+	// installStrategyAuto builds packages from source and offers to download binaries if the build fails
+	installStrategyAuto = "auto"
+	// installStrategyBinary downloads binaries without building packages from source
+	installStrategyBinary = "binary"
+	// installStrategySource builds packages from source and never downloads binaries
+	installStrategySource = "source"
+)
+
+type installStrategyKey struct{}
+
+func withInstallStrategy(ctx context.Context, strategy string) context.Context {
+	return context.WithValue(ctx, installStrategyKey{}, strategy)
+}
+
+// installStrategy returns the strategy packages are installed with, defaulting to installStrategyAuto
+func installStrategy(ctx context.Context) string {
+	if strategy, ok := ctx.Value(installStrategyKey{}).(string); ok && strategy != "" {
+		return strategy
+	}
+	return installStrategyAuto
+}
+
+// parseInstallStrategy parses the cli.install-strategy setting, an empty value standing for installStrategyAuto
+func parseInstallStrategy(value string) (string, error) {
+	switch value {
+	case "", installStrategyAuto:
+		return installStrategyAuto, nil
+	case installStrategyBinary, installStrategySource:
+		return value, nil
+	}
+	return "", fmt.Errorf("invalid install strategy %q, expected %s, %s or %s", value, installStrategyAuto, installStrategyBinary, installStrategySource)
+}
+
+// installStrategyFromFlags returns the strategy requested with --binary-only or --source-only, or set in the config file otherwise
+func installStrategyFromFlags(c *cli.Context) (string, error) {
+	binaryOnly, sourceOnly := c.Bool("binary-only"), c.Bool("source-only")
+	switch {
+	case binaryOnly && sourceOnly:
+		return "", fmt.Errorf("flags --binary-only and --source-only cannot be used together")
+	case binaryOnly:
+		return installStrategyBinary, nil
+	case sourceOnly:
+		return installStrategySource, nil
+	}
+	return parseInstallStrategy(os.Getenv("AKAMAI_CLI_INSTALL_STRATEGY"))
+}
@@ -0,0 +1,88 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// packagesLockName is the file in the Akamai CLI data directory locked while installed packages are modified
+const packagesLockName = ".lock"
+
+// withPackagesLock wraps the action of a command modifying installed packages, so that it runs only once no other
+// Akamai CLI instance is modifying them. Dry runs and update checks make no changes, so they run without the lock.
+func withPackagesLock(action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if isDryRun(c) || c.Bool("check") {
+			return action(c)
+		}
+		lock, err := lockPackages(c.Context)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			invalidatePackageIndex(c.Context)
+			if err := lock.Unlock(); err != nil {
+				log.FromContext(c.Context).Errorf("Unable to release packages lock: %s", err)
+			}
+		}()
+		return action(c)
+	}
+}
+
+// lockPackages acquires the packages lock, waiting up to cli.lock-timeout for another instance to release it
+func lockPackages(ctx context.Context) (*tools.FileLock, error) {
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+
+	dir, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, packagesLockName)
+	value, _ := config.Get(ctx).GetValue("cli", "lock-timeout")
+	timeout, err := tools.ParseLockTimeout(value)
+	if err != nil {
+		logger.Warnf("Invalid lock timeout, using default: %s", err)
+	}
+
+	lock, err := tools.LockFile(ctx, path, 0)
+	if errors.Is(err, tools.ErrLocked) && timeout > 0 {
+		logger.Debugf("Waiting for packages lock: %s", path)
+		term.Spinner().Start("Waiting for another instance of Akamai CLI to finish...")
+		lock, err = tools.LockFile(ctx, path, timeout)
+		if err != nil {
+			term.Spinner().Stop(terminal.SpinnerStatusFail)
+		} else {
+			term.Spinner().Stop(terminal.SpinnerStatusOK)
+		}
+	}
+	if errors.Is(err, tools.ErrLocked) {
+		return nil, commandFailure(errLocked, "Another instance of Akamai CLI is running and modifying installed packages, try again once it finishes")
+	}
+	if err != nil {
+		return nil, commandFailure(errConfig, "Unable to lock installed packages: %s", err)
+	}
+	return lock, nil
+}
@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func TestWithPackagesLock(t *testing.T) {
+	tests := map[string]struct {
+		args          []string
+		locked        bool
+		timeout       string
+		init          func(*mocked)
+		withExitCode  int
+		actionSkipped bool
+	}{
+		"not locked": {
+			args:    []string{"install"},
+			timeout: "0s",
+		},
+		"locked, no timeout": {
+			args:          []string{"install"},
+			locked:        true,
+			timeout:       "0s",
+			withExitCode:  exitLocked,
+			actionSkipped: true,
+		},
+		"locked until timeout": {
+			args:    []string{"install"},
+			locked:  true,
+			timeout: "200ms",
+			init: func(m *mocked) {
+				m.term.On("Spinner").Return(m.term).Twice()
+				m.term.On("Start", "Waiting for another instance of Akamai CLI to finish...", []interface{}(nil)).Return().Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+			},
+			withExitCode:  exitLocked,
+			actionSkipped: true,
+		},
+		"locked, dry run": {
+			args:   []string{"install", "--dry-run"},
+			locked: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "lock-home")
+			require.NoError(t, err)
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			defer func() {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+				require.NoError(t, os.RemoveAll(home))
+			}()
+
+			if test.locked {
+				lock, err := tools.LockFile(context.Background(), filepath.Join(home, ".akamai-cli", packagesLockName), 0)
+				require.NoError(t, err)
+				defer func() {
+					assert.NoError(t, lock.Unlock())
+				}()
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("GetValue", "cli", "lock-timeout").Return(test.timeout, test.timeout != "").Maybe()
+			if test.init != nil {
+				test.init(m)
+			}
+			var called bool
+			app, ctx := setupTestApp(&cli.Command{
+				Name:  "install",
+				Flags: []cli.Flag{&cli.BoolFlag{Name: "dry-run"}},
+				Action: withPackagesLock(func(c *cli.Context) error {
+					called = true
+					return nil
+				}),
+			}, m)
+
+			err = app.RunContext(ctx, append([]string{"akamai"}, test.args...))
+			m.term.AssertExpectations(t)
+			assert.Equal(t, !test.actionSkipped, called)
+			if test.withExitCode != 0 {
+				var exitErr cli.ExitCoder
+				require.Error(t, err)
+				require.True(t, errors.As(err, &exitErr))
+				assert.Equal(t, test.withExitCode, exitErr.ExitCode())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLockPackagesWaits(t *testing.T) {
+	home, err := ioutil.TempDir("", "lock-home")
+	require.NoError(t, err)
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+		require.NoError(t, os.RemoveAll(home))
+	}()
+
+	lock, err := tools.LockFile(context.Background(), filepath.Join(home, ".akamai-cli", packagesLockName), 0)
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		assert.NoError(t, lock.Unlock())
+	}()
+
+	m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+	m.cfg.On("GetValue", "cli", "lock-timeout").Return("5s", true)
+	m.term.On("Spinner").Return(m.term).Twice()
+	m.term.On("Start", "Waiting for another instance of Akamai CLI to finish...", []interface{}(nil)).Return().Once()
+	m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
+	var called bool
+	app, ctx := setupTestApp(&cli.Command{
+		Name: "update",
+		Action: withPackagesLock(func(c *cli.Context) error {
+			called = true
+			return nil
+		}),
+	}, m)
+
+	require.NoError(t, app.RunContext(ctx, []string{"akamai", "update"}))
+	m.term.AssertExpectations(t)
+	assert.True(t, called)
+}
+
+func TestReinstallPythonPackageLock(t *testing.T) {
+	legacyDir := map[string]string{"linux": ".local", "darwin": "Library", "windows": "Lib"}[runtime.GOOS]
+	if legacyDir == "" {
+		t.Skip("legacy Python installations are not detected on " + runtime.GOOS)
+	}
+	tests := map[string]struct {
+		locked       bool
+		legacy       bool
+		withExitCode int
+	}{
+		"locked by another instance": {
+			locked:       true,
+			legacy:       true,
+			withExitCode: exitLocked,
+		},
+		"reinstalled by another instance": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home := t.TempDir()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+			defer func() {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			packageDir := filepath.Join(home, ".akamai-cli", "src", "cli-echo-python")
+			require.NoError(t, os.MkdirAll(packageDir, 0755))
+			if test.legacy {
+				require.NoError(t, os.Mkdir(filepath.Join(packageDir, legacyDir), 0755))
+			}
+			if test.locked {
+				lock, err := tools.LockFile(context.Background(), filepath.Join(home, ".akamai-cli", packagesLockName), 0)
+				require.NoError(t, err)
+				defer func() {
+					assert.NoError(t, lock.Unlock())
+				}()
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("GetValue", "cli", "lock-timeout").Return("0s", true)
+			_, ctx := setupTestApp(&cli.Command{Name: "echo-python"}, m)
+
+			err := reinstallPythonPackage(ctx, m.gitRepo, m.langManager, "echo-python", packageDir, log.FromContext(ctx))
+			m.langManager.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			_, statErr := os.Stat(packageDir)
+			assert.NoError(t, statErr, "package should be left as is")
+			if test.withExitCode != 0 {
+				var exitErr cli.ExitCoder
+				require.Error(t, err)
+				require.True(t, errors.As(err, &exitErr))
+				assert.Equal(t, test.withExitCode, exitErr.ExitCode())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
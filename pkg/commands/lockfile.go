@@ -0,0 +1,269 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	lockFileName    = "akamai-packages.lock"
+	lockFileVersion = 1
+)
+
+// lockFile records the exact set of installed packages, so that it can be reproduced with "akamai install --from-lock"
+type lockFile struct {
+	Version  int             `json:"version"`
+	Packages []lockedPackage `json:"packages"`
+}
+
+// lockedPackage is a package pinned to the commit it was installed from, along with checksums of its command binaries.
+// Packages installed without git are recorded with their source instead of a repository, pinned to the changeset
+// of Mercurial repositories or to the checksum of archives. Local directories have no revision to pin.
+type lockedPackage struct {
+	Name   string `json:"name"`
+	Repo   string `json:"repo,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	// Source and SourceType locate packages installed without git, see installMetadata
+	Source     string `json:"source,omitempty"`
+	SourceType string `json:"source-type,omitempty"`
+	// Checksum is the SHA-256 checksum of the archive a package was installed from
+	Checksum string            `json:"checksum,omitempty"`
+	Binaries map[string]string `json:"binaries,omitempty"`
+	Image    string            `json:"image,omitempty"`
+}
+
+// source returns the source of a package installed without git, at its locked revision.
+// False is returned for packages installed from git repositories.
+func (p lockedPackage) source() (packageSource, bool) {
+	if p.SourceType == "" || p.SourceType == sourceGit {
+		return packageSource{}, false
+	}
+	source := packageSource{Type: p.SourceType, Location: p.Source}
+	if source.Type == sourceMercurial {
+		source.Ref = p.Commit
+	}
+	return source, true
+}
+
+// revision returns the locked revision of the package: a commit, a Mercurial changeset or an archive checksum
+func (p lockedPackage) revision() string {
+	if source, ok := p.source(); ok {
+		return source.revision(&installMetadata{Commit: p.Commit, Checksum: p.Checksum})
+	}
+	return p.Commit
+}
+
+// validate returns an error if the entry does not specify where to install the package from, and at which revision
+func (p lockedPackage) validate() error {
+	if p.Name == "" || filepath.Base(p.Name) != p.Name {
+		return fmt.Errorf("invalid package name \"%s\"", p.Name)
+	}
+	source, ok := p.source()
+	switch {
+	case !ok && (p.Repo == "" || p.Commit == ""):
+		return fmt.Errorf("package \"%s\" does not specify repository and commit", p.Name)
+	case !ok:
+		return nil
+	case source.Type != sourceDirectory && source.Type != sourceArchive && source.Type != sourceMercurial:
+		return fmt.Errorf("package \"%s\" has unknown source type \"%s\"", p.Name, source.Type)
+	case source.Location == "":
+		return fmt.Errorf("package \"%s\" does not specify its source", p.Name)
+	case source.Type != sourceDirectory && p.revision() == "":
+		return fmt.Errorf("package \"%s\" does not specify the revision of its source", p.Name)
+	}
+	return nil
+}
+
+// isInstalled returns true if the package was installed from the locked source at the locked revision
+func (p lockedPackage) isInstalled(meta *installMetadata) bool {
+	if meta == nil {
+		return false
+	}
+	locked, ok := p.source()
+	if !ok {
+		return meta.Commit == p.Commit
+	}
+	installed, ok := installedSource(meta)
+	return ok && installed.Type == locked.Type && installed.Location == locked.Location && installed.revision(meta) == p.revision()
+}
+
+// defaultLockFilePath returns location of the lock file maintained by install, update and uninstall commands
+func defaultLockFilePath() (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, lockFileName), nil
+}
+
+// buildLockFile collects all installed packages with install metadata.
+// Packages installed before install metadata was introduced cannot be reproduced, they are skipped with a warning.
+func buildLockFile(ctx context.Context) (*lockFile, error) {
+	logger := log.FromContext(ctx)
+	lock := &lockFile{Version: lockFileVersion, Packages: make([]lockedPackage, 0)}
+	var unlocked []string
+	for _, dir := range getPackagePaths() {
+		meta, err := readInstallMetadata(dir)
+		if err != nil {
+			return nil, err
+		}
+		pkg := lockedPackage{Name: filepath.Base(dir)}
+		if source, ok := installedSource(meta); ok {
+			pkg.Source, pkg.SourceType = source.Location, source.Type
+			pkg.Commit, pkg.Checksum = meta.Commit, meta.Checksum
+		} else if meta != nil && meta.Repo != "" && meta.Commit != "" {
+			pkg.Repo, pkg.Commit = meta.Repo, meta.Commit
+		} else {
+			logger.Debugf("Package %s has no install metadata, skipping", dir)
+			unlocked = append(unlocked, pkg.Name)
+			continue
+		}
+
+		if pkg.Binaries, err = binaryChecksums(dir); err != nil {
+			return nil, err
+		}
+		pkg.Image = meta.Image
+		lock.Packages = append(lock.Packages, pkg)
+	}
+	if len(unlocked) > 0 {
+		logger.Warnf("Packages installed without install metadata are not recorded in the lock file, reinstall them to record them: %s", strings.Join(unlocked, ", "))
+	}
+	return lock, nil
+}
+
+// updateLockFile writes the lock file for currently installed packages.
+// Failing to do so does not affect the installed packages, hence the error is only logged.
+func updateLockFile(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	path, err := defaultLockFilePath()
+	if err != nil {
+		logger.Errorf("Unable to determine lock file location: %s", err.Error())
+		return
+	}
+	lock, err := buildLockFile(ctx)
+	if err != nil {
+		logger.Errorf("Unable to build lock file: %s", err.Error())
+		return
+	}
+	if err := writeLockFile(path, lock); err != nil {
+		logger.Errorf("Unable to save lock file: %s", err.Error())
+		return
+	}
+	logger.Debugf("Lock file saved: %s", path)
+}
+
+func readLockFile(path string) (*lockFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock lockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+func writeLockFile(path string, lock *lockFile) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return tools.WriteFileAtomic(path, data, 0644)
+}
+
+// binaryChecksums calculates SHA-256 checksums of command executables located in the package directory and its bin directory.
+// Keys are paths relative to the package directory.
+func binaryChecksums(dir string) (map[string]string, error) {
+	var binaries []string
+	for _, pattern := range []string{filepath.Join(dir, "akamai-*"), filepath.Join(dir, "bin", "akamai-*")} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		binaries = append(binaries, matches...)
+	}
+
+	checksums := make(map[string]string)
+	for _, bin := range binaries {
+		info, err := os.Stat(bin)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		sum, err := fileChecksum(bin)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(dir, bin)
+		if err != nil {
+			return nil, err
+		}
+		checksums[filepath.ToSlash(rel)] = sum
+	}
+	if len(checksums) == 0 {
+		return nil, nil
+	}
+	return checksums, nil
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// mismatchedBinaries compares checksums of binaries in the package directory with the ones recorded in the lock file
+// and returns names of binaries which are missing or differ
+func mismatchedBinaries(dir string, locked map[string]string) ([]string, error) {
+	actual, err := binaryChecksums(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatched []string
+	for name, sum := range locked {
+		if !strings.EqualFold(actual[name], sum) {
+			mismatched = append(mismatched, name)
+		}
+	}
+	sort.Strings(mismatched)
+	return mismatched, nil
+}
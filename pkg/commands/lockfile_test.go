@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestBuildLockFile(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	srcPath := filepath.Join(home, ".akamai-cli", "src")
+	installed := map[string]*installMetadata{
+		"cli-git":       {Repo: "https://github.com/akamai/cli-git.git", Commit: plumbing.Hash{1}.String()},
+		"cli-archive":   {Source: "/tmp/cli-archive.tar.gz", SourceType: sourceArchive, Checksum: "abc"},
+		"cli-hg":        {Source: "https://hg.example.com/cli-hg", SourceType: sourceMercurial, Commit: "def"},
+		"cli-directory": {Source: "/tmp/cli-directory"},
+		"cli-legacy":    nil,
+	}
+	for name, meta := range installed {
+		require.NoError(t, os.MkdirAll(filepath.Join(srcPath, name), 0755))
+		if meta != nil {
+			require.NoError(t, writeInstallMetadata(filepath.Join(srcPath, name), meta))
+		}
+	}
+
+	lock, err := buildLockFile(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []lockedPackage{
+		{Name: "cli-git", Repo: "https://github.com/akamai/cli-git.git", Commit: plumbing.Hash{1}.String()},
+		{Name: "cli-archive", Source: "/tmp/cli-archive.tar.gz", SourceType: sourceArchive, Checksum: "abc"},
+		{Name: "cli-hg", Source: "https://hg.example.com/cli-hg", SourceType: sourceMercurial, Commit: "def"},
+		{Name: "cli-directory", Source: "/tmp/cli-directory", SourceType: sourceDirectory},
+	}, lock.Packages)
+
+	for _, pkg := range lock.Packages {
+		assert.NoError(t, pkg.validate())
+		assert.True(t, pkg.isInstalled(installed[pkg.Name]), "%s should be installed at the locked revision", pkg.Name)
+		if pkg.Name == "cli-hg" {
+			// Mercurial repositories are installed at the locked changeset
+			source, ok := pkg.source()
+			require.True(t, ok)
+			assert.Equal(t, packageSource{Type: sourceMercurial, Location: "https://hg.example.com/cli-hg", Ref: "def"}, source)
+		}
+	}
+}
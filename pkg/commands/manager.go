@@ -0,0 +1,95 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+)
+
+// PackageManager installs, updates and removes Akamai CLI packages, for tools embedding Akamai CLI package management.
+// The context given to its methods must hold the config and the terminal progress is reported to, see config.Context and terminal.Context.
+// Like the install, update and uninstall commands, methods modifying packages hold the packages lock and keep the lock file up to date.
+type PackageManager struct {
+	gitRepo     git.Repository
+	langManager packages.LangManager
+}
+
+// NewPackageManager returns a PackageManager fetching packages with gitRepo and building them with langManager,
+// usually git.NewRepository() and packages.NewLangManager()
+func NewPackageManager(gitRepo git.Repository, langManager packages.LangManager) *PackageManager {
+	return &PackageManager{gitRepo: gitRepo, langManager: langManager}
+}
+
+// Installed returns metadata of installed packages
+func (m *PackageManager) Installed() []packages.Metadata {
+	installed := make([]packages.Metadata, 0)
+	for _, dir := range getPackagePaths() {
+		if pkg, err := readPackage(dir); err == nil {
+			installed = append(installed, pkg)
+		}
+	}
+	return installed
+}
+
+// Install installs a package given the same way as to the install command: a package name, a repository,
+// a local directory or archive, optionally followed by @<branch, tag or commit>. Required packages are installed first.
+func (m *PackageManager) Install(ctx context.Context, pkg string) (*packages.Metadata, error) {
+	lock, err := lockPackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer m.unlock(ctx, lock.Unlock)
+
+	resolver := newDependencyResolver(m.gitRepo, m.langManager, false)
+	_, metadata, err := installPackageArg(ctx, m.gitRepo, m.langManager, pkg, false, resolver)
+	return metadata, err
+}
+
+// Update updates the package providing given command to the latest commit of its installed branch
+func (m *PackageManager) Update(ctx context.Context, command string) error {
+	lock, err := lockPackages(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.unlock(ctx, lock.Unlock)
+
+	return updatePackage(ctx, m.gitRepo, m.langManager, log.FromContext(ctx), command, false, false)
+}
+
+// Uninstall removes the package providing given command
+func (m *PackageManager) Uninstall(ctx context.Context, command string) error {
+	lock, err := lockPackages(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.unlock(ctx, lock.Unlock)
+
+	return uninstallPackage(ctx, m.langManager, command, log.FromContext(ctx))
+}
+
+// unlock updates the lock file and command shims after packages are modified, invalidates the package index,
+// and releases the packages lock
+func (m *PackageManager) unlock(ctx context.Context, unlock func() error) {
+	updateLockFile(ctx)
+	updateCommandShims(ctx)
+	invalidatePackageIndex(ctx)
+	if err := unlock(); err != nil {
+		log.FromContext(ctx).Errorf("Unable to release packages lock: %s", err)
+	}
+}
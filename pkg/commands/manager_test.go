@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func TestPackageManager(t *testing.T) {
+	home, err := ioutil.TempDir("", "package-manager")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(home))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+	srcPath := setupBundleHome(t, home)
+	require.NoError(t, os.MkdirAll(filepath.Join(srcPath, "cli-echo", "bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(srcPath, "cli-echo", "bin", "akamai-echo"), []byte("#!/bin/sh\n"), 0755))
+	sourceDir, err := filepath.Abs("./testdata/repo")
+	require.NoError(t, err)
+
+	m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+	m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
+	m.cfg.On("GetValue", "cli", "lock-timeout").Return("", false).Maybe()
+	ctx := terminal.Context(context.Background(), m.term)
+	ctx = config.Context(ctx, m.cfg)
+	manager := NewPackageManager(m.gitRepo, m.langManager)
+
+	installed := manager.Installed()
+	require.Len(t, installed, 2)
+	assert.Equal(t, "echo", installed[0].Commands[0].Name)
+	assert.Equal(t, "installed", installed[1].Commands[0].Name)
+
+	m.term.On("Spinner").Return(m.term)
+	m.term.On("Start", "Attempting to install package from %s...", []interface{}{sourceDir}).Return().Once()
+	m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+	m.term.On("OK").Return()
+	m.langManager.On("Install", filepath.Join(srcPath, "repo"), packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+	pkg, err := manager.Install(ctx, "./testdata/repo")
+	require.NoError(t, err)
+	assert.Equal(t, "app-1-cmd-1", pkg.Commands[0].Name)
+	assert.Len(t, manager.Installed(), 3)
+
+	m.term.On("Start", `Attempting to uninstall "echo" command...`, []interface{}(nil)).Return().Once()
+	require.NoError(t, manager.Uninstall(ctx, "echo"))
+	_, err = os.Stat(filepath.Join(srcPath, "cli-echo"))
+	assert.True(t, os.IsNotExist(err), "package should be removed")
+
+	err = manager.Uninstall(ctx, "missing")
+	assert.True(t, errors.Is(err, errNotFound))
+
+	lock, err := tools.LockFile(context.Background(), filepath.Join(home, ".akamai-cli", packagesLockName), 0)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, lock.Unlock())
+	}()
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", "lock-timeout").Return("0s", true).Once()
+	err = manager.Update(config.Context(ctx, cfg), "installed")
+	assert.True(t, errors.Is(err, errLocked))
+	cfg.AssertExpectations(t)
+
+	m.term.AssertExpectations(t)
+	m.langManager.AssertExpectations(t)
+}
@@ -0,0 +1,198 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+type quietOutputKey struct{}
+
+// WithQuietOutput marks the context so that only command results and errors are printed, without status messages, as with --quiet
+func WithQuietOutput(ctx context.Context) context.Context {
+	return context.WithValue(ctx, quietOutputKey{}, true)
+}
+
+// quietOutput returns true if status messages, such as hints and the list of commands after an install, should not be printed
+func quietOutput(ctx context.Context) bool {
+	quiet, _ := ctx.Value(quietOutputKey{}).(bool)
+	return quiet
+}
+
+// output formats of commands listing packages; any other format is a Go template
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+type (
+	// jsonPackage describes a package in machine-readable output
+	jsonPackage struct {
+		Name            string        `json:"name" yaml:"name"`
+		Title           string        `json:"title,omitempty" yaml:"title,omitempty"`
+		Version         string        `json:"version,omitempty" yaml:"version,omitempty"`
+		URL             string        `json:"url,omitempty" yaml:"url,omitempty"`
+		Author          string        `json:"author,omitempty" yaml:"author,omitempty"`
+		Tags            []string      `json:"tags,omitempty" yaml:"tags,omitempty"`
+		Installed       bool          `json:"installed" yaml:"installed"`
+		Status          string        `json:"status,omitempty" yaml:"status,omitempty"`
+		InstalledCommit string        `json:"installed-commit,omitempty" yaml:"installed-commit,omitempty"`
+		AvailableCommit string        `json:"available-commit,omitempty" yaml:"available-commit,omitempty"`
+		Reason          string        `json:"reason,omitempty" yaml:"reason,omitempty"`
+		Commands        []jsonCommand `json:"commands" yaml:"commands"`
+	}
+
+	// jsonCommand describes a package command in machine-readable output
+	jsonCommand struct {
+		Name        string   `json:"name" yaml:"name"`
+		Aliases     []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+		Version     string   `json:"version,omitempty" yaml:"version,omitempty"`
+		Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+		Deprecated  string   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+		RenamedTo   string   `json:"renamed-to,omitempty" yaml:"renamed-to,omitempty"`
+	}
+)
+
+func commandsToJSON(commands []command) []jsonCommand {
+	result := make([]jsonCommand, 0, len(commands))
+	for _, cmd := range commands {
+		result = append(result, jsonCommand{
+			Name:        cmd.Name,
+			Aliases:     cmd.Aliases,
+			Version:     cmd.Version,
+			Description: cmd.Description,
+			Deprecated:  cmd.Deprecated,
+			RenamedTo:   cmd.RenamedTo,
+		})
+	}
+	return result
+}
+
+func installedPackageToJSON(pkg subcommands) jsonPackage {
+	return jsonPackage{
+		Name:      pkg.Pkg,
+		Installed: true,
+		Commands:  commandsToJSON(pkg.Commands),
+	}
+}
+
+func remotePackageToJSON(pkg packageListPackage, installed bool) jsonPackage {
+	return jsonPackage{
+		Name:      pkg.Name,
+		Title:     pkg.Title,
+		Version:   pkg.Version,
+		URL:       pkg.URL,
+		Author:    pkg.Author,
+		Tags:      pkg.Tags,
+		Installed: installed,
+		Commands:  commandsToJSON(pkg.Commands),
+	}
+}
+
+func updateCheckToJSON(check packageUpdateCheck) jsonPackage {
+	return jsonPackage{
+		Name:            check.Name,
+		Installed:       true,
+		Status:          check.Status,
+		InstalledCommit: check.Installed,
+		AvailableCommit: check.Available,
+		Reason:          check.Reason,
+		Commands:        commandsToJSON(check.Package.Commands),
+	}
+}
+
+// writeJSON writes indented JSON representation of v to the terminal
+func writeJSON(term terminal.Terminal, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to encode output: %s", err.Error()), 1)
+	}
+	if _, err := term.Writeln(string(data)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// writeYAML writes YAML representation of v to the terminal
+func writeYAML(term terminal.Terminal, v interface{}) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return cli.Exit(color.RedString("Unable to encode output: %s", err.Error()), 1)
+	}
+	if _, err := term.Writeln(strings.TrimSuffix(buf.String(), "\n")); err != nil {
+		return err
+	}
+	return nil
+}
+
+// writeTemplate executes the Go template for each package and writes the results to the terminal, one per line
+func writeTemplate(term terminal.Terminal, format string, packages []jsonPackage) error {
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return commandFailure(errUsage, "Invalid format: %s", err.Error())
+	}
+	for _, pkg := range packages {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, pkg); err != nil {
+			return commandFailure(errUsage, "Invalid format: %s", err.Error())
+		}
+		if _, err := term.Writeln(strings.TrimSuffix(buf.String(), "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printTable displays rows in columns aligned on the widest cell, under a bold header.
+// Cells of the last column are not padded, so they may be colored.
+func printTable(term terminal.Terminal, header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row[:len(row)-1] {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	line := func(row []string) string {
+		var b strings.Builder
+		b.WriteString("  ")
+		for i, cell := range row[:len(row)-1] {
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2))
+		}
+		b.WriteString(row[len(row)-1])
+		return b.String()
+	}
+
+	term.Writeln(color.New(color.FgWhite, color.Bold).Sprint(line(header)))
+	for _, row := range rows {
+		term.Writeln(line(row))
+	}
+}
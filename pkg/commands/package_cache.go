@@ -0,0 +1,168 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// defaultPackageListTTL is how long a cached package list is used before it is fetched again
+const defaultPackageListTTL = time.Hour
+
+// packageListCache is a package list stored in the cache directory, along with the location it was fetched from
+type packageListCache struct {
+	URL     string      `json:"url"`
+	Fetched time.Time   `json:"fetched"`
+	List    packageList `json:"list"`
+}
+
+type refreshPackageListKey struct{}
+
+// withPackageListRefresh marks the context so that package lists are fetched from registries, even if a cached list is still valid
+func withPackageListRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshPackageListKey{}, true)
+}
+
+func refreshPackageList(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshPackageListKey{}).(bool)
+	return refresh
+}
+
+// parsePackageListTTL parses the cli.package-cache-ttl setting, e.g. "30m" or "24h"; 0 means the list is always fetched.
+// The default TTL is used for an empty value, and for an invalid value along with the error.
+func parsePackageListTTL(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultPackageListTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return defaultPackageListTTL, fmt.Errorf("invalid package cache TTL %q, expected a duration such as 30m or 24h", value)
+	}
+	return ttl, nil
+}
+
+// fetchRegistryPackageList returns the package list of the registry, from the cache if it has been fetched within the TTL.
+// If the registry cannot be reached, the last cached list is used regardless of its age, so that search works offline.
+func fetchRegistryPackageList(ctx context.Context, registry packageRegistry) (*packageList, error) {
+	logger := log.FromContext(ctx)
+	ttl, err := parsePackageListTTL(os.Getenv("AKAMAI_CLI_PACKAGE_CACHE_TTL"))
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+
+	cache := readPackageListCache(ctx, registry)
+	if cache != nil && !refreshPackageList(ctx) && time.Since(cache.Fetched) < ttl {
+		logger.Debugf("Using package list of registry %s cached at %s", registry.Name, cache.Fetched)
+		return &cache.List, nil
+	}
+
+	if timeout, ok := timeoutOverride(ctx); ok {
+		registry.Timeout = timeout
+	}
+	list, err := registry.Fetch(ctx)
+	if err != nil {
+		if cache == nil {
+			return nil, err
+		}
+		logger.Warnf("Unable to fetch package list of registry %s, using cached list: %s", registry.Name, err)
+		terminal.Get(ctx).WriteErrorf("%s\n", color.YellowString("Package registry %s could not be reached, using package list cached on %s",
+			registry.Name, cache.Fetched.Local().Format("2006-01-02 15:04")))
+		return &cache.List, nil
+	}
+	writePackageListCache(ctx, registry, list)
+	return list, nil
+}
+
+// cliCachePath returns the cache directory set with cli.cache-path, or the default cache directory
+func cliCachePath() (string, error) {
+	if cachePath := os.Getenv("AKAMAI_CLI_CACHE_PATH"); cachePath != "" {
+		return cachePath, nil
+	}
+	return tools.GetAkamaiCliCachePath()
+}
+
+// packageListCacheFile returns the file the package list of the registry is cached in
+func packageListCacheFile(registry packageRegistry) (string, error) {
+	cachePath, err := cliCachePath()
+	if err != nil {
+		return "", err
+	}
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, registry.Name)
+	return filepath.Join(cachePath, fmt.Sprintf("package-list-%s.json", name)), nil
+}
+
+// readPackageListCache returns the cached package list of the registry, or nil if there is none,
+// or if it was fetched from a different location
+func readPackageListCache(ctx context.Context, registry packageRegistry) *packageListCache {
+	logger := log.FromContext(ctx)
+	path, err := packageListCacheFile(registry)
+	if err != nil {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cache packageListCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		logger.Debugf("Ignoring invalid package list cache %s: %s", path, err)
+		return nil
+	}
+	if cache.URL != registry.ListURL() {
+		return nil
+	}
+	return &cache
+}
+
+// writePackageListCache stores the package list of the registry in the cache directory.
+// Failures are only logged, since the list can be fetched again.
+func writePackageListCache(ctx context.Context, registry packageRegistry, list *packageList) {
+	logger := log.FromContext(ctx)
+	path, err := packageListCacheFile(registry)
+	if err != nil {
+		logger.Debugf("Unable to cache package list: %s", err)
+		return
+	}
+	data, err := json.Marshal(packageListCache{URL: registry.ListURL(), Fetched: time.Now(), List: *list})
+	if err != nil {
+		logger.Debugf("Unable to cache package list: %s", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		logger.Debugf("Unable to cache package list: %s", err)
+		return
+	}
+	if err := tools.WriteFileAtomic(path, data, 0600); err != nil {
+		logger.Debugf("Unable to cache package list: %s", err)
+	}
+}
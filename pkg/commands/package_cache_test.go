@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestParsePackageListTTL(t *testing.T) {
+	tests := map[string]struct {
+		value     string
+		expected  time.Duration
+		withError string
+	}{
+		"default":  {value: "", expected: defaultPackageListTTL},
+		"duration": {value: "24h", expected: 24 * time.Hour},
+		"disabled": {value: "0", expected: 0},
+		"invalid": {value: "daily", expected: defaultPackageListTTL,
+			withError: `invalid package cache TTL "daily", expected a duration such as 30m or 24h`},
+		"negative": {value: "-1h", expected: defaultPackageListTTL,
+			withError: `invalid package cache TTL "-1h", expected a duration such as 30m or 24h`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ttl, err := parsePackageListTTL(test.value)
+			assert.Equal(t, test.expected, ttl)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFetchRegistryPackageListCache(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, err := w.Write([]byte(`{"packages": [{"name": "cli-fetched"}]}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+	offline := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer offline.Close()
+
+	tests := map[string]struct {
+		registryURL      string
+		cachedURL        string
+		cachedAge        time.Duration
+		ttl              string
+		refresh          bool
+		init             func(*terminal.Mock)
+		expectedPackage  string
+		expectedRequests int
+		withError        string
+	}{
+		"no cache": {
+			registryURL:      srv.URL,
+			expectedPackage:  "cli-fetched",
+			expectedRequests: 1,
+		},
+		"valid cache": {
+			registryURL:     srv.URL,
+			cachedURL:       srv.URL,
+			cachedAge:       time.Minute,
+			expectedPackage: "cli-cached",
+		},
+		"expired cache": {
+			registryURL:      srv.URL,
+			cachedURL:        srv.URL,
+			cachedAge:        2 * time.Hour,
+			expectedPackage:  "cli-fetched",
+			expectedRequests: 1,
+		},
+		"custom ttl": {
+			registryURL:     srv.URL,
+			cachedURL:       srv.URL,
+			cachedAge:       2 * time.Hour,
+			ttl:             "24h",
+			expectedPackage: "cli-cached",
+		},
+		"refresh": {
+			registryURL:      srv.URL,
+			cachedURL:        srv.URL,
+			cachedAge:        time.Minute,
+			refresh:          true,
+			expectedPackage:  "cli-fetched",
+			expectedRequests: 1,
+		},
+		"cache of a different url": {
+			registryURL:      srv.URL,
+			cachedURL:        offline.URL,
+			cachedAge:        time.Minute,
+			expectedPackage:  "cli-fetched",
+			expectedRequests: 1,
+		},
+		"registry unreachable, expired cache used": {
+			registryURL: offline.URL,
+			cachedURL:   offline.URL,
+			cachedAge:   48 * time.Hour,
+			init: func(m *terminal.Mock) {
+				fetched := time.Now().Add(-48 * time.Hour).Format("2006-01-02 15:04")
+				m.On("WriteErrorf", "%s\n", []interface{}{color.YellowString("Package registry %s could not be reached, using package list cached on %s", "akamai", fetched)}).Return().Once()
+			},
+			expectedPackage:  "cli-cached",
+			expectedRequests: 4,
+		},
+		"registry unreachable, no cache": {
+			registryURL:      offline.URL,
+			expectedRequests: 4,
+			withError:        "unable to fetch remote Package List (503 Service Unavailable)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer useTempCache(t)()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_RETRY_BACKOFF", "1ms"))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_CACHE_TTL", test.ttl))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_RETRY_BACKOFF"))
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_PACKAGE_CACHE_TTL"))
+			}()
+			registry := packageRegistry{Name: "akamai", URL: test.registryURL}
+			if test.cachedURL != "" {
+				cache := packageListCache{
+					URL:     test.cachedURL + "/cli/package-list.json",
+					Fetched: time.Now().Add(-test.cachedAge),
+					List:    packageList{Packages: []packageListPackage{{Name: "cli-cached"}}},
+				}
+				data, err := json.Marshal(cache)
+				require.NoError(t, err)
+				path, err := packageListCacheFile(registry)
+				require.NoError(t, err)
+				require.NoError(t, ioutil.WriteFile(path, data, 0600))
+			}
+			m := &terminal.Mock{}
+			if test.init != nil {
+				test.init(m)
+			}
+			ctx := terminal.Context(context.Background(), m)
+			if test.refresh {
+				ctx = withPackageListRefresh(ctx)
+			}
+			requests = 0
+
+			list, err := fetchRegistryPackageList(ctx, registry)
+			m.AssertExpectations(t)
+			assert.Equal(t, test.expectedRequests, requests)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, list.Packages, 1)
+			assert.Equal(t, test.expectedPackage, list.Packages[0].Name)
+
+			cached := readPackageListCache(ctx, registry)
+			require.NotNil(t, cached)
+			if test.expectedPackage == "cli-fetched" {
+				assert.Equal(t, "cli-fetched", cached.List.Packages[0].Name)
+			}
+		})
+	}
+}
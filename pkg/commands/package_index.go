@@ -0,0 +1,205 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	// packageIndexName is the file in the cache directory holding metadata of installed packages
+	packageIndexName = "package-index.json"
+	// packageIndexVersion is incremented when the format of the index changes, so that older indexes are rebuilt
+	packageIndexVersion = 1
+)
+
+type (
+	// packageIndex caches the cli.json metadata of installed packages, so that commands can be listed on startup
+	// without parsing every cli.json file
+	packageIndex struct {
+		Version  int              `json:"version"`
+		SrcPath  string           `json:"src-path"`
+		Packages []indexedPackage `json:"packages"`
+	}
+
+	// indexedPackage is the metadata of the package installed in Dir, along with the size and modification time
+	// of its cli.json when it was read
+	indexedPackage struct {
+		Dir      string      `json:"dir"`
+		Size     int64       `json:"size"`
+		ModTime  time.Time   `json:"mod-time"`
+		Metadata subcommands `json:"metadata"`
+	}
+)
+
+// installedPackages returns metadata of installed packages with a valid cli.json, in the order of their directories.
+// Metadata is taken from the package index, and only packages whose cli.json changed since the index was saved are read again.
+// cli.json files are checked concurrently, and the index is saved again if any package changed.
+func installedPackages(ctx context.Context) []subcommands {
+	logger := log.FromContext(ctx)
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return nil
+	}
+	dirs := getPackagePaths()
+	cached := make(map[string]indexedPackage)
+	index := readPackageIndex(ctx, srcPath)
+	if index != nil {
+		for _, pkg := range index.Packages {
+			cached[pkg.Dir] = pkg
+		}
+	}
+
+	entries := make([]*indexedPackage, len(dirs))
+	fresh := make([]bool, len(dirs))
+	runConcurrently(len(dirs), runtime.NumCPU(), func(i int) {
+		entries[i], fresh[i] = indexPackage(dirs[i], cached)
+	})
+
+	result := make([]subcommands, 0, len(dirs))
+	updated := &packageIndex{Version: packageIndexVersion, SrcPath: srcPath, Packages: make([]indexedPackage, 0, len(dirs))}
+	changed := index == nil
+	for i, entry := range entries {
+		if !fresh[i] {
+			changed = true
+		}
+		if entry == nil {
+			continue
+		}
+		result = append(result, entry.Metadata)
+		updated.Packages = append(updated.Packages, *entry)
+	}
+	if index != nil && len(updated.Packages) != len(index.Packages) {
+		// packages have been removed
+		changed = true
+	}
+	if changed {
+		logger.Debug("Package index is out of date, saving it")
+		writePackageIndex(ctx, updated)
+	}
+	return result
+}
+
+// indexPackage returns the indexed metadata of the package in given directory if its cli.json has not changed,
+// and reads it again otherwise. nil is returned for packages without a valid cli.json.
+// The returned flag is false if the index needs to be saved again.
+func indexPackage(dir string, cached map[string]indexedPackage) (*indexedPackage, bool) {
+	name := filepath.Base(dir)
+	entry, found := cached[name]
+	info, err := os.Stat(filepath.Join(dir, packages.MetadataFile))
+	if err != nil {
+		return nil, !found
+	}
+	if found && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+		return &entry, true
+	}
+	pkg, err := readPackage(dir)
+	if err != nil {
+		// invalid packages are not indexed, and read again each time
+		return nil, !found
+	}
+	return &indexedPackage{Dir: name, Size: info.Size(), ModTime: info.ModTime(), Metadata: pkg}, false
+}
+
+// packageIndexFile returns location of the package index in the cache directory
+func packageIndexFile() (string, error) {
+	cachePath, err := cliCachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cachePath, packageIndexName), nil
+}
+
+// readPackageIndex returns the package index, or nil if there is none, or if it was saved by another version
+// of Akamai CLI or for packages installed in another directory
+func readPackageIndex(ctx context.Context, srcPath string) *packageIndex {
+	logger := log.FromContext(ctx)
+	path, err := packageIndexFile()
+	if err != nil {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var index packageIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		logger.Debugf("Ignoring invalid package index %s: %s", path, err)
+		return nil
+	}
+	if index.Version != packageIndexVersion || index.SrcPath != srcPath {
+		return nil
+	}
+	return &index
+}
+
+// writePackageIndex saves the package index in the cache directory. The index is replaced at once,
+// so that concurrent instances never read a partially written index. Failures are only logged, since the index is rebuilt.
+func writePackageIndex(ctx context.Context, index *packageIndex) {
+	logger := log.FromContext(ctx)
+	path, err := packageIndexFile()
+	if err != nil {
+		logger.Debugf("Unable to save package index: %s", err)
+		return
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		logger.Debugf("Unable to save package index: %s", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		logger.Debugf("Unable to save package index: %s", err)
+		return
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+packageIndexName+"-*")
+	if err != nil {
+		logger.Debugf("Unable to save package index: %s", err)
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		logger.Debugf("Unable to save package index: %s", err)
+		if err := os.Remove(f.Name()); err != nil && !os.IsNotExist(err) {
+			logger.Debugf("Unable to remove %s: %s", f.Name(), err)
+		}
+	}
+}
+
+// invalidatePackageIndex removes the package index once installed packages are modified, so that it is rebuilt on next start
+func invalidatePackageIndex(ctx context.Context) {
+	path, err := packageIndexFile()
+	if err != nil {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.FromContext(ctx).Debugf("Unable to remove package index: %s", err)
+	}
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstalledPackages(t *testing.T) {
+	home, err := ioutil.TempDir("", "package-index")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(home))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+	defer useTempCache(t)()
+	srcPath := filepath.Join(home, ".akamai-cli", "src")
+	writeManifest := func(dir, manifest string, modTime time.Time) {
+		require.NoError(t, os.MkdirAll(filepath.Join(srcPath, dir), 0755))
+		path := filepath.Join(srcPath, dir, "cli.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(manifest), 0644))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	commandNames := func(pkgs []subcommands) []string {
+		names := make([]string, 0)
+		for _, pkg := range pkgs {
+			for _, cmd := range pkg.Commands {
+				names = append(names, cmd.Name+" "+cmd.Version)
+			}
+		}
+		return names
+	}
+	indexPath := filepath.Join(os.Getenv("AKAMAI_CLI_CACHE_PATH"), packageIndexName)
+	modTime := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	writeManifest("cli-echo", `{"commands": [{"name": "Echo", "version": "1.0.0"}]}`, modTime)
+	writeManifest("cli-invalid", `{"commands": [`, modTime)
+	writeManifest("cli-test", `{"commands": [{"name": "test", "version": "2.0.0"}]}`, modTime)
+	ctx := context.Background()
+
+	t.Run("index is built", func(t *testing.T) {
+		assert.Equal(t, []string{"echo 1.0.0", "test 2.0.0"}, commandNames(installedPackages(ctx)))
+		data, err := ioutil.ReadFile(indexPath)
+		require.NoError(t, err)
+		var index packageIndex
+		require.NoError(t, json.Unmarshal(data, &index))
+		assert.Equal(t, packageIndexVersion, index.Version)
+		assert.Equal(t, srcPath, index.SrcPath)
+		require.Len(t, index.Packages, 2)
+		assert.Equal(t, "cli-echo", index.Packages[0].Dir)
+		assert.Equal(t, "echo", index.Packages[0].Metadata.Pkg)
+
+		// indexed metadata is used as long as cli.json is unchanged
+		index.Packages[0].Metadata.Commands[0].Version = "1.0.0-indexed"
+		data, err = json.Marshal(index)
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(indexPath, data, 0600))
+		assert.Equal(t, []string{"echo 1.0.0-indexed", "test 2.0.0"}, commandNames(installedPackages(ctx)))
+	})
+
+	t.Run("changed package is read again", func(t *testing.T) {
+		writeManifest("cli-echo", `{"commands": [{"name": "echo", "version": "1.1.0"}]}`, modTime.Add(time.Hour))
+		assert.Equal(t, []string{"echo 1.1.0", "test 2.0.0"}, commandNames(installedPackages(ctx)))
+	})
+
+	t.Run("removed package is dropped", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(filepath.Join(srcPath, "cli-test")))
+		assert.Equal(t, []string{"echo 1.1.0"}, commandNames(installedPackages(ctx)))
+		index := readPackageIndex(ctx, srcPath)
+		require.NotNil(t, index)
+		assert.Len(t, index.Packages, 1)
+	})
+
+	t.Run("index of another directory is ignored", func(t *testing.T) {
+		assert.Nil(t, readPackageIndex(ctx, filepath.Join(home, "other")))
+	})
+
+	t.Run("invalidated index is rebuilt", func(t *testing.T) {
+		invalidatePackageIndex(ctx)
+		_, err := os.Stat(indexPath)
+		assert.True(t, os.IsNotExist(err))
+		assert.Equal(t, []string{"echo 1.1.0"}, commandNames(installedPackages(ctx)))
+		assert.NotNil(t, readPackageIndex(ctx, srcPath))
+	})
+}
@@ -0,0 +1,277 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// types of sources packages are installed from, recorded in install metadata so that update knows how to refresh them.
+// Packages without a recorded source type are installed from git repositories.
+const (
+	sourceGit       = "git"
+	sourceDirectory = "directory"
+	sourceArchive   = "archive"
+	sourceMercurial = "hg"
+)
+
+// mercurialPrefix marks install arguments naming Mercurial repositories, such as hg+https://hg.example.com/cli-tool
+const mercurialPrefix = "hg+"
+
+// hgCommand is the Mercurial executable, it can be replaced in tests
+var hgCommand = "hg"
+
+// packageSource is where a package installed without git comes from: a local directory, a local archive or one downloaded
+// over HTTP(S), or a Mercurial repository
+type packageSource struct {
+	Type     string
+	Location string
+	// Ref is the revision a Mercurial repository is checked out at, the tip of its default branch if empty
+	Ref string
+}
+
+// parsePackageSource returns the source of a package given as install argument, unless it is a git repository
+func parsePackageSource(arg string) (packageSource, bool) {
+	if strings.HasPrefix(arg, mercurialPrefix) {
+		repo, ref := splitPackageRef(strings.TrimPrefix(arg, mercurialPrefix))
+		return packageSource{Type: sourceMercurial, Location: repo, Ref: ref}, true
+	}
+	if isRemoteArchive(arg) {
+		return packageSource{Type: sourceArchive, Location: arg}, true
+	}
+	if location, ok := localPackageSource(arg); ok {
+		if isArchive(location) {
+			return packageSource{Type: sourceArchive, Location: location}, true
+		}
+		return packageSource{Type: sourceDirectory, Location: location}, true
+	}
+	return packageSource{}, false
+}
+
+// installedSource returns the source of a package according to its install metadata, false for packages installed from git.
+// The type of sources recorded before it was, which are local directories and archives, is determined from their location.
+func installedSource(meta *installMetadata) (packageSource, bool) {
+	if meta == nil || meta.Source == "" || meta.SourceType == sourceGit {
+		return packageSource{}, false
+	}
+	source := packageSource{Type: meta.SourceType, Location: meta.Source, Ref: meta.Ref}
+	if source.Type == "" {
+		source.Type = sourceDirectory
+		if isArchive(meta.Source) {
+			source.Type = sourceArchive
+		}
+	}
+	return source, true
+}
+
+// String returns the source as it is given to install
+func (s packageSource) String() string {
+	switch {
+	case s.Type == sourceMercurial && s.Ref != "":
+		return mercurialPrefix + s.Location + "@" + s.Ref
+	case s.Type == sourceMercurial:
+		return mercurialPrefix + s.Location
+	}
+	return s.Location
+}
+
+// setRevision records the revision of the source a package was staged from in its install metadata
+func (s packageSource) setRevision(meta *installMetadata, revision string) {
+	if s.Type == sourceMercurial {
+		meta.Commit = revision
+	} else {
+		meta.Checksum = revision
+	}
+}
+
+// revision returns the revision of the source recorded in install metadata, see setRevision
+func (s packageSource) revision(meta *installMetadata) string {
+	if s.Type == sourceMercurial {
+		return meta.Commit
+	}
+	return meta.Checksum
+}
+
+// isLocal returns true if the source is a directory or an archive on the local filesystem
+func (s packageSource) isLocal() bool {
+	return s.Type == sourceDirectory || (s.Type == sourceArchive && !isRemoteArchive(s.Location))
+}
+
+// stage copies, extracts or clones the package to the staging area, and returns the staged directory along with
+// the revision of the source: the SHA-256 checksum of archives or the changeset of Mercurial repositories
+func (s packageSource) stage(ctx context.Context) (string, string, error) {
+	switch {
+	case s.Type == sourceMercurial:
+		return stageMercurial(ctx, s.Location, s.Ref)
+	case s.Type == sourceArchive && isRemoteArchive(s.Location):
+		return stageRemoteArchive(ctx, s.Location)
+	case s.Type == sourceArchive:
+		checksum, err := fileChecksum(s.Location)
+		if err != nil {
+			return "", "", err
+		}
+		stagedDir, err := stageArchive(s.Location)
+		return stagedDir, checksum, err
+	}
+	stagedDir, err := stagePackage(s.Location)
+	return stagedDir, "", err
+}
+
+// isArchive returns true if the file name has the extension of a package archive: a gzipped tarball or a zip archive
+func isArchive(name string) bool {
+	return tools.IsTarGz(name) || tools.IsZip(name)
+}
+
+// isRemoteArchive returns true if arg is an HTTP(S) URL of a package archive, such as the asset of a release
+func isRemoteArchive(arg string) bool {
+	u, err := url.Parse(arg)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return false
+	}
+	return isArchive(u.Path)
+}
+
+// stageRemoteArchive downloads a package archive and extracts it to the staging area.
+// The archive is named after the last element of the URL path, so that the package is named after it.
+func stageRemoteArchive(ctx context.Context, archiveURL string) (_, _ string, e error) {
+	logger := log.FromContext(ctx)
+	u, err := url.Parse(archiveURL)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme == "http" {
+		logger.Warnf("Package archive %s is downloaded over plain HTTP", archiveURL)
+	}
+	tmpDir, err := ioutil.TempDir("", "akamai-archive")
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			logger.Errorf("Unable to remove downloaded archive: %s", err.Error())
+		}
+	}()
+
+	archive := filepath.Join(tmpDir, path.Base(u.Path))
+	var checksum string
+	err = runWithTimeout(ctx, "download", downloadTimeout(ctx), func(ctx context.Context) error {
+		checksum, err = downloadArchive(ctx, archiveURL, archive)
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	stagedDir, err := stageArchive(archive)
+	return stagedDir, checksum, err
+}
+
+// downloadArchive saves the archive at given URL to dst, and returns its SHA-256 checksum
+func downloadArchive(ctx context.Context, archiveURL, dst string) (string, error) {
+	res, err := tools.HTTPGet(ctx, archiveURL, 0)
+	if err != nil {
+		return "", fmt.Errorf("unable to download %s: %w", archiveURL, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to download %s: %s", archiveURL, res.Status)
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hash), res.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to download %s: %w", archiveURL, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// stageMercurial clones a Mercurial repository to the staging area, at given revision if set, and returns the
+// staged directory along with the checked out changeset
+func stageMercurial(ctx context.Context, repo, ref string) (_, _ string, e error) {
+	if _, err := lookPath(hgCommand); err != nil {
+		return "", "", fmt.Errorf("Mercurial is required to install packages from %s, but %s was not found in PATH", repo, hgCommand)
+	}
+	name := packageDirName(repo)
+	tmpDir, err := newStagingDir(name)
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if e == nil {
+			return
+		}
+		if err := os.RemoveAll(tmpDir); err != nil {
+			e = fmt.Errorf("%s; unable to clean up staging directory: %s", e, err)
+		}
+	}()
+
+	stagedDir := filepath.Join(tmpDir, name)
+	args := []string{"clone", "--noninteractive"}
+	if ref != "" {
+		args = append(args, "--updaterev", ref)
+	}
+	args = append(args, "--", repo, stagedDir)
+	err = runWithTimeout(ctx, "hg clone", gitTimeout(ctx), func(ctx context.Context) error {
+		_, err := runMercurial(ctx, args...)
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	changeset, err := runMercurial(ctx, "log", "--repository", stagedDir, "--rev", ".", "--template", "{node}")
+	if err != nil {
+		return "", "", err
+	}
+	return stagedDir, changeset, nil
+}
+
+// runMercurial runs hg with given arguments and returns its output. Errors include what hg wrote to stderr.
+func runMercurial(ctx context.Context, args ...string) (string, error) {
+	log.FromContext(ctx).Debugf("Running %s %s", hgCommand, strings.Join(args, " "))
+	cmd := exec.Command(hgCommand, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	// prompts for credentials would block
+	cmd.Env = append(os.Environ(), "HGPLAIN=1")
+	if err := tools.RunProcessGroup(ctx, cmd); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s %s: %s", hgCommand, args[0], msg)
+		}
+		return "", fmt.Errorf("%s %s: %w", hgCommand, args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePackageSource(t *testing.T) {
+	tests := map[string]struct {
+		arg      string
+		expected packageSource
+		ok       bool
+	}{
+		"local directory": {
+			arg:      "./cli-local",
+			expected: packageSource{Type: sourceDirectory, Location: "./cli-local"},
+			ok:       true,
+		},
+		"local archive": {
+			arg:      "file:///tmp/cli-local.zip",
+			expected: packageSource{Type: sourceArchive, Location: "/tmp/cli-local.zip"},
+			ok:       true,
+		},
+		"archive URL": {
+			arg:      "https://example.com/releases/cli-remote.tar.gz?token=abc",
+			expected: packageSource{Type: sourceArchive, Location: "https://example.com/releases/cli-remote.tar.gz?token=abc"},
+			ok:       true,
+		},
+		"mercurial repository": {
+			arg:      "hg+https://hg.example.com/cli-tool",
+			expected: packageSource{Type: sourceMercurial, Location: "https://hg.example.com/cli-tool"},
+			ok:       true,
+		},
+		"mercurial repository at revision": {
+			arg:      "hg+ssh://user@hg.example.com/cli-tool@stable",
+			expected: packageSource{Type: sourceMercurial, Location: "ssh://user@hg.example.com/cli-tool", Ref: "stable"},
+			ok:       true,
+		},
+		"git repository URL": {
+			arg: "https://github.com/akamai/cli-echo.git",
+		},
+		"package name": {
+			arg: "echo",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			source, ok := parsePackageSource(test.arg)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, source)
+			if ok && !source.isLocal() {
+				assert.Equal(t, test.arg, source.String())
+			}
+		})
+	}
+}
+
+func TestInstalledSource(t *testing.T) {
+	tests := map[string]struct {
+		meta     *installMetadata
+		expected packageSource
+		ok       bool
+	}{
+		"no metadata": {},
+		"git repository": {
+			meta: &installMetadata{Repo: "https://github.com/akamai/cli-echo.git", Commit: "abc"},
+		},
+		"mercurial repository": {
+			meta:     &installMetadata{Source: "https://hg.example.com/cli-tool", SourceType: sourceMercurial, Ref: "stable", Commit: "abc"},
+			expected: packageSource{Type: sourceMercurial, Location: "https://hg.example.com/cli-tool", Ref: "stable"},
+			ok:       true,
+		},
+		"directory installed by a previous version": {
+			meta:     &installMetadata{Source: "/tmp/cli-local"},
+			expected: packageSource{Type: sourceDirectory, Location: "/tmp/cli-local"},
+			ok:       true,
+		},
+		"archive installed by a previous version": {
+			meta:     &installMetadata{Source: "/tmp/cli-local.tgz"},
+			expected: packageSource{Type: sourceArchive, Location: "/tmp/cli-local.tgz"},
+			ok:       true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			source, ok := installedSource(test.meta)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, source)
+		})
+	}
+}
+
+func TestStageMercurial(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake hg is a shell script")
+	}
+	repo, err := filepath.Abs("./testdata/repo")
+	require.NoError(t, err)
+	tmpDir, err := ioutil.TempDir("", "akamai-hg")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+	// clones by copying the repository, and reports the revision it was asked for as changeset
+	script := `#!/bin/sh
+case "$1" in
+clone)
+	rev=tip
+	if [ "$3" = "--updaterev" ]; then rev=$4; shift 2; fi
+	if [ "$3" != "--" ]; then echo "hg: options not ended before the source" >&2; exit 255; fi
+	shift
+	cp -R "$3" "$4" && echo "$rev" > "$4/.hg-rev" ;;
+log)
+	if [ ! -f "$3/.hg-rev" ]; then echo "abort: repository $3 not found" >&2; exit 255; fi
+	cat "$3/.hg-rev" ;;
+esac
+`
+	hg := filepath.Join(tmpDir, "hg")
+	require.NoError(t, ioutil.WriteFile(hg, []byte(script), 0755))
+	hgCommand = hg
+	defer func() {
+		hgCommand = "hg"
+	}()
+
+	tests := map[string]struct {
+		ref       string
+		found     bool
+		changeset string
+		withError string
+	}{
+		"default branch": {
+			found:     true,
+			changeset: "tip",
+		},
+		"revision": {
+			ref:       "stable",
+			found:     true,
+			changeset: "stable",
+		},
+		"mercurial not installed": {
+			withError: "Mercurial is required to install packages from " + repo,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", tmpDir))
+			defer func() {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			found := map[string]string{}
+			if test.found {
+				found[hg] = hg
+			}
+			defer mockLookPath(found)()
+
+			stagedDir, changeset, err := stageMercurial(context.Background(), repo, test.ref)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, discardStagedPackage(stagedDir))
+			}()
+			assert.Equal(t, test.changeset, changeset)
+			assert.Equal(t, "repo", filepath.Base(stagedDir))
+			assert.NoError(t, validatePackage(stagedDir))
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+)
+
+type purgeKey struct{}
+
+// withPurge marks the context so that uninstalled packages are removed along with their config entries and container image
+func withPurge(ctx context.Context) context.Context {
+	return context.WithValue(ctx, purgeKey{}, true)
+}
+
+func purgeEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(purgeKey{}).(bool)
+	return enabled
+}
+
+type (
+	// packageLeftovers is what an installed package leaves outside of its directory
+	packageLeftovers struct {
+		commands []string
+		image    string
+	}
+
+	// configKey is a setting of the config file
+	configKey struct {
+		section string
+		key     string
+	}
+)
+
+// findPackageLeftovers reads commands and the container image of the package in given directory, before it is removed
+func findPackageLeftovers(dir string) packageLeftovers {
+	var leftovers packageLeftovers
+	if pkg, err := readPackage(dir); err == nil {
+		for _, cmd := range pkg.Commands {
+			leftovers.commands = append(leftovers.commands, strings.ToLower(cmd.Name))
+			for _, alias := range cmd.Aliases {
+				leftovers.commands = append(leftovers.commands, strings.ToLower(alias))
+			}
+		}
+	}
+	if meta, err := readInstallMetadata(dir); err == nil && meta != nil {
+		leftovers.image = meta.Image
+	}
+	return leftovers
+}
+
+// packageConfigEntries returns config sections named after commands of the package, which are exported to the commands
+// as AKAMAI_<COMMAND>_<KEY> variables, along with the same settings in profiles and aliases running the commands
+func packageConfigEntries(cfg config.Config, commands []string) ([]string, []configKey) {
+	isPackageCommand := make(map[string]bool, len(commands))
+	for _, cmd := range commands {
+		isPackageCommand[cmd] = true
+	}
+
+	var sections []string
+	var keys []configKey
+	for section, values := range cfg.Values() {
+		switch {
+		case isPackageCommand[section]:
+			sections = append(sections, section)
+		case section == config.AliasesSection:
+			for name, command := range values {
+				if words, err := splitAliasCommand(command); err == nil && len(words) > 0 && isPackageCommand[strings.ToLower(words[0])] {
+					keys = append(keys, configKey{section: section, key: name})
+				}
+			}
+		default:
+			if _, ok := config.ProfileName(section); !ok {
+				continue
+			}
+			for key := range values {
+				if path := strings.SplitN(key, ".", 2); len(path) == 2 && isPackageCommand[path[0]] {
+					keys = append(keys, configKey{section: section, key: key})
+				}
+			}
+		}
+	}
+	sort.Strings(sections)
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].section != keys[j].section {
+			return keys[i].section < keys[j].section
+		}
+		return keys[i].key < keys[j].key
+	})
+	return sections, keys
+}
+
+// purgePackage removes config entries and the container image of an uninstalled package.
+// The package is already removed at this point, so failing to remove the image is only reported as a warning.
+func purgePackage(ctx context.Context, leftovers packageLeftovers) error {
+	logger := log.FromContext(ctx)
+	cfg := config.Get(ctx)
+	sections, keys := packageConfigEntries(cfg, leftovers.commands)
+	values := cfg.Values()
+	for _, section := range sections {
+		// secrets are removed first, so that none is left in the keyring
+		for key := range values[section] {
+			if config.IsSecretKey(section, key) {
+				keys = append(keys, configKey{section: section, key: key})
+			}
+		}
+	}
+	for _, key := range keys {
+		logger.Debugf("Removing config key: %s.%s", key.section, key.key)
+		if config.IsSecretKey(key.section, key.key) {
+			if err := cfg.UnsetSecret(key.section, key.key); err != nil {
+				return fmt.Errorf("unable to remove secret %s.%s: %w", key.section, key.key, err)
+			}
+			continue
+		}
+		cfg.UnsetValue(key.section, key.key)
+	}
+	for _, section := range sections {
+		logger.Debugf("Removing config section: %s", section)
+		cfg.RemoveSection(section)
+	}
+	if len(sections) > 0 || len(keys) > 0 {
+		if err := cfg.Save(ctx); err != nil {
+			return fmt.Errorf("unable to save config: %w", err)
+		}
+	}
+
+	if leftovers.image != "" {
+		if err := removePackageImage(ctx, leftovers.image); err != nil {
+			logger.Warnf("Unable to remove image %s: %s", leftovers.image, err)
+		}
+	}
+	return nil
+}
+
+// removePackageImage removes the container image a package was installed with
+func removePackageImage(ctx context.Context, image string) error {
+	logger := log.FromContext(ctx)
+	engine, err := containerEngine()
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, engine, "rmi", image)
+	logger.Debugf("Executing command: %s", strings.Join(cmd.Args, " "))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if len(output) > 0 {
+			return fmt.Errorf("%s", lastLine(output))
+		}
+		return err
+	}
+	return nil
+}
+
+// purgeBuildCache removes package manager caches shared by package builds, once no package is left to use them
+func purgeBuildCache(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	dir, err := buildCacheDir()
+	if err != nil {
+		return err
+	}
+	for _, cache := range packages.BuildCaches {
+		path := filepath.Join(dir, cache.Name)
+		logger.Debugf("Removing build cache: %s", path)
+		if err := removeCacheDir(path); err != nil {
+			return fmt.Errorf("unable to clean %s cache: %w", cache.Name, err)
+		}
+	}
+	return nil
+}
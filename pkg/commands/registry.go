@@ -0,0 +1,68 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/registry"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+type (
+	// packageRegistry is a repository publishing a package list, used by search, list and install commands
+	packageRegistry = registry.Registry
+	// packageList is the list of packages published by registries
+	packageList = registry.PackageList
+	// packageListPackage is a package published by a registry
+	packageListPackage = registry.Package
+)
+
+// resolvePackageRepo returns the repository of a package given as install argument.
+// If registries are configured, package names are looked up in their package lists first,
+// so that packages of private registries and mirrors can be installed by name.
+func resolvePackageRepo(ctx context.Context, repo string) string {
+	if !isPackageName(repo) || !registry.HasCustomRegistries(config.Get(ctx)) {
+		return tools.Githubize(repo)
+	}
+	list, err := fetchPackageList(ctx)
+	if err != nil {
+		log.FromContext(ctx).Debugf("Unable to resolve %s using registries: %s", repo, err)
+		return tools.Githubize(repo)
+	}
+	name := strings.TrimPrefix(repo, "cli-")
+	for _, pkg := range list.Packages {
+		if strings.TrimPrefix(pkg.Name, "cli-") == name && pkg.URL != "" {
+			return tools.Githubize(pkg.URL)
+		}
+	}
+	return tools.Githubize(repo)
+}
+
+// isPackageName returns true if repo is a bare package name, rather than a GitHub repository or a URL
+func isPackageName(repo string) bool {
+	if strings.Contains(repo, "/") || strings.HasSuffix(repo, ".git") {
+		return false
+	}
+	for _, prefix := range []string{"http", "ssh", "file://"} {
+		if strings.HasPrefix(repo, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func TestFetchPackageListRegistries(t *testing.T) {
+	official := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/cli/package-list.json", r.URL.String())
+		assert.Empty(t, r.Header.Get("Authorization"))
+		_, err := w.Write([]byte(`{"packages": [
+			{"name": "cli-echo", "title": "Echo", "url": "https://github.com/akamai/cli-echo"},
+			{"name": "cli-purge", "title": "Purge", "url": "https://github.com/akamai/cli-purge"}
+		]}`))
+		assert.NoError(t, err)
+	}))
+	defer official.Close()
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/packages.json", r.URL.String())
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, err := w.Write([]byte(`{"packages": [
+			{"name": "cli-echo", "title": "Internal echo", "url": "https://git.example.com/tools/cli-echo.git"},
+			{"name": "cli-internal", "title": "Internal", "url": "https://git.example.com/tools/cli-internal.git"}
+		]}`))
+		assert.NoError(t, err)
+	}))
+	defer internal.Close()
+
+	tests := map[string]struct {
+		givenValues   map[string]string
+		init          func(*terminal.Mock)
+		expected      []packageListPackage
+		expectedRepos map[string]string
+		withError     string
+	}{
+		"packages of higher priority registry take precedence": {
+			givenValues: map[string]string{
+				"akamai.url":        official.URL,
+				"internal.url":      internal.URL + "/packages.json",
+				"internal.priority": "1",
+				"internal.header":   "Authorization: Bearer token",
+			},
+			init: func(m *terminal.Mock) {},
+			expected: []packageListPackage{
+				{Registry: "internal", Name: "cli-echo", Title: "Internal echo", URL: "https://git.example.com/tools/cli-echo.git"},
+				{Registry: "internal", Name: "cli-internal", Title: "Internal", URL: "https://git.example.com/tools/cli-internal.git"},
+				{Registry: "akamai", Name: "cli-purge", Title: "Purge", URL: "https://github.com/akamai/cli-purge"},
+			},
+			expectedRepos: map[string]string{
+				"echo":            "https://git.example.com/tools/cli-echo.git",
+				"cli-internal":    "https://git.example.com/tools/cli-internal.git",
+				"purge":           "https://github.com/akamai/cli-purge",
+				"unknown":         "https://github.com/akamai/cli-unknown.git",
+				"akamai/cli-echo": "https://github.com/akamai/cli-echo.git",
+			},
+		},
+		"unreachable registry is skipped": {
+			givenValues: map[string]string{
+				"akamai.url":   official.URL,
+				"internal.url": internal.URL + "/packages.json",
+			},
+			init: func(m *terminal.Mock) {
+				m.On("WriteErrorf", "%s\n", []interface{}{color.YellowString("Some package registries could not be reached, results may be incomplete")}).Return().Once()
+			},
+			expected: []packageListPackage{
+				{Registry: "akamai", Name: "cli-echo", Title: "Echo", URL: "https://github.com/akamai/cli-echo"},
+				{Registry: "akamai", Name: "cli-purge", Title: "Purge", URL: "https://github.com/akamai/cli-purge"},
+			},
+		},
+		"no registry can be reached": {
+			givenValues: map[string]string{
+				"akamai.disabled": "true",
+				"internal.url":    internal.URL + "/packages.json",
+			},
+			init:      func(m *terminal.Mock) {},
+			withError: "unable to fetch remote Package List (401 Unauthorized)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer useTempCache(t)()
+			// registries are configured with the official registry URL, which AKAMAI_CLI_PACKAGE_REPO would override
+			require.NoError(t, os.Unsetenv("AKAMAI_CLI_PACKAGE_REPO"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			m.cfg.On("Values").Return(map[string]map[string]string{config.RegistriesSection: test.givenValues})
+			ctx := terminal.Context(context.Background(), m.term)
+			ctx = config.Context(ctx, m.cfg)
+			test.init(m.term)
+
+			list, err := fetchPackageList(ctx)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, list.Packages)
+			for repo, expected := range test.expectedRepos {
+				assert.Equal(t, expected, resolvePackageRepo(ctx, repo), fmt.Sprintf("resolving %s", repo))
+			}
+			m.term.AssertExpectations(t)
+		})
+	}
+}
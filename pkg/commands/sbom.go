@@ -0,0 +1,277 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/version"
+)
+
+// package URL types of detected language dependencies, see https://github.com/package-url/purl-spec
+const (
+	purlTypeGolang = "golang"
+	purlTypePypi   = "pypi"
+	purlTypeNpm    = "npm"
+)
+
+type (
+	// sbomPackage is an installed package, as described in a software bill of materials
+	sbomPackage struct {
+		Name         string
+		Repo         string
+		Source       string
+		Commit       string
+		Language     string
+		Binaries     map[string]string
+		Dependencies []sbomDependency
+	}
+
+	// sbomDependency is a language dependency declared by a package
+	sbomDependency struct {
+		Type    string
+		Name    string
+		Version string
+	}
+)
+
+// purl returns the package URL of the dependency
+func (d sbomDependency) purl() string {
+	name := d.Name
+	if d.Type == purlTypePypi {
+		// PyPI names are case insensitive and normalized to lower case
+		name = strings.ToLower(name)
+	}
+	name = escapePurlName(name)
+	if d.Type == purlTypeNpm && strings.HasPrefix(name, "@") {
+		// the namespace of scoped npm packages starts with an encoded "@"
+		name = "%40" + name[1:]
+	}
+	purl := fmt.Sprintf("pkg:%s/%s", d.Type, name)
+	if d.Version != "" {
+		purl += "@" + url.PathEscape(d.Version)
+	}
+	return purl
+}
+
+// escapePurlName escapes the segments of a package name, keeping the namespace separators
+func escapePurlName(name string) string {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// purl returns the package URL of an installed package hosted on GitHub, or an empty string
+func (p sbomPackage) purl() string {
+	repo := strings.TrimSuffix(p.Repo, ".git")
+	for _, prefix := range []string{"https://github.com/", "git@github.com:"} {
+		if strings.HasPrefix(repo, prefix) {
+			purl := "pkg:github/" + strings.ToLower(strings.TrimPrefix(repo, prefix))
+			if p.Commit != "" {
+				purl += "@" + p.Commit
+			}
+			return purl
+		}
+	}
+	return ""
+}
+
+// cliPurl returns the package URL of Akamai CLI itself
+func cliPurl() string {
+	return "pkg:github/akamai/cli@v" + version.Version
+}
+
+// collectSBOMPackages describes all installed packages, sorted by name
+func collectSBOMPackages(gitRepo git.Repository) ([]sbomPackage, error) {
+	result := make([]sbomPackage, 0)
+	for _, dir := range getPackagePaths() {
+		info, err := readPackageInfo(gitRepo, dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(dir), err)
+		}
+		binaries, err := binaryChecksums(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", info.Name, err)
+		}
+		deps, err := detectDependencies(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", info.Name, err)
+		}
+		result = append(result, sbomPackage{
+			Name:         info.Name,
+			Repo:         info.Repo,
+			Source:       info.Source,
+			Commit:       info.Commit,
+			Language:     info.Language,
+			Binaries:     binaries,
+			Dependencies: deps,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// detectDependencies reads language dependencies declared in go.mod, requirements.txt and package.json of the package
+// in given directory. Versions of npm dependencies are read from node_modules, as package.json declares version ranges.
+func detectDependencies(dir string) ([]sbomDependency, error) {
+	var deps []sbomDependency
+	for _, detect := range []func(string) ([]sbomDependency, error){goModDependencies, requirementsDependencies, npmDependencies} {
+		found, err := detect(dir)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, found...)
+	}
+	return deps, nil
+}
+
+func goModDependencies(dir string) ([]sbomDependency, error) {
+	lines, err := readLines(filepath.Join(dir, "go.mod"))
+	if err != nil || lines == nil {
+		return nil, err
+	}
+
+	var deps []sbomDependency
+	inBlock := false
+	for _, line := range lines {
+		if i := strings.Index(line, "//"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+		if len(fields) >= 2 {
+			deps = append(deps, sbomDependency{Type: purlTypeGolang, Name: fields[0], Version: fields[1]})
+		}
+	}
+	return deps, nil
+}
+
+func requirementsDependencies(dir string) ([]sbomDependency, error) {
+	lines, err := readLines(filepath.Join(dir, "requirements.txt"))
+	if err != nil || lines == nil {
+		return nil, err
+	}
+
+	var deps []sbomDependency
+	for _, line := range lines {
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		// environment markers
+		if i := strings.Index(line, ";"); i != -1 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		// options, such as -r other.txt or -e ., and direct references
+		if line == "" || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		name, ver := line, ""
+		if i := strings.IndexAny(line, "=<>!~ ["); i != -1 {
+			name = line[:i]
+			spec := strings.TrimSpace(line[i:])
+			// extras, such as requests[security]
+			if strings.HasPrefix(spec, "[") {
+				if j := strings.Index(spec, "]"); j != -1 {
+					spec = strings.TrimSpace(spec[j+1:])
+				}
+			}
+			if strings.HasPrefix(spec, "==") && !strings.ContainsAny(spec[2:], ",*") {
+				ver = strings.TrimSpace(spec[2:])
+			}
+		}
+		deps = append(deps, sbomDependency{Type: purlTypePypi, Name: name, Version: ver})
+	}
+	return deps, nil
+}
+
+func npmDependencies(dir string) ([]sbomDependency, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var pkg struct {
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("invalid package.json: %w", err)
+	}
+
+	names := make([]string, 0, len(pkg.Dependencies))
+	for name := range pkg.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	deps := make([]sbomDependency, 0, len(names))
+	for _, name := range names {
+		var installed struct {
+			Version string `json:"version"`
+		}
+		if data, err := ioutil.ReadFile(filepath.Join(dir, "node_modules", filepath.FromSlash(name), "package.json")); err == nil {
+			_ = json.Unmarshal(data, &installed)
+		}
+		deps = append(deps, sbomDependency{Type: purlTypeNpm, Name: name, Version: installed.Version})
+	}
+	return deps, nil
+}
+
+// readLines returns lines of given file, nil if it does not exist
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
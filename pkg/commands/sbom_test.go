@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDependencies(t *testing.T) {
+	tests := map[string]struct {
+		files     map[string]string
+		expected  []sbomDependency
+		withError string
+	}{
+		"go modules": {
+			files: map[string]string{"go.mod": `module github.com/akamai/cli-test
+
+go 1.14
+
+require github.com/urfave/cli/v2 v2.3.0
+
+require (
+	github.com/apex/log v1.9.0
+	// a comment
+	golang.org/x/sys v0.0.0-20210331175145-43e1dd70ce54 // indirect
+)
+
+replace github.com/apex/log => ../log
+`},
+			expected: []sbomDependency{
+				{Type: purlTypeGolang, Name: "github.com/urfave/cli/v2", Version: "v2.3.0"},
+				{Type: purlTypeGolang, Name: "github.com/apex/log", Version: "v1.9.0"},
+				{Type: purlTypeGolang, Name: "golang.org/x/sys", Version: "v0.0.0-20210331175145-43e1dd70ce54"},
+			},
+		},
+		"python requirements": {
+			files: map[string]string{"requirements.txt": `# comment
+requests==2.25.1
+edgegrid-python >= 1.1
+PyYAML[extra]==5.4 ; python_version >= "3"
+-r other.txt
+git+https://github.com/akamai/lib.git
+`},
+			expected: []sbomDependency{
+				{Type: purlTypePypi, Name: "requests", Version: "2.25.1"},
+				{Type: purlTypePypi, Name: "edgegrid-python"},
+				{Type: purlTypePypi, Name: "PyYAML", Version: "5.4"},
+			},
+		},
+		"npm dependencies": {
+			files: map[string]string{
+				"package.json": `{"dependencies": {"yargs": "^16.0.0", "@akamai/edgegrid": "^3.0.0"}}`,
+				"node_modules/@akamai/edgegrid/package.json": `{"version": "3.0.8"}`,
+			},
+			expected: []sbomDependency{
+				{Type: purlTypeNpm, Name: "@akamai/edgegrid", Version: "3.0.8"},
+				{Type: purlTypeNpm, Name: "yargs"},
+			},
+		},
+		"no dependencies": {},
+		"invalid package.json": {
+			files:     map[string]string{"package.json": `{`},
+			withError: "invalid package.json",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-sbom")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			for path, content := range test.files {
+				path = filepath.Join(dir, filepath.FromSlash(path))
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+			}
+
+			deps, err := detectDependencies(dir)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, deps)
+		})
+	}
+}
+
+func TestSBOMPurl(t *testing.T) {
+	tests := map[string]struct {
+		purl     string
+		expected string
+	}{
+		"go module":         {purl: sbomDependency{Type: purlTypeGolang, Name: "github.com/apex/log", Version: "v1.9.0"}.purl(), expected: "pkg:golang/github.com/apex/log@v1.9.0"},
+		"pypi name":         {purl: sbomDependency{Type: purlTypePypi, Name: "PyYAML", Version: "5.4"}.purl(), expected: "pkg:pypi/pyyaml@5.4"},
+		"scoped npm":        {purl: sbomDependency{Type: purlTypeNpm, Name: "@akamai/edgegrid", Version: "3.0.8"}.purl(), expected: "pkg:npm/%40akamai/edgegrid@3.0.8"},
+		"no version":        {purl: sbomDependency{Type: purlTypeNpm, Name: "yargs"}.purl(), expected: "pkg:npm/yargs"},
+		"github package":    {purl: sbomPackage{Repo: "https://github.com/akamai/cli-Echo.git", Commit: "abc"}.purl(), expected: "pkg:github/akamai/cli-echo@abc"},
+		"github ssh":        {purl: sbomPackage{Repo: "git@github.com:akamai/cli-echo.git"}.purl(), expected: "pkg:github/akamai/cli-echo"},
+		"other git hosting": {purl: sbomPackage{Repo: "https://git.example.com/cli-echo.git", Commit: "abc"}.purl(), expected: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.purl)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// +build !windows
+
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// addToUserPath adds the directory to PATH in the startup file of the user shell, unless the file already refers to it.
+// It returns the startup file and false if PATH did not need to be changed.
+func addToUserPath(dir string) (string, bool, error) {
+	profile, err := shellProfile()
+	if err != nil {
+		return "", false, err
+	}
+	data, err := ioutil.ReadFile(profile)
+	if err != nil && !os.IsNotExist(err) {
+		return "", false, err
+	}
+	if strings.Contains(string(data), dir) {
+		return profile, false, nil
+	}
+
+	line := fmt.Sprintf("export PATH=\"%s:$PATH\"", dir)
+	if strings.HasSuffix(profile, ".fish") {
+		line = fmt.Sprintf("set -gx PATH \"%s\" $PATH", dir)
+	}
+	if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+		return "", false, err
+	}
+	f, err := os.OpenFile(profile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := fmt.Fprintf(f, "\n# added by akamai setup-path\n%s\n", line); err != nil {
+		f.Close()
+		return "", false, err
+	}
+	return profile, true, f.Close()
+}
+
+// shellProfile returns the startup file of the shell set in SHELL, ~/.profile for unknown shells
+func shellProfile() (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	switch filepath.Base(os.Getenv("SHELL")) {
+	case "zsh":
+		return filepath.Join(home, ".zshrc"), nil
+	case "bash":
+		// login shells started by macOS terminals do not read .bashrc
+		if runtime.GOOS == "darwin" {
+			return filepath.Join(home, ".bash_profile"), nil
+		}
+		return filepath.Join(home, ".bashrc"), nil
+	case "fish":
+		return filepath.Join(home, ".config", "fish", "config.fish"), nil
+	}
+	return filepath.Join(home, ".profile"), nil
+}
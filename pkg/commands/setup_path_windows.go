@@ -0,0 +1,59 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// userPathLocation describes where the user PATH is stored on Windows
+const userPathLocation = "the user environment variables"
+
+// addToUserPath appends the directory to the PATH variable of the user, stored in the registry.
+// PowerShell is used, as it notifies running programs of the change, and unlike setx, does not truncate long values.
+// It returns false if PATH already contains the directory.
+func addToUserPath(dir string) (string, bool, error) {
+	output, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command",
+		"[Environment]::GetEnvironmentVariable('Path', 'User')").Output()
+	if err != nil {
+		return "", false, fmt.Errorf("unable to read user PATH: %w", err)
+	}
+	current := strings.TrimSpace(string(output))
+	for _, path := range filepath.SplitList(current) {
+		if strings.EqualFold(filepath.Clean(path), filepath.Clean(dir)) {
+			return userPathLocation, false, nil
+		}
+	}
+
+	path := dir
+	if current != "" {
+		path = strings.TrimSuffix(current, ";") + ";" + dir
+	}
+	// the new value is passed in the environment, so that it does not need to be quoted in the script
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command",
+		"[Environment]::SetEnvironmentVariable('Path', $env:AKAMAI_CLI_USER_PATH, 'User')")
+	cmd.Env = append(os.Environ(), "AKAMAI_CLI_USER_PATH="+path)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if len(output) > 0 {
+			return "", false, fmt.Errorf("unable to set user PATH: %s", lastLine(output))
+		}
+		return "", false, fmt.Errorf("unable to set user PATH: %w", err)
+	}
+	return userPathLocation, true, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// shimHeader marks files in the bin directory generated by Akamai CLI, so that other files are never removed
+const shimHeader = "generated by Akamai CLI, do not edit"
+
+// commandShims returns file names and contents of the shims running given command through the Akamai CLI executable.
+// On Windows, the .cmd shim is used by cmd.exe and the .ps1 shim by PowerShell; other platforms use a shell script.
+// The command name is validated when the package metadata is read, the executable path is quoted for each shell.
+func commandShims(goos, self, cmd string) map[string]string {
+	name := "akamai-" + strings.ToLower(cmd)
+	if goos == "windows" {
+		return map[string]string{
+			name + ".cmd": fmt.Sprintf("@echo off\r\nrem %s\r\n\"%s\" %s %%*\r\n", shimHeader, strings.ReplaceAll(self, "%", "%%"), cmd),
+			name + ".ps1": fmt.Sprintf("# %s\r\n& '%s' %s @args\r\nexit $LASTEXITCODE\r\n", shimHeader, strings.ReplaceAll(self, "'", "''"), cmd),
+		}
+	}
+	return map[string]string{
+		name: fmt.Sprintf("#!/bin/sh\n# %s\nexec '%s' %s \"$@\"\n", shimHeader, strings.ReplaceAll(self, "'", `'\''`), cmd),
+	}
+}
+
+// packageCommandNames returns names of the commands of all installed packages, sorted
+func packageCommandNames() []string {
+	names := make([]string, 0)
+	for _, dir := range getPackagePaths() {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			names = append(names, strings.ToLower(cmd.Name))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeCommandShims writes shims of all installed commands into the bin directory, and removes shims of commands
+// which are no longer installed
+func writeCommandShims(binDir string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return err
+	}
+
+	shims := make(map[string]string)
+	for _, cmd := range packageCommandNames() {
+		for name, content := range commandShims(runtime.GOOS, self, cmd) {
+			shims[name] = content
+		}
+	}
+
+	files, err := ioutil.ReadDir(binDir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if _, ok := shims[file.Name()]; ok || !isCommandShim(filepath.Join(binDir, file.Name())) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(binDir, file.Name())); err != nil {
+			return err
+		}
+	}
+	for name, content := range shims {
+		if err := ioutil.WriteFile(filepath.Join(binDir, name), []byte(content), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isCommandShim returns true if the file has been generated by writeCommandShims
+func isCommandShim(path string) bool {
+	data, err := ioutil.ReadFile(path)
+	return err == nil && strings.Contains(string(data), shimHeader)
+}
+
+// updateCommandShims keeps shims in the bin directory in sync with installed commands.
+// Shims are always maintained on Windows; on other platforms, only once the bin directory is created by "akamai setup-path".
+// Failing to update them does not affect the installed packages, hence the error is only logged.
+func updateCommandShims(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	binDir, err := tools.GetAkamaiCliBinPath()
+	if err != nil {
+		logger.Errorf("Unable to determine bin directory location: %s", err.Error())
+		return
+	}
+	if runtime.GOOS != "windows" {
+		if _, err := os.Stat(binDir); err != nil {
+			return
+		}
+	}
+	if err := writeCommandShims(binDir); err != nil {
+		logger.Errorf("Unable to update command shims: %s", err.Error())
+		return
+	}
+	logger.Debugf("Command shims updated: %s", binDir)
+}
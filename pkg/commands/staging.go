@@ -0,0 +1,255 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	stagingDirName  = ".staging"
+	rollbackDirName = ".rollback"
+)
+
+// stagingRootMu prevents the staging root from being removed by discardStagedPackage while a staging directory
+// is created in it by another worker
+var stagingRootMu sync.RWMutex
+
+// stagePackage copies the package directory to a temporary location inside Akamai CLI root directory,
+// so that the package can be updated and rebuilt without affecting the installed version.
+// The staging area is kept on the same filesystem as the package, which allows swapping directories with a rename.
+func stagePackage(packageDir string) (string, error) {
+	tmpDir, err := newStagingDir(filepath.Base(packageDir))
+	if err != nil {
+		return "", err
+	}
+
+	stagedDir := filepath.Join(tmpDir, filepath.Base(packageDir))
+	if err := tools.CopyDir(packageDir, stagedDir); err != nil {
+		if rmErr := os.RemoveAll(tmpDir); rmErr != nil {
+			return "", fmt.Errorf("%s; unable to clean up staging directory: %s", err, rmErr)
+		}
+		return "", err
+	}
+
+	return stagedDir, nil
+}
+
+// stageClone clones the repository of a package to the staging area at the configured depth, with given branch checked out,
+// or the default one if empty. Packages cloned with a limited history can't be pulled, so they are updated by cloning them again.
+func stageClone(ctx context.Context, gitRepo git.Repository, packageDir, repo, branch string) (string, error) {
+	tmpDir, err := newStagingDir(filepath.Base(packageDir))
+	if err != nil {
+		return "", err
+	}
+
+	stagedDir := filepath.Join(tmpDir, filepath.Base(packageDir))
+	err = runWithTimeout(ctx, "git clone", gitTimeout(ctx), func(ctx context.Context) error {
+		return gitRepo.Clone(ctx, stagedDir, repo, false, cloneDepth(ctx, branch), terminal.Get(ctx).Spinner())
+	})
+	if err == nil && branch != "" {
+		var head *plumbing.Reference
+		if head, err = gitRepo.Head(); err == nil {
+			_, err = checkoutPackageRef(gitRepo, head, branch)
+		}
+	}
+	if err != nil {
+		if rmErr := os.RemoveAll(tmpDir); rmErr != nil {
+			return "", fmt.Errorf("%s; unable to clean up staging directory: %s", err, rmErr)
+		}
+		return "", err
+	}
+
+	return stagedDir, nil
+}
+
+// newStagingDir creates a temporary directory in the staging area for the package with given name
+func newStagingDir(name string) (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	stagingRoot := filepath.Join(cliPath, stagingDirName)
+	stagingRootMu.RLock()
+	defer stagingRootMu.RUnlock()
+	if err := os.MkdirAll(stagingRoot, 0700); err != nil {
+		return "", err
+	}
+	return ioutil.TempDir(stagingRoot, name+"-")
+}
+
+// stageArchive extracts a gzipped tarball or zip archive of a package to a temporary location inside Akamai CLI root directory.
+// The archive may contain package files either at its root or in a single top-level directory,
+// the name of which becomes the package name. Otherwise, the package is named after the archive.
+func stageArchive(archive string) (string, error) {
+	name := filepath.Base(archive)
+	for _, ext := range []string{".tgz", ".tar.gz", ".zip"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	tmpDir, err := newStagingDir(name)
+	if err != nil {
+		return "", err
+	}
+
+	stagedDir, err := extractArchive(archive, tmpDir, name)
+	if err != nil {
+		if rmErr := os.RemoveAll(tmpDir); rmErr != nil {
+			return "", fmt.Errorf("%s; unable to clean up staging directory: %s", err, rmErr)
+		}
+		return "", err
+	}
+	return stagedDir, nil
+}
+
+func extractArchive(archive, tmpDir, name string) (string, error) {
+	extractDir := filepath.Join(tmpDir, ".archive")
+	extract := tools.ExtractTarGz
+	if tools.IsZip(archive) {
+		extract = tools.ExtractZip
+	}
+	if err := extract(archive, extractDir); err != nil {
+		return "", err
+	}
+
+	root := extractDir
+	if _, err := os.Stat(filepath.Join(extractDir, "cli.json")); os.IsNotExist(err) {
+		entries, err := ioutil.ReadDir(extractDir)
+		if err != nil {
+			return "", err
+		}
+		if len(entries) == 1 && entries[0].IsDir() {
+			name = entries[0].Name()
+			root = filepath.Join(extractDir, name)
+		}
+	}
+
+	stagedDir := filepath.Join(tmpDir, name)
+	if err := os.Rename(root, stagedDir); err != nil {
+		return "", err
+	}
+	return stagedDir, os.RemoveAll(extractDir)
+}
+
+// commitStagedPackage replaces the package directory with its staged copy.
+// If the staged copy cannot be moved in place, the previous version is restored.
+func commitStagedPackage(stagedDir, packageDir string) error {
+	backupDir := stagedDir + ".old"
+	if err := os.Rename(packageDir, backupDir); err != nil {
+		return err
+	}
+
+	if err := os.Rename(stagedDir, packageDir); err != nil {
+		if rbErr := os.Rename(backupDir, packageDir); rbErr != nil {
+			return fmt.Errorf("%s; unable to restore previous version: %s", err, rbErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// revertStagedPackage brings back the version replaced by commitStagedPackage.
+// The committed copy is moved back to the staging area, to be removed by discardStagedPackage.
+func revertStagedPackage(stagedDir, packageDir string) error {
+	return commitStagedPackage(stagedDir+".old", packageDir)
+}
+
+// discardStagedPackage removes the staged copy along with the replaced version of the package, if any
+func discardStagedPackage(stagedDir string) error {
+	tmpDir := filepath.Dir(stagedDir)
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+
+	// remove the staging root when no other staged package is present
+	stagingRootMu.Lock()
+	defer stagingRootMu.Unlock()
+	_ = os.Remove(filepath.Dir(tmpDir))
+	return nil
+}
+
+// previousPackageDir returns the location of the version of the package replaced by the last update
+func previousPackageDir(packageDir string) (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, rollbackDirName, filepath.Base(packageDir)), nil
+}
+
+// keepPreviousPackage moves the version replaced by commitStagedPackage to the rollback area,
+// where it replaces any version kept by an earlier update
+func keepPreviousPackage(stagedDir, packageDir string) error {
+	previousDir, err := previousPackageDir(packageDir)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(previousDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(previousDir), 0700); err != nil {
+		return err
+	}
+	return os.Rename(stagedDir+".old", previousDir)
+}
+
+// restorePreviousPackage swaps the package directory with the version kept in the rollback area,
+// so that restoring again brings back the version which was rolled back
+func restorePreviousPackage(packageDir string) error {
+	previousDir, err := previousPackageDir(packageDir)
+	if err != nil {
+		return err
+	}
+	swapDir := previousDir + ".swap"
+	if err := os.Rename(packageDir, swapDir); err != nil {
+		return err
+	}
+
+	if err := os.Rename(previousDir, packageDir); err != nil {
+		if rbErr := os.Rename(swapDir, packageDir); rbErr != nil {
+			return fmt.Errorf("%s; unable to restore current version: %s", err, rbErr)
+		}
+		return err
+	}
+
+	return os.Rename(swapDir, previousDir)
+}
+
+// removePreviousPackage removes the version of the package kept for rollback, if any
+func removePreviousPackage(packageDir string) error {
+	previousDir, err := previousPackageDir(packageDir)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(previousDir); err != nil {
+		return err
+	}
+
+	// remove the rollback root when no other package version is kept
+	_ = os.Remove(filepath.Dir(previousDir))
+	return nil
+}
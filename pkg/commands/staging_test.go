@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStagingDirConcurrently(t *testing.T) {
+	home, err := ioutil.TempDir("", "akamai-staging")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(home))
+	}()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+
+	// workers discarding their staged packages remove the staging root while others create their directories
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				tmpDir, err := newStagingDir("cli-test")
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.NoError(t, discardStagedPackage(filepath.Join(tmpDir, "cli-test")))
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, err = os.Stat(filepath.Join(home, ".akamai-cli", stagingDirName))
+	assert.True(t, os.IsNotExist(err), "staging directory should be removed")
+}
@@ -15,60 +15,46 @@
 package commands
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
-	"text/template"
 
 	"github.com/akamai/cli/pkg/packages"
 
 	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
 	"github.com/urfave/cli/v2"
 
 	"github.com/akamai/cli/pkg/tools"
 )
 
-type subcommands struct {
-	Commands     []command                     `json:"commands"`
-	Requirements packages.LanguageRequirements `json:"requirements"`
-	Action       cli.ActionFunc                `json:"-"`
-	Pkg          string                        `json:"pkg"`
-}
+// subcommands is the cli.json metadata of an installed package, or of a built-in command
+type subcommands = packages.Metadata
 
 func readPackage(dir string) (subcommands, error) {
-	if _, err := os.Stat(filepath.Join(dir, "cli.json")); err != nil {
-		dir = filepath.Dir(dir)
-		if _, err = os.Stat(filepath.Join(dir, "cli.json")); err != nil {
-			return subcommands{}, cli.Exit("Package does not contain a cli.json file.", 1)
-		}
-	}
-
-	var packageData subcommands
-	cliJSON, err := ioutil.ReadFile(filepath.Join(dir, "cli.json"))
-	if err != nil {
-		return subcommands{}, err
-	}
-
-	err = json.Unmarshal(cliJSON, &packageData)
-	if err != nil {
-		return subcommands{}, err
+	pkg, err := packages.ReadMetadata(dir)
+	if errors.Is(err, packages.ErrNoMetadata) {
+		return subcommands{}, cli.Exit("Package does not contain a cli.json file.", 1)
 	}
+	return pkg, err
+}
 
-	for key := range packageData.Commands {
-		packageData.Commands[key].Name = strings.ToLower(packageData.Commands[key].Name)
+// executableCommands returns commands of the package which have an executable.
+// Renamed commands are forwarded to the command they were renamed to, so they are not built nor downloaded.
+func executableCommands(pkg subcommands) []command {
+	commands := make([]command, 0, len(pkg.Commands))
+	for _, cmd := range pkg.Commands {
+		if cmd.RenamedTo == "" {
+			commands = append(commands, cmd)
+		}
 	}
-
-	packageData.Pkg = filepath.Base(strings.Replace(dir, "cli-", "", 1))
-
-	return packageData, nil
+	return commands
 }
 
 func getPackagePaths() []string {
@@ -101,45 +87,80 @@ func findPackageDir(dir string) string {
 	return dir
 }
 
-func downloadBin(ctx context.Context, dir string, cmd command) error {
+// downloadBin downloads and verifies the binary of given command, reporting download progress to given writer.
+// Binaries published for other platforms able to run on the host are tried in order if the first one is not found.
+func downloadBin(ctx context.Context, dir string, cmd command, progress io.Writer) error {
 	logger := log.FromContext(ctx)
-	cmd.Arch = runtime.GOARCH
-
-	cmd.OS = runtime.GOOS
-	if runtime.GOOS == "darwin" {
-		cmd.OS = "mac"
-	}
-
-	if runtime.GOOS == "windows" {
-		cmd.BinSuffix = ".exe"
-	}
-
-	t := template.Must(template.New("url").Parse(cmd.Bin))
-	buf := &bytes.Buffer{}
-	if err := t.Execute(buf, cmd); err != nil {
+	candidates, err := binCandidates(cmd)
+	if err != nil {
 		logger.Debugf("Unable to create URL. Template: %s; Error: %s.", cmd.Bin, err.Error())
 		return err
 	}
 
-	url := buf.String()
-	logger.Debugf("Fetching binary from %s", url)
-
-	binName := filepath.Join(dir, "akamai-"+strings.ToLower(cmd.Name)+cmd.BinSuffix)
-	bin, err := os.Create(binName)
+	binName := filepath.Join(dir, "akamai-"+strings.ToLower(cmd.Name)+tools.BinSuffix())
+	// the binary is downloaded to a temporary file and only made executable once it is verified
+	bin, err := ioutil.TempFile(dir, ".download-*")
 	if err != nil {
 		return err
 	}
 	defer func() {
-		if err := bin.Close(); err != nil {
-			logger.Errorf("Error closing file: %s", err)
+		if err := os.Remove(bin.Name()); err != nil && !os.IsNotExist(err) {
+			logger.Errorf("Error removing temporary file: %s", err)
 		}
 	}()
 
-	if err := os.Chmod(binName, 0775); err != nil {
+	candidate, err := fetchBinCandidates(ctx, candidates, bin, progress)
+	if closeErr := bin.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if skipVerify(ctx) {
+		logger.Warnf("Skipping verification of binary %s", candidate.url)
+	} else if err := verifyBinary(ctx, candidate.cmd, candidate.url, bin.Name()); err != nil {
 		return err
 	}
 
-	res, err := http.Get(url)
+	if err := os.Chmod(bin.Name(), 0775); err != nil {
+		return err
+	}
+	return os.Rename(bin.Name(), binName)
+}
+
+// fetchBinCandidates downloads the first binary found among given candidates into bin.
+// Any error other than a missing binary stops the download, so that a network failure does not silently select another platform.
+func fetchBinCandidates(ctx context.Context, candidates []binCandidate, bin *os.File, progress io.Writer) (binCandidate, error) {
+	logger := log.FromContext(ctx)
+	tried := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		logger.Debugf("Fetching binary from %s", candidate.url)
+		err := fetchBin(ctx, candidate.url, bin, progress)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, errBinNotFound) {
+			return candidate, err
+		}
+		logger.Debugf("Binary not found: %s", candidate.url)
+		tried = append(tried, candidate.url)
+	}
+
+	osName, arch := binPlatform()
+	msg := fmt.Sprintf("no binary published for %s/%s, tried:\n  %s", osName, arch, strings.Join(tried, "\n  "))
+	if hostOS == "darwin" && hostArch == "arm64" && !hasRosetta() {
+		msg += "\ninstall Rosetta 2 to run binaries published for mac/amd64"
+	}
+	return binCandidate{}, errors.New(msg)
+}
+
+// errBinNotFound is returned by fetchBin if no binary is published at given URL
+var errBinNotFound = errors.New("binary not found")
+
+func fetchBin(ctx context.Context, url string, bin *os.File, progress io.Writer) error {
+	logger := log.FromContext(ctx)
+	res, err := tools.HTTPGet(ctx, url, 0)
 	if err != nil {
 		return err
 	}
@@ -149,11 +170,15 @@ func downloadBin(ctx context.Context, dir string, cmd command) error {
 		}
 	}()
 
+	if res.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", errBinNotFound, url)
+	}
 	if res.StatusCode != http.StatusOK {
 		return fmt.Errorf("invalid response status while fetching command binary: %d", res.StatusCode)
 	}
 
-	n, err := io.Copy(bin, res.Body)
+	body := &terminal.ProgressReader{Reader: res.Body, Total: res.ContentLength, Out: progress}
+	n, err := io.Copy(bin, body)
 	if err != nil || n == 0 {
 		return err
 	}
@@ -1,6 +1,16 @@
 package commands
 
 import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,6 +21,8 @@ func TestReadPackage(t *testing.T) {
 	tests := map[string]struct {
 		directory string
 		pkg       string
+		env       map[string]string
+		cwd       string
 		withError string
 	}{
 		"return subcommands with directory name": {
@@ -21,6 +33,12 @@ func TestReadPackage(t *testing.T) {
 			directory: "./testdata/.akamai-cli/src/cli-echo-python",
 			pkg:       "echo-python",
 		},
+		"return subcommands with env and cwd": {
+			directory: "./testdata/repo_env",
+			pkg:       "repo_env",
+			env:       map[string]string{"AKAMAI_TEST_ENDPOINT": "https://example.com"},
+			cwd:       "workdir",
+		},
 		"no error if no cli.json": {
 			directory: "./testdata/cli-search",
 			withError: `does not contain a cli.json`,
@@ -41,6 +59,124 @@ func TestReadPackage(t *testing.T) {
 			}
 			require.NoError(t, err)
 			assert.Equal(t, test.pkg, subcommands.Pkg, "the package name was not resolved properly")
+			assert.Equal(t, test.env, subcommands.Env)
+			assert.Equal(t, test.cwd, subcommands.Cwd)
+		})
+	}
+}
+
+func TestDownloadBin(t *testing.T) {
+	content := []byte("binary content")
+	checksum := "93a0b24644f2e0fd11d6b422c90275c482b0cc20be4a4e3f62148ed2932b4792"
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	publicKey := base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+	otherKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{2}, ed25519.SeedSize))
+
+	tests := map[string]struct {
+		declared     string
+		publicKey    string
+		checksumFile string
+		signature    []byte
+		skipVerify   bool
+		withError    string
+	}{
+		"checksum published alongside the binary": {
+			checksumFile: checksum + "  akamai-test\n",
+		},
+		"checksum declared in cli.json": {
+			declared:     checksum,
+			checksumFile: strings.Repeat("0", 64),
+		},
+		"checksum mismatch": {
+			checksumFile: strings.Repeat("0", 64),
+			withError:    "checksum mismatch",
+		},
+		"invalid checksum": {
+			checksumFile: "abc",
+			withError:    "invalid SHA256 checksum: abc",
+		},
+		"no checksum found": {
+			withError: "no checksum found",
+		},
+		"no checksum found, skip verification": {
+			skipVerify: true,
+		},
+		"valid signature": {
+			checksumFile: checksum,
+			publicKey:    publicKey,
+			signature:    ed25519.Sign(key, content),
+		},
+		"invalid signature": {
+			checksumFile: checksum,
+			publicKey:    publicKey,
+			signature:    ed25519.Sign(otherKey, content),
+			withError:    "invalid signature",
+		},
+		"no signature found": {
+			checksumFile: checksum,
+			publicKey:    publicKey,
+			withError:    "no signature found",
+		},
+		"invalid public key": {
+			checksumFile: checksum,
+			publicKey:    "abc",
+			withError:    "invalid public key",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body []byte
+				switch r.URL.Path {
+				case "/akamai-test":
+					body = content
+				case "/akamai-test.sha256":
+					body = []byte(test.checksumFile)
+				case "/akamai-test.sig":
+					body = []byte(base64.StdEncoding.EncodeToString(test.signature))
+				}
+				if len(body) == 0 {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, err := w.Write(body)
+				assert.NoError(t, err)
+			}))
+			defer srv.Close()
+
+			dir, err := ioutil.TempDir("", "download-bin")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+
+			cmd := command{Name: "test", Bin: srv.URL + "/akamai-{{.Name}}", PublicKey: test.publicKey}
+			if test.declared != "" {
+				osName, arch := binPlatform()
+				cmd.Checksums = map[string]string{osName + "/" + arch: test.declared}
+			}
+			ctx := context.Background()
+			if test.skipVerify {
+				ctx = withSkipVerify(ctx)
+			}
+
+			err = downloadBin(ctx, dir, cmd, ioutil.Discard)
+			files, readErr := ioutil.ReadDir(dir)
+			require.NoError(t, readErr)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				assert.Empty(t, files, "unverified binary should be removed")
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+			assert.True(t, strings.HasPrefix(files[0].Name(), "akamai-test"))
+			assert.NotZero(t, files[0].Mode()&0100, "binary should be executable")
+			downloaded, err := ioutil.ReadFile(filepath.Join(dir, files[0].Name()))
+			require.NoError(t, err)
+			assert.Equal(t, content, downloaded)
 		})
 	}
 }
@@ -0,0 +1,134 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/registry"
+)
+
+// timeouts of git operations and package builds, unless install.git-timeout, install.build-timeout or --timeout are set
+const (
+	defaultGitTimeout   = 10 * time.Minute
+	defaultBuildTimeout = 30 * time.Minute
+)
+
+type timeoutKey struct{}
+
+// withTimeout sets the timeout given with --timeout, which takes precedence over the timeouts of git operations,
+// package builds and registries set in the config file
+func withTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+// timeoutOverride returns the timeout given with --timeout, if any
+func timeoutOverride(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(timeoutKey{}).(time.Duration)
+	return timeout, ok && timeout > 0
+}
+
+// timeoutFromFlags returns the timeout given with --timeout, 0 if it is not set
+func timeoutFromFlags(c *cli.Context) (time.Duration, error) {
+	timeout := c.Duration("timeout")
+	if timeout < 0 {
+		return 0, fmt.Errorf("invalid timeout %s, expected a positive duration such as 30s or 10m", timeout)
+	}
+	return timeout, nil
+}
+
+// parseGitTimeout parses the install.git-timeout setting, e.g. "30s" or "10m".
+// The default timeout is used for an empty value, and for an invalid value along with the error.
+func parseGitTimeout(value string) (time.Duration, error) {
+	return parseTimeout(value, defaultGitTimeout)
+}
+
+// parseBuildTimeout parses the install.build-timeout setting, e.g. "5m" or "1h".
+// The default timeout is used for an empty value, and for an invalid value along with the error.
+func parseBuildTimeout(value string) (time.Duration, error) {
+	return parseTimeout(value, defaultBuildTimeout)
+}
+
+func parseTimeout(value string, defaultTimeout time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return defaultTimeout, fmt.Errorf("invalid timeout %q, expected a duration such as 30s or 10m", value)
+	}
+	return timeout, nil
+}
+
+// gitTimeout returns how long cloning, pulling or fetching a package repository can take
+func gitTimeout(ctx context.Context) time.Duration {
+	return phaseTimeout(ctx, "AKAMAI_INSTALL_GIT_TIMEOUT", parseGitTimeout)
+}
+
+// downloadTimeout returns how long downloading a package archive can take: the timeout given with --timeout,
+// or the default timeout of registry requests
+func downloadTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := timeoutOverride(ctx); ok {
+		return timeout
+	}
+	return registry.DefaultTimeout
+}
+
+// buildTimeout returns how long building a package can take
+func buildTimeout(ctx context.Context) time.Duration {
+	return phaseTimeout(ctx, "AKAMAI_INSTALL_BUILD_TIMEOUT", parseBuildTimeout)
+}
+
+// phaseTimeout returns the timeout given with --timeout, or the one of the setting exported in env
+func phaseTimeout(ctx context.Context, env string, parse func(string) (time.Duration, error)) time.Duration {
+	if timeout, ok := timeoutOverride(ctx); ok {
+		return timeout
+	}
+	timeout, err := parse(os.Getenv(env))
+	if err != nil {
+		log.FromContext(ctx).Warnf("%s, using %s", err, timeout)
+	}
+	return timeout
+}
+
+// timeoutError reports the phase of an operation which didn't complete within its timeout, such as "git clone"
+type timeoutError struct {
+	phase   string
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.phase, e.timeout)
+}
+
+// runWithTimeout runs the phase of an operation with a context done once the timeout expires.
+// If it does, a timeoutError naming the phase is returned instead of the error of the phase.
+func runWithTimeout(ctx context.Context, phase string, timeout time.Duration, run func(context.Context) error) error {
+	phaseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := run(phaseCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(phaseCtx.Err(), context.DeadlineExceeded) {
+		log.FromContext(ctx).Errorf("%s timed out after %s", phase, timeout)
+		return &timeoutError{phase: phase, timeout: timeout}
+	}
+	return err
+}
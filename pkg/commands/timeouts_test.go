@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akamai/cli/pkg/registry"
+)
+
+func TestParseTimeout(t *testing.T) {
+	tests := map[string]struct {
+		value     string
+		expected  time.Duration
+		withError string
+	}{
+		"default":  {expected: defaultGitTimeout},
+		"duration": {value: "90s", expected: 90 * time.Second},
+		"invalid": {value: "never", expected: defaultGitTimeout,
+			withError: `invalid timeout "never", expected a duration such as 30s or 10m`},
+		"zero": {value: "0s", expected: defaultGitTimeout,
+			withError: `invalid timeout "0s", expected a duration such as 30s or 10m`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			timeout, err := parseGitTimeout(test.value)
+			assert.Equal(t, test.expected, timeout)
+			if test.withError != "" {
+				assert.EqualError(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBuildTimeout(t *testing.T) {
+	tests := map[string]struct {
+		setting  string
+		flag     time.Duration
+		expected time.Duration
+	}{
+		"default":           {expected: defaultBuildTimeout},
+		"setting":           {setting: "1h", expected: time.Hour},
+		"invalid setting":   {setting: "forever", expected: defaultBuildTimeout},
+		"flag over setting": {setting: "1h", flag: 5 * time.Minute, expected: 5 * time.Minute},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_INSTALL_BUILD_TIMEOUT", test.setting))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_INSTALL_BUILD_TIMEOUT"))
+			}()
+			assert.Equal(t, test.expected, buildTimeout(withTimeout(context.Background(), test.flag)))
+		})
+	}
+}
+
+func TestDownloadTimeout(t *testing.T) {
+	tests := map[string]struct {
+		gitSetting string
+		flag       time.Duration
+		expected   time.Duration
+	}{
+		"default":             {expected: registry.DefaultTimeout},
+		"git setting ignored": {gitSetting: "1h", expected: registry.DefaultTimeout},
+		"flag":                {flag: 5 * time.Minute, expected: 5 * time.Minute},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_INSTALL_GIT_TIMEOUT", test.gitSetting))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_INSTALL_GIT_TIMEOUT"))
+			}()
+			assert.Equal(t, test.expected, downloadTimeout(withTimeout(context.Background(), test.flag)))
+		})
+	}
+}
+
+func TestRunWithTimeout(t *testing.T) {
+	errFailed := errors.New("failed")
+	waitDone := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	tests := map[string]struct {
+		run       func(context.Context) error
+		cancel    bool
+		withError string
+	}{
+		"completed": {
+			run: func(context.Context) error { return nil },
+		},
+		"failed": {
+			run:       func(context.Context) error { return errFailed },
+			withError: "failed",
+		},
+		"timed out": {
+			run:       waitDone,
+			withError: "git clone timed out after 10ms",
+		},
+		"canceled": {
+			run:       waitDone,
+			cancel:    true,
+			withError: context.Canceled.Error(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancel {
+				cancel()
+			}
+			err := runWithTimeout(ctx, "git clone", 10*time.Millisecond, test.run)
+			if test.withError != "" {
+				assert.EqualError(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -19,27 +19,38 @@ package commands
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"text/template"
 	"time"
 
+	"github.com/Masterminds/semver"
 	"github.com/akamai/cli/pkg/log"
 	"github.com/urfave/cli/v2"
 
 	"github.com/akamai/cli/pkg/config"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
 	"github.com/akamai/cli/pkg/version"
 	"github.com/fatih/color"
-	"github.com/inconshreveable/go-update"
 )
 
+const verifyUpgradeTimeout = 30 * time.Second
+
+// errUpgradeFailed is recorded in the audit log when the new version could not be downloaded, verified or installed
+var errUpgradeFailed = errors.New("unable to download, verify or install the new version")
+
 // CheckUpgradeVersion ...
 func CheckUpgradeVersion(ctx context.Context, force bool) string {
 	term := terminal.Get(ctx)
@@ -48,6 +59,9 @@ func CheckUpgradeVersion(ctx context.Context, force bool) string {
 	if !term.IsTTY() {
 		return ""
 	}
+	if !force && UpgradeCheckDisabled(ctx) {
+		return ""
+	}
 
 	data, _ := cfg.GetValue("cli", "last-upgrade-check")
 	data = strings.TrimSpace(data)
@@ -55,6 +69,10 @@ func CheckUpgradeVersion(ctx context.Context, force bool) string {
 	if data == "ignore" && !force {
 		return ""
 	}
+	if !force {
+		// automatic checks are not retried, so that commands are not delayed when offline
+		ctx = tools.WithoutRetry(ctx)
+	}
 
 	checkForUpgrade := false
 	if data == "never" || force {
@@ -69,8 +87,12 @@ func CheckUpgradeVersion(ctx context.Context, force bool) string {
 			return ""
 		}
 
+		interval, err := parseUpgradeCheckInterval(upgradeCheckIntervalValue(ctx))
+		if err != nil {
+			log.FromContext(ctx).Warn(err.Error())
+		}
 		currentTime := time.Now()
-		if lastUpgrade.Add(sleepTime24Hours).Before(currentTime) {
+		if lastUpgrade.Add(interval).Before(currentTime) {
 			checkForUpgrade = true
 		}
 	}
@@ -103,18 +125,33 @@ func CheckUpgradeVersion(ctx context.Context, force bool) string {
 	return ""
 }
 
+// getLatestReleaseVersion returns the latest version released in the configured channel, or "0" if it cannot be determined
 func getLatestReleaseVersion(ctx context.Context) string {
 	logger := log.FromContext(ctx)
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
+	channel, err := upgradeChannel(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+		return "0"
 	}
-	repo := "https://github.com/akamai/cli"
-	if r := os.Getenv("CLI_REPOSITORY"); r != "" {
-		repo = r
+	if channel != channelStable {
+		latestVersion, err := getLatestChannelVersion(ctx, channel)
+		if err != nil {
+			logger.Errorf("Unable to find the latest release in %s channel: %s", channel, err)
+			return "0"
+		}
+		return latestVersion
 	}
-	resp, err := client.Head(fmt.Sprintf("%s/releases/latest", repo))
+
+	client := tools.HTTPClient(0)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	repo := cliRepository()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, fmt.Sprintf("%s/releases/latest", repo), nil)
+	if err != nil {
+		return "0"
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return "0"
 	}
@@ -134,39 +171,111 @@ func getLatestReleaseVersion(ctx context.Context) string {
 	return latestVersion
 }
 
-// UpgradeCli ...
+// githubRelease is a release returned by GitHub releases API
+type githubRelease struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// getLatestChannelVersion lists releases of Akamai CLI repository and returns the highest version available in given channel
+func getLatestChannelVersion(ctx context.Context, channel string) (string, error) {
+	logger := log.FromContext(ctx)
+	url := releasesAPIURL(cliRepository())
+	logger.Debugf("Fetching releases from %s", url)
+
+	resp, err := tools.HTTPGet(ctx, url, registryTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Error(err.Error())
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("invalid response status: %d", resp.StatusCode)
+	}
+
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return "", err
+	}
+	if latest := latestChannelRelease(releases, channel); latest != "" {
+		return latest, nil
+	}
+	return "0", nil
+}
+
+// latestChannelRelease returns the highest version among releases published in given channel.
+// Beta channel includes stable releases and pre-releases, except nightly builds. Nightly channel includes all published releases.
+func latestChannelRelease(releases []githubRelease, channel string) string {
+	var latest string
+	for _, release := range releases {
+		if release.Draft || (release.Prerelease && channel == channelStable) {
+			continue
+		}
+		if channel != channelNightly && strings.Contains(strings.ToLower(release.TagName), channelNightly) {
+			continue
+		}
+		if _, err := semver.NewVersion(release.TagName); err != nil {
+			continue
+		}
+		if latest == "" || version.Compare(latest, release.TagName) == 1 {
+			latest = release.TagName
+		}
+	}
+	return latest
+}
+
+// releasesAPIURL returns the URL listing releases of given repository, using GitHub API for repositories hosted on GitHub
+func releasesAPIURL(repo string) string {
+	return strings.Replace(strings.TrimSuffix(repo, "/"), "https://github.com/", "https://api.github.com/repos/", 1) + "/releases?per_page=100"
+}
+
+func cliRepository() string {
+	if r := os.Getenv("CLI_REPOSITORY"); r != "" {
+		return r
+	}
+	return "https://github.com/akamai/cli"
+}
+
+// UpgradeCli downloads given release of Akamai CLI, usually the latest one, and replaces the running executable with it.
+// The new executable is verified before replacing the current one, which is kept if any of the checks fails.
 func UpgradeCli(ctx context.Context, latestVersion string) bool {
 	term := terminal.Get(ctx)
 	logger := log.FromContext(ctx)
 
 	term.Spinner().Start("Upgrading Akamai CLI")
 
-	repo := "https://github.com/akamai/cli"
-	if r := os.Getenv("CLI_REPOSITORY"); r != "" {
-		repo = r
-	}
+	repo := cliRepository()
 	cmd := command{
 		Version: latestVersion,
 		Bin:     fmt.Sprintf("%s/releases/download/{{.Version}}/akamai-{{.Version}}-{{.OS}}{{.Arch}}{{.BinSuffix}}", repo),
-		Arch:    runtime.GOARCH,
-		OS:      runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		OS:        runtime.GOOS,
+		BinSuffix: tools.BinSuffix(),
 	}
 
 	if runtime.GOOS == "darwin" {
 		cmd.OS = "mac"
 	}
 
-	if runtime.GOOS == "windows" {
-		cmd.BinSuffix = ".exe"
-	}
-
 	t := template.Must(template.New("url").Parse(cmd.Bin))
 	buf := &bytes.Buffer{}
 	if err := t.Execute(buf, cmd); err != nil {
 		return false
 	}
 
-	resp, err := http.Get(buf.String())
+	audit := auditEntry{Action: auditUpgrade, Package: auditCliPackage, Version: latestVersion, PreviousVersion: version.Version, Source: buf.String()}
+	replaced := false
+	defer func() {
+		if !replaced {
+			recordAudit(ctx, audit, errUpgradeFailed)
+		}
+	}()
+
+	resp, err := tools.HTTPGet(ctx, buf.String(), 0)
 	if err != nil || resp.StatusCode != http.StatusOK {
 		term.Spinner().Fail()
 		errMsg := color.RedString("Unable to download release, please try again.")
@@ -180,7 +289,7 @@ func UpgradeCli(ctx context.Context, latestVersion string) bool {
 		}
 	}()
 
-	shaResp, err := http.Get(fmt.Sprintf("%v%v", buf.String(), ".sig"))
+	shaResp, err := tools.HTTPGet(ctx, fmt.Sprintf("%v%v", buf.String(), ".sig"), 0)
 	if err != nil || shaResp.StatusCode != http.StatusOK {
 		term.Spinner().Fail()
 		term.Writeln(color.RedString("Unable to retrieve signature for verification, please try again."))
@@ -206,31 +315,56 @@ func UpgradeCli(ctx context.Context, latestVersion string) bool {
 		return false
 	}
 
-	selfPath := os.Args[0]
+	selfPath, err := selfExecutable()
+	if err != nil {
+		term.Spinner().Fail()
+		term.Writeln(color.RedString("Unable to find the Akamai CLI executable: %s", err.Error()))
+		return false
+	}
 
-	err = update.Apply(resp.Body, update.Options{TargetPath: selfPath, Checksum: shasum})
+	newPath, err := downloadExecutable(resp.Body, selfPath, shasum)
 	if err != nil {
 		term.Spinner().Fail()
-		if rerr := update.RollbackError(err); rerr != nil {
-			term.Writeln(color.RedString("Unable to install or rollback, please re-install."))
-			os.Exit(1)
-			return false
-		} else if strings.HasPrefix(err.Error(), "Upgrade file has wrong checksum.") {
-			term.Writeln(color.RedString(err.Error()))
-			term.Writeln(color.RedString("Checksums do not match, please try again."))
-			return false
-		}
 		term.Writeln(color.RedString(err.Error()))
 		return false
 	}
+	defer func() {
+		if err := os.Remove(newPath); err != nil && !os.IsNotExist(err) {
+			logger.Error(err.Error())
+		}
+	}()
+
+	if err := verifyExecutable(ctx, newPath, latestVersion); err != nil {
+		term.Spinner().Fail()
+		term.Writeln(color.RedString("Unable to verify new version: %s", err.Error()))
+		term.Writeln(color.RedString("Previous version has been kept, please try again."))
+		return false
+	}
+
+	staged, err := replaceExecutable(newPath, selfPath)
+	if err != nil {
+		term.Spinner().Fail()
+		term.Writeln(color.RedString("Unable to replace executable: %s", err.Error()))
+		term.Writeln(color.RedString("Previous version has been kept, please try again."))
+		return false
+	}
 
 	term.Spinner().OK()
+	replaced = true
+	// the command is run again with the new version, which exits before deferred functions are called
+	recordAudit(ctx, audit, nil)
 
-	if err == nil {
-		os.Args[0] = selfPath
+	if staged {
+		term.Printf("Akamai CLI %s has been downloaded and will replace %s next time you run it\n", color.CyanString("v"+latestVersion), color.CyanString("v"+version.Version))
+		return true
 	}
-	err = passthruCommand(os.Args)
-	if err != nil {
+	if version.Compare(version.Version, latestVersion) == -1 {
+		term.Printf("Akamai CLI downgraded from %s to %s\n", color.CyanString("v"+version.Version), color.CyanString("v"+latestVersion))
+	} else {
+		term.Printf("Akamai CLI upgraded from %s to %s\n", color.CyanString("v"+version.Version), color.CyanString("v"+latestVersion))
+	}
+
+	if err := passthruCommand(append([]string{selfPath}, os.Args[1:]...), ""); err != nil {
 		cli.OsExiter(1)
 		return false
 	}
@@ -238,3 +372,113 @@ func UpgradeCli(ctx context.Context, latestVersion string) bool {
 
 	return true
 }
+
+// downloadExecutable saves the new executable next to the current one and verifies its checksum
+func downloadExecutable(body io.Reader, selfPath string, checksum []byte) (string, error) {
+	ext := filepath.Ext(selfPath)
+	name := strings.TrimSuffix(filepath.Base(selfPath), ext)
+	f, err := ioutil.TempFile(filepath.Dir(selfPath), fmt.Sprintf(".%s-upgrade-*%s", name, ext))
+	if err != nil {
+		return "", fmt.Errorf("unable to save release: %s", err)
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hash), body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", removeOnError(f.Name(), fmt.Errorf("unable to save release: %s", err))
+	}
+
+	if !bytes.Equal(hash.Sum(nil), checksum) {
+		return "", removeOnError(f.Name(), fmt.Errorf("checksums do not match, please try again"))
+	}
+
+	mode := os.FileMode(0755)
+	if stat, err := os.Stat(selfPath); err == nil {
+		mode = stat.Mode().Perm()
+	}
+	if err := os.Chmod(f.Name(), mode); err != nil {
+		return "", removeOnError(f.Name(), err)
+	}
+
+	return f.Name(), nil
+}
+
+// verifyExecutable runs the new executable and checks that it reports the expected version
+func verifyExecutable(ctx context.Context, path, expectedVersion string) error {
+	ctx, cancel := context.WithTimeout(ctx, verifyUpgradeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return fmt.Errorf("unable to run new version: %s", err)
+	}
+	if !strings.Contains(string(out), expectedVersion) {
+		return fmt.Errorf("new version reported unexpected version: %s", strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// replaceExecutable atomically moves the new executable in place of the current one.
+// On Windows, the running executable cannot be replaced, so the new one is staged and applied by ApplyStagedUpgrade on next launch.
+func replaceExecutable(newPath, selfPath string) (bool, error) {
+	if runtime.GOOS == "windows" {
+		return true, os.Rename(newPath, stagedUpgradePath(selfPath))
+	}
+	return false, os.Rename(newPath, selfPath)
+}
+
+// ApplyStagedUpgrade replaces the executable with the new version staged during previous upgrade, if there is one
+func ApplyStagedUpgrade() (bool, error) {
+	selfPath, err := selfExecutable()
+	if err != nil {
+		return false, err
+	}
+	stagedPath := stagedUpgradePath(selfPath)
+	if _, err := os.Stat(stagedPath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	oldPath := filepath.Join(filepath.Dir(selfPath), fmt.Sprintf(".%s.old", filepath.Base(selfPath)))
+	if err := os.Rename(selfPath, oldPath); err != nil {
+		return false, err
+	}
+	if err := os.Rename(stagedPath, selfPath); err != nil {
+		if rbErr := os.Rename(oldPath, selfPath); rbErr != nil {
+			return false, fmt.Errorf("%s; unable to restore previous version: %s", err, rbErr)
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// executable returns the path of the running executable, it can be replaced in tests
+var executable = os.Executable
+
+// selfExecutable returns the path of the running executable with symlinks resolved, where the new version is written.
+// os.Args[0] can't be used, since it is only the command name when the CLI is started from PATH.
+func selfExecutable() (string, error) {
+	path, err := executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+func stagedUpgradePath(selfPath string) string {
+	return filepath.Join(filepath.Dir(selfPath), fmt.Sprintf(".%s.new", filepath.Base(selfPath)))
+}
+
+func removeOnError(path string, err error) error {
+	if rmErr := os.Remove(path); rmErr != nil {
+		return fmt.Errorf("%s; unable to remove downloaded file: %s", err, rmErr)
+	}
+	return err
+}
@@ -26,7 +26,7 @@ func CheckUpgradeVersion(ctx context.Context, force bool) string {
 	return ""
 }
 
-func getLatestReleaseVersion() string {
+func getLatestReleaseVersion(ctx context.Context) string {
 	return "0"
 }
 
@@ -34,6 +34,10 @@ func UpgradeCli(ctx context.Context, latestVersion string) bool {
 	return false
 }
 
+func ApplyStagedUpgrade() (bool, error) {
+	return false, nil
+}
+
 func getUpgradeCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "upgrade",
@@ -0,0 +1,148 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+type skipVerifyKey struct{}
+
+// withSkipVerify marks the context so that downloaded binaries are installed without verification
+func withSkipVerify(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipVerifyKey{}, true)
+}
+
+func skipVerify(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipVerifyKey{}).(bool)
+	return skip
+}
+
+// expectedChecksum returns the SHA256 checksum the binary downloaded from url is expected to have.
+// The checksum declared in cli.json for the current platform takes precedence over the checksum file published alongside the binary.
+func expectedChecksum(ctx context.Context, cmd command, url string) (string, error) {
+	for _, key := range checksumKeys(cmd) {
+		if checksum, ok := cmd.Checksums[key]; ok {
+			return strings.ToLower(checksum), nil
+		}
+	}
+
+	content, found, err := fetchAsset(ctx, url+".sha256")
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("no checksum found for %s, use --skip-verify to install it without verification", url)
+	}
+
+	// checksum files generated by sha256sum also contain the file name
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("invalid checksum file: %s.sha256", url)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// verifyBinary verifies the downloaded binary against its expected checksum and,
+// if the command declares a public key, against the ed25519 signature published alongside the binary
+func verifyBinary(ctx context.Context, cmd command, url, path string) error {
+	expected, err := expectedChecksum(ctx, cmd, url)
+	if err != nil {
+		return err
+	}
+	if _, err := hex.DecodeString(expected); err != nil || len(expected) != 64 {
+		return fmt.Errorf("invalid SHA256 checksum: %s", expected)
+	}
+
+	actual, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", url, expected, actual)
+	}
+
+	if cmd.PublicKey == "" {
+		return nil
+	}
+	return verifySignature(ctx, cmd.PublicKey, url, path)
+}
+
+func verifySignature(ctx context.Context, publicKey, url, path string) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key: %s", publicKey)
+	}
+
+	content, found, err := fetchAsset(ctx, url+".sig")
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no signature found for %s, use --skip-verify to install it without verification", url)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return fmt.Errorf("invalid signature file: %s.sig", url)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, data, signature) {
+		return fmt.Errorf("invalid signature for %s", url)
+	}
+	return nil
+}
+
+// fetchAsset downloads a small release asset, such as checksum or signature file. False is returned if the asset does not exist.
+func fetchAsset(ctx context.Context, url string) ([]byte, bool, error) {
+	logger := log.FromContext(ctx)
+	logger.Debugf("Fetching %s", url)
+
+	res, err := tools.HTTPGet(ctx, url, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Errorf("Error closing request body: %s", err)
+		}
+	}()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("invalid response status while fetching %s: %d", url, res.StatusCode)
+	}
+
+	content, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	return content, true, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// newGitRepository creates a repository for each concurrent worker, as a repository operates on a single package at a time
+var newGitRepository = git.NewRepository
+
+// packageTask is a package operation executed by a worker.
+// Output of the operation is buffered, so that output of concurrent operations does not interleave.
+type packageTask struct {
+	name string
+	out  *terminal.BufferedTerminal
+	err  error
+}
+
+func newPackageTask(name string) *packageTask {
+	return &packageTask{name: name, out: terminal.NewBuffered()}
+}
+
+// failed returns true if the operation returned an error, except for warnings such as an already installed package
+func (t *packageTask) failed() bool {
+	if isWarning(t.err) {
+		return false
+	}
+	var exitErr cli.ExitCoder
+	if errors.As(t.err, &exitErr) {
+		return exitErr.ExitCode() != 0
+	}
+	return t.err != nil
+}
+
+// failureKind returns the kind of errors of failed tasks if all of them failed for the same reason, nil otherwise
+func failureKind(tasks []*packageTask) error {
+	var kind error
+	for _, task := range tasks {
+		if !task.failed() {
+			continue
+		}
+		taskKind := kindOf(task.err)
+		if taskKind == nil || (kind != nil && kind != taskKind) {
+			return nil
+		}
+		kind = taskKind
+	}
+	return kind
+}
+
+// runConcurrently executes task for each index in [0, n) using a pool of at most concurrency workers,
+// and waits for all tasks to finish
+func runConcurrently(n, concurrency int, task func(i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				task(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// printTaskResults writes buffered output of finished tasks, in order, followed by error messages of failed tasks.
+// The number of failed tasks is returned.
+func printTaskResults(term terminal.Terminal, tasks []*packageTask) int {
+	failed := 0
+	for _, task := range tasks {
+		term.Printf("%s", task.out.String())
+		if task.err != nil {
+			term.Writeln(task.err.Error())
+		}
+		if task.failed() {
+			failed++
+		}
+	}
+	return failed
+}
@@ -15,8 +15,10 @@
 package config
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/akamai/cli/pkg/log"
 	"io/ioutil"
 	"os"
@@ -26,14 +28,36 @@ import (
 
 	"github.com/go-ini/ini"
 
+	"github.com/akamai/cli/pkg/keyring"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/akamai/cli/pkg/tools"
 )
 
 const (
 	configVersion string = "1.1"
+
+	profileSectionPrefix = "profile "
+	// RegistriesSection is the name of the config section storing package registries, with keys in <registry>.<setting> format
+	RegistriesSection = "registries"
+	// AliasesSection is the name of the config section storing user aliases of commands
+	AliasesSection = "aliases"
+
+	// SecretStorageKeyring is the default value of cli.secret-storage, storing secrets in the OS keyring
+	SecretStorageKeyring = "keyring"
+	// SecretStorageFile is the value of cli.secret-storage storing secrets in the config file, for systems without a keyring
+	SecretStorageFile = "file"
+
+	keyringService = "akamai-cli"
+	// KeyringValue is stored in the config file in place of a secret kept in the OS keyring
+	KeyringValue = "<keyring>"
 )
 
+// globalSections are exported to all executed commands, even if they aren't set
+var globalSections = []string{"cli", "install"}
+
+// secretKeyWords designate settings holding secrets, which are stored in the OS keyring
+var secretKeyWords = []string{"token", "secret", "password"}
+
 type (
 	// Config contains methods to operate on CLI config
 	Config interface {
@@ -42,13 +66,21 @@ type (
 		GetValue(string, string) (string, bool)
 		SetValue(string, string, string)
 		UnsetValue(string, string)
+		SetSecret(string, string, string) error
+		UnsetSecret(string, string) error
+		RemoveSection(string)
 		ExportEnv(context.Context) error
+		ExportSectionEnv(string) error
+		Path() string
+		Replace(context.Context, []byte) error
 	}
 
 	// IniConfig represents a config stored in ini file
 	IniConfig struct {
-		path string
-		file *ini.File
+		path    string
+		file    *ini.File
+		profile string
+		keyring keyring.Keyring
 	}
 
 	contextType string
@@ -64,13 +96,13 @@ func NewIni() (*IniConfig, error) {
 	}
 	if _, err = os.Stat(path); os.IsNotExist(err) {
 		iniFile := ini.Empty()
-		return &IniConfig{path: path, file: iniFile}, nil
+		return &IniConfig{path: path, file: iniFile, keyring: keyring.System()}, nil
 	}
 	iniFile, err := ini.Load(path)
 	if err != nil {
 		return nil, err
 	}
-	return &IniConfig{path: path, file: iniFile}, nil
+	return &IniConfig{path: path, file: iniFile, keyring: keyring.System()}, nil
 }
 
 // Context sets the config in the context
@@ -88,10 +120,11 @@ func Get(ctx context.Context) Config {
 	return t
 }
 
-// Save stores the ini file in filesystem
+// Save stores the ini file in filesystem.
+// The file is replaced atomically while holding a lock, so that Akamai CLI instances running at the same time never leave a corrupted file.
 func (c *IniConfig) Save(ctx context.Context) error {
 	term := terminal.Get(ctx)
-	if err := c.file.SaveTo(c.path); err != nil {
+	if err := c.save(ctx); err != nil {
 		term.Writeln(err.Error())
 		log.FromContext(ctx).Error(err.Error())
 		return err
@@ -100,26 +133,101 @@ func (c *IniConfig) Save(ctx context.Context) error {
 	return nil
 }
 
-// Values returns a map containing sections from the config. Each section contans a key-value map of its contents
+func (c *IniConfig) save(ctx context.Context) error {
+	buf := &bytes.Buffer{}
+	if _, err := c.file.WriteTo(buf); err != nil {
+		return err
+	}
+	return c.write(ctx, buf.Bytes())
+}
+
+func (c *IniConfig) write(ctx context.Context, data []byte) error {
+	value, _ := c.GetValue("cli", "lock-timeout")
+	timeout, err := tools.ParseLockTimeout(value)
+	if err != nil {
+		log.FromContext(ctx).Warnf("Invalid lock timeout, using default: %s", err)
+	}
+	lock, err := tools.LockFile(ctx, c.path+".lock", timeout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.FromContext(ctx).Errorf("Unable to unlock config file: %s", err)
+		}
+	}()
+
+	return tools.WriteFileAtomic(c.path, data, 0644)
+}
+
+// Path returns the location of the ini file
+func (c *IniConfig) Path() string {
+	return c.path
+}
+
+// Replace replaces the ini file with provided contents, as written by the user, and loads settings from them.
+// The file is written in the same way as by Save, and left unchanged if the contents can't be parsed.
+func (c *IniConfig) Replace(ctx context.Context, data []byte) error {
+	file, err := ini.Load(data)
+	if err != nil {
+		return err
+	}
+	if err := c.write(ctx, data); err != nil {
+		return err
+	}
+	c.file = file
+	return nil
+}
+
+// ProfileSection returns the name of the config section storing settings of given profile.
+// Keys of a profile section are settings in <section>.<key> format, which override settings outside of the profile.
+func ProfileSection(profile string) string {
+	return profileSectionPrefix + profile
+}
+
+// ProfileName returns the name of the profile stored in given config section, if it is a profile section
+func ProfileName(section string) (string, bool) {
+	if !strings.HasPrefix(section, profileSectionPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(section, profileSectionPrefix), true
+}
+
+// UseProfile activates settings of given profile, so that they take precedence over other settings
+// in GetValue and ExportEnv. An empty name deactivates the profile.
+func (c *IniConfig) UseProfile(profile string) {
+	c.profile = profile
+}
+
+// Values returns a map containing sections from the config. Each section contans a key-value map of its contents.
+// Secrets stored in the OS keyring are read from it, and left out if they can't be read.
 func (c *IniConfig) Values() map[string]map[string]string {
 	sections := make(map[string]map[string]string)
 	for _, section := range c.file.Sections() {
 		values := make(map[string]string)
 		for _, key := range section.Keys() {
-			values[key.Name()] = key.String()
+			if value, ok := c.secret(section.Name(), key.Name(), key.String()); ok {
+				values[key.Name()] = value
+			}
 		}
 		sections[section.Name()] = values
 	}
 	return sections
 }
 
-// GetValue fetches a value from provided section under provided key
+// GetValue fetches a value from provided section under provided key.
+// A secret stored in the OS keyring is read from it, and reported as not set if it can't be read.
 func (c *IniConfig) GetValue(section, key string) (string, bool) {
+	if c.profile != "" {
+		if profile, err := c.file.GetSection(ProfileSection(c.profile)); err == nil && profile.HasKey(section+"."+key) {
+			return c.secret(profile.Name(), section+"."+key, profile.Key(section+"."+key).String())
+		}
+	}
 	s := c.file.Section(section)
 	if !s.HasKey(key) {
 		return "", false
 	}
-	return s.Key(key).String(), true
+	return c.secret(section, key, s.Key(key).String())
 }
 
 // SetValue sets a key in provided section
@@ -134,32 +242,224 @@ func (c *IniConfig) UnsetValue(section, key string) {
 	s.DeleteKey(key)
 }
 
+// SetSecret sets a key holding a secret in provided section.
+// Unless cli.secret-storage is set to "file", the secret is stored in the OS keyring and the config file only marks it as stored there.
+func (c *IniConfig) SetSecret(section, key, value string) error {
+	storage, err := ParseSecretStorage(c.rawValue("cli", "secret-storage"))
+	if err != nil {
+		return err
+	}
+	if storage == SecretStorageFile {
+		if err := c.deleteSecret(section, key); err != nil {
+			return err
+		}
+		c.SetValue(section, key, value)
+		return nil
+	}
+
+	if err := c.keyring.Set(keyringService, secretAccount(section, key), value); err != nil {
+		return err
+	}
+	c.SetValue(section, key, KeyringValue)
+	return nil
+}
+
+// UnsetSecret unsets a key holding a secret in provided section, removing the secret from the OS keyring if it is stored there
+func (c *IniConfig) UnsetSecret(section, key string) error {
+	if err := c.deleteSecret(section, key); err != nil {
+		return err
+	}
+	c.UnsetValue(section, key)
+	return nil
+}
+
+func (c *IniConfig) deleteSecret(section, key string) error {
+	if c.rawValue(section, key) != KeyringValue {
+		return nil
+	}
+	if err := c.keyring.Delete(keyringService, secretAccount(section, key)); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// secret returns the value of given key as stored in the config file, or read from the OS keyring if the file marks it as stored there
+func (c *IniConfig) secret(section, key, value string) (string, bool) {
+	if value != KeyringValue {
+		return value, true
+	}
+	if c.keyring == nil {
+		return "", false
+	}
+	secret, err := c.keyring.Get(keyringService, secretAccount(section, key))
+	return secret, err == nil
+}
+
+// rawValue returns the value of given key as stored in the config file, ignoring the active profile
+func (c *IniConfig) rawValue(section, key string) string {
+	s := c.file.Section(section)
+	if !s.HasKey(key) {
+		return ""
+	}
+	return s.Key(key).String()
+}
+
+func secretAccount(section, key string) string {
+	return section + "." + key
+}
+
+// IsSecretKey returns true if provided key holds a secret: an access token, a password, or a header sent to a package registry.
+// For profile sections, the key is a setting in <section>.<key> format.
+func IsSecretKey(section, key string) bool {
+	if _, ok := ProfileName(section); ok {
+		path := strings.SplitN(key, ".", 2)
+		if len(path) != 2 {
+			return false
+		}
+		section, key = path[0], path[1]
+	}
+	if section == AliasesSection {
+		return false
+	}
+	if section == RegistriesSection {
+		// registry settings are stored as <registry>.<setting>
+		key = key[strings.LastIndex(key, ".")+1:]
+		if key == "header" {
+			return true
+		}
+	}
+	for _, word := range secretKeyWords {
+		if strings.Contains(strings.ToLower(key), word) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSecretStorage validates the value of cli.secret-storage, which defaults to SecretStorageKeyring
+func ParseSecretStorage(value string) (string, error) {
+	switch value {
+	case "", SecretStorageKeyring:
+		return SecretStorageKeyring, nil
+	case SecretStorageFile:
+		return SecretStorageFile, nil
+	}
+	return "", fmt.Errorf("invalid secret storage %q, expected %s or %s", value, SecretStorageKeyring, SecretStorageFile)
+}
+
+// RemoveSection removes provided section along with all of its keys
+func (c *IniConfig) RemoveSection(section string) {
+	c.file.DeleteSection(section)
+}
+
 // ExportEnv exports values from config file as environmental variables, prefixing each with AKAMAI_<SECTION_NAME>
+// Values of the active profile replace values of the same settings, other profiles, registries and aliases are not exported.
+// Secrets, such as install.github-token, are not exported: commands reading them get them from the config when needed,
+// so that they aren't read from the keyring on each run, and aren't passed to every executed package.
+// Secrets of packages are exported only to the package providing the command, by ExportSectionEnv.
 // It also attempts migration from previous config versions
 func (c *IniConfig) ExportEnv(ctx context.Context) error {
 	if err := migrateConfig(ctx, c); err != nil {
 		return err
 	}
 
+	for _, section := range c.exportedSections() {
+		if err := c.exportSection(section, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportedSections returns the names of the sections set in the config file or in the active profile, along with
+// globalSections, except for registries, aliases and profiles
+func (c *IniConfig) exportedSections() []string {
+	names := append([]string{}, globalSections...)
+	add := func(name string) {
+		if _, ok := ProfileName(name); ok || name == RegistriesSection || name == AliasesSection {
+			return
+		}
+		for _, n := range names {
+			if n == name {
+				return
+			}
+		}
+		names = append(names, name)
+	}
 	for _, section := range c.file.Sections() {
+		add(section.Name())
+	}
+	if c.profile == "" {
+		return names
+	}
+	if profile, err := c.file.GetSection(ProfileSection(c.profile)); err == nil {
+		for _, key := range profile.Keys() {
+			if path := strings.SplitN(key.Name(), ".", 2); len(path) == 2 {
+				add(path[0])
+			}
+		}
+	}
+	return names
+}
+
+// ExportSectionEnv exports values of given section, including the ones set in the active profile, as environmental variables.
+// Sections other than cli and install are named after commands. They are exported to all commands by ExportEnv, secrets aside,
+// and given to the package providing the command with its secrets.
+func (c *IniConfig) ExportSectionEnv(name string) error {
+	return c.exportSection(name, true)
+}
+
+// exportSection exports values of given section and of the active profile, skipping secrets unless withSecrets is set
+func (c *IniConfig) exportSection(name string, withSecrets bool) error {
+	if section, err := c.file.GetSection(name); err == nil {
 		for _, key := range section.Keys() {
-			envVar := "AKAMAI_" + strings.ToUpper(section.Name()) + "_"
-			envVar += strings.ToUpper(strings.Replace(key.Name(), "-", "_", -1))
-			if err := os.Setenv(envVar, key.String()); err != nil {
+			if !withSecrets && IsSecretKey(name, key.Name()) {
+				continue
+			}
+			value, ok := c.secret(section.Name(), key.Name(), key.String())
+			if !ok {
+				continue
+			}
+			if err := os.Setenv(configEnvName(name, key.Name()), value); err != nil {
 				return err
 			}
 		}
 	}
+
+	if c.profile == "" {
+		return nil
+	}
+	profile, err := c.file.GetSection(ProfileSection(c.profile))
+	if err != nil {
+		return nil
+	}
+	for _, key := range profile.Keys() {
+		path := strings.SplitN(key.Name(), ".", 2)
+		if len(path) != 2 || path[0] != name || (!withSecrets && IsSecretKey(path[0], path[1])) {
+			continue
+		}
+		value, ok := c.secret(profile.Name(), key.Name(), key.String())
+		if !ok {
+			continue
+		}
+		if err := os.Setenv(configEnvName(path[0], path[1]), value); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func configEnvName(section, key string) string {
+	return "AKAMAI_" + strings.ToUpper(strings.Replace(section+"_"+key, "-", "_", -1))
+}
+
 func getConfigFilePath() (string, error) {
-	cliPath, err := tools.GetAkamaiCliPath()
+	configPath, err := tools.GetAkamaiCliConfigPath()
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(cliPath, "config"), nil
+	return filepath.Join(configPath, "config"), nil
 }
 
 func migrateConfig(ctx context.Context, cfg *IniConfig) error {
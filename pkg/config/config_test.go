@@ -2,9 +2,13 @@ package config
 
 import (
 	"context"
+	"errors"
+	"github.com/akamai/cli/pkg/keyring"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
 	"github.com/go-ini/ini"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"io/ioutil"
 	"os"
@@ -67,6 +71,54 @@ func TestSave(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSaveLocked(t *testing.T) {
+	dir, err := ioutil.TempDir(".", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", dir))
+	cfg, err := NewIni()
+	require.NoError(t, err)
+	cfg.SetValue("cli", "lock-timeout", "0s")
+
+	lock, err := tools.LockFile(context.Background(), cfg.path+".lock", 0)
+	require.NoError(t, err)
+	term := &terminal.Mock{}
+	term.On("Writeln", mock.Anything).Return(0, nil).Once()
+	err = cfg.Save(terminal.Context(context.Background(), term))
+	assert.True(t, errors.Is(err, tools.ErrLocked))
+	_, err = os.Stat(cfg.path)
+	assert.True(t, os.IsNotExist(err), "config should not be written while locked")
+
+	require.NoError(t, lock.Unlock())
+	assert.NoError(t, cfg.Save(terminal.Context(context.Background(), term)))
+	_, err = os.Stat(cfg.path)
+	assert.NoError(t, err)
+	term.AssertExpectations(t)
+}
+
+func TestReplace(t *testing.T) {
+	dir, err := ioutil.TempDir(".", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", dir))
+	cfg, err := NewIni()
+	require.NoError(t, err)
+	ctx := terminal.Context(context.Background(), &terminal.Mock{})
+
+	data := []byte("[cli]\n# edited\nchannel = beta\n")
+	require.NoError(t, cfg.Replace(ctx, data))
+	written, err := ioutil.ReadFile(cfg.Path())
+	require.NoError(t, err)
+	assert.Equal(t, data, written)
+	val, _ := cfg.GetValue("cli", "channel")
+	assert.Equal(t, "beta", val)
+
+	assert.Error(t, cfg.Replace(ctx, []byte("[cli\n")))
+	written, err = ioutil.ReadFile(cfg.Path())
+	require.NoError(t, err)
+	assert.Equal(t, data, written)
+}
+
 func TestContext(t *testing.T) {
 	cfg := IniConfig{
 		path: "test",
@@ -200,6 +252,125 @@ func TestUnsetValue(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestSetSecret(t *testing.T) {
+	tests := map[string]struct {
+		storage   string
+		init      func(*keyring.Mock)
+		withError error
+		expected  string
+	}{
+		"secret stored in keyring": {
+			init: func(k *keyring.Mock) {
+				k.On("Set", "akamai-cli", "install.github-token", "ghp_abc").Return(nil).Once()
+			},
+			expected: KeyringValue,
+		},
+		"keyring unavailable": {
+			init: func(k *keyring.Mock) {
+				k.On("Set", "akamai-cli", "install.github-token", "ghp_abc").Return(keyring.ErrUnavailable).Once()
+			},
+			withError: keyring.ErrUnavailable,
+		},
+		"secret stored in file": {
+			storage:  SecretStorageFile,
+			init:     func(k *keyring.Mock) {},
+			expected: "ghp_abc",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			k := &keyring.Mock{}
+			test.init(k)
+			cfg := &IniConfig{path: "test", file: ini.Empty(), keyring: keyring.Cached(k)}
+			if test.storage != "" {
+				cfg.SetValue("cli", "secret-storage", test.storage)
+			}
+			err := cfg.SetSecret("install", "github-token", "ghp_abc")
+			k.AssertExpectations(t)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError))
+				_, ok := cfg.GetValue("install", "github-token")
+				assert.False(t, ok)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, cfg.file.Section("install").Key("github-token").String())
+			val, ok := cfg.GetValue("install", "github-token")
+			assert.True(t, ok)
+			assert.Equal(t, "ghp_abc", val)
+		})
+	}
+}
+
+func TestKeyringSecrets(t *testing.T) {
+	k := &keyring.Mock{}
+	k.On("Get", "akamai-cli", "registries.internal.header").Return("Authorization: Bearer abc", nil).Once()
+	k.On("Get", "akamai-cli", "profile staging.install.github-token").Return("", keyring.ErrNotFound).Once()
+	cfg := &IniConfig{path: "test", file: ini.Empty(), keyring: keyring.Cached(k)}
+	cfg.SetValue(RegistriesSection, "internal.header", KeyringValue)
+	cfg.SetValue(ProfileSection("staging"), "install.github-token", KeyringValue)
+
+	assert.Equal(t, "Authorization: Bearer abc", cfg.Values()[RegistriesSection]["internal.header"])
+	val, ok := cfg.GetValue(RegistriesSection, "internal.header")
+	assert.True(t, ok)
+	assert.Equal(t, "Authorization: Bearer abc", val)
+
+	cfg.UseProfile("staging")
+	_, ok = cfg.GetValue("install", "github-token")
+	assert.False(t, ok)
+	_, ok = cfg.Values()[ProfileSection("staging")]["install.github-token"]
+	assert.False(t, ok)
+	k.AssertExpectations(t)
+}
+
+func TestUnsetSecret(t *testing.T) {
+	k := &keyring.Mock{}
+	k.On("Delete", "akamai-cli", "install.github-token").Return(keyring.ErrNotFound).Once()
+	cfg := &IniConfig{path: "test", file: ini.Empty(), keyring: keyring.Cached(k)}
+	cfg.SetValue("install", "github-token", KeyringValue)
+	cfg.SetValue("install", "gitlab-token", "glpat-abc")
+
+	require.NoError(t, cfg.UnsetSecret("install", "github-token"))
+	require.NoError(t, cfg.UnsetSecret("install", "gitlab-token"))
+	assert.Empty(t, cfg.file.Section("install").Keys())
+	k.AssertExpectations(t)
+}
+
+func TestIsSecretKey(t *testing.T) {
+	tests := map[string]struct {
+		section  string
+		key      string
+		expected bool
+	}{
+		"access token":              {section: "install", key: "github-token", expected: true},
+		"password":                  {section: "echo", key: "db-password", expected: true},
+		"registry header":           {section: RegistriesSection, key: "internal.header", expected: true},
+		"registry url":              {section: RegistriesSection, key: "internal.url"},
+		"profile access token":      {section: ProfileSection("staging"), key: "install.gitlab-token", expected: true},
+		"profile registry header":   {section: ProfileSection("staging"), key: "registries.internal.header", expected: true},
+		"profile setting":           {section: ProfileSection("staging"), key: "cli.channel"},
+		"header outside registries": {section: "echo", key: "header"},
+		"alias":                     {section: AliasesSection, key: "token"},
+		"setting":                   {section: "cli", key: "last-upgrade-check"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsSecretKey(test.section, test.key))
+		})
+	}
+}
+
+func TestRemoveSection(t *testing.T) {
+	cfg := IniConfig{path: "test", file: ini.Empty()}
+	cfg.SetValue("echo", "testKey", "abc")
+	cfg.SetValue("cli", "testKey", "abc")
+	cfg.RemoveSection("echo")
+	_, ok := cfg.Values()["echo"]
+	assert.False(t, ok)
+	_, ok = cfg.GetValue("cli", "testKey")
+	assert.True(t, ok)
+}
+
 func TestExportConfigEnv(t *testing.T) {
 	tests := map[string]struct {
 		givenValues      map[string]string
@@ -340,3 +511,160 @@ func TestExportConfigEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestProfile(t *testing.T) {
+	cfg := &IniConfig{path: "test", file: ini.Empty()}
+	cfg.SetValue("cli", "key1", "default1")
+	cfg.SetValue("cli", "key2", "default2")
+	cfg.SetValue(ProfileSection("staging"), "cli.key1", "staging1")
+	cfg.SetValue(ProfileSection("staging"), "install.github-token", "token")
+
+	val, _ := cfg.GetValue("cli", "key1")
+	assert.Equal(t, "default1", val)
+	_, ok := cfg.GetValue("install", "github-token")
+	assert.False(t, ok)
+
+	cfg.UseProfile("staging")
+	val, _ = cfg.GetValue("cli", "key1")
+	assert.Equal(t, "staging1", val)
+	val, _ = cfg.GetValue("cli", "key2")
+	assert.Equal(t, "default2", val)
+	val, ok = cfg.GetValue("install", "github-token")
+	assert.True(t, ok)
+	assert.Equal(t, "token", val)
+
+	cfg.UseProfile("production")
+	val, _ = cfg.GetValue("cli", "key1")
+	assert.Equal(t, "default1", val)
+
+	name, ok := ProfileName(ProfileSection("staging"))
+	assert.True(t, ok)
+	assert.Equal(t, "staging", name)
+	_, ok = ProfileName("cli")
+	assert.False(t, ok)
+}
+
+func TestExportConfigEnvProfile(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "test")
+	require.NoError(t, err)
+	defer func() {
+		os.RemoveAll(dir)
+	}()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", dir))
+	cfg, err := NewIni()
+	require.NoError(t, err)
+	ctx := terminal.Context(context.Background(), &terminal.Mock{})
+	cfg.SetValue("cli", "config-version", "1.1")
+	cfg.SetValue("cli", "some-key", "default")
+	cfg.SetValue(ProfileSection("staging"), "cli.some-key", "staging")
+	cfg.SetValue(ProfileSection("staging"), "install.github-token", "token")
+	cfg.SetValue(ProfileSection("production"), "cli.other-key", "production")
+	cfg.UseProfile("staging")
+
+	require.NoError(t, cfg.ExportEnv(ctx))
+	expectedEnvs := map[string]string{
+		"AKAMAI_CLI_SOME_KEY":         "staging",
+		"AKAMAI_INSTALL_GITHUB_TOKEN": "",
+		"AKAMAI_CLI_OTHER_KEY":        "",
+	}
+	for k, v := range expectedEnvs {
+		assert.Equal(t, v, os.Getenv(k), k)
+		require.NoError(t, os.Unsetenv(k))
+	}
+	require.NoError(t, os.Unsetenv("AKAMAI_CLI_CONFIG_VERSION"))
+}
+
+func TestExportConfigEnvRegistriesAndAliases(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "test")
+	require.NoError(t, err)
+	defer func() {
+		os.RemoveAll(dir)
+	}()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", dir))
+	cfg, err := NewIni()
+	require.NoError(t, err)
+	ctx := terminal.Context(context.Background(), &terminal.Mock{})
+	cfg.SetValue("cli", "config-version", "1.1")
+	cfg.SetValue(RegistriesSection, "internal.url", "https://example.com")
+	cfg.SetValue(RegistriesSection, "internal.header", "Authorization: Bearer token")
+	cfg.SetValue(AliasesSection, "pls", "property list --json")
+
+	require.NoError(t, cfg.ExportEnv(ctx))
+	assert.Equal(t, "", os.Getenv("AKAMAI_REGISTRIES_INTERNAL.URL"))
+	assert.Equal(t, "", os.Getenv("AKAMAI_REGISTRIES_INTERNAL.HEADER"))
+	assert.Equal(t, "", os.Getenv("AKAMAI_ALIASES_PLS"))
+	require.NoError(t, os.Unsetenv("AKAMAI_CLI_CONFIG_VERSION"))
+}
+
+func TestExportConfigEnvSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "test")
+	require.NoError(t, err)
+	defer func() {
+		os.RemoveAll(dir)
+	}()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", dir))
+	k := &keyring.Mock{}
+	k.On("Get", "akamai-cli", "property.api-token").Return("secret", nil).Once()
+	cfg := &IniConfig{path: filepath.Join(dir, "config"), file: ini.Empty(), keyring: keyring.Cached(k)}
+	ctx := terminal.Context(context.Background(), &terminal.Mock{})
+	cfg.SetValue("cli", "config-version", "1.1")
+	cfg.SetValue("cli", "proxy", "http://proxy:3128")
+	cfg.SetValue("install", "github-token", KeyringValue)
+	cfg.SetValue("install", "gitlab-token", "glpat-abc")
+	cfg.SetValue("property", "api-token", KeyringValue)
+
+	require.NoError(t, cfg.ExportEnv(ctx))
+	for _, env := range []string{"AKAMAI_INSTALL_GITHUB_TOKEN", "AKAMAI_INSTALL_GITLAB_TOKEN", "AKAMAI_PROPERTY_API_TOKEN"} {
+		_, ok := os.LookupEnv(env)
+		assert.False(t, ok, env)
+	}
+	assert.Equal(t, "http://proxy:3128", os.Getenv("AKAMAI_CLI_PROXY"))
+
+	// secrets of a package are passed to its commands, read from the keyring only when one runs
+	require.NoError(t, cfg.ExportSectionEnv("property"))
+	assert.Equal(t, "secret", os.Getenv("AKAMAI_PROPERTY_API_TOKEN"))
+	k.AssertExpectations(t)
+	for _, env := range []string{"AKAMAI_CLI_CONFIG_VERSION", "AKAMAI_CLI_PROXY", "AKAMAI_PROPERTY_API_TOKEN"} {
+		require.NoError(t, os.Unsetenv(env))
+	}
+}
+
+func TestExportSectionEnv(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "test")
+	require.NoError(t, err)
+	defer func() {
+		os.RemoveAll(dir)
+	}()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", dir))
+	cfg, err := NewIni()
+	require.NoError(t, err)
+	ctx := terminal.Context(context.Background(), &terminal.Mock{})
+	cfg.SetValue("cli", "config-version", "1.1")
+	cfg.SetValue("property", "default-group", "123")
+	cfg.SetValue("property", "contract", "C-1")
+	cfg.SetValue("property-manager", "format", "json")
+	cfg.SetValue(ProfileSection("staging"), "property.default-group", "456")
+	cfg.SetValue(ProfileSection("staging"), "purge.network", "staging")
+	cfg.UseProfile("staging")
+
+	// settings of packages are exported to all commands as well, which existing packages may rely on
+	require.NoError(t, cfg.ExportEnv(ctx))
+	assert.Equal(t, "456", os.Getenv("AKAMAI_PROPERTY_DEFAULT_GROUP"))
+	assert.Equal(t, "staging", os.Getenv("AKAMAI_PURGE_NETWORK"))
+	require.NoError(t, os.Unsetenv("AKAMAI_PROPERTY_DEFAULT_GROUP"))
+	require.NoError(t, os.Unsetenv("AKAMAI_PURGE_NETWORK"))
+
+	require.NoError(t, cfg.ExportSectionEnv("property"))
+	require.NoError(t, cfg.ExportSectionEnv("property-manager"))
+	expectedEnvs := map[string]string{
+		"AKAMAI_PROPERTY_DEFAULT_GROUP":  "456",
+		"AKAMAI_PROPERTY_CONTRACT":       "C-1",
+		"AKAMAI_PROPERTY_MANAGER_FORMAT": "json",
+		"AKAMAI_PURGE_NETWORK":           "",
+	}
+	for k, v := range expectedEnvs {
+		assert.Equal(t, v, os.Getenv(k), k)
+		require.NoError(t, os.Unsetenv(k))
+	}
+	require.NoError(t, os.Unsetenv("AKAMAI_CLI_CONFIG_VERSION"))
+}
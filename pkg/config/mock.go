@@ -41,8 +41,43 @@ func (m *Mock) UnsetValue(section string, key string) {
 	m.Called(section, key)
 }
 
+// RemoveSection mock
+func (m *Mock) RemoveSection(section string) {
+	m.Called(section)
+}
+
 // ExportEnv mock
 func (m *Mock) ExportEnv(_ context.Context) error {
 	args := m.Called()
 	return args.Error(0)
 }
+
+// ExportSectionEnv mock
+func (m *Mock) ExportSectionEnv(section string) error {
+	args := m.Called(section)
+	return args.Error(0)
+}
+
+// SetSecret mock
+func (m *Mock) SetSecret(section string, key string, value string) error {
+	args := m.Called(section, key, value)
+	return args.Error(0)
+}
+
+// UnsetSecret mock
+func (m *Mock) UnsetSecret(section string, key string) error {
+	args := m.Called(section, key)
+	return args.Error(0)
+}
+
+// Path mock
+func (m *Mock) Path() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+// Replace mock
+func (m *Mock) Replace(_ context.Context, data []byte) error {
+	args := m.Called(data)
+	return args.Error(0)
+}
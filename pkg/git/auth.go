@@ -0,0 +1,163 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+)
+
+// tokenSource describes where an access token for a git hosting service is configured
+type tokenSource struct {
+	host      string
+	configKey string
+	env       string
+	username  string
+}
+
+// tokenSources lists hosts for which an access token can be set with "akamai config set install.<key> <token>" or an environment variable
+var tokenSources = []tokenSource{
+	{host: "github.com", configKey: "github-token", env: "AKAMAI_CLI_GITHUB_TOKEN", username: "x-access-token"},
+	{host: "gitlab.com", configKey: "gitlab-token", env: "AKAMAI_CLI_GITLAB_TOKEN", username: "oauth2"},
+}
+
+// credentialFill returns credentials for given endpoint from configured git credential helpers
+var credentialFill = gitCredentialFill
+
+// withAuth runs a remote operation on the repository at given URL with credentials resolved for it.
+// If the remote requires authentication and no credentials were configured, the operation is retried with credentials from git credential helpers.
+func withAuth(ctx context.Context, url string, op func(auth transport.AuthMethod) error) error {
+	logger := log.FromContext(ctx)
+	auth, err := authMethod(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	err = op(auth)
+	if auth != nil || !(errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed)) {
+		return err
+	}
+
+	endpoint, epErr := transport.NewEndpoint(url)
+	if epErr != nil || !isHTTP(endpoint) {
+		return err
+	}
+	helperAuth, helperErr := credentialFill(ctx, endpoint)
+	if helperErr != nil {
+		logger.Debugf("Unable to get credentials from git credential helper: %s", helperErr)
+		return err
+	}
+	if helperAuth == nil {
+		return err
+	}
+	logger.Debugf("Retrying with credentials from git credential helper for %s", endpoint.Host)
+	return op(helperAuth)
+}
+
+// authMethod returns the authentication method used for the repository at given URL.
+// SSH URLs are authenticated using the SSH agent, HTTP URLs using the access token configured for the host, if any.
+func authMethod(ctx context.Context, url string) (transport.AuthMethod, error) {
+	if url == "" {
+		return nil, nil
+	}
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.Protocol == "ssh" {
+		user := endpoint.User
+		if user == "" {
+			user = ssh.DefaultUsername
+		}
+		auth, err := ssh.NewSSHAgentAuth(user)
+		if err != nil {
+			return nil, fmt.Errorf("unable to connect to SSH agent: %w", err)
+		}
+		return auth, nil
+	}
+
+	if !isHTTP(endpoint) || endpoint.User != "" {
+		// credentials given in the URL are used as they are
+		return nil, nil
+	}
+
+	for _, source := range tokenSources {
+		if !strings.EqualFold(endpoint.Host, source.host) {
+			continue
+		}
+		if token := source.token(ctx); token != "" {
+			log.FromContext(ctx).Debugf("Using %s access token", source.host)
+			return &http.BasicAuth{Username: source.username, Password: token}, nil
+		}
+	}
+	return nil, nil
+}
+
+// token returns the access token set in environment, which takes precedence over the CLI config.
+// The token set in the config is read from it only when needed, since secret settings are not exported to the environment.
+func (s tokenSource) token(ctx context.Context) string {
+	if token := os.Getenv(s.env); token != "" {
+		return token
+	}
+	if token, ok := config.Get(ctx).GetValue("install", s.configKey); ok {
+		return token
+	}
+	return ""
+}
+
+func isHTTP(endpoint *transport.Endpoint) bool {
+	return endpoint.Protocol == "http" || endpoint.Protocol == "https"
+}
+
+// gitCredentialFill asks git credential helpers for credentials using "git credential fill".
+// Interactive prompts are disabled, so nil is returned if no helper provides credentials.
+func gitCredentialFill(ctx context.Context, endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	gitBin, err := exec.LookPath("git")
+	if err != nil {
+		return nil, err
+	}
+
+	input := fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", endpoint.Protocol, endpoint.Host, strings.TrimPrefix(endpoint.Path, "/"))
+	cmd := exec.CommandContext(ctx, gitBin, "credential", "fill")
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	return parseCredentials(out.String()), nil
+}
+
+// parseCredentials reads username and password from "git credential fill" output
+func parseCredentials(output string) transport.AuthMethod {
+	auth := &http.BasicAuth{}
+	for _, line := range strings.Split(output, "\n") {
+		kv := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "username":
+			auth.Username = kv[1]
+		case "password":
+			auth.Password = kv[1]
+		}
+	}
+	if auth.Password == "" {
+		return nil
+	}
+	return auth
+}
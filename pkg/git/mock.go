@@ -21,8 +21,8 @@ func (m *Mock) Open(path string) error {
 }
 
 // Clone mock
-func (m *Mock) Clone(_ context.Context, path, repo string, isBare bool, progress terminal.Spinner) error {
-	args := m.Called(path, repo, isBare, progress)
+func (m *Mock) Clone(_ context.Context, path, repo string, isBare bool, depth int, progress terminal.Spinner) error {
+	args := m.Called(path, repo, isBare, depth, progress)
 	return args.Error(0)
 }
 
@@ -32,6 +32,12 @@ func (m *Mock) Pull(_ context.Context, worktree *git.Worktree) error {
 	return args.Error(0)
 }
 
+// Fetch mock
+func (m *Mock) Fetch(_ context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 // Head mock
 func (m *Mock) Head() (*plumbing.Reference, error) {
 	args := m.Called()
@@ -41,6 +47,30 @@ func (m *Mock) Head() (*plumbing.Reference, error) {
 	return args.Get(0).(*plumbing.Reference), args.Error(1)
 }
 
+// Reference mock
+func (m *Mock) Reference(name plumbing.ReferenceName) (*plumbing.Reference, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*plumbing.Reference), args.Error(1)
+}
+
+// ResolveRevision mock
+func (m *Mock) ResolveRevision(rev plumbing.Revision) (*plumbing.Hash, error) {
+	args := m.Called(rev)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*plumbing.Hash), args.Error(1)
+}
+
+// Checkout mock
+func (m *Mock) Checkout(worktree *git.Worktree, opts *git.CheckoutOptions) error {
+	args := m.Called(worktree, opts)
+	return args.Error(0)
+}
+
 // Worktree mock
 func (m *Mock) Worktree() (*git.Worktree, error) {
 	args := m.Called()
@@ -58,3 +88,27 @@ func (m *Mock) CommitObject(h plumbing.Hash) (*object.Commit, error) {
 	}
 	return args.Get(0).(*object.Commit), args.Error(1)
 }
+
+// Log mock
+func (m *Mock) Log(from, until plumbing.Hash, limit int) ([]*object.Commit, error) {
+	args := m.Called(from, until, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*object.Commit), args.Error(1)
+}
+
+// RemoteReference mock
+func (m *Mock) RemoteReference(_ context.Context, name plumbing.ReferenceName) (*plumbing.Reference, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*plumbing.Reference), args.Error(1)
+}
+
+// IsAncestor mock
+func (m *Mock) IsAncestor(ancestor, descendant plumbing.Hash) (bool, error) {
+	args := m.Called(ancestor, descendant)
+	return args.Bool(0), args.Error(1)
+}
@@ -2,13 +2,20 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/client"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
 
 	"gopkg.in/src-d/go-git.v4"
 
+	"github.com/akamai/cli/pkg/log"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
 )
 
 const (
@@ -19,17 +26,35 @@ const (
 // Repository interface.
 type Repository interface {
 	Open(path string) error
-	Clone(ctx context.Context, path, repo string, isBare bool, progress terminal.Spinner) error
+	// Clone clones repo into path. If depth is not 0, only the given number of commits is fetched from the tip of each branch and tag.
+	Clone(ctx context.Context, path, repo string, isBare bool, depth int, progress terminal.Spinner) error
 	Pull(ctx context.Context, worktree *git.Worktree) error
+	Fetch(ctx context.Context) error
+	// RemoteReference returns given reference of the default remote, such as refs/heads/master, without fetching any commit.
+	RemoteReference(ctx context.Context, name plumbing.ReferenceName) (*plumbing.Reference, error)
 	Head() (*plumbing.Reference, error)
+	Reference(name plumbing.ReferenceName) (*plumbing.Reference, error)
+	ResolveRevision(rev plumbing.Revision) (*plumbing.Hash, error)
+	Checkout(worktree *git.Worktree, opts *git.CheckoutOptions) error
 	Worktree() (*git.Worktree, error)
 	CommitObject(h plumbing.Hash) (*object.Commit, error)
+	Log(from, until plumbing.Hash, limit int) ([]*object.Commit, error)
+	// IsAncestor returns true if the ancestor commit is reachable from the descendant commit, or is the same commit.
+	// In a limited history, only the commits it holds are searched.
+	IsAncestor(ancestor, descendant plumbing.Hash) (bool, error)
 }
 
 type repository struct {
 	gitRepo *git.Repository
 }
 
+func init() {
+	// clones and pulls over http(s) go through the same proxy as other requests
+	httpClient := githttp.NewClient(tools.HTTPClient(0))
+	client.InstallProtocol("http", httpClient)
+	client.InstallProtocol("https", httpClient)
+}
+
 // NewRepository will initialize new git integrations instance.
 func NewRepository() Repository {
 	return &repository{}
@@ -44,20 +69,103 @@ func (r *repository) Open(path string) error {
 	return nil
 }
 
-func (r *repository) Clone(ctx context.Context, path, repo string, isBare bool, progress terminal.Spinner) error {
-	gitRepo, err := git.PlainCloneContext(ctx, path, isBare, &git.CloneOptions{
-		URL:      repo,
-		Progress: progress,
+func (r *repository) Clone(ctx context.Context, path, repo string, isBare bool, depth int, progress terminal.Spinner) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{"repo": repo, "path": path, "depth": depth})
+	logger.Debug("Cloning repository")
+	return logFailure(logger, "clone", withAuth(ctx, repo, func(auth transport.AuthMethod) error {
+		gitRepo, err := git.PlainCloneContext(ctx, path, isBare, &git.CloneOptions{
+			URL:      repo,
+			Auth:     auth,
+			Depth:    depth,
+			Progress: progress,
+		})
+		if err != nil {
+			return err
+		}
+		r.gitRepo = gitRepo
+		return nil
+	}))
+}
+
+func (r *repository) Pull(ctx context.Context, worktree *git.Worktree) error {
+	opts := &git.PullOptions{RemoteName: DefaultRemoteName}
+	// pull the checked out branch instead of remote HEAD, so that packages installed from a branch stay on it
+	if r.gitRepo != nil {
+		if head, err := r.gitRepo.Head(); err == nil && head.Name().IsBranch() {
+			opts.ReferenceName = head.Name()
+		}
+	}
+	logger := log.FromContext(ctx).WithFields(log.Fields{"repo": r.remoteURL(), "ref": opts.ReferenceName.String()})
+	logger.Debug("Pulling repository")
+	err := withAuth(ctx, r.remoteURL(), func(auth transport.AuthMethod) error {
+		opts.Auth = auth
+		return worktree.PullContext(ctx, opts)
 	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	return logFailure(logger, "pull", err)
+}
+
+func (r *repository) Fetch(ctx context.Context) error {
+	if r.gitRepo == nil {
+		return fmt.Errorf("repository is not yet initialized")
+	}
+	logger := log.FromContext(ctx).WithField("repo", r.remoteURL())
+	logger.Debug("Fetching repository")
+	err := withAuth(ctx, r.remoteURL(), func(auth transport.AuthMethod) error {
+		return r.gitRepo.FetchContext(ctx, &git.FetchOptions{RemoteName: DefaultRemoteName, Auth: auth})
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	return logFailure(logger, "fetch", err)
+}
+
+func (r *repository) RemoteReference(ctx context.Context, name plumbing.ReferenceName) (*plumbing.Reference, error) {
+	if r.gitRepo == nil {
+		return nil, fmt.Errorf("repository is not yet initialized")
+	}
+	remote, err := r.gitRepo.Remote(DefaultRemoteName)
 	if err != nil {
+		return nil, err
+	}
+	logger := log.FromContext(ctx).WithField("repo", r.remoteURL())
+	logger.Debug("Listing remote references")
+	var refs []*plumbing.Reference
+	err = withAuth(ctx, r.remoteURL(), func(auth transport.AuthMethod) error {
+		refs, err = remote.List(&git.ListOptions{Auth: auth})
 		return err
+	})
+	if err != nil {
+		return nil, logFailure(logger, "list references of", err)
 	}
-	r.gitRepo = gitRepo
-	return nil
+	for _, ref := range refs {
+		if ref.Name() == name {
+			return ref, nil
+		}
+	}
+	return nil, plumbing.ErrReferenceNotFound
 }
 
-func (r *repository) Pull(ctx context.Context, worktree *git.Worktree) error {
-	return worktree.PullContext(ctx, &git.PullOptions{RemoteName: DefaultRemoteName})
+// logFailure logs a failed remote operation along with the repository it was run on
+func logFailure(logger log.Logger, op string, err error) error {
+	if err != nil {
+		logger.WithError(err).Warnf("Unable to %s repository", op)
+	}
+	return err
+}
+
+// remoteURL returns the URL of the default remote, used to resolve credentials
+func (r *repository) remoteURL() string {
+	if r.gitRepo == nil {
+		return ""
+	}
+	remote, err := r.gitRepo.Remote(DefaultRemoteName)
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
 }
 
 func (r *repository) Head() (*plumbing.Reference, error) {
@@ -67,6 +175,24 @@ func (r *repository) Head() (*plumbing.Reference, error) {
 	return r.gitRepo.Head()
 }
 
+func (r *repository) Reference(name plumbing.ReferenceName) (*plumbing.Reference, error) {
+	if r.gitRepo == nil {
+		return nil, fmt.Errorf("repository is not yet initialized")
+	}
+	return r.gitRepo.Reference(name, true)
+}
+
+func (r *repository) ResolveRevision(rev plumbing.Revision) (*plumbing.Hash, error) {
+	if r.gitRepo == nil {
+		return nil, fmt.Errorf("repository is not yet initialized")
+	}
+	return r.gitRepo.ResolveRevision(rev)
+}
+
+func (r *repository) Checkout(worktree *git.Worktree, opts *git.CheckoutOptions) error {
+	return worktree.Checkout(opts)
+}
+
 func (r *repository) Worktree() (*git.Worktree, error) {
 	if r.gitRepo == nil {
 		return nil, fmt.Errorf("repository is not yet initialized")
@@ -80,3 +206,49 @@ func (r *repository) CommitObject(h plumbing.Hash) (*object.Commit, error) {
 	}
 	return r.gitRepo.CommitObject(h)
 }
+
+// Log returns at most limit commits reachable from the from commit, newest first, stopping at the until commit, which is not included.
+func (r *repository) Log(from, until plumbing.Hash, limit int) ([]*object.Commit, error) {
+	if r.gitRepo == nil {
+		return nil, fmt.Errorf("repository is not yet initialized")
+	}
+	iter, err := r.gitRepo.Log(&git.LogOptions{From: from, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var commits []*object.Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if c.Hash == until || len(commits) == limit {
+			return storer.ErrStop
+		}
+		commits = append(commits, c)
+		return nil
+	})
+	return commits, err
+}
+
+func (r *repository) IsAncestor(ancestor, descendant plumbing.Hash) (bool, error) {
+	if r.gitRepo == nil {
+		return false, fmt.Errorf("repository is not yet initialized")
+	}
+	ancestorCommit, err := r.gitRepo.CommitObject(ancestor)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		// a commit which wasn't fetched is not part of the history
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	descendantCommit, err := r.gitRepo.CommitObject(descendant)
+	if err != nil {
+		return false, err
+	}
+	found, err := ancestorCommit.IsAncestor(descendantCommit)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		// parents of the oldest commits of a limited history are missing
+		return found, nil
+	}
+	return found, err
+}
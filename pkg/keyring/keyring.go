@@ -0,0 +1,135 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keyring stores secrets in the keyring of the operating system: the Keychain on macOS, the Credential Manager
+// on Windows and the Secret Service (GNOME Keyring, KWallet) on other platforms
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+type (
+	// Keyring stores secrets identified by a service and a user
+	Keyring interface {
+		Get(service, user string) (string, error)
+		Set(service, user, secret string) error
+		Delete(service, user string) error
+	}
+)
+
+var (
+	// ErrNotFound is returned when the keyring holds no secret for given service and user
+	ErrNotFound = errors.New("secret not found in keyring")
+	// ErrUnavailable is returned when the keyring of the operating system can't be used, for example on headless systems
+	ErrUnavailable = errors.New("keyring is not available")
+)
+
+// system is shared by all configs, so that each secret is read from the keyring of the operating system once per process
+var system = Cached(systemKeyring{})
+
+// System returns the keyring of the operating system
+func System() Keyring {
+	return system
+}
+
+type (
+	// cachedKeyring remembers the secrets read from a keyring, as reading one runs a command line tool or prompts the user
+	cachedKeyring struct {
+		keyring Keyring
+		mu      sync.Mutex
+		secrets map[string]cachedSecret
+	}
+
+	cachedSecret struct {
+		value string
+		err   error
+	}
+)
+
+// Cached returns a keyring reading each secret from given keyring at most once, including secrets which are not found.
+// It is safe for concurrent use, and concurrent reads of the same secret wait for the first one.
+func Cached(k Keyring) Keyring {
+	return &cachedKeyring{keyring: k, secrets: make(map[string]cachedSecret)}
+}
+
+func cacheKey(service, user string) string {
+	return service + "\x00" + user
+}
+
+func (k *cachedKeyring) Get(service, user string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if cached, ok := k.secrets[cacheKey(service, user)]; ok {
+		return cached.value, cached.err
+	}
+	secret, err := k.keyring.Get(service, user)
+	k.secrets[cacheKey(service, user)] = cachedSecret{value: secret, err: err}
+	return secret, err
+}
+
+func (k *cachedKeyring) Set(service, user, secret string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.keyring.Set(service, user, secret); err != nil {
+		return err
+	}
+	k.secrets[cacheKey(service, user)] = cachedSecret{value: secret}
+	return nil
+}
+
+func (k *cachedKeyring) Delete(service, user string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.secrets, cacheKey(service, user))
+	return k.keyring.Delete(service, user)
+}
+
+// runTool runs a keyring command line tool with given standard input, and returns its standard output.
+// A tool which is not installed results in ErrUnavailable; a failed run in an *exec.ExitError carrying the standard error.
+func runTool(stdin string, name string, args ...string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitErr.Stderr = stderr.Bytes()
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// toolError returns an error describing a failed run of a keyring tool, using its standard error if any
+func toolError(name string, err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if msg := strings.TrimSpace(string(exitErr.Stderr)); msg != "" {
+			return fmt.Errorf("%s: %s", name, msg)
+		}
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
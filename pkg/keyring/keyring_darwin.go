@@ -0,0 +1,72 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyring
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errSecItemNotFound is the exit code of the security tool when the Keychain holds no matching item
+const errSecItemNotFound = 44
+
+// systemKeyring stores secrets in the login Keychain using the security tool
+type systemKeyring struct{}
+
+// Get returns the secret of given service and user
+func (systemKeyring) Get(service, user string) (string, error) {
+	out, err := runTool("", "security", "find-generic-password", "-s", service, "-a", user, "-w")
+	if err != nil {
+		return "", keychainError(err)
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+// Set stores the secret of given service and user, replacing any previous one.
+// The command is passed on standard input, so that the secret doesn't show in the list of processes.
+func (systemKeyring) Set(service, user, secret string) error {
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", quote(service), quote(user), hex.EncodeToString([]byte(secret)))
+	if _, err := runTool(command, "security", "-i"); err != nil {
+		return keychainError(err)
+	}
+	return nil
+}
+
+// Delete removes the secret of given service and user
+func (systemKeyring) Delete(service, user string) error {
+	if _, err := runTool("", "security", "delete-generic-password", "-s", service, "-a", user); err != nil {
+		return keychainError(err)
+	}
+	return nil
+}
+
+func keychainError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound {
+		return ErrNotFound
+	}
+	if errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	return toolError("security", err)
+}
+
+// quote quotes a value for the interactive mode of the security tool
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
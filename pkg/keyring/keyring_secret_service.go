@@ -0,0 +1,68 @@
+// +build !darwin,!windows
+
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// systemKeyring stores secrets using the Secret Service API through the secret-tool command from libsecret
+type systemKeyring struct{}
+
+// Get returns the secret of given service and user
+func (systemKeyring) Get(service, user string) (string, error) {
+	out, err := runTool("", "secret-tool", "lookup", "service", service, "account", user)
+	if err != nil {
+		var exitErr *exec.ExitError
+		// secret-tool fails without any message when no secret matches
+		if errors.As(err, &exitErr) && len(strings.TrimSpace(string(exitErr.Stderr))) == 0 {
+			return "", ErrNotFound
+		}
+		return "", secretToolError(err)
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+// Set stores the secret of given service and user, replacing any previous one.
+// The secret is passed on standard input, so that it doesn't show in the list of processes.
+func (systemKeyring) Set(service, user, secret string) error {
+	label := fmt.Sprintf("%s: %s", service, user)
+	if _, err := runTool(secret, "secret-tool", "store", "--label", label, "service", service, "account", user); err != nil {
+		return secretToolError(err)
+	}
+	return nil
+}
+
+// Delete removes the secret of given service and user
+func (systemKeyring) Delete(service, user string) error {
+	if _, err := runTool("", "secret-tool", "clear", "service", service, "account", user); err != nil {
+		return secretToolError(err)
+	}
+	return nil
+}
+
+// secretToolError reports failures of secret-tool as ErrUnavailable: they are caused by the lack of a Secret Service,
+// a D-Bus session or a display to unlock the keyring with, as on headless systems
+func secretToolError(err error) error {
+	if errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrUnavailable, toolError("secret-tool", err))
+}
@@ -0,0 +1,43 @@
+package keyring
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCached(t *testing.T) {
+	m := &Mock{}
+	m.On("Get", "akamai-cli", "install.github-token").Return("ghp_abc", nil).Once()
+	m.On("Get", "akamai-cli", "install.gitlab-token").Return("", ErrNotFound).Once()
+	m.On("Set", "akamai-cli", "install.gitlab-token", "glpat-abc").Return(nil).Once()
+	m.On("Delete", "akamai-cli", "install.github-token").Return(nil).Once()
+	m.On("Get", "akamai-cli", "install.github-token").Return("", ErrNotFound).Once()
+	k := Cached(m)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			secret, err := k.Get("akamai-cli", "install.github-token")
+			assert.NoError(t, err)
+			assert.Equal(t, "ghp_abc", secret)
+			_, err = k.Get("akamai-cli", "install.gitlab-token")
+			assert.Equal(t, ErrNotFound, err)
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, k.Set("akamai-cli", "install.gitlab-token", "glpat-abc"))
+	secret, err := k.Get("akamai-cli", "install.gitlab-token")
+	require.NoError(t, err)
+	assert.Equal(t, "glpat-abc", secret)
+
+	require.NoError(t, k.Delete("akamai-cli", "install.github-token"))
+	_, err = k.Get("akamai-cli", "install.github-token")
+	assert.Equal(t, ErrNotFound, err)
+	m.AssertExpectations(t)
+}
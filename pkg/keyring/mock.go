@@ -0,0 +1,28 @@
+package keyring
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock impl of Keyring interface
+type Mock struct {
+	mock.Mock
+}
+
+// Get mock
+func (m *Mock) Get(service, user string) (string, error) {
+	args := m.Called(service, user)
+	return args.String(0), args.Error(1)
+}
+
+// Set mock
+func (m *Mock) Set(service, user, secret string) error {
+	args := m.Called(service, user, secret)
+	return args.Error(0)
+}
+
+// Delete mock
+func (m *Mock) Delete(service, user string) error {
+	args := m.Called(service, user)
+	return args.Error(0)
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/apex/log"
+	"github.com/apex/log/handlers/json"
 	"github.com/apex/log/handlers/text"
 )
 
@@ -33,43 +34,84 @@ type (
 	// Logger is a type wrapper around log.Interface used to simplify imports in the project
 	Logger log.Interface
 
+	// Fields is a type alias of log.Fields, used to add fields to log entries
+	Fields = log.Fields
+
 	// Handler is a custom handler designed to work with or without colored output
 	Handler struct {
 		mu         sync.Mutex
 		Writer     io.Writer
 		withColors bool
 	}
+
+	// Options contains logging settings passed as command line flags, which take precedence over environment variables
+	Options struct {
+		Level  string
+		Path   string
+		Format string
+	}
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
 // SetupContext creates supplies a context.Context with new Logger instance
-// It handles setting up logging level and log output
-func SetupContext(ctx context.Context, defaultWriter io.Writer) context.Context {
+// It handles setting up logging level and log output. Options which are not set are read from environment variables:
+// level from AKAMAI_LOG or AKAMAI_CLI_LOG_LEVEL, path from AKAMAI_CLI_LOG_PATH and format from AKAMAI_CLI_LOG_FORMAT.
+// AKAMAI_CLI_* variables are also exported from config values, such as cli.log-level.
+func SetupContext(ctx context.Context, defaultWriter io.Writer, opts Options) context.Context {
 	logger := &log.Logger{
 		Level:   log.ErrorLevel,
 		Handler: text.New(defaultWriter),
 	}
 	output := defaultWriter
-	if lvlEnv := os.Getenv("AKAMAI_LOG"); lvlEnv != "" {
-		logLevel, err := log.ParseLevel(strings.ToLower(lvlEnv))
+	if level, source := optionValue(opts.Level, "--log-level", "AKAMAI_LOG", "AKAMAI_CLI_LOG_LEVEL"); level != "" {
+		logLevel, err := log.ParseLevel(strings.ToLower(level))
 		if err == nil {
 			logger.Level = logLevel
 		} else {
-			logger.Error("Unknown AKAMAI_LOG value. Allowed values: fatal, error, warn, warning, info, debug")
+			logger.Errorf("Unknown %s value. Allowed values: fatal, error, warn, warning, info, debug", source)
 		}
 	}
 	coloredOutput := true
-	if outputEnv := os.Getenv("AKAMAI_CLI_LOG_PATH"); outputEnv != "" {
-		f, err := os.OpenFile(outputEnv, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
+	if path, source := optionValue(opts.Path, "--log-file", "AKAMAI_CLI_LOG_PATH"); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
 		if err != nil {
-			logger.Errorf("Invalid value of AKAMAI_CLI_LOG_PATH %s", err)
+			logger.Errorf("Invalid value of %s %s", source, err)
+		} else {
+			coloredOutput = false
+			output = f
 		}
-		coloredOutput = false
-		output = f
 	}
 	logger.Handler = NewHandler(output, coloredOutput)
+	if format, source := optionValue(opts.Format, "--log-format", "AKAMAI_CLI_LOG_FORMAT"); format != "" {
+		switch strings.ToLower(format) {
+		case FormatJSON:
+			logger.Handler = json.New(output)
+		case FormatText:
+		default:
+			logger.Errorf("Unknown %s value. Allowed values: text, json", source)
+		}
+	}
 	return log.NewContext(ctx, logger)
 }
 
+// optionValue returns the option value if it is set, or the value of the first environment variable which is set, along with its source
+func optionValue(value, flag string, envs ...string) (string, string) {
+	if value != "" {
+		return value, flag
+	}
+	for _, env := range envs {
+		if value := os.Getenv(env); value != "" {
+			return value, env
+		}
+	}
+	return "", ""
+}
+
 // FromContext wraps log.FromContext function to simplify imports in the project
 func FromContext(ctx context.Context) Logger {
 	return log.FromContext(ctx)
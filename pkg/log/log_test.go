@@ -15,7 +15,10 @@ import (
 func TestSetupContext(t *testing.T) {
 	tests := map[string]struct {
 		envs          map[string]string
+		opts          Options
 		expectedLevel log.Level
+		expectedPath  string
+		expectedJSON  bool
 		withError     *regexp.Regexp
 	}{
 		"no envs passed, defaults are used": {
@@ -39,6 +42,47 @@ func TestSetupContext(t *testing.T) {
 			expectedLevel: log.ErrorLevel,
 			withError:     regexp.MustCompile(`ERROR.*Unknown AKAMAI_LOG value. Allowed values: fatal, error, warn, warning, info, debug`),
 		},
+		"level set in config": {
+			envs:          map[string]string{"AKAMAI_CLI_LOG_LEVEL": "info"},
+			expectedLevel: log.InfoLevel,
+		},
+		"AKAMAI_LOG overrides config": {
+			envs:          map[string]string{"AKAMAI_LOG": "warn", "AKAMAI_CLI_LOG_LEVEL": "info"},
+			expectedLevel: log.WarnLevel,
+		},
+		"flag overrides AKAMAI_LOG": {
+			envs:          map[string]string{"AKAMAI_LOG": "warn"},
+			opts:          Options{Level: "debug"},
+			expectedLevel: log.DebugLevel,
+		},
+		"invalid log level flag": {
+			opts:          Options{Level: "abc"},
+			expectedLevel: log.ErrorLevel,
+			withError:     regexp.MustCompile(`ERROR.*Unknown --log-level value`),
+		},
+		"log file flag overrides AKAMAI_CLI_LOG_PATH": {
+			envs:          map[string]string{"AKAMAI_CLI_LOG_PATH": "./ignored.txt"},
+			opts:          Options{Path: "./testlogs.txt"},
+			expectedLevel: log.ErrorLevel,
+			expectedPath:  "./testlogs.txt",
+		},
+		"json format, write logs to a file": {
+			envs:          map[string]string{"AKAMAI_CLI_LOG_FORMAT": "json"},
+			opts:          Options{Path: "./testlogs.txt"},
+			expectedLevel: log.ErrorLevel,
+			expectedPath:  "./testlogs.txt",
+			expectedJSON:  true,
+		},
+		"json format flag, output to terminal": {
+			opts:          Options{Format: "JSON"},
+			expectedLevel: log.ErrorLevel,
+			expectedJSON:  true,
+		},
+		"invalid log format": {
+			envs:          map[string]string{"AKAMAI_CLI_LOG_FORMAT": "xml"},
+			expectedLevel: log.ErrorLevel,
+			withError:     regexp.MustCompile(`ERROR.*Unknown AKAMAI_CLI_LOG_FORMAT value. Allowed values: text, json`),
+		},
 	}
 
 	for name, test := range tests {
@@ -52,7 +96,7 @@ func TestSetupContext(t *testing.T) {
 				}
 			}()
 			var buf bytes.Buffer
-			ctx := SetupContext(context.Background(), &buf)
+			ctx := SetupContext(context.Background(), &buf, test.opts)
 			logger := log.FromContext(ctx).(*log.Logger)
 			assert.Equal(t, test.expectedLevel, logger.Level)
 			if test.withError != nil {
@@ -60,14 +104,21 @@ func TestSetupContext(t *testing.T) {
 				return
 			}
 			logger.Error("test!")
-			if v, ok := test.envs["AKAMAI_CLI_LOG_PATH"]; ok {
-				res, err := ioutil.ReadFile(v)
+			path := test.expectedPath
+			if v, ok := test.envs["AKAMAI_CLI_LOG_PATH"]; ok && path == "" {
+				path = v
+			}
+			output := buf.String()
+			if path != "" {
+				res, err := ioutil.ReadFile(path)
 				require.NoError(t, err)
-				assert.Contains(t, string(res), "test!")
-				require.NoError(t, os.Remove(v))
-				return
+				require.NoError(t, os.Remove(path))
+				output = string(res)
+			}
+			assert.Contains(t, output, "test!")
+			if test.expectedJSON {
+				assert.Contains(t, output, `"message":"test!"`)
 			}
-			assert.Contains(t, buf.String(), "test!")
 		})
 	}
 }
@@ -92,7 +143,7 @@ func TestWithCommand(t *testing.T) {
 				require.NoError(t, os.Unsetenv("AKAMAI_CLI_LOG_PATH"))
 			}()
 			var buf bytes.Buffer
-			ctx := SetupContext(context.Background(), &buf)
+			ctx := SetupContext(context.Background(), &buf, Options{})
 			logger := WithCommand(ctx, "test")
 			logger.Error("abc")
 			if test.logFile != "" {
@@ -0,0 +1,89 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packages
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BuildCache is a package manager cache kept in the build cache directory, shared by all package builds
+type BuildCache struct {
+	// Name is the name of the cache directory
+	Name string
+	// Env is the variable pointing the package manager to the cache directory
+	Env string
+}
+
+// BuildCaches lists caches of the supported package managers
+var BuildCaches = []BuildCache{
+	{Name: "go-build", Env: "GOCACHE"},
+	{Name: "go-mod", Env: "GOMODCACHE"},
+	{Name: "pip", Env: "PIP_CACHE_DIR"},
+	{Name: "npm", Env: "npm_config_cache"},
+	{Name: "yarn", Env: "YARN_CACHE_FOLDER"},
+	{Name: "composer", Env: "COMPOSER_CACHE_DIR"},
+	{Name: "bundler", Env: "BUNDLE_USER_CACHE"},
+}
+
+var buildCacheContext contextType = "build-cache"
+
+// WithBuildCache returns a context in which Install points package managers to caches in given directory,
+// so that modules, wheels and build artifacts are reused by later installations and updates
+func WithBuildCache(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, buildCacheContext, dir)
+}
+
+// buildCacheEnv returns variables pointing package managers to caches in given directory.
+// Caches set in the environment take precedence.
+func buildCacheEnv(dir string) []string {
+	var env []string
+	for _, cache := range BuildCaches {
+		if _, ok := os.LookupEnv(cache.Env); ok {
+			continue
+		}
+		env = append(env, cache.Env+"="+filepath.Join(dir, cache.Name))
+	}
+	return env
+}
+
+// withEnv returns the environment of a command extended with given variables, unless the command already sets them
+func withEnv(cmdEnv, env []string) []string {
+	if len(env) == 0 {
+		return cmdEnv
+	}
+	if cmdEnv == nil {
+		cmdEnv = os.Environ()
+	}
+	result := append([]string{}, cmdEnv...)
+	for _, variable := range env {
+		name := strings.SplitN(variable, "=", 2)[0]
+		if !containsVar(cmdEnv, name) {
+			result = append(result, variable)
+		}
+	}
+	return result
+}
+
+func containsVar(env []string, name string) bool {
+	for _, variable := range env {
+		if strings.HasPrefix(variable, name+"=") {
+			return true
+		}
+	}
+	return false
+}
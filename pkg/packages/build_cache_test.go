@@ -0,0 +1,77 @@
+package packages
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cliLog "github.com/akamai/cli/pkg/log"
+)
+
+func TestBuildCacheEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("GOCACHE", "/tmp/go-build"))
+	defer func() {
+		require.NoError(t, os.Unsetenv("GOCACHE"))
+	}()
+	for _, cache := range BuildCaches {
+		if cache.Env != "GOCACHE" {
+			require.NoError(t, os.Unsetenv(cache.Env))
+		}
+	}
+
+	dir := filepath.Join("cache", "build")
+	assert.Equal(t, []string{
+		"GOMODCACHE=" + filepath.Join(dir, "go-mod"),
+		"PIP_CACHE_DIR=" + filepath.Join(dir, "pip"),
+		"npm_config_cache=" + filepath.Join(dir, "npm"),
+		"YARN_CACHE_FOLDER=" + filepath.Join(dir, "yarn"),
+		"COMPOSER_CACHE_DIR=" + filepath.Join(dir, "composer"),
+		"BUNDLE_USER_CACHE=" + filepath.Join(dir, "bundler"),
+	}, buildCacheEnv(dir))
+}
+
+func TestWithEnv(t *testing.T) {
+	tests := map[string]struct {
+		cmdEnv   []string
+		env      []string
+		expected []string
+	}{
+		"no variables": {
+			cmdEnv:   []string{"A=1"},
+			expected: []string{"A=1"},
+		},
+		"variables added": {
+			cmdEnv:   []string{"A=1"},
+			env:      []string{"B=2", "C=3"},
+			expected: []string{"A=1", "B=2", "C=3"},
+		},
+		"variables set by the command take precedence": {
+			cmdEnv:   []string{"A=1", "B=command"},
+			env:      []string{"B=2"},
+			expected: []string{"A=1", "B=command"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, withEnv(test.cmdEnv, test.env))
+		})
+	}
+}
+
+func TestLoggingExecutorBuildCache(t *testing.T) {
+	require.NoError(t, os.Unsetenv("PIP_CACHE_DIR"))
+	executor := &loggingExecutor{
+		executor: &defaultExecutor{},
+		logger:   &log.Logger{Level: log.DebugLevel, Handler: cliLog.NewHandler(os.Stderr, false)},
+		env:      buildCacheEnv("/tmp/akamai/build"),
+	}
+	output, err := executor.ExecCommand(exec.Command("sh", "-c", "echo $PIP_CACHE_DIR"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/tmp/akamai/build", "pip")+"\n", string(output))
+}
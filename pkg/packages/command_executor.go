@@ -1,8 +1,19 @@
 package packages
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
 )
 
 type (
@@ -12,7 +23,25 @@ type (
 		FileExists(string) (bool, error)
 	}
 
+	// contextExecutor is implemented by executors able to kill a command once a context is done
+	contextExecutor interface {
+		ExecCommandContext(ctx context.Context, cmd *exec.Cmd, withCombinedOutput ...bool) ([]byte, error)
+	}
+
 	defaultExecutor struct{}
+
+	// loggingExecutor logs every executed command, so that a failing installation step can be identified
+	// If progress is set, build steps are also reported to it, for example to a terminal spinner
+	// If ctx is set, no more commands are executed once it is done, e.g. when the installation is canceled or times out,
+	// and the running command is killed if the executor supports it
+	// env holds variables added to the environment of every command, such as build cache locations
+	loggingExecutor struct {
+		executor
+		ctx      context.Context
+		logger   log.Logger
+		progress io.Writer
+		env      []string
+	}
 )
 
 func (d *defaultExecutor) ExecCommand(cmd *exec.Cmd, withCombinedOutput ...bool) ([]byte, error) {
@@ -22,6 +51,22 @@ func (d *defaultExecutor) ExecCommand(cmd *exec.Cmd, withCombinedOutput ...bool)
 	return cmd.Output()
 }
 
+// ExecCommandContext executes the command like ExecCommand, killing it along with processes it spawned once ctx is done
+func (d *defaultExecutor) ExecCommandContext(ctx context.Context, cmd *exec.Cmd, withCombinedOutput ...bool) ([]byte, error) {
+	output, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = output, stderr
+	if len(withCombinedOutput) > 0 {
+		cmd.Stderr = output
+	}
+	err := tools.RunProcessGroup(ctx, cmd)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(withCombinedOutput) == 0 {
+		// as with cmd.Output, so that the reason of the failure can be logged
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return output.Bytes(), err
+}
+
 func (d *defaultExecutor) LookPath(file string) (string, error) {
 	return exec.LookPath(file)
 }
@@ -36,3 +81,57 @@ func (d *defaultExecutor) FileExists(path string) (bool, error) {
 	}
 	return true, nil
 }
+
+func (e *loggingExecutor) ExecCommand(cmd *exec.Cmd, withCombinedOutput ...bool) ([]byte, error) {
+	logger := e.logger.WithFields(log.Fields{
+		"cmd": strings.Join(cmd.Args, " "),
+		"dir": cmd.Dir,
+	})
+	if e.ctx != nil && e.ctx.Err() != nil {
+		logger.Debug("Command skipped, installation canceled")
+		return nil, e.ctx.Err()
+	}
+	logger.Debug("Executing command")
+	cmd.Env = withEnv(cmd.Env, e.env)
+	if e.progress != nil {
+		fmt.Fprintf(e.progress, "Running %s", stepName(cmd))
+	}
+	start := time.Now()
+	var output []byte
+	var err error
+	if ctxExecutor, ok := e.executor.(contextExecutor); ok && e.ctx != nil {
+		output, err = ctxExecutor.ExecCommandContext(e.ctx, cmd, withCombinedOutput...)
+	} else {
+		output, err = e.executor.ExecCommand(cmd, withCombinedOutput...)
+	}
+	logger = logger.WithField("duration", time.Since(start).Round(time.Millisecond).String())
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			logger = logger.WithField("stderr", strings.TrimSpace(string(exitErr.Stderr)))
+		} else if len(withCombinedOutput) > 0 && len(output) > 0 {
+			logger = logger.WithField("output", strings.TrimSpace(string(output)))
+		}
+		logger.WithError(err).Warn("Command failed")
+		return output, err
+	}
+	logger.Debug("Command finished")
+	return output, nil
+}
+
+// stepName returns a short description of a build step, such as "go build" or "npm install", skipping flags and paths
+func stepName(cmd *exec.Cmd) string {
+	var words []string
+	for i, arg := range cmd.Args {
+		if i == 0 {
+			arg = filepath.Base(arg)
+		} else if strings.HasPrefix(arg, "-") || strings.ContainsAny(arg, `/\`) {
+			continue
+		}
+		words = append(words, arg)
+		if len(words) == 3 {
+			break
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -1,10 +1,20 @@
 package packages
 
 import (
-	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
+	"bytes"
+	"context"
+	"errors"
 	"os/exec"
+	"regexp"
+	"runtime"
 	"testing"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cliLog "github.com/akamai/cli/pkg/log"
 )
 
 func TestExecCommand(t *testing.T) {
@@ -15,6 +25,74 @@ func TestExecCommand(t *testing.T) {
 	assert.Equal(t, "test\n", string(res))
 }
 
+func TestExecCommandContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands in tests are shell commands")
+	}
+	tests := map[string]struct {
+		script       string
+		combined     bool
+		timeout      time.Duration
+		expected     string
+		stderr       string
+		withError    bool
+		withDeadline bool
+	}{
+		"output": {
+			script:   "echo test; echo warning >&2",
+			timeout:  time.Minute,
+			expected: "test\n",
+		},
+		"combined output": {
+			script:   "echo test; echo warning >&2",
+			combined: true,
+			timeout:  time.Minute,
+			expected: "test\nwarning\n",
+		},
+		"command failure": {
+			script:    "echo test; echo failure >&2; exit 2",
+			timeout:   time.Minute,
+			expected:  "test\n",
+			stderr:    "failure\n",
+			withError: true,
+		},
+		"killed along with spawned processes once timed out": {
+			script:       "sleep 10 & sleep 10",
+			timeout:      100 * time.Millisecond,
+			withError:    true,
+			withDeadline: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+			defer cancel()
+			executor := defaultExecutor{}
+			var combined []bool
+			if test.combined {
+				combined = append(combined, true)
+			}
+			start := time.Now()
+			res, err := executor.ExecCommandContext(ctx, exec.Command("sh", "-c", test.script), combined...)
+			assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+			assert.Equal(t, test.expected, string(res))
+			if !test.withError {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			if test.withDeadline {
+				assert.True(t, errors.Is(err, context.DeadlineExceeded))
+				return
+			}
+			var exitErr *exec.ExitError
+			require.True(t, errors.As(err, &exitErr))
+			assert.Equal(t, test.stderr, string(exitErr.Stderr))
+		})
+	}
+}
+
 func TestLookPath(t *testing.T) {
 	executor := defaultExecutor{}
 	res, err := executor.LookPath("go")
@@ -51,3 +129,82 @@ func TestFileExists(t *testing.T) {
 		})
 	}
 }
+
+func TestStepName(t *testing.T) {
+	tests := map[string]struct {
+		cmd      *exec.Cmd
+		expected string
+	}{
+		"go build":    {cmd: exec.Command("/usr/local/go/bin/go", "build", "-o", "/tmp/bin/akamai-test", "."), expected: "go build ."},
+		"npm install": {cmd: exec.Command("npm", "install", "--production"), expected: "npm install"},
+		"pip install": {cmd: exec.Command("python3", "-m", "pip", "install", "--user", "-r", "/tmp/requirements.txt"), expected: "python3 pip install"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, stepName(test.cmd))
+		})
+	}
+}
+
+func TestLoggingExecutor(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := map[string]struct {
+		cmd       *exec.Cmd
+		ctx       context.Context
+		expected  []*regexp.Regexp
+		skipped   bool
+		withError bool
+	}{
+		"command succeeds": {
+			cmd: exec.Command("echo", "test"),
+			expected: []*regexp.Regexp{
+				regexp.MustCompile(`DEBUG.*Executing command.*cmd=echo test`),
+				regexp.MustCompile(`DEBUG.*Command finished.*cmd=echo test`),
+			},
+		},
+		"command fails, stderr is logged": {
+			cmd: exec.Command("sh", "-c", "echo broken >&2; exit 3"),
+			expected: []*regexp.Regexp{
+				regexp.MustCompile(`WARN.*Command failed.*error=exit status 3.*stderr=broken`),
+			},
+			withError: true,
+		},
+		"installation canceled": {
+			cmd: exec.Command("echo", "test"),
+			ctx: canceled,
+			expected: []*regexp.Regexp{
+				regexp.MustCompile(`DEBUG.*Command skipped, installation canceled.*cmd=echo test`),
+			},
+			skipped:   true,
+			withError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf, progress bytes.Buffer
+			executor := &loggingExecutor{
+				executor: &defaultExecutor{},
+				ctx:      test.ctx,
+				logger:   &log.Logger{Level: log.DebugLevel, Handler: cliLog.NewHandler(&buf, false)},
+				progress: &progress,
+			}
+			_, err := executor.ExecCommand(test.cmd)
+			if test.skipped {
+				assert.Empty(t, progress.String())
+			} else {
+				assert.Equal(t, "Running "+stepName(test.cmd), progress.String())
+			}
+			for _, expected := range test.expected {
+				assert.Regexp(t, expected, buf.String())
+			}
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -59,26 +59,26 @@ func (l *langManager) installGolang(ctx context.Context, dir, ver string, comman
 		}
 	}
 
-	cliPath, err := tools.GetAkamaiCliPath()
-	if goPath := os.Getenv("GOPATH"); goPath != "" {
-		cliPath = fmt.Sprintf("%s%d%s", goPath, os.PathListSeparator, cliPath)
-	}
+	goPath, err := tools.GetAkamaiCliPath()
 	if err != nil {
 		return cli.Exit(color.RedString("Unable to determine CLI home directory"), 1)
 	}
-	if err := os.Setenv("GOPATH", cliPath); err != nil {
-		return err
+	if userPath := os.Getenv("GOPATH"); userPath != "" {
+		goPath = userPath + string(os.PathListSeparator) + goPath
 	}
-	if err = installGolangModules(logger, l.commandExecutor, dir); err != nil {
+	// GOPATH is only set for the go commands, as packages may be installed concurrently
+	env := golangEnv(goPath)
+	if err = installGolangModules(logger, l.commandExecutor, dir, env); err != nil {
 		logger.Info("go.sum not found, running glide package manager[WARN: Usage of Glide is DEPRECTED]")
 
-		if err = installGolangDepsGlide(logger, l.commandExecutor, dir); err != nil {
+		if err = installGolangDepsGlide(logger, l.commandExecutor, dir, env); err != nil {
 			return err
 		}
 	}
 
 	for _, command := range commands {
-		execName := "akamai-" + strings.ToLower(command)
+		// without the suffix, binaries built on Windows could not be found in PATH
+		execName := "akamai-" + strings.ToLower(command) + tools.BinSuffix()
 
 		var cmd *exec.Cmd
 		if len(commands) > 1 {
@@ -88,6 +88,7 @@ func (l *langManager) installGolang(ctx context.Context, dir, ver string, comman
 		}
 
 		cmd.Dir = dir
+		cmd.Env = env
 		_, err = l.commandExecutor.ExecCommand(cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
@@ -101,7 +102,18 @@ func (l *langManager) installGolang(ctx context.Context, dir, ver string, comman
 	return nil
 }
 
-func installGolangDepsGlide(logger log.Logger, cmdExecutor executor, dir string) error {
+// golangEnv returns the environment of the current process with GOPATH replaced by given path
+func golangEnv(goPath string) []string {
+	env := []string{"GOPATH=" + goPath}
+	for _, v := range os.Environ() {
+		if !strings.HasPrefix(v, "GOPATH=") {
+			env = append(env, v)
+		}
+	}
+	return env
+}
+
+func installGolangDepsGlide(logger log.Logger, cmdExecutor executor, dir string, env []string) error {
 	if ok, _ := cmdExecutor.FileExists(filepath.Join(dir, "glide.lock")); !ok {
 		return nil
 	}
@@ -110,6 +122,7 @@ func installGolangDepsGlide(logger log.Logger, cmdExecutor executor, dir string)
 	if err == nil {
 		cmd := exec.Command(bin, "install")
 		cmd.Dir = dir
+		cmd.Env = env
 		_, err = cmdExecutor.ExecCommand(cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
@@ -126,7 +139,7 @@ func installGolangDepsGlide(logger log.Logger, cmdExecutor executor, dir string)
 	return nil
 }
 
-func installGolangModules(logger log.Logger, cmdExecutor executor, dir string) error {
+func installGolangModules(logger log.Logger, cmdExecutor executor, dir string, env []string) error {
 	bin, err := cmdExecutor.LookPath("go")
 	if err != nil {
 		err = fmt.Errorf("%w: %s. Please verify if the executable is included in your PATH", ErrRuntimeNotFound, "go")
@@ -142,6 +155,7 @@ func installGolangModules(logger log.Logger, cmdExecutor executor, dir string) e
 		moduleName := filepath.Base(dir)
 		cmd := exec.Command(bin, "mod", "init", moduleName)
 		cmd.Dir = dir
+		cmd.Env = env
 		_, err = cmdExecutor.ExecCommand(cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
@@ -154,6 +168,7 @@ func installGolangModules(logger log.Logger, cmdExecutor executor, dir string) e
 	logger.Info("go.sum found, running go module package manager")
 	cmd := exec.Command(bin, "mod", "tidy")
 	cmd.Dir = dir
+	cmd.Env = env
 	_, err = cmdExecutor.ExecCommand(cmd)
 	if err != nil {
 		var exitErr *exec.ExitError
@@ -4,13 +4,30 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/akamai/cli/pkg/tools"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"os"
 	"os/exec"
 	"testing"
 )
 
 func TestInstallGolang(t *testing.T) {
+	userPath, userPathSet := os.LookupEnv("GOPATH")
+	require.NoError(t, os.Setenv("GOPATH", "/home/go"))
+	defer func() {
+		if userPathSet {
+			require.NoError(t, os.Setenv("GOPATH", userPath))
+		} else {
+			require.NoError(t, os.Unsetenv("GOPATH"))
+		}
+	}()
+	cliPath, err := tools.GetAkamaiCliPath()
+	require.NoError(t, err)
+	goPath := "/home/go" + string(os.PathListSeparator) + cliPath
+	goEnv := golangEnv(goPath)
+	require.Contains(t, goEnv, "GOPATH="+goPath)
+
 	tests := map[string]struct {
 		givenDir      string
 		givenVer      string
@@ -30,11 +47,13 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test", "."},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -50,16 +69,19 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test1", "./test1"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test2", "./test2"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -76,16 +98,19 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/glide",
 					Args: []string{"/test/glide", "install"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test1", "./test1"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test2", "./test2"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -101,6 +126,7 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, &exec.ExitError{})
 				m.On("FileExists", "testDir/glide.lock").Return(true, nil)
 				m.On("LookPath", "glide").Return("/test/glide", nil)
@@ -108,16 +134,19 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/glide",
 					Args: []string{"/test/glide", "install"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test1", "./test1"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test2", "./test2"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -135,11 +164,13 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/glide",
 					Args: []string{"/test/glide", "install"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test", "."},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -156,6 +187,7 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test", "."},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -173,6 +205,7 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/glide",
 					Args: []string{"/test/glide", "install"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, &exec.ExitError{})
 			},
 			withError: ErrPackageManagerExec,
@@ -206,11 +239,13 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test", "."},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -261,11 +296,13 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test", "."},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, &exec.ExitError{})
 			},
 			withError: ErrPackageCompileFailure,
@@ -279,6 +316,7 @@ func TestInstallGolang(t *testing.T) {
 			l := langManager{m}
 			err := l.installGolang(context.Background(), test.givenDir, test.givenVer, test.givenCommands)
 			m.AssertExpectations(t)
+			assert.Equal(t, "/home/go", os.Getenv("GOPATH"), "GOPATH of the process should not be modified")
 			if test.withError != nil {
 				assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
 				return
@@ -0,0 +1,126 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// MetadataFile is the name of the file declaring commands and requirements of a package
+const MetadataFile = "cli.json"
+
+// ErrNoMetadata is returned by ReadMetadata if the package directory does not contain a cli.json file
+var ErrNoMetadata = errors.New("package does not contain a cli.json file")
+
+// ErrInvalidCommandName is returned by ReadMetadata if a command name cannot be used as a command, nor in a file name
+var ErrInvalidCommandName = errors.New("invalid command name")
+
+// commandNameRegexp matches valid (lower-cased) command names
+var commandNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+type (
+	// Metadata is the content of the cli.json file of a package
+	Metadata struct {
+		Commands     []Command            `json:"commands"`
+		Requirements LanguageRequirements `json:"requirements"`
+		Action       cli.ActionFunc       `json:"-"`
+		// Pkg is the package name, derived from the package directory
+		Pkg          string            `json:"pkg"`
+		Env          map[string]string `json:"env"`
+		Cwd          string            `json:"cwd"`
+		Dependencies []Dependency      `json:"dependencies,omitempty"`
+		Hooks        map[string]string `json:"hooks,omitempty"`
+		Image        string            `json:"image,omitempty"`
+	}
+
+	// Command is a command provided by a package
+	Command struct {
+		Name         string            `json:"name"`
+		Aliases      []string          `json:"aliases"`
+		Version      string            `json:"version"`
+		Description  string            `json:"description"`
+		Usage        string            `json:"usage"`
+		Arguments    string            `json:"arguments"`
+		Bin          string            `json:"bin"`
+		Checksums    map[string]string `json:"checksums,omitempty"`
+		PublicKey    string            `json:"public-key,omitempty"`
+		AutoComplete bool              `json:"auto-complete"`
+		// CompletionProtocol is how the command completes its own arguments, when AutoComplete is set:
+		// "flag" passes them followed by --generate-bash-completion, "command" passes them after __complete
+		CompletionProtocol string `json:"completion-protocol,omitempty"`
+		// Deprecated is a message displayed when the command is run, such as what to use instead
+		Deprecated string `json:"deprecated,omitempty"`
+		// RenamedTo is the new name of the command, invocations of the command are forwarded to it
+		RenamedTo string `json:"renamed-to,omitempty"`
+		// Docs is the documentation of the command in Markdown, displayed by "akamai help <command>"
+		Docs string `json:"docs,omitempty"`
+
+		Flags       []cli.Flag     `json:"-"`
+		BinSuffix   string         `json:"-"`
+		OS          string         `json:"-"`
+		Arch        string         `json:"-"`
+		Libc        string         `json:"-"`
+		Subcommands []*cli.Command `json:"-"`
+	}
+
+	// Dependency is another package required by a package
+	Dependency struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+)
+
+// ReadMetadata reads the cli.json file of the package in given directory, or in its parent directory
+// if dir is a subdirectory of the package. Command names are lower-cased, and must only contain letters, digits and dashes.
+func ReadMetadata(dir string) (Metadata, error) {
+	if _, err := os.Stat(filepath.Join(dir, MetadataFile)); err != nil {
+		dir = filepath.Dir(dir)
+		if _, err = os.Stat(filepath.Join(dir, MetadataFile)); err != nil {
+			return Metadata{}, ErrNoMetadata
+		}
+	}
+
+	var metadata Metadata
+	data, err := ioutil.ReadFile(filepath.Join(dir, MetadataFile))
+	if err != nil {
+		return Metadata{}, err
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return Metadata{}, err
+	}
+
+	for key := range metadata.Commands {
+		metadata.Commands[key].Name = strings.ToLower(metadata.Commands[key].Name)
+		metadata.Commands[key].RenamedTo = strings.ToLower(metadata.Commands[key].RenamedTo)
+		if !commandNameRegexp.MatchString(metadata.Commands[key].Name) {
+			return Metadata{}, fmt.Errorf("%w: %q, only letters, digits and dashes are allowed", ErrInvalidCommandName, metadata.Commands[key].Name)
+		}
+		if renamed := metadata.Commands[key].RenamedTo; renamed != "" && !commandNameRegexp.MatchString(renamed) {
+			return Metadata{}, fmt.Errorf("%w: %q, only letters, digits and dashes are allowed", ErrInvalidCommandName, renamed)
+		}
+	}
+	metadata.Pkg = filepath.Base(strings.Replace(dir, "cli-", "", 1))
+
+	return metadata, nil
+}
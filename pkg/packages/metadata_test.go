@@ -0,0 +1,63 @@
+package packages
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMetadata(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metadata")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	pkgDir := filepath.Join(dir, "cli-echo")
+	require.NoError(t, os.MkdirAll(filepath.Join(pkgDir, "bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, MetadataFile), []byte(`{
+		"requirements": {"go": "1.14.0"},
+		"commands": [{"name": "Echo", "aliases": ["e"], "version": "1.0.0"}],
+		"dependencies": [{"name": "cli-other", "version": "^1.0.0"}]
+	}`), 0644))
+
+	for name, path := range map[string]string{"package directory": pkgDir, "subdirectory": filepath.Join(pkgDir, "bin")} {
+		t.Run(name, func(t *testing.T) {
+			metadata, err := ReadMetadata(path)
+			require.NoError(t, err)
+			assert.Equal(t, Metadata{
+				Commands:     []Command{{Name: "echo", Aliases: []string{"e"}, Version: "1.0.0"}},
+				Requirements: LanguageRequirements{Go: "1.14.0"},
+				Pkg:          "echo",
+				Dependencies: []Dependency{{Name: "cli-other", Version: "^1.0.0"}},
+			}, metadata)
+		})
+	}
+
+	_, err = ReadMetadata(dir)
+	assert.Equal(t, ErrNoMetadata, err)
+}
+
+func TestReadMetadataInvalidCommandName(t *testing.T) {
+	tests := map[string]string{
+		"path separator":     `{"commands": [{"name": "../echo"}]}`,
+		"shell syntax":       `{"commands": [{"name": "echo\"; rm -rf ~; \""}]}`,
+		"leading dash":       `{"commands": [{"name": "-echo"}]}`,
+		"empty name":         `{"commands": [{"name": ""}]}`,
+		"invalid rename":     `{"commands": [{"name": "echo", "renamed-to": "echo new"}]}`,
+		"whitespace in name": `{"commands": [{"name": "echo cmd"}]}`,
+	}
+
+	for name, metadata := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, MetadataFile), []byte(metadata), 0644))
+			_, err := ReadMetadata(dir)
+			assert.True(t, errors.Is(err, ErrInvalidCommandName), "unexpected error: %v", err)
+		})
+	}
+}
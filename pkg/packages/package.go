@@ -3,6 +3,9 @@ package packages
 import (
 	"context"
 	"errors"
+	"io"
+	"path/filepath"
+
 	"github.com/akamai/cli/pkg/log"
 )
 
@@ -45,6 +48,10 @@ var (
 	ErrPackageCompileFailure         = errors.New("unable to build binary")
 )
 
+type contextType string
+
+var progressContext contextType = "progress"
+
 type langManager struct {
 	commandExecutor executor
 }
@@ -56,20 +63,34 @@ func NewLangManager() LangManager {
 	}
 }
 
+// WithProgress returns a context in which Install reports each build step to given writer, such as a terminal spinner
+func WithProgress(ctx context.Context, progress io.Writer) context.Context {
+	return context.WithValue(ctx, progressContext, progress)
+}
+
 // Install builds and installs contents of a directory based on provided language requirements
 func (l *langManager) Install(ctx context.Context, dir string, reqs LanguageRequirements, commands []string) error {
 	lang, requirements := determineLangAndRequirements(reqs)
+	log.FromContext(ctx).Debugf("Installing %s package in %s", lang, dir)
+	progress, _ := ctx.Value(progressContext).(io.Writer)
+	var env []string
+	if cacheDir, ok := ctx.Value(buildCacheContext).(string); ok && cacheDir != "" {
+		env = buildCacheEnv(cacheDir)
+	}
+	installer := &langManager{
+		commandExecutor: &loggingExecutor{executor: l.commandExecutor, ctx: ctx, logger: log.FromContext(ctx), progress: progress, env: env},
+	}
 	switch lang {
 	case PHP:
-		return l.installPHP(ctx, dir, requirements)
+		return installer.installPHP(ctx, dir, requirements)
 	case Javascript:
-		return l.installJavaScript(ctx, dir, requirements)
+		return installer.installJavaScript(ctx, dir, requirements)
 	case Ruby:
-		return l.installRuby(ctx, dir, requirements)
+		return installer.installRuby(ctx, dir, requirements)
 	case Python:
-		return l.installPython(ctx, dir, requirements)
+		return installer.installPython(ctx, dir, requirements)
 	case Go:
-		return l.installGolang(ctx, dir, requirements, commands)
+		return installer.installGolang(ctx, dir, requirements, commands)
 	}
 	return ErrUnknownLang
 }
@@ -89,6 +110,12 @@ func (l *langManager) FindExec(ctx context.Context, reqs LanguageRequirements, c
 		}
 		return []string{bin, cmdExec}, nil
 	case Python:
+		// executables are in the package directory or its bin directory
+		for _, dir := range []string{filepath.Dir(cmdExec), filepath.Dir(filepath.Dir(cmdExec))} {
+			if bin, ok := VenvPython(dir); ok {
+				return []string{bin, cmdExec}, nil
+			}
+		}
 		bin, err := findPythonBin(ctx, l.commandExecutor, requirements)
 		if err != nil {
 			return nil, err
@@ -102,6 +129,11 @@ func (l *langManager) FindExec(ctx context.Context, reqs LanguageRequirements, c
 	}
 }
 
+// Language returns the programming language of the package and its required version, or Undefined if no requirement is set
+func (r LanguageRequirements) Language() (string, string) {
+	return determineLangAndRequirements(r)
+}
+
 func determineLangAndRequirements(reqs LanguageRequirements) (string, string) {
 	if reqs.Php != "" {
 		return PHP, reqs.Php
@@ -23,12 +23,18 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/akamai/cli/pkg/log"
 	"github.com/akamai/cli/pkg/version"
 )
 
+// VenvDirName is the directory of the virtual environment Python packages are installed in, inside the package directory
+const VenvDirName = ".venv"
+
+// installPython creates a virtual environment in the package directory, using the interpreter matching the requirement,
+// and installs package dependencies into it. The package commands are executed with the interpreter of the virtual environment.
 func (l *langManager) installPython(ctx context.Context, dir, cmdReq string) error {
 	logger := log.FromContext(ctx)
 
@@ -36,10 +42,6 @@ func (l *langManager) installPython(ctx context.Context, dir, cmdReq string) err
 	if err != nil {
 		return err
 	}
-	pipBin, err := findPipBin(ctx, l.commandExecutor, cmdReq)
-	if err != nil {
-		return err
-	}
 
 	if cmdReq != "" && cmdReq != "*" {
 		cmd := exec.Command(pythonBin, "--version")
@@ -58,13 +60,67 @@ func (l *langManager) installPython(ctx context.Context, dir, cmdReq string) err
 		}
 	}
 
-	if err := installPythonDepsPip(ctx, l.commandExecutor, pipBin, dir); err != nil {
+	if err := createPythonVenv(ctx, l.commandExecutor, pythonBin, dir); err != nil {
 		return err
 	}
+	if err := installPythonDepsPip(ctx, l.commandExecutor, venvPythonPath(dir), dir); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createPythonVenv creates the virtual environment of the package with given interpreter, replacing the existing one, if any.
+// Python 2 does not provide the venv module, in which case virtualenv is used.
+func createPythonVenv(ctx context.Context, cmdExecutor executor, pythonBin, dir string) error {
+	logger := log.FromContext(ctx)
+	venv := filepath.Join(dir, VenvDirName)
+
+	cmd := exec.Command(pythonBin, "-m", "venv", "--clear", venv)
+	cmd.Dir = dir
+	output, err := cmdExecutor.ExecCommand(cmd, true)
+	if err == nil {
+		return nil
+	}
+	logger.Debugf("Unable to create virtual environment with venv module: %s", bytes.TrimSpace(output))
 
+	virtualenv, lookErr := cmdExecutor.LookPath("virtualenv")
+	if lookErr != nil {
+		return fmt.Errorf("%w: %s. Please install the venv module of your Python distribution (e.g. python3-venv) or virtualenv", ErrPackageManagerExec, "venv")
+	}
+	cmd = exec.Command(virtualenv, "--clear", "-p", pythonBin, venv)
+	cmd.Dir = dir
+	if output, err := cmdExecutor.ExecCommand(cmd, true); err != nil {
+		logger.Debugf("Unable to create virtual environment with virtualenv: %s", bytes.TrimSpace(output))
+		return fmt.Errorf("%w: %s", ErrPackageManagerExec, "virtualenv")
+	}
 	return nil
 }
 
+// VenvPython returns the interpreter of the virtual environment of the package in given directory, if the package has one
+func VenvPython(dir string) (string, bool) {
+	bin := venvPythonPath(dir)
+	if _, err := os.Stat(bin); err != nil {
+		return "", false
+	}
+	return bin, true
+}
+
+// VenvBinDir returns the directory of executables of the virtual environment of the package in given directory
+func VenvBinDir(dir string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(dir, VenvDirName, "Scripts")
+	}
+	return filepath.Join(dir, VenvDirName, "bin")
+}
+
+func venvPythonPath(dir string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(VenvBinDir(dir), "python.exe")
+	}
+	return filepath.Join(VenvBinDir(dir), "python")
+}
+
 func findPythonBin(ctx context.Context, cmdExecutor executor, ver string) (string, error) {
 	logger := log.FromContext(ctx)
 
@@ -97,38 +153,8 @@ func findPythonBin(ctx context.Context, cmdExecutor executor, ver string) (strin
 	return bin, nil
 }
 
-func findPipBin(ctx context.Context, cmdExecutor executor, ver string) (string, error) {
-	logger := log.FromContext(ctx)
-
-	var bin string
-	var err error
-	defer func() {
-		if err == nil {
-			logger.Debugf("Pip binary found: %s", bin)
-		}
-	}()
-	if ver == "" || ver == "*" {
-		bin, err = lookForBins(cmdExecutor, "pip3", "pip2", "pip")
-		if err != nil {
-			return "", fmt.Errorf("%w: %s", ErrPackageManagerNotFound, "pip")
-		}
-		return bin, nil
-	}
-	if version.Compare("3.0.0", ver) != -1 {
-		bin, err = lookForBins(cmdExecutor, "pip3", "pip")
-		if err != nil {
-			return "", fmt.Errorf("%w: %s", ErrPackageManagerNotFound, "pip3")
-		}
-		return bin, nil
-	}
-	bin, err = lookForBins(cmdExecutor, "pip2", "pip", "pip3")
-	if err != nil {
-		return "", fmt.Errorf("%w: %s", ErrPackageManagerNotFound, "pip")
-	}
-	return bin, nil
-}
-
-func installPythonDepsPip(ctx context.Context, cmdExecutor executor, bin, dir string) error {
+// installPythonDepsPip installs requirements of the package into its virtual environment, using the pip module of its interpreter
+func installPythonDepsPip(ctx context.Context, cmdExecutor executor, pythonBin, dir string) error {
 	logger := log.FromContext(ctx)
 
 	if ok, _ := cmdExecutor.FileExists(filepath.Join(dir, "requirements.txt")); !ok {
@@ -136,16 +162,13 @@ func installPythonDepsPip(ctx context.Context, cmdExecutor executor, bin, dir st
 	}
 	logger.Info("requirements.txt found, running pip package manager")
 
-	if err := os.Setenv("PYTHONUSERBASE", dir); err != nil {
-		return err
-	}
-	args := []string{bin, "install", "--user", "--ignore-installed", "-r", "requirements.txt"}
+	args := []string{pythonBin, "-m", "pip", "install", "-r", "requirements.txt"}
 	cmd := exec.Command(args[0], args[1:]...)
 	cmd.Dir = dir
 	if _, err := cmdExecutor.ExecCommand(cmd); err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
-			logger.Debugf("Unable execute package manager (PYTHONUSERBASE=%s %s): \n %s", dir, strings.Join(args, " "), exitErr.Stderr)
+			logger.Debugf("Unable execute package manager (%s): \n %s", strings.Join(args, " "), exitErr.Stderr)
 		}
 		return fmt.Errorf("%w: %s. Please verify pip system dependencies (setuptools, python3-dev, gcc, libffi-dev, openssl-dev)", ErrPackageManagerExec, "pip")
 	}
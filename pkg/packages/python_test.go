@@ -11,6 +11,18 @@ import (
 )
 
 func TestInstallPython(t *testing.T) {
+	venv := func(python string) *exec.Cmd {
+		return &exec.Cmd{
+			Path: python,
+			Args: []string{python, "-m", "venv", "--clear", "testDir/.venv"},
+			Dir:  "testDir",
+		}
+	}
+	pipInstall := &exec.Cmd{
+		Path: "testDir/.venv/bin/python",
+		Args: []string{"testDir/.venv/bin/python", "-m", "pip", "install", "-r", "requirements.txt"},
+		Dir:  "testDir",
+	}
 	tests := map[string]struct {
 		givenDir  string
 		givenVer  string
@@ -22,35 +34,33 @@ func TestInstallPython(t *testing.T) {
 			givenVer: "3.0.0",
 			init: func(m *mocked) {
 				m.On("LookPath", "python3").Return("/test/python3", nil).Once()
-				m.On("LookPath", "pip3").Return("/test/pip3", nil).Once()
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/python3",
 					Args: []string{"/test/python3", "--version"},
 				}, true).Return([]byte("Python 3.1.0"), nil).Once()
+				m.On("ExecCommand", venv("/test/python3"), true).Return(nil, nil).Once()
 				m.On("FileExists", "testDir/requirements.txt").Return(true, nil).Once()
-				m.On("ExecCommand", &exec.Cmd{
-					Path: "/test/pip3",
-					Args: []string{"/test/pip3", "install", "--user", "--ignore-installed", "-r", "requirements.txt"},
-					Dir:  "testDir",
-				}).Return(nil, nil).Once()
+				m.On("ExecCommand", pipInstall).Return(nil, nil).Once()
 			},
 		},
-		"with version 2 and pip": {
+		"with version 2 and virtualenv": {
 			givenDir: "testDir",
 			givenVer: "2.0.0",
 			init: func(m *mocked) {
 				m.On("LookPath", "python2").Return("/test/python2", nil).Once()
-				m.On("LookPath", "pip2").Return("/test/pip2", nil).Once()
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/python2",
 					Args: []string{"/test/python2", "--version"},
 				}, true).Return([]byte("Python 2.1.0"), nil).Once()
-				m.On("FileExists", "testDir/requirements.txt").Return(true, nil).Once()
+				m.On("ExecCommand", venv("/test/python2"), true).Return([]byte("No module named venv"), &exec.ExitError{}).Once()
+				m.On("LookPath", "virtualenv").Return("/test/virtualenv", nil).Once()
 				m.On("ExecCommand", &exec.Cmd{
-					Path: "/test/pip2",
-					Args: []string{"/test/pip2", "install", "--user", "--ignore-installed", "-r", "requirements.txt"},
+					Path: "/test/virtualenv",
+					Args: []string{"/test/virtualenv", "--clear", "-p", "/test/python2", "testDir/.venv"},
 					Dir:  "testDir",
-				}).Return(nil, nil).Once()
+				}, true).Return(nil, nil).Once()
+				m.On("FileExists", "testDir/requirements.txt").Return(true, nil).Once()
+				m.On("ExecCommand", pipInstall).Return(nil, nil).Once()
 			},
 		},
 		"with default version and pip": {
@@ -58,21 +68,17 @@ func TestInstallPython(t *testing.T) {
 			givenVer: "*",
 			init: func(m *mocked) {
 				m.On("LookPath", "python3").Return("/test/python3", nil).Once()
-				m.On("LookPath", "pip3").Return("/test/pip3", nil).Once()
+				m.On("ExecCommand", venv("/test/python3"), true).Return(nil, nil).Once()
 				m.On("FileExists", "testDir/requirements.txt").Return(true, nil).Once()
-				m.On("ExecCommand", &exec.Cmd{
-					Path: "/test/pip3",
-					Args: []string{"/test/pip3", "install", "--user", "--ignore-installed", "-r", "requirements.txt"},
-					Dir:  "testDir",
-				}).Return(nil, nil).Once()
+				m.On("ExecCommand", pipInstall).Return(nil, nil).Once()
 			},
 		},
-		"with default version and no pip": {
+		"with default version and no requirements": {
 			givenDir: "testDir",
 			givenVer: "*",
 			init: func(m *mocked) {
 				m.On("LookPath", "python3").Return("/test/python3", nil).Once()
-				m.On("LookPath", "pip3").Return("/test/pip3", nil).Once()
+				m.On("ExecCommand", venv("/test/python3"), true).Return(nil, nil).Once()
 				m.On("FileExists", "testDir/requirements.txt").Return(false, nil).Once()
 			},
 		},
@@ -81,13 +87,19 @@ func TestInstallPython(t *testing.T) {
 			givenVer: "*",
 			init: func(m *mocked) {
 				m.On("LookPath", "python3").Return("/test/python3", nil).Once()
-				m.On("LookPath", "pip3").Return("/test/pip3", nil).Once()
+				m.On("ExecCommand", venv("/test/python3"), true).Return(nil, nil).Once()
 				m.On("FileExists", "testDir/requirements.txt").Return(true, nil).Once()
-				m.On("ExecCommand", &exec.Cmd{
-					Path: "/test/pip3",
-					Args: []string{"/test/pip3", "install", "--user", "--ignore-installed", "-r", "requirements.txt"},
-					Dir:  "testDir",
-				}).Return(nil, &exec.ExitError{}).Once()
+				m.On("ExecCommand", pipInstall).Return(nil, &exec.ExitError{}).Once()
+			},
+			withError: ErrPackageManagerExec,
+		},
+		"venv module and virtualenv not available": {
+			givenDir: "testDir",
+			givenVer: "*",
+			init: func(m *mocked) {
+				m.On("LookPath", "python3").Return("/test/python3", nil).Once()
+				m.On("ExecCommand", venv("/test/python3"), true).Return([]byte("ensurepip is not available"), &exec.ExitError{}).Once()
+				m.On("LookPath", "virtualenv").Return("", fmt.Errorf("not found")).Once()
 			},
 			withError: ErrPackageManagerExec,
 		},
@@ -96,7 +108,6 @@ func TestInstallPython(t *testing.T) {
 			givenVer: "3.0.0",
 			init: func(m *mocked) {
 				m.On("LookPath", "python3").Return("/test/python3", nil).Once()
-				m.On("LookPath", "pip3").Return("/test/pip3", nil).Once()
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/python3",
 					Args: []string{"/test/python3", "--version"},
@@ -109,7 +120,6 @@ func TestInstallPython(t *testing.T) {
 			givenVer: "3.0.5",
 			init: func(m *mocked) {
 				m.On("LookPath", "python3").Return("/test/python3", nil).Once()
-				m.On("LookPath", "pip3").Return("/test/pip3", nil).Once()
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/python3",
 					Args: []string{"/test/python3", "--version"},
@@ -127,17 +137,6 @@ func TestInstallPython(t *testing.T) {
 			},
 			withError: ErrRuntimeNotFound,
 		},
-		"pip bin not found": {
-			givenDir: "testDir",
-			givenVer: "*",
-			init: func(m *mocked) {
-				m.On("LookPath", "python3").Return("/test/python3", nil).Once()
-				m.On("LookPath", "pip3").Return("", fmt.Errorf("not found")).Once()
-				m.On("LookPath", "pip2").Return("", fmt.Errorf("not found")).Once()
-				m.On("LookPath", "pip").Return("", fmt.Errorf("not found")).Once()
-			},
-			withError: ErrPackageManagerNotFound,
-		},
 	}
 
 	for name, test := range tests {
@@ -0,0 +1,240 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registry reads package registries configured in the Akamai CLI config file and fetches their package lists
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	// OfficialName is the name of the official Akamai registry
+	OfficialName = "akamai"
+	// OfficialURL is the default location of the official Akamai registry
+	OfficialURL = "https://developer.akamai.com"
+	// DefaultTimeout is how long fetching the package list of a registry can take, retries included, unless its timeout is set
+	DefaultTimeout = 2 * time.Minute
+)
+
+type (
+	// Registry is a repository publishing a package list.
+	// Registries are configured in the registries config section, with keys in <name>.<setting> format.
+	Registry struct {
+		Name     string
+		URL      string
+		Priority int
+		// Header is sent with each request to the registry, in "Name: value" format, e.g. an Authorization header
+		Header string
+		// Timeout is how long fetching the package list can take, DefaultTimeout if 0
+		Timeout time.Duration
+	}
+
+	// PackageList is the list of packages published by a registry
+	PackageList struct {
+		Version  float64   `json:"version"`
+		Packages []Package `json:"packages"`
+	}
+
+	// Package is a package published by a registry
+	Package struct {
+		// Registry is the name of the registry the package is published in
+		Registry     string             `json:"-"`
+		Title        string             `json:"title"`
+		Name         string             `json:"name"`
+		Version      string             `json:"version"`
+		URL          string             `json:"url"`
+		Issues       string             `json:"issues"`
+		Author       string             `json:"author"`
+		Tags         []string           `json:"tags"`
+		Commands     []packages.Command `json:"commands"`
+		Requirements struct {
+			Go     string `json:"go"`
+			Php    string `json:"php"`
+			Node   string `json:"node"`
+			Ruby   string `json:"ruby"`
+			Python string `json:"python"`
+		} `json:"requirements"`
+	}
+)
+
+// ListURL returns location of the package list: the registry URL if it points to a JSON file,
+// or cli/package-list.json in the repository at the registry URL otherwise
+func (r Registry) ListURL() string {
+	if strings.HasSuffix(r.URL, ".json") {
+		return r.URL
+	}
+	return fmt.Sprintf("%s/cli/package-list.json", strings.TrimSuffix(r.URL, "/"))
+}
+
+// Get requests the package list of the registry, with given timeout or the default request timeout if 0
+func (r Registry) Get(ctx context.Context, timeout time.Duration) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.ListURL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.Header != "" {
+		name, value := splitHeader(r.Header)
+		req.Header.Set(name, value)
+	}
+	return tools.HTTPClient(timeout).Do(req)
+}
+
+// Fetch downloads and parses the package list of the registry, failing once the timeout of the registry expires
+func (r Registry) Fetch(ctx context.Context) (*PackageList, error) {
+	logger := log.FromContext(ctx)
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	list, err := r.fetch(fetchCtx, logger)
+	if err != nil && ctx.Err() == nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("unable to fetch remote Package List of registry %s (timed out after %s)", r.Name, timeout)
+	}
+	return list, err
+}
+
+func (r Registry) fetch(ctx context.Context, logger log.Logger) (*PackageList, error) {
+	resp, err := r.Get(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch remote Package List (%s)", err.Error())
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Error(err.Error())
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch remote Package List (%s)", resp.Status)
+	}
+
+	result := &PackageList{}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch remote Package List (%s)", err.Error())
+	}
+
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch remote Package List (%s)", err.Error())
+	}
+
+	return result, nil
+}
+
+// FromConfig returns enabled registries, highest priority first, and registries with the same priority by name.
+// The official registry, named akamai, has priority 0 and is enabled unless registries.akamai.disabled is true.
+// Its URL can be changed with registries.akamai.url, for example to use a mirror, or with AKAMAI_CLI_PACKAGE_REPO.
+func FromConfig(cfg config.Config) ([]Registry, error) {
+	settings := make(map[string]map[string]string)
+	for key, value := range cfg.Values()[config.RegistriesSection] {
+		path := strings.SplitN(key, ".", 2)
+		if len(path) != 2 {
+			return nil, fmt.Errorf("invalid registry setting %q, expected registries.<name>.<setting>", key)
+		}
+		if settings[path[0]] == nil {
+			settings[path[0]] = make(map[string]string)
+		}
+		settings[path[0]][path[1]] = value
+	}
+	if settings[OfficialName] == nil {
+		settings[OfficialName] = make(map[string]string)
+	}
+
+	registries := make([]Registry, 0, len(settings))
+	for name, values := range settings {
+		if disabled, _ := strconv.ParseBool(values["disabled"]); disabled {
+			continue
+		}
+		registry := Registry{Name: name, URL: values["url"], Header: values["header"]}
+		if name == OfficialName {
+			if customRepo := os.Getenv("AKAMAI_CLI_PACKAGE_REPO"); customRepo != "" {
+				registry.URL = customRepo
+			} else if registry.URL == "" {
+				registry.URL = OfficialURL
+			}
+		}
+		if registry.URL == "" {
+			return nil, fmt.Errorf("registry %q has no url", name)
+		}
+		if priority, ok := values["priority"]; ok {
+			p, err := strconv.Atoi(priority)
+			if err != nil {
+				return nil, fmt.Errorf("invalid priority of registry %q: %q, expected an integer", name, priority)
+			}
+			registry.Priority = p
+		}
+		if value, ok := values["timeout"]; ok {
+			timeout, err := ParseTimeout(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout of registry %q: %s", name, err)
+			}
+			registry.Timeout = timeout
+		}
+		if registry.Header != "" {
+			if headerName, _ := splitHeader(registry.Header); headerName == "" {
+				return nil, fmt.Errorf("invalid header of registry %q, expected \"Name: value\" format", name)
+			}
+		}
+		registries = append(registries, registry)
+	}
+
+	sort.Slice(registries, func(i, j int) bool {
+		if registries[i].Priority != registries[j].Priority {
+			return registries[i].Priority > registries[j].Priority
+		}
+		return registries[i].Name < registries[j].Name
+	})
+	return registries, nil
+}
+
+// HasCustomRegistries returns true if registries are configured, in which case package names are resolved using registries
+func HasCustomRegistries(cfg config.Config) bool {
+	return len(cfg.Values()[config.RegistriesSection]) > 0
+}
+
+// ParseTimeout parses the timeout setting of a registry, e.g. "30s" or "5m"
+func ParseTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q, expected a duration such as 30s or 5m", value)
+	}
+	return timeout, nil
+}
+
+func splitHeader(header string) (string, string) {
+	parts := strings.SplitN(header, ":", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
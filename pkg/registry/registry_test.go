@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akamai/cli/pkg/config"
+)
+
+func TestFromConfig(t *testing.T) {
+	tests := map[string]struct {
+		givenValues map[string]string
+		packageRepo string
+		expected    []Registry
+		withError   string
+	}{
+		"official registry by default": {
+			expected: []Registry{{Name: "akamai", URL: "https://developer.akamai.com"}},
+		},
+		"registries ordered by priority and name": {
+			givenValues: map[string]string{
+				"mirror.url":        "https://mirror.example.com",
+				"internal.url":      "https://internal.example.com/packages.json",
+				"internal.priority": "10",
+				"internal.header":   "Authorization: Bearer token",
+				"internal.timeout":  "30s",
+				"other.url":         "https://other.example.com",
+				"other.priority":    "-1",
+			},
+			expected: []Registry{
+				{Name: "internal", URL: "https://internal.example.com/packages.json", Priority: 10, Header: "Authorization: Bearer token", Timeout: 30 * time.Second},
+				{Name: "akamai", URL: "https://developer.akamai.com"},
+				{Name: "mirror", URL: "https://mirror.example.com"},
+				{Name: "other", URL: "https://other.example.com", Priority: -1},
+			},
+		},
+		"official registry replaced by a mirror": {
+			givenValues: map[string]string{"akamai.url": "https://mirror.example.com"},
+			expected:    []Registry{{Name: "akamai", URL: "https://mirror.example.com"}},
+		},
+		"official registry disabled": {
+			givenValues: map[string]string{
+				"akamai.disabled": "true",
+				"internal.url":    "https://internal.example.com",
+			},
+			expected: []Registry{{Name: "internal", URL: "https://internal.example.com"}},
+		},
+		"AKAMAI_CLI_PACKAGE_REPO overrides official registry url": {
+			givenValues: map[string]string{"akamai.url": "https://mirror.example.com"},
+			packageRepo: "https://repo.example.com",
+			expected:    []Registry{{Name: "akamai", URL: "https://repo.example.com"}},
+		},
+		"invalid setting": {
+			givenValues: map[string]string{"internal": "https://internal.example.com"},
+			withError:   `invalid registry setting "internal", expected registries.<name>.<setting>`,
+		},
+		"missing url": {
+			givenValues: map[string]string{"internal.priority": "1"},
+			withError:   `registry "internal" has no url`,
+		},
+		"invalid priority": {
+			givenValues: map[string]string{"internal.url": "https://internal.example.com", "internal.priority": "high"},
+			withError:   `invalid priority of registry "internal": "high", expected an integer`,
+		},
+		"invalid timeout": {
+			givenValues: map[string]string{"internal.url": "https://internal.example.com", "internal.timeout": "never"},
+			withError:   `invalid timeout of registry "internal": invalid timeout "never", expected a duration such as 30s or 5m`,
+		},
+		"invalid header": {
+			givenValues: map[string]string{"internal.url": "https://internal.example.com", "internal.header": "token"},
+			withError:   `invalid header of registry "internal", expected "Name: value" format`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", test.packageRepo))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_PACKAGE_REPO"))
+			}()
+			cfg := &config.Mock{}
+			cfg.On("Values").Return(map[string]map[string]string{config.RegistriesSection: test.givenValues}).Once()
+
+			registries, err := FromConfig(cfg)
+			cfg.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, registries)
+		})
+	}
+}
+
+func TestListURL(t *testing.T) {
+	assert.Equal(t, "https://example.com/cli/package-list.json", Registry{URL: "https://example.com/"}.ListURL())
+	assert.Equal(t, "https://example.com/packages.json", Registry{URL: "https://example.com/packages.json"}.ListURL())
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cli/package-list.json":
+			_, err := w.Write([]byte(`{"version": 1.0, "packages": [{"name": "cli-echo", "commands": [{"name": "echo", "aliases": ["e"]}]}]}`))
+			assert.NoError(t, err)
+		case "/slow.json":
+			time.Sleep(200 * time.Millisecond)
+		case "/invalid.json":
+			_, err := w.Write([]byte(`invalid`))
+			assert.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	list, err := Registry{Name: "test", URL: srv.URL}.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list.Packages, 1)
+	assert.Equal(t, "cli-echo", list.Packages[0].Name)
+	require.Len(t, list.Packages[0].Commands, 1)
+	assert.Equal(t, []string{"e"}, list.Packages[0].Commands[0].Aliases)
+
+	_, err = Registry{Name: "test", URL: srv.URL + "/missing.json"}.Fetch(context.Background())
+	assert.EqualError(t, err, "unable to fetch remote Package List (404 Not Found)")
+	_, err = Registry{Name: "test", URL: srv.URL + "/invalid.json"}.Fetch(context.Background())
+	assert.Error(t, err)
+	_, err = Registry{Name: "test", URL: srv.URL + "/slow.json", Timeout: 50 * time.Millisecond}.Fetch(context.Background())
+	assert.EqualError(t, err, "unable to fetch remote Package List of registry test (timed out after 50ms)")
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/akamai/cli/pkg/config"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
 )
 
 // Akamai CLI (optionally) tracks upgrades, package installs, and updates anonymously
@@ -39,6 +40,7 @@ import (
 const (
 	statsVersion     string = "1.1"
 	sleepTime24Hours        = time.Hour * 24
+	trackingID              = "UA-34796267-23"
 )
 
 // FirstRunCheckStats ...
@@ -176,7 +178,7 @@ func TrackEvent(ctx context.Context, category, action, value string) {
 	}
 
 	form := url.Values{}
-	form.Add("tid", "UA-34796267-23")
+	form.Add("tid", trackingID)
 	form.Add("v", "1")        // Version 1
 	form.Add("aip", "1")      // Anonymize IP
 	form.Add("cid", clientID) // Client ID
@@ -185,15 +187,12 @@ func TrackEvent(ctx context.Context, category, action, value string) {
 	form.Add("ea", action)    // Action
 	form.Add("el", value)     // Label
 
-	hc := http.Client{}
+	hc := tools.HTTPClient(0)
 	debug := os.Getenv("AKAMAI_CLI_DEBUG_ANALYTICS")
 	var req *http.Request
 	var err error
 
-	analyticsURL := "https://www.google-analytics.com"
-	if customURL := os.Getenv("AKAMAI_CLI_ANALYTICS_URL"); customURL != "" {
-		analyticsURL = customURL
-	}
+	analyticsURL := analyticsURL()
 	if debug != "" {
 		req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/debug/collect", analyticsURL), strings.NewReader(form.Encode()))
 	} else {
@@ -206,7 +205,8 @@ func TrackEvent(ctx context.Context, category, action, value string) {
 
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	res, err := hc.Do(req)
+	// statistics are not worth delaying commands
+	res, err := hc.Do(req.WithContext(tools.WithoutRetry(req.Context())))
 	if err != nil {
 		return
 	}
@@ -217,6 +217,14 @@ func TrackEvent(ctx context.Context, category, action, value string) {
 	}
 }
 
+// analyticsURL returns the URL of the analytics service, which can be overridden with AKAMAI_CLI_ANALYTICS_URL
+func analyticsURL() string {
+	if customURL := os.Getenv("AKAMAI_CLI_ANALYTICS_URL"); customURL != "" {
+		return customURL
+	}
+	return "https://www.google-analytics.com"
+}
+
 // CheckPing ...
 func CheckPing(ctx context.Context) error {
 	cfg := config.Get(ctx)
@@ -0,0 +1,364 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/akamai/cli/pkg/version"
+)
+
+// Telemetry is opt-in: it is enabled only with cli.telemetry set to on, independently of cli.enable-cli-statistics.
+//
+// Events are queued in a file of the Akamai CLI directory and sent in batches, so that users can inspect them before
+// they leave their machine. Events hold no arguments, paths, user or host names, and are all sent with the same anonymous client ID.
+
+const (
+	telemetryQueueName = "telemetry.jsonl"
+	telemetryClientID  = "anonymous"
+	// TelemetryBatchSize is the number of queued events which are sent together, at most, in a single request
+	TelemetryBatchSize = 20
+	// TelemetryMaxAge is how long events are queued before being sent, if fewer than TelemetryBatchSize are queued
+	TelemetryMaxAge = 24 * time.Hour
+	// telemetryQueueLimit is the number of events kept while they cannot be sent, the oldest ones being dropped
+	telemetryQueueLimit = 10 * TelemetryBatchSize
+	// telemetryFailuresName is the file recording failed attempts to send events, next to the queue
+	telemetryFailuresName = "telemetry-failures.json"
+	// telemetryRetryInterval is how long to wait after a failed attempt before sending events again
+	telemetryRetryInterval = time.Hour
+	// telemetryMaxFailures is the number of failed attempts in a row after which queued events are dropped
+	telemetryMaxFailures = 5
+)
+
+// telemetryTimeout is how long sending events can take, so that commands are barely delayed when the network drops requests
+var telemetryTimeout = 5 * time.Second
+
+// TelemetryEvent is an anonymous usage event: a built-in command being run, or a package operation, and its outcome
+type TelemetryEvent struct {
+	Time     time.Time `json:"time"`
+	Category string    `json:"category"`
+	Action   string    `json:"action"`
+	Outcome  string    `json:"outcome"`
+	Version  string    `json:"version"`
+	Platform string    `json:"platform"`
+}
+
+// telemetryFailures records failed attempts in a row to send the queued events
+type telemetryFailures struct {
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// telemetryMutex serializes writes of events recorded by packages installed or updated concurrently
+var telemetryMutex sync.Mutex
+
+// ParseTelemetry parses the cli.telemetry setting. Telemetry is off for an empty value.
+func ParseTelemetry(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "off", "false":
+		return false, nil
+	case "on", "true":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid telemetry setting %q, expected on or off", value)
+}
+
+// TelemetryEnabled returns true if telemetry was turned on with cli.telemetry
+func TelemetryEnabled() bool {
+	enabled, _ := ParseTelemetry(os.Getenv("AKAMAI_CLI_TELEMETRY"))
+	return enabled
+}
+
+// TelemetryQueuePath returns location of the file queueing events until they are sent
+func TelemetryQueuePath() (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, telemetryQueueName), nil
+}
+
+// TelemetryURL returns the URL batches of events are sent to
+func TelemetryURL() string {
+	return analyticsURL() + "/batch"
+}
+
+// RecordTelemetry queues an event if telemetry is enabled.
+// Telemetry is not worth failing commands: errors queueing the event are only logged.
+func RecordTelemetry(ctx context.Context, category, action, outcome string) {
+	if !TelemetryEnabled() {
+		return
+	}
+	logger := log.FromContext(ctx)
+	event := TelemetryEvent{
+		Time:     time.Now().UTC().Truncate(time.Second),
+		Category: category,
+		Action:   action,
+		Outcome:  outcome,
+		Version:  version.Version,
+		Platform: runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		logger.Debugf("Unable to encode telemetry event: %s", err)
+		return
+	}
+	path, err := TelemetryQueuePath()
+	if err != nil {
+		logger.Debugf("Unable to determine telemetry queue location: %s", err)
+		return
+	}
+
+	telemetryMutex.Lock()
+	defer telemetryMutex.Unlock()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		logger.Debugf("Unable to open telemetry queue: %s", err)
+		return
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			logger.Debugf("Unable to close telemetry queue: %s", err)
+		}
+	}()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		logger.Debugf("Unable to queue telemetry event: %s", err)
+	}
+}
+
+// ReadTelemetryQueue returns events waiting to be sent, oldest first. Lines which cannot be parsed are skipped.
+func ReadTelemetryQueue() ([]TelemetryEvent, error) {
+	path, err := TelemetryQueuePath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []TelemetryEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event TelemetryEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// TelemetryPayloads returns bodies of the requests sending given events, each one holding a batch of at most TelemetryBatchSize events
+func TelemetryPayloads(events []TelemetryEvent) []string {
+	var payloads []string
+	for start := 0; start < len(events); start += TelemetryBatchSize {
+		end := start + TelemetryBatchSize
+		if end > len(events) {
+			end = len(events)
+		}
+		hits := make([]string, 0, end-start)
+		for _, event := range events[start:end] {
+			form := url.Values{}
+			form.Add("tid", trackingID)
+			form.Add("v", "1")                 // Version 1
+			form.Add("aip", "1")               // Anonymize IP
+			form.Add("cid", telemetryClientID) // Client ID
+			form.Add("t", "event")             // Type
+			form.Add("ec", event.Category)     // Category
+			form.Add("ea", event.Action)       // Action
+			form.Add("el", event.Outcome)      // Label
+			form.Add("an", "akamai-cli")       // Application name
+			form.Add("av", event.Version)      // Application version
+			form.Add("cd1", event.Platform)    // Platform, as a custom dimension
+			hits = append(hits, form.Encode())
+		}
+		payloads = append(payloads, strings.Join(hits, "\n"))
+	}
+	return payloads
+}
+
+// TelemetryDue returns true if queued events should be sent: once a batch is full, or the oldest event is TelemetryMaxAge old
+func TelemetryDue(events []TelemetryEvent, now time.Time) bool {
+	if len(events) == 0 {
+		return false
+	}
+	return len(events) >= TelemetryBatchSize || !events[0].Time.Add(TelemetryMaxAge).After(now)
+}
+
+// SendTelemetry sends queued events if they are due, and removes them from the queue once sent.
+// Events queued before telemetry was turned off are discarded instead. If events cannot be sent, they are kept
+// to be sent later, up to a limit above which the oldest are dropped. Sending takes at most telemetryTimeout, and is
+// not attempted again for telemetryRetryInterval after a failure; after telemetryMaxFailures in a row, events are dropped.
+func SendTelemetry(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	path, err := TelemetryQueuePath()
+	if err != nil {
+		return err
+	}
+	failuresPath := filepath.Join(filepath.Dir(path), telemetryFailuresName)
+	if !TelemetryEnabled() {
+		for _, p := range []string{path, failuresPath} {
+			if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		return nil
+	}
+	events, err := ReadTelemetryQueue()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if !TelemetryDue(events, now) {
+		return nil
+	}
+	failures := readTelemetryFailures(failuresPath)
+	if failures.Count > 0 && now.Sub(failures.Last) < telemetryRetryInterval {
+		logger.Debugf("Not sending telemetry events until %s, after %d failed attempts", failures.Last.Add(telemetryRetryInterval), failures.Count)
+		return nil
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, telemetryTimeout)
+	defer cancel()
+	var sent int
+	var sendErr error
+	for _, payload := range TelemetryPayloads(events) {
+		if sendErr = sendTelemetryBatch(sendCtx, payload); sendErr != nil {
+			break
+		}
+		sent += TelemetryBatchSize
+	}
+	if sent > len(events) {
+		sent = len(events)
+	}
+	logger.Debugf("Sent %d of %d telemetry events", sent, len(events))
+
+	remaining := events[sent:]
+	if len(remaining) > telemetryQueueLimit {
+		remaining = remaining[len(remaining)-telemetryQueueLimit:]
+	}
+	if sendErr == nil {
+		failures = telemetryFailures{}
+	} else {
+		failures.Count++
+		failures.Last = now
+		if failures.Count >= telemetryMaxFailures {
+			logger.Debugf("Dropping %d telemetry events after %d failed attempts", len(remaining), failures.Count)
+			remaining, failures = nil, telemetryFailures{}
+		}
+	}
+	if err := writeTelemetryFailures(failuresPath, failures); err != nil {
+		return err
+	}
+	if err := writeTelemetryQueue(path, remaining); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// readTelemetryFailures returns the failed attempts to send events recorded at given path, none if it can't be read
+func readTelemetryFailures(path string) telemetryFailures {
+	var failures telemetryFailures
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return failures
+	}
+	if err := json.Unmarshal(data, &failures); err != nil {
+		return telemetryFailures{}
+	}
+	return failures
+}
+
+// writeTelemetryFailures records failed attempts to send events, removing the file if there are none
+func writeTelemetryFailures(path string, failures telemetryFailures) error {
+	if failures.Count == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.Marshal(failures)
+	if err != nil {
+		return err
+	}
+	return tools.WriteFileAtomic(path, data, 0600)
+}
+
+func sendTelemetryBatch(ctx context.Context, payload string) error {
+	req, err := http.NewRequest(http.MethodPost, TelemetryURL(), strings.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	// telemetry is not worth delaying commands
+	res, err := tools.HTTPClient(0).Do(req.WithContext(tools.WithoutRetry(ctx)))
+	if err != nil {
+		return fmt.Errorf("unable to send telemetry: %w", err)
+	}
+	defer res.Body.Close()
+	if _, err := ioutil.ReadAll(res.Body); err != nil {
+		return fmt.Errorf("unable to send telemetry: %w", err)
+	}
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unable to send telemetry: %s", res.Status)
+	}
+	return nil
+}
+
+// writeTelemetryQueue replaces the queue with given events, removing it if there are none
+func writeTelemetryQueue(path string, events []TelemetryEvent) error {
+	telemetryMutex.Lock()
+	defer telemetryMutex.Unlock()
+	if len(events) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	var data []byte
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
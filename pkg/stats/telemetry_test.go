@@ -0,0 +1,270 @@
+package stats
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTempTelemetry sets AKAMAI_CLI_HOME to a new temporary directory and turns telemetry on or off.
+// The returned function removes the directory and restores the environment.
+func useTempTelemetry(t *testing.T, enabled string) func() {
+	home, err := ioutil.TempDir("", "akamai-telemetry")
+	require.NoError(t, err)
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", home))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_TELEMETRY", enabled))
+	return func() {
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_HOME"))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_TELEMETRY"))
+		require.NoError(t, os.RemoveAll(home))
+	}
+}
+
+func queueTelemetry(t *testing.T, events []TelemetryEvent) {
+	path, err := TelemetryQueuePath()
+	require.NoError(t, err)
+	require.NoError(t, writeTelemetryQueue(path, events))
+}
+
+func TestParseTelemetry(t *testing.T) {
+	tests := map[string]struct {
+		value     string
+		expected  bool
+		withError string
+	}{
+		"default":        {value: "", expected: false},
+		"on":             {value: " on ", expected: true},
+		"off":            {value: "OFF", expected: false},
+		"boolean values": {value: "true", expected: true},
+		"invalid":        {value: "maybe", withError: `invalid telemetry setting "maybe", expected on or off`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			enabled, err := ParseTelemetry(test.value)
+			if test.withError != "" {
+				assert.EqualError(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, enabled)
+		})
+	}
+}
+
+func TestRecordTelemetry(t *testing.T) {
+	tests := map[string]struct {
+		enabled  string
+		expected []string
+	}{
+		"telemetry on": {
+			enabled:  "on",
+			expected: []string{"command list success", "install cli-echo build failure"},
+		},
+		"telemetry off": {
+			enabled: "off",
+		},
+		"telemetry not configured": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer useTempTelemetry(t, test.enabled)()
+
+			RecordTelemetry(context.Background(), "command", "list", "success")
+			RecordTelemetry(context.Background(), "install", "cli-echo", "build failure")
+
+			events, err := ReadTelemetryQueue()
+			require.NoError(t, err)
+			var recorded []string
+			for _, event := range events {
+				recorded = append(recorded, strings.Join([]string{event.Category, event.Action, event.Outcome}, " "))
+				assert.NotEmpty(t, event.Version)
+				assert.Contains(t, event.Platform, "/")
+				assert.WithinDuration(t, time.Now(), event.Time, time.Minute)
+			}
+			assert.Equal(t, test.expected, recorded)
+		})
+	}
+}
+
+func TestTelemetryPayloads(t *testing.T) {
+	events := make([]TelemetryEvent, TelemetryBatchSize+1)
+	for i := range events {
+		events[i] = TelemetryEvent{Category: "command", Action: "list", Outcome: "success", Version: "1.3.0", Platform: "linux/amd64"}
+	}
+	events[TelemetryBatchSize] = TelemetryEvent{Category: "install", Action: "third-party", Outcome: "network failure", Version: "1.3.0", Platform: "darwin/arm64"}
+
+	payloads := TelemetryPayloads(events)
+
+	require.Len(t, payloads, 2)
+	assert.Len(t, strings.Split(payloads[0], "\n"), TelemetryBatchSize)
+	assert.Equal(t, "aip=1&an=akamai-cli&av=1.3.0&cd1=darwin%2Farm64&cid=anonymous&ea=third-party&ec=install&el=network+failure&t=event&tid=UA-34796267-23&v=1", payloads[1])
+	assert.Empty(t, TelemetryPayloads(nil))
+}
+
+func TestTelemetryDue(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		events   []TelemetryEvent
+		expected bool
+	}{
+		"no events": {},
+		"recent events": {
+			events: []TelemetryEvent{{Time: now.Add(-time.Hour)}, {Time: now}},
+		},
+		"oldest event a day old": {
+			events:   []TelemetryEvent{{Time: now.Add(-TelemetryMaxAge)}, {Time: now}},
+			expected: true,
+		},
+		"full batch": {
+			events:   make([]TelemetryEvent, TelemetryBatchSize),
+			expected: true,
+		},
+	}
+	for i := range tests["full batch"].events {
+		tests["full batch"].events[i].Time = now
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, TelemetryDue(test.events, now))
+		})
+	}
+}
+
+func TestSendTelemetry(t *testing.T) {
+	old := time.Now().Add(-2 * TelemetryMaxAge)
+	tests := map[string]struct {
+		enabled          string
+		queued           []TelemetryEvent
+		failures         telemetryFailures
+		status           int
+		delay            time.Duration
+		expectedSent     int
+		expectedQueue    int
+		expectedFailures int
+		withError        string
+	}{
+		"events sent": {
+			enabled:      "on",
+			queued:       []TelemetryEvent{{Time: old, Category: "command", Action: "list"}, {Time: old, Category: "command", Action: "search"}},
+			status:       http.StatusOK,
+			expectedSent: 1,
+		},
+		"events not due yet": {
+			enabled:       "on",
+			queued:        []TelemetryEvent{{Time: time.Now(), Category: "command", Action: "list"}},
+			expectedQueue: 1,
+		},
+		"events kept if they cannot be sent": {
+			enabled:          "on",
+			queued:           []TelemetryEvent{{Time: old, Category: "command", Action: "list"}},
+			status:           http.StatusServiceUnavailable,
+			expectedSent:     1,
+			expectedQueue:    1,
+			expectedFailures: 1,
+			withError:        "unable to send telemetry: 503 Service Unavailable",
+		},
+		"events kept if sending times out": {
+			enabled:          "on",
+			queued:           []TelemetryEvent{{Time: old, Category: "command", Action: "list"}},
+			status:           http.StatusOK,
+			delay:            time.Second,
+			expectedSent:     1,
+			expectedQueue:    1,
+			expectedFailures: 1,
+			withError:        "context deadline exceeded",
+		},
+		"events not sent again shortly after a failure": {
+			enabled:          "on",
+			queued:           []TelemetryEvent{{Time: old, Category: "command", Action: "list"}},
+			failures:         telemetryFailures{Count: 1, Last: time.Now().Add(-time.Minute)},
+			expectedQueue:    1,
+			expectedFailures: 1,
+		},
+		"events sent again once retry interval elapsed": {
+			enabled:      "on",
+			queued:       []TelemetryEvent{{Time: old, Category: "command", Action: "list"}},
+			failures:     telemetryFailures{Count: 2, Last: time.Now().Add(-2 * telemetryRetryInterval)},
+			status:       http.StatusOK,
+			expectedSent: 1,
+		},
+		"events dropped after repeated failures": {
+			enabled:      "on",
+			queued:       []TelemetryEvent{{Time: old, Category: "command", Action: "list"}},
+			failures:     telemetryFailures{Count: telemetryMaxFailures - 1, Last: time.Now().Add(-2 * telemetryRetryInterval)},
+			status:       http.StatusServiceUnavailable,
+			expectedSent: 1,
+			withError:    "unable to send telemetry: 503 Service Unavailable",
+		},
+		"events discarded once telemetry is off": {
+			enabled: "off",
+			queued:  []TelemetryEvent{{Time: old, Category: "command", Action: "list"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer useTempTelemetry(t, test.enabled)()
+			var sent int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/batch", r.URL.String())
+				assert.Equal(t, http.MethodPost, r.Method)
+				body, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, TelemetryPayloads(test.queued)[0], string(body))
+				atomic.AddInt32(&sent, 1)
+				select {
+				case <-time.After(test.delay):
+				case <-r.Context().Done():
+				}
+				w.WriteHeader(test.status)
+			}))
+			defer srv.Close()
+			defer func(timeout time.Duration) {
+				telemetryTimeout = timeout
+			}(telemetryTimeout)
+			telemetryTimeout = 100 * time.Millisecond
+			require.NoError(t, os.Setenv("AKAMAI_CLI_ANALYTICS_URL", srv.URL))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_ANALYTICS_URL"))
+			}()
+			queueTelemetry(t, test.queued)
+			path, err := TelemetryQueuePath()
+			require.NoError(t, err)
+			failuresPath := filepath.Join(filepath.Dir(path), telemetryFailuresName)
+			require.NoError(t, writeTelemetryFailures(failuresPath, test.failures))
+
+			err = SendTelemetry(context.Background())
+
+			assert.Equal(t, test.expectedSent, int(atomic.LoadInt32(&sent)))
+			events, readErr := ReadTelemetryQueue()
+			require.NoError(t, readErr)
+			assert.Len(t, events, test.expectedQueue)
+			assert.Equal(t, test.expectedFailures, readTelemetryFailures(failuresPath).Count)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			if test.expectedQueue == 0 {
+				path, err := TelemetryQueuePath()
+				require.NoError(t, err)
+				_, err = os.Stat(path)
+				assert.True(t, os.IsNotExist(err))
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+type (
+	// BufferedTerminal records all output in memory instead of writing it to the screen.
+	// It is used by tasks running concurrently, so that the output of each task can be displayed at once when the task is done.
+	// User input is not available.
+	BufferedTerminal struct {
+		mu   sync.Mutex
+		buf  bytes.Buffer
+		spnr *bufferedSpinner
+	}
+
+	// bufferedSpinner records final spinner status lines in the terminal buffer
+	bufferedSpinner struct {
+		term   *BufferedTerminal
+		prefix string
+	}
+)
+
+// ErrNoInput is returned when user input is requested from a terminal which does not support it
+var ErrNoInput = errors.New("user input is not available")
+
+// NewBuffered returns a new buffered terminal
+func NewBuffered() *BufferedTerminal {
+	t := &BufferedTerminal{}
+	t.spnr = &bufferedSpinner{term: t}
+	return t
+}
+
+// Write appends data to the buffer
+func (t *BufferedTerminal) Write(v []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.buf.Write(v)
+}
+
+// Printf writes a formatted message to the buffer
+func (t *BufferedTerminal) Printf(f string, args ...interface{}) {
+	fmt.Fprintf(t, f, args...)
+}
+
+// Writeln writes a line to the buffer
+func (t *BufferedTerminal) Writeln(args ...interface{}) (int, error) {
+	return fmt.Fprintln(t, args...)
+}
+
+// WriteError writes a message to the buffer
+func (t *BufferedTerminal) WriteError(v interface{}) {
+	fmt.Fprint(t, v)
+}
+
+// WriteErrorf writes a formatted message to the buffer
+func (t *BufferedTerminal) WriteErrorf(f string, args ...interface{}) {
+	fmt.Fprintf(t, f, args...)
+}
+
+// Error returns the buffer writer, as errors are displayed along with the rest of the output
+func (t *BufferedTerminal) Error() io.Writer {
+	return t
+}
+
+// Prompt returns ErrNoInput
+func (t *BufferedTerminal) Prompt(string, ...string) (string, error) {
+	return "", ErrNoInput
+}
+
+// Confirm returns ErrNoInput
+func (t *BufferedTerminal) Confirm(string, bool) (bool, error) {
+	return false, ErrNoInput
+}
+
+// IsTTY returns false, as the buffered terminal is not interactive
+func (t *BufferedTerminal) IsTTY() bool {
+	return false
+}
+
+// Spinner returns a spinner which records its final status in the buffer
+func (t *BufferedTerminal) Spinner() Spinner {
+	return t.spnr
+}
+
+// String returns the recorded output
+func (t *BufferedTerminal) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.buf.String()
+}
+
+// Start sets the message of the spinner
+func (s *bufferedSpinner) Start(f string, args ...interface{}) {
+	s.prefix = fmt.Sprintf(f, args...)
+}
+
+// Stop writes the spinner message with the final status
+func (s *bufferedSpinner) Stop(status SpinnerStatus) {
+	s.term.Printf("%s %s", s.prefix, status)
+}
+
+// Write discards progress updates
+func (s *bufferedSpinner) Write(v []byte) (int, error) {
+	return len(v), nil
+}
+
+// OK stops the spinner with ok status
+func (s *bufferedSpinner) OK() {
+	s.Stop(SpinnerStatusOK)
+}
+
+// WarnOK stops the spinner with WarnOK status
+func (s *bufferedSpinner) WarnOK() {
+	s.Stop(SpinnerStatusWarnOK)
+}
+
+// Warn stops the spinner with Warn status
+func (s *bufferedSpinner) Warn() {
+	s.Stop(SpinnerStatusWarn)
+}
+
+// Fail stops the spinner with fail status
+func (s *bufferedSpinner) Fail() {
+	s.Stop(SpinnerStatusFail)
+}
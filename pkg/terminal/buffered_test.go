@@ -0,0 +1,43 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferedTerminal(t *testing.T) {
+	term := NewBuffered()
+
+	term.Spinner().Start("Attempting to update %s...", "echo")
+	_, err := term.Spinner().Write([]byte("progress"))
+	require.NoError(t, err)
+	term.Spinner().OK()
+	term.Printf("test: %s\n", "abc")
+	_, err = term.Writeln("line")
+	require.NoError(t, err)
+	term.WriteError("oops")
+
+	assert.Equal(t, "Attempting to update echo... "+string(SpinnerStatusOK)+"test: abc\nline\noops", term.String())
+	assert.False(t, term.IsTTY())
+
+	_, err = term.Confirm("Continue?", true)
+	assert.Equal(t, ErrNoInput, err)
+	_, err = term.Prompt("Name")
+	assert.Equal(t, ErrNoInput, err)
+}
@@ -0,0 +1,55 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const progressBarWidth = 20
+
+// ProgressReader reports how many bytes have been read from the underlying reader to Out, for example a spinner
+type ProgressReader struct {
+	io.Reader
+	// Total is the expected number of bytes, or a value lower than 1 if it is not known
+	Total int64
+	Out   io.Writer
+	read  int64
+}
+
+// Read implements io.Reader and reports progress after each read
+func (r *ProgressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+	if n > 0 {
+		_, _ = io.WriteString(r.Out, FormatProgress(r.read, r.Total))
+	}
+	return n, err
+}
+
+// FormatProgress returns a progress bar with percentage and transferred bytes, e.g. "[=====     ] 50% 1.0 MB / 2.0 MB"
+// If total is not known, only the transferred bytes are returned
+func FormatProgress(current, total int64) string {
+	if total < 1 {
+		return formatBytes(current)
+	}
+	if current > total {
+		current = total
+	}
+	done := int(current * progressBarWidth / total)
+	bar := strings.Repeat("=", done) + strings.Repeat(" ", progressBarWidth-done)
+	return fmt.Sprintf("[%s] %d%% %s / %s", bar, current*100/total, formatBytes(current), formatBytes(total))
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 3; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
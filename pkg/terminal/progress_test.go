@@ -0,0 +1,40 @@
+package terminal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatProgress(t *testing.T) {
+	tests := map[string]struct {
+		current, total int64
+		expected       string
+	}{
+		"unknown total":   {current: 512, expected: "512 B"},
+		"kilobytes":       {current: 1536, expected: "1.5 KB"},
+		"start":           {current: 0, total: 2 << 20, expected: "[                    ] 0% 0 B / 2.0 MB"},
+		"half":            {current: 1 << 20, total: 2 << 20, expected: "[==========          ] 50% 1.0 MB / 2.0 MB"},
+		"done":            {current: 3 << 30, total: 3 << 30, expected: "[====================] 100% 3.0 GB / 3.0 GB"},
+		"more than total": {current: 20, total: 10, expected: "[====================] 100% 10 B / 10 B"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, FormatProgress(test.current, test.total))
+		})
+	}
+}
+
+func TestProgressReader(t *testing.T) {
+	var out bytes.Buffer
+	r := &ProgressReader{Reader: strings.NewReader("0123456789"), Total: 10, Out: &out}
+	res, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(res))
+	assert.True(t, strings.HasSuffix(out.String(), "[====================] 100% 10 B / 10 B"))
+}
@@ -25,6 +25,11 @@ type (
 	DefaultSpinner struct {
 		spinner *spnr.Spinner
 		prefix  string
+		static  bool
+		// quiet spinners print only failures
+		quiet bool
+		// lastLine is the time progress was last printed in static mode
+		lastLine time.Time
 	}
 )
 
@@ -36,6 +41,9 @@ var (
 	SpinnerStatusFail   = SpinnerStatus(fmt.Sprintf("... [%s]\n", color.RedString("FAIL")))
 )
 
+// StaticProgressInterval is the minimum interval between progress lines printed when the spinner is not animated
+var StaticProgressInterval = 5 * time.Second
+
 // StandardSpinner returns a default spinner for Akamai CLI
 func StandardSpinner() *DefaultSpinner {
 	return &DefaultSpinner{spinner: spnr.New(spnr.CharSets[33], 500*time.Millisecond)}
@@ -44,23 +52,79 @@ func StandardSpinner() *DefaultSpinner {
 // Start starts the spinner using the provided string as the prefix
 func (s *DefaultSpinner) Start(f string, args ...interface{}) {
 	s.prefix = fmt.Sprintf(f, args...)
+	if s.quiet {
+		return
+	}
+	if s.static {
+		s.lastLine = time.Now()
+		return
+	}
 	s.spinner.Prefix = s.prefix + " "
 	s.spinner.Start()
 }
 
 // Stop stops the spinner and updates the final status message
 func (s *DefaultSpinner) Stop(status SpinnerStatus) {
+	s.spinner.Lock()
 	s.spinner.Suffix = ""
+	s.spinner.Unlock()
+	if s.quiet {
+		if status == SpinnerStatusFail {
+			fmt.Fprint(s.spinner.Writer, s.prefix+" "+string(status))
+		}
+		return
+	}
+	if s.static {
+		fmt.Fprint(s.spinner.Writer, s.prefix+" "+string(status))
+		return
+	}
 	s.spinner.FinalMSG = s.prefix + " " + string(status)
 	s.spinner.Stop()
 }
 
-// Write implements the io.Writer interface and updates the suffix of the spinner
+// Write implements the io.Writer interface and updates the suffix of the spinner with the last line written,
+// such as git transfer progress or download progress.
+// In static mode, progress is printed as separate lines, at most once per StaticProgressInterval
 func (s *DefaultSpinner) Write(v []byte) (n int, err error) {
-	s.spinner.Suffix = " " + strings.TrimSpace(string(v))
+	line := lastLine(string(v))
+	if line == "" || s.quiet {
+		return len(v), nil
+	}
+	if s.static {
+		if s.prefix != "" && time.Since(s.lastLine) >= StaticProgressInterval {
+			s.lastLine = time.Now()
+			fmt.Fprintf(s.spinner.Writer, "%s %s\n", s.prefix, line)
+		}
+		return len(v), nil
+	}
+	s.spinner.Lock()
+	s.spinner.Suffix = " " + line
+	s.spinner.Unlock()
 	return len(v), nil
 }
 
+// lastLine returns the last non-empty line of progress output, in which lines may be terminated with carriage returns
+func lastLine(v string) string {
+	lines := strings.FieldsFunc(v, func(r rune) bool { return r == '\r' || r == '\n' })
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// DisableAnimation makes the spinner print only the final status line, without animation frames and cursor movements.
+// It is used when the output is not a terminal, for example in CI logs
+func (s *DefaultSpinner) DisableAnimation() {
+	s.static = true
+}
+
+// Silence makes the spinner print nothing but the status line of failed steps, as with the global --quiet flag
+func (s *DefaultSpinner) Silence() {
+	s.quiet = true
+}
+
 // OK stops the spinner with ok status
 func (s *DefaultSpinner) OK() {
 	s.Stop(SpinnerStatusOK)
@@ -113,3 +113,80 @@ func TestSpinnerWrite(t *testing.T) {
 	assert.Equal(t, 4, l)
 	assert.Equal(t, " test", s.spinner.Suffix)
 }
+
+func TestSpinnerWriteProgress(t *testing.T) {
+	tests := map[string]struct {
+		static   bool
+		expected string
+	}{
+		"animated spinner shows last progress line": {
+			expected: "",
+		},
+		"static spinner prints progress lines": {
+			static:   true,
+			expected: "cloning Receiving objects: 100% (10/10)\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wr := bytes.Buffer{}
+			s := DefaultSpinner{
+				spinner: spnr.New(spnr.CharSets[26], 1*time.Minute, spnr.WithWriter(&wr)),
+				static:  test.static,
+				prefix:  "cloning",
+			}
+			_, err := s.Write([]byte("Receiving objects:  50% (5/10)\rReceiving objects: 100% (10/10)\r\n"))
+			assert.NoError(t, err)
+			_, err = s.Write([]byte("\r"))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, wr.String())
+			if !test.static {
+				assert.Equal(t, " Receiving objects: 100% (10/10)", s.spinner.Suffix)
+			}
+		})
+	}
+}
+
+func TestDisableAnimation(t *testing.T) {
+	wr := bytes.Buffer{}
+	s := DefaultSpinner{
+		spinner: spnr.New(spnr.CharSets[26], 1*time.Millisecond, spnr.WithWriter(&wr)),
+	}
+	s.DisableAnimation()
+	s.Start("spinner %s", "test")
+	time.Sleep(10 * time.Millisecond)
+	_, err := s.Write([]byte("progress"))
+	assert.NoError(t, err)
+	assert.Empty(t, wr.String())
+	s.OK()
+	assert.Equal(t, "spinner test ... [OK]\n", colorSequence.ReplaceAllString(wr.String(), ""))
+}
+
+func TestSilence(t *testing.T) {
+	tests := map[string]struct {
+		stop     func(s *DefaultSpinner)
+		expected string
+	}{
+		"ok":   {stop: (*DefaultSpinner).OK},
+		"warn": {stop: (*DefaultSpinner).Warn},
+		"fail": {stop: (*DefaultSpinner).Fail, expected: "spinner test ... [FAIL]\n"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wr := bytes.Buffer{}
+			s := DefaultSpinner{
+				spinner: spnr.New(spnr.CharSets[26], 1*time.Millisecond, spnr.WithWriter(&wr)),
+			}
+			s.Silence()
+			s.Start("spinner %s", "test")
+			time.Sleep(10 * time.Millisecond)
+			_, err := s.Write([]byte("progress"))
+			assert.NoError(t, err)
+			assert.Empty(t, wr.String())
+			test.stop(&s)
+			assert.Equal(t, test.expected, colorSequence.ReplaceAllString(wr.String(), ""))
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"regexp"
 	"strings"
 	"time"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/mattn/go-isatty"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
 )
 
 type (
@@ -69,6 +71,11 @@ type (
 		fd uintptr
 	}
 
+	// noColorWriter removes color escape sequences from written data
+	noColorWriter struct {
+		io.Writer
+	}
+
 	// Reader provides a minimal interface for Stdout.
 	Reader interface {
 		io.Reader
@@ -77,11 +84,12 @@ type (
 
 	// DefaultTerminal implementation of Terminal interface
 	DefaultTerminal struct {
-		out   Writer
-		err   io.Writer
-		in    Reader
-		start time.Time
-		spnr  *DefaultSpinner
+		out     Writer
+		err     io.Writer
+		in      Reader
+		start   time.Time
+		spnr    *DefaultSpinner
+		noColor bool
 	}
 
 	// SpinnerStatus defines a spinner status message
@@ -90,16 +98,30 @@ type (
 	contextType string
 )
 
-var terminalContext contextType = "terminal"
+var (
+	terminalContext contextType = "terminal"
+
+	colorSequence = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
 
 // Color returns a colorable terminal
+// Colors are disabled if NO_COLOR environment variable is set or the output is not a terminal
+// Spinner animation is disabled if the output is not a terminal
 func Color() *DefaultTerminal {
 	wr := &colorWriter{
 		Writer: colorable.NewColorableStdout(),
 		fd:     os.Stdout.Fd(),
 	}
 
-	return New(wr, os.Stdin, colorable.NewColorableStderr())
+	t := New(wr, os.Stdin, colorable.NewColorableStderr())
+	if os.Getenv("NO_COLOR") != "" || !t.IsTTY() {
+		t.DisableColors()
+	}
+	// the spinner writes to stderr, which may be redirected separately from stdout
+	if !t.IsTTY() || !isatty.IsTerminal(os.Stderr.Fd()) && !isatty.IsCygwinTerminal(os.Stderr.Fd()) {
+		t.spnr.DisableAnimation()
+	}
+	return t
 }
 
 // New returns a new terminal with the specifed streams
@@ -193,6 +215,28 @@ func (t *DefaultTerminal) IsTTY() bool {
 	return isatty.IsTerminal(t.out.Fd()) || isatty.IsCygwinTerminal(t.out.Fd())
 }
 
+// DisableColors removes color escape sequences from all output written to the terminal, including the spinner and prompts
+func (t *DefaultTerminal) DisableColors() {
+	color.NoColor = true
+	core.DisableColor = true
+	if t.noColor {
+		return
+	}
+	t.noColor = true
+	t.out = &colorWriter{
+		Writer: &noColorWriter{t.out},
+		fd:     t.out.Fd(),
+	}
+	t.err = &noColorWriter{t.err}
+	t.spnr.spinner.Writer = t.err
+}
+
+// Quiet disables colors and silences the spinner, so that only command results and errors are written, e.g. in scripts
+func (t *DefaultTerminal) Quiet() {
+	t.DisableColors()
+	t.spnr.Silence()
+}
+
 // Spinner returns the terminal spinner
 func (t *DefaultTerminal) Spinner() Spinner {
 	return t.spnr
@@ -226,6 +270,18 @@ func (w *colorWriter) Fd() uintptr {
 	return w.fd
 }
 
+func (w *noColorWriter) Write(v []byte) (int, error) {
+	if _, err := w.Writer.Write(colorSequence.ReplaceAll(v, nil)); err != nil {
+		return 0, err
+	}
+	return len(v), nil
+}
+
+// StripColors removes color escape sequences from s, e.g. from error messages saved to files
+func StripColors(s string) string {
+	return colorSequence.ReplaceAllString(s, "")
+}
+
 // ShowBanner displays welcome banner
 func ShowBanner(ctx context.Context) {
 	term := Get(ctx)
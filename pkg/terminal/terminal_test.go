@@ -232,3 +232,26 @@ func TestShowBanner(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, string(data), "Welcome to Akamai CLI")
 }
+
+func TestDisableColors(t *testing.T) {
+	out, err := ioutil.TempFile("", t.Name())
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, os.Remove(out.Name())) // clean up
+	}()
+
+	term := New(out, nil, DiscardWriter())
+	term.DisableColors()
+	term.DisableColors()
+
+	term.Printf("test: %s\n", "\x1b[31mabc\x1b[0m \x1b[1;33mdef\x1b[0m")
+
+	_, err = out.Seek(0, 0)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+
+	assert.Equal(t, "test: abc def\n", string(data))
+	assert.Equal(t, out.Fd(), term.out.Fd())
+}
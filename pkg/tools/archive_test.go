@@ -0,0 +1,161 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarGz(t *testing.T) {
+	src, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(src))
+	}()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "cli.json"), []byte(`{}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "bin", "akamai-test"), []byte("binary"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "skipped"), []byte("skipped"), 0644))
+
+	archive := filepath.Join(src, "pkg.tar.gz")
+	err = CreateTarGz(src, archive, "pkg", func(rel string, info os.FileInfo) bool {
+		return rel == "skipped" || rel == "pkg.tar.gz"
+	})
+	require.NoError(t, err)
+
+	dst := filepath.Join(src, "out")
+	require.NoError(t, ExtractTarGz(archive, dst))
+
+	data, err := ioutil.ReadFile(filepath.Join(dst, "pkg", "cli.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(data))
+	info, err := os.Stat(filepath.Join(dst, "pkg", "bin", "akamai-test"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	_, err = os.Stat(filepath.Join(dst, "pkg", "skipped"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestExtractTarGzInvalidEntry(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	archive := filepath.Join(dir, "evil.tar.gz")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../evil", Mode: 0644, Size: 4, Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte("evil"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+
+	err = ExtractTarGz(archive, filepath.Join(dir, "out"))
+	assert.EqualError(t, err, "invalid archive entry: ../evil")
+	_, err = os.Stat(filepath.Join(dir, "evil"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestIsTarGz(t *testing.T) {
+	assert.True(t, IsTarGz("pkg.tar.gz"))
+	assert.True(t, IsTarGz("pkg.tgz"))
+	assert.False(t, IsTarGz("pkg.zip"))
+	assert.False(t, IsTarGz("property"))
+}
+
+func writeZip(t *testing.T, archive string, files map[string]os.FileMode) {
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for name, mode := range files {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		header.SetMode(mode)
+		w, err := zw.CreateHeader(header)
+		require.NoError(t, err)
+		if mode.IsRegular() {
+			_, err = w.Write([]byte(name))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+}
+
+func TestExtractZip(t *testing.T) {
+	tests := map[string]struct {
+		files     map[string]os.FileMode
+		withError string
+	}{
+		"package files": {
+			files: map[string]os.FileMode{"pkg/": os.ModeDir | 0755, "pkg/cli.json": 0644, "pkg/bin/akamai-test": 0755},
+		},
+		"entry outside of destination": {
+			files:     map[string]os.FileMode{"../evil": 0644},
+			withError: "invalid archive entry: ../evil",
+		},
+		"symbolic link": {
+			files:     map[string]os.FileMode{"link": os.ModeSymlink | 0777},
+			withError: "unsupported archive entry: link",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "akamai-zip")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			archive := filepath.Join(dir, "pkg.zip")
+			writeZip(t, archive, test.files)
+
+			dst := filepath.Join(dir, "out")
+			err = ExtractZip(archive, dst)
+			if test.withError != "" {
+				assert.EqualError(t, err, test.withError)
+				_, err = os.Stat(filepath.Join(dir, "evil"))
+				assert.True(t, os.IsNotExist(err))
+				return
+			}
+			require.NoError(t, err)
+			data, err := ioutil.ReadFile(filepath.Join(dst, "pkg", "cli.json"))
+			require.NoError(t, err)
+			assert.Equal(t, "pkg/cli.json", string(data))
+			info, err := os.Stat(filepath.Join(dst, "pkg", "bin", "akamai-test"))
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+		})
+	}
+}
+
+func TestIsZip(t *testing.T) {
+	assert.True(t, IsZip("pkg.zip"))
+	assert.False(t, IsZip("pkg.tar.gz"))
+	assert.False(t, IsZip("property"))
+}
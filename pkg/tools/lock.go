@@ -0,0 +1,123 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultLockTimeout is the time to wait for a lock held by another process, used when cli.lock-timeout is not set
+const DefaultLockTimeout = time.Minute
+
+// lockRetryInterval is the delay between attempts to acquire a lock held by another process
+const lockRetryInterval = 100 * time.Millisecond
+
+// ErrLocked is returned by LockFile if the lock is still held by another process once the timeout expires
+var ErrLocked = errors.New("another instance of Akamai CLI is running")
+
+// errWouldBlock is returned by tryLock if the file is locked by another process
+var errWouldBlock = errors.New("file is locked")
+
+// FileLock is an advisory lock on a file, held until Unlock is called
+type FileLock struct {
+	file *os.File
+}
+
+// ParseLockTimeout parses the cli.lock-timeout setting, e.g. "30s" or "5m". 0 means the lock is not waited for.
+// The default timeout is used for an empty value, and for an invalid value along with the error.
+func ParseLockTimeout(value string) (time.Duration, error) {
+	return parseDuration("lock timeout", value, DefaultLockTimeout)
+}
+
+// LockFile acquires an exclusive advisory lock on the file at given path, creating the file if needed.
+// If another process holds the lock, it is attempted again until the timeout expires or the context is done.
+// The lock is released by Unlock, or by the operating system once the process exits.
+func LockFile(ctx context.Context, path string, timeout time.Duration) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLock(file)
+		if err == nil {
+			return &FileLock{file: file}, nil
+		}
+		if !errors.Is(err, errWouldBlock) || !time.Now().Before(deadline) {
+			if closeErr := file.Close(); closeErr != nil {
+				return nil, closeErr
+			}
+			if errors.Is(err, errWouldBlock) {
+				return nil, fmt.Errorf("%w: %s is locked", ErrLocked, path)
+			}
+			return nil, err
+		}
+		if err := sleepContext(ctx, lockRetryInterval); err != nil {
+			if closeErr := file.Close(); closeErr != nil {
+				return nil, closeErr
+			}
+			return nil, err
+		}
+	}
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	if err := unlock(l.file); err != nil {
+		_ = l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
+
+// WriteFileAtomic writes data to a temporary file in the directory of given path, and renames it to path,
+// so that readers never see a partially written file and the previous content is kept if writing fails
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) (e error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
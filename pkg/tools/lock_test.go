@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lock")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "nested", ".lock")
+
+	lock, err := LockFile(context.Background(), path, 0)
+	require.NoError(t, err)
+
+	_, err = LockFile(context.Background(), path, 0)
+	assert.True(t, errors.Is(err, ErrLocked), "lock should be held: %v", err)
+
+	start := time.Now()
+	_, err = LockFile(context.Background(), path, 300*time.Millisecond)
+	assert.True(t, errors.Is(err, ErrLocked))
+	assert.True(t, time.Since(start) >= 300*time.Millisecond, "lock should be waited for")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = LockFile(ctx, path, time.Minute)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		assert.NoError(t, lock.Unlock())
+	}()
+	second, err := LockFile(context.Background(), path, 5*time.Second)
+	require.NoError(t, err)
+	assert.NoError(t, second.Unlock())
+}
+
+func TestParseLockTimeout(t *testing.T) {
+	tests := map[string]struct {
+		value     string
+		expected  time.Duration
+		withError bool
+	}{
+		"default":     {expected: DefaultLockTimeout},
+		"duration":    {value: "10s", expected: 10 * time.Second},
+		"do not wait": {value: "0s", expected: 0},
+		"invalid":     {value: "abc", expected: DefaultLockTimeout, withError: true},
+		"negative":    {value: "-1s", expected: DefaultLockTimeout, withError: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			timeout, err := ParseLockTimeout(test.value)
+			assert.Equal(t, test.expected, timeout)
+			assert.Equal(t, test.withError, err != nil)
+		})
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "config")
+	require.NoError(t, ioutil.WriteFile(path, []byte("old"), 0644))
+
+	require.NoError(t, WriteFileAtomic(path, []byte("new"), 0600))
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "temporary file should be renamed")
+
+	assert.Error(t, WriteFileAtomic(filepath.Join(dir, "missing", "config"), []byte("new"), 0600))
+}
@@ -0,0 +1,156 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	legacyDirName = ".akamai-cli"
+	xdgDirName    = "akamai"
+)
+
+// useLegacyLayout returns true if all Akamai CLI files are kept in a single .akamai-cli directory, instead of XDG base directories.
+// This is the case when AKAMAI_CLI_HOME or AKAMAI_CLI_LEGACY_LAYOUT is set, and on Windows.
+func useLegacyLayout() bool {
+	if os.Getenv("AKAMAI_CLI_HOME") != "" || runtime.GOOS == "windows" {
+		return true
+	}
+	legacy, _ := strconv.ParseBool(os.Getenv("AKAMAI_CLI_LEGACY_LAYOUT"))
+	return legacy
+}
+
+// GetAkamaiCliConfigPath returns the directory containing Akamai CLI config file, $XDG_CONFIG_HOME/akamai by default
+func GetAkamaiCliConfigPath() (string, error) {
+	if useLegacyLayout() {
+		return GetAkamaiCliPath()
+	}
+	return makeDir(xdgDir("XDG_CONFIG_HOME", ".config"))
+}
+
+// GetAkamaiCliCachePath returns the default Akamai CLI cache directory, $XDG_CACHE_HOME/akamai by default
+func GetAkamaiCliCachePath() (string, error) {
+	if useLegacyLayout() {
+		cliPath, err := GetAkamaiCliPath()
+		if err != nil {
+			return "", err
+		}
+		return makeDir(filepath.Join(cliPath, "cache"), nil)
+	}
+	return makeDir(xdgDir("XDG_CACHE_HOME", ".cache"))
+}
+
+// MigrateLegacyLayout moves files from the legacy ~/.akamai-cli directory to XDG base directories: the config file
+// to the config directory, the cache to the cache directory, and everything else, including packages, to the data directory.
+// The migration is done only once, when the data directory does not exist yet. It returns true if files have been moved.
+func MigrateLegacyLayout() (bool, error) {
+	if useLegacyLayout() {
+		return false, nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return false, err
+	}
+	legacyPath := filepath.Join(home, legacyDirName)
+	if info, err := os.Stat(legacyPath); err != nil || !info.IsDir() {
+		return false, nil
+	}
+	dataPath, err := xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(dataPath); !os.IsNotExist(err) {
+		return false, nil
+	}
+
+	configPath, err := xdgDir("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return false, err
+	}
+	if err := movePath(filepath.Join(legacyPath, "config"), filepath.Join(configPath, "config")); err != nil {
+		return false, err
+	}
+	cachePath, err := xdgDir("XDG_CACHE_HOME", ".cache")
+	if err != nil {
+		return false, err
+	}
+	if err := movePath(filepath.Join(legacyPath, "cache"), cachePath); err != nil {
+		return false, err
+	}
+	if err := movePath(legacyPath, dataPath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LegacyCachePath returns the cache directory used before migration to XDG base directories
+func LegacyCachePath() (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, legacyDirName, "cache"), nil
+}
+
+// xdgDir returns the akamai directory inside the base directory set in given environment variable.
+// Relative paths are ignored, as required by the XDG Base Directory specification.
+func xdgDir(env, fallback string) (string, error) {
+	if base := os.Getenv(env); base != "" && filepath.IsAbs(base) {
+		return filepath.Join(base, xdgDirName), nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", cli.Exit("Home directory could not be found. Please set $AKAMAI_CLI_HOME.", -1)
+	}
+	return filepath.Join(home, fallback, xdgDirName), nil
+}
+
+func makeDir(path string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return "", cli.Exit("Unable to create Akamai CLI root directory.", -1)
+	}
+	return path, nil
+}
+
+// movePath moves a file or a directory, unless the destination already exists.
+// Files are copied if they cannot be renamed, for example when destination is on a different filesystem.
+func movePath(src, dst string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := CopyDir(src, dst); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupHome(t *testing.T, env map[string]string) (string, func()) {
+	if runtime.GOOS == "windows" {
+		t.Skip("XDG base directories are not used on Windows")
+	}
+	home, err := ioutil.TempDir("", "akamai-home")
+	require.NoError(t, err)
+	homedir.DisableCache = true
+	vars := map[string]string{"HOME": home, "AKAMAI_CLI_HOME": "", "AKAMAI_CLI_LEGACY_LAYOUT": "", "XDG_CONFIG_HOME": "", "XDG_DATA_HOME": "", "XDG_CACHE_HOME": ""}
+	for k, v := range env {
+		vars[k] = v
+	}
+	previous := make(map[string]string)
+	for k, v := range vars {
+		previous[k] = os.Getenv(k)
+		require.NoError(t, os.Setenv(k, v))
+	}
+	return home, func() {
+		for k, v := range previous {
+			require.NoError(t, os.Setenv(k, v))
+		}
+		homedir.DisableCache = false
+		require.NoError(t, os.RemoveAll(home))
+	}
+}
+
+func TestAkamaiCliPaths(t *testing.T) {
+	tests := map[string]struct {
+		env                 map[string]string
+		data, config, cache string
+	}{
+		"XDG defaults": {
+			data:   ".local/share/akamai",
+			config: ".config/akamai",
+			cache:  ".cache/akamai",
+		},
+		"XDG variables": {
+			env:    map[string]string{"XDG_DATA_HOME": "${HOME}/data", "XDG_CONFIG_HOME": "${HOME}/cfg", "XDG_CACHE_HOME": "${HOME}/tmp"},
+			data:   "data/akamai",
+			config: "cfg/akamai",
+			cache:  "tmp/akamai",
+		},
+		"relative XDG variables are ignored": {
+			env:    map[string]string{"XDG_DATA_HOME": "data"},
+			data:   ".local/share/akamai",
+			config: ".config/akamai",
+			cache:  ".cache/akamai",
+		},
+		"legacy layout": {
+			env:    map[string]string{"AKAMAI_CLI_LEGACY_LAYOUT": "true", "XDG_DATA_HOME": "${HOME}/data"},
+			data:   ".akamai-cli",
+			config: ".akamai-cli",
+			cache:  ".akamai-cli/cache",
+		},
+		"AKAMAI_CLI_HOME set": {
+			env:    map[string]string{"AKAMAI_CLI_HOME": "${HOME}/cli"},
+			data:   "cli/.akamai-cli",
+			config: "cli/.akamai-cli",
+			cache:  "cli/.akamai-cli/cache",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, teardown := setupHome(t, nil)
+			defer teardown()
+			for k, v := range test.env {
+				require.NoError(t, os.Setenv(k, os.Expand(v, func(string) string { return home })))
+			}
+
+			data, err := GetAkamaiCliPath()
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(home, test.data), data)
+			config, err := GetAkamaiCliConfigPath()
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(home, test.config), config)
+			cache, err := GetAkamaiCliCachePath()
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(home, test.cache), cache)
+			for _, dir := range []string{data, config, cache} {
+				assert.DirExists(t, dir)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyLayout(t *testing.T) {
+	writeFile := func(t *testing.T, path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	}
+	tests := map[string]struct {
+		env      map[string]string
+		init     func(*testing.T, string)
+		migrated bool
+		expected map[string]string
+	}{
+		"migrate legacy directory": {
+			init: func(t *testing.T, home string) {
+				writeFile(t, filepath.Join(home, ".akamai-cli", "config"), "[cli]\n")
+				writeFile(t, filepath.Join(home, ".akamai-cli", "cache", "file"), "cached")
+				writeFile(t, filepath.Join(home, ".akamai-cli", "src", "cli-echo", "cli.json"), "{}")
+				writeFile(t, filepath.Join(home, ".akamai-cli", "akamai-packages.lock"), "lock")
+			},
+			migrated: true,
+			expected: map[string]string{
+				".config/akamai/config":                     "[cli]\n",
+				".cache/akamai/file":                        "cached",
+				".local/share/akamai/src/cli-echo/cli.json": "{}",
+				".local/share/akamai/akamai-packages.lock":  "lock",
+			},
+		},
+		"no legacy directory": {
+			init: func(t *testing.T, home string) {},
+		},
+		"already migrated": {
+			init: func(t *testing.T, home string) {
+				writeFile(t, filepath.Join(home, ".akamai-cli", "config"), "legacy")
+				writeFile(t, filepath.Join(home, ".local", "share", "akamai", "akamai-packages.lock"), "lock")
+			},
+			expected: map[string]string{
+				".akamai-cli/config": "legacy",
+			},
+		},
+		"legacy layout": {
+			env: map[string]string{"AKAMAI_CLI_LEGACY_LAYOUT": "1"},
+			init: func(t *testing.T, home string) {
+				writeFile(t, filepath.Join(home, ".akamai-cli", "config"), "legacy")
+			},
+			expected: map[string]string{
+				".akamai-cli/config": "legacy",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			home, teardown := setupHome(t, test.env)
+			defer teardown()
+			test.init(t, home)
+
+			migrated, err := MigrateLegacyLayout()
+			require.NoError(t, err)
+			assert.Equal(t, test.migrated, migrated)
+			for path, content := range test.expected {
+				data, err := ioutil.ReadFile(filepath.Join(home, path))
+				require.NoError(t, err)
+				assert.Equal(t, content, string(data))
+			}
+			if test.migrated {
+				assert.NoDirExists(t, filepath.Join(home, ".akamai-cli"))
+			}
+		})
+	}
+}
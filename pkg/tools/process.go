@@ -0,0 +1,45 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"os/exec"
+
+	"github.com/akamai/cli/pkg/log"
+)
+
+// RunProcessGroup starts the command in its own process group and waits for it to exit.
+// Once ctx is done, the command is killed along with processes it spawned, and the error of ctx is returned.
+func RunProcessGroup(ctx context.Context, cmd *exec.Cmd) error {
+	if err := startProcessGroup(cmd); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if err := killProcessGroup(cmd); err != nil {
+			log.FromContext(ctx).Warnf("Unable to kill process %d: %s", cmd.Process.Pid, err)
+		}
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2021. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+)
+
+// default retry settings, used when cli.retries, cli.retry-backoff or cli.request-timeout is not set
+const (
+	DefaultRetries        = 3
+	DefaultRetryBackoff   = time.Second
+	DefaultRequestTimeout = time.Minute
+
+	maxRetryBackoff = 30 * time.Second
+)
+
+// RetryOptions configure retries of failed HTTP requests
+type RetryOptions struct {
+	// Retries is the maximum number of times a failed request is retried
+	Retries int
+	// Backoff is the delay before the first retry, doubled for each next retry
+	Backoff time.Duration
+	// Timeout is the maximum time to wait for a response to each attempt, 0 means no limit
+	Timeout time.Duration
+}
+
+// RetryOptionsFromEnvironment reads retry settings from AKAMAI_CLI_RETRIES, AKAMAI_CLI_RETRY_BACKOFF and AKAMAI_CLI_REQUEST_TIMEOUT,
+// exported from cli.retries, cli.retry-backoff and cli.request-timeout config values. Defaults are used for variables which are not set.
+func RetryOptionsFromEnvironment() (RetryOptions, error) {
+	return ParseRetryOptions(os.Getenv("AKAMAI_CLI_RETRIES"), os.Getenv("AKAMAI_CLI_RETRY_BACKOFF"), os.Getenv("AKAMAI_CLI_REQUEST_TIMEOUT"))
+}
+
+// ParseRetryOptions parses the number of retries, the retry backoff and the request timeout, e.g. "3", "1s" and "1m".
+// Defaults are used for empty values, and for invalid values along with the error.
+func ParseRetryOptions(retries, backoff, timeout string) (RetryOptions, error) {
+	opts := RetryOptions{Retries: DefaultRetries, Backoff: DefaultRetryBackoff, Timeout: DefaultRequestTimeout}
+	if retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("invalid number of retries %q, expected a non-negative integer", retries)
+		}
+		opts.Retries = n
+	}
+	var err error
+	if opts.Backoff, err = parseDuration("retry backoff", backoff, opts.Backoff); err != nil {
+		return opts, err
+	}
+	if opts.Timeout, err = parseDuration("request timeout", timeout, opts.Timeout); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+func parseDuration(name, value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return fallback, fmt.Errorf("invalid %s %q, expected a duration such as 500ms, 2s or 1m", name, value)
+	}
+	return d, nil
+}
+
+type noRetryKey struct{}
+
+// WithoutRetry returns a context for requests which should not be retried, for example because they are not worth delaying the user
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// retryTransport retries requests failed because of network errors or server errors
+type retryTransport struct {
+	base http.RoundTripper
+	// sleep waits for given duration, unless the context is done first
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := log.FromContext(req.Context())
+	opts, err := RetryOptionsFromEnvironment()
+	if err != nil {
+		logger.Warnf("Invalid retry settings, using defaults: %s", err)
+	}
+	if noRetry, _ := req.Context().Value(noRetryKey{}).(bool); noRetry || (req.Body != nil && req.GetBody == nil) {
+		opts.Retries = 0
+	}
+
+	backoff := opts.Backoff
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+		resp, err := t.attempt(attemptReq, opts.Timeout)
+		if attempt == opts.Retries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		target := req.URL.Host + req.URL.Path
+		if err != nil {
+			logger.Debugf("Request to %s failed, retrying in %s: %s", target, backoff, err)
+		} else {
+			logger.Debugf("Request to %s failed with status %d, retrying in %s", target, resp.StatusCode, backoff)
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		if err := t.sleep(req.Context(), backoff); err != nil {
+			return nil, err
+		}
+		if backoff *= 2; backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// attempt sends the request, failing if no response is received within timeout
+func (t *retryTransport) attempt(req *http.Request, timeout time.Duration) (*http.Response, error) {
+	if timeout == 0 {
+		return t.base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(timeout, cancel)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	// the timeout applies to waiting for the response, the body can be read for as long as needed
+	if !timer.Stop() {
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		cancel()
+		return nil, &responseTimeoutError{timeout: timeout}
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// shouldRetry returns true if request failure may be transient: a network error, a connection closed by the server,
+// or a server error. Errors such as an unsupported URL or an invalid certificate are not retried.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// responseTimeoutError is returned if no response is received within the request timeout
+type responseTimeoutError struct {
+	timeout time.Duration
+}
+
+// Error implements error
+func (e *responseTimeoutError) Error() string {
+	return fmt.Sprintf("no response received within %s", e.timeout)
+}
+
+// Timeout implements net.Error
+func (e *responseTimeoutError) Timeout() bool { return true }
+
+// Temporary implements net.Error
+func (e *responseTimeoutError) Temporary() bool { return true }
+
+// cancelOnClose releases the context of a request once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
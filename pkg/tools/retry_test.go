@@ -0,0 +1,211 @@
+package tools
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := map[string]struct {
+		statuses       []int
+		method         string
+		body           string
+		envs           map[string]string
+		noRetry        bool
+		expectedStatus int
+		expectedSleeps []time.Duration
+	}{
+		"success": {
+			statuses:       []int{http.StatusOK},
+			expectedStatus: http.StatusOK,
+		},
+		"server errors are retried with exponential backoff": {
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			expectedStatus: http.StatusOK,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second},
+		},
+		"retries exhausted": {
+			statuses:       []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusServiceUnavailable},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		"client error is not retried": {
+			statuses:       []int{http.StatusNotFound},
+			expectedStatus: http.StatusNotFound,
+		},
+		"retry settings from environment": {
+			statuses:       []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			envs:           map[string]string{"AKAMAI_CLI_RETRIES": "1", "AKAMAI_CLI_RETRY_BACKOFF": "100ms"},
+			expectedStatus: http.StatusInternalServerError,
+			expectedSleeps: []time.Duration{100 * time.Millisecond},
+		},
+		"invalid retry settings": {
+			statuses:       []int{http.StatusInternalServerError, http.StatusOK},
+			envs:           map[string]string{"AKAMAI_CLI_RETRIES": "abc"},
+			expectedStatus: http.StatusOK,
+			expectedSleeps: []time.Duration{time.Second},
+		},
+		"request body is sent again": {
+			statuses:       []int{http.StatusInternalServerError, http.StatusOK},
+			method:         http.MethodPost,
+			body:           "request body",
+			expectedStatus: http.StatusOK,
+			expectedSleeps: []time.Duration{time.Second},
+		},
+		"retries disabled": {
+			statuses:       []int{http.StatusInternalServerError, http.StatusOK},
+			noRetry:        true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range test.envs {
+				require.NoError(t, os.Setenv(k, v))
+				defer func(k string) {
+					require.NoError(t, os.Unsetenv(k))
+				}(k)
+			}
+			var calls int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, test.body, string(body))
+				require.Less(t, calls, len(test.statuses))
+				w.WriteHeader(test.statuses[calls])
+				calls++
+			}))
+			defer srv.Close()
+
+			var sleeps []time.Duration
+			transport := &retryTransport{base: http.DefaultTransport, sleep: func(_ context.Context, d time.Duration) error {
+				sleeps = append(sleeps, d)
+				return nil
+			}}
+			ctx := context.Background()
+			if test.noRetry {
+				ctx = WithoutRetry(ctx)
+			}
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req, err := http.NewRequestWithContext(ctx, method, srv.URL, strings.NewReader(test.body))
+			require.NoError(t, err)
+
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedSleeps, sleeps)
+		})
+	}
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	tests := map[string]struct {
+		url            string
+		handler        http.HandlerFunc
+		timeout        string
+		expectedSleeps int
+		withError      string
+	}{
+		"connection refused": {
+			url:            "http://127.0.0.1:1",
+			expectedSleeps: DefaultRetries,
+			withError:      "connection refused",
+		},
+		"no response within request timeout": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			},
+			timeout:        "10ms",
+			expectedSleeps: DefaultRetries,
+			withError:      "no response received within 10ms",
+		},
+		"unsupported URL": {
+			url:       "abc://example.com",
+			withError: "unsupported protocol scheme",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.timeout != "" {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_REQUEST_TIMEOUT", test.timeout))
+				defer func() {
+					require.NoError(t, os.Unsetenv("AKAMAI_CLI_REQUEST_TIMEOUT"))
+				}()
+			}
+			url := test.url
+			if test.handler != nil {
+				srv := httptest.NewServer(test.handler)
+				defer srv.Close()
+				url = srv.URL
+			}
+			var sleeps int
+			transport := &retryTransport{base: http.DefaultTransport, sleep: func(context.Context, time.Duration) error {
+				sleeps++
+				return nil
+			}}
+
+			_, err := (&http.Client{Transport: transport}).Get(url)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.withError)
+			assert.Equal(t, test.expectedSleeps, sleeps)
+		})
+	}
+}
+
+func TestParseRetryOptions(t *testing.T) {
+	tests := map[string]struct {
+		retries, backoff, timeout string
+		expected                  RetryOptions
+		withError                 string
+	}{
+		"defaults": {
+			expected: RetryOptions{Retries: DefaultRetries, Backoff: DefaultRetryBackoff, Timeout: DefaultRequestTimeout},
+		},
+		"all values": {
+			retries:  "0",
+			backoff:  "500ms",
+			timeout:  "0s",
+			expected: RetryOptions{Backoff: 500 * time.Millisecond},
+		},
+		"invalid retries": {
+			retries:   "-1",
+			withError: `invalid number of retries "-1", expected a non-negative integer`,
+		},
+		"invalid backoff": {
+			backoff:   "1 second",
+			withError: `invalid retry backoff "1 second", expected a duration such as 500ms, 2s or 1m`,
+		},
+		"invalid timeout": {
+			timeout:   "-1m",
+			withError: `invalid request timeout "-1m", expected a duration such as 500ms, 2s or 1m`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts, err := ParseRetryOptions(test.retries, test.backoff, test.timeout)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, opts)
+		})
+	}
+}
@@ -17,6 +17,7 @@ package tools
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/mitchellh/go-homedir"
@@ -28,8 +29,13 @@ func Self() string {
 	return filepath.Base(os.Args[0])
 }
 
-// GetAkamaiCliPath ...
+// GetAkamaiCliPath returns the directory containing installed packages and other data, $XDG_DATA_HOME/akamai by default.
+// With the legacy layout, it is the .akamai-cli directory in $AKAMAI_CLI_HOME or the home directory.
 func GetAkamaiCliPath() (string, error) {
+	if !useLegacyLayout() {
+		return makeDir(xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share")))
+	}
+
 	cliHome := os.Getenv("AKAMAI_CLI_HOME")
 	if cliHome == "" {
 		var err error
@@ -39,13 +45,7 @@ func GetAkamaiCliPath() (string, error) {
 		}
 	}
 
-	cliPath := filepath.Join(cliHome, ".akamai-cli")
-	err := os.MkdirAll(cliPath, 0700)
-	if err != nil {
-		return "", cli.Exit("Unable to create Akamai CLI root directory.", -1)
-	}
-
-	return cliPath, nil
+	return makeDir(filepath.Join(cliHome, legacyDirName), nil)
 }
 
 // GetAkamaiCliSrcPath ...
@@ -55,6 +55,23 @@ func GetAkamaiCliSrcPath() (string, error) {
 	return filepath.Join(cliHome, "src"), nil
 }
 
+// GetAkamaiCliBinPath returns the directory holding shims of installed commands, which can be added to PATH
+func GetAkamaiCliBinPath() (string, error) {
+	cliHome, err := GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliHome, "bin"), nil
+}
+
+// BinSuffix returns the file name suffix of executables on the current platform, ".exe" on Windows
+func BinSuffix() string {
+	if runtime.GOOS == "windows" {
+		return ".exe"
+	}
+	return ""
+}
+
 // Githubize ..
 func Githubize(repo string) string {
 	if strings.HasPrefix(repo, "http") || strings.HasPrefix(repo, "ssh") || strings.HasSuffix(repo, ".git") {